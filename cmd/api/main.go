@@ -11,6 +11,11 @@ import (
 
 	"github.com/arrase21/crm-users/internal/config"
 	"github.com/arrase21/crm-users/internal/database"
+	"github.com/arrase21/crm-users/internal/dian"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mail"
+	"github.com/arrase21/crm-users/internal/payout"
+	"github.com/arrase21/crm-users/internal/pila"
 	"github.com/arrase21/crm-users/internal/repository"
 	"github.com/arrase21/crm-users/internal/service"
 	transportHttp "github.com/arrase21/crm-users/internal/transport/http"
@@ -26,6 +31,10 @@ func main() {
 
 	log.Println("1️⃣ cargando configuración")
 	pgCfg := config.LoadPostgres()
+	payoutCfg := config.LoadPayout()
+	smtpCfg := config.LoadSMTP()
+	dianCfg := config.LoadDian()
+	pilaCfg := config.LoadPILA()
 
 	log.Println("2️⃣ conectando a la base de datos")
 
@@ -39,6 +48,8 @@ func main() {
 		log.Fatalf("❌ Failed to migrate database: %v", err)
 	}
 
+	txManager := repository.NewGormTxManager(db)
+
 	userRepo := repository.NewGormUserRepository(db)
 	userService := service.NewUserService(userRepo)
 	roleRepo := repository.NewGormRoleRepository(db)
@@ -48,7 +59,9 @@ func main() {
 
 	// Employee
 	employeeRepo := repository.NewGormEmployeeRepository(db)
-	employeeService := service.NewEmployeeService(employeeRepo)
+	// Data scopes (alcance own_reports / own_department de los permisos)
+	dataScopeService := service.NewDataScopeService(permissionService, employeeRepo)
+	employeeService := service.NewEmployeeService(employeeRepo, dataScopeService)
 
 	// Payroll Concept
 	payrollConceptRepo := repository.NewGormPayrollConceptRepository(db)
@@ -57,40 +70,288 @@ func main() {
 	// Employee Contract
 	contractRepo := repository.NewGormEmployeeContractRepository(db)
 
+	// Accounting Periods (cierre y bloqueo)
+	periodRepo := repository.NewGormAccountingPeriodRepository(db)
+	accountingPeriodService := service.NewAccountingPeriodService(periodRepo, permissionService)
+
 	// Payroll
 	payrollRepo := repository.NewGormPayrollRepository(db)
-	payrollService := service.NewPayrollService(payrollRepo)
+	payrollService := service.NewPayrollService(payrollRepo, periodRepo, dataScopeService)
 	payrollItemRepo := repository.NewGormPayrollItemRepository(db)
 
 	// Payment
 	paymentRepo := repository.NewGormPaymentRepository(db)
+	bankAccountRepo := repository.NewGormEmployeeBankAccountRepository(db)
+
+	// Retro adjustments (diferencias de periodos ya pagados)
+	retroRepo := repository.NewGormRetroAdjustmentRepository(db)
+
+	// Acumulados anuales por empleado (retención y certificado de ingresos)
+	payrollAccumulatorRepo := repository.NewGormPayrollAccumulatorRepository(db)
+
+	// Centros de costo y reparto del costo de nómina por empleado
+	costCenterRepo := repository.NewGormCostCenterRepository(db)
+	costAllocationRepo := repository.NewGormCostCenterAllocationRepository(db)
+
+	// Payroll status history & transitions
+	payrollHistoryRepo := repository.NewGormPayrollStatusHistoryRepository(db)
+	payrollTransitionRepo := repository.NewGormPayrollTransitionRepository(db)
+
+	// Notification outbox (correos que se registran con el cambio que los origina)
+	notificationOutboxRepo := repository.NewGormNotificationOutboxRepository(db)
+
+	// Ledger de prestaciones: los pagos que se cancelan antes de pagarse devuelven su saldo
+	benefitLedgerRepo := repository.NewGormBenefitLedgerRepository(db)
+
+	// Payroll State Service (transiciones de estado)
+	payrollStateService := service.NewPayrollStateService(
+		txManager,
+		payrollRepo,
+		paymentRepo,
+		employeeRepo,
+		bankAccountRepo,
+		payrollHistoryRepo,
+		payrollTransitionRepo,
+		notificationOutboxRepo,
+		payrollAccumulatorRepo,
+		benefitLedgerRepo,
+		periodRepo,
+	)
+
+	// Ausencias: las licencias no remuneradas descuentan días en el cálculo de la nómina
+	absenceRepo := repository.NewGormEmployeeAbsenceRepository(db)
 
 	// Payroll Calculator
 	payrollCalculatorService := service.NewPayrollCalculatorService(
+		txManager,
 		payrollRepo,
 		payrollItemRepo,
 		employeeRepo,
 		contractRepo,
 		payrollConceptRepo,
+		periodRepo,
+		retroRepo,
+		payrollAccumulatorRepo,
+		costAllocationRepo,
+		absenceRepo,
+		payrollStateService,
 	)
 
-	// Payroll State Service (transiciones de estado)
-	payrollStateService := service.NewPayrollStateService(
+	// Batch Payroll Service
+	batchPayrollService := service.NewPayrollBatchService(
+		txManager,
 		payrollRepo,
-		paymentRepo,
+		payrollItemRepo,
 		employeeRepo,
+		contractRepo,
+		payrollConceptRepo,
+		periodRepo,
+		retroRepo,
+		payrollStateService,
+		payrollAccumulatorRepo,
+		costAllocationRepo,
+		absenceRepo,
 	)
 
-	// Batch Payroll Service
-	batchPayrollService := service.NewPayrollBatchService(
+	// Payroll Reversal Service (reversos y nóminas de reemplazo)
+	payrollReversalService := service.NewPayrollReversalService(
+		txManager,
+		payrollRepo,
+		payrollItemRepo,
+		payrollHistoryRepo,
+		periodRepo,
+		payrollCalculatorService,
+		payrollAccumulatorRepo,
+	)
+
+	// Payroll Retro Service (ajustes RETRO_* sobre periodos pagados)
+	payrollRetroService := service.NewPayrollRetroService(
+		txManager,
+		payrollRepo,
+		employeeRepo,
+		contractRepo,
+		payrollConceptRepo,
+		retroRepo,
+		payrollCalculatorService,
+	)
+
+	// Benefit Service (provisiones y pagos de prestaciones sociales)
+	benefitService := service.NewBenefitService(
+		txManager,
 		payrollRepo,
 		payrollItemRepo,
+		benefitLedgerRepo,
+		periodRepo,
+	)
+
+	// Termination Service (terminación de contrato y liquidación final)
+	terminationRepo := repository.NewGormEmployeeTerminationRepository(db)
+	terminationService := service.NewTerminationService(
+		txManager,
 		employeeRepo,
 		contractRepo,
+		payrollRepo,
+		payrollItemRepo,
 		payrollConceptRepo,
+		benefitLedgerRepo,
+		terminationRepo,
+		periodRepo,
+		payrollCalculatorService,
+		benefitService,
+	)
+
+	// Contract Types (tipos de contrato del tenant con sus reglas)
+	contractTypeRepo := repository.NewGormContractTypeRepository(db)
+	contractTypeService := service.NewContractTypeService(contractTypeRepo)
+
+	// Departments & Positions (estructura organizacional)
+	departmentRepo := repository.NewGormDepartmentRepository(db)
+	positionRepo := repository.NewGormPositionRepository(db)
+	departmentService := service.NewDepartmentService(departmentRepo, employeeRepo)
+	positionService := service.NewPositionService(positionRepo, departmentRepo)
+	compensationService := service.NewCompensationService(employeeRepo, positionRepo, departmentRepo)
+
+	// Contract Service (historia de contratos, modificaciones y prórrogas)
+	contractService := service.NewContractService(
+		txManager,
+		contractRepo,
+		employeeRepo,
+		contractTypeRepo,
+		positionRepo,
+		periodRepo,
+		permissionService,
+		payrollRetroService,
+	)
+
+	// Onboarding (usuario, empleado, contrato, conceptos y cuenta de pago)
+	employeeConceptRepo := repository.NewGormEmployeeConceptRepository(db)
+	onboardingService := service.NewOnboardingService(
+		txManager,
+		userRepo,
+		employeeRepo,
+		departmentRepo,
+		positionRepo,
+		payrollConceptRepo,
+		employeeConceptRepo,
+		bankAccountRepo,
+		periodRepo,
+		contractService,
+	)
+
+	// Bank Accounts (cuentas de pago del empleado)
+	bankAccountService := service.NewBankAccountService(txManager, bankAccountRepo, employeeRepo)
+
+	// Payout (proveedor de pagos y ciclo de vida de las transferencias)
+	payoutProvider, err := payout.NewProvider(payoutCfg.Provider)
+	if err != nil {
+		log.Fatalf("❌ Failed to configure payout provider: %v", err)
+	}
+	paymentService := service.NewPaymentService(txManager, paymentRepo, payrollStateService, payoutProvider, payoutCfg.CallbackSecret)
+
+	// Bank files (CSV, pain.001 y planos de ancho fijo por banco)
+	bankFileRepo := repository.NewGormBankPaymentFileRepository(db)
+	bankFileTemplateRepo := repository.NewGormBankFileTemplateRepository(db)
+	bankFileService := service.NewBankFileService(
+		txManager,
+		payrollRepo,
+		paymentRepo,
+		bankFileRepo,
+		bankFileTemplateRepo,
 		payrollStateService,
 	)
 
+	// Bank statements (conciliación de pagos con el extracto)
+	bankStatementRepo := repository.NewGormBankStatementRepository(db)
+	reconciliationService := service.NewReconciliationService(txManager, bankStatementRepo, paymentRepo, paymentService)
+
+	// Payslips (desprendible de nómina en PDF con plantilla del tenant)
+	payslipTemplateRepo := repository.NewGormPayslipTemplateRepository(db)
+	payslipService := service.NewPayslipService(
+		payrollRepo,
+		contractRepo,
+		departmentRepo,
+		positionRepo,
+		payslipTemplateRepo,
+	)
+
+	// Notifications (envío del desprendible por SMTP con reintentos)
+	var mailer domain.Mailer
+	if smtpCfg.Host != "" {
+		mailer = mail.NewSMTPMailer(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, smtpCfg.From)
+	}
+	notificationService := service.NewNotificationService(
+		notificationOutboxRepo,
+		payrollRepo,
+		payslipService,
+		mailer,
+		smtpCfg.MaxAttempts,
+		smtpCfg.RetryBackoff,
+	)
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	defer stopDispatch()
+	if mailer != nil {
+		go notificationService.Run(dispatchCtx, smtpCfg.PollInterval)
+	} else {
+		log.Println("ℹ️ SMTP_HOST no configurado, el envío de desprendibles queda pendiente")
+	}
+
+	// Electronic payroll (documento soporte de nómina electrónica para la DIAN)
+	dianClient, err := dian.NewClient(dianCfg.Client)
+	if err != nil {
+		log.Fatalf("❌ Failed to configure DIAN client: %v", err)
+	}
+	electronicPayrollRepo := repository.NewGormElectronicPayrollRepository(db)
+	electronicPayrollService := service.NewElectronicPayrollService(
+		txManager,
+		electronicPayrollRepo,
+		payrollRepo,
+		contractRepo,
+		paymentRepo,
+		payslipTemplateRepo,
+		dianClient,
+		dian.Settings{
+			Environment:    dianCfg.Environment,
+			SoftwareID:     dianCfg.SoftwareID,
+			SoftwarePIN:    dianCfg.SoftwarePIN,
+			ProviderName:   dianCfg.ProviderName,
+			ProviderNIT:    dianCfg.ProviderNIT,
+			DepartmentCode: dianCfg.DepartmentCode,
+			CityCode:       dianCfg.CityCode,
+		},
+	)
+
+	// Ausencias y planilla PILA de aportes a seguridad social
+	absenceService := service.NewAbsenceService(txManager, absenceRepo, employeeRepo, periodRepo, dataScopeService, payrollRetroService)
+	pilaService := service.NewPILAService(
+		payrollRepo,
+		contractRepo,
+		absenceRepo,
+		payslipTemplateRepo,
+		pila.Settings{
+			OperatorCode:     pilaCfg.OperatorCode,
+			ARLCode:          pilaCfg.ARLCode,
+			CompensationFund: pilaCfg.CompensationFund,
+			BranchCode:       pilaCfg.BranchCode,
+			BranchName:       pilaCfg.BranchName,
+			DepartmentCode:   pilaCfg.DepartmentCode,
+			CityCode:         pilaCfg.CityCode,
+			EconomicActivity: pilaCfg.EconomicActivity,
+		},
+	)
+
+	// Asiento contable de nómina
+	journalService := service.NewJournalService(payrollRepo, payrollConceptRepo)
+
+	// Libro de nómina con una columna por concepto
+	payrollRegisterRepo := repository.NewGormPayrollRegisterRepository(db)
+	payrollRegisterService := service.NewPayrollRegisterService(payrollRegisterRepo, dataScopeService)
+
+	// Acumulados anuales y certificado de ingresos y retenciones
+	accumulatorService := service.NewAccumulatorService(payrollAccumulatorRepo, employeeRepo, payslipTemplateRepo)
+
+	// Centros de costo y reporte de costo de nómina por centro
+	costCenterService := service.NewCostCenterService(costCenterRepo, costAllocationRepo, employeeRepo, payrollRegisterRepo)
+
 	router := transportHttp.NewRouter(
 		userService,
 		roleService,
@@ -101,6 +362,30 @@ func main() {
 		payrollService,
 		payrollStateService,
 		batchPayrollService,
+		payrollReversalService,
+		accountingPeriodService,
+		payrollRetroService,
+		benefitService,
+		terminationService,
+		contractService,
+		contractTypeService,
+		departmentService,
+		positionService,
+		compensationService,
+		onboardingService,
+		bankAccountService,
+		paymentService,
+		bankFileService,
+		reconciliationService,
+		payslipService,
+		notificationService,
+		electronicPayrollService,
+		absenceService,
+		pilaService,
+		journalService,
+		payrollRegisterService,
+		accumulatorService,
+		costCenterService,
 	)
 	port := getEnv("PORT", "8080")
 	srv := &http.Server{
@@ -121,6 +406,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("🛑 Shutting down server...")
+	stopDispatch()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
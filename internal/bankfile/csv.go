@@ -0,0 +1,35 @@
+package bankfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
+// CSVExporter genera un CSV genérico: una fila por transferencia y una fila final TOTAL con
+// el número de registros, la suma y el hash de control
+type CSVExporter struct{}
+
+func (CSVExporter) Format() string      { return "csv" }
+func (CSVExporter) Extension() string   { return "csv" }
+func (CSVExporter) ContentType() string { return "text/csv" }
+
+func (CSVExporter) Export(batch *Batch) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"reference", "end_to_end_id", "employee_dni", "employee_name", "bank_name",
+		"account_type", "account_number", "amount"}}
+	for _, t := range batch.Transfers {
+		rows = append(rows, []string{batch.Reference, t.EndToEndID, t.EmployeeDni, t.EmployeeName, t.BankName,
+			t.AccountType, t.AccountNumber, formatAmount(t.Amount)})
+	}
+	rows = append(rows, []string{"TOTAL", strconv.Itoa(batch.Count()), formatAmount(batch.Total()), batch.ControlHash()})
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
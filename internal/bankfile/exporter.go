@@ -0,0 +1,101 @@
+package bankfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Exporter genera el archivo de pagos en el formato de un banco
+type Exporter interface {
+	Format() string
+	Extension() string
+	ContentType() string
+	Export(batch *Batch) ([]byte, error)
+}
+
+// Debtor es la cuenta de la empresa desde la que salen las transferencias
+type Debtor struct {
+	Name          string
+	ID            string // NIT
+	BankName      string
+	AccountType   string
+	AccountNumber string
+}
+
+// Transfer es una transferencia a la cuenta de un empleado
+type Transfer struct {
+	PaymentID     uint
+	PayrollID     uint
+	EndToEndID    string
+	EmployeeDni   string
+	EmployeeName  string
+	BankName      string
+	AccountType   string
+	AccountNumber string
+	Amount        float64
+}
+
+// Batch es el lote de transferencias de un archivo con sus totales de control
+type Batch struct {
+	Reference     string
+	CreatedAt     time.Time
+	ExecutionDate time.Time
+	Debtor        Debtor
+	Transfers     []Transfer
+}
+
+// Count es el número de transferencias del lote
+func (b *Batch) Count() int {
+	return len(b.Transfers)
+}
+
+// Total es la suma de los montos del lote
+func (b *Batch) Total() float64 {
+	var cents int64
+	for _, t := range b.Transfers {
+		cents += Cents(t.Amount)
+	}
+	return float64(cents) / 100
+}
+
+// ControlHash es el SHA-256 de las transferencias (referencia, cuenta y monto en centavos).
+// Va en el registro de control para que el banco detecte cambios en el detalle.
+func (b *Batch) ControlHash() string {
+	h := sha256.New()
+	for _, t := range b.Transfers {
+		fmt.Fprintf(h, "%s|%s|%d\n", t.EndToEndID, t.AccountNumber, Cents(t.Amount))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cents convierte un monto a centavos
+func Cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FileHash es el SHA-256 del contenido del archivo
+func FileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewExporter retorna el exportador del formato; fixed_width requiere la plantilla del banco
+func NewExporter(format string, template *domain.BankFileTemplate) (Exporter, error) {
+	switch format {
+	case domain.BankFileFormatCSV:
+		return CSVExporter{}, nil
+	case domain.BankFileFormatPain001:
+		return Pain001Exporter{}, nil
+	case domain.BankFileFormatFixedWidth:
+		if template == nil {
+			return nil, fmt.Errorf("%w: fixed width export requires a template", domain.ErrInvalidBankFileTemplate)
+		}
+		return FixedWidthExporter{Template: template}, nil
+	}
+	return nil, domain.ErrInvalidBankFileFormat
+}
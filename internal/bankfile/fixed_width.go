@@ -0,0 +1,122 @@
+package bankfile
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// FixedWidthExporter genera un archivo plano con los registros de ancho fijo que define la
+// plantilla del banco. Los montos se escriben en centavos sin separador decimal y los
+// valores más largos que la columna se truncan.
+type FixedWidthExporter struct {
+	Template *domain.BankFileTemplate
+}
+
+func (FixedWidthExporter) Format() string      { return "fixed_width" }
+func (FixedWidthExporter) Extension() string   { return "txt" }
+func (FixedWidthExporter) ContentType() string { return "text/plain" }
+
+func (e FixedWidthExporter) Export(batch *Batch) ([]byte, error) {
+	sections := make(map[string][]domain.BankFileTemplateField)
+	for _, f := range e.Template.Fields {
+		sections[f.Section] = append(sections[f.Section], f)
+	}
+	if len(sections[domain.BankFileSectionDetail]) == 0 {
+		return nil, fmt.Errorf("%w: at least one detail field is required", domain.ErrInvalidBankFileTemplate)
+	}
+
+	var buf bytes.Buffer
+	if fields := sections[domain.BankFileSectionHeader]; len(fields) > 0 {
+		writeRecord(&buf, fields, func(source string) string { return batchValue(batch, source) })
+	}
+	for i, t := range batch.Transfers {
+		writeRecord(&buf, sections[domain.BankFileSectionDetail], func(source string) string {
+			return transferValue(batch, &t, i+1, source)
+		})
+	}
+	if fields := sections[domain.BankFileSectionTrailer]; len(fields) > 0 {
+		writeRecord(&buf, fields, func(source string) string { return batchValue(batch, source) })
+	}
+	return buf.Bytes(), nil
+}
+
+func writeRecord(buf *bytes.Buffer, fields []domain.BankFileTemplateField, value func(source string) string) {
+	for _, f := range fields {
+		v := f.Value
+		if f.Source != "constant" {
+			v = value(f.Source)
+		}
+		buf.WriteString(pad(v, f))
+	}
+	buf.WriteString("\r\n")
+}
+
+func batchValue(batch *Batch, source string) string {
+	switch source {
+	case "reference":
+		return batch.Reference
+	case "date":
+		return batch.CreatedAt.Format("20060102")
+	case "execution_date":
+		return batch.ExecutionDate.Format("20060102")
+	case "debtor_name":
+		return batch.Debtor.Name
+	case "debtor_id":
+		return batch.Debtor.ID
+	case "debtor_account":
+		return batch.Debtor.AccountNumber
+	case "debtor_account_type":
+		return batch.Debtor.AccountType
+	case "count":
+		return strconv.Itoa(batch.Count())
+	case "total":
+		return strconv.FormatInt(Cents(batch.Total()), 10)
+	case "hash":
+		return batch.ControlHash()
+	}
+	return ""
+}
+
+func transferValue(batch *Batch, t *Transfer, sequence int, source string) string {
+	switch source {
+	case "reference":
+		return batch.Reference
+	case "sequence":
+		return strconv.Itoa(sequence)
+	case "end_to_end_id":
+		return t.EndToEndID
+	case "employee_dni":
+		return t.EmployeeDni
+	case "employee_name":
+		return t.EmployeeName
+	case "bank_name":
+		return t.BankName
+	case "account_type":
+		return t.AccountType
+	case "account_number":
+		return t.AccountNumber
+	case "amount":
+		return strconv.FormatInt(Cents(t.Amount), 10)
+	}
+	return ""
+}
+
+// pad ajusta el valor al ancho de la columna con el relleno y la alineación de la plantilla
+func pad(value string, f domain.BankFileTemplateField) string {
+	value = strings.ToUpper(value)
+	if n := utf8.RuneCountInString(value); n > f.Width {
+		return string([]rune(value)[:f.Width])
+	} else if n < f.Width {
+		fill := strings.Repeat(f.PadChar, f.Width-n)
+		if f.Align == "right" {
+			return fill + value
+		}
+		return value + fill
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+package bankfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Pain001Exporter genera una transferencia ISO 20022 pain.001.001.03. NbOfTxs y CtrlSum
+// llevan los totales de control del lote.
+type Pain001Exporter struct{}
+
+func (Pain001Exporter) Format() string      { return "pain001" }
+func (Pain001Exporter) Extension() string   { return "xml" }
+func (Pain001Exporter) ContentType() string { return "application/xml" }
+
+type painDocument struct {
+	XMLName xml.Name             `xml:"Document"`
+	Xmlns   string               `xml:"xmlns,attr"`
+	Init    painCstmrCdtTrfInitn `xml:"CstmrCdtTrfInitn"`
+}
+
+type painCstmrCdtTrfInitn struct {
+	GrpHdr painGroupHeader `xml:"GrpHdr"`
+	PmtInf painPaymentInfo `xml:"PmtInf"`
+}
+
+type painGroupHeader struct {
+	MsgId    string    `xml:"MsgId"`
+	CreDtTm  string    `xml:"CreDtTm"`
+	NbOfTxs  int       `xml:"NbOfTxs"`
+	CtrlSum  string    `xml:"CtrlSum"`
+	InitgPty painParty `xml:"InitgPty"`
+}
+
+type painParty struct {
+	Nm string       `xml:"Nm"`
+	Id *painPartyID `xml:"Id,omitempty"`
+}
+
+type painPartyID struct {
+	OrgId painOther `xml:"OrgId>Othr"`
+}
+
+type painOther struct {
+	Id string `xml:"Id"`
+}
+
+type painPaymentInfo struct {
+	PmtInfId    string            `xml:"PmtInfId"`
+	PmtMtd      string            `xml:"PmtMtd"`
+	NbOfTxs     int               `xml:"NbOfTxs"`
+	CtrlSum     string            `xml:"CtrlSum"`
+	PmtTpInf    painPaymentType   `xml:"PmtTpInf"`
+	ReqdExctnDt string            `xml:"ReqdExctnDt"`
+	Dbtr        painParty         `xml:"Dbtr"`
+	DbtrAcct    painAccount       `xml:"DbtrAcct"`
+	DbtrAgt     painAgent         `xml:"DbtrAgt"`
+	CdtTrfTxInf []painTransaction `xml:"CdtTrfTxInf"`
+}
+
+type painPaymentType struct {
+	CtgyPurp string `xml:"CtgyPurp>Cd"`
+}
+
+type painAccount struct {
+	Id  painOther `xml:"Id>Othr"`
+	Tp  string    `xml:"Tp>Prtry,omitempty"`
+	Ccy string    `xml:"Ccy"`
+}
+
+type painAgent struct {
+	Nm string `xml:"FinInstnId>Nm"`
+}
+
+type painTransaction struct {
+	EndToEndId string      `xml:"PmtId>EndToEndId"`
+	Amt        painAmount  `xml:"Amt>InstdAmt"`
+	CdtrAgt    painAgent   `xml:"CdtrAgt"`
+	Cdtr       painParty   `xml:"Cdtr"`
+	CdtrAcct   painAccount `xml:"CdtrAcct"`
+	RmtInf     string      `xml:"RmtInf>Ustrd"`
+}
+
+type painAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (Pain001Exporter) Export(batch *Batch) ([]byte, error) {
+	count := batch.Count()
+	total := formatAmount(batch.Total())
+	doc := painDocument{
+		Xmlns: pain001Namespace,
+		Init: painCstmrCdtTrfInitn{
+			GrpHdr: painGroupHeader{
+				MsgId:    batch.Reference,
+				CreDtTm:  batch.CreatedAt.Format("2006-01-02T15:04:05"),
+				NbOfTxs:  count,
+				CtrlSum:  total,
+				InitgPty: painParty{Nm: batch.Debtor.Name, Id: debtorID(batch.Debtor)},
+			},
+			PmtInf: painPaymentInfo{
+				PmtInfId:    batch.Reference,
+				PmtMtd:      "TRF",
+				NbOfTxs:     count,
+				CtrlSum:     total,
+				PmtTpInf:    painPaymentType{CtgyPurp: "SALA"},
+				ReqdExctnDt: batch.ExecutionDate.Format("2006-01-02"),
+				Dbtr:        painParty{Nm: batch.Debtor.Name, Id: debtorID(batch.Debtor)},
+				DbtrAcct: painAccount{
+					Id:  painOther{Id: batch.Debtor.AccountNumber},
+					Tp:  strings.ToUpper(batch.Debtor.AccountType),
+					Ccy: "COP",
+				},
+				DbtrAgt: painAgent{Nm: batch.Debtor.BankName},
+			},
+		},
+	}
+	for _, t := range batch.Transfers {
+		doc.Init.PmtInf.CdtTrfTxInf = append(doc.Init.PmtInf.CdtTrfTxInf, painTransaction{
+			EndToEndId: t.EndToEndID,
+			Amt:        painAmount{Ccy: "COP", Value: formatAmount(t.Amount)},
+			CdtrAgt:    painAgent{Nm: t.BankName},
+			Cdtr:       painParty{Nm: t.EmployeeName, Id: &painPartyID{OrgId: painOther{Id: t.EmployeeDni}}},
+			CdtrAcct: painAccount{
+				Id:  painOther{Id: t.AccountNumber},
+				Tp:  strings.ToUpper(t.AccountType),
+				Ccy: "COP",
+			},
+			RmtInf: "NOMINA " + batch.Reference,
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func debtorID(d Debtor) *painPartyID {
+	if d.ID == "" {
+		return nil
+	}
+	return &painPartyID{OrgId: painOther{Id: d.ID}}
+}
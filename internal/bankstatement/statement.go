@@ -0,0 +1,189 @@
+package bankstatement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatos de extracto soportados
+const (
+	FormatCSV     = "csv"
+	FormatCamt053 = "camt053"
+)
+
+// ErrInvalidStatement indica que el extracto no se pudo leer
+var ErrInvalidStatement = errors.New("invalid bank statement")
+
+// Statement es un extracto bancario leído del archivo
+type Statement struct {
+	Reference     string
+	AccountNumber string
+	Lines         []Line
+}
+
+// Line es un movimiento del extracto. Amount siempre es positivo; Debit indica si salió
+// de la cuenta de la empresa.
+type Line struct {
+	Number      int
+	Date        time.Time
+	Amount      float64
+	Debit       bool
+	Account     string
+	Reference   string
+	Description string
+}
+
+// Parse lee un extracto en CSV o camt.053
+func Parse(format string, content []byte) (*Statement, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return parseCSV(content)
+	case FormatCamt053:
+		return parseCamt053(content)
+	}
+	return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidStatement, format)
+}
+
+// parseCSV lee un CSV con encabezado. Las columnas reconocidas son date, amount, account,
+// reference y description; un monto negativo o una columna type con D/debit es un débito.
+func parseCSV(content []byte) (*Statement, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := cols["amount"]; !ok {
+		return nil, fmt.Errorf("%w: amount column is required", ErrInvalidStatement)
+	}
+	get := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	st := &Statement{}
+	for n := 2; ; n++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatement, n, err)
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(get(record, "amount"), ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid amount", ErrInvalidStatement, n)
+		}
+		line := Line{
+			Number:      n,
+			Amount:      roundCents(math.Abs(amount)),
+			Debit:       amount < 0,
+			Account:     normalizeAccount(get(record, "account")),
+			Reference:   get(record, "reference"),
+			Description: get(record, "description"),
+		}
+		switch strings.ToUpper(get(record, "type")) {
+		case "D", "DEBIT", "DBIT":
+			line.Debit = true
+		case "C", "CREDIT", "CRDT":
+			line.Debit = false
+		}
+		if d := get(record, "date"); d != "" {
+			if line.Date, err = time.Parse("2006-01-02", d); err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid date", ErrInvalidStatement, n)
+			}
+		}
+		st.Lines = append(st.Lines, line)
+	}
+	return st, nil
+}
+
+type camtDocument struct {
+	Stmt struct {
+		Id   string `xml:"Id"`
+		Acct struct {
+			Othr string `xml:"Id>Othr>Id"`
+			IBAN string `xml:"Id>IBAN"`
+		} `xml:"Acct"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amt         string `xml:"Amt"`
+	CdtDbtInd   string `xml:"CdtDbtInd"`
+	BookingDate string `xml:"BookgDt>Dt"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	AddtlInfo   string `xml:"AddtlNtryInf"`
+	Tx          struct {
+		EndToEndId string `xml:"Refs>EndToEndId"`
+		CdtrAcct   string `xml:"RltdPties>CdtrAcct>Id>Othr>Id"`
+		CdtrIBAN   string `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+		Ustrd      string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCamt053 lee un extracto ISO 20022 camt.053; cada Ntry es una línea
+func parseCamt053(content []byte) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+	st := &Statement{
+		Reference:     doc.Stmt.Id,
+		AccountNumber: normalizeAccount(firstNonEmpty(doc.Stmt.Acct.Othr, doc.Stmt.Acct.IBAN)),
+	}
+	for i, e := range doc.Stmt.Entries {
+		amount, err := strconv.ParseFloat(strings.TrimSpace(e.Amt), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d: invalid amount", ErrInvalidStatement, i+1)
+		}
+		line := Line{
+			Number:      i + 1,
+			Amount:      roundCents(math.Abs(amount)),
+			Debit:       strings.EqualFold(e.CdtDbtInd, "DBIT"),
+			Account:     normalizeAccount(firstNonEmpty(e.Tx.CdtrAcct, e.Tx.CdtrIBAN)),
+			Reference:   firstNonEmpty(e.Tx.EndToEndId, e.AcctSvcrRef),
+			Description: firstNonEmpty(e.Tx.Ustrd, e.AddtlInfo),
+		}
+		if e.BookingDate != "" {
+			if line.Date, err = time.Parse("2006-01-02", strings.TrimSpace(e.BookingDate)); err != nil {
+				return nil, fmt.Errorf("%w: entry %d: invalid booking date", ErrInvalidStatement, i+1)
+			}
+		}
+		st.Lines = append(st.Lines, line)
+	}
+	return st, nil
+}
+
+func normalizeAccount(account string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(account))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,26 @@
+package config
+
+// DianConfig son los datos del software de nómina electrónica habilitado ante la DIAN
+type DianConfig struct {
+	Client         string
+	Environment    string
+	SoftwareID     string
+	SoftwarePIN    string
+	ProviderName   string
+	ProviderNIT    string
+	DepartmentCode string
+	CityCode       string
+}
+
+func LoadDian() *DianConfig {
+	return &DianConfig{
+		Client:         getEnv("DIAN_CLIENT", "stub"),
+		Environment:    getEnv("DIAN_ENVIRONMENT", "2"),
+		SoftwareID:     getEnv("DIAN_SOFTWARE_ID", "local-software"),
+		SoftwarePIN:    getEnv("DIAN_SOFTWARE_PIN", "00000"),
+		ProviderName:   getEnv("DIAN_PROVIDER_NAME", "Proveedor local"),
+		ProviderNIT:    getEnv("DIAN_PROVIDER_NIT", "900000000"),
+		DepartmentCode: getEnv("DIAN_DEPARTMENT_CODE", "11"),
+		CityCode:       getEnv("DIAN_CITY_CODE", "11001"),
+	}
+}
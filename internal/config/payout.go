@@ -0,0 +1,15 @@
+package config
+
+// PayoutConfig configura el proveedor de pagos. Sin PAYOUT_CALLBACK_SECRET el callback del
+// proveedor se rechaza y el estado de las transferencias solo se obtiene con la sincronización.
+type PayoutConfig struct {
+	Provider       string
+	CallbackSecret string
+}
+
+func LoadPayout() *PayoutConfig {
+	return &PayoutConfig{
+		Provider:       getEnv("PAYOUT_PROVIDER", "fake"),
+		CallbackSecret: getEnv("PAYOUT_CALLBACK_SECRET", ""),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+// PILAConfig son los datos del aportante para la planilla de seguridad social
+type PILAConfig struct {
+	OperatorCode     string
+	ARLCode          string
+	CompensationFund string
+	BranchCode       string
+	BranchName       string
+	DepartmentCode   string
+	CityCode         string
+	EconomicActivity string
+}
+
+func LoadPILA() *PILAConfig {
+	return &PILAConfig{
+		OperatorCode:     getEnv("PILA_OPERATOR_CODE", "83"),
+		ARLCode:          getEnv("PILA_ARL_CODE", "14-23"),
+		CompensationFund: getEnv("PILA_CCF_CODE", "CCF22"),
+		BranchCode:       getEnv("PILA_BRANCH_CODE", ""),
+		BranchName:       getEnv("PILA_BRANCH_NAME", ""),
+		DepartmentCode:   getEnv("PILA_DEPARTMENT_CODE", "11"),
+		CityCode:         getEnv("PILA_CITY_CODE", "001"),
+		EconomicActivity: getEnv("PILA_ECONOMIC_ACTIVITY", "1000000"),
+	}
+}
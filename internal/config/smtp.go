@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// SMTPConfig configura el envío de correos y el despachador del outbox.
+// Sin SMTP_HOST el despachador no se inicia y las notificaciones quedan pending.
+type SMTPConfig struct {
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	From         string
+	PollInterval time.Duration
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+func LoadSMTP() *SMTPConfig {
+	return &SMTPConfig{
+		Host:         getEnv("SMTP_HOST", ""),
+		Port:         getEnv("SMTP_PORT", "587"),
+		Username:     getEnv("SMTP_USERNAME", ""),
+		Password:     getEnv("SMTP_PASSWORD", ""),
+		From:         getEnv("SMTP_FROM", "nomina@localhost"),
+		PollInterval: getDuration("OUTBOX_POLL_INTERVAL", 30*time.Second),
+		MaxAttempts:  getInt("OUTBOX_MAX_ATTEMPTS", 5),
+		RetryBackoff: getDuration("OUTBOX_RETRY_BACKOFF", time.Minute),
+	}
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+func getInt(key string, def int) int {
+	n, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
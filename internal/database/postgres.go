@@ -62,11 +62,33 @@ func Automigrate(db *gorm.DB) error {
 		&domain.Position{},
 		&domain.Employee{},
 		&domain.EmployeeContract{},
+		&domain.EmployeeConcept{},
+		&domain.EmployeeBankAccount{},
 		&domain.ContractType{},
 		&domain.Payroll{},
 		&domain.PayrollItem{},
 		&domain.PayrollConcept{},
 		&domain.Payment{},
+		&domain.BankPaymentFile{},
+		&domain.BankFileTemplate{},
+		&domain.BankFileTemplateField{},
+		&domain.BankStatement{},
+		&domain.BankStatementLine{},
+		&domain.PayslipTemplate{},
+		&domain.NotificationOutbox{},
+		&domain.ElectronicPayrollDocument{},
+		&domain.ElectronicPayrollSubmission{},
+		&domain.EmployeeAbsence{},
+		&domain.PayrollAccumulator{},
+		&domain.CostCenter{},
+		&domain.CostCenterAllocation{},
+		&domain.PayrollItemAllocation{},
+		&domain.PayrollStatusHistory{},
+		&domain.PayrollStatusTransition{},
+		&domain.AccountingPeriod{},
+		&domain.RetroAdjustment{},
+		&domain.BenefitLedgerEntry{},
+		&domain.EmployeeTermination{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %s", err)
@@ -0,0 +1,59 @@
+package dian
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// CUNE calcula el Código Único de Nómina Electrónica: SHA-384 de la concatenación de
+// NumNE + FecNE + HorNE + ValDev + ValDed + ValTolNE + NitNE + DocEmp + TipoXML +
+// SoftwarePin + TipAmb, con los valores en dos decimales
+func CUNE(number, date, hour string, earnings, deductions, total float64, employerNIT, workerDocument, tipoXML, softwarePIN, environment string) string {
+	return sha384(number, date, hour, formatAmount(earnings), formatAmount(deductions), formatAmount(total),
+		employerNIT, workerDocument, tipoXML, softwarePIN, environment)
+}
+
+// SoftwareSecurityCode es el código de seguridad del software: SHA-384 de
+// SoftwareID + SoftwarePin + NumNE
+func SoftwareSecurityCode(softwareID, softwarePIN, number string) string {
+	return sha384(softwareID, softwarePIN, number)
+}
+
+// CheckDigit calcula el dígito de verificación de un NIT con los pesos de la DIAN
+func CheckDigit(nit string) string {
+	weights := []int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+	sum := 0
+	for i := 0; i < len(nit) && i < len(weights); i++ {
+		d := nit[len(nit)-1-i]
+		if d < '0' || d > '9' {
+			continue
+		}
+		sum += int(d-'0') * weights[i]
+	}
+	r := sum % 11
+	if r > 1 {
+		r = 11 - r
+	}
+	return strconv.Itoa(r)
+}
+
+// SplitNIT separa un NIT escrito como 900123456-7 en número y dígito de verificación;
+// si no trae dígito se calcula
+func SplitNIT(value string) (nit, dv string) {
+	value = strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(value), ".", ""), " ", "")
+	if i := strings.LastIndexByte(value, '-'); i >= 0 {
+		return value[:i], value[i+1:]
+	}
+	return value, CheckDigit(value)
+}
+
+func sha384(parts ...string) string {
+	sum := sha512.Sum384([]byte(strings.Join(parts, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,616 @@
+package dian
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Tipos de documento y de nota
+const (
+	TipoXMLIndividual = "102" // documento soporte de pago de nómina electrónica
+	TipoXMLAdjustment = "103" // nota de ajuste del documento soporte
+
+	NoteReplace = "1" // TipoNota 1: reemplaza el documento predecesor
+	NoteDelete  = "2" // TipoNota 2: elimina el documento predecesor
+)
+
+const (
+	documentVersion   = "V1.0: Documento Soporte de Pago de Nómina Electrónica"
+	adjustmentVersion = "V1.0: Nota de Ajuste de Documento Soporte de Pago de Nómina Electrónica"
+	// periodoMensual es el código de periodo de nómina mensual
+	periodoMensual = "5"
+)
+
+// colombia es la zona horaria con la que se informan fecha y hora de generación
+var colombia = time.FixedZone("COT", -5*60*60)
+
+// Settings son los datos del software de nómina habilitado ante la DIAN
+type Settings struct {
+	Environment  string // 1 producción, 2 pruebas
+	SoftwareID   string
+	SoftwarePIN  string
+	ProviderName string
+	ProviderNIT  string // con o sin dígito de verificación
+	// DepartmentCode y CityCode (DIVIPOLA) son el lugar de generación y de trabajo
+	DepartmentCode string
+	CityCode       string
+}
+
+// Employer es el empleador que emite el documento
+type Employer struct {
+	Name    string
+	NIT     string // con o sin dígito de verificación
+	Address string
+}
+
+// Worker es el trabajador del documento
+type Worker struct {
+	Code           string
+	DocumentType   string // 13 cédula de ciudadanía
+	DocumentNumber string
+	FirstName      string
+	LastName       string
+	ContractType   string // 1 término fijo, 2 indefinido, 3 obra o labor, 4 aprendizaje, 5 prácticas
+	Salary         float64
+	HireDate       time.Time
+	RetirementDate *time.Time
+}
+
+// Payment es la forma en que se pagó la nómina
+type Payment struct {
+	Method        string // código DIAN: 10 efectivo, 20 cheque, 47 transferencia
+	BankName      string
+	AccountType   string
+	AccountNumber string
+}
+
+// Concept es un devengado sin elemento propio en el esquema
+type Concept struct {
+	Description string
+	Amount      float64
+}
+
+// Earnings son los devengados del mes
+type Earnings struct {
+	DaysWorked        int
+	Salary            float64
+	Transport         float64
+	Vacation          float64
+	Prima             float64
+	Severance         float64
+	SeveranceInterest float64
+	Bonuses           []float64
+	Others            []Concept
+	Indemnification   float64
+}
+
+// Total suma los devengados
+func (e Earnings) Total() float64 {
+	total := e.Salary + e.Transport + e.Vacation + e.Prima + e.Severance + e.SeveranceInterest + e.Indemnification
+	for _, b := range e.Bonuses {
+		total += b
+	}
+	for _, o := range e.Others {
+		total += o.Amount
+	}
+	return round(total)
+}
+
+// Deductions son las deducciones del mes
+type Deductions struct {
+	Health      float64
+	Pension     float64
+	Withholding float64
+	Others      []float64
+}
+
+// Total suma las deducciones
+func (d Deductions) Total() float64 {
+	total := d.Health + d.Pension + d.Withholding
+	for _, o := range d.Others {
+		total += o
+	}
+	return round(total)
+}
+
+// Predecessor es el documento que corrige una nota de ajuste
+type Predecessor struct {
+	Number   string
+	CUNE     string
+	IssuedAt time.Time
+}
+
+// Document es un documento soporte de nómina o una nota de ajuste
+type Document struct {
+	TipoXML     string
+	NoteType    string // solo notas de ajuste
+	Prefix      string
+	Consecutive int
+	IssuedAt    time.Time
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Employer    Employer
+	Worker      Worker
+	Payment     Payment
+	PayDates    []time.Time
+	Earnings    Earnings
+	Deductions  Deductions
+	Notes       []string
+	Predecessor *Predecessor
+}
+
+// Number es el número del documento: prefijo y consecutivo
+func (d *Document) Number() string {
+	return d.Prefix + strconv.Itoa(d.Consecutive)
+}
+
+// IsDeletion indica si es una nota que elimina el documento predecesor
+func (d *Document) IsDeletion() bool {
+	return d.TipoXML == TipoXMLAdjustment && d.NoteType == NoteDelete
+}
+
+// Totals retorna devengados, deducciones y total del comprobante; una nota de
+// eliminación no lleva valores
+func (d *Document) Totals() (earnings, deductions, total float64) {
+	if d.IsDeletion() {
+		return 0, 0, 0
+	}
+	earnings, deductions = d.Earnings.Total(), d.Deductions.Total()
+	return earnings, deductions, round(earnings - deductions)
+}
+
+// Built es el XML generado con su CUNE
+type Built struct {
+	XML  []byte
+	CUNE string
+}
+
+// Build genera el XML del documento, calcula su CUNE y lo valida contra el esquema
+func Build(d *Document, settings Settings) (*Built, error) {
+	if d.TipoXML == TipoXMLAdjustment && d.Predecessor == nil {
+		return nil, fmt.Errorf("adjustment note requires a predecessor document")
+	}
+	issued := d.IssuedAt.In(colombia)
+	date, hour := issued.Format("2006-01-02"), issued.Format("15:04:05-07:00")
+	employerNIT, employerDV := SplitNIT(d.Employer.NIT)
+	providerNIT, providerDV := SplitNIT(settings.ProviderNIT)
+	earnings, deductions, total := d.Totals()
+	workerDocument := d.Worker.DocumentNumber
+	if d.IsDeletion() {
+		workerDocument = "0"
+	}
+
+	cune := CUNE(d.Number(), date, hour, earnings, deductions, total, employerNIT, workerDocument,
+		d.TipoXML, settings.SoftwarePIN, settings.Environment)
+
+	header := header{
+		NumeroSecuenciaXML: numeroSecuenciaXML{
+			CodigoTrabajador: d.Worker.Code,
+			Prefijo:          d.Prefix,
+			Consecutivo:      strconv.Itoa(d.Consecutive),
+			Numero:           d.Number(),
+		},
+		LugarGeneracionXML: lugarGeneracionXML{
+			Pais: "CO", DepartamentoEstado: settings.DepartmentCode, MunicipioCiudad: settings.CityCode, Idioma: "es",
+		},
+		ProveedorXML: proveedorXML{
+			RazonSocial: settings.ProviderName,
+			NIT:         providerNIT,
+			DV:          providerDV,
+			SoftwareID:  settings.SoftwareID,
+			SoftwareSC:  SoftwareSecurityCode(settings.SoftwareID, settings.SoftwarePIN, d.Number()),
+		},
+		CodigoQR: qrText(d.Number(), date, hour, employerNIT, workerDocument, earnings, deductions, total, cune, settings.Environment),
+		InformacionGeneral: informacionGeneral{
+			Version:       documentVersion,
+			Ambiente:      settings.Environment,
+			TipoXML:       d.TipoXML,
+			CUNE:          cune,
+			EncripCUNE:    "CUNE-SHA384",
+			FechaGen:      date,
+			HoraGen:       hour,
+			PeriodoNomina: periodoMensual,
+			TipoMoneda:    "COP",
+		},
+		Notas: d.Notes,
+		Empleador: empleador{
+			RazonSocial:        d.Employer.Name,
+			NIT:                employerNIT,
+			DV:                 employerDV,
+			Pais:               "CO",
+			DepartamentoEstado: settings.DepartmentCode,
+			MunicipioCiudad:    settings.CityCode,
+			Direccion:          d.Employer.Address,
+		},
+	}
+	if d.TipoXML == TipoXMLAdjustment {
+		header.InformacionGeneral.Version = adjustmentVersion
+	}
+
+	var root any
+	switch {
+	case d.TipoXML == TipoXMLIndividual:
+		root = &nominaIndividual{soporte: d.soporte(header, date, settings)}
+	case d.IsDeletion():
+		root = &nominaAjuste{
+			TipoNota: NoteDelete,
+			Eliminar: &eliminar{EliminandoPredecesor: d.predecessor(), header: header},
+		}
+	default:
+		root = &nominaAjuste{
+			TipoNota:   NoteReplace,
+			Reemplazar: &reemplazar{ReemplazandoPredecesor: d.predecessor(), soporte: d.soporte(header, date, settings)},
+		}
+	}
+
+	body, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(xml.Header), body...)
+	data = append(data, '\n')
+	if err := Validate(d.TipoXML, data); err != nil {
+		return nil, err
+	}
+	return &Built{XML: data, CUNE: cune}, nil
+}
+
+func (d *Document) predecessor() predecesor {
+	return predecesor{
+		NumeroPred:   d.Predecessor.Number,
+		CUNEPred:     d.Predecessor.CUNE,
+		FechaGenPred: d.Predecessor.IssuedAt.In(colombia).Format("2006-01-02"),
+	}
+}
+
+func (d *Document) soporte(h header, date string, settings Settings) soporte {
+	w := d.Worker
+	lastNames := strings.Fields(w.LastName)
+	firstNames := strings.Fields(w.FirstName)
+	periodo := periodo{
+		FechaIngreso:           w.HireDate.Format("2006-01-02"),
+		FechaLiquidacionInicio: d.PeriodStart.Format("2006-01-02"),
+		FechaLiquidacionFin:    d.PeriodEnd.Format("2006-01-02"),
+		TiempoLaborado:         formatAmount(float64(domain.Days360(w.HireDate, d.PeriodEnd))),
+		FechaGen:               date,
+	}
+	if w.RetirementDate != nil {
+		periodo.FechaRetiro = w.RetirementDate.Format("2006-01-02")
+	}
+	trabajador := trabajador{
+		TipoTrabajador:                 "01",
+		SubTipoTrabajador:              "00",
+		AltoRiesgoPension:              "false",
+		TipoDocumento:                  w.DocumentType,
+		NumeroDocumento:                w.DocumentNumber,
+		PrimerApellido:                 first(lastNames),
+		SegundoApellido:                rest(lastNames),
+		PrimerNombre:                   first(firstNames),
+		OtrosNombres:                   rest(firstNames),
+		LugarTrabajoPais:               "CO",
+		LugarTrabajoDepartamentoEstado: settings.DepartmentCode,
+		LugarTrabajoMunicipioCiudad:    settings.CityCode,
+		LugarTrabajoDireccion:          d.Employer.Address,
+		SalarioIntegral:                "false",
+		TipoContrato:                   w.ContractType,
+		Sueldo:                         formatAmount(w.Salary),
+		CodigoTrabajador:               w.Code,
+	}
+	fechas := make([]string, len(d.PayDates))
+	for i, p := range d.PayDates {
+		fechas[i] = p.Format("2006-01-02")
+	}
+	earnings, deductions, total := d.Totals()
+	return soporte{
+		Periodo:     periodo,
+		header:      h,
+		Trabajador:  trabajador,
+		Pago:        pago{Forma: "1", Metodo: d.Payment.Method, Banco: d.Payment.BankName, TipoCuenta: d.Payment.AccountType, NumeroCuenta: d.Payment.AccountNumber},
+		FechasPagos: fechasPagos{FechaPago: fechas},
+		Devengados:  d.Earnings.xml(),
+		Deducciones: d.Deductions.xml(),
+		Totales:     formatAmount(earnings),
+		Deducidos:   formatAmount(deductions),
+		Comprobante: formatAmount(total),
+	}
+}
+
+func (e Earnings) xml() devengados {
+	out := devengados{
+		Basico: basico{DiasTrabajados: strconv.Itoa(e.DaysWorked), SueldoTrabajado: formatAmount(e.Salary)},
+	}
+	if e.Transport != 0 {
+		out.Transporte = &transporte{AuxilioTransporte: formatAmount(e.Transport)}
+	}
+	if e.Vacation != 0 {
+		out.Vacaciones = &vacaciones{Comunes: pagoCantidad{Pago: formatAmount(e.Vacation)}}
+	}
+	if e.Prima != 0 {
+		out.Primas = &pagoCantidad{Pago: formatAmount(e.Prima)}
+	}
+	if e.Severance != 0 || e.SeveranceInterest != 0 {
+		out.Cesantias = &cesantias{Pago: formatAmount(e.Severance), Porcentaje: "12.00", PagoIntereses: formatAmount(e.SeveranceInterest)}
+	}
+	if len(e.Bonuses) > 0 {
+		out.Bonificaciones = &bonificaciones{}
+		for _, b := range e.Bonuses {
+			out.Bonificaciones.Bonificacion = append(out.Bonificaciones.Bonificacion, bonificacion{BonificacionS: formatAmount(b)})
+		}
+	}
+	if len(e.Others) > 0 {
+		out.OtrosConceptos = &otrosConceptos{}
+		for _, o := range e.Others {
+			out.OtrosConceptos.OtroConcepto = append(out.OtrosConceptos.OtroConcepto,
+				otroConcepto{DescripcionConcepto: o.Description, ConceptoS: formatAmount(o.Amount)})
+		}
+	}
+	if e.Indemnification != 0 {
+		out.Indemnizacion = formatAmount(e.Indemnification)
+	}
+	return out
+}
+
+// Porcentajes legales de aporte del trabajador a salud y pensión
+const (
+	healthPercentage  = "4.00"
+	pensionPercentage = "4.00"
+)
+
+func (d Deductions) xml() deducciones {
+	out := deducciones{
+		Salud:        aporte{Porcentaje: healthPercentage, Deduccion: formatAmount(d.Health)},
+		FondoPension: aporte{Porcentaje: pensionPercentage, Deduccion: formatAmount(d.Pension)},
+	}
+	if d.Pension == 0 {
+		out.FondoPension.Porcentaje = "0.00"
+	}
+	if d.Withholding != 0 {
+		out.RetencionFuente = formatAmount(d.Withholding)
+	}
+	if len(d.Others) > 0 {
+		out.OtrasDeducciones = &otrasDeducciones{}
+		for _, o := range d.Others {
+			out.OtrasDeducciones.OtraDeduccion = append(out.OtrasDeducciones.OtraDeduccion, formatAmount(o))
+		}
+	}
+	return out
+}
+
+func qrText(number, date, hour, nit, document string, earnings, deductions, total float64, cune, environment string) string {
+	host := "catalogo-vpfe-hab.dian.gov.co"
+	if environment == "1" {
+		host = "catalogo-vpfe.dian.gov.co"
+	}
+	return fmt.Sprintf("NumNIE: %s FecNIE: %s HorNIE: %s NitNIE: %s DocEmp: %s ValDev: %s ValDed: %s ValTol: %s CUNE: %s https://%s/document/searchqr?documentkey=%s",
+		number, date, hour, nit, document, formatAmount(earnings), formatAmount(deductions), formatAmount(total), cune, host, cune)
+}
+
+func first(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+func rest(words []string) string {
+	if len(words) < 2 {
+		return ""
+	}
+	return strings.Join(words[1:], " ")
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Estructuras XML del esquema
+
+type nominaIndividual struct {
+	XMLName xml.Name `xml:"dian:gov:co:facturaelectronica:NominaIndividual NominaIndividual"`
+	soporte
+}
+
+type nominaAjuste struct {
+	XMLName    xml.Name    `xml:"dian:gov:co:facturaelectronica:NominaIndividualDeAjuste NominaIndividualDeAjuste"`
+	TipoNota   string      `xml:"TipoNota"`
+	Reemplazar *reemplazar `xml:"Reemplazar,omitempty"`
+	Eliminar   *eliminar   `xml:"Eliminar,omitempty"`
+}
+
+type reemplazar struct {
+	ReemplazandoPredecesor predecesor `xml:"ReemplazandoPredecesor"`
+	soporte
+}
+
+type eliminar struct {
+	EliminandoPredecesor predecesor `xml:"EliminandoPredecesor"`
+	header
+}
+
+type predecesor struct {
+	NumeroPred   string `xml:"NumeroPred,attr"`
+	CUNEPred     string `xml:"CUNEPred,attr"`
+	FechaGenPred string `xml:"FechaGenPred,attr"`
+}
+
+type header struct {
+	NumeroSecuenciaXML numeroSecuenciaXML `xml:"NumeroSecuenciaXML"`
+	LugarGeneracionXML lugarGeneracionXML `xml:"LugarGeneracionXML"`
+	ProveedorXML       proveedorXML       `xml:"ProveedorXML"`
+	CodigoQR           string             `xml:"CodigoQR"`
+	InformacionGeneral informacionGeneral `xml:"InformacionGeneral"`
+	Notas              []string           `xml:"Notas"`
+	Empleador          empleador          `xml:"Empleador"`
+}
+
+type soporte struct {
+	Periodo periodo `xml:"Periodo"`
+	header
+	Trabajador  trabajador  `xml:"Trabajador"`
+	Pago        pago        `xml:"Pago"`
+	FechasPagos fechasPagos `xml:"FechasPagos"`
+	Devengados  devengados  `xml:"Devengados"`
+	Deducciones deducciones `xml:"Deducciones"`
+	Totales     string      `xml:"DevengadosTotal"`
+	Deducidos   string      `xml:"DeduccionesTotal"`
+	Comprobante string      `xml:"ComprobanteTotal"`
+}
+
+type periodo struct {
+	FechaIngreso           string `xml:"FechaIngreso,attr"`
+	FechaRetiro            string `xml:"FechaRetiro,attr,omitempty"`
+	FechaLiquidacionInicio string `xml:"FechaLiquidacionInicio,attr"`
+	FechaLiquidacionFin    string `xml:"FechaLiquidacionFin,attr"`
+	TiempoLaborado         string `xml:"TiempoLaborado,attr"`
+	FechaGen               string `xml:"FechaGen,attr"`
+}
+
+type numeroSecuenciaXML struct {
+	CodigoTrabajador string `xml:"CodigoTrabajador,attr,omitempty"`
+	Prefijo          string `xml:"Prefijo,attr"`
+	Consecutivo      string `xml:"Consecutivo,attr"`
+	Numero           string `xml:"Numero,attr"`
+}
+
+type lugarGeneracionXML struct {
+	Pais               string `xml:"Pais,attr"`
+	DepartamentoEstado string `xml:"DepartamentoEstado,attr"`
+	MunicipioCiudad    string `xml:"MunicipioCiudad,attr"`
+	Idioma             string `xml:"Idioma,attr"`
+}
+
+type proveedorXML struct {
+	RazonSocial string `xml:"RazonSocial,attr"`
+	NIT         string `xml:"NIT,attr"`
+	DV          string `xml:"DV,attr"`
+	SoftwareID  string `xml:"SoftwareID,attr"`
+	SoftwareSC  string `xml:"SoftwareSC,attr"`
+}
+
+type informacionGeneral struct {
+	Version       string `xml:"Version,attr"`
+	Ambiente      string `xml:"Ambiente,attr"`
+	TipoXML       string `xml:"TipoXML,attr"`
+	CUNE          string `xml:"CUNE,attr"`
+	EncripCUNE    string `xml:"EncripCUNE,attr"`
+	FechaGen      string `xml:"FechaGen,attr"`
+	HoraGen       string `xml:"HoraGen,attr"`
+	PeriodoNomina string `xml:"PeriodoNomina,attr"`
+	TipoMoneda    string `xml:"TipoMoneda,attr"`
+}
+
+type empleador struct {
+	RazonSocial        string `xml:"RazonSocial,attr"`
+	NIT                string `xml:"NIT,attr"`
+	DV                 string `xml:"DV,attr"`
+	Pais               string `xml:"Pais,attr"`
+	DepartamentoEstado string `xml:"DepartamentoEstado,attr"`
+	MunicipioCiudad    string `xml:"MunicipioCiudad,attr"`
+	Direccion          string `xml:"Direccion,attr"`
+}
+
+type trabajador struct {
+	TipoTrabajador                 string `xml:"TipoTrabajador,attr"`
+	SubTipoTrabajador              string `xml:"SubTipoTrabajador,attr"`
+	AltoRiesgoPension              string `xml:"AltoRiesgoPension,attr"`
+	TipoDocumento                  string `xml:"TipoDocumento,attr"`
+	NumeroDocumento                string `xml:"NumeroDocumento,attr"`
+	PrimerApellido                 string `xml:"PrimerApellido,attr"`
+	SegundoApellido                string `xml:"SegundoApellido,attr,omitempty"`
+	PrimerNombre                   string `xml:"PrimerNombre,attr"`
+	OtrosNombres                   string `xml:"OtrosNombres,attr,omitempty"`
+	LugarTrabajoPais               string `xml:"LugarTrabajoPais,attr"`
+	LugarTrabajoDepartamentoEstado string `xml:"LugarTrabajoDepartamentoEstado,attr"`
+	LugarTrabajoMunicipioCiudad    string `xml:"LugarTrabajoMunicipioCiudad,attr"`
+	LugarTrabajoDireccion          string `xml:"LugarTrabajoDireccion,attr"`
+	SalarioIntegral                string `xml:"SalarioIntegral,attr"`
+	TipoContrato                   string `xml:"TipoContrato,attr"`
+	Sueldo                         string `xml:"Sueldo,attr"`
+	CodigoTrabajador               string `xml:"CodigoTrabajador,attr,omitempty"`
+}
+
+type pago struct {
+	Forma        string `xml:"Forma,attr"`
+	Metodo       string `xml:"Metodo,attr"`
+	Banco        string `xml:"Banco,attr,omitempty"`
+	TipoCuenta   string `xml:"TipoCuenta,attr,omitempty"`
+	NumeroCuenta string `xml:"NumeroCuenta,attr,omitempty"`
+}
+
+type fechasPagos struct {
+	FechaPago []string `xml:"FechaPago"`
+}
+
+type devengados struct {
+	Basico         basico          `xml:"Basico"`
+	Transporte     *transporte     `xml:"Transporte,omitempty"`
+	Vacaciones     *vacaciones     `xml:"Vacaciones,omitempty"`
+	Primas         *pagoCantidad   `xml:"Primas,omitempty"`
+	Cesantias      *cesantias      `xml:"Cesantias,omitempty"`
+	Bonificaciones *bonificaciones `xml:"Bonificaciones,omitempty"`
+	OtrosConceptos *otrosConceptos `xml:"OtrosConceptos,omitempty"`
+	Indemnizacion  string          `xml:"Indemnizacion,omitempty"`
+}
+
+type basico struct {
+	DiasTrabajados  string `xml:"DiasTrabajados,attr"`
+	SueldoTrabajado string `xml:"SueldoTrabajado,attr"`
+}
+
+type transporte struct {
+	AuxilioTransporte string `xml:"AuxilioTransporte,attr"`
+}
+
+type vacaciones struct {
+	Comunes pagoCantidad `xml:"VacacionesComunes"`
+}
+
+type pagoCantidad struct {
+	Cantidad string `xml:"Cantidad,attr,omitempty"`
+	Pago     string `xml:"Pago,attr"`
+}
+
+type cesantias struct {
+	Pago          string `xml:"Pago,attr"`
+	Porcentaje    string `xml:"Porcentaje,attr"`
+	PagoIntereses string `xml:"PagoIntereses,attr"`
+}
+
+type bonificaciones struct {
+	Bonificacion []bonificacion `xml:"Bonificacion"`
+}
+
+type bonificacion struct {
+	BonificacionS string `xml:"BonificacionS,attr,omitempty"`
+}
+
+type otrosConceptos struct {
+	OtroConcepto []otroConcepto `xml:"OtroConcepto"`
+}
+
+type otroConcepto struct {
+	DescripcionConcepto string `xml:"DescripcionConcepto,attr"`
+	ConceptoS           string `xml:"ConceptoS,attr,omitempty"`
+}
+
+type deducciones struct {
+	Salud            aporte            `xml:"Salud"`
+	FondoPension     aporte            `xml:"FondoPension"`
+	RetencionFuente  string            `xml:"RetencionFuente,omitempty"`
+	OtrasDeducciones *otrasDeducciones `xml:"OtrasDeducciones,omitempty"`
+}
+
+type aporte struct {
+	Porcentaje string `xml:"Porcentaje,attr"`
+	Deduccion  string `xml:"Deduccion,attr"`
+}
+
+type otrasDeducciones struct {
+	OtraDeduccion []string `xml:"OtraDeduccion"`
+}
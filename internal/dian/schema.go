@@ -0,0 +1,42 @@
+package dian
+
+import (
+	"embed"
+	"fmt"
+	"sync"
+)
+
+//go:embed schema/*.xsd
+var schemaFS embed.FS
+
+var (
+	schemasOnce sync.Once
+	schemas     map[string]*Schema
+	schemasErr  error
+)
+
+// Validate valida el XML de un documento contra el esquema incluido de su tipo
+func Validate(tipoXML string, data []byte) error {
+	schemasOnce.Do(func() {
+		schemas = map[string]*Schema{}
+		for kind, file := range map[string]string{
+			TipoXMLIndividual: "schema/NominaIndividual.xsd",
+			TipoXMLAdjustment: "schema/NominaIndividualDeAjuste.xsd",
+		} {
+			s, err := LoadSchema(schemaFS, file)
+			if err != nil {
+				schemasErr = err
+				return
+			}
+			schemas[kind] = s
+		}
+	})
+	if schemasErr != nil {
+		return schemasErr
+	}
+	s, ok := schemas[tipoXML]
+	if !ok {
+		return fmt.Errorf("unknown document type %q", tipoXML)
+	}
+	return s.Validate(data)
+}
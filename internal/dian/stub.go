@@ -0,0 +1,49 @@
+package dian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// StubClient simula el servicio web de la DIAN: valida el XML contra el esquema y acepta
+// los documentos válidos. Sirve para desarrollo y pruebas sin certificado de firma.
+type StubClient struct{}
+
+func NewStubClient() *StubClient {
+	return &StubClient{}
+}
+
+func (c *StubClient) Name() string {
+	return "stub"
+}
+
+func (c *StubClient) Send(ctx context.Context, document *domain.ElectronicPayrollDocument) (domain.ElectronicPayrollResult, error) {
+	if len(document.XML) == 0 {
+		return domain.ElectronicPayrollResult{}, errors.New("document has no xml")
+	}
+	if err := Validate(document.DocumentType, document.XML); err != nil {
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			return domain.ElectronicPayrollResult{Messages: invalid.Problems}, nil
+		}
+		return domain.ElectronicPayrollResult{}, err
+	}
+	return domain.ElectronicPayrollResult{
+		TrackID:  document.CUNE,
+		Accepted: true,
+		Messages: []string{"Procesado Correctamente."},
+	}, nil
+}
+
+// NewClient crea el cliente de transmisión configurado
+func NewClient(name string) (domain.ElectronicPayrollClient, error) {
+	switch strings.ToLower(name) {
+	case "", "stub":
+		return NewStubClient(), nil
+	}
+	return nil, fmt.Errorf("unknown dian client %q", name)
+}
@@ -0,0 +1,492 @@
+package dian
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxProblems limita los errores que se reportan de un documento
+const maxProblems = 20
+
+// Schema es un esquema XSD cargado para validar documentos. Cubre el subconjunto que usan
+// los esquemas de nómina: element, complexType con sequence/choice/group, attribute y
+// simpleType con restricciones enumeration, pattern, minLength, maxLength y fractionDigits.
+// Los nombres se comparan sin prefijo; el espacio de nombres solo se verifica en la raíz.
+type Schema struct {
+	targetNamespace string
+	elements        map[string]*xsdNode
+	complexTypes    map[string]*xsdNode
+	simpleTypes     map[string]*xsdNode
+	groups          map[string]*xsdNode
+	patterns        map[string]*regexp.Regexp
+}
+
+// ValidationError reúne las diferencias entre un documento y el esquema
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "xml does not match schema: " + strings.Join(e.Problems, "; ")
+}
+
+type xsdNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xsdNode
+}
+
+func (n *xsdNode) child(name string) *xsdNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// xmlNode es un elemento del documento a validar
+type xmlNode struct {
+	name     string
+	space    string
+	attrs    map[string]string
+	children []*xmlNode
+	text     string
+}
+
+// LoadSchema carga el esquema name de fsys resolviendo sus xs:include
+func LoadSchema(fsys fs.FS, name string) (*Schema, error) {
+	s := &Schema{
+		elements:     map[string]*xsdNode{},
+		complexTypes: map[string]*xsdNode{},
+		simpleTypes:  map[string]*xsdNode{},
+		groups:       map[string]*xsdNode{},
+		patterns:     map[string]*regexp.Regexp{},
+	}
+	root, err := s.load(fsys, name, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	s.targetNamespace = root.attrs["targetNamespace"]
+	return s, nil
+}
+
+func (s *Schema) load(fsys fs.FS, name string, loaded map[string]bool) (*xsdNode, error) {
+	loaded[name] = true
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseXSD(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for _, c := range root.children {
+		switch c.name {
+		case "include":
+			include := path.Join(path.Dir(name), c.attrs["schemaLocation"])
+			if !loaded[include] {
+				if _, err := s.load(fsys, include, loaded); err != nil {
+					return nil, err
+				}
+			}
+		case "element":
+			s.elements[c.attrs["name"]] = c
+		case "complexType":
+			s.complexTypes[c.attrs["name"]] = c
+		case "simpleType":
+			s.simpleTypes[c.attrs["name"]] = c
+		case "group":
+			s.groups[c.attrs["name"]] = c
+		}
+	}
+	return root, nil
+}
+
+func parseXSD(data []byte) (*xsdNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xsdNode
+	var root *xsdNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xsdNode{name: t.Name.Local, attrs: map[string]string{}}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if root == nil || root.name != "schema" {
+		return nil, fmt.Errorf("not an xml schema")
+	}
+	return root, nil
+}
+
+func parseInstance(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, space: t.Name.Space, attrs: map[string]string{}}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || a.Name.Space == "http://www.w3.org/2001/XMLSchema-instance" {
+					continue
+				}
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return root, nil
+}
+
+// Validate verifica que el documento cumpla el esquema
+func (s *Schema) Validate(data []byte) error {
+	doc, err := parseInstance(data)
+	if err != nil {
+		return &ValidationError{Problems: []string{"malformed xml: " + err.Error()}}
+	}
+	v := &validator{schema: s}
+	decl, ok := s.elements[doc.name]
+	switch {
+	case !ok:
+		v.fail("/"+doc.name, "unexpected root element")
+	case doc.space != s.targetNamespace:
+		v.fail("/"+doc.name, fmt.Sprintf("namespace %q, expected %q", doc.space, s.targetNamespace))
+	default:
+		v.element(doc, decl, "/"+doc.name)
+	}
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	schema   *Schema
+	problems []string
+}
+
+func (v *validator) fail(at, msg string) {
+	if len(v.problems) < maxProblems {
+		v.problems = append(v.problems, at+": "+msg)
+	}
+}
+
+// element valida un elemento contra su declaración
+func (v *validator) element(n *xmlNode, decl *xsdNode, at string) {
+	if complexType := v.complexTypeOf(decl); complexType != nil {
+		v.complex(n, complexType, at)
+		return
+	}
+	if len(n.children) > 0 {
+		v.fail(at, "unexpected child elements")
+	}
+	if len(n.attrs) > 0 {
+		v.fail(at, "unexpected attributes")
+	}
+	v.simpleValue(strings.TrimSpace(n.text), decl, at)
+}
+
+func (v *validator) complexTypeOf(decl *xsdNode) *xsdNode {
+	if inline := decl.child("complexType"); inline != nil {
+		return inline
+	}
+	return v.schema.complexTypes[localName(decl.attrs["type"])]
+}
+
+func (v *validator) complex(n *xmlNode, complexType *xsdNode, at string) {
+	// Atributos declarados
+	declared := map[string]bool{}
+	for _, a := range complexType.children {
+		if a.name != "attribute" {
+			continue
+		}
+		name := a.attrs["name"]
+		declared[name] = true
+		value, ok := n.attrs[name]
+		if !ok {
+			if a.attrs["use"] == "required" {
+				v.fail(at, "missing attribute "+name)
+			}
+			continue
+		}
+		v.simpleValue(value, a, at+"/@"+name)
+	}
+	for name := range n.attrs {
+		if !declared[name] {
+			v.fail(at, "unexpected attribute "+name)
+		}
+	}
+
+	// Contenido
+	var particle *xsdNode
+	for _, c := range complexType.children {
+		if c.name == "sequence" || c.name == "choice" || c.name == "group" {
+			particle = c
+		}
+	}
+	if particle == nil {
+		if len(n.children) > 0 {
+			v.fail(at, "unexpected child elements")
+		}
+		return
+	}
+	pos := v.particle(particle, n.children, 0, at)
+	for _, extra := range n.children[pos:] {
+		v.fail(at, "unexpected element "+extra.name)
+	}
+}
+
+// particle consume los hijos desde pos según sequence, choice, group o element y retorna
+// la posición siguiente. La coincidencia es voraz, suficiente para esquemas deterministas.
+func (v *validator) particle(p *xsdNode, children []*xmlNode, pos int, at string) int {
+	switch p.name {
+	case "sequence":
+		for _, c := range p.children {
+			pos = v.particle(c, children, pos, at)
+		}
+	case "group":
+		group := v.schema.groups[localName(p.attrs["ref"])]
+		if group == nil {
+			v.fail(at, "unknown group "+p.attrs["ref"])
+			return pos
+		}
+		for _, c := range group.children {
+			pos = v.particle(c, children, pos, at)
+		}
+	case "choice":
+		if pos < len(children) {
+			for _, option := range p.children {
+				if v.startsWith(option, children[pos].name) {
+					return v.particle(option, children, pos, at)
+				}
+			}
+		}
+		if minOccurs(p) > 0 {
+			names := make([]string, 0, len(p.children))
+			for _, option := range p.children {
+				names = append(names, option.attrs["name"])
+			}
+			v.fail(at, "expected one of "+strings.Join(names, ", "))
+		}
+	case "element":
+		name := p.attrs["name"]
+		max := maxOccurs(p)
+		count := 0
+		for pos < len(children) && children[pos].name == name && (max < 0 || count < max) {
+			v.element(children[pos], p, fmt.Sprintf("%s/%s", at, name))
+			pos++
+			count++
+		}
+		if count < minOccurs(p) {
+			v.fail(at, "missing element "+name)
+		}
+	}
+	return pos
+}
+
+// startsWith indica si la partícula puede empezar con el elemento name
+func (v *validator) startsWith(p *xsdNode, name string) bool {
+	switch p.name {
+	case "element":
+		return p.attrs["name"] == name
+	case "sequence":
+		return len(p.children) > 0 && v.startsWith(p.children[0], name)
+	case "group":
+		group := v.schema.groups[localName(p.attrs["ref"])]
+		return group != nil && len(group.children) > 0 && v.startsWith(group.children[0], name)
+	}
+	return false
+}
+
+// simpleValue valida un valor contra el tipo simple de una declaración de elemento o atributo
+func (v *validator) simpleValue(value string, decl *xsdNode, at string) {
+	if inline := decl.child("simpleType"); inline != nil {
+		v.restriction(value, inline, at)
+		return
+	}
+	v.typed(value, decl.attrs["type"], at)
+}
+
+func (v *validator) typed(value, typeName, at string) {
+	if typeName == "" {
+		return
+	}
+	if strings.HasPrefix(typeName, "xs:") {
+		if msg := checkBuiltin(value, strings.TrimPrefix(typeName, "xs:")); msg != "" {
+			v.fail(at, msg)
+		}
+		return
+	}
+	simpleType := v.schema.simpleTypes[localName(typeName)]
+	if simpleType == nil {
+		v.fail(at, "unknown type "+typeName)
+		return
+	}
+	v.restriction(value, simpleType, at)
+}
+
+func (v *validator) restriction(value string, simpleType *xsdNode, at string) {
+	r := simpleType.child("restriction")
+	if r == nil {
+		return
+	}
+	v.typed(value, r.attrs["base"], at)
+
+	var enumeration []string
+	for _, facet := range r.children {
+		limit := facet.attrs["value"]
+		switch facet.name {
+		case "enumeration":
+			enumeration = append(enumeration, limit)
+		case "pattern":
+			re, err := v.schema.pattern(limit)
+			if err != nil {
+				v.fail(at, "invalid pattern "+limit)
+			} else if !re.MatchString(value) {
+				v.fail(at, fmt.Sprintf("value %q does not match %s", value, limit))
+			}
+		case "minLength":
+			if n, _ := strconv.Atoi(limit); utf8.RuneCountInString(value) < n {
+				v.fail(at, fmt.Sprintf("value shorter than %d characters", n))
+			}
+		case "maxLength":
+			if n, _ := strconv.Atoi(limit); utf8.RuneCountInString(value) > n {
+				v.fail(at, fmt.Sprintf("value longer than %d characters", n))
+			}
+		case "fractionDigits":
+			n, _ := strconv.Atoi(limit)
+			if i := strings.IndexByte(value, '.'); i >= 0 && len(value)-i-1 > n {
+				v.fail(at, fmt.Sprintf("value %q has more than %d decimals", value, n))
+			}
+		}
+	}
+	if len(enumeration) > 0 {
+		for _, e := range enumeration {
+			if e == value {
+				return
+			}
+		}
+		v.fail(at, fmt.Sprintf("value %q not in [%s]", value, strings.Join(enumeration, ", ")))
+	}
+}
+
+// pattern compila un patrón XSD, que siempre aplica al valor completo
+func (s *Schema) pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[p]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile("^(?:" + p + ")$")
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[p] = re
+	return re, nil
+}
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+)
+
+func checkBuiltin(value, typeName string) string {
+	switch typeName {
+	case "decimal":
+		if !decimalPattern.MatchString(value) {
+			return fmt.Sprintf("value %q is not a decimal", value)
+		}
+	case "integer":
+		if !integerPattern.MatchString(value) {
+			return fmt.Sprintf("value %q is not an integer", value)
+		}
+	case "boolean":
+		switch value {
+		case "true", "false", "1", "0":
+		default:
+			return fmt.Sprintf("value %q is not a boolean", value)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Sprintf("value %q is not a date", value)
+		}
+	}
+	return ""
+}
+
+func minOccurs(n *xsdNode) int {
+	if v, ok := n.attrs["minOccurs"]; ok {
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 1
+}
+
+// maxOccurs retorna -1 para unbounded
+func maxOccurs(n *xsdNode) int {
+	v, ok := n.attrs["maxOccurs"]
+	if !ok {
+		return 1
+	}
+	if v == "unbounded" {
+		return -1
+	}
+	i, _ := strconv.Atoi(v)
+	return i
+}
+
+func localName(qname string) string {
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
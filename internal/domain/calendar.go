@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// Days360 cuenta los días entre dos fechas, inclusive, en base 360 como se liquida la nómina
+func Days360(from, to time.Time) int {
+	d1, d2 := min(from.Day(), 30), min(to.Day(), 30)
+	days := (to.Year()-from.Year())*360 + (int(to.Month())-int(from.Month()))*30 + d2 - d1 + 1
+	return max(days, 0)
+}
@@ -26,6 +26,8 @@ var (
 var (
 	ErrPermissionNotFound = errors.New("permission not found")
 	ErrActionNotFound     = errors.New("action not found")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrInvalidDataScope   = errors.New("data scope must be all, own_reports or own_department")
 )
 
 // Errores de Nómina
@@ -36,12 +38,196 @@ var (
 	ErrPayrollAlreadyPaid       = errors.New("payroll already paid")
 	ErrConceptNotFound          = errors.New("payroll concept not found")
 	ErrInvalidPeriod            = errors.New("invalid period: end date must be after start date")
+	ErrActorRequired            = errors.New("user id is required for this operation")
+	ErrSameUserApproval         = errors.New("payroll must be approved by a different user than the one who calculated it")
+	ErrCalculatorUnknown        = errors.New("payroll has no recorded calculator: recalculate it before approving")
+	ErrInvalidPayrollStatus     = errors.New("invalid payroll status")
+	ErrPayrollNotPaid           = errors.New("only paid payrolls can be reversed")
+	ErrPayrollReversed          = errors.New("payroll has been reversed")
+	ErrPayrollNotRecalculable   = errors.New("payroll cannot be recalculated in its current status")
+	ErrPayrollNotDeletable      = errors.New("only draft or calculated payrolls can be deleted")
+)
+
+// Errores de contratos
+var (
+	ErrContractOverlap      = errors.New("contract dates overlap an existing contract")
+	ErrInvalidContractDates = errors.New("contract end date must be on or after its start date")
+	ErrContractNotActive    = errors.New("only the active contract can be changed")
+	ErrContractNotRenewable = errors.New("only fixed-term contracts with an end date can be renewed")
+	ErrEmployeeInactive     = errors.New("employee is not active")
+
+	ErrContractTypeNotFound        = errors.New("contract type not found")
+	ErrContractTypeInactive        = errors.New("contract type is not active")
+	ErrContractEndDateRequired     = errors.New("contract type requires an end date")
+	ErrSocialSecurityProofRequired = errors.New("contract type requires social security proof")
+)
+
+// Errores de estructura organizacional
+var (
+	ErrDepartmentNotFound           = errors.New("department not found")
+	ErrPositionNotFound             = errors.New("position not found")
+	ErrDepartmentCycle              = errors.New("department cannot be its own ancestor")
+	ErrDepartmentHasActiveEmployees = errors.New("department still has active employees")
+	ErrDepartmentHasChildren        = errors.New("department still has sub-departments")
+	ErrInvalidSalaryBand            = errors.New("salary band must satisfy 0 < min <= mid <= max with a currency")
+	ErrSalaryOutOfBand              = errors.New("salary is outside the position salary band")
+	ErrManagerCycle                 = errors.New("employee cannot report to itself or to one of its reports")
+)
+
+// Errores de cuentas bancarias y pagos
+var (
+	ErrBankAccountNotFound    = errors.New("bank account not found")
+	ErrBankNameRequired       = errors.New("bank name is required")
+	ErrInvalidAccountType     = errors.New("account type must be savings or checking")
+	ErrInvalidAccountNumber   = errors.New("account number does not match the bank format")
+	ErrInvalidPaymentSplit    = errors.New("bank account split percentages cannot exceed 100")
+	ErrNoBankAccount          = errors.New("employee has no active bank account")
+	ErrNoPrimaryBankAccount   = errors.New("employee has no primary bank account")
+	ErrPrimaryAccountInactive = errors.New("primary bank account cannot be deactivated while others are active")
+	ErrInvalidPaymentAmount   = errors.New("payment amount must be greater than 0")
+	ErrPaymentExceedsNetPay   = errors.New("payments cannot exceed the payroll net pay")
+	ErrPaymentNotFound        = errors.New("payment not found")
+	ErrInvalidPaymentStatus   = errors.New("invalid payment status change")
+
+	ErrInvalidCallbackSignature = errors.New("invalid payment callback signature")
+)
+
+// Errores de archivos bancarios
+var (
+	ErrBankFileNotFound         = errors.New("bank file not found")
+	ErrBankFileTemplateNotFound = errors.New("bank file template not found")
+	ErrInvalidBankFileFormat    = errors.New("invalid bank file format")
+	ErrInvalidBankFileTemplate  = errors.New("invalid bank file template")
+	ErrNoPayrollsToExport       = errors.New("no approved or partially paid payrolls to export in the period")
+	ErrDebtorAccountRequired    = errors.New("debtor name and account are required")
+)
+
+// Errores del desprendible de nómina
+var (
+	ErrPayslipTemplateNotFound = errors.New("payslip template not found")
+	ErrPayslipNotAvailable     = errors.New("payslip is only available for calculated, approved or paid payrolls")
+	ErrInvalidPayslipTemplate  = errors.New("invalid payslip template")
+	ErrInvalidPayslipLogo      = errors.New("logo must be a PNG or JPEG image up to 512 KB")
+)
+
+// Errores de notificaciones
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrMailerNotConfigured  = errors.New("mailer is not configured")
+)
+
+// Errores de nómina electrónica
+var (
+	ErrElectronicPayrollNotFound       = errors.New("electronic payroll document not found")
+	ErrInvalidElectronicPayrollPeriod  = errors.New("invalid electronic payroll period: year and month 1-12 are required")
+	ErrEmployerDataRequired            = errors.New("employer name and NIT are required: set them in the payslip template")
+	ErrInvalidElectronicPayrollXML     = errors.New("electronic payroll xml does not match the schema")
+	ErrElectronicPayrollNotSubmittable = errors.New("only generated or rejected documents can be submitted")
+)
+
+// Errores de ausencias y planilla PILA
+var (
+	ErrAbsenceNotFound    = errors.New("absence not found")
+	ErrInvalidAbsenceKind = errors.New("absence kind must be vacation, paid_leave, unpaid_leave, sick_leave, maternity or work_accident")
+	ErrInvalidAbsenceDate = errors.New("absence end date must be on or after its start date")
+	ErrAbsenceOverlap     = errors.New("absence dates overlap an existing absence")
+	ErrInvalidPILAPeriod  = errors.New("invalid pila period: year and month 1-12 are required")
+	ErrNoPayrollsForPILA  = errors.New("no payrolls to report in the period")
+	ErrPILAHasErrors      = errors.New("pila file has validation errors")
+)
+
+// Errores de contabilización de nómina
+var (
+	ErrInvalidJournalPeriod   = errors.New("invalid journal period: start and end dates are required")
+	ErrNoPayrollsForJournal   = errors.New("no payrolls to post in the period")
+	ErrConceptAccountsMissing = errors.New("payroll concepts without ledger accounts")
+	ErrJournalUnbalanced      = errors.New("journal entry is not balanced: debits must equal credits")
+	ErrInvalidJournalFormat   = errors.New("journal format must be csv or json")
+)
+
+// Errores del libro de nómina
+var (
+	ErrInvalidRegisterFilter = errors.New("payroll register requires period_start and period_end or payroll_ids")
+	ErrInvalidRegisterFormat = errors.New("payroll register format must be csv or xlsx")
+	ErrNoPayrollsForRegister = errors.New("no payrolls match the register filter")
+)
+
+// Errores de acumulados y certificado de ingresos y retenciones
+var (
+	ErrInvalidCertificateYear = errors.New("invalid certificate year")
+	ErrNoIncomeForCertificate = errors.New("employee has no payments in the certificate year")
+)
+
+// Errores de centros de costo
+var (
+	ErrCostCenterNotFound      = errors.New("cost center not found")
+	ErrCostCenterInactive      = errors.New("cost center is inactive")
+	ErrInvalidCostAllocation   = errors.New("cost center allocation percentages must be positive, unique per cost center and add up to 100")
+	ErrCostAllocationNotFound  = errors.New("cost center allocation not found")
+	ErrInvalidCostReportFormat = errors.New("invalid cost report format: use csv or json")
+)
+
+// Errores de conciliación bancaria
+var (
+	ErrStatementNotFound        = errors.New("bank statement not found")
+	ErrStatementLineNotFound    = errors.New("bank statement line not found")
+	ErrStatementAlreadyImported = errors.New("bank statement was already imported")
+	ErrStatementLineResolved    = errors.New("bank statement line is already reconciled")
+	ErrPaymentAlreadyReconciled = errors.New("payment is already reconciled")
+)
+
+// Errores de onboarding
+var (
+	ErrOnboardingInvalid = errors.New("onboarding request has invalid fields")
+)
+
+// Errores de terminación de contrato
+var (
+	ErrEmployeeTerminated      = errors.New("employee is already terminated")
+	ErrTerminationNotFound     = errors.New("termination not found")
+	ErrInvalidTerminationDate  = errors.New("termination date must be on or after the contract start date")
+	ErrOpenPayrollPending      = errors.New("employee has open payrolls; pay or cancel them before terminating")
+	ErrSettlementAlreadySigned = errors.New("settlement document is already signed")
+)
+
+// Errores de periodos contables
+var (
+	ErrPeriodNotFound = errors.New("accounting period not found")
+	ErrPeriodClosed   = errors.New("accounting period is closed")
+	ErrPeriodLocked   = errors.New("accounting period is locked and cannot be reopened")
+	ErrPeriodOverlap  = errors.New("accounting period overlaps an existing period")
 )
 
 // ContextKey for tenant
 type contextKey string
 
-const TenantIDKey contextKey = "tenant_id"
+const (
+	TenantIDKey contextKey = "tenant_id"
+	UserIDKey   contextKey = "user_id"
+	// EmployeeScopeKey guarda el *EmployeeScope con que se filtran los listados
+	EmployeeScopeKey contextKey = "employee_scope"
+)
+
+// EmployeeScope restringe las consultas a los empleados indicados; sin scope en el
+// contexto no se aplica ningún filtro
+type EmployeeScope struct {
+	EmployeeIDs []uint
+}
+
+// Allows indica si el empleado está dentro del alcance
+func (s *EmployeeScope) Allows(employeeID uint) bool {
+	for _, id := range s.EmployeeIDs {
+		if id == employeeID {
+			return true
+		}
+	}
+	return false
+}
+
+// TxManager ejecuta operaciones de varios repositorios en una misma transacción
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
 
 type UserRepo interface {
 	Create(ctx context.Context, usr *User) error
@@ -61,7 +247,8 @@ type RoleRepo interface {
 	Delete(ctx context.Context, roleID uint) error
 
 	// Gestión de permisos
-	AssignPermission(ctx context.Context, roleID, actionID uint) error
+	// AssignPermission otorga la acción al rol con el alcance indicado; si ya existe actualiza el alcance
+	AssignPermission(ctx context.Context, roleID, actionID uint, scope string) error
 	RevokePermission(ctx context.Context, roleID, actionID uint) error
 	GetPermissions(ctx context.Context, roleID uint) ([]PermissionAction, error)
 }
@@ -91,6 +278,10 @@ type EmployeeRepo interface {
 	GetByUserID(ctx context.Context, userID uint) (*Employee, error)
 	List(ctx context.Context, page, limit int) ([]Employee, int64, error)
 	ListActive(ctx context.Context, page, limit int) ([]Employee, int64, error)
+	// ListByManagers retorna los subordinados directos de los jefes indicados
+	ListByManagers(ctx context.Context, managerIDs []uint) ([]Employee, error)
+	// ListByDepartment retorna los empleados del departamento, activos o no
+	ListByDepartment(ctx context.Context, departmentID uint) ([]Employee, error)
 	Update(ctx context.Context, emp *Employee) error
 	Delete(ctx context.Context, id uint) error
 }
@@ -98,6 +289,9 @@ type EmployeeRepo interface {
 type PayrollRepo interface {
 	Create(ctx context.Context, payroll *Payroll) error
 	GetByID(ctx context.Context, id uint) (*Payroll, error)
+	// LockByID bloquea (FOR UPDATE) la nómina; se usa dentro de una transacción para
+	// serializar los pagos sobre el mismo saldo
+	LockByID(ctx context.Context, id uint) (*Payroll, error)
 	GetByEmployeeAndPeriod(ctx context.Context, employeeID uint, periodStart, periodEnd time.Time) (*Payroll, error)
 	GetByPeriod(ctx context.Context, periodStart, periodEnd time.Time) ([]Payroll, error)
 	ListByEmployee(ctx context.Context, employeeID uint) ([]Payroll, error)
@@ -105,6 +299,15 @@ type PayrollRepo interface {
 	Delete(ctx context.Context, id uint) error
 }
 
+// PayrollRegisterRepo lee las nóminas del libro de nómina por lotes para no cargar todo el
+// periodo en memoria
+type PayrollRegisterRepo interface {
+	// ListConcepts retorna los conceptos (tipo, código y nombre) presentes en las nóminas del filtro
+	ListConcepts(ctx context.Context, filter PayrollRegisterFilter) ([]PayrollItem, error)
+	// Stream recorre las nóminas del filtro en lotes con empleado, departamento, cargo e items
+	Stream(ctx context.Context, filter PayrollRegisterFilter, batchSize int, fn func([]Payroll) error) error
+}
+
 type PayrollConceptRepo interface {
 	Create(ctx context.Context, concept *PayrollConcept) error
 	GetByID(ctx context.Context, id uint) (*PayrollConcept, error)
@@ -115,6 +318,20 @@ type PayrollConceptRepo interface {
 	Delete(ctx context.Context, id uint) error
 }
 
+type EmployeeConceptRepo interface {
+	CreateBatch(ctx context.Context, concepts []EmployeeConcept) error
+	ListByEmployee(ctx context.Context, employeeID uint) ([]EmployeeConcept, error)
+}
+
+type EmployeeBankAccountRepo interface {
+	Create(ctx context.Context, account *EmployeeBankAccount) error
+	GetByID(ctx context.Context, id uint) (*EmployeeBankAccount, error)
+	ListByEmployee(ctx context.Context, employeeID uint) ([]EmployeeBankAccount, error)
+	Update(ctx context.Context, account *EmployeeBankAccount) error
+	// ClearPrimary quita la marca de principal a las demás cuentas del empleado
+	ClearPrimary(ctx context.Context, employeeID, exceptID uint) error
+}
+
 type PayrollItemRepo interface {
 	Create(ctx context.Context, item *PayrollItem) error
 	CreateBatch(ctx context.Context, items []PayrollItem) error
@@ -127,18 +344,245 @@ type EmployeeContractRepo interface {
 	Create(ctx context.Context, contract *EmployeeContract) error
 	GetByID(ctx context.Context, id uint) (*EmployeeContract, error)
 	GetActiveByEmployee(ctx context.Context, employeeID uint) (*EmployeeContract, error)
+	// LockActiveByEmployee bloquea (FOR UPDATE) el contrato activo del empleado; se usa
+	// dentro de una transacción para serializar los cambios de contrato
+	LockActiveByEmployee(ctx context.Context, employeeID uint) (*EmployeeContract, error)
 	ListByEmployee(ctx context.Context, employeeID uint) ([]EmployeeContract, error)
+	// ListActiveEndingBy retorna los contratos activos con fecha de terminación hasta until
+	ListActiveEndingBy(ctx context.Context, until time.Time) ([]EmployeeContract, error)
 	Update(ctx context.Context, contract *EmployeeContract) error
 	Delete(ctx context.Context, id uint) error
 }
 
+type DepartmentRepo interface {
+	Create(ctx context.Context, department *Department) error
+	GetByID(ctx context.Context, id uint) (*Department, error)
+	List(ctx context.Context) ([]Department, error)
+	Update(ctx context.Context, department *Department) error
+	Delete(ctx context.Context, id uint) error
+	// Headcount retorna los empleados activos por departamento
+	Headcount(ctx context.Context) (map[uint]int64, error)
+}
+
+type PositionRepo interface {
+	Create(ctx context.Context, position *Position) error
+	GetByID(ctx context.Context, id uint) (*Position, error)
+	// List retorna los cargos del tenant; departmentID 0 no filtra
+	List(ctx context.Context, departmentID uint) ([]Position, error)
+	Update(ctx context.Context, position *Position) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type ContractTypeRepo interface {
+	Create(ctx context.Context, contractType *ContractType) error
+	GetByID(ctx context.Context, id uint) (*ContractType, error)
+	GetByCode(ctx context.Context, code string) (*ContractType, error)
+	List(ctx context.Context) ([]ContractType, error)
+	Update(ctx context.Context, contractType *ContractType) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type PayrollStatusHistoryRepo interface {
+	Create(ctx context.Context, entry *PayrollStatusHistory) error
+	ListByPayroll(ctx context.Context, payrollID uint) ([]PayrollStatusHistory, error)
+}
+
+type PayrollTransitionRepo interface {
+	ListByTenant(ctx context.Context) ([]PayrollStatusTransition, error)
+	ReplaceAll(ctx context.Context, transitions []PayrollStatusTransition) error
+}
+
 type PaymentRepo interface {
 	Create(ctx context.Context, payment *Payment) error
 	GetByID(ctx context.Context, id uint) (*Payment, error)
-	GetByPayrollID(ctx context.Context, payrollID uint) (*Payment, error)
+	// LockByID bloquea (FOR UPDATE) el pago; se usa dentro de una transacción para que dos
+	// conciliaciones no confirmen el mismo pago
+	LockByID(ctx context.Context, id uint) (*Payment, error)
+	ListByPayroll(ctx context.Context, payrollID uint) ([]Payment, error)
+	ListByEmployee(ctx context.Context, employeeID uint) ([]Payment, error)
+	ListByStatus(ctx context.Context, status string) ([]Payment, error)
+	GetByProviderRef(ctx context.Context, reference string) (*Payment, error)
+	// UpdateStatus guarda el estado, la referencia del proveedor, el archivo bancario, el motivo
+	// de falla y sus fechas
+	UpdateStatus(ctx context.Context, payment *Payment) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type BankPaymentFileRepo interface {
+	Create(ctx context.Context, file *BankPaymentFile) error
+	GetByID(ctx context.Context, id uint) (*BankPaymentFile, error)
+	// List retorna los archivos sin su contenido, los más recientes primero
+	List(ctx context.Context) ([]BankPaymentFile, error)
+}
+
+type BankFileTemplateRepo interface {
+	Create(ctx context.Context, template *BankFileTemplate) error
+	GetByID(ctx context.Context, id uint) (*BankFileTemplate, error)
+	List(ctx context.Context) ([]BankFileTemplate, error)
+	// Update reemplaza los datos y las columnas de la plantilla
+	Update(ctx context.Context, template *BankFileTemplate) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type BankStatementRepo interface {
+	// Create guarda el extracto con sus líneas
+	Create(ctx context.Context, statement *BankStatement) error
+	// GetByID retorna el extracto con sus líneas en orden
+	GetByID(ctx context.Context, id uint) (*BankStatement, error)
+	GetByHash(ctx context.Context, hash string) (*BankStatement, error)
+	// List retorna los extractos sin sus líneas, los más recientes primero
+	List(ctx context.Context) ([]BankStatement, error)
+	// UpdateCounts guarda los totales de conciliación del extracto
+	UpdateCounts(ctx context.Context, statement *BankStatement) error
+	// UpdateLine guarda el estado, el pago, la diferencia y la resolución de una línea
+	UpdateLine(ctx context.Context, line *BankStatementLine) error
+	// IsPaymentMatched indica si el pago ya está conciliado con alguna línea
+	IsPaymentMatched(ctx context.Context, paymentID uint) (bool, error)
+}
+
+type PayslipTemplateRepo interface {
+	// Get retorna la plantilla del tenant
+	Get(ctx context.Context) (*PayslipTemplate, error)
+	// Save crea o actualiza la plantilla del tenant
+	Save(ctx context.Context, template *PayslipTemplate) error
+}
+
+type NotificationOutboxRepo interface {
+	Create(ctx context.Context, notification *NotificationOutbox) error
+	ListByPayroll(ctx context.Context, payrollID uint) ([]NotificationOutbox, error)
+	// ListDue retorna las notificaciones pendientes con NextAttemptAt vencido de todos los
+	// tenants, para el despachador en segundo plano
+	ListDue(ctx context.Context, now time.Time, limit int) ([]NotificationOutbox, error)
+	// UpdateDelivery guarda el resultado de un intento de envío
+	UpdateDelivery(ctx context.Context, notification *NotificationOutbox) error
+	// RetryFailed vuelve a pending las notificaciones fallidas de una nómina
+	RetryFailed(ctx context.Context, payrollID uint) (int64, error)
+}
+
+// EmailAttachment es un archivo adjunto de un correo
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// EmailMessage es un correo a enviar
+type EmailMessage struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
+}
+
+// Mailer envía correos electrónicos
+type Mailer interface {
+	Send(ctx context.Context, message EmailMessage) error
+}
+
+type ElectronicPayrollRepo interface {
+	Create(ctx context.Context, document *ElectronicPayrollDocument) error
+	// GetByID carga el documento con su predecesor y sus transmisiones
+	GetByID(ctx context.Context, id uint) (*ElectronicPayrollDocument, error)
+	// ListByPeriod retorna los documentos del mes sin el XML, del más antiguo al más reciente
+	ListByPeriod(ctx context.Context, year, month int) ([]ElectronicPayrollDocument, error)
+	UpdateStatus(ctx context.Context, document *ElectronicPayrollDocument) error
+	// NextConsecutive retorna el siguiente consecutivo del prefijo para el tenant
+	NextConsecutive(ctx context.Context, prefix string) (int, error)
+	CreateSubmission(ctx context.Context, submission *ElectronicPayrollSubmission) error
+}
+
+type CostCenterRepo interface {
+	Create(ctx context.Context, costCenter *CostCenter) error
+	GetByID(ctx context.Context, id uint) (*CostCenter, error)
+	List(ctx context.Context) ([]CostCenter, error)
+	Update(ctx context.Context, costCenter *CostCenter) error
+}
+
+type CostCenterAllocationRepo interface {
+	// ListByEmployee retorna todas las versiones de asignación del empleado con su centro de costo
+	ListByEmployee(ctx context.Context, employeeID uint) ([]CostCenterAllocation, error)
+	// ReplaceVersion reemplaza la versión que rige desde effectiveFrom; vacía la elimina
+	ReplaceVersion(ctx context.Context, employeeID uint, effectiveFrom time.Time, allocations []CostCenterAllocation) error
+}
+
+type PayrollAccumulatorRepo interface {
+	// Add suma los montos a los acumulados existentes, creándolos si no existen
+	Add(ctx context.Context, accumulators []PayrollAccumulator) error
+	ListByEmployee(ctx context.Context, employeeID uint, year int) ([]PayrollAccumulator, error)
+}
+
+type EmployeeAbsenceRepo interface {
+	Create(ctx context.Context, absence *EmployeeAbsence) error
+	GetByID(ctx context.Context, id uint) (*EmployeeAbsence, error)
+	ListByEmployee(ctx context.Context, employeeID uint) ([]EmployeeAbsence, error)
+	// ListOverlapping retorna las ausencias de todos los empleados que se cruzan con [start, end]
+	ListOverlapping(ctx context.Context, start, end time.Time) ([]EmployeeAbsence, error)
 	Delete(ctx context.Context, id uint) error
 }
 
+// ElectronicPayrollResult es la respuesta de la DIAN a una transmisión
+type ElectronicPayrollResult struct {
+	TrackID  string
+	Accepted bool
+	Messages []string
+}
+
+// ElectronicPayrollClient transmite documentos de nómina electrónica a la DIAN
+type ElectronicPayrollClient interface {
+	Name() string
+	Send(ctx context.Context, document *ElectronicPayrollDocument) (ElectronicPayrollResult, error)
+}
+
+// PayoutResult es el estado de un pago según el proveedor
+type PayoutResult struct {
+	Status        string
+	FailureReason string
+}
+
+// PayoutProvider envía pagos al banco o pasarela y consulta su estado
+type PayoutProvider interface {
+	Name() string
+	// Submit envía el pago y retorna la referencia del proveedor
+	Submit(ctx context.Context, payment *Payment) (string, error)
+	// Status consulta el estado de un pago enviado: sent, completed o failed
+	Status(ctx context.Context, reference string) (PayoutResult, error)
+}
+
+type AccountingPeriodRepo interface {
+	Create(ctx context.Context, period *AccountingPeriod) error
+	GetByID(ctx context.Context, id uint) (*AccountingPeriod, error)
+	List(ctx context.Context) ([]AccountingPeriod, error)
+	// FindOverlapping retorna los periodos que se cruzan con el rango [start, end]
+	FindOverlapping(ctx context.Context, start, end time.Time) ([]AccountingPeriod, error)
+	Update(ctx context.Context, period *AccountingPeriod) error
+}
+
+type EmployeeTerminationRepo interface {
+	Create(ctx context.Context, termination *EmployeeTermination) error
+	GetByEmployee(ctx context.Context, employeeID uint) (*EmployeeTermination, error)
+	Update(ctx context.Context, termination *EmployeeTermination) error
+}
+
+type BenefitLedgerRepo interface {
+	CreateBatch(ctx context.Context, entries []BenefitLedgerEntry) error
+	ExistsForPayroll(ctx context.Context, payrollID uint) (bool, error)
+	ListByPayroll(ctx context.Context, payrollID uint) ([]BenefitLedgerEntry, error)
+	ListByEmployee(ctx context.Context, employeeID uint) ([]BenefitLedgerEntry, error)
+	// Balances retorna el saldo por empleado de una prestación con movimientos hasta upTo
+	// (solo saldos distintos de cero)
+	Balances(ctx context.Context, benefit string, upTo time.Time) ([]BenefitBalance, error)
+}
+
+type RetroAdjustmentRepo interface {
+	CreateBatch(ctx context.Context, adjustments []RetroAdjustment) error
+	ListBySource(ctx context.Context, sourcePayrollID uint) ([]RetroAdjustment, error)
+	ListByTarget(ctx context.Context, targetPayrollID uint) ([]RetroAdjustment, error)
+	ListByEmployee(ctx context.Context, employeeID uint) ([]RetroAdjustment, error)
+	// ListPending retorna los ajustes aún no asignados a una nómina cuyo periodo termina antes de before
+	ListPending(ctx context.Context, employeeID uint, before time.Time) ([]RetroAdjustment, error)
+	AssignTarget(ctx context.Context, ids []uint, targetPayrollID uint) error
+}
+
 // Extended PayrollRepo con métodos para batch processing
 type PayrollBatchRepo interface {
 	PayrollRepo
@@ -13,7 +13,23 @@ import (
 const (
 	PayrollStatusDraft      = "draft"
 	PayrollStatusCalculated = "calculated"
+	PayrollStatusApproved   = "approved"
 	PayrollStatusPaid       = "paid"
+	// PayrollStatusPartiallyPaid: hay pagos registrados que no cubren todo el neto
+	PayrollStatusPartiallyPaid = "partially_paid"
+	PayrollStatusCancelled     = "cancelled"
+	PayrollStatusReversed      = "reversed"
+)
+
+// Payroll kinds: una nómina regular, su reverso o la nómina de reemplazo que la corrige
+const (
+	PayrollKindRegular     = "regular"
+	PayrollKindReversal    = "reversal"
+	PayrollKindReplacement = "replacement"
+	// PayrollKindBenefit agrupa pagos de prestaciones (prima semestral, consignación de cesantías)
+	PayrollKindBenefit = "benefit"
+	// PayrollKindSettlement es la liquidación final al terminar el contrato
+	PayrollKindSettlement = "settlement"
 )
 
 const (
@@ -36,8 +52,65 @@ const (
 	ConceptHealthEmployer  = "HEALTH_EMPLOYER"
 	ConceptPensionEmployer = "PENSION_EMPLOYER"
 	ConceptParafiscales    = "PARAFISCALES"
+	ConceptPriorPayment    = "PRIOR_PAYMENT"
+
+	// Provisiones de prestaciones sociales (aporte del empleador, se acumulan en el ledger)
+	ConceptPrimaProvision             = "PRIMA_PROVISION"
+	ConceptSeveranceProvision         = "SEVERANCE_PROVISION"
+	ConceptSeveranceInterestProvision = "SEVERANCE_INTEREST_PROVISION"
+	ConceptVacationProvision          = "VACATION_PROVISION"
+
+	// Pagos de prestaciones generados desde el ledger
+	ConceptPrimaPayment             = "PRIMA"
+	ConceptSeverancePayment         = "SEVERANCE"
+	ConceptSeveranceInterestPayment = "SEVERANCE_INTEREST"
+	ConceptVacationPayment          = "VACATION"
+	ConceptIndemnification          = "INDEMNIFICATION"
+
+	// ConceptApprenticeStipend reemplaza al salario base en contratos de aprendizaje
+	ConceptApprenticeStipend = "APPRENTICE_STIPEND"
+)
+
+// Códigos de los tipos de contrato por defecto de cada tenant
+const (
+	ContractTypeIndefinite     = "INDEFINITE"
+	ContractTypeFixedTerm      = "FIXED_TERM"
+	ContractTypeApprenticeship = "APPRENTICESHIP"
+	ContractTypeService        = "SERVICE"
+)
+
+// Prestaciones sociales acumuladas en el ledger por empleado
+const (
+	BenefitPrima             = "prima"
+	BenefitSeverance         = "severance"
+	BenefitSeveranceInterest = "severance_interest"
+	BenefitVacation          = "vacation"
 )
 
+// Tipos de movimiento del ledger de prestaciones
+const (
+	BenefitEntryAccrual = "accrual"
+	BenefitEntryPayment = "payment"
+)
+
+// Alcances de datos de un permiso: todos los registros del tenant, los de los subordinados
+// (directos e indirectos) del usuario o los de su departamento
+const (
+	DataScopeAll           = "all"
+	DataScopeOwnReports    = "own_reports"
+	DataScopeOwnDepartment = "own_department"
+)
+
+// Recursos cuyos listados se filtran por el alcance de datos del usuario
+const (
+	ResourceEmployees = "employees"
+	ResourcePayrolls  = "payrolls"
+	ResourceAbsences  = "absences"
+)
+
+// RetroConceptPrefix antecede el código de los items de ajuste retroactivo (RETRO_BASE_SALARY)
+const RetroConceptPrefix = "RETRO_"
+
 type User struct {
 	ID uint `gorm:"primaryKey" json:"id"`
 	// TenantID uint `gorm:"not null;uniqueIndex:idx_users_tenant_dni;uniqueIndex:idx_users_tenant_phone;uniqueIndex:idx_users_tenant_email"`
@@ -95,9 +168,11 @@ type Role struct {
 }
 
 type RolePermission struct {
-	ID        uint             `gorm:"primaryKey" json:"id"`
-	RoleID    uint             `gorm:"not null;uniqueIndex:idx_role_action" json:"role_id"`
-	ActionID  uint             `gorm:"not null;uniqueIndex:idx_role_action" json:"action_id"`
+	ID       uint `gorm:"primaryKey" json:"id"`
+	RoleID   uint `gorm:"not null;uniqueIndex:idx_role_action" json:"role_id"`
+	ActionID uint `gorm:"not null;uniqueIndex:idx_role_action" json:"action_id"`
+	// Scope limita los registros a los que aplica el permiso; vacío equivale a DataScopeAll
+	Scope     string           `gorm:"size:20;default:all" json:"scope"`
 	GrantedAt time.Time        `gorm:"autoCreateTime" json:"granted_at"`
 	Role      Role             `gorm:"foreignKey:RoleID" json:"-"`
 	Action    PermissionAction `gorm:"foreignKey:ActionID" json:"action,omitzero"`
@@ -115,28 +190,37 @@ type UserRole struct {
 }
 
 type Department struct {
-	ID        uint           `gorm:"primaryKey"`
-	TenantID  uint           `gorm:"not null;index"`
-	Name      string         `gorm:"size:100;not null"`
-	Code      string         `gorm:"size:20;uniqueIndex:idx_dept_tenant_code,composite:tenant_code"`
-	IsActive  bool           `gorm:"default:true"`
-	CreatedAt time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `gorm:"index"`
-	Positions []Position     `gorm:"foreignKey:DepartmentID"`
+	ID       uint   `gorm:"primaryKey"`
+	TenantID uint   `gorm:"not null;index"`
+	Name     string `gorm:"size:100;not null"`
+	Code     string `gorm:"size:20;uniqueIndex:idx_dept_tenant_code,composite:tenant_code"`
+	// ParentID arma el árbol organizacional; nil es un departamento raíz
+	ParentID *uint `gorm:"index"`
+	// HeadEmployeeID es el empleado que dirige el departamento
+	HeadEmployeeID *uint          `gorm:"index"`
+	IsActive       bool           `gorm:"default:true"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	Positions      []Position     `gorm:"foreignKey:DepartmentID"`
 }
 
 type Position struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	TenantID     uint           `gorm:"not null;index" json:"tenant_id"`
-	DepartmentID uint           `gorm:"index"`
-	NamePosition string         `gorm:"size:100;not null" json:"name_position"`
-	Description  string         `gorm:"size:255;not null" json:"description"`
-	IsActive     bool           `gorm:"default:true"`
-	CreatedAt    time.Time      `gorm:"not null" json:"created_at"`
-	UpdatedAt    time.Time      `gorm:"not null" json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index:idx_users_deleted_at" json:"deleted_at,omitzero"`
-	Deparment    Department     `gorm:"foreignKey:DepartmentID"`
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	TenantID     uint   `gorm:"not null;index" json:"tenant_id"`
+	DepartmentID uint   `gorm:"index"`
+	NamePosition string `gorm:"size:100;not null" json:"name_position"`
+	Description  string `gorm:"size:255;not null" json:"description"`
+	// Banda salarial del cargo; SalaryMax 0 indica que no tiene banda
+	SalaryMin      float64        `gorm:"default:0" json:"salary_min"`
+	SalaryMid      float64        `gorm:"default:0" json:"salary_mid"`
+	SalaryMax      float64        `gorm:"default:0" json:"salary_max"`
+	SalaryCurrency string         `gorm:"size:3" json:"salary_currency"`
+	IsActive       bool           `gorm:"default:true"`
+	CreatedAt      time.Time      `gorm:"not null" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"not null" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index:idx_users_deleted_at" json:"deleted_at,omitzero"`
+	Deparment      Department     `gorm:"foreignKey:DepartmentID"`
 }
 
 type Employee struct {
@@ -145,19 +229,69 @@ type Employee struct {
 	UserID       uint `gorm:"not null;uniqueIndex"`
 	DepartmentID uint `gorm:"index"`
 	PositionID   uint `gorm:"index"`
-	IsActive     bool `gorm:"default:true;index"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt     `gorm:"index"`
-	User         User               `gorm:"foreignKey:UserID"`
-	Department   Department         `gorm:"foreignKey:DepartmentID"`
-	Position     Position           `gorm:"foreignKey:PositionID"`
-	Contracts    []EmployeeContract `gorm:"foreignKey:EmployeeID"`
+	// ManagerID es el jefe directo; nil para la cabeza del organigrama
+	ManagerID  *uint `gorm:"index"`
+	IsActive   bool  `gorm:"default:true;index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt     `gorm:"index"`
+	User       User               `gorm:"foreignKey:UserID"`
+	Department Department         `gorm:"foreignKey:DepartmentID"`
+	Position   Position           `gorm:"foreignKey:PositionID"`
+	Contracts  []EmployeeContract `gorm:"foreignKey:EmployeeID"`
+	// Concepts son los conceptos con valor propio del empleado (bonificaciones, descuentos fijos)
+	Concepts []EmployeeConcept `gorm:"foreignKey:EmployeeID"`
+
+	// Afiliaciones a seguridad social para la planilla PILA: códigos de la EPS y del fondo de
+	// pensiones, y clase de riesgo ARL (1 a 5) del puesto de trabajo
+	HealthFundCode  string `gorm:"size:6"`
+	PensionFundCode string `gorm:"size:6"`
+	RiskClass       int    `gorm:"default:1"`
+}
+
+// EmployeeConcept asigna a un empleado un concepto de nómina con un valor propio: un monto fijo
+// mensual (prorrateado al periodo) o un porcentaje del salario que reemplaza el del concepto
+type EmployeeConcept struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	TenantID   uint           `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID uint           `gorm:"not null;uniqueIndex:idx_employee_concept" json:"employee_id"`
+	ConceptID  uint           `gorm:"not null;uniqueIndex:idx_employee_concept" json:"concept_id"`
+	Amount     float64        `gorm:"default:0" json:"amount"`
+	Percentage float64        `gorm:"default:0" json:"percentage"`
+	IsActive   bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	Concept    PayrollConcept `gorm:"foreignKey:ConceptID" json:"concept,omitzero"`
+}
+
+// Tipos de cuenta bancaria
+const (
+	BankAccountSavings  = "savings"
+	BankAccountChecking = "checking"
+)
+
+// EmployeeBankAccount es una cuenta donde se consigna la nómina del empleado. Un empleado
+// puede tener varias cuentas con una sola principal; si alguna cuenta activa tiene
+// SplitPercentage, el pago se reparte entre esas cuentas y lo que falte para 100 va a la
+// principal.
+type EmployeeBankAccount struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	TenantID        uint      `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID      uint      `gorm:"not null;index" json:"employee_id"`
+	BankName        string    `gorm:"size:100;not null" json:"bank_name"`
+	AccountType     string    `gorm:"size:20;not null" json:"account_type"`
+	AccountNumber   string    `gorm:"size:50;not null" json:"account_number"`
+	IsPrimary       bool      `gorm:"default:false" json:"is_primary"`
+	SplitPercentage float64   `gorm:"default:0" json:"split_percentage"`
+	IsActive        bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 type EmployeeContract struct {
-	ID             uint `gorm:"primaryKey"`
-	TenantID       uint `gorm:"not null;index"`
-	EmployeeID     uint `gorm:"not null;index"`
+	ID uint `gorm:"primaryKey"`
+	// El índice único parcial garantiza un solo contrato activo por empleado
+	TenantID       uint `gorm:"not null;index;uniqueIndex:idx_contract_active_employee,where:is_active = true"`
+	EmployeeID     uint `gorm:"not null;index;uniqueIndex:idx_contract_active_employee,where:is_active = true"`
 	ContractTypeID uint `gorm:"index"`
 
 	BaseSalary float64
@@ -174,6 +308,15 @@ type EmployeeContract struct {
 	TransportAllowance  float64
 	HousingAllowance    float64
 
+	// SocialSecurityProof es la referencia de la planilla o certificado de aportes a
+	// seguridad social, exigida por los tipos de contrato de prestación de servicios
+	SocialSecurityProof string `gorm:"size:100"`
+
+	// BandOverrideReason justifica un salario fuera de la banda del cargo; BandOverrideBy
+	// es el usuario con permiso de excepción que lo autorizó
+	BandOverrideReason string `gorm:"size:255"`
+	BandOverrideBy     uint
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -181,10 +324,35 @@ type EmployeeContract struct {
 	ContractType ContractType `gorm:"foreignKey:ContractTypeID"`
 }
 
+// ContractType es un tipo de contrato del tenant con las reglas que aplican el calculador
+// de nómina y el servicio de contratos
 type ContractType struct {
-	ID          uint   `gorm:"primaryKey"`
-	Name        string `gorm:"size:50;not null"`
-	Description string `gorm:"size:255"`
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	TenantID    uint   `gorm:"not null;index;uniqueIndex:idx_contract_type_tenant_code,composite:tenant_code" json:"tenant_id"`
+	Code        string `gorm:"size:30;uniqueIndex:idx_contract_type_tenant_code,composite:tenant_code" json:"code"`
+	Name        string `gorm:"size:50;not null" json:"name"`
+	Description string `gorm:"size:255" json:"description"`
+
+	// RequiresEndDate exige fecha de terminación (término fijo)
+	RequiresEndDate bool `gorm:"default:false" json:"requires_end_date"`
+	// RenewalAlertDays genera alertas de renovación N días antes del vencimiento; 0 las desactiva
+	RenewalAlertDays int `gorm:"default:0" json:"renewal_alert_days"`
+	// SkipPension omite el aporte a pensión del empleado y del empleador (aprendizaje)
+	SkipPension bool `gorm:"default:false" json:"skip_pension"`
+	// StipendPercentage paga un apoyo de sostenimiento equivalente a este porcentaje del
+	// salario mínimo en lugar del salario base; 0 lo desactiva
+	StipendPercentage float64 `gorm:"default:0" json:"stipend_percentage"`
+	// NoDeductions omite todas las deducciones de nómina (prestación de servicios)
+	NoDeductions bool `gorm:"default:false" json:"no_deductions"`
+	// RequiresSocialSecurityProof exige la planilla de aportes del contratista para liquidar
+	RequiresSocialSecurityProof bool `gorm:"default:false" json:"requires_social_security_proof"`
+	// NoSocialBenefits no causa ni liquida prima, cesantías e intereses, ni indemnización al
+	// terminar (aprendizaje, prestación de servicios)
+	NoSocialBenefits bool `gorm:"default:false" json:"no_social_benefits"`
+
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type Payroll struct {
@@ -198,14 +366,53 @@ type Payroll struct {
 	GrossAmount     float64
 	TotalDeductions float64
 	NetAmount       float64
-	Status          string `gorm:"size:20;default:'draft'"` // draft, calculated, paid
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	Status          string `gorm:"size:20;default:'draft'"`         // draft, calculated, approved, paid, cancelled, reversed
+	Kind            string `gorm:"size:20;default:'regular';index"` // regular, reversal, replacement, benefit, settlement
+	// OriginalPayrollID enlaza un reverso o reemplazo con la nómina que corrige
+	OriginalPayrollID *uint `gorm:"index"`
+	CalculatedBy      uint
+	ApprovedBy        uint
+	ApprovedAt        *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 
 	Employee Employee      `gorm:"foreignKey:EmployeeID"`
 	Items    []PayrollItem `gorm:"foreignKey:PayrollID"`
 }
 
+// PayrollStatusHistory registra cada transición de estado de una nómina
+type PayrollStatusHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;index" json:"tenant_id"`
+	PayrollID  uint      `gorm:"not null;index" json:"payroll_id"`
+	FromStatus string    `gorm:"size:20" json:"from_status"`
+	ToStatus   string    `gorm:"size:20;not null" json:"to_status"`
+	ActorID    uint      `gorm:"index" json:"actor_id"`
+	Reason     string    `gorm:"size:255" json:"reason"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PayrollStatusTransition define una transición permitida para un tenant.
+// Si un tenant no tiene transiciones configuradas se usa DefaultPayrollTransitions.
+type PayrollStatusTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;uniqueIndex:idx_transition_tenant_from_to" json:"tenant_id"`
+	FromStatus string    `gorm:"size:20;not null;uniqueIndex:idx_transition_tenant_from_to" json:"from_status"`
+	ToStatus   string    `gorm:"size:20;not null;uniqueIndex:idx_transition_tenant_from_to" json:"to_status"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PayrollRegisterFilter selecciona las nóminas del libro de nómina: por periodo o por una
+// corrida (PayrollIDs), opcionalmente limitadas a departamentos, cargos y estados
+type PayrollRegisterFilter struct {
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	PayrollIDs    []uint
+	DepartmentIDs []uint
+	PositionIDs   []uint
+	Statuses      []string
+}
+
 type PayrollItem struct {
 	ID           uint    `gorm:"primaryKey"`
 	PayrollID    uint    `gorm:"not null;index"`
@@ -218,43 +425,437 @@ type PayrollItem struct {
 
 	Payroll Payroll        `gorm:"foreignKey:PayrollID"`
 	Concept PayrollConcept `gorm:"foreignKey:ConceptID"`
+	// Allocations reparte los devengos y aportes del empleador entre centros de costo
+	Allocations []PayrollItemAllocation `gorm:"foreignKey:PayrollItemID"`
+}
+
+// PayrollItemAllocation es la parte de un item imputada a un centro de costo. Lo que no
+// quede repartido se contabiliza con el centro de costo del concepto.
+type PayrollItemAllocation struct {
+	ID             uint    `gorm:"primaryKey"`
+	PayrollItemID  uint    `gorm:"not null;index"`
+	CostCenterID   uint    `gorm:"not null;index"`
+	CostCenterCode string  `gorm:"size:20"`
+	Percentage     float64 `gorm:"not null"`
+	Amount         float64 `gorm:"not null"`
 }
 
 type PayrollConcept struct {
-	ID           uint           `gorm:"primaryKey"`
-	TenantID     uint           `gorm:"not null;index" json:"tenant_id"`
-	Code         string         `gorm:"size:30;not null;uniqueIndex:idx_concept_tenant_code,composite:tenant_code" json:"code"`
-	Name         string         `gorm:"size:100" json:"name"`
-	Type         string         `gorm:"size:20" json:"type"` // earning | deduction | employer_contribution
-	Description  string         `gorm:"size:255" json:"description"`
-	Percentage   float64        `gorm:"default:0" json:"percentage"`
-	EmployeePart float64        `gorm:"default:0" json:"employee_part"`
-	EmployerPart float64        `gorm:"default:0" json:"employer_part"`
-	IsMandatory  bool           `gorm:"default:false" json:"is_mandatory"`
-	IsActive     bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"`
+	ID           uint    `gorm:"primaryKey"`
+	TenantID     uint    `gorm:"not null;index" json:"tenant_id"`
+	Code         string  `gorm:"size:30;not null;uniqueIndex:idx_concept_tenant_code,composite:tenant_code" json:"code"`
+	Name         string  `gorm:"size:100" json:"name"`
+	Type         string  `gorm:"size:20" json:"type"` // earning | deduction | employer_contribution
+	Description  string  `gorm:"size:255" json:"description"`
+	Percentage   float64 `gorm:"default:0" json:"percentage"`
+	EmployeePart float64 `gorm:"default:0" json:"employee_part"`
+	EmployerPart float64 `gorm:"default:0" json:"employer_part"`
+	IsMandatory  bool    `gorm:"default:false" json:"is_mandatory"`
+	IsActive     bool    `gorm:"default:true" json:"is_active"`
+	// Cuentas contables del asiento de nómina; vacías usan las del PUC por defecto
+	DebitAccount  string         `gorm:"size:20" json:"debit_account"`
+	CreditAccount string         `gorm:"size:20" json:"credit_account"`
+	CostCenter    string         `gorm:"size:20" json:"cost_center"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"`
+}
+
+// LedgerAccounts son las cuentas débito y crédito con las que se contabiliza un concepto
+type LedgerAccounts struct {
+	Debit  string
+	Credit string
+}
+
+// RetroAdjustment registra la diferencia entre lo pagado en un periodo y lo que debió
+// pagarse, y la nómina abierta (TargetPayrollID) donde se liquida como item RETRO_*
+type RetroAdjustment struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	TenantID        uint      `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID      uint      `gorm:"not null;index" json:"employee_id"`
+	SourcePayrollID uint      `gorm:"not null;index" json:"source_payroll_id"`
+	TargetPayrollID *uint     `gorm:"index" json:"target_payroll_id,omitempty"`
+	PeriodStart     time.Time `json:"period_start"`
+	PeriodEnd       time.Time `json:"period_end"`
+	ConceptID       uint      `json:"concept_id"`
+	Type            string    `gorm:"size:20" json:"type"`
+	Code            string    `gorm:"size:30" json:"code"`
+	Name            string    `gorm:"size:100" json:"name"`
+	PaidAmount      float64   `json:"paid_amount"`
+	ExpectedAmount  float64   `json:"expected_amount"`
+	Amount          float64   `gorm:"not null" json:"amount"`
+	Reason          string    `gorm:"size:255" json:"reason"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Accounting period statuses
+const (
+	PeriodStatusOpen   = "open"
+	PeriodStatusClosed = "closed"
+	PeriodStatusLocked = "locked"
+)
+
+// AccountingPeriod congela los datos de nómina de un rango de fechas por tenant.
+// Un periodo cerrado puede reabrirse con permiso; uno bloqueado es definitivo.
+type AccountingPeriod struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TenantID    uint       `gorm:"not null;uniqueIndex:idx_period_tenant_start" json:"tenant_id"`
+	Name        string     `gorm:"size:50" json:"name"`
+	PeriodStart time.Time  `gorm:"not null;uniqueIndex:idx_period_tenant_start" json:"period_start"`
+	PeriodEnd   time.Time  `gorm:"not null" json:"period_end"`
+	Status      string     `gorm:"size:20;not null;default:'open';index" json:"status"`
+	ClosedBy    uint       `json:"closed_by,omitzero"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// Contains indica si la fecha cae dentro del periodo
+func (p *AccountingPeriod) Contains(date time.Time) bool {
+	return !date.Before(p.PeriodStart) && !date.After(p.PeriodEnd)
+}
+
+// Métodos de pago
+const (
+	PaymentMethodBankTransfer = "bank_transfer"
+)
+
+// Estados de un pago: todo pago nace pending; las transferencias pasan a sent al enviarse
+// al proveedor y terminan en completed o failed según la respuesta del banco
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSent      = "sent"
+	PaymentStatusCompleted = "completed"
+	PaymentStatusFailed    = "failed"
+)
+
 type Payment struct {
 	ID        uint `gorm:"primaryKey"`
+	TenantID  uint `gorm:"index"`
 	PayrollID uint `gorm:"not null;index"`
 
 	Method string `gorm:"size:30"` // bank_transfer
 
+	// Copia de la cuenta al momento del pago: si la cuenta cambia después, el pago conserva
+	// los datos a los que realmente se consignó
+	BankAccountID *uint  `gorm:"index"`
 	BankName      string `gorm:"size:100"`
+	AccountType   string `gorm:"size:20"`
 	AccountNumber string `gorm:"size:50"`
 
 	Amount float64
 
-	PaidAt    time.Time
-	Status    string `gorm:"size:20"`
+	PaidAt time.Time
+	Status string `gorm:"size:20;index"`
+
+	// Seguimiento en el proveedor de pagos
+	ProviderRef   string `gorm:"size:100;index"`
+	FailureReason string `gorm:"size:255"`
+	SentAt        *time.Time
+	SettledAt     *time.Time
+	// BankFileID es el archivo bancario en el que se envió la transferencia
+	BankFileID *uint `gorm:"index"`
+
 	CreatedAt time.Time
+	UpdatedAt time.Time
 
 	Payroll Payroll `gorm:"foreignKey:PayrollID"`
 }
 
+// Formatos de archivo bancario
+const (
+	BankFileFormatCSV        = "csv"
+	BankFileFormatPain001    = "pain001"
+	BankFileFormatFixedWidth = "fixed_width"
+)
+
+// BankPaymentFile es un archivo de pagos generado para cargar en el portal del banco.
+// Guarda los totales de control y el hash SHA-256 del contenido para verificar la carga.
+type BankPaymentFile struct {
+	ID            uint      `gorm:"primaryKey"`
+	TenantID      uint      `gorm:"not null;index"`
+	Reference     string    `gorm:"size:35;not null;index"`
+	Format        string    `gorm:"size:20;not null"`
+	TemplateID    *uint     `gorm:"index"`
+	PeriodStart   time.Time `gorm:"not null"`
+	PeriodEnd     time.Time `gorm:"not null"`
+	ExecutionDate time.Time
+	FileName      string `gorm:"size:100"`
+	RecordCount   int
+	TotalAmount   float64
+	Hash          string `gorm:"size:64"`
+	Content       []byte
+	CreatedBy     uint
+	CreatedAt     time.Time
+}
+
+// PayslipTemplate personaliza el desprendible de nómina del tenant: datos del empleador,
+// textos, color y logo. Sin plantilla se usa un desprendible básico.
+type PayslipTemplate struct {
+	ID             uint   `gorm:"primaryKey"`
+	TenantID       uint   `gorm:"not null;uniqueIndex"`
+	CompanyName    string `gorm:"size:150"`
+	CompanyNIT     string `gorm:"size:30"`
+	CompanyAddress string `gorm:"size:255"`
+	CompanyPhone   string `gorm:"size:30"`
+	Title          string `gorm:"size:100"`
+	FooterText     string `gorm:"size:500"`
+	PrimaryColor   string `gorm:"size:7"` // #RRGGBB
+	// ShowEmployerContributions incluye los aportes del empleador como información
+	ShowEmployerContributions bool `gorm:"default:true"`
+	Logo                      []byte
+	LogoContentType           string `gorm:"size:50"`
+	CreatedAt                 time.Time
+	UpdatedAt                 time.Time
+}
+
+// Estados de entrega de una notificación del outbox
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+	NotificationSkipped = "skipped"
+)
+
+// NotificationKindPayslipEmail es el correo con el desprendible de una nómina pagada
+const NotificationKindPayslipEmail = "payslip_email"
+
+// NotificationOutbox es una notificación pendiente de envío. Se registra en la misma
+// transacción que el cambio que la origina y la despacha un proceso en segundo plano.
+type NotificationOutbox struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TenantID      uint       `gorm:"not null;index" json:"tenant_id"`
+	PayrollID     uint       `gorm:"not null;index" json:"payroll_id"`
+	EmployeeID    uint       `gorm:"not null;index" json:"employee_id"`
+	Kind          string     `gorm:"size:30;not null" json:"kind"`
+	Recipient     string     `gorm:"size:150" json:"recipient"`
+	Status        string     `gorm:"size:20;not null;default:'pending';index" json:"status"` // pending, sent, failed, skipped
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError     string     `gorm:"size:500" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Estados del documento de nómina electrónica
+const (
+	ElectronicPayrollGenerated  = "generated"
+	ElectronicPayrollAccepted   = "accepted"
+	ElectronicPayrollRejected   = "rejected"
+	ElectronicPayrollSuperseded = "superseded"
+)
+
+// Resultado de una transmisión a la DIAN
+const (
+	SubmissionAccepted = "accepted"
+	SubmissionRejected = "rejected"
+	SubmissionError    = "error"
+)
+
+// ElectronicPayrollDocument es el documento soporte de nómina electrónica de un empleado en
+// un mes (DocumentType 102) o una nota de ajuste (103) que reemplaza (NoteType 1) o elimina
+// (NoteType 2) al documento predecesor ya aceptado
+type ElectronicPayrollDocument struct {
+	ID            uint   `gorm:"primaryKey"`
+	TenantID      uint   `gorm:"not null;index;uniqueIndex:idx_eldoc_tenant_number,composite:tenant_number"`
+	EmployeeID    uint   `gorm:"not null;index"`
+	Year          int    `gorm:"not null;index:idx_eldoc_period"`
+	Month         int    `gorm:"not null;index:idx_eldoc_period"`
+	DocumentType  string `gorm:"size:3;not null"`
+	NoteType      string `gorm:"size:1"`
+	Prefix        string `gorm:"size:10;not null"`
+	Consecutive   int    `gorm:"not null"`
+	Number        string `gorm:"size:20;not null;uniqueIndex:idx_eldoc_tenant_number,composite:tenant_number"`
+	CUNE          string `gorm:"size:96;index"`
+	PredecessorID *uint  `gorm:"index"`
+	// PayrollIDs son las nóminas incluidas, separadas por coma; Fingerprint detecta cambios
+	PayrollIDs      string `gorm:"size:500"`
+	Fingerprint     string `gorm:"size:64"`
+	TotalEarnings   float64
+	TotalDeductions float64
+	NetAmount       float64
+	XML             []byte
+	Status          string `gorm:"size:20;not null;default:'generated';index"` // generated, accepted, rejected, superseded
+	IssuedAt        time.Time
+	GeneratedBy     uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	Predecessor *ElectronicPayrollDocument    `gorm:"foreignKey:PredecessorID"`
+	Submissions []ElectronicPayrollSubmission `gorm:"foreignKey:DocumentID"`
+}
+
+// ElectronicPayrollSubmission registra cada transmisión de un documento a la DIAN
+type ElectronicPayrollSubmission struct {
+	ID          uint   `gorm:"primaryKey"`
+	TenantID    uint   `gorm:"not null;index"`
+	DocumentID  uint   `gorm:"not null;index"`
+	Client      string `gorm:"size:30"`
+	TrackID     string `gorm:"size:100"`
+	Status      string `gorm:"size:20;not null"` // accepted, rejected, error
+	Messages    string `gorm:"size:2000"`
+	SubmittedBy uint
+	CreatedAt   time.Time
+}
+
+// Tipos de ausencia; cada uno corresponde a una novedad de la planilla PILA
+const (
+	AbsenceVacation     = "vacation"      // VAC: vacaciones disfrutadas
+	AbsencePaidLeave    = "paid_leave"    // LR: licencia remunerada
+	AbsenceUnpaidLeave  = "unpaid_leave"  // SLN: licencia no remunerada o suspensión
+	AbsenceSickLeave    = "sick_leave"    // IGE: incapacidad por enfermedad general
+	AbsenceMaternity    = "maternity"     // LMA: licencia de maternidad o paternidad
+	AbsenceWorkAccident = "work_accident" // IRL: incapacidad por accidente o enfermedad laboral
+)
+
+// EmployeeAbsence es una ausencia del empleado entre StartDate y EndDate (ambos inclusive).
+// Reference guarda el número de autorización de la incapacidad o licencia.
+type EmployeeAbsence struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID uint      `gorm:"not null;index" json:"employee_id"`
+	Kind       string    `gorm:"size:20;not null" json:"kind"`
+	StartDate  time.Time `gorm:"not null;index" json:"start_date"`
+	EndDate    time.Time `gorm:"not null;index" json:"end_date"`
+	Reference  string    `gorm:"size:30" json:"reference"`
+	Notes      string    `gorm:"size:255" json:"notes"`
+	CreatedBy  uint      `json:"created_by"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Códigos de los acumulados anuales que no corresponden a un concepto de nómina
+const (
+	AccumulatorGross            = "GROSS"
+	AccumulatorContributionBase = "CONTRIBUTION_BASE"
+	// AccumulatorExemptIncome es la renta exenta del 25% ya aplicada en la retención del año
+	AccumulatorExemptIncome = "EXEMPT_INCOME"
+	AccumulatorTypeTotal    = "total"
+)
+
+// PayrollAccumulator acumula en el año (por fecha de pago) un valor del empleado: cada
+// devengo y deducción por código de concepto, y los totales de devengado, base de aportes y
+// renta exenta. Se actualiza al pagar una nómina y alimenta la retención y el certificado.
+type PayrollAccumulator struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;uniqueIndex:idx_accumulator_code" json:"tenant_id"`
+	EmployeeID uint      `gorm:"not null;uniqueIndex:idx_accumulator_code" json:"employee_id"`
+	Year       int       `gorm:"not null;uniqueIndex:idx_accumulator_code" json:"year"`
+	Code       string    `gorm:"size:40;not null;uniqueIndex:idx_accumulator_code" json:"code"`
+	Type       string    `gorm:"size:25" json:"type"` // earning | deduction | total
+	Amount     float64   `gorm:"not null;default:0" json:"amount"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CostCenter es un centro de costo o proyecto del tenant al que se imputa el costo de nómina
+type CostCenter struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"not null;uniqueIndex:idx_cost_center_code" json:"tenant_id"`
+	Code      string    `gorm:"size:20;not null;uniqueIndex:idx_cost_center_code" json:"code"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CostCenterAllocation es el porcentaje del costo de un empleado que va a un centro de costo
+// desde EffectiveFrom. Las asignaciones con la misma fecha forman una versión que suma 100%
+// y rige hasta la siguiente versión.
+type CostCenterAllocation struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TenantID      uint       `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID    uint       `gorm:"not null;index" json:"employee_id"`
+	CostCenterID  uint       `gorm:"not null;index" json:"cost_center_id"`
+	Percentage    float64    `gorm:"not null" json:"percentage"`
+	EffectiveFrom time.Time  `gorm:"type:date;not null" json:"effective_from"`
+	CreatedBy     uint       `json:"created_by"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	CostCenter    CostCenter `gorm:"foreignKey:CostCenterID" json:"cost_center,omitzero"`
+}
+
+// Estados de conciliación de una línea del extracto
+const (
+	StatementLineMatched    = "matched"
+	StatementLineUnmatched  = "unmatched"
+	StatementLineDifference = "difference"
+	StatementLineDuplicate  = "duplicate"
+	StatementLineResolved   = "resolved"
+	StatementLineIgnored    = "ignored"
+)
+
+// BankStatement es un extracto bancario importado para conciliar los pagos de nómina
+type BankStatement struct {
+	ID             uint   `gorm:"primaryKey"`
+	TenantID       uint   `gorm:"not null;index"`
+	Format         string `gorm:"size:20;not null"`
+	FileName       string `gorm:"size:255"`
+	Hash           string `gorm:"size:64;index"` // SHA-256 del archivo, evita importarlo dos veces
+	Reference      string `gorm:"size:100"`
+	AccountNumber  string `gorm:"size:50"`
+	LineCount      int
+	MatchedCount   int
+	ExceptionCount int
+	ImportedBy     uint
+	CreatedAt      time.Time
+
+	Lines []BankStatementLine `gorm:"foreignKey:StatementID"`
+}
+
+// BankStatementLine es un movimiento del extracto con el resultado de la conciliación.
+// Difference es el monto del extracto menos el monto del pago encontrado.
+type BankStatementLine struct {
+	ID            uint `gorm:"primaryKey"`
+	TenantID      uint `gorm:"not null;index"`
+	StatementID   uint `gorm:"not null;index"`
+	LineNumber    int
+	BookingDate   *time.Time
+	Amount        float64
+	Debit         bool
+	AccountNumber string `gorm:"size:50"`
+	Reference     string `gorm:"size:100;index"`
+	Description   string `gorm:"size:255"`
+	Status        string `gorm:"size:20;index"`
+	PaymentID     *uint  `gorm:"index"`
+	Difference    float64
+	Note          string `gorm:"size:255"`
+	ResolvedBy    uint
+	ResolvedAt    *time.Time
+}
+
+// Secciones de una plantilla de ancho fijo
+const (
+	BankFileSectionHeader  = "header"
+	BankFileSectionDetail  = "detail"
+	BankFileSectionTrailer = "trailer"
+)
+
+// BankFileTemplate describe el archivo plano de ancho fijo que pide un banco: un registro
+// de encabezado, uno por transferencia y uno de totales
+type BankFileTemplate struct {
+	ID        uint   `gorm:"primaryKey"`
+	TenantID  uint   `gorm:"not null;index"`
+	Name      string `gorm:"size:100;not null"`
+	BankName  string `gorm:"size:100"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Fields []BankFileTemplateField `gorm:"foreignKey:TemplateID"`
+}
+
+// BankFileTemplateField es una columna de la plantilla. Source indica el dato que se
+// escribe (employee_dni, amount, count, ...) o constant para un texto fijo.
+type BankFileTemplateField struct {
+	ID         uint   `gorm:"primaryKey"`
+	TemplateID uint   `gorm:"not null;index"`
+	Section    string `gorm:"size:10;not null"`
+	Position   int
+	Source     string `gorm:"size:30;not null"`
+	Value      string `gorm:"size:100"`
+	Width      int
+	Align      string `gorm:"size:5"`
+	PadChar    string `gorm:"size:1"`
+}
+
 // ========================================
 // Métodos de conveniencia
 // ========================================
@@ -308,6 +909,14 @@ func (Payroll) TableName() string {
 	return "payrolls"
 }
 
+func (PayrollStatusHistory) TableName() string {
+	return "payroll_status_history"
+}
+
+func (PayrollStatusTransition) TableName() string {
+	return "payroll_status_transitions"
+}
+
 func (PayrollItem) TableName() string {
 	return "payroll_items"
 }
@@ -320,6 +929,127 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
+func (AccountingPeriod) TableName() string {
+	return "accounting_periods"
+}
+
+// BenefitLedgerEntry es un movimiento del ledger de prestaciones de un empleado:
+// causaciones (positivas) desde nóminas pagadas y pagos (negativos) al liquidarlas
+type BenefitLedgerEntry struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TenantID    uint      `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID  uint      `gorm:"not null;index" json:"employee_id"`
+	PayrollID   uint      `gorm:"not null;index" json:"payroll_id"`
+	Benefit     string    `gorm:"size:30;not null;index" json:"benefit"`
+	EntryType   string    `gorm:"size:20;not null" json:"entry_type"`
+	Amount      float64   `gorm:"not null" json:"amount"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// BenefitBalance es el saldo acumulado de una prestación para un empleado
+type BenefitBalance struct {
+	EmployeeID uint    `json:"employee_id"`
+	Benefit    string  `json:"benefit"`
+	Balance    float64 `json:"balance"`
+}
+
+// EmployeeTermination registra la terminación de un contrato y su liquidación final.
+// SignedAt queda en nil hasta que el empleado firma el documento de liquidación.
+type EmployeeTermination struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	TenantID            uint       `gorm:"not null;index" json:"tenant_id"`
+	EmployeeID          uint       `gorm:"not null;index" json:"employee_id"`
+	ContractID          uint       `gorm:"not null;index" json:"contract_id"`
+	SettlementPayrollID uint       `gorm:"index" json:"settlement_payroll_id"`
+	TerminationDate     time.Time  `gorm:"not null" json:"termination_date"`
+	Reason              string     `gorm:"size:255;not null" json:"reason"`
+	JustCause           bool       `json:"just_cause"`
+	DaysWorked          int        `json:"days_worked"`
+	IndemnificationDays float64    `json:"indemnification_days"`
+	CreatedBy           uint       `json:"created_by"`
+	SignedAt            *time.Time `json:"signed_at,omitempty"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (EmployeeTermination) TableName() string {
+	return "employee_terminations"
+}
+
+func (BenefitLedgerEntry) TableName() string {
+	return "benefit_ledger_entries"
+}
+
+func (RetroAdjustment) TableName() string {
+	return "retro_adjustments"
+}
+
+func (EmployeeConcept) TableName() string {
+	return "employee_concepts"
+}
+
+func (EmployeeBankAccount) TableName() string {
+	return "employee_bank_accounts"
+}
+
+func (PayrollItemAllocation) TableName() string {
+	return "payroll_item_allocations"
+}
+
+func (BankPaymentFile) TableName() string {
+	return "bank_payment_files"
+}
+
+func (BankFileTemplate) TableName() string {
+	return "bank_file_templates"
+}
+
+func (BankFileTemplateField) TableName() string {
+	return "bank_file_template_fields"
+}
+
+func (BankStatement) TableName() string {
+	return "bank_statements"
+}
+
+func (BankStatementLine) TableName() string {
+	return "bank_statement_lines"
+}
+
+func (PayslipTemplate) TableName() string {
+	return "payslip_templates"
+}
+
+func (NotificationOutbox) TableName() string {
+	return "notification_outboxes"
+}
+
+func (ElectronicPayrollDocument) TableName() string {
+	return "electronic_payroll_documents"
+}
+
+func (ElectronicPayrollSubmission) TableName() string {
+	return "electronic_payroll_submissions"
+}
+
+func (EmployeeAbsence) TableName() string {
+	return "employee_absences"
+}
+
+func (PayrollAccumulator) TableName() string {
+	return "payroll_accumulators"
+}
+
+func (CostCenter) TableName() string {
+	return "cost_centers"
+}
+
+func (CostCenterAllocation) TableName() string {
+	return "cost_center_allocations"
+}
+
 // ========================================
 // Métodos de User para verificar permisos
 // ========================================
@@ -15,5 +15,77 @@ func DefaultPayrollConcepts() []PayrollConcept {
 		{Code: ConceptHealthEmployer, Name: "Aporte Salud Empleador", Type: PayrollTypeEmployerContribution, IsMandatory: true},
 		{Code: ConceptPensionEmployer, Name: "Aporte Pensión Empleador", Type: PayrollTypeEmployerContribution, IsMandatory: true},
 		{Code: ConceptParafiscales, Name: "Parafiscales", Type: PayrollTypeEmployerContribution, IsMandatory: true},
+		{Code: ConceptPrimaProvision, Name: "Provisión Prima de Servicios", Type: PayrollTypeEmployerContribution, Percentage: 8.33, IsMandatory: true},
+		{Code: ConceptSeveranceProvision, Name: "Provisión Cesantías", Type: PayrollTypeEmployerContribution, Percentage: 8.33, IsMandatory: true},
+		{Code: ConceptSeveranceInterestProvision, Name: "Provisión Intereses Cesantías", Type: PayrollTypeEmployerContribution, Percentage: 1, IsMandatory: true},
+		{Code: ConceptVacationProvision, Name: "Provisión Vacaciones", Type: PayrollTypeEmployerContribution, Percentage: 4.17, IsMandatory: true},
+	}
+}
+
+// DefaultConceptAccounts relaciona cada concepto con sus cuentas del PUC (Decreto 2650 de 1993).
+// Los devengos causan el gasto contra salarios por pagar (250505); las deducciones trasladan
+// parte de ese pasivo a las entidades de salud, pensión y DIAN; los aportes del empleador y las
+// provisiones causan el gasto contra su pasivo; los pagos de prestaciones consumen la provisión.
+func DefaultConceptAccounts() map[string]LedgerAccounts {
+	return map[string]LedgerAccounts{
+		ConceptBaseSalary:                 {Debit: "510506", Credit: "250505"},
+		ConceptTransport:                  {Debit: "510527", Credit: "250505"},
+		ConceptHousing:                    {Debit: "510545", Credit: "250505"},
+		ConceptOvertime:                   {Debit: "510515", Credit: "250505"},
+		ConceptBonus:                      {Debit: "510548", Credit: "250505"},
+		ConceptApprenticeStipend:          {Debit: "510595", Credit: "250505"},
+		ConceptIndemnification:            {Debit: "510560", Credit: "250505"},
+		ConceptHealth:                     {Debit: "250505", Credit: "237005"},
+		ConceptPension:                    {Debit: "250505", Credit: "238030"},
+		ConceptTax:                        {Debit: "250505", Credit: "236505"},
+		ConceptOtherDeduction:             {Debit: "250505", Credit: "237095"},
+		ConceptHealthEmployer:             {Debit: "510569", Credit: "237005"},
+		ConceptPensionEmployer:            {Debit: "510570", Credit: "238030"},
+		ConceptParafiscales:               {Debit: "510572", Credit: "237010"},
+		ConceptSeveranceProvision:         {Debit: "510530", Credit: "261005"},
+		ConceptSeveranceInterestProvision: {Debit: "510533", Credit: "261010"},
+		ConceptVacationProvision:          {Debit: "510539", Credit: "261015"},
+		ConceptPrimaProvision:             {Debit: "510536", Credit: "261020"},
+		ConceptSeverancePayment:           {Debit: "261005", Credit: "250505"},
+		ConceptSeveranceInterestPayment:   {Debit: "261010", Credit: "250505"},
+		ConceptVacationPayment:            {Debit: "261015", Credit: "250505"},
+		ConceptPrimaPayment:               {Debit: "261020", Credit: "250505"},
+	}
+}
+
+// DefaultContractTypes retorna los tipos de contrato base con sus reglas
+func DefaultContractTypes() []ContractType {
+	return []ContractType{
+		{Code: ContractTypeIndefinite, Name: "Término Indefinido", IsActive: true},
+		{Code: ContractTypeFixedTerm, Name: "Término Fijo", RequiresEndDate: true, RenewalAlertDays: 30, IsActive: true},
+		{Code: ContractTypeApprenticeship, Name: "Aprendizaje", Description: "Apoyo de sostenimiento sin aporte a pensión",
+			RequiresEndDate: true, SkipPension: true, StipendPercentage: 75, NoSocialBenefits: true, IsActive: true},
+		{Code: ContractTypeService, Name: "Prestación de Servicios", Description: "Sin deducciones de nómina; el contratista acredita sus aportes",
+			NoDeductions: true, RequiresSocialSecurityProof: true, NoSocialBenefits: true, IsActive: true},
+	}
+}
+
+// BenefitProvisionConcepts relaciona cada concepto de provisión con la prestación del ledger
+func BenefitProvisionConcepts() map[string]string {
+	return map[string]string{
+		ConceptPrimaProvision:             BenefitPrima,
+		ConceptSeveranceProvision:         BenefitSeverance,
+		ConceptSeveranceInterestProvision: BenefitSeveranceInterest,
+		ConceptVacationProvision:          BenefitVacation,
+	}
+}
+
+// DefaultPayrollTransitions retorna la tabla de transiciones de estado por defecto
+// draft -> calculated -> approved -> paid, con cancelación y reversión a draft
+// antes del pago. Solo una nómina aprobada puede pagarse; un pago parcial la deja en
+// partially_paid hasta completar el neto. paid, cancelled y reversed son estados terminales.
+func DefaultPayrollTransitions() map[string][]string {
+	return map[string][]string{
+		PayrollStatusDraft:         {PayrollStatusCalculated, PayrollStatusDraft, PayrollStatusCancelled},
+		PayrollStatusCalculated:    {PayrollStatusApproved, PayrollStatusDraft, PayrollStatusCancelled},
+		PayrollStatusApproved:      {PayrollStatusPartiallyPaid, PayrollStatusPaid, PayrollStatusDraft, PayrollStatusCancelled},
+		PayrollStatusPartiallyPaid: {PayrollStatusPaid},
+		PayrollStatusPaid:          {},
+		PayrollStatusCancelled:     {},
 	}
 }
@@ -2,6 +2,9 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -64,3 +67,144 @@ func (u *User) ValidateAll() error {
 	}
 	return nil
 }
+
+// bankAccountFormats son los formatos de número de cuenta conocidos por banco. Los bancos
+// que no están en la tabla solo exigen entre 6 y 20 dígitos.
+var bankAccountFormats = map[string]*regexp.Regexp{
+	"BANCOLOMBIA":        regexp.MustCompile(`^\d{11}$`),
+	"BANCO DE BOGOTA":    regexp.MustCompile(`^\d{9}$`),
+	"DAVIVIENDA":         regexp.MustCompile(`^\d{12}$`),
+	"BBVA":               regexp.MustCompile(`^\d{9,10}$`),
+	"BANCO DE OCCIDENTE": regexp.MustCompile(`^\d{9}$`),
+	"NEQUI":              regexp.MustCompile(`^3\d{9}$`),
+	"DAVIPLATA":          regexp.MustCompile(`^3\d{9}$`),
+}
+
+var defaultAccountFormat = regexp.MustCompile(`^\d{6,20}$`)
+
+// Normalize limpia la cuenta: el número queda sin espacios ni guiones
+func (a *EmployeeBankAccount) Normalize() {
+	a.BankName = strings.TrimSpace(a.BankName)
+	a.AccountType = strings.ToLower(strings.TrimSpace(a.AccountType))
+	a.AccountNumber = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(a.AccountNumber))
+}
+
+// Validate valida el tipo de cuenta y el número según el formato del banco
+func (a *EmployeeBankAccount) Validate() error {
+	a.Normalize()
+	if a.BankName == "" {
+		return ErrBankNameRequired
+	}
+	if a.AccountType != BankAccountSavings && a.AccountType != BankAccountChecking {
+		return ErrInvalidAccountType
+	}
+	if !ValidAccountNumber(a.BankName, a.AccountNumber) {
+		return ErrInvalidAccountNumber
+	}
+	if a.SplitPercentage < 0 || a.SplitPercentage > 100 {
+		return ErrInvalidPaymentSplit
+	}
+	return nil
+}
+
+// ValidAccountNumber indica si el número cumple el formato del banco
+func ValidAccountNumber(bankName, number string) bool {
+	format, ok := bankAccountFormats[strings.ToUpper(strings.TrimSpace(bankName))]
+	if !ok {
+		format = defaultAccountFormat
+	}
+	return format.MatchString(number)
+}
+
+// bankFileSources son los datos que puede escribir cada sección de una plantilla de ancho fijo
+var bankFileSources = map[string][]string{
+	BankFileSectionHeader: {"constant", "reference", "date", "execution_date", "debtor_name", "debtor_id",
+		"debtor_account", "debtor_account_type", "count", "total"},
+	BankFileSectionDetail: {"constant", "reference", "sequence", "end_to_end_id", "employee_dni", "employee_name",
+		"bank_name", "account_type", "account_number", "amount"},
+	BankFileSectionTrailer: {"constant", "reference", "count", "total", "hash"},
+}
+
+// Validate valida las columnas de la plantilla y las ordena por sección y posición
+func (t *BankFileTemplate) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidBankFileTemplate)
+	}
+	hasDetail := false
+	for i := range t.Fields {
+		f := &t.Fields[i]
+		f.Section = strings.ToLower(strings.TrimSpace(f.Section))
+		f.Source = strings.ToLower(strings.TrimSpace(f.Source))
+		f.Align = strings.ToLower(strings.TrimSpace(f.Align))
+		sources, ok := bankFileSources[f.Section]
+		if !ok {
+			return fmt.Errorf("%w: unknown section %q", ErrInvalidBankFileTemplate, f.Section)
+		}
+		if !slices.Contains(sources, f.Source) {
+			return fmt.Errorf("%w: source %q is not allowed in %s", ErrInvalidBankFileTemplate, f.Source, f.Section)
+		}
+		if f.Width <= 0 {
+			return fmt.Errorf("%w: field %s width must be greater than 0", ErrInvalidBankFileTemplate, f.Source)
+		}
+		if f.Align == "" {
+			f.Align = "left"
+		}
+		if f.Align != "left" && f.Align != "right" {
+			return fmt.Errorf("%w: align must be left or right", ErrInvalidBankFileTemplate)
+		}
+		if f.PadChar == "" {
+			f.PadChar = " "
+		}
+		if len(f.PadChar) != 1 {
+			return fmt.Errorf("%w: pad char must be a single character", ErrInvalidBankFileTemplate)
+		}
+		hasDetail = hasDetail || f.Section == BankFileSectionDetail
+	}
+	if !hasDetail {
+		return fmt.Errorf("%w: at least one detail field is required", ErrInvalidBankFileTemplate)
+	}
+	sectionOrder := map[string]int{BankFileSectionHeader: 0, BankFileSectionDetail: 1, BankFileSectionTrailer: 2}
+	slices.SortStableFunc(t.Fields, func(a, b BankFileTemplateField) int {
+		if a.Section != b.Section {
+			return sectionOrder[a.Section] - sectionOrder[b.Section]
+		}
+		return a.Position - b.Position
+	})
+	return nil
+}
+
+var hexColorFormat = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate limpia los textos de la plantilla y valida el color
+func (t *PayslipTemplate) Validate() error {
+	t.CompanyName = strings.TrimSpace(t.CompanyName)
+	t.CompanyNIT = strings.TrimSpace(t.CompanyNIT)
+	t.Title = strings.TrimSpace(t.Title)
+	t.PrimaryColor = strings.TrimSpace(t.PrimaryColor)
+	if t.PrimaryColor != "" && !hexColorFormat.MatchString(t.PrimaryColor) {
+		return fmt.Errorf("%w: primary color must use the format #RRGGBB", ErrInvalidPayslipTemplate)
+	}
+	return nil
+}
+
+var absenceKinds = []string{AbsenceVacation, AbsencePaidLeave, AbsenceUnpaidLeave, AbsenceSickLeave, AbsenceMaternity, AbsenceWorkAccident}
+
+// Validate valida el tipo y las fechas de la ausencia
+func (a *EmployeeAbsence) Validate() error {
+	a.Kind = strings.ToLower(strings.TrimSpace(a.Kind))
+	a.Reference = strings.TrimSpace(a.Reference)
+	a.Notes = strings.TrimSpace(a.Notes)
+	if !slices.Contains(absenceKinds, a.Kind) {
+		return ErrInvalidAbsenceKind
+	}
+	if a.StartDate.IsZero() || a.EndDate.Before(a.StartDate) {
+		return ErrInvalidAbsenceDate
+	}
+	return nil
+}
+
+// Overlaps indica si la ausencia se cruza con el rango [start, end]
+func (a *EmployeeAbsence) Overlaps(start, end time.Time) bool {
+	return !a.StartDate.After(end) && !a.EndDate.Before(start)
+}
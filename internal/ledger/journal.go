@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Posting identifica el lado de un movimiento: cuenta, centro de costo y tercero
+type Posting struct {
+	Account     string
+	CostCenter  string
+	ThirdParty  string
+	Description string
+}
+
+// Line es un movimiento del asiento; solo uno de Debit o Credit tiene valor
+type Line struct {
+	Account     string  `json:"account"`
+	CostCenter  string  `json:"cost_center,omitempty"`
+	ThirdParty  string  `json:"third_party,omitempty"`
+	Description string  `json:"description"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+}
+
+type lineKey struct {
+	account, costCenter, thirdParty string
+	debit                           bool
+}
+
+// Entry es un asiento contable de partida doble. Los movimientos de la misma cuenta, centro
+// de costo, tercero y naturaleza se acumulan en una sola línea.
+type Entry struct {
+	Reference   string    `json:"reference"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Lines       []Line    `json:"lines"`
+
+	index map[lineKey]int
+}
+
+func NewEntry(reference string, date time.Time, description string) *Entry {
+	return &Entry{
+		Reference:   reference,
+		Date:        date,
+		Description: description,
+		Lines:       []Line{},
+		index:       map[lineKey]int{},
+	}
+}
+
+// Post registra un valor en débito a una cuenta y en crédito a la otra; un valor negativo
+// (reverso) invierte la naturaleza de ambos lados
+func (e *Entry) Post(debit, credit Posting, amount float64) {
+	if amount < 0 {
+		debit, credit = credit, debit
+		amount = -amount
+	}
+	if amount == 0 {
+		return
+	}
+	e.add(debit, amount, true)
+	e.add(credit, amount, false)
+}
+
+func (e *Entry) add(p Posting, amount float64, debit bool) {
+	key := lineKey{account: p.Account, costCenter: p.CostCenter, thirdParty: p.ThirdParty, debit: debit}
+	i, ok := e.index[key]
+	if !ok {
+		i = len(e.Lines)
+		e.index[key] = i
+		e.Lines = append(e.Lines, Line{
+			Account:     p.Account,
+			CostCenter:  p.CostCenter,
+			ThirdParty:  p.ThirdParty,
+			Description: p.Description,
+		})
+	}
+	if debit {
+		e.Lines[i].Debit = round(e.Lines[i].Debit + amount)
+	} else {
+		e.Lines[i].Credit = round(e.Lines[i].Credit + amount)
+	}
+}
+
+// Totals suma los débitos y los créditos del asiento
+func (e *Entry) Totals() (debit, credit float64) {
+	for _, l := range e.Lines {
+		debit += l.Debit
+		credit += l.Credit
+	}
+	return round(debit), round(credit)
+}
+
+// Balanced indica si el asiento tiene movimientos y los débitos son iguales a los créditos
+func (e *Entry) Balanced() bool {
+	debit, credit := e.Totals()
+	return len(e.Lines) > 0 && math.Abs(debit-credit) < 0.005
+}
+
+// CSV exporta el asiento con una fila por movimiento y una fila final TOTAL con las sumas
+func (e *Entry) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	date := e.Date.Format("2006-01-02")
+	rows := [][]string{{"reference", "date", "account", "cost_center", "third_party", "description", "debit", "credit"}}
+	for _, l := range e.Lines {
+		rows = append(rows, []string{e.Reference, date, l.Account, l.CostCenter, l.ThirdParty, l.Description,
+			formatAmount(l.Debit), formatAmount(l.Credit)})
+	}
+	debit, credit := e.Totals()
+	rows = append(rows, []string{"TOTAL", date, "", "", "", strconv.Itoa(len(e.Lines)), formatAmount(debit), formatAmount(credit)})
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
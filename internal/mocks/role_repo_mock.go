@@ -62,9 +62,9 @@ func (m *MockRoleRepo) Delete(ctx context.Context, roleID uint) error {
 	return args.Error(0)
 }
 
-// AssignPermission provides a mock function with given fields: ctx, roleID, actionID
-func (m *MockRoleRepo) AssignPermission(ctx context.Context, roleID, actionID uint) error {
-	args := m.Called(ctx, roleID, actionID)
+// AssignPermission provides a mock function with given fields: ctx, roleID, actionID, scope
+func (m *MockRoleRepo) AssignPermission(ctx context.Context, roleID, actionID uint, scope string) error {
+	args := m.Called(ctx, roleID, actionID, scope)
 	return args.Error(0)
 }
 
@@ -0,0 +1,77 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ErrUnknownReference indica que el proveedor no conoce la referencia consultada
+var ErrUnknownReference = errors.New("unknown payout reference")
+
+// FakeProvider simula un banco en memoria: acepta los pagos, los deja sent y los completa en
+// la siguiente consulta. Las cuentas terminadas en 0000 se rechazan para probar las fallas.
+type FakeProvider struct {
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*fakePayout
+}
+
+type fakePayout struct {
+	result domain.PayoutResult
+	polls  int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{payments: make(map[string]*fakePayout)}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Submit(ctx context.Context, payment *domain.Payment) (string, error) {
+	if payment.AccountNumber == "" {
+		return "", errors.New("payment has no destination account")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	ref := fmt.Sprintf("FAKE-%06d", p.seq)
+	result := domain.PayoutResult{Status: domain.PaymentStatusSent}
+	if strings.HasSuffix(payment.AccountNumber, "0000") {
+		result = domain.PayoutResult{Status: domain.PaymentStatusFailed, FailureReason: "account does not exist"}
+	}
+	p.payments[ref] = &fakePayout{result: result}
+	return ref, nil
+}
+
+func (p *FakeProvider) Status(ctx context.Context, reference string) (domain.PayoutResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payout, ok := p.payments[reference]
+	if !ok {
+		return domain.PayoutResult{}, ErrUnknownReference
+	}
+	// El rechazo se informa en la primera consulta; los demás se liquidan en la segunda
+	payout.polls++
+	if payout.result.Status == domain.PaymentStatusSent && payout.polls > 1 {
+		payout.result.Status = domain.PaymentStatusCompleted
+	}
+	return payout.result, nil
+}
+
+// NewProvider retorna el proveedor configurado por nombre
+func NewProvider(name string) (domain.PayoutProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "fake":
+		return NewFakeProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown payout provider %q", name)
+}
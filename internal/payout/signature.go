@@ -0,0 +1,28 @@
+package payout
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader es el encabezado con la firma HMAC-SHA256 (hex) del cuerpo del callback
+const SignatureHeader = "X-Payout-Signature"
+
+// Sign firma el cuerpo de un callback con el secreto compartido con el proveedor
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature compara en tiempo constante la firma recibida con la del cuerpo. Sin
+// secreto configurado ninguna firma es válida.
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	expected := Sign(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(strings.TrimSpace(signature))))
+}
@@ -0,0 +1,198 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Tamaño carta en puntos
+const (
+	PageWidth  = 612.0
+	PageHeight = 792.0
+)
+
+// Style es el estilo de la fuente Helvetica
+type Style int
+
+const (
+	Regular Style = iota
+	Bold
+)
+
+// Color es un color RGB
+type Color struct{ R, G, B uint8 }
+
+var Black = Color{0, 0, 0}
+
+// Document arma un PDF sencillo con texto Helvetica, líneas, rectángulos e imágenes.
+// Las coordenadas van en puntos con origen en la esquina superior izquierda de la página.
+type Document struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	images  []*pdfImage
+	style   Style
+	size    float64
+	color   Color
+}
+
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+func New() *Document {
+	return &Document{size: 10, color: Black}
+}
+
+// AddPage agrega una página en blanco y la deja como actual
+func (d *Document) AddPage() {
+	d.current = new(bytes.Buffer)
+	d.pages = append(d.pages, d.current)
+}
+
+// SetFont cambia el estilo y el tamaño del texto
+func (d *Document) SetFont(style Style, size float64) {
+	d.style = style
+	d.size = size
+}
+
+// SetTextColor cambia el color del texto y de los rellenos
+func (d *Document) SetTextColor(c Color) {
+	d.color = c
+}
+
+// Text escribe el texto con la línea base en (x, y)
+func (d *Document) Text(x, y float64, s string) {
+	font := "F1"
+	if d.style == Bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current, "q %s rg BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET Q\n",
+		rgb(d.color), font, d.size, x, PageHeight-y, escape(encodeWinAnsi(s)))
+}
+
+// TextRight escribe el texto alineado a la derecha en x
+func (d *Document) TextRight(x, y float64, s string) {
+	d.Text(x-d.StringWidth(s), y, s)
+}
+
+// StringWidth es el ancho del texto con la fuente actual
+func (d *Document) StringWidth(s string) float64 {
+	return StringWidth(s, d.style, d.size)
+}
+
+// Line dibuja una línea gris de 0.5 puntos
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current, "q 0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S Q\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// FillRect dibuja un rectángulo relleno con el color dado
+func (d *Document) FillRect(x, y, w, h float64, c Color) {
+	fmt.Fprintf(d.current, "q %s rg %.2f %.2f %.2f %.2f re f Q\n", rgb(c), x, PageHeight-y-h, w, h)
+}
+
+// Image dibuja la imagen en el rectángulo dado. La transparencia se mezcla sobre blanco.
+func (d *Document) Image(img image.Image, x, y, w, h float64) error {
+	b := img.Bounds()
+	raw := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			r, g, bl, a := img.At(px, py).RGBA()
+			// Mezcla sobre fondo blanco con valores de 16 bits
+			white := 0xffff - a
+			raw = append(raw, uint8((r+white)>>8), uint8((g+white)>>8), uint8((bl+white)>>8))
+		}
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	d.images = append(d.images, &pdfImage{width: b.Dx(), height: b.Dy(), data: buf.Bytes()})
+	fmt.Fprintf(d.current, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, PageHeight-y-h, len(d.images))
+	return nil
+}
+
+// Bytes serializa el documento
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+		return len(offsets)
+	}
+	stream := func(dict string, data []byte) int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(offsets), dict, len(data))
+		out.Write(data)
+		out.WriteString("\nendstream\nendobj\n")
+		return len(offsets)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// 1 catálogo y 2 árbol de páginas; las páginas se numeran después de fuentes e imágenes
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	firstPage := 5 + len(d.images)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	var xobjects []string
+	for i, img := range d.images {
+		n := stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.data)
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, n))
+	}
+	resources := "<< /Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(xobjects) > 0 {
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+	resources += " >>"
+
+	for _, content := range d.pages {
+		pageNum := len(offsets) + 1
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+			PageWidth, PageHeight, resources, pageNum+1))
+		stream("", content.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+func rgb(c Color) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+func escape(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String()
+}
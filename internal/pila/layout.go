@@ -0,0 +1,214 @@
+package pila
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tipos de campo: los alfanuméricos se alinean a la izquierda con espacios y los numéricos a
+// la derecha con ceros
+const (
+	alpha = iota
+	numeric
+)
+
+// field es una columna de un registro de ancho fijo
+type field[T any] struct {
+	name  string
+	width int
+	kind  int
+	value func(*T) string
+}
+
+// headerFields es el registro tipo 1 (encabezado del aportante), 359 posiciones
+var headerFields = []field[Header]{
+	{"tipo_registro", 2, numeric, constant[Header]("1")},
+	{"modalidad_planilla", 1, numeric, constant[Header]("1")},
+	{"secuencia", 4, numeric, constant[Header]("1")},
+	{"razon_social", 200, alpha, func(h *Header) string { return h.Name }},
+	{"tipo_documento_aportante", 2, alpha, func(h *Header) string { return "NI" }},
+	{"numero_documento_aportante", 16, alpha, func(h *Header) string { return h.NIT }},
+	{"digito_verificacion", 1, numeric, func(h *Header) string { return h.DV }},
+	{"tipo_planilla", 1, alpha, func(h *Header) string { return "E" }},
+	{"planilla_asociada", 10, alpha, blank[Header]},
+	{"fecha_planilla_asociada", 10, alpha, blank[Header]},
+	{"forma_presentacion", 1, alpha, func(h *Header) string { return "U" }},
+	{"codigo_sucursal", 10, alpha, func(h *Header) string { return h.Settings.BranchCode }},
+	{"nombre_sucursal", 40, alpha, func(h *Header) string { return h.Settings.BranchName }},
+	{"codigo_arl", 6, alpha, func(h *Header) string { return h.Settings.ARLCode }},
+	{"periodo_otros_subsistemas", 7, alpha, func(h *Header) string { return h.Period.Format("2006-01") }},
+	{"periodo_salud", 7, alpha, func(h *Header) string { return h.Period.AddDate(0, 1, 0).Format("2006-01") }},
+	{"numero_radicacion", 10, alpha, blank[Header]},
+	{"fecha_pago", 10, alpha, blank[Header]},
+	{"total_empleados", 5, numeric, func(h *Header) string { return fmt.Sprint(h.Employees) }},
+	{"valor_total_nomina", 12, numeric, func(h *Header) string { return amount(h.PayrollTotal) }},
+	{"tipo_aportante", 2, numeric, constant[Header]("1")},
+	{"codigo_operador", 2, numeric, func(h *Header) string { return h.Settings.OperatorCode }},
+}
+
+// lineFields es el registro tipo 2 (liquidación de un cotizante), 693 posiciones
+var lineFields = []field[Line]{
+	{"tipo_registro", 2, numeric, constant[Line]("2")},
+	{"secuencia", 5, numeric, func(l *Line) string { return fmt.Sprint(l.Sequence) }},
+	{"tipo_documento", 2, alpha, func(l *Line) string { return l.DocumentType }},
+	{"numero_documento", 16, alpha, func(l *Line) string { return l.DocumentNumber }},
+	{"tipo_cotizante", 2, numeric, func(l *Line) string { return l.ContributorType }},
+	{"subtipo_cotizante", 2, numeric, constant[Line]("0")},
+	{"extranjero_no_obligado", 1, alpha, blank[Line]},
+	{"colombiano_exterior", 1, alpha, blank[Line]},
+	{"departamento", 2, numeric, func(l *Line) string { return l.DepartmentCode }},
+	{"municipio", 3, numeric, func(l *Line) string { return l.CityCode }},
+	{"primer_apellido", 20, alpha, func(l *Line) string { return l.FirstSurname }},
+	{"segundo_apellido", 30, alpha, func(l *Line) string { return l.SecondSurname }},
+	{"primer_nombre", 20, alpha, func(l *Line) string { return l.FirstName }},
+	{"segundo_nombre", 30, alpha, func(l *Line) string { return l.OtherNames }},
+	{"ING", 1, alpha, func(l *Line) string { return mark(l.HireDate != nil) }},
+	{"RET", 1, alpha, func(l *Line) string { return mark(l.RetirementDate != nil) }},
+	{"TDE", 1, alpha, blank[Line]},
+	{"TAE", 1, alpha, blank[Line]},
+	{"TDP", 1, alpha, blank[Line]},
+	{"TAP", 1, alpha, blank[Line]},
+	{"VSP", 1, alpha, func(l *Line) string { return mark(l.SalaryChangeDate != nil) }},
+	{"COR", 1, alpha, blank[Line]},
+	{"VST", 1, alpha, blank[Line]},
+	{"SLN", 1, alpha, func(l *Line) string { return mark(l.UnpaidLeave != nil) }},
+	{"IGE", 1, alpha, func(l *Line) string { return mark(l.SickLeave != nil) }},
+	{"LMA", 1, alpha, func(l *Line) string { return mark(l.Maternity != nil) }},
+	{"VAC_LR", 1, alpha, func(l *Line) string { return l.vacationMark() }},
+	{"AVP", 1, alpha, blank[Line]},
+	{"VCT", 1, alpha, blank[Line]},
+	{"dias_IRL", 2, numeric, func(l *Line) string { return fmt.Sprint(NoveltyDays(l.WorkAccident)) }},
+	{"codigo_afp", 6, alpha, func(l *Line) string { return l.PensionFund }},
+	{"codigo_afp_traslado", 6, alpha, blank[Line]},
+	{"codigo_eps", 6, alpha, func(l *Line) string { return l.HealthFund }},
+	{"codigo_eps_traslado", 6, alpha, blank[Line]},
+	{"codigo_ccf", 6, alpha, func(l *Line) string { return l.CompensationFund }},
+	{"dias_pension", 2, numeric, func(l *Line) string { return fmt.Sprint(l.PensionDays) }},
+	{"dias_salud", 2, numeric, func(l *Line) string { return fmt.Sprint(l.HealthDays) }},
+	{"dias_arl", 2, numeric, func(l *Line) string { return fmt.Sprint(l.RiskDays) }},
+	{"dias_ccf", 2, numeric, func(l *Line) string { return fmt.Sprint(l.CCFDays) }},
+	{"salario_basico", 9, numeric, func(l *Line) string { return amount(l.Salary) }},
+	{"salario_integral", 1, alpha, func(l *Line) string { return mark(l.IntegralSalary) }},
+	{"ibc_pension", 9, numeric, func(l *Line) string { return amount(l.IBCPension) }},
+	{"ibc_salud", 9, numeric, func(l *Line) string { return amount(l.IBCHealth) }},
+	{"ibc_arl", 9, numeric, func(l *Line) string { return amount(l.IBCRisk) }},
+	{"ibc_ccf", 9, numeric, func(l *Line) string { return amount(l.IBCCCF) }},
+	{"tarifa_pension", 7, numeric, func(l *Line) string { return rate(l.PensionRate, 5) }},
+	{"cotizacion_pension", 9, numeric, func(l *Line) string { return amount(l.PensionValue) }},
+	{"aporte_voluntario_afiliado", 9, numeric, constant[Line]("0")},
+	{"aporte_voluntario_aportante", 9, numeric, constant[Line]("0")},
+	{"total_pension", 9, numeric, func(l *Line) string { return amount(l.PensionValue) }},
+	{"fsp_solidaridad", 9, numeric, func(l *Line) string { return amount(l.SolidarityValue) }},
+	{"fsp_subsistencia", 9, numeric, func(l *Line) string { return amount(l.SubsistenceValue) }},
+	{"valor_no_retenido", 9, numeric, constant[Line]("0")},
+	{"tarifa_salud", 7, numeric, func(l *Line) string { return rate(l.HealthRate, 5) }},
+	{"cotizacion_salud", 9, numeric, func(l *Line) string { return amount(l.HealthValue) }},
+	{"upc_adicional", 9, numeric, constant[Line]("0")},
+	{"autorizacion_incapacidad", 15, alpha, func(l *Line) string { return l.SickLeave.reference() }},
+	{"valor_incapacidad", 9, numeric, constant[Line]("0")},
+	{"autorizacion_lma", 15, alpha, func(l *Line) string { return l.Maternity.reference() }},
+	{"valor_lma", 9, numeric, constant[Line]("0")},
+	{"tarifa_arl", 9, numeric, func(l *Line) string { return rate(l.RiskRate, 7) }},
+	{"centro_trabajo", 9, numeric, constant[Line]("0")},
+	{"cotizacion_arl", 9, numeric, func(l *Line) string { return amount(l.RiskValue) }},
+	{"tarifa_ccf", 7, numeric, func(l *Line) string { return rate(l.CCFRate, 5) }},
+	{"valor_ccf", 9, numeric, func(l *Line) string { return amount(l.CCFValue) }},
+	{"tarifa_sena", 7, numeric, func(l *Line) string { return rate(l.SENARate, 5) }},
+	{"valor_sena", 9, numeric, func(l *Line) string { return amount(l.SENAValue) }},
+	{"tarifa_icbf", 7, numeric, func(l *Line) string { return rate(l.ICBFRate, 5) }},
+	{"valor_icbf", 9, numeric, func(l *Line) string { return amount(l.ICBFValue) }},
+	{"tarifa_esap", 7, numeric, func(l *Line) string { return rate(0, 5) }},
+	{"valor_esap", 9, numeric, constant[Line]("0")},
+	{"tarifa_men", 7, numeric, func(l *Line) string { return rate(0, 5) }},
+	{"valor_men", 9, numeric, constant[Line]("0")},
+	{"tipo_documento_principal", 2, alpha, blank[Line]},
+	{"documento_principal", 16, alpha, blank[Line]},
+	{"exonerado", 1, alpha, func(l *Line) string { return yesNo(l.Exonerated) }},
+	{"codigo_arl", 6, alpha, func(l *Line) string { return l.ARLCode }},
+	{"clase_riesgo", 1, numeric, func(l *Line) string { return fmt.Sprint(l.RiskClass) }},
+	{"tarifa_especial_pension", 1, alpha, blank[Line]},
+	{"fecha_ingreso", 10, alpha, func(l *Line) string { return date(l.HireDate) }},
+	{"fecha_retiro", 10, alpha, func(l *Line) string { return date(l.RetirementDate) }},
+	{"fecha_inicio_vsp", 10, alpha, func(l *Line) string { return date(l.SalaryChangeDate) }},
+	{"fecha_inicio_sln", 10, alpha, func(l *Line) string { return l.UnpaidLeave.start() }},
+	{"fecha_fin_sln", 10, alpha, func(l *Line) string { return l.UnpaidLeave.end() }},
+	{"fecha_inicio_ige", 10, alpha, func(l *Line) string { return l.SickLeave.start() }},
+	{"fecha_fin_ige", 10, alpha, func(l *Line) string { return l.SickLeave.end() }},
+	{"fecha_inicio_lma", 10, alpha, func(l *Line) string { return l.Maternity.start() }},
+	{"fecha_fin_lma", 10, alpha, func(l *Line) string { return l.Maternity.end() }},
+	{"fecha_inicio_vac_lr", 10, alpha, func(l *Line) string { return l.vacation().start() }},
+	{"fecha_fin_vac_lr", 10, alpha, func(l *Line) string { return l.vacation().end() }},
+	{"fecha_inicio_vct", 10, alpha, blank[Line]},
+	{"fecha_fin_vct", 10, alpha, blank[Line]},
+	{"fecha_inicio_irl", 10, alpha, func(l *Line) string { return l.WorkAccident.start() }},
+	{"fecha_fin_irl", 10, alpha, func(l *Line) string { return l.WorkAccident.end() }},
+	{"ibc_otros_parafiscales", 9, numeric, func(l *Line) string { return amount(l.otherParafiscalesIBC()) }},
+	{"horas_laboradas", 3, numeric, func(l *Line) string { return fmt.Sprint(l.Hours) }},
+	{"fecha_radicacion_exterior", 10, alpha, blank[Line]},
+	{"actividad_economica_arl", 7, numeric, func(l *Line) string { return l.EconomicActivity }},
+}
+
+// writeRecord escribe un registro y retorna los campos cuyo valor no cabe en la columna
+func writeRecord[T any](buf *bytes.Buffer, fields []field[T], record *T) []string {
+	var problems []string
+	for _, f := range fields {
+		value := normalize(f.value(record))
+		n := utf8.RuneCountInString(value)
+		if n > f.width {
+			problems = append(problems, fmt.Sprintf("%s: value %q exceeds %d characters", f.name, value, f.width))
+			value = string([]rune(value)[:f.width])
+			n = f.width
+		}
+		fill := strings.Repeat(" ", f.width-n)
+		if f.kind == numeric {
+			buf.WriteString(strings.Repeat("0", f.width-n) + value)
+		} else {
+			buf.WriteString(value + fill)
+		}
+	}
+	buf.WriteString("\r\n")
+	return problems
+}
+
+// recordWidth es la longitud de un registro sin el fin de línea
+func recordWidth[T any](fields []field[T]) int {
+	width := 0
+	for _, f := range fields {
+		width += f.width
+	}
+	return width
+}
+
+// normalize pasa el texto a mayúsculas sin tildes ni eñes, como lo exigen los operadores
+func normalize(value string) string {
+	return asciiReplacer.Replace(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+var asciiReplacer = strings.NewReplacer(
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+	"À", "A", "È", "E", "Ì", "I", "Ò", "O", "Ù", "U",
+)
+
+func constant[T any](value string) func(*T) string {
+	return func(*T) string { return value }
+}
+
+func blank[T any](*T) string {
+	return ""
+}
+
+func mark(set bool) string {
+	if set {
+		return "X"
+	}
+	return ""
+}
+
+func yesNo(set bool) string {
+	if set {
+		return "S"
+	}
+	return "N"
+}
@@ -0,0 +1,403 @@
+// Package pila genera el archivo plano de la Planilla Integrada de Liquidación de Aportes
+// (planilla tipo E, empleados) con el encabezado del aportante y una línea por cotizante.
+package pila
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Tarifas de aportes sobre el IBC (fracciones)
+const (
+	PensionRate           = 0.16
+	HealthRate            = 0.125
+	HealthRateExonerated  = 0.04 // solo el aporte del empleado (art. 114-1 ET)
+	CompensationFundRate  = 0.04
+	SENARate              = 0.02
+	ICBFRate              = 0.03
+	maxIBCMinimumWages    = 25
+	solidarityMinimumWage = 4
+)
+
+// Tipos de cotizante usados por el generador
+const (
+	ContributorEmployee   = "01"
+	ContributorApprentice = "12" // aprendiz en etapa lectiva: solo aporta a salud
+)
+
+// riskRates es la tarifa ARL de cada clase de riesgo (Decreto 1772 de 1994)
+var riskRates = map[int]float64{1: 0.00522, 2: 0.01044, 3: 0.02436, 4: 0.0435, 5: 0.0696}
+
+// RiskRate retorna la tarifa de la clase de riesgo ARL
+func RiskRate(class int) (float64, bool) {
+	rate, ok := riskRates[class]
+	return rate, ok
+}
+
+// Settings son los datos del aportante ante el operador de información
+type Settings struct {
+	OperatorCode     string // código del operador de información
+	ARLCode          string // administradora de riesgos laborales del aportante
+	CompensationFund string // caja de compensación familiar
+	BranchCode       string
+	BranchName       string
+	DepartmentCode   string // DIVIPOLA del departamento (2 dígitos)
+	CityCode         string // DIVIPOLA del municipio sin el departamento (3 dígitos)
+	EconomicActivity string // actividad económica para riesgos laborales (7 dígitos)
+}
+
+// Header es el registro tipo 1 del aportante para un periodo. Employees y PayrollTotal los
+// calcula Build.
+type Header struct {
+	Settings
+	Name         string
+	NIT          string
+	DV           string
+	Period       time.Time // primer día del mes liquidado
+	MinimumWage  float64
+	Employees    int
+	PayrollTotal float64
+}
+
+// Novelty es una novedad del periodo (vacaciones, licencias, incapacidades) con sus días
+// sobre base 30 y las fechas dentro del mes
+type Novelty struct {
+	Start     time.Time
+	End       time.Time
+	Days      int
+	Reference string
+}
+
+// NoveltyDays suma los días de las novedades; una novedad nil cuenta cero
+func NoveltyDays(novelties ...*Novelty) int {
+	days := 0
+	for _, n := range novelties {
+		if n != nil {
+			days += n.Days
+		}
+	}
+	return days
+}
+
+func (n *Novelty) start() string {
+	if n == nil {
+		return ""
+	}
+	return n.Start.Format("2006-01-02")
+}
+
+func (n *Novelty) end() string {
+	if n == nil {
+		return ""
+	}
+	return n.End.Format("2006-01-02")
+}
+
+func (n *Novelty) reference() string {
+	if n == nil {
+		return ""
+	}
+	return n.Reference
+}
+
+// Line es la liquidación de aportes de un cotizante. Las tarifas son fracciones; los valores
+// de los aportes los calcula Build a partir del IBC y la tarifa.
+type Line struct {
+	Sequence        int
+	EmployeeID      uint
+	DocumentType    string
+	DocumentNumber  string
+	ContributorType string
+	DepartmentCode  string
+	CityCode        string
+	FirstSurname    string
+	SecondSurname   string
+	FirstName       string
+	OtherNames      string
+
+	// Novedades
+	HireDate         *time.Time
+	RetirementDate   *time.Time
+	SalaryChangeDate *time.Time
+	Vacation         *Novelty
+	PaidLeave        *Novelty
+	UnpaidLeave      *Novelty
+	SickLeave        *Novelty
+	Maternity        *Novelty
+	WorkAccident     *Novelty
+
+	// Administradoras
+	PensionFund      string
+	HealthFund       string
+	CompensationFund string
+	ARLCode          string
+	RiskClass        int
+	EconomicActivity string
+
+	PensionDays    int
+	HealthDays     int
+	RiskDays       int
+	CCFDays        int
+	Hours          int
+	Salary         float64
+	IntegralSalary bool
+	Exonerated     bool
+
+	IBCPension float64
+	IBCHealth  float64
+	IBCRisk    float64
+	IBCCCF     float64
+
+	PensionRate float64
+	HealthRate  float64
+	RiskRate    float64
+	CCFRate     float64
+	SENARate    float64
+	ICBFRate    float64
+
+	PensionValue     float64
+	SolidarityValue  float64
+	SubsistenceValue float64
+	HealthValue      float64
+	RiskValue        float64
+	CCFValue         float64
+	SENAValue        float64
+	ICBFValue        float64
+}
+
+// vacation es la novedad VAC-LR: vacaciones o, si no hay, licencia remunerada
+func (l *Line) vacation() *Novelty {
+	if l.Vacation != nil {
+		return l.Vacation
+	}
+	return l.PaidLeave
+}
+
+func (l *Line) vacationMark() string {
+	switch {
+	case l.Vacation != nil:
+		return "X"
+	case l.PaidLeave != nil:
+		return "L"
+	}
+	return ""
+}
+
+func (l *Line) otherParafiscalesIBC() float64 {
+	if l.SENARate == 0 && l.ICBFRate == 0 {
+		return 0
+	}
+	return l.IBCCCF
+}
+
+// LineError es un error de validación de un registro del archivo; Line 0 es el encabezado
+type LineError struct {
+	Line       int    `json:"line"`
+	EmployeeID uint   `json:"employee_id,omitempty"`
+	Message    string `json:"message"`
+}
+
+// File es la planilla generada con sus errores de validación
+type File struct {
+	Header  Header
+	Lines   []Line
+	Content []byte
+	Errors  []LineError
+}
+
+// Total es la suma de todos los aportes de la planilla
+func (f *File) Total() float64 {
+	total := 0.0
+	for _, l := range f.Lines {
+		total += l.PensionValue + l.SolidarityValue + l.SubsistenceValue + l.HealthValue +
+			l.RiskValue + l.CCFValue + l.SENAValue + l.ICBFValue
+	}
+	return total
+}
+
+// HeaderWidth y LineWidth son las longitudes de los registros tipo 1 y 2
+var (
+	HeaderWidth = recordWidth(headerFields)
+	LineWidth   = recordWidth(lineFields)
+)
+
+// Build calcula los aportes de cada línea, valida los registros y escribe el archivo. El
+// archivo se genera aunque haya errores para que puedan revisarse todos a la vez.
+func Build(header Header, lines []Line) *File {
+	file := &File{Lines: lines}
+	header.Employees = len(lines)
+	header.PayrollTotal = 0
+	for i := range file.Lines {
+		l := &file.Lines[i]
+		l.Sequence = i + 1
+		l.compute(header.MinimumWage)
+		header.PayrollTotal += l.IBCHealth
+	}
+	file.Header = header
+
+	var buf bytes.Buffer
+	problems := header.validate()
+	problems = append(problems, writeRecord(&buf, headerFields, &header)...)
+	for _, p := range problems {
+		file.Errors = append(file.Errors, LineError{Message: p})
+	}
+	for i := range file.Lines {
+		l := &file.Lines[i]
+		problems := l.validate(header.MinimumWage)
+		problems = append(problems, writeRecord(&buf, lineFields, l)...)
+		for _, p := range problems {
+			file.Errors = append(file.Errors, LineError{Line: l.Sequence, EmployeeID: l.EmployeeID, Message: p})
+		}
+	}
+	file.Content = buf.Bytes()
+	return file
+}
+
+// compute aplica el tope de 25 SMMLV al IBC y calcula los aportes, incluido el fondo de
+// solidaridad pensional desde 4 SMMLV
+func (l *Line) compute(minimumWage float64) {
+	if minimumWage > 0 {
+		limit := maxIBCMinimumWages * minimumWage
+		l.IBCPension = math.Min(l.IBCPension, limit)
+		l.IBCHealth = math.Min(l.IBCHealth, limit)
+		l.IBCRisk = math.Min(l.IBCRisk, limit)
+		l.IBCCCF = math.Min(l.IBCCCF, limit)
+	}
+	l.IBCPension = ceilPeso(l.IBCPension)
+	l.IBCHealth = ceilPeso(l.IBCHealth)
+	l.IBCRisk = ceilPeso(l.IBCRisk)
+	l.IBCCCF = ceilPeso(l.IBCCCF)
+
+	l.PensionValue = Contribution(l.IBCPension, l.PensionRate)
+	l.HealthValue = Contribution(l.IBCHealth, l.HealthRate)
+	l.RiskValue = Contribution(l.IBCRisk, l.RiskRate)
+	l.CCFValue = Contribution(l.IBCCCF, l.CCFRate)
+	l.SENAValue = Contribution(l.IBCCCF, l.SENARate)
+	l.ICBFValue = Contribution(l.IBCCCF, l.ICBFRate)
+
+	l.SolidarityValue, l.SubsistenceValue = 0, 0
+	if l.PensionRate > 0 && minimumWage > 0 && l.IBCPension >= solidarityMinimumWage*minimumWage {
+		l.SolidarityValue = Contribution(l.IBCPension, 0.005)
+		l.SubsistenceValue = Contribution(l.IBCPension, solidarityRate(l.IBCPension/minimumWage)-0.005)
+	}
+}
+
+// solidarityRate es la tarifa total del fondo de solidaridad pensional según los SMMLV del IBC
+func solidarityRate(minimumWages float64) float64 {
+	switch {
+	case minimumWages > 20:
+		return 0.02
+	case minimumWages >= 16:
+		return 0.01 + 0.002*math.Floor(minimumWages-15)
+	}
+	return 0.01
+}
+
+// validate revisa los datos obligatorios y la coherencia de días e IBC de la línea
+func (l *Line) validate(minimumWage float64) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if l.DocumentNumber == "" {
+		add("employee has no identification number")
+	} else if _, err := strconv.ParseUint(l.DocumentNumber, 10, 64); err != nil && l.DocumentType == "CC" {
+		add("identification number %q must be numeric", l.DocumentNumber)
+	}
+	if l.FirstSurname == "" || l.FirstName == "" {
+		add("first name and first surname are required")
+	}
+	if l.HealthFund == "" {
+		add("health fund (EPS) code is required")
+	}
+	if l.PensionRate > 0 && l.PensionFund == "" {
+		add("pension fund (AFP) code is required")
+	}
+	if l.CCFRate > 0 && l.CompensationFund == "" {
+		add("compensation fund (CCF) code is required")
+	}
+	if l.RiskRate > 0 && l.ARLCode == "" {
+		add("ARL code is required")
+	}
+	if _, ok := RiskRate(l.RiskClass); !ok {
+		add("risk class %d must be between 1 and 5", l.RiskClass)
+	}
+
+	for _, d := range []struct {
+		name string
+		days int
+	}{{"pension", l.PensionDays}, {"health", l.HealthDays}, {"ARL", l.RiskDays}, {"CCF", l.CCFDays}} {
+		if d.days < 0 || d.days > 30 {
+			add("%s days %d must be between 0 and 30", d.name, d.days)
+		}
+	}
+	if l.HealthDays == 0 {
+		add("health days must be greater than 0")
+	}
+	if l.RiskDays > l.HealthDays {
+		add("ARL days %d exceed health days %d", l.RiskDays, l.HealthDays)
+	}
+	novelties := NoveltyDays(l.Vacation, l.PaidLeave, l.UnpaidLeave, l.SickLeave, l.Maternity, l.WorkAccident)
+	if novelties > l.HealthDays {
+		add("novelty days %d exceed the %d days reported", novelties, l.HealthDays)
+	}
+
+	// El IBC no puede ser inferior al salario mínimo proporcional a los días cotizados
+	if minimumWage > 0 && l.HealthDays > 0 {
+		floor := math.Round(minimumWage * float64(l.HealthDays) / 30)
+		if l.IBCHealth+1 < floor {
+			add("health IBC %.0f is below the minimum wage for %d days (%.0f)", l.IBCHealth, l.HealthDays, floor)
+		}
+		if l.PensionRate > 0 && l.IBCPension+1 < math.Round(minimumWage*float64(l.PensionDays)/30) {
+			add("pension IBC %.0f is below the minimum wage for %d days", l.IBCPension, l.PensionDays)
+		}
+	}
+	return problems
+}
+
+func (h *Header) validate() []string {
+	var problems []string
+	if h.Name == "" || h.NIT == "" {
+		problems = append(problems, "contributor name and NIT are required")
+	}
+	if h.Settings.ARLCode == "" {
+		problems = append(problems, "contributor ARL code is required")
+	}
+	if h.Settings.OperatorCode == "" {
+		problems = append(problems, "information operator code is required")
+	}
+	return problems
+}
+
+// Contribution es el aporte sobre el IBC redondeado a la centena superior, como lo exige
+// la PILA
+func Contribution(ibc, rate float64) float64 {
+	if ibc <= 0 || rate <= 0 {
+		return 0
+	}
+	cents := math.Round(ibc * rate * 100)
+	return math.Ceil(cents/10000) * 100
+}
+
+// ceilPeso redondea el IBC al peso superior
+func ceilPeso(value float64) float64 {
+	return math.Ceil(math.Round(value*100) / 100)
+}
+
+func amount(value float64) string {
+	return strconv.FormatInt(int64(math.Round(value)), 10)
+}
+
+func rate(value float64, decimals int) string {
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+func date(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format("2006-01-02")
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormAccountingPeriodRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAccountingPeriodRepository(db *gorm.DB) domain.AccountingPeriodRepo {
+	return &GormAccountingPeriodRepo{db: db}
+}
+
+func (r *GormAccountingPeriodRepo) Create(ctx context.Context, period *domain.AccountingPeriod) error {
+	if period == nil {
+		return errors.New("period cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	period.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(period).Error
+}
+
+func (r *GormAccountingPeriodRepo) GetByID(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	if id == 0 {
+		return nil, errors.New("invalid period id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var period domain.AccountingPeriod
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&period).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPeriodNotFound
+		}
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *GormAccountingPeriodRepo) List(ctx context.Context) ([]domain.AccountingPeriod, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var periods []domain.AccountingPeriod
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("period_start DESC").
+		Find(&periods).Error
+	if err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (r *GormAccountingPeriodRepo) FindOverlapping(ctx context.Context, start, end time.Time) ([]domain.AccountingPeriod, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var periods []domain.AccountingPeriod
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND period_start <= ? AND period_end >= ?", tenantID, end, start).
+		Order("period_start").
+		Find(&periods).Error
+	if err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (r *GormAccountingPeriodRepo) Update(ctx context.Context, period *domain.AccountingPeriod) error {
+	if period == nil || period.ID == 0 {
+		return errors.New("period cannot be nil or 0")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.AccountingPeriod{}).
+		Where("id = ? AND tenant_id = ?", period.ID, tenantID).
+		Updates(map[string]interface{}{
+			"name":      period.Name,
+			"status":    period.Status,
+			"closed_by": period.ClosedBy,
+			"closed_at": period.ClosedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPeriodNotFound
+	}
+	return nil
+}
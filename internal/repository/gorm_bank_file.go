@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormBankPaymentFileRepo struct {
+	db *gorm.DB
+}
+
+func NewGormBankPaymentFileRepository(db *gorm.DB) domain.BankPaymentFileRepo {
+	return &GormBankPaymentFileRepo{
+		db: db,
+	}
+}
+
+func (r *GormBankPaymentFileRepo) Create(ctx context.Context, file *domain.BankPaymentFile) error {
+	if file == nil {
+		return errors.New("bank file cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	file.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(file).Error
+}
+
+func (r *GormBankPaymentFileRepo) GetByID(ctx context.Context, id uint) (*domain.BankPaymentFile, error) {
+	if id == 0 {
+		return nil, errors.New("invalid bank file id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var file domain.BankPaymentFile
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&file).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBankFileNotFound
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// List retorna los archivos del tenant sin el contenido, los más recientes primero
+func (r *GormBankPaymentFileRepo) List(ctx context.Context) ([]domain.BankPaymentFile, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var files []domain.BankPaymentFile
+	err = dbFromCtx(ctx, r.db).
+		Omit("content").
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC, id DESC").
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+type GormBankFileTemplateRepo struct {
+	db *gorm.DB
+}
+
+func NewGormBankFileTemplateRepository(db *gorm.DB) domain.BankFileTemplateRepo {
+	return &GormBankFileTemplateRepo{
+		db: db,
+	}
+}
+
+func (r *GormBankFileTemplateRepo) Create(ctx context.Context, template *domain.BankFileTemplate) error {
+	if template == nil {
+		return errors.New("bank file template cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(template).Error
+}
+
+func (r *GormBankFileTemplateRepo) GetByID(ctx context.Context, id uint) (*domain.BankFileTemplate, error) {
+	if id == 0 {
+		return nil, errors.New("invalid bank file template id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var template domain.BankFileTemplate
+	err = dbFromCtx(ctx, r.db).
+		Preload("Fields", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBankFileTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *GormBankFileTemplateRepo) List(ctx context.Context) ([]domain.BankFileTemplate, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var templates []domain.BankFileTemplate
+	err = dbFromCtx(ctx, r.db).
+		Preload("Fields", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Update reemplaza los datos y las columnas; debe llamarse dentro de una transacción
+func (r *GormBankFileTemplateRepo) Update(ctx context.Context, template *domain.BankFileTemplate) error {
+	if template == nil || template.ID == 0 {
+		return errors.New("bank file template cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	db := dbFromCtx(ctx, r.db)
+	result := db.Model(&domain.BankFileTemplate{}).
+		Where("tenant_id = ? AND id = ?", tenantID, template.ID).
+		Updates(map[string]interface{}{
+			"name":      template.Name,
+			"bank_name": template.BankName,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBankFileTemplateNotFound
+	}
+	if err := db.Where("template_id = ?", template.ID).Delete(&domain.BankFileTemplateField{}).Error; err != nil {
+		return err
+	}
+	if len(template.Fields) == 0 {
+		return nil
+	}
+	for i := range template.Fields {
+		template.Fields[i].ID = 0
+		template.Fields[i].TemplateID = template.ID
+	}
+	return db.Create(&template.Fields).Error
+}
+
+func (r *GormBankFileTemplateRepo) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid bank file template id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	db := dbFromCtx(ctx, r.db)
+	result := db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.BankFileTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBankFileTemplateNotFound
+	}
+	return db.Where("template_id = ?", id).Delete(&domain.BankFileTemplateField{}).Error
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormBankStatementRepo struct {
+	db *gorm.DB
+}
+
+func NewGormBankStatementRepository(db *gorm.DB) domain.BankStatementRepo {
+	return &GormBankStatementRepo{
+		db: db,
+	}
+}
+
+func (r *GormBankStatementRepo) Create(ctx context.Context, statement *domain.BankStatement) error {
+	if statement == nil {
+		return errors.New("bank statement cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	statement.TenantID = tenantID
+	for i := range statement.Lines {
+		statement.Lines[i].TenantID = tenantID
+	}
+	return dbFromCtx(ctx, r.db).Create(statement).Error
+}
+
+func (r *GormBankStatementRepo) GetByID(ctx context.Context, id uint) (*domain.BankStatement, error) {
+	if id == 0 {
+		return nil, errors.New("invalid bank statement id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statement domain.BankStatement
+	err = dbFromCtx(ctx, r.db).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("line_number ASC, id ASC")
+		}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&statement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrStatementNotFound
+		}
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *GormBankStatementRepo) GetByHash(ctx context.Context, hash string) (*domain.BankStatement, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statement domain.BankStatement
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND hash = ?", tenantID, hash).
+		First(&statement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrStatementNotFound
+		}
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *GormBankStatementRepo) List(ctx context.Context) ([]domain.BankStatement, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statements []domain.BankStatement
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC, id DESC").
+		Find(&statements).Error
+	if err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+func (r *GormBankStatementRepo) UpdateCounts(ctx context.Context, statement *domain.BankStatement) error {
+	if statement == nil || statement.ID == 0 {
+		return errors.New("bank statement cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.BankStatement{}).
+		Where("tenant_id = ? AND id = ?", tenantID, statement.ID).
+		Updates(map[string]interface{}{
+			"matched_count":   statement.MatchedCount,
+			"exception_count": statement.ExceptionCount,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrStatementNotFound
+	}
+	return nil
+}
+
+func (r *GormBankStatementRepo) UpdateLine(ctx context.Context, line *domain.BankStatementLine) error {
+	if line == nil || line.ID == 0 {
+		return errors.New("bank statement line cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.BankStatementLine{}).
+		Where("tenant_id = ? AND id = ?", tenantID, line.ID).
+		Updates(map[string]interface{}{
+			"status":      line.Status,
+			"payment_id":  line.PaymentID,
+			"difference":  line.Difference,
+			"note":        line.Note,
+			"resolved_by": line.ResolvedBy,
+			"resolved_at": line.ResolvedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrStatementLineNotFound
+	}
+	return nil
+}
+
+// IsPaymentMatched indica si alguna línea conciliada o resuelta apunta al pago
+func (r *GormBankStatementRepo) IsPaymentMatched(ctx context.Context, paymentID uint) (bool, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.BankStatementLine{}).
+		Where("tenant_id = ? AND payment_id = ? AND status IN ?", tenantID, paymentID,
+			[]string{domain.StatementLineMatched, domain.StatementLineResolved}).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormBenefitLedgerRepo struct {
+	db *gorm.DB
+}
+
+func NewGormBenefitLedgerRepository(db *gorm.DB) domain.BenefitLedgerRepo {
+	return &GormBenefitLedgerRepo{db: db}
+}
+
+func (r *GormBenefitLedgerRepo) CreateBatch(ctx context.Context, entries []domain.BenefitLedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].TenantID = tenantID
+	}
+	return dbFromCtx(ctx, r.db).Create(&entries).Error
+}
+
+func (r *GormBenefitLedgerRepo) ExistsForPayroll(ctx context.Context, payrollID uint) (bool, error) {
+	if payrollID == 0 {
+		return false, errors.New("invalid payroll id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.BenefitLedgerEntry{}).
+		Where("tenant_id = ? AND payroll_id = ?", tenantID, payrollID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GormBenefitLedgerRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.BenefitLedgerEntry, error) {
+	if payrollID == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []domain.BenefitLedgerEntry
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND payroll_id = ?", tenantID, payrollID).
+		Order("id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GormBenefitLedgerRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.BenefitLedgerEntry, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []domain.BenefitLedgerEntry
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("period_start, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GormBenefitLedgerRepo) Balances(ctx context.Context, benefit string, upTo time.Time) ([]domain.BenefitBalance, error) {
+	if benefit == "" {
+		return nil, errors.New("benefit is required")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var balances []domain.BenefitBalance
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.BenefitLedgerEntry{}).
+		Select("employee_id, benefit, SUM(amount) AS balance").
+		Where("tenant_id = ? AND benefit = ? AND period_end <= ?", tenantID, benefit, upTo).
+		Group("employee_id, benefit").
+		Having("SUM(amount) <> 0").
+		Order("employee_id").
+		Scan(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
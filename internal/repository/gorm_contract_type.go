@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormContractTypeRepo struct {
+	db *gorm.DB
+}
+
+func NewGormContractTypeRepository(db *gorm.DB) domain.ContractTypeRepo {
+	return &GormContractTypeRepo{
+		db: db,
+	}
+}
+
+func (r *GormContractTypeRepo) Create(ctx context.Context, contractType *domain.ContractType) error {
+	if contractType == nil {
+		return errors.New("contract type cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	contractType.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(contractType).Error
+}
+
+func (r *GormContractTypeRepo) GetByID(ctx context.Context, id uint) (*domain.ContractType, error) {
+	if id == 0 {
+		return nil, errors.New("invalid contract type id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var contractType domain.ContractType
+	err = dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).First(&contractType).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrContractTypeNotFound
+		}
+		return nil, err
+	}
+	return &contractType, nil
+}
+
+func (r *GormContractTypeRepo) GetByCode(ctx context.Context, code string) (*domain.ContractType, error) {
+	if code == "" {
+		return nil, errors.New("invalid code")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var contractType domain.ContractType
+	err = dbFromCtx(ctx, r.db).Where("tenant_id = ? AND code = ?", tenantID, code).First(&contractType).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrContractTypeNotFound
+		}
+		return nil, err
+	}
+	return &contractType, nil
+}
+
+func (r *GormContractTypeRepo) List(ctx context.Context) ([]domain.ContractType, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var contractTypes []domain.ContractType
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("code").
+		Find(&contractTypes).Error
+	if err != nil {
+		return nil, err
+	}
+	return contractTypes, nil
+}
+
+func (r *GormContractTypeRepo) Update(ctx context.Context, contractType *domain.ContractType) error {
+	if contractType == nil || contractType.ID == 0 {
+		return errors.New("contract type cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.ContractType{}).
+		Where("id = ? AND tenant_id = ?", contractType.ID, tenantID).
+		Updates(map[string]interface{}{
+			"name":                           contractType.Name,
+			"description":                    contractType.Description,
+			"requires_end_date":              contractType.RequiresEndDate,
+			"renewal_alert_days":             contractType.RenewalAlertDays,
+			"skip_pension":                   contractType.SkipPension,
+			"stipend_percentage":             contractType.StipendPercentage,
+			"no_deductions":                  contractType.NoDeductions,
+			"requires_social_security_proof": contractType.RequiresSocialSecurityProof,
+			"no_social_benefits":             contractType.NoSocialBenefits,
+			"is_active":                      contractType.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrContractTypeNotFound
+	}
+	return nil
+}
+
+func (r *GormContractTypeRepo) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid contract type id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&domain.ContractType{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrContractTypeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormCostCenterRepo struct {
+	db *gorm.DB
+}
+
+func NewGormCostCenterRepository(db *gorm.DB) domain.CostCenterRepo {
+	return &GormCostCenterRepo{
+		db: db,
+	}
+}
+
+func (r *GormCostCenterRepo) Create(ctx context.Context, costCenter *domain.CostCenter) error {
+	if costCenter == nil {
+		return errors.New("cost center cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	costCenter.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(costCenter).Error
+}
+
+func (r *GormCostCenterRepo) GetByID(ctx context.Context, id uint) (*domain.CostCenter, error) {
+	if id == 0 {
+		return nil, errors.New("invalid cost center id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var costCenter domain.CostCenter
+	err = dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).First(&costCenter).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCostCenterNotFound
+		}
+		return nil, err
+	}
+	return &costCenter, nil
+}
+
+func (r *GormCostCenterRepo) List(ctx context.Context) ([]domain.CostCenter, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var costCenters []domain.CostCenter
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("code").
+		Find(&costCenters).Error
+	return costCenters, err
+}
+
+func (r *GormCostCenterRepo) Update(ctx context.Context, costCenter *domain.CostCenter) error {
+	if costCenter == nil || costCenter.ID == 0 {
+		return errors.New("cost center cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.CostCenter{}).
+		Where("id = ? AND tenant_id = ?", costCenter.ID, tenantID).
+		Updates(map[string]interface{}{
+			"code":      costCenter.Code,
+			"name":      costCenter.Name,
+			"is_active": costCenter.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCostCenterNotFound
+	}
+	return nil
+}
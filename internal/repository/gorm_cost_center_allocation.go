@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormCostCenterAllocationRepo struct {
+	db *gorm.DB
+}
+
+func NewGormCostCenterAllocationRepository(db *gorm.DB) domain.CostCenterAllocationRepo {
+	return &GormCostCenterAllocationRepo{
+		db: db,
+	}
+}
+
+func (r *GormCostCenterAllocationRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.CostCenterAllocation, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var allocations []domain.CostCenterAllocation
+	err = dbFromCtx(ctx, r.db).
+		Preload("CostCenter").
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("effective_from, id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// ReplaceVersion borra las asignaciones con la misma fecha de vigencia y crea las nuevas en
+// una transacción, para que la versión nunca quede a medias
+func (r *GormCostCenterAllocationRepo) ReplaceVersion(ctx context.Context, employeeID uint, effectiveFrom time.Time, allocations []domain.CostCenterAllocation) error {
+	if employeeID == 0 {
+		return errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	return dbFromCtx(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("tenant_id = ? AND employee_id = ? AND effective_from = ?", tenantID, employeeID, effectiveFrom).
+			Delete(&domain.CostCenterAllocation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(allocations) == 0 {
+			if result.RowsAffected == 0 {
+				return domain.ErrCostAllocationNotFound
+			}
+			return nil
+		}
+		for i := range allocations {
+			allocations[i].ID = 0
+			allocations[i].TenantID = tenantID
+			allocations[i].EmployeeID = employeeID
+			allocations[i].EffectiveFrom = effectiveFrom
+		}
+		return tx.Omit("CostCenter").Create(&allocations).Error
+	})
+}
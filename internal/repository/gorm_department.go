@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormDepartmentRepo struct {
+	db *gorm.DB
+}
+
+func NewGormDepartmentRepository(db *gorm.DB) domain.DepartmentRepo {
+	return &GormDepartmentRepo{
+		db: db,
+	}
+}
+
+func (r *GormDepartmentRepo) Create(ctx context.Context, department *domain.Department) error {
+	if department == nil {
+		return errors.New("department cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	department.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Omit("Positions").Create(department).Error
+}
+
+func (r *GormDepartmentRepo) GetByID(ctx context.Context, id uint) (*domain.Department, error) {
+	if id == 0 {
+		return nil, errors.New("invalid department id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var department domain.Department
+	err = dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).First(&department).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+func (r *GormDepartmentRepo) List(ctx context.Context) ([]domain.Department, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var departments []domain.Department
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&departments).Error
+	if err != nil {
+		return nil, err
+	}
+	return departments, nil
+}
+
+func (r *GormDepartmentRepo) Update(ctx context.Context, department *domain.Department) error {
+	if department == nil || department.ID == 0 {
+		return errors.New("department cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.Department{}).
+		Where("id = ? AND tenant_id = ?", department.ID, tenantID).
+		Updates(map[string]interface{}{
+			"name":             department.Name,
+			"code":             department.Code,
+			"parent_id":        department.ParentID,
+			"head_employee_id": department.HeadEmployeeID,
+			"is_active":        department.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDepartmentNotFound
+	}
+	return nil
+}
+
+func (r *GormDepartmentRepo) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid department id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&domain.Department{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDepartmentNotFound
+	}
+	return nil
+}
+
+func (r *GormDepartmentRepo) Headcount(ctx context.Context) (map[uint]int64, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		DepartmentID uint
+		Total        int64
+	}
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.Employee{}).
+		Select("department_id, COUNT(*) AS total").
+		Where("tenant_id = ? AND is_active = ? AND department_id > 0", tenantID, true).
+		Group("department_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	headcount := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		headcount[row.DepartmentID] = row.Total
+	}
+	return headcount, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormElectronicPayrollRepo struct {
+	db *gorm.DB
+}
+
+func NewGormElectronicPayrollRepository(db *gorm.DB) domain.ElectronicPayrollRepo {
+	return &GormElectronicPayrollRepo{
+		db: db,
+	}
+}
+
+func (r *GormElectronicPayrollRepo) Create(ctx context.Context, document *domain.ElectronicPayrollDocument) error {
+	if document == nil {
+		return errors.New("electronic payroll document cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	document.TenantID = tenantID
+	err = dbFromCtx(ctx, r.db).Omit("Predecessor", "Submissions").Create(document).Error
+	if isDuplicateError(err) {
+		return errors.New("electronic payroll document number already exists")
+	}
+	return err
+}
+
+func (r *GormElectronicPayrollRepo) GetByID(ctx context.Context, id uint) (*domain.ElectronicPayrollDocument, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var document domain.ElectronicPayrollDocument
+	err = dbFromCtx(ctx, r.db).
+		Preload("Predecessor", func(db *gorm.DB) *gorm.DB { return db.Omit("xml") }).
+		Preload("Submissions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&document).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrElectronicPayrollNotFound
+		}
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *GormElectronicPayrollRepo) ListByPeriod(ctx context.Context, year, month int) ([]domain.ElectronicPayrollDocument, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var documents []domain.ElectronicPayrollDocument
+	err = dbFromCtx(ctx, r.db).
+		Omit("xml").
+		Where("tenant_id = ? AND year = ? AND month = ?", tenantID, year, month).
+		Order("id").
+		Find(&documents).Error
+	return documents, err
+}
+
+func (r *GormElectronicPayrollRepo) UpdateStatus(ctx context.Context, document *domain.ElectronicPayrollDocument) error {
+	if document == nil || document.ID == 0 {
+		return errors.New("electronic payroll document cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.ElectronicPayrollDocument{}).
+		Where("id = ? AND tenant_id = ?", document.ID, tenantID).
+		Update("status", document.Status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrElectronicPayrollNotFound
+	}
+	return nil
+}
+
+func (r *GormElectronicPayrollRepo) NextConsecutive(ctx context.Context, prefix string) (int, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var last int
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.ElectronicPayrollDocument{}).
+		Where("tenant_id = ? AND prefix = ?", tenantID, prefix).
+		Select("COALESCE(MAX(consecutive), 0)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+func (r *GormElectronicPayrollRepo) CreateSubmission(ctx context.Context, submission *domain.ElectronicPayrollSubmission) error {
+	if submission == nil {
+		return errors.New("submission cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	submission.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(submission).Error
+}
@@ -27,7 +27,7 @@ func (r *GormEmployeeRepo) Create(ctx context.Context, emp *domain.Employee) err
 		return err
 	}
 	emp.TenantID = tenantID
-	err = r.db.WithContext(ctx).Create(emp).Error
+	err = dbFromCtx(ctx, r.db).Create(emp).Error
 	if err != nil {
 		return err
 	}
@@ -42,9 +42,10 @@ func (r *GormEmployeeRepo) GetByID(ctx context.Context, id uint) (*domain.Employ
 		return nil, err
 	}
 	var employee domain.Employee
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("User").Preload("Department").Preload("Position").
-		Preload("Contracts").Where("tenant_id = ? AND id =?", tenantID, id).First(&employee).Error
+		Preload("Contracts").Preload("Concepts", "is_active = ?", true).
+		Where("tenant_id = ? AND id =?", tenantID, id).First(&employee).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrEmployeeNotFound
@@ -63,7 +64,7 @@ func (r *GormEmployeeRepo) GetByUserID(ctx context.Context, userID uint) (*domai
 		return nil, err
 	}
 	var employee domain.Employee
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("User").
 		Preload("Department").
 		Preload("Position").
@@ -94,11 +95,12 @@ func (r *GormEmployeeRepo) List(ctx context.Context, page, limit int) ([]domain.
 	offset := (page - 1) * limit
 	var employees []domain.Employee
 	var total int64
-	if err := r.db.WithContext(ctx).Model(&domain.Employee{}).Where("tenant_id = ?", tenantID).
+	if err := scopeEmployees(ctx, dbFromCtx(ctx, r.db), "id").Model(&domain.Employee{}).
+		Where("tenant_id = ?", tenantID).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := r.db.WithContext(ctx).
+	if err := scopeEmployees(ctx, dbFromCtx(ctx, r.db), "id").
 		Preload("User").
 		Preload("Department").
 		Preload("Position").
@@ -124,7 +126,7 @@ func (r *GormEmployeeRepo) Update(ctx context.Context, emp *domain.Employee) err
 		return err
 	}
 	emp.TenantID = tenantID
-	err = r.db.WithContext(ctx).Save(emp).Error
+	err = dbFromCtx(ctx, r.db).Save(emp).Error
 	if err != nil {
 		return err
 	}
@@ -139,7 +141,7 @@ func (r *GormEmployeeRepo) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
-	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.Employee{})
+	result := dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.Employee{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -166,16 +168,17 @@ func (r *GormEmployeeRepo) ListActive(ctx context.Context, page, limit int) ([]d
 	var employees []domain.Employee
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&domain.Employee{}).
+	if err := dbFromCtx(ctx, r.db).Model(&domain.Employee{}).
 		Where("tenant_id = ? AND is_active = ?", tenantID, true).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := dbFromCtx(ctx, r.db).
 		Preload("User").
 		Preload("Contracts", "is_active = ?", true). // Solo contratos activos
 		Preload("Contracts.ContractType").
+		Preload("Concepts", "is_active = ?", true).
 		Where("tenant_id = ? AND is_active = ?", tenantID, true).
 		Order("id ASC").
 		Offset(offset).
@@ -186,3 +189,43 @@ func (r *GormEmployeeRepo) ListActive(ctx context.Context, page, limit int) ([]d
 
 	return employees, total, nil
 }
+
+// ListByManagers retorna los subordinados directos de los jefes indicados
+func (r *GormEmployeeRepo) ListByManagers(ctx context.Context, managerIDs []uint) ([]domain.Employee, error) {
+	if len(managerIDs) == 0 {
+		return nil, nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var employees []domain.Employee
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND manager_id IN ?", tenantID, managerIDs).
+		Order("id ASC").
+		Find(&employees).Error
+	if err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// ListByDepartment retorna los empleados del departamento, activos o no
+func (r *GormEmployeeRepo) ListByDepartment(ctx context.Context, departmentID uint) ([]domain.Employee, error) {
+	if departmentID == 0 {
+		return nil, errors.New("invalid department id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var employees []domain.Employee
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND department_id = ?", tenantID, departmentID).
+		Order("id ASC").
+		Find(&employees).Error
+	if err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
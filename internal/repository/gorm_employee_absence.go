@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormEmployeeAbsenceRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEmployeeAbsenceRepository(db *gorm.DB) domain.EmployeeAbsenceRepo {
+	return &GormEmployeeAbsenceRepo{
+		db: db,
+	}
+}
+
+func (r *GormEmployeeAbsenceRepo) Create(ctx context.Context, absence *domain.EmployeeAbsence) error {
+	if absence == nil {
+		return errors.New("absence cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	absence.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(absence).Error
+}
+
+func (r *GormEmployeeAbsenceRepo) GetByID(ctx context.Context, id uint) (*domain.EmployeeAbsence, error) {
+	if id == 0 {
+		return nil, errors.New("invalid absence id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var absence domain.EmployeeAbsence
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&absence).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAbsenceNotFound
+		}
+		return nil, err
+	}
+	return &absence, nil
+}
+
+func (r *GormEmployeeAbsenceRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeAbsence, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var absences []domain.EmployeeAbsence
+	err = scopeEmployees(ctx, dbFromCtx(ctx, r.db), "employee_id").
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("start_date DESC").
+		Find(&absences).Error
+	return absences, err
+}
+
+func (r *GormEmployeeAbsenceRepo) ListOverlapping(ctx context.Context, start, end time.Time) ([]domain.EmployeeAbsence, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var absences []domain.EmployeeAbsence
+	err = scopeEmployees(ctx, dbFromCtx(ctx, r.db), "employee_id").
+		Where("tenant_id = ? AND start_date <= ? AND end_date >= ?", tenantID, end, start).
+		Order("employee_id, start_date").
+		Find(&absences).Error
+	return absences, err
+}
+
+func (r *GormEmployeeAbsenceRepo) Delete(ctx context.Context, id uint) error {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.EmployeeAbsence{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAbsenceNotFound
+	}
+	return nil
+}
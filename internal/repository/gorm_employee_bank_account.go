@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormEmployeeBankAccountRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEmployeeBankAccountRepository(db *gorm.DB) domain.EmployeeBankAccountRepo {
+	return &GormEmployeeBankAccountRepo{
+		db: db,
+	}
+}
+
+func (r *GormEmployeeBankAccountRepo) Create(ctx context.Context, account *domain.EmployeeBankAccount) error {
+	if account == nil {
+		return errors.New("bank account cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	account.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(account).Error
+}
+
+func (r *GormEmployeeBankAccountRepo) GetByID(ctx context.Context, id uint) (*domain.EmployeeBankAccount, error) {
+	if id == 0 {
+		return nil, errors.New("invalid bank account id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var account domain.EmployeeBankAccount
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBankAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListByEmployee retorna las cuentas del empleado, activas o no, con la principal primero
+func (r *GormEmployeeBankAccountRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeBankAccount, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []domain.EmployeeBankAccount
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("is_primary DESC, id ASC").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *GormEmployeeBankAccountRepo) Update(ctx context.Context, account *domain.EmployeeBankAccount) error {
+	if account == nil || account.ID == 0 {
+		return errors.New("bank account cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.EmployeeBankAccount{}).
+		Where("id = ? AND tenant_id = ?", account.ID, tenantID).
+		Updates(map[string]interface{}{
+			"bank_name":        account.BankName,
+			"account_type":     account.AccountType,
+			"account_number":   account.AccountNumber,
+			"is_primary":       account.IsPrimary,
+			"split_percentage": account.SplitPercentage,
+			"is_active":        account.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBankAccountNotFound
+	}
+	return nil
+}
+
+func (r *GormEmployeeBankAccountRepo) ClearPrimary(ctx context.Context, employeeID, exceptID uint) error {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	return dbFromCtx(ctx, r.db).
+		Model(&domain.EmployeeBankAccount{}).
+		Where("tenant_id = ? AND employee_id = ? AND id <> ?", tenantID, employeeID, exceptID).
+		Update("is_primary", false).Error
+}
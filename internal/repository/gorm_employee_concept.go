@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormEmployeeConceptRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEmployeeConceptRepository(db *gorm.DB) domain.EmployeeConceptRepo {
+	return &GormEmployeeConceptRepo{
+		db: db,
+	}
+}
+
+func (r *GormEmployeeConceptRepo) CreateBatch(ctx context.Context, concepts []domain.EmployeeConcept) error {
+	if len(concepts) == 0 {
+		return nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range concepts {
+		concepts[i].TenantID = tenantID
+	}
+	return dbFromCtx(ctx, r.db).Omit("Concept").Create(&concepts).Error
+}
+
+func (r *GormEmployeeConceptRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeConcept, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var concepts []domain.EmployeeConcept
+	err = dbFromCtx(ctx, r.db).
+		Preload("Concept").
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("id ASC").
+		Find(&concepts).Error
+	if err != nil {
+		return nil, err
+	}
+	return concepts, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/arrase21/crm-users/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormEmployeeContractRepo struct {
@@ -28,7 +29,7 @@ func (r *GormEmployeeContractRepo) Create(ctx context.Context, contract *domain.
 		return err
 	}
 	contract.TenantID = tenantID
-	err = r.db.WithContext(ctx).Create(contract).Error
+	err = dbFromCtx(ctx, r.db).Create(contract).Error
 	if err != nil {
 		return err
 	}
@@ -45,7 +46,7 @@ func (r *GormEmployeeContractRepo) GetByID(ctx context.Context, id uint) (*domai
 	}
 
 	var contract domain.EmployeeContract
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Employee.User").
 		Preload("ContractType").
 		Where("tenant_id = ? AND id = ?", tenantID, id).
@@ -69,7 +70,7 @@ func (r *GormEmployeeContractRepo) GetActiveByEmployee(ctx context.Context, empl
 	}
 
 	var contract domain.EmployeeContract
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Employee.User").
 		Preload("ContractType").
 		Where("tenant_id = ? AND employee_id = ? AND is_active = ?", tenantID, employeeID, true).
@@ -83,6 +84,29 @@ func (r *GormEmployeeContractRepo) GetActiveByEmployee(ctx context.Context, empl
 	return &contract, nil
 }
 
+func (r *GormEmployeeContractRepo) LockActiveByEmployee(ctx context.Context, employeeID uint) (*domain.EmployeeContract, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var contract domain.EmployeeContract
+	err = dbFromCtx(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND employee_id = ? AND is_active = ?", tenantID, employeeID, true).
+		First(&contract).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrEmployeeContractNotFound
+		}
+		return nil, err
+	}
+	return &contract, nil
+}
+
 func (r *GormEmployeeContractRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeContract, error) {
 	if employeeID == 0 {
 		return nil, errors.New("invalid employee id")
@@ -92,7 +116,7 @@ func (r *GormEmployeeContractRepo) ListByEmployee(ctx context.Context, employeeI
 		return nil, err
 	}
 	var contracts []domain.EmployeeContract
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Employee.User").
 		Preload("ContractType").
 		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
@@ -104,6 +128,24 @@ func (r *GormEmployeeContractRepo) ListByEmployee(ctx context.Context, employeeI
 	return contracts, nil
 }
 
+func (r *GormEmployeeContractRepo) ListActiveEndingBy(ctx context.Context, until time.Time) ([]domain.EmployeeContract, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var contracts []domain.EmployeeContract
+	err = dbFromCtx(ctx, r.db).
+		Preload("Employee.User").
+		Preload("ContractType").
+		Where("tenant_id = ? AND is_active = ? AND end_date IS NOT NULL AND end_date <= ?", tenantID, true, until).
+		Order("end_date").
+		Find(&contracts).Error
+	if err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
+
 func (r *GormEmployeeContractRepo) Update(ctx context.Context, contract *domain.EmployeeContract) error {
 	if contract == nil || contract.ID == 0 {
 		return errors.New("contract cannot be nil or 0")
@@ -117,9 +159,13 @@ func (r *GormEmployeeContractRepo) Update(ctx context.Context, contract *domain.
 		return err
 	}
 	contract.TenantID = existing.TenantID
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Model(&domain.EmployeeContract{}).
-		Where("id = ? AND tenant_id = ?", contract.ID, tenantID).Updates(contract).Error
+		Where("id = ? AND tenant_id = ?", contract.ID, tenantID).
+		// Select("*") para persistir también IsActive=false y EndDate=nil
+		Select("*").
+		Omit("id", "tenant_id", "employee_id", "created_at", "Employee", "ContractType").
+		Updates(contract).Error
 	if err != nil {
 		return err
 	}
@@ -134,7 +180,7 @@ func (r *GormEmployeeContractRepo) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
-	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.EmployeeContract{})
+	result := dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.EmployeeContract{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormEmployeeTerminationRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEmployeeTerminationRepository(db *gorm.DB) domain.EmployeeTerminationRepo {
+	return &GormEmployeeTerminationRepo{db: db}
+}
+
+func (r *GormEmployeeTerminationRepo) Create(ctx context.Context, termination *domain.EmployeeTermination) error {
+	if termination == nil {
+		return errors.New("termination cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	termination.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(termination).Error
+}
+
+func (r *GormEmployeeTerminationRepo) GetByEmployee(ctx context.Context, employeeID uint) (*domain.EmployeeTermination, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var termination domain.EmployeeTermination
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND employee_id = ?", tenantID, employeeID).
+		Order("id DESC").
+		First(&termination).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTerminationNotFound
+		}
+		return nil, err
+	}
+	return &termination, nil
+}
+
+func (r *GormEmployeeTerminationRepo) Update(ctx context.Context, termination *domain.EmployeeTermination) error {
+	if termination == nil || termination.ID == 0 {
+		return errors.New("termination cannot be nil or 0")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	termination.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Save(termination).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormNotificationOutboxRepo struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationOutboxRepository(db *gorm.DB) domain.NotificationOutboxRepo {
+	return &GormNotificationOutboxRepo{
+		db: db,
+	}
+}
+
+func (r *GormNotificationOutboxRepo) Create(ctx context.Context, notification *domain.NotificationOutbox) error {
+	if notification == nil {
+		return errors.New("notification cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	notification.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(notification).Error
+}
+
+func (r *GormNotificationOutboxRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.NotificationOutbox, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var notifications []domain.NotificationOutbox
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND payroll_id = ?", tenantID, payrollID).
+		Order("id").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// ListDue no filtra por tenant: el despachador atiende a todos y usa el TenantID de cada fila
+func (r *GormNotificationOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.NotificationOutbox, error) {
+	var notifications []domain.NotificationOutbox
+	err := dbFromCtx(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", domain.NotificationPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *GormNotificationOutboxRepo) UpdateDelivery(ctx context.Context, notification *domain.NotificationOutbox) error {
+	if notification == nil || notification.ID == 0 {
+		return errors.New("notification cannot be nil or with zero id")
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.NotificationOutbox{}).
+		Where("id = ? AND tenant_id = ?", notification.ID, notification.TenantID).
+		Updates(map[string]interface{}{
+			"status":          notification.Status,
+			"attempts":        notification.Attempts,
+			"next_attempt_at": notification.NextAttemptAt,
+			"last_error":      notification.LastError,
+			"sent_at":         notification.SentAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *GormNotificationOutboxRepo) RetryFailed(ctx context.Context, payrollID uint) (int64, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.NotificationOutbox{}).
+		Where("tenant_id = ? AND payroll_id = ? AND status = ?", tenantID, payrollID, domain.NotificationFailed).
+		Updates(map[string]interface{}{
+			"status":          domain.NotificationPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"last_error":      "",
+		})
+	return result.RowsAffected, result.Error
+}
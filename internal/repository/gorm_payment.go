@@ -6,6 +6,7 @@ import (
 
 	"github.com/arrase21/crm-users/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormPaymentRepo struct {
@@ -20,7 +21,12 @@ func (r *GormPaymentRepo) Create(ctx context.Context, payment *domain.Payment) e
 	if payment == nil {
 		return errors.New("payment cannot be nil")
 	}
-	return r.db.WithContext(ctx).Create(payment).Error
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	payment.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(payment).Error
 }
 
 func (r *GormPaymentRepo) GetByID(ctx context.Context, id uint) (*domain.Payment, error) {
@@ -33,20 +39,44 @@ func (r *GormPaymentRepo) GetByID(ctx context.Context, id uint) (*domain.Payment
 	}
 
 	var payment domain.Payment
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Payroll").
 		Where("tenant_id = ? AND id = ?", tenantID, id).
 		First(&payment).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("payment not found")
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *GormPaymentRepo) LockByID(ctx context.Context, id uint) (*domain.Payment, error) {
+	if id == 0 {
+		return nil, errors.New("invalid payment id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment domain.Payment
+	err = dbFromCtx(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound
 		}
 		return nil, err
 	}
 	return &payment, nil
 }
 
-func (r *GormPaymentRepo) GetByPayrollID(ctx context.Context, payrollID uint) (*domain.Payment, error) {
+// ListByPayroll retorna los pagos de la nómina en orden de registro
+func (r *GormPaymentRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.Payment, error) {
 	if payrollID == 0 {
 		return nil, errors.New("invalid payroll id")
 	}
@@ -55,20 +85,107 @@ func (r *GormPaymentRepo) GetByPayrollID(ctx context.Context, payrollID uint) (*
 		return nil, err
 	}
 
+	var payments []domain.Payment
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND payroll_id = ?", tenantID, payrollID).
+		Order("id ASC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// ListByEmployee retorna los pagos de todas las nóminas del empleado, los más recientes primero
+func (r *GormPaymentRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.Payment, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []domain.Payment
+	err = dbFromCtx(ctx, r.db).
+		Joins("JOIN payrolls ON payrolls.id = payments.payroll_id").
+		Where("payments.tenant_id = ? AND payrolls.employee_id = ?", tenantID, employeeID).
+		Order("payments.paid_at DESC, payments.id DESC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// ListByStatus retorna los pagos del tenant en un estado, los más antiguos primero
+func (r *GormPaymentRepo) ListByStatus(ctx context.Context, status string) ([]domain.Payment, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []domain.Payment
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND status = ?", tenantID, status).
+		Order("id ASC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *GormPaymentRepo) GetByProviderRef(ctx context.Context, reference string) (*domain.Payment, error) {
+	if reference == "" {
+		return nil, errors.New("invalid provider reference")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var payment domain.Payment
-	err = r.db.WithContext(ctx).
-		Where("payroll_id = ?", payrollID).
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND provider_ref = ?", tenantID, reference).
 		First(&payment).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("payment not found")
+			return nil, domain.ErrPaymentNotFound
 		}
 		return nil, err
 	}
-	_ = tenantID // Evitar warning de variable no usada
 	return &payment, nil
 }
 
+func (r *GormPaymentRepo) UpdateStatus(ctx context.Context, payment *domain.Payment) error {
+	if payment == nil || payment.ID == 0 {
+		return errors.New("payment cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.Payment{}).
+		Where("id = ? AND tenant_id = ?", payment.ID, tenantID).
+		Updates(map[string]interface{}{
+			"status":         payment.Status,
+			"provider_ref":   payment.ProviderRef,
+			"bank_file_id":   payment.BankFileID,
+			"failure_reason": payment.FailureReason,
+			"sent_at":        payment.SentAt,
+			"settled_at":     payment.SettledAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPaymentNotFound
+	}
+	return nil
+}
+
 func (r *GormPaymentRepo) Delete(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("invalid payment id")
@@ -77,7 +194,7 @@ func (r *GormPaymentRepo) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
-	return r.db.WithContext(ctx).
+	return dbFromCtx(ctx, r.db).
 		Where("tenant_id = ? AND id = ?", tenantID, id).
 		Delete(&domain.Payment{}).Error
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/arrase21/crm-users/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormPayrollRepo struct {
@@ -28,7 +29,7 @@ func (r *GormPayrollRepo) Create(ctx context.Context, payroll *domain.Payroll) e
 		return err
 	}
 	payroll.TenantID = tenantID
-	err = r.db.WithContext(ctx).Create(payroll).Error
+	err = dbFromCtx(ctx, r.db).Create(payroll).Error
 	if err != nil {
 		return err
 	}
@@ -45,9 +46,9 @@ func (r *GormPayrollRepo) GetByID(ctx context.Context, id uint) (*domain.Payroll
 	}
 
 	var payroll domain.Payroll
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Employee.User").
-		Preload("Items").
+		Preload("Items.Allocations").
 		Where("tenant_id = ? AND id = ?", tenantID, id).
 		First(&payroll).Error
 	if err != nil {
@@ -60,6 +61,31 @@ func (r *GormPayrollRepo) GetByID(ctx context.Context, id uint) (*domain.Payroll
 
 }
 
+func (r *GormPayrollRepo) LockByID(ctx context.Context, id uint) (*domain.Payroll, error) {
+	if id == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var payroll domain.Payroll
+	err = dbFromCtx(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Employee.User").
+		Preload("Items.Allocations").
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&payroll).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPayrollNotFound
+		}
+		return nil, err
+	}
+	return &payroll, nil
+}
+
 func (r *GormPayrollRepo) GetByEmployeeAndPeriod(ctx context.Context, employeID uint, periodStart, periodEnd time.Time) (*domain.Payroll, error) {
 	if employeID == 0 {
 		return nil, errors.New("invalid employeid")
@@ -69,10 +95,12 @@ func (r *GormPayrollRepo) GetByEmployeeAndPeriod(ctx context.Context, employeID
 		return nil, err
 	}
 	var payroll domain.Payroll
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("Employee.User").
-		Preload("Items").
+		Preload("Items.Allocations").
 		Where("tenant_id = ? AND employee_id = ? AND period_start = ? AND period_end =?", tenantID, employeID, periodStart, periodEnd).
+		Where("kind <> ?", domain.PayrollKindReversal).
+		Order("id DESC").
 		First(&payroll).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -92,9 +120,9 @@ func (r *GormPayrollRepo) ListByEmployee(ctx context.Context, employeeID uint) (
 		return nil, err
 	}
 	var payrolls []domain.Payroll
-	err = r.db.WithContext(ctx).
+	err = scopeEmployees(ctx, dbFromCtx(ctx, r.db), "employee_id").
 		Preload("Employee.User").
-		Preload("Items").
+		Preload("Items.Allocations").
 		Where("tenant_id = ? AND employee_id = ?", tenanID, employeeID).
 		Order("period_start").
 		Find(&payrolls).Error
@@ -117,9 +145,13 @@ func (r *GormPayrollRepo) Update(ctx context.Context, payroll *domain.Payroll) e
 		return err
 	}
 	payroll.TenantID = existing.TenantID
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Model(&domain.Payroll{}).
-		Where("id = ? AND tenant_id = ?", payroll.ID, tenantID).Updates(payroll).Error
+		Where("id = ? AND tenant_id = ?", payroll.ID, tenantID).
+		// Select("*") para persistir también ApprovedBy=0, ApprovedAt=nil y montos en cero
+		Select("*").
+		Omit("id", "tenant_id", "employee_id", "created_at", "Employee", "Items").
+		Updates(payroll).Error
 	if err != nil {
 		return err
 	}
@@ -134,7 +166,7 @@ func (r *GormPayrollRepo) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
-	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.Payroll{})
+	result := dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&domain.Payroll{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -152,9 +184,9 @@ func (r *GormPayrollRepo) GetByPeriod(ctx context.Context, periodStart, periodEn
 	}
 
 	var payrolls []domain.Payroll
-	err = r.db.WithContext(ctx).
+	err = scopeEmployees(ctx, dbFromCtx(ctx, r.db), "employee_id").
 		Preload("Employee.User").
-		Preload("Items").
+		Preload("Items.Allocations").
 		Where("tenant_id = ? AND period_start >= ? AND period_end <= ?", tenantID, periodStart, periodEnd).
 		Order("employee_id, period_start").
 		Find(&payrolls).Error
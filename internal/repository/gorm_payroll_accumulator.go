@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormPayrollAccumulatorRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayrollAccumulatorRepository(db *gorm.DB) domain.PayrollAccumulatorRepo {
+	return &GormPayrollAccumulatorRepo{
+		db: db,
+	}
+}
+
+// Add hace un upsert por (tenant, empleado, año, código) sumando el monto en la base de datos,
+// de modo que dos pagos concurrentes no se pisan
+func (r *GormPayrollAccumulatorRepo) Add(ctx context.Context, accumulators []domain.PayrollAccumulator) error {
+	if len(accumulators) == 0 {
+		return nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range accumulators {
+		accumulators[i].ID = 0
+		accumulators[i].TenantID = tenantID
+	}
+	return dbFromCtx(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "employee_id"}, {Name: "year"}, {Name: "code"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"amount":     gorm.Expr("payroll_accumulators.amount + EXCLUDED.amount"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).
+		Create(&accumulators).Error
+}
+
+func (r *GormPayrollAccumulatorRepo) ListByEmployee(ctx context.Context, employeeID uint, year int) ([]domain.PayrollAccumulator, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var accumulators []domain.PayrollAccumulator
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND employee_id = ? AND year = ?", tenantID, employeeID, year).
+		Order("type, code").
+		Find(&accumulators).Error
+	return accumulators, err
+}
@@ -22,14 +22,14 @@ func (r *GormPayrollItemRepo) Create(ctx context.Context, item *domain.PayrollIt
 	if item == nil {
 		return errors.New("item cannot be nil")
 	}
-	return r.db.WithContext(ctx).Create(item).Error
+	return dbFromCtx(ctx, r.db).Create(item).Error
 }
 
 func (r *GormPayrollItemRepo) CreateBatch(ctx context.Context, items []domain.PayrollItem) error {
 	if len(items) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Create(&items).Error
+	return dbFromCtx(ctx, r.db).Create(&items).Error
 }
 
 func (r *GormPayrollItemRepo) GetByIDPayrollID(ctx context.Context, payrollID uint) ([]domain.PayrollItem, error) {
@@ -37,7 +37,7 @@ func (r *GormPayrollItemRepo) GetByIDPayrollID(ctx context.Context, payrollID ui
 		return nil, errors.New("invalid payrollid")
 	}
 	var items []domain.PayrollItem
-	err := r.db.WithContext(ctx).Where("payroll_id = ?", payrollID).Find(&items).Error
+	err := dbFromCtx(ctx, r.db).Where("payroll_id = ?", payrollID).Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +48,12 @@ func (r *GormPayrollItemRepo) DeleteByPayrollID(ctx context.Context, payrollID u
 	if payrollID == 0 {
 		return errors.New("payroll cannot be nil")
 	}
-	result := r.db.WithContext(ctx).Where("payroll_id = ?", payrollID).Delete(&domain.PayrollItem{})
+	db := dbFromCtx(ctx, r.db)
+	items := db.Model(&domain.PayrollItem{}).Select("id").Where("payroll_id = ?", payrollID)
+	if err := db.Where("payroll_item_id IN (?)", items).Delete(&domain.PayrollItemAllocation{}).Error; err != nil {
+		return err
+	}
+	result := db.Where("payroll_id = ?", payrollID).Delete(&domain.PayrollItem{})
 	if result.Error != nil {
 		return result.Error
 	}
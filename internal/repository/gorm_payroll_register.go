@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormPayrollRegisterRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayrollRegisterRepository(db *gorm.DB) domain.PayrollRegisterRepo {
+	return &GormPayrollRegisterRepo{
+		db: db,
+	}
+}
+
+func (r *GormPayrollRegisterRepo) ListConcepts(ctx context.Context, filter domain.PayrollRegisterFilter) ([]domain.PayrollItem, error) {
+	payrolls, err := r.payrolls(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var items []domain.PayrollItem
+	err = dbFromCtx(ctx, r.db).
+		Model(&domain.PayrollItem{}).
+		Select("type, code, MIN(name) AS name").
+		Where("payroll_id IN (?)", payrolls.Select("id")).
+		Group("type, code").
+		Order("code").
+		Scan(&items).Error
+	return items, err
+}
+
+func (r *GormPayrollRegisterRepo) Stream(ctx context.Context, filter domain.PayrollRegisterFilter, batchSize int, fn func([]domain.Payroll) error) error {
+	payrolls, err := r.payrolls(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var batch []domain.Payroll
+	return payrolls.
+		Preload("Employee.User").
+		Preload("Employee.Department").
+		Preload("Employee.Position").
+		Preload("Items.Allocations").
+		FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// payrolls arma la consulta de nóminas del filtro; departamento y cargo se filtran con una
+// subconsulta de empleados para no romper la paginación por id de FindInBatches
+func (r *GormPayrollRegisterRepo) payrolls(ctx context.Context, filter domain.PayrollRegisterFilter) (*gorm.DB, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db := dbFromCtx(ctx, r.db)
+	query := scopeEmployees(ctx, db.Model(&domain.Payroll{}), "employee_id").
+		Where("tenant_id = ?", tenantID)
+	if len(filter.PayrollIDs) > 0 {
+		query = query.Where("id IN ?", filter.PayrollIDs)
+	} else {
+		query = query.Where("period_start >= ? AND period_end <= ?", filter.PeriodStart, filter.PeriodEnd)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.DepartmentIDs) > 0 || len(filter.PositionIDs) > 0 {
+		employees := db.Model(&domain.Employee{}).Select("id").Where("tenant_id = ?", tenantID)
+		if len(filter.DepartmentIDs) > 0 {
+			employees = employees.Where("department_id IN ?", filter.DepartmentIDs)
+		}
+		if len(filter.PositionIDs) > 0 {
+			employees = employees.Where("position_id IN ?", filter.PositionIDs)
+		}
+		query = query.Where("employee_id IN (?)", employees)
+	}
+	return query, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormPayrollStatusHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayrollStatusHistoryRepository(db *gorm.DB) domain.PayrollStatusHistoryRepo {
+	return &GormPayrollStatusHistoryRepo{db: db}
+}
+
+func (r *GormPayrollStatusHistoryRepo) Create(ctx context.Context, entry *domain.PayrollStatusHistory) error {
+	if entry == nil {
+		return errors.New("history entry cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	entry.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Create(entry).Error
+}
+
+func (r *GormPayrollStatusHistoryRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.PayrollStatusHistory, error) {
+	if payrollID == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var history []domain.PayrollStatusHistory
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ? AND payroll_id = ?", tenantID, payrollID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+type GormPayrollTransitionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayrollTransitionRepository(db *gorm.DB) domain.PayrollTransitionRepo {
+	return &GormPayrollTransitionRepo{db: db}
+}
+
+func (r *GormPayrollTransitionRepo) ListByTenant(ctx context.Context) ([]domain.PayrollStatusTransition, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var transitions []domain.PayrollStatusTransition
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("from_status, to_status").
+		Find(&transitions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// ReplaceAll reemplaza la tabla de transiciones del tenant en una sola transacción
+func (r *GormPayrollTransitionRepo) ReplaceAll(ctx context.Context, transitions []domain.PayrollStatusTransition) error {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	return dbFromCtx(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&domain.PayrollStatusTransition{}).Error; err != nil {
+			return err
+		}
+		if len(transitions) == 0 {
+			return nil
+		}
+		for i := range transitions {
+			transitions[i].ID = 0
+			transitions[i].TenantID = tenantID
+		}
+		return tx.Create(&transitions).Error
+	})
+}
@@ -27,7 +27,7 @@ func (r *GormPayrollConceptRepo) Create(ctx context.Context, concept *domain.Pay
 		return err
 	}
 	concept.TenantID = tenantID
-	err = r.db.WithContext(ctx).Create(concept).Error
+	err = dbFromCtx(ctx, r.db).Create(concept).Error
 	if err != nil {
 		return err
 	}
@@ -42,7 +42,7 @@ func (r *GormPayrollConceptRepo) GetByID(ctx context.Context, id uint) (*domain.
 		return nil, err
 	}
 	var concept domain.PayrollConcept
-	err = r.db.WithContext(ctx).Where("tenant_id =? AND id =?", tenantID, id).First(&concept).Error
+	err = dbFromCtx(ctx, r.db).Where("tenant_id =? AND id =?", tenantID, id).First(&concept).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrConceptNotFound
@@ -61,7 +61,7 @@ func (r *GormPayrollConceptRepo) GetByCode(ctx context.Context, code string) (*d
 		return nil, err
 	}
 	var concept domain.PayrollConcept
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Where("code = ? AND tenant_id = ?", code, tenantID).
 		First(&concept).Error
 	if err != nil {
@@ -79,7 +79,7 @@ func (r *GormPayrollConceptRepo) GetActiveConcepts(ctx context.Context) ([]domai
 		return nil, err
 	}
 	var concepts []domain.PayrollConcept
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Where("tenant_id = ? AND is_active = ?", tenantID, true).
 		Order("code").
 		Find(&concepts).Error
@@ -97,7 +97,7 @@ func (r *GormPayrollConceptRepo) List(ctx context.Context, page, limit int) ([]d
 
 	offset := (page - 1) * limit
 	var concepts []domain.PayrollConcept
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Where("tenant_id = ?", tenantID).
 		Offset(offset).
 		Limit(limit).
@@ -123,18 +123,21 @@ func (r *GormPayrollConceptRepo) Update(ctx context.Context, concept *domain.Pay
 	if err != nil {
 		return err
 	}
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Model(&domain.PayrollConcept{}).
 		Where("id = ? AND tenant_id = ?", concept.ID, tenantID).
 		Updates(map[string]interface{}{
-			"name":          concept.Name,
-			"type":          concept.Type,
-			"description":   concept.Description,
-			"percentage":    concept.Percentage,
-			"employee_part": concept.EmployeePart,
-			"employer_part": concept.EmployerPart,
-			"is_mandatory":  concept.IsMandatory,
-			"is_active":     concept.IsActive,
+			"name":           concept.Name,
+			"type":           concept.Type,
+			"description":    concept.Description,
+			"percentage":     concept.Percentage,
+			"employee_part":  concept.EmployeePart,
+			"employer_part":  concept.EmployerPart,
+			"is_mandatory":   concept.IsMandatory,
+			"is_active":      concept.IsActive,
+			"debit_account":  concept.DebitAccount,
+			"credit_account": concept.CreditAccount,
+			"cost_center":    concept.CostCenter,
 		}).Error
 	if err != nil {
 		return err
@@ -150,7 +153,7 @@ func (r *GormPayrollConceptRepo) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
-	result := r.db.WithContext(ctx).
+	result := dbFromCtx(ctx, r.db).
 		Where("id = ? AND tenant_id = ?", id, tenantID).
 		Delete(&domain.PayrollConcept{})
 	if result.Error != nil {
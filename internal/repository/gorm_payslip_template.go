@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormPayslipTemplateRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayslipTemplateRepository(db *gorm.DB) domain.PayslipTemplateRepo {
+	return &GormPayslipTemplateRepo{
+		db: db,
+	}
+}
+
+func (r *GormPayslipTemplateRepo) Get(ctx context.Context) (*domain.PayslipTemplate, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var template domain.PayslipTemplate
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPayslipTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Save crea la plantilla del tenant o reemplaza todos sus campos
+func (r *GormPayslipTemplateRepo) Save(ctx context.Context, template *domain.PayslipTemplate) error {
+	if template == nil {
+		return errors.New("payslip template cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Save(template).Error
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormPositionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPositionRepository(db *gorm.DB) domain.PositionRepo {
+	return &GormPositionRepo{
+		db: db,
+	}
+}
+
+func (r *GormPositionRepo) Create(ctx context.Context, position *domain.Position) error {
+	if position == nil {
+		return errors.New("position cannot be nil")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	position.TenantID = tenantID
+	return dbFromCtx(ctx, r.db).Omit("Deparment").Create(position).Error
+}
+
+func (r *GormPositionRepo) GetByID(ctx context.Context, id uint) (*domain.Position, error) {
+	if id == 0 {
+		return nil, errors.New("invalid position id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var position domain.Position
+	err = dbFromCtx(ctx, r.db).Where("tenant_id = ? AND id = ?", tenantID, id).First(&position).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPositionNotFound
+		}
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (r *GormPositionRepo) List(ctx context.Context, departmentID uint) ([]domain.Position, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := dbFromCtx(ctx, r.db).Where("tenant_id = ?", tenantID)
+	if departmentID != 0 {
+		query = query.Where("department_id = ?", departmentID)
+	}
+	var positions []domain.Position
+	if err := query.Order("name_position").Find(&positions).Error; err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+func (r *GormPositionRepo) Update(ctx context.Context, position *domain.Position) error {
+	if position == nil || position.ID == 0 {
+		return errors.New("position cannot be nil or with zero id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Model(&domain.Position{}).
+		Where("id = ? AND tenant_id = ?", position.ID, tenantID).
+		Updates(map[string]interface{}{
+			"department_id":   position.DepartmentID,
+			"name_position":   position.NamePosition,
+			"description":     position.Description,
+			"salary_min":      position.SalaryMin,
+			"salary_mid":      position.SalaryMid,
+			"salary_max":      position.SalaryMax,
+			"salary_currency": position.SalaryCurrency,
+			"is_active":       position.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPositionNotFound
+	}
+	return nil
+}
+
+func (r *GormPositionRepo) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid position id")
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	result := dbFromCtx(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&domain.Position{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPositionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GormRetroAdjustmentRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRetroAdjustmentRepository(db *gorm.DB) domain.RetroAdjustmentRepo {
+	return &GormRetroAdjustmentRepo{db: db}
+}
+
+func (r *GormRetroAdjustmentRepo) CreateBatch(ctx context.Context, adjustments []domain.RetroAdjustment) error {
+	if len(adjustments) == 0 {
+		return nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range adjustments {
+		adjustments[i].TenantID = tenantID
+	}
+	return dbFromCtx(ctx, r.db).Create(&adjustments).Error
+}
+
+func (r *GormRetroAdjustmentRepo) ListBySource(ctx context.Context, sourcePayrollID uint) ([]domain.RetroAdjustment, error) {
+	if sourcePayrollID == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	return r.list(ctx, "source_payroll_id = ?", sourcePayrollID)
+}
+
+func (r *GormRetroAdjustmentRepo) ListByTarget(ctx context.Context, targetPayrollID uint) ([]domain.RetroAdjustment, error) {
+	if targetPayrollID == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	return r.list(ctx, "target_payroll_id = ?", targetPayrollID)
+}
+
+func (r *GormRetroAdjustmentRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.RetroAdjustment, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	return r.list(ctx, "employee_id = ?", employeeID)
+}
+
+func (r *GormRetroAdjustmentRepo) ListPending(ctx context.Context, employeeID uint, before time.Time) ([]domain.RetroAdjustment, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	return r.list(ctx, "employee_id = ? AND target_payroll_id IS NULL AND period_end < ?", employeeID, before)
+}
+
+func (r *GormRetroAdjustmentRepo) AssignTarget(ctx context.Context, ids []uint, targetPayrollID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return err
+	}
+	return dbFromCtx(ctx, r.db).
+		Model(&domain.RetroAdjustment{}).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Update("target_payroll_id", targetPayrollID).Error
+}
+
+func (r *GormRetroAdjustmentRepo) list(ctx context.Context, query string, args ...interface{}) ([]domain.RetroAdjustment, error) {
+	tenantID, err := tenantFromctx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var adjustments []domain.RetroAdjustment
+	err = dbFromCtx(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Where(query, args...).
+		Order("period_start, id").
+		Find(&adjustments).Error
+	if err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
@@ -31,7 +31,7 @@ func (r *GormRoleRepo) Create(ctx context.Context, role *domain.Role) error {
 		role.TenantID = tenantID
 	}
 
-	err = r.db.WithContext(ctx).Create(role).Error
+	err = dbFromCtx(ctx, r.db).Create(role).Error
 	if err != nil {
 		return err
 	}
@@ -47,7 +47,7 @@ func (r *GormRoleRepo) GetByID(ctx context.Context, id uint) (*domain.Role, erro
 		return nil, err
 	}
 	var role domain.Role
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("RolePermissions.Action.Resource").
 		Where("tenant_id = ? AND id = ?", tenantID, id).
 		First(&role).Error
@@ -66,7 +66,7 @@ func (r *GormRoleRepo) GetByName(ctx context.Context, name string) (*domain.Role
 		return nil, err
 	}
 	var role domain.Role
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Preload("RolePermissions.Action.Resource").
 		Where("tenant_id = ? AND name = ?", tenantID, name).
 		First(&role).Error
@@ -85,7 +85,7 @@ func (r *GormRoleRepo) List(ctx context.Context) ([]domain.Role, error) {
 		return nil, err
 	}
 	var roles []domain.Role
-	if err := r.db.WithContext(ctx).
+	if err := dbFromCtx(ctx, r.db).
 		Preload("RolePermissions.Action.Resource").
 		Where("tenant_id = ?", tenantID).
 		Order("id DESC").
@@ -110,7 +110,7 @@ func (r *GormRoleRepo) Update(ctx context.Context, role *domain.Role) error {
 
 	role.TenantID = existing.TenantID
 
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Model(&domain.Role{}).
 		Where("id = ? AND tenant_id = ?", role.ID, tenantID).Updates(role).Error
 	if err != nil {
@@ -140,7 +140,7 @@ func (r *GormRoleRepo) Delete(ctx context.Context, id uint) error {
 		return errors.New("cannot delete system roles")
 	}
 
-	result := r.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", id, tenantID).Delete(&domain.Role{})
+	result := dbFromCtx(ctx, r.db).Where("id = ? AND tenant_id = ?", id, tenantID).Delete(&domain.Role{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -151,7 +151,7 @@ func (r *GormRoleRepo) Delete(ctx context.Context, id uint) error {
 }
 
 // Assing permissions
-func (r *GormRoleRepo) AssignPermission(ctx context.Context, roleID, actionID uint) error {
+func (r *GormRoleRepo) AssignPermission(ctx context.Context, roleID, actionID uint, scope string) error {
 	tenantID, err := tenantFromctx(ctx)
 	if err != nil {
 		return err
@@ -175,11 +175,15 @@ func (r *GormRoleRepo) AssignPermission(ctx context.Context, roleID, actionID ui
 	rolePermission := &domain.RolePermission{
 		RoleID:   roleID,
 		ActionID: actionID,
+		Scope:    scope,
 	}
-	err = r.db.WithContext(ctx).Create(rolePermission).Error
+	err = dbFromCtx(ctx, r.db).Create(rolePermission).Error
 	if err != nil {
 		if isDuplicateError(err) {
-			return nil
+			// El permiso ya estaba otorgado: solo se actualiza su alcance
+			return dbFromCtx(ctx, r.db).Model(&domain.RolePermission{}).
+				Where("role_id = ? AND action_id = ?", roleID, actionID).
+				Update("scope", scope).Error
 		}
 		return err
 	}
@@ -198,7 +202,7 @@ func (r *GormRoleRepo) RevokePermission(ctx context.Context, roleID, actionID ui
 	if role.TenantID != tenantID {
 		return errors.New("role does not belong to tenant")
 	}
-	result := r.db.WithContext(ctx).Where("role_id = ? AND action_id = ?", roleID, actionID).Delete(&domain.RolePermission{})
+	result := dbFromCtx(ctx, r.db).Where("role_id = ? AND action_id = ?", roleID, actionID).Delete(&domain.RolePermission{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -218,7 +222,7 @@ func (r *GormRoleRepo) GetPermissions(ctx context.Context, roleID uint) ([]domai
 		return nil, errors.New("role does not belong to tenant")
 	}
 	var actions []domain.PermissionAction
-	err = r.db.WithContext(ctx).Joins("JOIN role_permissions ON role_permissions.action_id = permission_actions.id").
+	err = dbFromCtx(ctx, r.db).Joins("JOIN role_permissions ON role_permissions.action_id = permission_actions.id").
 		Preload("Resource").
 		Where("role_permissions.role_id = ?", roleID).
 		Find(&actions).Error
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type GormTxManager struct {
+	db *gorm.DB
+}
+
+func NewGormTxManager(db *gorm.DB) domain.TxManager {
+	return &GormTxManager{db: db}
+}
+
+// WithinTransaction ejecuta fn dentro de una transacción. Los repositorios que reciban
+// el contexto de fn usan la misma transacción; las llamadas anidadas la reutilizan.
+func (m *GormTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFromCtx retorna la transacción activa del contexto o la conexión por defecto
+func dbFromCtx(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
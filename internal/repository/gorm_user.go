@@ -48,7 +48,7 @@ func (r *GormUserRepo) Create(ctx context.Context, usr *domain.User) error {
 
 	usr.TenantID = tenantID
 
-	err = r.db.WithContext(ctx).Create(usr).Error
+	err = dbFromCtx(ctx, r.db).Create(usr).Error
 	if err != nil {
 		if isDuplicateError(err) {
 			if strings.Contains(err.Error(), "dni") {
@@ -79,7 +79,7 @@ func (r *GormUserRepo) GetByID(ctx context.Context, id uint) (*domain.User, erro
 	}
 
 	var user domain.User
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Where("tenant_id = ? AND id = ?", tenantID, id).
 		First(&user).Error
 
@@ -104,7 +104,7 @@ func (r *GormUserRepo) GetByDni(ctx context.Context, dni string) (*domain.User,
 	}
 
 	var user domain.User
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Where("tenant_id = ? AND dni = ?", tenantID, dni).
 		First(&user).Error
 
@@ -137,7 +137,7 @@ func (r *GormUserRepo) List(ctx context.Context, page, limit int) ([]domain.User
 	var total int64
 
 	// Contar total
-	if err := r.db.WithContext(ctx).
+	if err := dbFromCtx(ctx, r.db).
 		Model(&domain.User{}).
 		Where("tenant_id = ?", tenantID).
 		Count(&total).Error; err != nil {
@@ -145,7 +145,7 @@ func (r *GormUserRepo) List(ctx context.Context, page, limit int) ([]domain.User
 	}
 
 	// Obtener página
-	if err := r.db.WithContext(ctx).
+	if err := dbFromCtx(ctx, r.db).
 		Where("tenant_id = ?", tenantID).
 		Order("id DESC").
 		Offset(offset).
@@ -176,7 +176,7 @@ func (r *GormUserRepo) Update(ctx context.Context, usr *domain.User) error {
 	// Preservar tenant_id original (seguridad)
 	usr.TenantID = existing.TenantID
 
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Model(&domain.User{}).
 		Where("id = ? AND tenant_id = ?", usr.ID, tenantID).
 		Updates(usr).Error
@@ -210,7 +210,7 @@ func (r *GormUserRepo) Delete(ctx context.Context, id uint) error {
 		return err
 	}
 
-	result := r.db.WithContext(ctx).
+	result := dbFromCtx(ctx, r.db).
 		Where("id = ? AND tenant_id = ?", id, tenantID).
 		Delete(&domain.User{})
 
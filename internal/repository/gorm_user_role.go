@@ -49,7 +49,7 @@ func (r *GormUserRoleRepo) AssignRole(ctx context.Context, userID, roleID uint)
 		RoleID:   roleID,
 		TenantID: tenantID,
 	}
-	err = r.db.WithContext(ctx).Create(userRole).Error
+	err = dbFromCtx(ctx, r.db).Create(userRole).Error
 	if err != nil {
 		if isDuplicateError(err) {
 			return nil
@@ -67,7 +67,7 @@ func (r *GormUserRoleRepo) RevokeRole(ctx context.Context, userID, roleID uint)
 	if err != nil {
 		return err
 	}
-	result := r.db.WithContext(ctx).Where("user_id = ? AND role_id = ? AND tenant_id = ?", userID, roleID, tenantID).
+	result := dbFromCtx(ctx, r.db).Where("user_id = ? AND role_id = ? AND tenant_id = ?", userID, roleID, tenantID).
 		Delete(&domain.UserRole{})
 	if result.Error != nil {
 		return result.Error
@@ -87,7 +87,7 @@ func (r *GormUserRoleRepo) GetUserRoles(ctx context.Context, userID uint) ([]dom
 		return nil, err
 	}
 	var roles []domain.Role
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
 		Preload("RolePermissions.Action.Resource").
 		Where("user_roles.user_id = ? AND user_roles.tenant_id = ?", userID, tenantID).
@@ -108,7 +108,7 @@ func (r *GormUserRoleRepo) GetRoleUsers(ctx context.Context, roleID uint) ([]dom
 		return nil, err
 	}
 	var users []domain.User
-	err = r.db.WithContext(ctx).
+	err = dbFromCtx(ctx, r.db).
 		Joins("JOIN user_roles ON user_roles.user_id = users.id").
 		Where("user_roles.role_id = ? AND user_roles.tenant_id = ?", roleID, tenantID).
 		Find(&users).Error
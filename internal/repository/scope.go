@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"gorm.io/gorm"
+)
+
+// scopeEmployees limita la consulta a los empleados del alcance de datos del contexto.
+// column es la columna que referencia al empleado (id o employee_id); sin scope no filtra.
+func scopeEmployees(ctx context.Context, db *gorm.DB, column string) *gorm.DB {
+	scope, ok := ctx.Value(domain.EmployeeScopeKey).(*domain.EmployeeScope)
+	if !ok || scope == nil {
+		return db
+	}
+	if len(scope.EmployeeIDs) == 0 {
+		return db.Where("1 = 0")
+	}
+	return db.Where(column+" IN ?", scope.EmployeeIDs)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// AbsenceService registra vacaciones, licencias e incapacidades de los empleados. Las
+// ausencias alimentan las novedades de la planilla PILA, por eso no se registran ni eliminan
+// en periodos contables cerrados. Las licencias no remuneradas descuentan días de la nómina:
+// si caen en periodos ya pagados se liquidan con el motor retroactivo.
+type AbsenceService struct {
+	txManager    domain.TxManager
+	absenceRepo  domain.EmployeeAbsenceRepo
+	employeeRepo domain.EmployeeRepo
+	periodRepo   domain.AccountingPeriodRepo
+	scopeSvc     *DataScopeService
+	retroSvc     *PayrollRetroService
+}
+
+func NewAbsenceService(
+	txManager domain.TxManager,
+	absenceRepo domain.EmployeeAbsenceRepo,
+	employeeRepo domain.EmployeeRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	scopeSvc *DataScopeService,
+	retroSvc *PayrollRetroService,
+) *AbsenceService {
+	return &AbsenceService{
+		txManager:    txManager,
+		absenceRepo:  absenceRepo,
+		employeeRepo: employeeRepo,
+		periodRepo:   periodRepo,
+		scopeSvc:     scopeSvc,
+		retroSvc:     retroSvc,
+	}
+}
+
+// List retorna las ausencias del empleado, de la más reciente a la más antigua, si el
+// empleado está dentro del alcance de datos del actor
+func (s *AbsenceService) List(ctx context.Context, employeeID uint) ([]domain.EmployeeAbsence, error) {
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourceEmployees)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.absenceRepo.ListByEmployee(ctx, employeeID)
+}
+
+// Create registra una ausencia; no puede cruzarse con otra del mismo empleado
+func (s *AbsenceService) Create(ctx context.Context, employeeID uint, absence *domain.EmployeeAbsence) error {
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return err
+	}
+	if err := absence.Validate(); err != nil {
+		return err
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, absence.StartDate, absence.EndDate); err != nil {
+		return err
+	}
+	existing, err := s.absenceRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+	for i := range existing {
+		if existing[i].Overlaps(absence.StartDate, absence.EndDate) {
+			return domain.ErrAbsenceOverlap
+		}
+	}
+	absence.ID = 0
+	absence.EmployeeID = employeeID
+	absence.CreatedBy = actorFromCtx(ctx)
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.absenceRepo.Create(ctx, absence); err != nil {
+			return err
+		}
+		return s.runRetro(ctx, absence, "unpaid leave recorded after payment")
+	})
+}
+
+// Delete elimina una ausencia validando que pertenezca al empleado
+func (s *AbsenceService) Delete(ctx context.Context, employeeID, absenceID uint) error {
+	absence, err := s.absenceRepo.GetByID(ctx, absenceID)
+	if err != nil {
+		return err
+	}
+	if absence.EmployeeID != employeeID {
+		return domain.ErrAbsenceNotFound
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, absence.StartDate, absence.EndDate); err != nil {
+		return err
+	}
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.absenceRepo.Delete(ctx, absenceID); err != nil {
+			return err
+		}
+		return s.runRetro(ctx, absence, "unpaid leave removed after payment")
+	})
+}
+
+// runRetro reliquida los periodos pagados desde el inicio de una licencia no remunerada pasada;
+// las demás ausencias no cambian los días pagados
+func (s *AbsenceService) runRetro(ctx context.Context, absence *domain.EmployeeAbsence, reason string) error {
+	if absence.Kind != domain.AbsenceUnpaidLeave || !absence.StartDate.Before(time.Now()) || s.retroSvc == nil {
+		return nil
+	}
+	_, err := s.retroSvc.Run(ctx, absence.EmployeeID, absence.StartDate, reason)
+	return err
+}
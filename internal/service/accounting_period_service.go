@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Permiso requerido para reabrir un periodo cerrado
+const (
+	PeriodPermissionResource = "accounting_periods"
+	PeriodPermissionReopen   = "reopen"
+)
+
+// AccountingPeriodService gestiona el cierre y bloqueo de periodos contables
+type AccountingPeriodService struct {
+	periodRepo    domain.AccountingPeriodRepo
+	permissionSvc *PermissionService
+}
+
+func NewAccountingPeriodService(
+	periodRepo domain.AccountingPeriodRepo,
+	permissionSvc *PermissionService,
+) *AccountingPeriodService {
+	return &AccountingPeriodService{
+		periodRepo:    periodRepo,
+		permissionSvc: permissionSvc,
+	}
+}
+
+func (s *AccountingPeriodService) Create(ctx context.Context, period *domain.AccountingPeriod) error {
+	if period == nil {
+		return errors.New("period cannot be nil")
+	}
+	if period.PeriodStart.IsZero() || period.PeriodEnd.IsZero() || period.PeriodEnd.Before(period.PeriodStart) {
+		return domain.ErrInvalidPeriod
+	}
+	overlapping, err := s.periodRepo.FindOverlapping(ctx, period.PeriodStart, period.PeriodEnd)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return domain.ErrPeriodOverlap
+	}
+	period.Status = domain.PeriodStatusOpen
+	return s.periodRepo.Create(ctx, period)
+}
+
+func (s *AccountingPeriodService) GetByID(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	return s.periodRepo.GetByID(ctx, id)
+}
+
+func (s *AccountingPeriodService) List(ctx context.Context) ([]domain.AccountingPeriod, error) {
+	return s.periodRepo.List(ctx)
+}
+
+// Close cierra un periodo abierto; a partir de aquí sus datos de nómina quedan congelados
+func (s *AccountingPeriodService) Close(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	period, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status != domain.PeriodStatusOpen {
+		return nil, fmt.Errorf("%w: period is %s", ErrInvalidStatusTransition, period.Status)
+	}
+	now := time.Now()
+	period.Status = domain.PeriodStatusClosed
+	period.ClosedBy = actorFromCtx(ctx)
+	period.ClosedAt = &now
+	if err := s.periodRepo.Update(ctx, period); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// Lock bloquea definitivamente un periodo (por ejemplo tras reportarlo a las autoridades)
+func (s *AccountingPeriodService) Lock(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	period, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status == domain.PeriodStatusLocked {
+		return nil, fmt.Errorf("%w: period is already locked", ErrInvalidStatusTransition)
+	}
+	if period.ClosedAt == nil {
+		now := time.Now()
+		period.ClosedBy = actorFromCtx(ctx)
+		period.ClosedAt = &now
+	}
+	period.Status = domain.PeriodStatusLocked
+	if err := s.periodRepo.Update(ctx, period); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// Reopen reabre un periodo cerrado. Requiere el permiso accounting_periods.reopen.
+func (s *AccountingPeriodService) Reopen(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	actor := actorFromCtx(ctx)
+	if actor == 0 {
+		return nil, domain.ErrActorRequired
+	}
+	allowed, err := s.permissionSvc.UserHasPermission(ctx, actor, PeriodPermissionResource, PeriodPermissionReopen)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	period, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch period.Status {
+	case domain.PeriodStatusLocked:
+		return nil, domain.ErrPeriodLocked
+	case domain.PeriodStatusOpen:
+		return nil, fmt.Errorf("%w: period is already open", ErrInvalidStatusTransition)
+	}
+
+	period.Status = domain.PeriodStatusOpen
+	period.ClosedBy = 0
+	period.ClosedAt = nil
+	if err := s.periodRepo.Update(ctx, period); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// ensurePeriodOpen rechaza operaciones sobre fechas que caen en un periodo cerrado o bloqueado
+func ensurePeriodOpen(ctx context.Context, periodRepo domain.AccountingPeriodRepo, start, end time.Time) error {
+	periods, err := periodRepo.FindOverlapping(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	for _, p := range periods {
+		if p.Status != domain.PeriodStatusOpen {
+			return fmt.Errorf("%w: %s (%s to %s)", domain.ErrPeriodClosed,
+				p.Status, p.PeriodStart.Format("2006-01-02"), p.PeriodEnd.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
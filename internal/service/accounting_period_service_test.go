@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountingPeriodRepo struct {
+	mock.Mock
+}
+
+func (m *MockAccountingPeriodRepo) Create(ctx context.Context, period *domain.AccountingPeriod) error {
+	args := m.Called(ctx, period)
+	return args.Error(0)
+}
+
+func (m *MockAccountingPeriodRepo) GetByID(ctx context.Context, id uint) (*domain.AccountingPeriod, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepo) List(ctx context.Context) ([]domain.AccountingPeriod, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepo) FindOverlapping(ctx context.Context, start, end time.Time) ([]domain.AccountingPeriod, error) {
+	args := m.Called(ctx, start, end)
+	return args.Get(0).([]domain.AccountingPeriod), args.Error(1)
+}
+
+func (m *MockAccountingPeriodRepo) Update(ctx context.Context, period *domain.AccountingPeriod) error {
+	args := m.Called(ctx, period)
+	return args.Error(0)
+}
+
+// newOpenPeriodRepo simula un tenant sin periodos cerrados
+func newOpenPeriodRepo() *MockAccountingPeriodRepo {
+	repo := new(MockAccountingPeriodRepo)
+	repo.On("FindOverlapping", mock.Anything, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{}, nil)
+	return repo
+}
+
+type MockUserRoleRepo struct {
+	mock.Mock
+}
+
+func (m *MockUserRoleRepo) AssignRole(ctx context.Context, userID, roleID uint) error {
+	args := m.Called(ctx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockUserRoleRepo) RevokeRole(ctx context.Context, userID, roleID uint) error {
+	args := m.Called(ctx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockUserRoleRepo) GetUserRoles(ctx context.Context, userID uint) ([]domain.Role, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockUserRoleRepo) GetRoleUsers(ctx context.Context, roleID uint) ([]domain.User, error) {
+	args := m.Called(ctx, roleID)
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+// roleWithPermission arma un rol activo con un único permiso resource.action
+func roleWithPermission(resource, action string) domain.Role {
+	return domain.Role{
+		Name:     "role_" + resource + "_" + action,
+		IsActive: true,
+		RolePermissions: []domain.RolePermission{
+			{Action: domain.PermissionAction{
+				Action:   action,
+				IsActive: true,
+				Resource: domain.Permission{Name: resource, IsActive: true},
+			}},
+		},
+	}
+}
+
+func TestPayrollCalculator_CalculateAndSave_ClosedPeriod(t *testing.T) {
+	ctx := context.Background()
+
+	periodRepo := new(MockAccountingPeriodRepo)
+	periodRepo.On("FindOverlapping", ctx, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{
+		{ID: 1, Status: domain.PeriodStatusClosed,
+			PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	mockPayrollRepo := new(MockPayrollRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, new(MockPayrollItemRepo), new(MockEmployeeRepo), new(MockContractRepo), new(MockConceptRepo), periodRepo, newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	_, err := calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+	mockPayrollRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPayrollService_Delete_ClosedPeriod(t *testing.T) {
+	ctx := context.Background()
+
+	periodRepo := new(MockAccountingPeriodRepo)
+	periodRepo.On("FindOverlapping", ctx, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{
+		{ID: 1, Status: domain.PeriodStatusLocked},
+	}, nil)
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollRepo.On("GetByID", ctx, uint(7)).Return(&domain.Payroll{ID: 7, Status: domain.PayrollStatusDraft}, nil)
+	payrollSvc := NewPayrollService(mockPayrollRepo, periodRepo, nil)
+
+	err := payrollSvc.Delete(ctx, 7)
+
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+	mockPayrollRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAccountingPeriodService_Reopen(t *testing.T) {
+	ctx := withActor(context.Background(), 5)
+
+	t.Run("❌ Error - Without reopen permission", func(t *testing.T) {
+		userRoleRepo := new(MockUserRoleRepo)
+		userRoleRepo.On("GetUserRoles", ctx, uint(5)).Return([]domain.Role{roleWithPermission("payroll", "read")}, nil)
+		periodSvc := NewAccountingPeriodService(new(MockAccountingPeriodRepo), NewPermissionService(userRoleRepo, mocks.NewMockRoleRepo()))
+
+		_, err := periodSvc.Reopen(ctx, 1)
+
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	})
+
+	t.Run("❌ Error - Locked period cannot be reopened", func(t *testing.T) {
+		userRoleRepo := new(MockUserRoleRepo)
+		userRoleRepo.On("GetUserRoles", ctx, uint(5)).Return([]domain.Role{roleWithPermission(PeriodPermissionResource, PeriodPermissionReopen)}, nil)
+		periodRepo := new(MockAccountingPeriodRepo)
+		periodRepo.On("GetByID", ctx, uint(1)).Return(&domain.AccountingPeriod{ID: 1, Status: domain.PeriodStatusLocked}, nil)
+		periodSvc := NewAccountingPeriodService(periodRepo, NewPermissionService(userRoleRepo, mocks.NewMockRoleRepo()))
+
+		_, err := periodSvc.Reopen(ctx, 1)
+
+		assert.ErrorIs(t, err, domain.ErrPeriodLocked)
+	})
+
+	t.Run("✅ Success - Closed period reopened", func(t *testing.T) {
+		userRoleRepo := new(MockUserRoleRepo)
+		userRoleRepo.On("GetUserRoles", ctx, uint(5)).Return([]domain.Role{roleWithPermission(PeriodPermissionResource, PeriodPermissionReopen)}, nil)
+		closedAt := time.Now()
+		periodRepo := new(MockAccountingPeriodRepo)
+		periodRepo.On("GetByID", ctx, uint(1)).Return(&domain.AccountingPeriod{ID: 1, Status: domain.PeriodStatusClosed, ClosedAt: &closedAt}, nil)
+		periodRepo.On("Update", ctx, mock.AnythingOfType("*domain.AccountingPeriod")).Return(nil)
+		periodSvc := NewAccountingPeriodService(periodRepo, NewPermissionService(userRoleRepo, mocks.NewMockRoleRepo()))
+
+		period, err := periodSvc.Reopen(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.PeriodStatusOpen, period.Status)
+		assert.Nil(t, period.ClosedAt)
+	})
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/pdf"
+)
+
+// AccumulatorService consulta los acumulados anuales del empleado y genera el certificado de
+// ingresos y retenciones
+type AccumulatorService struct {
+	accumulatorRepo domain.PayrollAccumulatorRepo
+	employeeRepo    domain.EmployeeRepo
+	templateRepo    domain.PayslipTemplateRepo
+}
+
+func NewAccumulatorService(
+	accumulatorRepo domain.PayrollAccumulatorRepo,
+	employeeRepo domain.EmployeeRepo,
+	templateRepo domain.PayslipTemplateRepo,
+) *AccumulatorService {
+	return &AccumulatorService{
+		accumulatorRepo: accumulatorRepo,
+		employeeRepo:    employeeRepo,
+		templateRepo:    templateRepo,
+	}
+}
+
+// Años aceptados para consultar acumulados y certificados
+const (
+	minAccumulatorYear = 2000
+	maxAccumulatorYear = 2100
+)
+
+// IncomeCertificate es el certificado de ingresos y retenciones por rentas de trabajo
+// (formulario 220) de un empleado en un año gravable
+type IncomeCertificate struct {
+	Year             int
+	IssuedAt         time.Time
+	EmployerName     string
+	EmployerNIT      string
+	EmployerAddress  string
+	EmployeeID       uint
+	EmployeeDNI      string
+	EmployeeName     string
+	Salaries         float64 // salarios y horas extra
+	Benefits         float64 // prima y vacaciones
+	Severance        float64 // cesantías e intereses pagados
+	OtherPayments    float64 // demás pagos laborales
+	Gross            float64
+	Health           float64 // aportes obligatorios a salud
+	Pension          float64 // aportes obligatorios a pensión
+	OtherDeductions  float64
+	Withholding      float64 // retención en la fuente practicada
+	ContributionBase float64
+	ExemptIncome     float64 // renta exenta del 25% aplicada
+	// color es el color principal de la plantilla del desprendible
+	color string
+}
+
+// ListByEmployee retorna los acumulados del empleado en el año
+func (s *AccumulatorService) ListByEmployee(ctx context.Context, employeeID uint, year int) ([]domain.PayrollAccumulator, error) {
+	if year < minAccumulatorYear || year > maxAccumulatorYear {
+		return nil, domain.ErrInvalidCertificateYear
+	}
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.accumulatorRepo.ListByEmployee(ctx, employeeID, year)
+}
+
+// Certificate arma el certificado del año con los acumulados del empleado. Solo los años
+// ya iniciados tienen certificado; el año en curso sale con lo pagado hasta la fecha.
+func (s *AccumulatorService) Certificate(ctx context.Context, employeeID uint, year int) (*IncomeCertificate, error) {
+	if year > time.Now().Year() {
+		return nil, domain.ErrInvalidCertificateYear
+	}
+	if year < minAccumulatorYear {
+		return nil, domain.ErrInvalidCertificateYear
+	}
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	template, err := employerTemplate(ctx, s.templateRepo)
+	if err != nil {
+		return nil, err
+	}
+	accumulators, err := s.accumulatorRepo.ListByEmployee(ctx, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &IncomeCertificate{
+		Year:            year,
+		IssuedAt:        time.Now(),
+		EmployerName:    template.CompanyName,
+		EmployerNIT:     template.CompanyNIT,
+		EmployerAddress: template.CompanyAddress,
+		EmployeeID:      employee.ID,
+		EmployeeDNI:     employee.User.Dni,
+		EmployeeName:    strings.TrimSpace(employee.User.FirstName + " " + employee.User.LastName),
+		color:           template.PrimaryColor,
+	}
+	for _, acc := range accumulators {
+		switch acc.Type {
+		case domain.PayrollTypeEarning:
+			switch acc.Code {
+			case domain.ConceptBaseSalary, domain.ConceptOvertime, domain.ConceptApprenticeStipend:
+				cert.Salaries += acc.Amount
+			case domain.ConceptPrimaPayment, domain.ConceptVacationPayment:
+				cert.Benefits += acc.Amount
+			case domain.ConceptSeverancePayment, domain.ConceptSeveranceInterestPayment:
+				cert.Severance += acc.Amount
+			default:
+				cert.OtherPayments += acc.Amount
+			}
+		case domain.PayrollTypeDeduction:
+			switch acc.Code {
+			case domain.ConceptHealth:
+				cert.Health += acc.Amount
+			case domain.ConceptPension:
+				cert.Pension += acc.Amount
+			case domain.ConceptTax:
+				cert.Withholding += acc.Amount
+			default:
+				cert.OtherDeductions += acc.Amount
+			}
+		case domain.AccumulatorTypeTotal:
+			switch acc.Code {
+			case domain.AccumulatorGross:
+				cert.Gross = acc.Amount
+			case domain.AccumulatorContributionBase:
+				cert.ContributionBase = acc.Amount
+			case domain.AccumulatorExemptIncome:
+				cert.ExemptIncome = math.Min(acc.Amount, exemptIncomeAnnualUVT*uvt(year))
+			}
+		}
+	}
+	if cert.Gross == 0 {
+		return nil, domain.ErrNoIncomeForCertificate
+	}
+	return cert, nil
+}
+
+// CertificatePDF genera el certificado de ingresos y retenciones del año en PDF
+func (s *AccumulatorService) CertificatePDF(ctx context.Context, employeeID uint, year int) (*Payslip, error) {
+	cert, err := s.Certificate(ctx, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderIncomeCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	return &Payslip{
+		FileName: fmt.Sprintf("certificado-ingresos-%s-%d.pdf", cert.EmployeeDNI, cert.Year),
+		Content:  content,
+	}, nil
+}
+
+// renderIncomeCertificate imprime el certificado con el diseño del desprendible
+func renderIncomeCertificate(cert *IncomeCertificate) ([]byte, error) {
+	doc := pdf.New()
+	doc.AddPage()
+	w := &payslipWriter{doc: doc, y: payslipMargin, color: parseHexColor(cert.color)}
+	if cert.color == "" {
+		w.color = parseHexColor(defaultPayslipTemplate().PrimaryColor)
+	}
+
+	doc.SetTextColor(w.color)
+	doc.SetFont(pdf.Bold, 13)
+	doc.Text(payslipMargin, w.y+14, "Certificado de Ingresos y Retenciones")
+	doc.SetFont(pdf.Regular, 10)
+	doc.Text(payslipMargin, w.y+28, "por Rentas de Trabajo")
+	doc.SetTextColor(pdf.Black)
+	doc.SetFont(pdf.Bold, 11)
+	doc.TextRight(payslipRight, w.y+14, fmt.Sprintf("Año gravable %d", cert.Year))
+	doc.SetFont(pdf.Regular, 9)
+	doc.TextRight(payslipRight, w.y+28, "Expedido: "+cert.IssuedAt.Format("2006-01-02"))
+	w.y += 42
+	doc.FillRect(payslipMargin, w.y, payslipRight-payslipMargin, 2, w.color)
+	w.y += 18
+
+	w.field(payslipMargin, "Retenedor", cert.EmployerName)
+	w.field(320, "NIT", cert.EmployerNIT)
+	w.y += 14
+	if cert.EmployerAddress != "" {
+		w.field(payslipMargin, "Dirección", cert.EmployerAddress)
+		w.y += 14
+	}
+	w.field(payslipMargin, "Empleado", cert.EmployeeName)
+	w.field(320, "Documento", cert.EmployeeDNI)
+	w.y += 24
+
+	w.amounts("Ingresos", []certificateRow{
+		{"Pagos por salarios", cert.Salaries},
+		{"Prima y vacaciones", cert.Benefits},
+		{"Cesantías e intereses pagados", cert.Severance},
+		{"Otros pagos laborales", cert.OtherPayments},
+	}, "Total ingresos brutos", cert.Gross)
+	w.amounts("Aportes y deducciones", []certificateRow{
+		{"Aportes obligatorios a salud", cert.Health},
+		{"Aportes obligatorios a pensión", cert.Pension},
+		{"Otras deducciones", cert.OtherDeductions},
+		{"Renta exenta aplicada (25%)", cert.ExemptIncome},
+		{"Base de cotización acumulada", cert.ContributionBase},
+	}, "", 0)
+
+	w.ensure(40)
+	doc.FillRect(payslipMargin, w.y, payslipRight-payslipMargin, 24, w.color)
+	doc.SetTextColor(pdf.Color{R: 255, G: 255, B: 255})
+	doc.SetFont(pdf.Bold, 12)
+	doc.Text(payslipMargin+8, w.y+16, "RETENCIÓN EN LA FUENTE PRACTICADA")
+	doc.TextRight(payslipRight-8, w.y+16, formatCOP(cert.Withholding))
+	doc.SetTextColor(pdf.Black)
+	w.y += 38
+
+	doc.SetFont(pdf.Regular, 8)
+	note := "Valores acumulados según la fecha de pago de las nóminas del año gravable. " +
+		"Certificado generado a partir de los registros de nómina del empleador."
+	for _, line := range wrapText(note, pdf.Regular, 8, payslipRight-payslipMargin) {
+		w.ensure(12)
+		doc.Text(payslipMargin, w.y, line)
+		w.y += 10
+	}
+	return doc.Bytes()
+}
+
+// certificateRow es un renglón del certificado con su valor
+type certificateRow struct {
+	label  string
+	amount float64
+}
+
+// amounts imprime un grupo de valores del certificado con su total opcional
+func (w *payslipWriter) amounts(title string, rows []certificateRow, totalLabel string, total float64) {
+	doc := w.doc
+	w.ensure(40)
+	doc.SetTextColor(w.color)
+	doc.SetFont(pdf.Bold, 10)
+	doc.Text(payslipMargin, w.y, title)
+	doc.SetTextColor(pdf.Black)
+	w.y += 4
+	doc.Line(payslipMargin, w.y, payslipRight, w.y)
+	w.y += 12
+
+	doc.SetFont(pdf.Regular, 9)
+	for _, row := range rows {
+		w.ensure(13)
+		doc.Text(payslipMargin, w.y, row.label)
+		doc.TextRight(payslipRight, w.y, formatCOP(row.amount))
+		w.y += 13
+	}
+	if totalLabel != "" {
+		doc.Line(payslipMargin+300, w.y-8, payslipRight, w.y-8)
+		doc.SetFont(pdf.Bold, 9)
+		doc.Text(payslipMargin+300, w.y+2, totalLabel)
+		doc.TextRight(payslipRight, w.y+2, formatCOP(total))
+		w.y += 10
+	}
+	w.y += 12
+}
+
+// accumulatorYear es el año al que se imputa una nómina: el de su fecha de pago, o el del fin
+// del periodo si aún no la tiene
+func accumulatorYear(payroll *domain.Payroll) int {
+	if !payroll.PayDate.IsZero() {
+		return payroll.PayDate.Year()
+	}
+	return payroll.PeriodEnd.Year()
+}
+
+// payrollAccumulators arma los valores que una nómina suma a los acumulados del año. sign -1
+// los descuenta cuando un pago se revierte; las nóminas de reverso ya traen los items negados.
+func payrollAccumulators(payroll *domain.Payroll, sign float64) []domain.PayrollAccumulator {
+	year := accumulatorYear(payroll)
+	byCode := make(map[string]*domain.PayrollAccumulator)
+	var codes []string
+	add := func(code, kind string, amount float64) {
+		acc, ok := byCode[code]
+		if !ok {
+			acc = &domain.PayrollAccumulator{EmployeeID: payroll.EmployeeID, Year: year, Code: code, Type: kind}
+			byCode[code] = acc
+			codes = append(codes, code)
+		}
+		acc.Amount = roundCents(acc.Amount + sign*amount)
+	}
+
+	var gross float64
+	for _, item := range payroll.Items {
+		// El neto ya pagado de una nómina de reemplazo no es un ingreso ni una deducción real
+		if item.Code == domain.ConceptPriorPayment {
+			continue
+		}
+		code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+		switch item.Type {
+		case domain.PayrollTypeEarning:
+			gross += item.Amount
+			add(code, item.Type, item.Amount)
+		case domain.PayrollTypeDeduction:
+			add(code, item.Type, item.Amount)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	add(domain.AccumulatorGross, domain.AccumulatorTypeTotal, gross)
+	add(domain.AccumulatorContributionBase, domain.AccumulatorTypeTotal, payrollContributionBase(payroll.Items))
+	add(domain.AccumulatorExemptIncome, domain.AccumulatorTypeTotal,
+		periodExemptIncome(payroll.Items, year, registerDays(payroll.PeriodStart, payroll.PeriodEnd)))
+
+	accumulators := make([]domain.PayrollAccumulator, 0, len(codes))
+	for _, code := range codes {
+		accumulators = append(accumulators, *byCode[code])
+	}
+	return accumulators
+}
+
+// payrollContributionBase es el IBC de la nómina: devengos salariales más lo que los pagos no
+// salariales excedan el 40% de la remuneración. En un reverso se calcula con el signo invertido.
+func payrollContributionBase(items []domain.PayrollItem) float64 {
+	var salary, nonSalary float64
+	for _, item := range items {
+		code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+		switch {
+		case item.Type == domain.PayrollTypeEarning && slices.Contains(salaryCodes, code):
+			salary += item.Amount
+		case item.Type == domain.PayrollTypeEarning && slices.Contains(nonSalaryCodes, code):
+			nonSalary += item.Amount
+		}
+	}
+	sign := 1.0
+	if salary+nonSalary < 0 {
+		sign, salary, nonSalary = -1, -salary, -nonSalary
+	}
+	return roundCents(sign * (salary + math.Max(0, nonSalary-nonSalaryLimit*(salary+nonSalary))))
+}
+
+// accumulatePayroll suma (o descuenta con sign -1) una nómina en los acumulados del año
+func accumulatePayroll(ctx context.Context, repo domain.PayrollAccumulatorRepo, payroll *domain.Payroll, sign float64) error {
+	if err := repo.Add(ctx, payrollAccumulators(payroll, sign)); err != nil {
+		return fmt.Errorf("update payroll accumulators: %w", err)
+	}
+	return nil
+}
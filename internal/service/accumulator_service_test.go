@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPayrollAccumulatorRepo struct {
+	mock.Mock
+}
+
+func (m *MockPayrollAccumulatorRepo) Add(ctx context.Context, accumulators []domain.PayrollAccumulator) error {
+	args := m.Called(ctx, accumulators)
+	return args.Error(0)
+}
+
+func (m *MockPayrollAccumulatorRepo) ListByEmployee(ctx context.Context, employeeID uint, year int) ([]domain.PayrollAccumulator, error) {
+	args := m.Called(ctx, employeeID, year)
+	return args.Get(0).([]domain.PayrollAccumulator), args.Error(1)
+}
+
+// newAccumulatorRepoMock acepta cualquier actualización y no tiene acumulados previos
+func newAccumulatorRepoMock() *MockPayrollAccumulatorRepo {
+	accumulatorRepo := new(MockPayrollAccumulatorRepo)
+	accumulatorRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+	accumulatorRepo.On("ListByEmployee", mock.Anything, mock.Anything, mock.Anything).Return([]domain.PayrollAccumulator{}, nil)
+	return accumulatorRepo
+}
+
+func accumulatorAmounts(accumulators []domain.PayrollAccumulator) map[string]float64 {
+	amounts := make(map[string]float64, len(accumulators))
+	for _, acc := range accumulators {
+		amounts[acc.Code] = acc.Amount
+	}
+	return amounts
+}
+
+func TestComputeWithholding(t *testing.T) {
+	items := []domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 10000000},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptSeverancePayment, Amount: 5000000},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 400000},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 400000},
+	}
+
+	// Las cesantías no entran; 9.2M menos 25% exento = 6.9M = 131,7 UVT en el rango del 19%
+	w := computeWithholding(items, 2026, 30, 0)
+	assert.Equal(t, 10000000.0, w.Income)
+	assert.Equal(t, 800000.0, w.NonTaxable)
+	assert.Equal(t, 2300000.0, w.Exempt)
+	assert.Equal(t, 6900000.0, w.Base)
+	assert.Equal(t, 366000.0, w.Amount)
+
+	// Con el tope anual de renta exenta agotado la base sube al rango del 28%
+	w = computeWithholding(items, 2026, 30, exemptIncomeAnnualUVT*uvt(2026))
+	assert.Equal(t, 0.0, w.Exempt)
+	assert.Equal(t, 900000.0, w.Amount)
+
+	// Por debajo de 95 UVT no hay retención
+	w = computeWithholding([]domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 5000000},
+	}, 2026, 30, 0)
+	assert.Equal(t, 0.0, w.Amount)
+}
+
+func TestPayrollCalculator_Calculate_WithholdingUsesAccumulators(t *testing.T) {
+	ctx := context.Background()
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	accumulatorRepo := new(MockPayrollAccumulatorRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, new(MockPayrollRepo), new(MockPayrollItemRepo), mockEmployeeRepo,
+		mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), newEmptyRetroRepo(), accumulatorRepo, newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 10000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 4},
+		{ID: 3, Code: domain.ConceptPension, Name: "Pensión", Type: domain.PayrollTypeDeduction, Percentage: 4},
+		{ID: 4, Code: domain.ConceptTax, Name: "Retención", Type: domain.PayrollTypeDeduction},
+	}, nil)
+	// Renta exenta acumulada en noviembre: queda menos del 25% disponible
+	accumulatorRepo.On("ListByEmployee", ctx, uint(1), 2026).Return([]domain.PayrollAccumulator{
+		{Code: domain.AccumulatorGross, Type: domain.AccumulatorTypeTotal, Amount: 100000000},
+		{Code: domain.AccumulatorExemptIncome, Type: domain.AccumulatorTypeTotal, Amount: exemptIncomeAnnualUVT*uvt(2026) - 1000000},
+	}, nil)
+
+	result, err := calculator.Calculate(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC),
+		PayDate:     time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// Base 9.2M - 1M exento = 8.2M = 156,6 UVT en el rango del 28%
+	require.NotNil(t, result.Withholding)
+	assert.Equal(t, 1000000.0, result.Withholding.Exempt)
+	var tax float64
+	for _, item := range result.Items {
+		if item.Code == domain.ConceptTax {
+			tax = item.Amount
+		}
+	}
+	assert.Equal(t, 620000.0, tax)
+	assert.Equal(t, 1420000.0, result.TotalDeductions)
+	assert.Equal(t, 8580000.0, result.NetAmount)
+	assert.Equal(t, 8580000.0, result.Payroll.NetAmount)
+}
+
+func TestPayrollAccumulators(t *testing.T) {
+	payroll := &domain.Payroll{
+		EmployeeID: 7, PeriodStart: pilaDate(3, 1), PeriodEnd: pilaDate(3, 31), PayDate: time.Date(2027, 1, 5, 0, 0, 0, 0, time.UTC),
+		Items: []domain.PayrollItem{
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+			{Type: domain.PayrollTypeEarning, Code: domain.RetroConceptPrefix + domain.ConceptBaseSalary, Amount: 100000},
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBonus, Amount: 1500000},
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 84000},
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPriorPayment, Amount: 900000},
+			{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 252000},
+		},
+	}
+
+	accumulators := payrollAccumulators(payroll, 1)
+	amounts := accumulatorAmounts(accumulators)
+	// Se imputa al año de pago; el retroactivo suma al concepto y el pago previo no cuenta
+	assert.Equal(t, 2027, accumulators[0].Year)
+	assert.Equal(t, uint(7), accumulators[0].EmployeeID)
+	assert.Equal(t, map[string]float64{
+		domain.ConceptBaseSalary:           2100000,
+		domain.ConceptBonus:                1500000,
+		domain.ConceptHealth:               84000,
+		domain.AccumulatorGross:            3600000,
+		domain.AccumulatorContributionBase: 2160000, // bonificación sobre el 40%: 1.5M - 1.44M
+		domain.AccumulatorExemptIncome:     879000,
+	}, amounts)
+
+	// Revertir el pago descuenta exactamente lo sumado
+	for code, amount := range accumulatorAmounts(payrollAccumulators(payroll, -1)) {
+		assert.Equal(t, -amounts[code], amount, code)
+	}
+	// Un reverso con items negados descuenta lo mismo
+	reversal := &domain.Payroll{EmployeeID: 7, PeriodStart: payroll.PeriodStart, PeriodEnd: payroll.PeriodEnd,
+		PayDate: payroll.PayDate, Items: negateItems(payroll.Items, 0)}
+	for code, amount := range accumulatorAmounts(payrollAccumulators(reversal, 1)) {
+		assert.Equal(t, -amounts[code], amount, code)
+	}
+}
+
+func TestPayrollStateService_PaymentUpdatesAccumulators(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	accumulatorRepo := new(MockPayrollAccumulatorRepo)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo),
+		new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), accumulatorRepo, newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{
+		ID: 1, EmployeeID: 1, Status: domain.PayrollStatusApproved, NetAmount: 1920000,
+		PeriodStart: pilaDate(4, 1), PeriodEnd: pilaDate(4, 30), PayDate: pilaDate(4, 30),
+		Items: []domain.PayrollItem{
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 80000},
+		},
+	}
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{}, nil).Once()
+	accumulatorRepo.On("Add", ctx, mock.Anything).Return(nil)
+
+	// Un anticipo no acumula; el pago del saldo sí
+	_, err := stateSvc.RegisterPayments(ctx, 1, []PaymentPart{{Method: "cash", Amount: 920000}})
+	require.NoError(t, err)
+	accumulatorRepo.AssertNotCalled(t, "Add", ctx, mock.Anything)
+
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{{ID: 1, Amount: 920000}}, nil)
+	_, err = stateSvc.RegisterPayments(ctx, 1, []PaymentPart{{Method: "cash"}})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PayrollStatusPaid, payroll.Status)
+	accumulatorRepo.AssertCalled(t, "Add", ctx, mock.MatchedBy(func(accs []domain.PayrollAccumulator) bool {
+		amounts := accumulatorAmounts(accs)
+		return amounts[domain.AccumulatorGross] == 2000000 && amounts[domain.ConceptHealth] == 80000 && accs[0].Year == 2026
+	}))
+}
+
+func TestAccumulatorService_Certificate(t *testing.T) {
+	ctx := context.Background()
+	accumulatorRepo := new(MockPayrollAccumulatorRepo)
+	employeeRepo := new(MockEmployeeRepo)
+	templateRepo := new(MockPayslipTemplateRepo)
+	svc := NewAccumulatorService(accumulatorRepo, employeeRepo, templateRepo)
+
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{
+		ID: 1, User: domain.User{FirstName: "Ana", LastName: "Gómez", Dni: "12345678"},
+	}, nil)
+	templateRepo.On("Get", ctx).Return(&domain.PayslipTemplate{CompanyName: "Acme SAS", CompanyNIT: "900123456"}, nil)
+	accumulatorRepo.On("ListByEmployee", ctx, uint(1), 2025).Return([]domain.PayrollAccumulator{
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 1000000},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 1000000},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptTax, Amount: 750000},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 24000000},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBonus, Amount: 500000},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptPrimaPayment, Amount: 2000000},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptSeverancePayment, Amount: 1800000},
+		{Type: domain.AccumulatorTypeTotal, Code: domain.AccumulatorGross, Amount: 28300000},
+		{Type: domain.AccumulatorTypeTotal, Code: domain.AccumulatorExemptIncome, Amount: 6000000},
+	}, nil)
+	accumulatorRepo.On("ListByEmployee", ctx, uint(1), 2024).Return([]domain.PayrollAccumulator{}, nil)
+
+	cert, err := svc.Certificate(ctx, 1, 2025)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme SAS", cert.EmployerName)
+	assert.Equal(t, "Ana Gómez", cert.EmployeeName)
+	assert.Equal(t, 24000000.0, cert.Salaries)
+	assert.Equal(t, 2000000.0, cert.Benefits)
+	assert.Equal(t, 1800000.0, cert.Severance)
+	assert.Equal(t, 500000.0, cert.OtherPayments)
+	assert.Equal(t, 28300000.0, cert.Gross)
+	assert.Equal(t, 2000000.0, cert.Health+cert.Pension)
+	assert.Equal(t, 750000.0, cert.Withholding)
+	assert.Equal(t, 6000000.0, cert.ExemptIncome)
+
+	content, err := renderIncomeCertificate(cert)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF", string(content[:4]))
+
+	_, err = svc.Certificate(ctx, 1, 2024)
+	assert.ErrorIs(t, err, domain.ErrNoIncomeForCertificate)
+	_, err = svc.Certificate(ctx, 1, time.Now().Year()+1)
+	assert.ErrorIs(t, err, domain.ErrInvalidCertificateYear)
+}
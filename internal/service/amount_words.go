@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var wordsUnder30 = []string{
+	"cero", "uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho", "nueve",
+	"diez", "once", "doce", "trece", "catorce", "quince", "dieciséis", "diecisiete", "dieciocho", "diecinueve",
+	"veinte", "veintiuno", "veintidós", "veintitrés", "veinticuatro", "veinticinco", "veintiséis", "veintisiete",
+	"veintiocho", "veintinueve",
+}
+
+var wordsTens = []string{"", "", "", "treinta", "cuarenta", "cincuenta", "sesenta", "setenta", "ochenta", "noventa"}
+
+var wordsHundreds = []string{"", "ciento", "doscientos", "trescientos", "cuatrocientos", "quinientos",
+	"seiscientos", "setecientos", "ochocientos", "novecientos"}
+
+// amountInWords escribe un monto en pesos en letras, como se usa en los desprendibles:
+// 1500000.5 -> "UN MILLÓN QUINIENTOS MIL PESOS CON 50/100 M/CTE"
+func amountInWords(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	pesos := cents / 100
+	words := numberInWords(pesos, true)
+	// "un millón de pesos", "dos millones de pesos"
+	if pesos >= 1000000 && pesos%1000000 == 0 {
+		words += " de"
+	}
+	currency := "pesos"
+	if pesos == 1 {
+		currency = "peso"
+	}
+	result := fmt.Sprintf("%s %s", words, currency)
+	if rest := cents % 100; rest > 0 {
+		result += fmt.Sprintf(" con %02d/100", rest)
+	}
+	return strings.ToUpper(result + " m/cte")
+}
+
+// numberInWords escribe un entero en letras; apocope acorta "uno" a "un" al final
+// ("un peso", "veintiún mil")
+func numberInWords(n int64, apocope bool) string {
+	switch {
+	case n >= 1000000:
+		millions, rest := n/1000000, n%1000000
+		var words string
+		if millions == 1 {
+			words = "un millón"
+		} else {
+			words = numberInWords(millions, true) + " millones"
+		}
+		if rest > 0 {
+			words += " " + numberInWords(rest, apocope)
+		}
+		return words
+	case n >= 1000:
+		thousands, rest := n/1000, n%1000
+		words := "mil"
+		if thousands > 1 {
+			words = numberInWords(thousands, true) + " mil"
+		}
+		if rest > 0 {
+			words += " " + numberInWords(rest, apocope)
+		}
+		return words
+	case n >= 100:
+		if n == 100 {
+			return "cien"
+		}
+		words := wordsHundreds[n/100]
+		if rest := n % 100; rest > 0 {
+			words += " " + numberInWords(rest, apocope)
+		}
+		return words
+	case n >= 30:
+		words := wordsTens[n/10]
+		if rest := n % 10; rest > 0 {
+			words += " y " + numberInWords(rest, apocope)
+		}
+		return words
+	}
+	if apocope {
+		switch n {
+		case 1:
+			return "un"
+		case 21:
+			return "veintiún"
+		}
+	}
+	return wordsUnder30[n]
+}
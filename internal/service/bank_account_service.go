@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// BankAccountService administra las cuentas de pago de los empleados
+type BankAccountService struct {
+	txManager    domain.TxManager
+	accountRepo  domain.EmployeeBankAccountRepo
+	employeeRepo domain.EmployeeRepo
+}
+
+func NewBankAccountService(
+	txManager domain.TxManager,
+	accountRepo domain.EmployeeBankAccountRepo,
+	employeeRepo domain.EmployeeRepo,
+) *BankAccountService {
+	return &BankAccountService{
+		txManager:    txManager,
+		accountRepo:  accountRepo,
+		employeeRepo: employeeRepo,
+	}
+}
+
+// List retorna las cuentas del empleado con la principal primero
+func (s *BankAccountService) List(ctx context.Context, employeeID uint) ([]domain.EmployeeBankAccount, error) {
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.accountRepo.ListByEmployee(ctx, employeeID)
+}
+
+// GetByID retorna una cuenta validando que pertenezca al empleado
+func (s *BankAccountService) GetByID(ctx context.Context, employeeID, accountID uint) (*domain.EmployeeBankAccount, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.EmployeeID != employeeID {
+		return nil, domain.ErrBankAccountNotFound
+	}
+	return account, nil
+}
+
+// Create agrega una cuenta al empleado. La primera cuenta activa queda como principal y una
+// cuenta marcada como principal le quita la marca a las demás.
+func (s *BankAccountService) Create(ctx context.Context, employeeID uint, account *domain.EmployeeBankAccount) error {
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return err
+	}
+	if err := account.Validate(); err != nil {
+		return err
+	}
+	account.ID = 0
+	account.EmployeeID = employeeID
+	account.IsActive = true
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		others, err := s.activeAccounts(ctx, employeeID, 0)
+		if err != nil {
+			return err
+		}
+		if len(others) == 0 {
+			account.IsPrimary = true
+		}
+		if err := checkSplitTotal(others, account); err != nil {
+			return err
+		}
+		if err := s.accountRepo.Create(ctx, account); err != nil {
+			return err
+		}
+		if account.IsPrimary {
+			return s.accountRepo.ClearPrimary(ctx, employeeID, account.ID)
+		}
+		return nil
+	})
+}
+
+// Update cambia los datos de la cuenta. La principal solo deja de serlo cuando otra cuenta
+// se marca como principal; desactivarla exige elegir antes otra principal.
+func (s *BankAccountService) Update(ctx context.Context, employeeID uint, account *domain.EmployeeBankAccount) error {
+	current, err := s.GetByID(ctx, employeeID, account.ID)
+	if err != nil {
+		return err
+	}
+	if err := account.Validate(); err != nil {
+		return err
+	}
+	account.EmployeeID = employeeID
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		others, err := s.activeAccounts(ctx, employeeID, account.ID)
+		if err != nil {
+			return err
+		}
+		if !account.IsActive {
+			if current.IsPrimary && len(others) > 0 {
+				return domain.ErrPrimaryAccountInactive
+			}
+			account.IsPrimary = false
+			account.SplitPercentage = 0
+		} else if current.IsPrimary || len(others) == 0 {
+			account.IsPrimary = true
+		}
+		if account.IsActive {
+			if err := checkSplitTotal(others, account); err != nil {
+				return err
+			}
+		}
+		if err := s.accountRepo.Update(ctx, account); err != nil {
+			return err
+		}
+		if account.IsPrimary {
+			return s.accountRepo.ClearPrimary(ctx, employeeID, account.ID)
+		}
+		return nil
+	})
+}
+
+// SetPrimary marca la cuenta como principal del empleado
+func (s *BankAccountService) SetPrimary(ctx context.Context, employeeID, accountID uint) (*domain.EmployeeBankAccount, error) {
+	account, err := s.GetByID(ctx, employeeID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, domain.ErrPrimaryAccountInactive
+	}
+	account.IsPrimary = true
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.accountRepo.Update(ctx, account); err != nil {
+			return err
+		}
+		return s.accountRepo.ClearPrimary(ctx, employeeID, account.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Deactivate desactiva la cuenta; los pagos ya hechos conservan su copia de los datos
+func (s *BankAccountService) Deactivate(ctx context.Context, employeeID, accountID uint) error {
+	account, err := s.GetByID(ctx, employeeID, accountID)
+	if err != nil {
+		return err
+	}
+	account.IsActive = false
+	return s.Update(ctx, employeeID, account)
+}
+
+// activeAccounts retorna las cuentas activas del empleado excepto exceptID
+func (s *BankAccountService) activeAccounts(ctx context.Context, employeeID, exceptID uint) ([]domain.EmployeeBankAccount, error) {
+	accounts, err := s.accountRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]domain.EmployeeBankAccount, 0, len(accounts))
+	for _, a := range accounts {
+		if a.IsActive && a.ID != exceptID {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+// checkSplitTotal valida que los porcentajes de reparto de las cuentas activas no pasen de 100;
+// lo que falte para 100 va a la cuenta principal (ver splitPayment)
+func checkSplitTotal(others []domain.EmployeeBankAccount, account *domain.EmployeeBankAccount) error {
+	total := account.SplitPercentage
+	for _, a := range others {
+		total += a.SplitPercentage
+	}
+	if roundCents(total) > 100 {
+		return domain.ErrInvalidPaymentSplit
+	}
+	return nil
+}
+
+// splitPayment reparte el monto entre las cuentas activas del empleado: por porcentaje si
+// alguna cuenta lo define o completo a la principal. Si los porcentajes suman menos de 100,
+// el resto va a la principal. El último tramo absorbe el redondeo para que la suma sea
+// exactamente el monto.
+func splitPayment(accounts []domain.EmployeeBankAccount, amount float64) ([]domain.Payment, error) {
+	var primary *domain.EmployeeBankAccount
+	var split []domain.EmployeeBankAccount
+	var total float64
+	active := 0
+	for i := range accounts {
+		if !accounts[i].IsActive {
+			continue
+		}
+		active++
+		if accounts[i].IsPrimary && primary == nil {
+			primary = &accounts[i]
+		}
+		if accounts[i].SplitPercentage > 0 {
+			split = append(split, accounts[i])
+			total += accounts[i].SplitPercentage
+		}
+	}
+	if active == 0 {
+		return nil, domain.ErrNoBankAccount
+	}
+	if roundCents(total) > 100 {
+		return nil, domain.ErrInvalidPaymentSplit
+	}
+
+	// El resto hasta 100 (todo, si nadie define porcentaje) va a la principal
+	percentages := make([]float64, len(split))
+	for i := range split {
+		percentages[i] = split[i].SplitPercentage
+	}
+	if remainder := roundCents(100 - total); remainder > 0 {
+		if primary == nil {
+			return nil, domain.ErrNoPrimaryBankAccount
+		}
+		found := false
+		for i := range split {
+			if split[i].ID == primary.ID {
+				percentages[i] += remainder
+				found = true
+			}
+		}
+		if !found {
+			split = append(split, *primary)
+			percentages = append(percentages, remainder)
+		}
+	}
+
+	payments := make([]domain.Payment, len(split))
+	remaining := amount
+	for i := range split {
+		part := roundCents(amount * percentages[i] / 100)
+		if i == len(split)-1 {
+			part = roundCents(remaining)
+		}
+		remaining -= part
+		payments[i] = accountPayment(&split[i], part)
+	}
+	return payments, nil
+}
+
+// accountPayment crea un pago con la copia de los datos de la cuenta
+func accountPayment(account *domain.EmployeeBankAccount, amount float64) domain.Payment {
+	accountID := account.ID
+	return domain.Payment{
+		BankAccountID: &accountID,
+		BankName:      account.BankName,
+		AccountType:   account.AccountType,
+		AccountNumber: account.AccountNumber,
+		Amount:        amount,
+	}
+}
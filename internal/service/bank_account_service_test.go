@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSplitPayment_ByPercentage(t *testing.T) {
+	accounts := []domain.EmployeeBankAccount{
+		{ID: 1, BankName: "Bancolombia", AccountNumber: "00123456789", IsPrimary: true, IsActive: true, SplitPercentage: 33.33},
+		{ID: 2, BankName: "Nequi", AccountNumber: "3001234567", IsActive: true, SplitPercentage: 66.67},
+		{ID: 3, BankName: "Davivienda", AccountNumber: "001234567890", IsActive: false, SplitPercentage: 50},
+	}
+
+	payments, err := splitPayment(accounts, 1000000.01)
+
+	assert.NoError(t, err)
+	assert.Len(t, payments, 2)
+	assert.Equal(t, 333300.0, payments[0].Amount)
+	assert.Equal(t, 666700.01, payments[1].Amount)
+	assert.Equal(t, "Nequi", payments[1].BankName)
+	assert.Equal(t, uint(2), *payments[1].BankAccountID)
+}
+
+func TestSplitPayment_Errors(t *testing.T) {
+	_, err := splitPayment(nil, 100)
+	assert.ErrorIs(t, err, domain.ErrNoBankAccount)
+
+	_, err = splitPayment([]domain.EmployeeBankAccount{{ID: 1, IsActive: true}}, 100)
+	assert.ErrorIs(t, err, domain.ErrNoPrimaryBankAccount)
+
+	_, err = splitPayment([]domain.EmployeeBankAccount{
+		{ID: 1, IsActive: true, IsPrimary: true, SplitPercentage: 60},
+		{ID: 2, IsActive: true, SplitPercentage: 50},
+	}, 100)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentSplit)
+
+	// Sin principal no hay a dónde enviar el resto
+	_, err = splitPayment([]domain.EmployeeBankAccount{{ID: 2, IsActive: true, SplitPercentage: 30}}, 100)
+	assert.ErrorIs(t, err, domain.ErrNoPrimaryBankAccount)
+}
+
+func TestSplitPayment_RemainderGoesToPrimary(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts []domain.EmployeeBankAccount
+		want     map[uint]float64
+	}{
+		{
+			name: "primary with percentage",
+			accounts: []domain.EmployeeBankAccount{
+				{ID: 1, IsActive: true, IsPrimary: true, SplitPercentage: 60},
+				{ID: 2, IsActive: true, SplitPercentage: 30},
+			},
+			want: map[uint]float64{1: 700000, 2: 300000},
+		},
+		{
+			name: "primary without percentage",
+			accounts: []domain.EmployeeBankAccount{
+				{ID: 1, IsActive: true, IsPrimary: true},
+				{ID: 2, IsActive: true, SplitPercentage: 25},
+			},
+			want: map[uint]float64{1: 750000, 2: 250000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := splitPayment(tt.accounts, 1000000)
+
+			assert.NoError(t, err)
+			got := make(map[uint]float64)
+			for _, p := range payments {
+				got[*p.BankAccountID] += p.Amount
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBankAccountService_Create_ValidatesBankFormat(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := new(MockEmployeeBankAccountRepo)
+	employeeRepo := new(MockEmployeeRepo)
+	svc := NewBankAccountService(&MockTxManager{}, accountRepo, employeeRepo)
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+
+	// Bancolombia usa cuentas de 11 dígitos
+	err := svc.Create(ctx, 1, &domain.EmployeeBankAccount{
+		BankName: "Bancolombia", AccountType: domain.BankAccountSavings, AccountNumber: "123456789",
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidAccountNumber)
+
+	err = svc.Create(ctx, 1, &domain.EmployeeBankAccount{
+		BankName: "Banco Agrario", AccountType: "credit", AccountNumber: "123456789",
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidAccountType)
+	accountRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestBankAccountService_Create_FirstAccountIsPrimary(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := new(MockEmployeeBankAccountRepo)
+	employeeRepo := new(MockEmployeeRepo)
+	svc := NewBankAccountService(&MockTxManager{}, accountRepo, employeeRepo)
+
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	accountRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeBankAccount{}, nil)
+	accountRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeBankAccount")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.EmployeeBankAccount).ID = 9 }).Return(nil)
+	accountRepo.On("ClearPrimary", ctx, uint(1), uint(9)).Return(nil)
+
+	account := &domain.EmployeeBankAccount{BankName: "Nequi", AccountType: "SAVINGS", AccountNumber: "300 123 4567"}
+	err := svc.Create(ctx, 1, account)
+
+	assert.NoError(t, err)
+	assert.True(t, account.IsPrimary)
+	assert.Equal(t, "3001234567", account.AccountNumber)
+	accountRepo.AssertCalled(t, "ClearPrimary", ctx, uint(1), uint(9))
+}
+
+func TestBankAccountService_Deactivate_PrimaryWithOtherAccounts(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := new(MockEmployeeBankAccountRepo)
+	svc := NewBankAccountService(&MockTxManager{}, accountRepo, new(MockEmployeeRepo))
+
+	primary := domain.EmployeeBankAccount{ID: 1, EmployeeID: 1, BankName: "Nequi", AccountType: domain.BankAccountSavings,
+		AccountNumber: "3001234567", IsPrimary: true, IsActive: true}
+	accountRepo.On("GetByID", ctx, uint(1)).Return(&primary, nil)
+	accountRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeBankAccount{
+		primary,
+		{ID: 2, EmployeeID: 1, IsActive: true},
+	}, nil)
+
+	err := svc.Deactivate(ctx, 1, 1)
+
+	assert.ErrorIs(t, err, domain.ErrPrimaryAccountInactive)
+	accountRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/bankfile"
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// BankFileService genera los archivos de pago que tesorería carga en el banco
+type BankFileService struct {
+	txManager    domain.TxManager
+	payrollRepo  domain.PayrollRepo
+	paymentRepo  domain.PaymentRepo
+	fileRepo     domain.BankPaymentFileRepo
+	templateRepo domain.BankFileTemplateRepo
+	stateSvc     *PayrollStateService
+}
+
+func NewBankFileService(
+	txManager domain.TxManager,
+	payrollRepo domain.PayrollRepo,
+	paymentRepo domain.PaymentRepo,
+	fileRepo domain.BankPaymentFileRepo,
+	templateRepo domain.BankFileTemplateRepo,
+	stateSvc *PayrollStateService,
+) *BankFileService {
+	return &BankFileService{
+		txManager:    txManager,
+		payrollRepo:  payrollRepo,
+		paymentRepo:  paymentRepo,
+		fileRepo:     fileRepo,
+		templateRepo: templateRepo,
+		stateSvc:     stateSvc,
+	}
+}
+
+// BankFileRequest define el archivo a generar
+type BankFileRequest struct {
+	Format        string
+	TemplateID    uint
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	ExecutionDate time.Time
+	Debtor        bankfile.Debtor
+}
+
+// Export genera un archivo con el saldo pendiente de las nóminas aprobadas o pagadas
+// parcialmente del periodo; las calculadas esperan su aprobación. Cada nómina se paga por
+// transferencia a las cuentas del empleado y los pagos quedan sent con la referencia del
+// archivo. Si una nómina no se puede pagar no se genera nada.
+func (s *BankFileService) Export(ctx context.Context, req BankFileRequest) (*domain.BankPaymentFile, error) {
+	if req.Debtor.Name == "" || req.Debtor.AccountNumber == "" {
+		return nil, domain.ErrDebtorAccountRequired
+	}
+	if req.PeriodEnd.Before(req.PeriodStart) {
+		return nil, domain.ErrInvalidPeriod
+	}
+	var template *domain.BankFileTemplate
+	if req.Format == domain.BankFileFormatFixedWidth && req.TemplateID != 0 {
+		t, err := s.templateRepo.GetByID(ctx, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		template = t
+	}
+	exporter, err := bankfile.NewExporter(req.Format, template)
+	if err != nil {
+		return nil, err
+	}
+
+	payrolls, err := s.payrollRepo.GetByPeriod(ctx, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	var payable []domain.Payroll
+	for _, p := range payrolls {
+		if (p.Status == domain.PayrollStatusApproved || p.Status == domain.PayrollStatusPartiallyPaid) && p.NetAmount > 0 {
+			payable = append(payable, p)
+		}
+	}
+	if len(payable) == 0 {
+		return nil, domain.ErrNoPayrollsToExport
+	}
+
+	now := time.Now()
+	if req.ExecutionDate.IsZero() {
+		req.ExecutionDate = now
+	}
+	batch := &bankfile.Batch{
+		Reference:     "NOM" + now.Format("20060102150405"),
+		CreatedAt:     now,
+		ExecutionDate: req.ExecutionDate,
+		Debtor:        req.Debtor,
+	}
+	file := &domain.BankPaymentFile{
+		Reference:     batch.Reference,
+		Format:        exporter.Format(),
+		PeriodStart:   req.PeriodStart,
+		PeriodEnd:     req.PeriodEnd,
+		ExecutionDate: req.ExecutionDate,
+		FileName:      fmt.Sprintf("%s.%s", strings.ToLower(batch.Reference), exporter.Extension()),
+		CreatedBy:     actorFromCtx(ctx),
+		CreatedAt:     now,
+	}
+	if template != nil {
+		file.TemplateID = &template.ID
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var payments []domain.Payment
+		for i := range payable {
+			payroll := &payable[i]
+			paid, err := s.stateSvc.MarkAsPaid(ctx, payroll.ID, domain.PaymentMethodBankTransfer)
+			if err != nil {
+				return fmt.Errorf("payroll %d: %w", payroll.ID, err)
+			}
+			for _, p := range paid {
+				batch.Transfers = append(batch.Transfers, bankTransfer(batch.Reference, payroll, &p))
+			}
+			payments = append(payments, paid...)
+		}
+
+		content, err := exporter.Export(batch)
+		if err != nil {
+			return err
+		}
+		file.Content = content
+		file.Hash = bankfile.FileHash(content)
+		file.RecordCount = batch.Count()
+		file.TotalAmount = batch.Total()
+		if err := s.fileRepo.Create(ctx, file); err != nil {
+			return err
+		}
+
+		for i := range payments {
+			payments[i].Status = domain.PaymentStatusSent
+			payments[i].ProviderRef = batch.Transfers[i].EndToEndID
+			payments[i].BankFileID = &file.ID
+			payments[i].SentAt = &now
+			if err := s.paymentRepo.UpdateStatus(ctx, &payments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// bankTransfer arma la transferencia de un pago con los datos del empleado
+func bankTransfer(reference string, payroll *domain.Payroll, payment *domain.Payment) bankfile.Transfer {
+	user := payroll.Employee.User
+	return bankfile.Transfer{
+		PaymentID:     payment.ID,
+		PayrollID:     payroll.ID,
+		EndToEndID:    fmt.Sprintf("%s-%d", reference, payment.ID),
+		EmployeeDni:   user.Dni,
+		EmployeeName:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		BankName:      payment.BankName,
+		AccountType:   payment.AccountType,
+		AccountNumber: payment.AccountNumber,
+		Amount:        payment.Amount,
+	}
+}
+
+// GetFile retorna un archivo generado con su contenido
+func (s *BankFileService) GetFile(ctx context.Context, id uint) (*domain.BankPaymentFile, error) {
+	return s.fileRepo.GetByID(ctx, id)
+}
+
+// ListFiles retorna los archivos generados sin su contenido
+func (s *BankFileService) ListFiles(ctx context.Context) ([]domain.BankPaymentFile, error) {
+	return s.fileRepo.List(ctx)
+}
+
+// ContentType retorna el tipo MIME del formato del archivo
+func (s *BankFileService) ContentType(file *domain.BankPaymentFile) string {
+	// La plantilla no cambia el tipo de contenido, basta un exportador vacío
+	exporter, err := bankfile.NewExporter(file.Format, &domain.BankFileTemplate{})
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return exporter.ContentType()
+}
+
+// CreateTemplate registra la plantilla de ancho fijo de un banco
+func (s *BankFileService) CreateTemplate(ctx context.Context, template *domain.BankFileTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	template.ID = 0
+	return s.templateRepo.Create(ctx, template)
+}
+
+// GetTemplate retorna una plantilla con sus columnas
+func (s *BankFileService) GetTemplate(ctx context.Context, id uint) (*domain.BankFileTemplate, error) {
+	return s.templateRepo.GetByID(ctx, id)
+}
+
+// ListTemplates retorna las plantillas del tenant
+func (s *BankFileService) ListTemplates(ctx context.Context) ([]domain.BankFileTemplate, error) {
+	return s.templateRepo.List(ctx)
+}
+
+// UpdateTemplate reemplaza los datos y las columnas de la plantilla
+func (s *BankFileService) UpdateTemplate(ctx context.Context, template *domain.BankFileTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.templateRepo.Update(ctx, template)
+	})
+}
+
+// DeleteTemplate elimina una plantilla; los archivos ya generados conservan su contenido
+func (s *BankFileService) DeleteTemplate(ctx context.Context, id uint) error {
+	return s.templateRepo.Delete(ctx, id)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/bankfile"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBankPaymentFileRepo struct {
+	mock.Mock
+}
+
+func (m *MockBankPaymentFileRepo) Create(ctx context.Context, file *domain.BankPaymentFile) error {
+	args := m.Called(ctx, file)
+	file.ID = 4
+	return args.Error(0)
+}
+
+func (m *MockBankPaymentFileRepo) GetByID(ctx context.Context, id uint) (*domain.BankPaymentFile, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BankPaymentFile), args.Error(1)
+}
+
+func (m *MockBankPaymentFileRepo) List(ctx context.Context) ([]domain.BankPaymentFile, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.BankPaymentFile), args.Error(1)
+}
+
+type MockBankFileTemplateRepo struct {
+	mock.Mock
+}
+
+func (m *MockBankFileTemplateRepo) Create(ctx context.Context, template *domain.BankFileTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockBankFileTemplateRepo) GetByID(ctx context.Context, id uint) (*domain.BankFileTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BankFileTemplate), args.Error(1)
+}
+
+func (m *MockBankFileTemplateRepo) List(ctx context.Context) ([]domain.BankFileTemplate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.BankFileTemplate), args.Error(1)
+}
+
+func (m *MockBankFileTemplateRepo) Update(ctx context.Context, template *domain.BankFileTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockBankFileTemplateRepo) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestBankFileService_Export_CSV(t *testing.T) {
+	ctx := context.Background()
+
+	payrollRepo := new(MockPayrollRepo)
+	paymentRepo := new(MockPaymentRepo)
+	accountRepo := new(MockEmployeeBankAccountRepo)
+	fileRepo := new(MockBankPaymentFileRepo)
+	historyRepo, transitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, payrollRepo, paymentRepo, new(MockEmployeeRepo), accountRepo, historyRepo, transitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	svc := NewBankFileService(&MockTxManager{}, payrollRepo, paymentRepo, fileRepo, new(MockBankFileTemplateRepo), stateSvc)
+
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	approved := domain.Payroll{ID: 1, EmployeeID: 1, Status: domain.PayrollStatusApproved, NetAmount: 1250000.5,
+		Employee: domain.Employee{ID: 1, User: domain.User{FirstName: "Ana", LastName: "Gomez", Dni: "12345678"}}}
+	payrollRepo.On("GetByPeriod", ctx, start, end).Return([]domain.Payroll{
+		approved,
+		{ID: 2, EmployeeID: 2, Status: domain.PayrollStatusDraft, NetAmount: 900000},
+	}, nil)
+	payrollRepo.On("LockByID", ctx, uint(1)).Return(&approved, nil)
+	payrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	paymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{}, nil)
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	paymentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	accountRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeBankAccount{
+		{ID: 5, EmployeeID: 1, BankName: "Bancolombia", AccountType: domain.BankAccountSavings, AccountNumber: "00123456789", IsPrimary: true, IsActive: true},
+	}, nil)
+	fileRepo.On("Create", ctx, mock.AnythingOfType("*domain.BankPaymentFile")).Return(nil)
+
+	file, err := svc.Export(ctx, BankFileRequest{
+		Format:      domain.BankFileFormatCSV,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Debtor:      bankfile.Debtor{Name: "ACME SAS", AccountNumber: "00999999999"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, file.RecordCount)
+	assert.Equal(t, 1250000.5, file.TotalAmount)
+	assert.Equal(t, bankfile.FileHash(file.Content), file.Hash)
+	lines := strings.Split(strings.TrimSpace(string(file.Content)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "12345678,Ana Gomez,Bancolombia,savings,00123456789,1250000.50")
+	assert.True(t, strings.HasPrefix(lines[2], "TOTAL,1,1250000.50,"))
+	paymentRepo.AssertCalled(t, "UpdateStatus", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Status == domain.PaymentStatusSent && *p.BankFileID == 4 && p.ProviderRef == file.Reference+"-1"
+	}))
+}
+
+func TestBankFileService_Export_PartiallyPaidBalance(t *testing.T) {
+	ctx := context.Background()
+
+	payrollRepo := new(MockPayrollRepo)
+	paymentRepo := new(MockPaymentRepo)
+	accountRepo := new(MockEmployeeBankAccountRepo)
+	fileRepo := new(MockBankPaymentFileRepo)
+	historyRepo, transitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, payrollRepo, paymentRepo, new(MockEmployeeRepo), accountRepo, historyRepo, transitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	svc := NewBankFileService(&MockTxManager{}, payrollRepo, paymentRepo, fileRepo, new(MockBankFileTemplateRepo), stateSvc)
+
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	partial := domain.Payroll{ID: 3, EmployeeID: 1, Status: domain.PayrollStatusPartiallyPaid, NetAmount: 1000000,
+		Employee: domain.Employee{ID: 1, User: domain.User{FirstName: "Ana", LastName: "Gomez", Dni: "12345678"}}}
+	payrollRepo.On("GetByPeriod", ctx, start, end).Return([]domain.Payroll{
+		partial,
+		{ID: 4, EmployeeID: 2, Status: domain.PayrollStatusCalculated, NetAmount: 900000},
+	}, nil)
+	payrollRepo.On("LockByID", ctx, uint(3)).Return(&partial, nil)
+	payrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	paymentRepo.On("ListByPayroll", ctx, uint(3)).Return([]domain.Payment{
+		{ID: 8, PayrollID: 3, Amount: 400000, Status: domain.PaymentStatusCompleted},
+	}, nil)
+	paymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	paymentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	accountRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeBankAccount{
+		{ID: 5, EmployeeID: 1, BankName: "Bancolombia", AccountType: domain.BankAccountSavings, AccountNumber: "00123456789", IsPrimary: true, IsActive: true},
+	}, nil)
+	fileRepo.On("Create", ctx, mock.AnythingOfType("*domain.BankPaymentFile")).Return(nil)
+
+	file, err := svc.Export(ctx, BankFileRequest{
+		Format:      domain.BankFileFormatCSV,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Debtor:      bankfile.Debtor{Name: "ACME SAS", AccountNumber: "00999999999"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, file.RecordCount)
+	assert.Equal(t, float64(600000), file.TotalAmount)
+	assert.Equal(t, domain.PayrollStatusPaid, partial.Status)
+	payrollRepo.AssertNotCalled(t, "LockByID", ctx, uint(4))
+}
+
+func TestFixedWidthExporter_PadsAndTotals(t *testing.T) {
+	template := &domain.BankFileTemplate{Name: "Plano", Fields: []domain.BankFileTemplateField{
+		{Section: "trailer", Source: "total", Width: 10, Align: "right", PadChar: "0"},
+		{Section: "detail", Position: 2, Source: "amount", Width: 12, Align: "right", PadChar: "0"},
+		{Section: "detail", Position: 1, Source: "employee_name", Width: 8},
+		{Section: "header", Source: "constant", Value: "H", Width: 1},
+		{Section: "trailer", Position: -1, Source: "count", Width: 3, Align: "right", PadChar: "0"},
+	}}
+	assert.NoError(t, template.Validate())
+
+	exporter, err := bankfile.NewExporter(domain.BankFileFormatFixedWidth, template)
+	assert.NoError(t, err)
+	content, err := exporter.Export(&bankfile.Batch{Transfers: []bankfile.Transfer{
+		{EmployeeName: "Ana María Gómez", Amount: 1500.25},
+		{EmployeeName: "Luis", Amount: 10},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "H\r\nANA MARÍ000000150025\r\nLUIS    000000001000\r\n0020000151025\r\n", string(content))
+
+	template.Fields[0].Width = 0
+	assert.ErrorIs(t, template.Validate(), domain.ErrInvalidBankFileTemplate)
+}
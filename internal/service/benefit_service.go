@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// BenefitService causa las provisiones de prestaciones sociales en el ledger por empleado y
+// genera los pagos de prima semestral y la consignación anual de cesantías
+type BenefitService struct {
+	txManager       domain.TxManager
+	payrollRepo     domain.PayrollRepo
+	payrollItemRepo domain.PayrollItemRepo
+	ledgerRepo      domain.BenefitLedgerRepo
+	periodRepo      domain.AccountingPeriodRepo
+}
+
+func NewBenefitService(
+	txManager domain.TxManager,
+	payrollRepo domain.PayrollRepo,
+	payrollItemRepo domain.PayrollItemRepo,
+	ledgerRepo domain.BenefitLedgerRepo,
+	periodRepo domain.AccountingPeriodRepo,
+) *BenefitService {
+	return &BenefitService{
+		txManager:       txManager,
+		payrollRepo:     payrollRepo,
+		payrollItemRepo: payrollItemRepo,
+		ledgerRepo:      ledgerRepo,
+		periodRepo:      periodRepo,
+	}
+}
+
+// BenefitAccrualResult resume una corrida de causación
+type BenefitAccrualResult struct {
+	PayrollsPosted  int                `json:"payrolls_posted"`
+	PayrollsSkipped int                `json:"payrolls_skipped"`
+	Totals          map[string]float64 `json:"totals"`
+}
+
+// EmployeeBenefitLedger es el ledger de prestaciones de un empleado con sus saldos
+type EmployeeBenefitLedger struct {
+	EmployeeID uint                        `json:"employee_id"`
+	Entries    []domain.BenefitLedgerEntry `json:"entries"`
+	Balances   map[string]float64          `json:"balances"`
+}
+
+// benefitPayout relaciona una prestación del ledger con el item que la paga
+type benefitPayout struct {
+	benefit string
+	code    string
+	name    string
+}
+
+// paymentBenefits relaciona los códigos de pago con su prestación, para que el reverso
+// de un pago devuelva el saldo al ledger
+var paymentBenefits = map[string]string{
+	domain.ConceptPrimaPayment:             domain.BenefitPrima,
+	domain.ConceptSeverancePayment:         domain.BenefitSeverance,
+	domain.ConceptSeveranceInterestPayment: domain.BenefitSeveranceInterest,
+	domain.ConceptVacationPayment:          domain.BenefitVacation,
+}
+
+// Accrue lleva al ledger las provisiones de las nóminas pagadas del periodo. Las nóminas
+// que ya tienen movimientos se omiten, por lo que puede ejecutarse varias veces.
+func (s *BenefitService) Accrue(ctx context.Context, periodStart, periodEnd time.Time) (*BenefitAccrualResult, error) {
+	if periodStart.IsZero() || periodEnd.IsZero() || periodEnd.Before(periodStart) {
+		return nil, domain.ErrInvalidPeriod
+	}
+
+	payrolls, err := s.payrollRepo.GetByPeriod(ctx, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.postPayrolls(ctx, payrolls)
+}
+
+// AccrueEmployee lleva al ledger las nóminas pagadas del empleado aún no causadas
+func (s *BenefitService) AccrueEmployee(ctx context.Context, employeeID uint) (*BenefitAccrualResult, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	payrolls, err := s.payrollRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.postPayrolls(ctx, payrolls)
+}
+
+func (s *BenefitService) postPayrolls(ctx context.Context, payrolls []domain.Payroll) (*BenefitAccrualResult, error) {
+	result := &BenefitAccrualResult{Totals: make(map[string]float64)}
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i := range payrolls {
+			p := &payrolls[i]
+			// Las reversadas también se causan: su reverso las compensa
+			if p.Status != domain.PayrollStatusPaid && p.Status != domain.PayrollStatusReversed {
+				continue
+			}
+			posted, err := s.ledgerRepo.ExistsForPayroll(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+			if posted {
+				result.PayrollsSkipped++
+				continue
+			}
+			entries := ledgerEntriesFromPayroll(p)
+			if len(entries) == 0 {
+				continue
+			}
+			if err := s.ledgerRepo.CreateBatch(ctx, entries); err != nil {
+				return err
+			}
+			for _, e := range entries {
+				result.Totals[e.Benefit] = roundCents(result.Totals[e.Benefit] + e.Amount)
+			}
+			result.PayrollsPosted++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLedger retorna los movimientos y saldos de prestaciones de un empleado
+func (s *BenefitService) GetLedger(ctx context.Context, employeeID uint) (*EmployeeBenefitLedger, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	entries, err := s.ledgerRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	balances := map[string]float64{
+		domain.BenefitPrima:             0,
+		domain.BenefitSeverance:         0,
+		domain.BenefitSeveranceInterest: 0,
+		domain.BenefitVacation:          0,
+	}
+	for _, e := range entries {
+		balances[e.Benefit] = roundCents(balances[e.Benefit] + e.Amount)
+	}
+	return &EmployeeBenefitLedger{EmployeeID: employeeID, Entries: entries, Balances: balances}, nil
+}
+
+// PayPrima genera la nómina de prima del semestre (1 o 2) con el saldo causado a su cierre
+func (s *BenefitService) PayPrima(ctx context.Context, year, semester int, payDate time.Time) ([]domain.Payroll, error) {
+	if semester != 1 && semester != 2 {
+		return nil, errors.New("semester must be 1 or 2")
+	}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.June, 30, 0, 0, 0, 0, time.UTC)
+	if semester == 2 {
+		start = time.Date(year, time.July, 1, 0, 0, 0, 0, time.UTC)
+		end = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return s.generatePayments(ctx, start, end, payDate, []benefitPayout{
+		{benefit: domain.BenefitPrima, code: domain.ConceptPrimaPayment, name: "Prima de Servicios"},
+	})
+}
+
+// ConsignSeverance genera la consignación anual de cesantías y el pago de sus intereses
+func (s *BenefitService) ConsignSeverance(ctx context.Context, year int, payDate time.Time) ([]domain.Payroll, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	return s.generatePayments(ctx, start, end, payDate, []benefitPayout{
+		{benefit: domain.BenefitSeverance, code: domain.ConceptSeverancePayment, name: "Consignación Cesantías"},
+		{benefit: domain.BenefitSeveranceInterest, code: domain.ConceptSeveranceInterestPayment, name: "Intereses sobre Cesantías"},
+	})
+}
+
+// generatePayments crea una nómina de prestaciones por empleado con saldo positivo y registra
+// el pago en el ledger en la misma transacción, para que el saldo no se pague dos veces. Si la
+// nómina se cancela antes del pago, PayrollStateService.Cancel devuelve el saldo al ledger.
+func (s *BenefitService) generatePayments(
+	ctx context.Context,
+	periodStart, periodEnd, payDate time.Time,
+	payouts []benefitPayout,
+) ([]domain.Payroll, error) {
+	if year := periodStart.Year(); year < 2000 || year > 2100 {
+		return nil, domain.ErrInvalidPeriod
+	}
+	if payDate.IsZero() {
+		payDate = time.Now()
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, payDate, payDate); err != nil {
+		return nil, err
+	}
+
+	// Saldos por empleado, en el orden de las prestaciones a pagar
+	amounts := make(map[uint]map[string]float64)
+	var employeeIDs []uint
+	for _, payout := range payouts {
+		balances, err := s.ledgerRepo.Balances(ctx, payout.benefit, periodEnd)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range balances {
+			if b.Balance <= 0 {
+				continue
+			}
+			if _, ok := amounts[b.EmployeeID]; !ok {
+				amounts[b.EmployeeID] = make(map[string]float64)
+				employeeIDs = append(employeeIDs, b.EmployeeID)
+			}
+			amounts[b.EmployeeID][payout.benefit] = roundCents(b.Balance)
+		}
+	}
+
+	actor := actorFromCtx(ctx)
+	var created []domain.Payroll
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, employeeID := range employeeIDs {
+			payroll := &domain.Payroll{
+				EmployeeID:   employeeID,
+				PeriodStart:  periodStart,
+				PeriodEnd:    periodEnd,
+				PayDate:      payDate,
+				Status:       domain.PayrollStatusCalculated,
+				Kind:         domain.PayrollKindBenefit,
+				CalculatedBy: actor,
+			}
+			var items []domain.PayrollItem
+			var entries []domain.BenefitLedgerEntry
+			for _, payout := range payouts {
+				amount, ok := amounts[employeeID][payout.benefit]
+				if !ok {
+					continue
+				}
+				payroll.GrossAmount += amount
+				items = append(items, domain.PayrollItem{
+					Type:         domain.PayrollTypeEarning,
+					Code:         payout.code,
+					Name:         payout.name,
+					Amount:       amount,
+					CalculatedAt: time.Now(),
+				})
+				entries = append(entries, domain.BenefitLedgerEntry{
+					EmployeeID:  employeeID,
+					Benefit:     payout.benefit,
+					EntryType:   domain.BenefitEntryPayment,
+					Amount:      -amount,
+					PeriodStart: periodStart,
+					PeriodEnd:   periodEnd,
+				})
+			}
+			payroll.NetAmount = payroll.GrossAmount
+
+			if err := s.payrollRepo.Create(ctx, payroll); err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].PayrollID = payroll.ID
+			}
+			if err := s.payrollItemRepo.CreateBatch(ctx, items); err != nil {
+				return err
+			}
+			for i := range entries {
+				entries[i].PayrollID = payroll.ID
+			}
+			if err := s.ledgerRepo.CreateBatch(ctx, entries); err != nil {
+				return err
+			}
+			payroll.Items = items
+			created = append(created, *payroll)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ledgerEntriesFromPayroll agrupa por prestación los items de provisión (incluidos sus
+// retroactivos) y los pagos de prestaciones presentes en una nómina
+func ledgerEntriesFromPayroll(payroll *domain.Payroll) []domain.BenefitLedgerEntry {
+	provisions := domain.BenefitProvisionConcepts()
+	type key struct{ benefit, entryType string }
+	totals := make(map[key]float64)
+	var order []key
+
+	for _, item := range payroll.Items {
+		code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+		var k key
+		amount := item.Amount
+		if benefit, ok := provisions[code]; ok {
+			k = key{benefit, domain.BenefitEntryAccrual}
+		} else if benefit, ok := paymentBenefits[code]; ok {
+			k = key{benefit, domain.BenefitEntryPayment}
+			amount = -amount
+		} else {
+			continue
+		}
+		if _, seen := totals[k]; !seen {
+			order = append(order, k)
+		}
+		totals[k] += amount
+	}
+
+	entries := make([]domain.BenefitLedgerEntry, 0, len(order))
+	for _, k := range order {
+		amount := roundCents(totals[k])
+		if amount == 0 {
+			continue
+		}
+		entries = append(entries, domain.BenefitLedgerEntry{
+			EmployeeID:  payroll.EmployeeID,
+			PayrollID:   payroll.ID,
+			Benefit:     k.benefit,
+			EntryType:   k.entryType,
+			Amount:      amount,
+			PeriodStart: payroll.PeriodStart,
+			PeriodEnd:   payroll.PeriodEnd,
+		})
+	}
+	return entries
+}
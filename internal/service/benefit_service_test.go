@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBenefitLedgerRepo struct {
+	mock.Mock
+}
+
+func (m *MockBenefitLedgerRepo) CreateBatch(ctx context.Context, entries []domain.BenefitLedgerEntry) error {
+	args := m.Called(ctx, entries)
+	return args.Error(0)
+}
+
+func (m *MockBenefitLedgerRepo) ExistsForPayroll(ctx context.Context, payrollID uint) (bool, error) {
+	args := m.Called(ctx, payrollID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBenefitLedgerRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.BenefitLedgerEntry, error) {
+	args := m.Called(ctx, payrollID)
+	return args.Get(0).([]domain.BenefitLedgerEntry), args.Error(1)
+}
+
+func newBenefitLedgerRepoMock() *MockBenefitLedgerRepo {
+	ledgerRepo := new(MockBenefitLedgerRepo)
+	ledgerRepo.On("ListByPayroll", mock.Anything, mock.Anything).Return([]domain.BenefitLedgerEntry{}, nil)
+	return ledgerRepo
+}
+
+func (m *MockBenefitLedgerRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.BenefitLedgerEntry, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.BenefitLedgerEntry), args.Error(1)
+}
+
+func (m *MockBenefitLedgerRepo) Balances(ctx context.Context, benefit string, upTo time.Time) ([]domain.BenefitBalance, error) {
+	args := m.Called(ctx, benefit, upTo)
+	return args.Get(0).([]domain.BenefitBalance), args.Error(1)
+}
+
+func TestBenefitService_Accrue_PostsProvisionsOnce(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	ledgerRepo := new(MockBenefitLedgerRepo)
+	benefitSvc := NewBenefitService(&MockTxManager{}, mockPayrollRepo, new(MockPayrollItemRepo), ledgerRepo, newOpenPeriodRepo())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	mockPayrollRepo.On("GetByPeriod", ctx, start, end).Return([]domain.Payroll{
+		{ID: 1, EmployeeID: 1, Status: domain.PayrollStatusPaid, PeriodStart: start, PeriodEnd: end, Items: []domain.PayrollItem{
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+			{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPrimaProvision, Amount: 166600},
+			{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptVacationProvision, Amount: 83400},
+			{Type: domain.PayrollTypeEmployerContribution, Code: "RETRO_" + domain.ConceptPrimaProvision, Amount: 1000},
+		}},
+		{ID: 2, EmployeeID: 2, Status: domain.PayrollStatusPaid, PeriodStart: start, PeriodEnd: end},
+		{ID: 3, EmployeeID: 3, Status: domain.PayrollStatusDraft, PeriodStart: start, PeriodEnd: end},
+	}, nil)
+	ledgerRepo.On("ExistsForPayroll", ctx, uint(1)).Return(false, nil)
+	ledgerRepo.On("ExistsForPayroll", ctx, uint(2)).Return(true, nil)
+	ledgerRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.BenefitLedgerEntry")).Return(nil)
+
+	result, err := benefitSvc.Accrue(ctx, start, end)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.PayrollsPosted)
+	assert.Equal(t, 1, result.PayrollsSkipped)
+	assert.Equal(t, float64(167600), result.Totals[domain.BenefitPrima])
+	assert.Equal(t, float64(83400), result.Totals[domain.BenefitVacation])
+	ledgerRepo.AssertNotCalled(t, "ExistsForPayroll", ctx, uint(3))
+}
+
+func TestBenefitService_PayPrima_GeneratesPayableItems(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	ledgerRepo := new(MockBenefitLedgerRepo)
+	benefitSvc := NewBenefitService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, ledgerRepo, newOpenPeriodRepo())
+
+	semesterEnd := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	ledgerRepo.On("Balances", ctx, domain.BenefitPrima, semesterEnd).Return([]domain.BenefitBalance{
+		{EmployeeID: 1, Benefit: domain.BenefitPrima, Balance: 999600},
+		{EmployeeID: 2, Benefit: domain.BenefitPrima, Balance: -500},
+	}, nil)
+	mockPayrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	ledgerRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.BenefitLedgerEntry")).Return(nil)
+
+	payrolls, err := benefitSvc.PayPrima(ctx, 2024, 1, time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Len(t, payrolls, 1)
+	assert.Equal(t, domain.PayrollKindBenefit, payrolls[0].Kind)
+	assert.Equal(t, float64(999600), payrolls[0].NetAmount)
+	assert.Equal(t, domain.ConceptPrimaPayment, payrolls[0].Items[0].Code)
+	ledgerRepo.AssertCalled(t, "CreateBatch", ctx, mock.MatchedBy(func(entries []domain.BenefitLedgerEntry) bool {
+		return len(entries) == 1 &&
+			entries[0].EntryType == domain.BenefitEntryPayment &&
+			entries[0].Amount == -999600
+	}))
+}
+
+func TestLedgerEntriesFromPayroll_ReversedPaymentRestoresBalance(t *testing.T) {
+	reversal := &domain.Payroll{ID: 9, EmployeeID: 1, Kind: domain.PayrollKindReversal, Items: []domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptPrimaPayment, Amount: -999600},
+	}}
+
+	entries := ledgerEntriesFromPayroll(reversal)
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, domain.BenefitPrima, entries[0].Benefit)
+	assert.Equal(t, float64(999600), entries[0].Amount)
+}
+
+func TestPayrollStateService_CancelBenefitPayroll_ReleasesLedger(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	ledgerRepo := new(MockBenefitLedgerRepo)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo),
+		new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), ledgerRepo, newOpenPeriodRepo())
+
+	semesterEnd := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	payroll := &domain.Payroll{ID: 40, EmployeeID: 1, Status: domain.PayrollStatusCalculated, Kind: domain.PayrollKindBenefit}
+	mockPayrollRepo.On("GetByID", ctx, uint(40)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, payroll).Return(nil)
+	ledgerRepo.On("ListByPayroll", ctx, uint(40)).Return([]domain.BenefitLedgerEntry{
+		{ID: 3, EmployeeID: 1, PayrollID: 40, Benefit: domain.BenefitPrima, EntryType: domain.BenefitEntryPayment,
+			Amount: -1250000, PeriodEnd: semesterEnd},
+	}, nil)
+	ledgerRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+
+	err := stateSvc.Cancel(ctx, 40, "wrong semester")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PayrollStatusCancelled, payroll.Status)
+	ledgerRepo.AssertCalled(t, "CreateBatch", ctx, mock.MatchedBy(func(entries []domain.BenefitLedgerEntry) bool {
+		return len(entries) == 1 && entries[0].ID == 0 && entries[0].PayrollID == 40 &&
+			entries[0].Benefit == domain.BenefitPrima && entries[0].Amount == 1250000
+	}))
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Posición del salario respecto a la banda del cargo
+const (
+	BandStatusBelow  = "below"
+	BandStatusWithin = "within"
+	BandStatusAbove  = "above"
+	BandStatusNoBand = "no_band"
+)
+
+// CompensationService genera los reportes de compensación frente a las bandas salariales
+type CompensationService struct {
+	employeeRepo   domain.EmployeeRepo
+	positionRepo   domain.PositionRepo
+	departmentRepo domain.DepartmentRepo
+}
+
+func NewCompensationService(
+	employeeRepo domain.EmployeeRepo,
+	positionRepo domain.PositionRepo,
+	departmentRepo domain.DepartmentRepo,
+) *CompensationService {
+	return &CompensationService{
+		employeeRepo:   employeeRepo,
+		positionRepo:   positionRepo,
+		departmentRepo: departmentRepo,
+	}
+}
+
+// CompaRatioRow es el compa-ratio (salario / punto medio de la banda) de un empleado
+type CompaRatioRow struct {
+	EmployeeID   uint    `json:"employee_id"`
+	EmployeeName string  `json:"employee_name"`
+	PositionID   uint    `json:"position_id"`
+	PositionName string  `json:"position_name"`
+	BaseSalary   float64 `json:"base_salary"`
+	Currency     string  `json:"currency"`
+	SalaryMin    float64 `json:"salary_min"`
+	SalaryMid    float64 `json:"salary_mid"`
+	SalaryMax    float64 `json:"salary_max"`
+	CompaRatio   float64 `json:"compa_ratio"`
+	BandStatus   string  `json:"band_status"`
+}
+
+// DepartmentCompaRatio agrupa los compa-ratios de un departamento. El promedio solo
+// considera empleados con banda en la misma moneda.
+type DepartmentCompaRatio struct {
+	DepartmentID      uint            `json:"department_id"`
+	DepartmentName    string          `json:"department_name"`
+	Employees         []CompaRatioRow `json:"employees"`
+	AverageCompaRatio float64         `json:"average_compa_ratio"`
+	BelowBand         int             `json:"below_band"`
+}
+
+// CompaRatioReport lista el compa-ratio de los empleados activos por departamento, del más
+// bajo al más alto. departmentID 0 incluye todos los departamentos.
+func (s *CompensationService) CompaRatioReport(ctx context.Context, departmentID uint) ([]DepartmentCompaRatio, error) {
+	positions, err := s.positionRepo.List(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	positionByID := make(map[uint]*domain.Position, len(positions))
+	for i := range positions {
+		positionByID[positions[i].ID] = &positions[i]
+	}
+	departments, err := s.departmentRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	departmentNames := make(map[uint]string, len(departments))
+	for _, d := range departments {
+		departmentNames[d.ID] = d.Name
+	}
+
+	employees, err := allActiveEmployees(ctx, s.employeeRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[uint]*DepartmentCompaRatio)
+	var order []uint
+	for _, emp := range employees {
+		if departmentID != 0 && emp.DepartmentID != departmentID {
+			continue
+		}
+		if len(emp.Contracts) == 0 {
+			continue
+		}
+		row := compaRatioRow(&emp, &emp.Contracts[0], positionByID[emp.PositionID])
+
+		group, ok := groups[emp.DepartmentID]
+		if !ok {
+			group = &DepartmentCompaRatio{DepartmentID: emp.DepartmentID, DepartmentName: departmentNames[emp.DepartmentID]}
+			groups[emp.DepartmentID] = group
+			order = append(order, emp.DepartmentID)
+		}
+		group.Employees = append(group.Employees, row)
+	}
+
+	report := make([]DepartmentCompaRatio, 0, len(order))
+	for _, id := range order {
+		group := groups[id]
+		sort.SliceStable(group.Employees, func(i, j int) bool {
+			return group.Employees[i].CompaRatio < group.Employees[j].CompaRatio
+		})
+		var total float64
+		var counted int
+		for _, row := range group.Employees {
+			if row.BandStatus == BandStatusNoBand {
+				continue
+			}
+			total += row.CompaRatio
+			counted++
+			if row.BandStatus == BandStatusBelow {
+				group.BelowBand++
+			}
+		}
+		if counted > 0 {
+			group.AverageCompaRatio = roundRatio(total / float64(counted))
+		}
+		report = append(report, *group)
+	}
+	sort.SliceStable(report, func(i, j int) bool { return report[i].DepartmentName < report[j].DepartmentName })
+	return report, nil
+}
+
+func compaRatioRow(emp *domain.Employee, contract *domain.EmployeeContract, position *domain.Position) CompaRatioRow {
+	row := CompaRatioRow{
+		EmployeeID:   emp.ID,
+		EmployeeName: strings.TrimSpace(emp.User.FirstName + " " + emp.User.LastName),
+		PositionID:   emp.PositionID,
+		BaseSalary:   contract.BaseSalary,
+		Currency:     contract.Currency,
+		BandStatus:   BandStatusNoBand,
+	}
+	if position == nil {
+		return row
+	}
+	row.PositionName = position.NamePosition
+	if !hasSalaryBand(position) || position.SalaryMid <= 0 ||
+		(position.SalaryCurrency != "" && !strings.EqualFold(contract.Currency, position.SalaryCurrency)) {
+		return row
+	}
+	row.SalaryMin = position.SalaryMin
+	row.SalaryMid = position.SalaryMid
+	row.SalaryMax = position.SalaryMax
+	row.CompaRatio = roundRatio(contract.BaseSalary / position.SalaryMid)
+	switch {
+	case contract.BaseSalary < position.SalaryMin:
+		row.BandStatus = BandStatusBelow
+	case contract.BaseSalary > position.SalaryMax:
+		row.BandStatus = BandStatusAbove
+	default:
+		row.BandStatus = BandStatusWithin
+	}
+	return row
+}
+
+// roundRatio redondea un índice a cuatro decimales
+func roundRatio(v float64) float64 {
+	return roundCents(v*100) / 100
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompensationService_CompaRatioReport(t *testing.T) {
+	ctx := context.Background()
+	employeeRepo := new(MockEmployeeRepo)
+	positionRepo := new(MockPositionRepo)
+	departmentRepo := new(MockDepartmentRepo)
+	svc := NewCompensationService(employeeRepo, positionRepo, departmentRepo)
+
+	positionRepo.On("List", ctx, uint(0)).Return([]domain.Position{
+		{ID: 1, NamePosition: "Analista", SalaryMin: 3000000, SalaryMid: 4000000, SalaryMax: 5000000, SalaryCurrency: "COP"},
+		{ID: 2, NamePosition: "Auxiliar"},
+	}, nil)
+	departmentRepo.On("List", ctx).Return([]domain.Department{{ID: 1, Name: "Finanzas"}, {ID: 2, Name: "Bodega"}}, nil)
+	employeeRepo.On("ListActive", ctx, 1, 1000).Return([]domain.Employee{
+		{ID: 1, DepartmentID: 1, PositionID: 1, User: domain.User{FirstName: "Ana", LastName: "Gil"},
+			Contracts: []domain.EmployeeContract{{BaseSalary: 4400000, Currency: "COP"}}},
+		{ID: 2, DepartmentID: 1, PositionID: 1,
+			Contracts: []domain.EmployeeContract{{BaseSalary: 2800000, Currency: "COP"}}},
+		{ID: 3, DepartmentID: 2, PositionID: 2,
+			Contracts: []domain.EmployeeContract{{BaseSalary: 1500000, Currency: "COP"}}},
+	}, int64(3), nil)
+
+	report, err := svc.CompaRatioReport(ctx, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, report, 2)
+	assert.Equal(t, "Bodega", report[0].DepartmentName)
+	assert.Equal(t, BandStatusNoBand, report[0].Employees[0].BandStatus)
+
+	finance := report[1]
+	assert.Equal(t, uint(2), finance.Employees[0].EmployeeID)
+	assert.Equal(t, 0.7, finance.Employees[0].CompaRatio)
+	assert.Equal(t, BandStatusBelow, finance.Employees[0].BandStatus)
+	assert.Equal(t, 1.1, finance.Employees[1].CompaRatio)
+	assert.Equal(t, "Ana Gil", finance.Employees[1].EmployeeName)
+	assert.Equal(t, 0.9, finance.AverageCompaRatio)
+	assert.Equal(t, 1, finance.BelowBand)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// actorFromCtx obtiene el usuario que ejecuta la operación (0 si no viene en el contexto)
+func actorFromCtx(ctx context.Context) uint {
+	userID, ok := ctx.Value(domain.UserIDKey).(uint)
+	if !ok {
+		return 0
+	}
+	return userID
+}
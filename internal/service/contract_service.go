@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Permiso requerido para pactar un salario fuera de la banda del cargo
+const (
+	ContractPermissionResource     = "contracts"
+	ContractPermissionBandOverride = "salary_band_override"
+)
+
+// ContractService gestiona el ciclo de vida de los contratos de un empleado garantizando
+// un único contrato activo y fechas sin solapamiento
+type ContractService struct {
+	txManager     domain.TxManager
+	contractRepo  domain.EmployeeContractRepo
+	employeeRepo  domain.EmployeeRepo
+	typeRepo      domain.ContractTypeRepo
+	positionRepo  domain.PositionRepo
+	periodRepo    domain.AccountingPeriodRepo
+	permissionSvc *PermissionService
+	retroSvc      *PayrollRetroService
+}
+
+func NewContractService(
+	txManager domain.TxManager,
+	contractRepo domain.EmployeeContractRepo,
+	employeeRepo domain.EmployeeRepo,
+	typeRepo domain.ContractTypeRepo,
+	positionRepo domain.PositionRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	permissionSvc *PermissionService,
+	retroSvc *PayrollRetroService,
+) *ContractService {
+	return &ContractService{
+		txManager:     txManager,
+		contractRepo:  contractRepo,
+		employeeRepo:  employeeRepo,
+		typeRepo:      typeRepo,
+		positionRepo:  positionRepo,
+		periodRepo:    periodRepo,
+		permissionSvc: permissionSvc,
+		retroSvc:      retroSvc,
+	}
+}
+
+// ContractTerms son los términos modificables de un contrato; los campos nil se conservan
+type ContractTerms struct {
+	ContractTypeID      *uint
+	BaseSalary          *float64
+	Currency            *string
+	EndDate             *time.Time
+	WorkHoursPerDay     *float64
+	WorkDaysPerWeek     *float64
+	HealthContribution  *float64
+	PensionContribution *float64
+	TransportAllowance  *float64
+	HousingAllowance    *float64
+	SocialSecurityProof *string
+	// BandOverrideReason justifica un salario fuera de la banda del cargo
+	BandOverrideReason *string
+}
+
+// ContractRenewalAlert es un contrato cuyo tipo genera alertas y que vence pronto
+type ContractRenewalAlert struct {
+	Contract domain.EmployeeContract
+	DaysLeft int
+}
+
+// Create registra un nuevo contrato y lo activa, cerrando el contrato activo anterior
+// el día previo al inicio del nuevo
+func (s *ContractService) Create(ctx context.Context, employeeID uint, contract *domain.EmployeeContract) error {
+	if contract == nil {
+		return errors.New("contract cannot be nil")
+	}
+	if contract.StartDate.IsZero() {
+		return errors.New("contract start date is required")
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, contract.StartDate, contract.StartDate); err != nil {
+		return err
+	}
+	contract.EmployeeID = employeeID
+	return s.activate(ctx, contract, nil)
+}
+
+// Amend cambia los términos del contrato activo desde effectiveDate (hoy si no se indica). Si
+// la fecha coincide con el inicio del contrato se corrige en sitio; si no, se crea una nueva
+// versión y la anterior se cierra. Las fechas pasadas se permiten mientras su periodo contable
+// siga abierto: el motor retroactivo liquida las diferencias de los periodos ya pagados en la
+// siguiente nómina abierta.
+func (s *ContractService) Amend(
+	ctx context.Context,
+	employeeID, contractID uint,
+	effectiveDate time.Time,
+	terms ContractTerms,
+) (*domain.EmployeeContract, error) {
+	current, err := s.activeContract(ctx, employeeID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveDate.IsZero() {
+		now := time.Now()
+		effectiveDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		// Un contrato que aún no inicia se corrige en sitio
+		if effectiveDate.Before(current.StartDate) {
+			effectiveDate = current.StartDate
+		}
+	}
+	if effectiveDate.Before(current.StartDate) {
+		return nil, domain.ErrContractOverlap
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, effectiveDate, effectiveDate); err != nil {
+		return nil, err
+	}
+
+	var amended *domain.EmployeeContract
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.lockActive(ctx, current); err != nil {
+			return err
+		}
+		if effectiveDate.Equal(current.StartDate) {
+			applyContractTerms(current, terms)
+			if err := validateContractDates(current); err != nil {
+				return err
+			}
+			if err := s.checkContractType(ctx, current, current.ContractTypeID); err != nil {
+				return err
+			}
+			employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+			if err != nil {
+				return err
+			}
+			if err := s.checkSalaryBand(ctx, current, employee.PositionID); err != nil {
+				return err
+			}
+			amended = current
+			if err := s.contractRepo.Update(ctx, current); err != nil {
+				return err
+			}
+		} else {
+			next := copyContract(current)
+			next.StartDate = effectiveDate
+			applyContractTerms(next, terms)
+			if err := s.activate(ctx, next, current); err != nil {
+				return err
+			}
+			amended = next
+		}
+
+		if effectiveDate.Before(time.Now()) && s.retroSvc != nil {
+			if _, err := s.retroSvc.Run(ctx, employeeID, effectiveDate, "contract amendment"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return amended, nil
+}
+
+// Renew prorroga un contrato a término fijo: crea un nuevo contrato desde el día siguiente
+// al vencimiento hasta newEndDate con los mismos términos (o los indicados)
+func (s *ContractService) Renew(
+	ctx context.Context,
+	employeeID, contractID uint,
+	newEndDate time.Time,
+	terms ContractTerms,
+) (*domain.EmployeeContract, error) {
+	current, err := s.activeContract(ctx, employeeID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if current.EndDate == nil {
+		return nil, domain.ErrContractNotRenewable
+	}
+
+	next := copyContract(current)
+	next.StartDate = current.EndDate.AddDate(0, 0, 1)
+	applyContractTerms(next, terms)
+	end := newEndDate
+	next.EndDate = &end
+
+	if err := ensurePeriodOpen(ctx, s.periodRepo, next.StartDate, next.StartDate); err != nil {
+		return nil, err
+	}
+	if err := s.activate(ctx, next, current); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Timeline retorna todos los contratos del empleado en orden cronológico
+func (s *ContractService) Timeline(ctx context.Context, employeeID uint) ([]domain.EmployeeContract, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	contracts, err := s.contractRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(contracts, func(i, j int) bool {
+		return contracts[i].StartDate.Before(contracts[j].StartDate)
+	})
+	return contracts, nil
+}
+
+// RenewalAlerts retorna los contratos activos que vencen dentro de los días de alerta de su
+// tipo, incluidos los ya vencidos sin renovar, ordenados por fecha de vencimiento
+func (s *ContractService) RenewalAlerts(ctx context.Context, asOf time.Time) ([]ContractRenewalAlert, error) {
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	types, err := s.typeRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alertDays := make(map[uint]int)
+	maxDays := 0
+	for _, t := range types {
+		if t.RenewalAlertDays <= 0 {
+			continue
+		}
+		alertDays[t.ID] = t.RenewalAlertDays
+		if t.RenewalAlertDays > maxDays {
+			maxDays = t.RenewalAlertDays
+		}
+	}
+	alerts := []ContractRenewalAlert{}
+	if maxDays == 0 {
+		return alerts, nil
+	}
+
+	contracts, err := s.contractRepo.ListActiveEndingBy(ctx, asOf.AddDate(0, 0, maxDays))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range contracts {
+		days, ok := alertDays[c.ContractTypeID]
+		if !ok || c.EndDate == nil {
+			continue
+		}
+		left := int(c.EndDate.Sub(asOf).Hours() / 24)
+		if left > days {
+			continue
+		}
+		alerts = append(alerts, ContractRenewalAlert{Contract: c, DaysLeft: left})
+	}
+	return alerts, nil
+}
+
+// activate valida fechas y crea el contrato como activo. El contrato activo anterior (o
+// previous, que debe seguir activo) se cierra el día previo al inicio, todo en una transacción.
+func (s *ContractService) activate(ctx context.Context, contract *domain.EmployeeContract, previous *domain.EmployeeContract) error {
+	if err := validateContractDates(contract); err != nil {
+		return err
+	}
+	previousTypeID := uint(0)
+	if previous != nil {
+		previousTypeID = previous.ContractTypeID
+	}
+	if err := s.checkContractType(ctx, contract, previousTypeID); err != nil {
+		return err
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, contract.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if !employee.IsActive {
+		return domain.ErrEmployeeInactive
+	}
+	if err := s.checkSalaryBand(ctx, contract, employee.PositionID); err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// El contrato activo se lee bloqueado dentro de la transacción: dos activaciones
+		// simultáneas se serializan y la segunda ve el contrato que dejó la primera
+		active, err := s.contractRepo.LockActiveByEmployee(ctx, contract.EmployeeID)
+		if err != nil && !errors.Is(err, domain.ErrEmployeeContractNotFound) {
+			return err
+		}
+		if previous == nil {
+			previous = active
+		} else if active == nil || active.ID != previous.ID {
+			return domain.ErrContractNotActive
+		}
+
+		contracts, err := s.contractRepo.ListByEmployee(ctx, contract.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if previous != nil {
+			if !contract.StartDate.After(previous.StartDate) {
+				return domain.ErrContractOverlap
+			}
+			closing := contract.StartDate.AddDate(0, 0, -1)
+			if previous.EndDate == nil || previous.EndDate.After(closing) {
+				previous.EndDate = &closing
+			}
+		}
+		for i := range contracts {
+			if previous != nil && contracts[i].ID == previous.ID {
+				continue
+			}
+			if contractsOverlap(&contracts[i], contract) {
+				return domain.ErrContractOverlap
+			}
+		}
+
+		if previous != nil {
+			previous.IsActive = false
+			if err := s.contractRepo.Update(ctx, previous); err != nil {
+				return err
+			}
+		}
+		contract.ID = 0
+		contract.IsActive = true
+		return s.contractRepo.Create(ctx, contract)
+	})
+}
+
+// lockActive bloquea el contrato activo del empleado y verifica que siga siendo current
+func (s *ContractService) lockActive(ctx context.Context, current *domain.EmployeeContract) error {
+	active, err := s.contractRepo.LockActiveByEmployee(ctx, current.EmployeeID)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmployeeContractNotFound) {
+			return domain.ErrContractNotActive
+		}
+		return err
+	}
+	if active.ID != current.ID {
+		return domain.ErrContractNotActive
+	}
+	return nil
+}
+
+// activeContract obtiene el contrato activo del empleado y verifica que sea contractID
+func (s *ContractService) activeContract(ctx context.Context, employeeID, contractID uint) (*domain.EmployeeContract, error) {
+	if employeeID == 0 || contractID == 0 {
+		return nil, errors.New("invalid employee or contract id")
+	}
+	current, err := s.contractRepo.GetByID(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if current.EmployeeID != employeeID {
+		return nil, domain.ErrEmployeeContractNotFound
+	}
+	if !current.IsActive {
+		return nil, domain.ErrContractNotActive
+	}
+	return current, nil
+}
+
+// checkContractType valida el contrato contra las reglas de su tipo. Un tipo inactivo solo
+// se rechaza cuando se asigna; las versiones de un contrato que ya lo usaba lo conservan.
+func (s *ContractService) checkContractType(ctx context.Context, contract *domain.EmployeeContract, currentTypeID uint) error {
+	if contract.ContractTypeID == 0 {
+		return nil
+	}
+	contractType, err := s.typeRepo.GetByID(ctx, contract.ContractTypeID)
+	if err != nil {
+		return err
+	}
+	if !contractType.IsActive && contract.ContractTypeID != currentTypeID {
+		return domain.ErrContractTypeInactive
+	}
+	if contractType.RequiresEndDate && contract.EndDate == nil {
+		return domain.ErrContractEndDateRequired
+	}
+	if contractType.RequiresSocialSecurityProof && strings.TrimSpace(contract.SocialSecurityProof) == "" {
+		return domain.ErrSocialSecurityProofRequired
+	}
+	return nil
+}
+
+// checkSalaryBand valida el salario contra la banda del cargo del empleado. Fuera de la banda
+// se exige una justificación y que el actor tenga el permiso contracts.salary_band_override.
+func (s *ContractService) checkSalaryBand(ctx context.Context, contract *domain.EmployeeContract, positionID uint) error {
+	contract.BandOverrideReason = strings.TrimSpace(contract.BandOverrideReason)
+	if positionID == 0 {
+		return nil
+	}
+	position, err := s.positionRepo.GetByID(ctx, positionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !hasSalaryBand(position) || salaryInBand(position, contract.BaseSalary, contract.Currency) {
+		contract.BandOverrideReason = ""
+		contract.BandOverrideBy = 0
+		return nil
+	}
+
+	if contract.BandOverrideReason == "" {
+		return fmt.Errorf("%w: %.2f %s not in %.2f-%.2f %s", domain.ErrSalaryOutOfBand,
+			contract.BaseSalary, contract.Currency, position.SalaryMin, position.SalaryMax, position.SalaryCurrency)
+	}
+	actor := actorFromCtx(ctx)
+	if actor == 0 {
+		return domain.ErrActorRequired
+	}
+	allowed, err := s.permissionSvc.UserHasPermission(ctx, actor, ContractPermissionResource, ContractPermissionBandOverride)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.ErrPermissionDenied
+	}
+	contract.BandOverrideBy = actor
+	return nil
+}
+
+func validateContractDates(contract *domain.EmployeeContract) error {
+	if contract.EndDate != nil && contract.EndDate.Before(contract.StartDate) {
+		return domain.ErrInvalidContractDates
+	}
+	return nil
+}
+
+// contractsOverlap indica si dos contratos comparten algún día; un EndDate nil es indefinido
+func contractsOverlap(a, b *domain.EmployeeContract) bool {
+	if a.EndDate != nil && a.EndDate.Before(b.StartDate) {
+		return false
+	}
+	if b.EndDate != nil && b.EndDate.Before(a.StartDate) {
+		return false
+	}
+	return true
+}
+
+// copyContract crea una nueva versión del contrato con los mismos términos
+func copyContract(c *domain.EmployeeContract) *domain.EmployeeContract {
+	next := &domain.EmployeeContract{
+		EmployeeID:          c.EmployeeID,
+		ContractTypeID:      c.ContractTypeID,
+		BaseSalary:          c.BaseSalary,
+		Currency:            c.Currency,
+		WorkHoursPerDay:     c.WorkHoursPerDay,
+		WorkDaysPerWeek:     c.WorkDaysPerWeek,
+		HealthContribution:  c.HealthContribution,
+		PensionContribution: c.PensionContribution,
+		TransportAllowance:  c.TransportAllowance,
+		HousingAllowance:    c.HousingAllowance,
+		SocialSecurityProof: c.SocialSecurityProof,
+	}
+	if c.EndDate != nil {
+		end := *c.EndDate
+		next.EndDate = &end
+	}
+	return next
+}
+
+func applyContractTerms(c *domain.EmployeeContract, terms ContractTerms) {
+	if terms.ContractTypeID != nil {
+		c.ContractTypeID = *terms.ContractTypeID
+	}
+	if terms.BaseSalary != nil {
+		c.BaseSalary = *terms.BaseSalary
+	}
+	if terms.Currency != nil {
+		c.Currency = *terms.Currency
+	}
+	if terms.EndDate != nil {
+		end := *terms.EndDate
+		c.EndDate = &end
+	}
+	if terms.WorkHoursPerDay != nil {
+		c.WorkHoursPerDay = *terms.WorkHoursPerDay
+	}
+	if terms.WorkDaysPerWeek != nil {
+		c.WorkDaysPerWeek = *terms.WorkDaysPerWeek
+	}
+	if terms.HealthContribution != nil {
+		c.HealthContribution = *terms.HealthContribution
+	}
+	if terms.PensionContribution != nil {
+		c.PensionContribution = *terms.PensionContribution
+	}
+	if terms.TransportAllowance != nil {
+		c.TransportAllowance = *terms.TransportAllowance
+	}
+	if terms.HousingAllowance != nil {
+		c.HousingAllowance = *terms.HousingAllowance
+	}
+	if terms.SocialSecurityProof != nil {
+		c.SocialSecurityProof = *terms.SocialSecurityProof
+	}
+	if terms.BandOverrideReason != nil {
+		c.BandOverrideReason = *terms.BandOverrideReason
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockContractTypeRepo struct {
+	mock.Mock
+}
+
+func (m *MockContractTypeRepo) Create(ctx context.Context, contractType *domain.ContractType) error {
+	args := m.Called(ctx, contractType)
+	return args.Error(0)
+}
+
+func (m *MockContractTypeRepo) GetByID(ctx context.Context, id uint) (*domain.ContractType, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContractType), args.Error(1)
+}
+
+func (m *MockContractTypeRepo) GetByCode(ctx context.Context, code string) (*domain.ContractType, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContractType), args.Error(1)
+}
+
+func (m *MockContractTypeRepo) List(ctx context.Context) ([]domain.ContractType, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.ContractType), args.Error(1)
+}
+
+func (m *MockContractTypeRepo) Update(ctx context.Context, contractType *domain.ContractType) error {
+	args := m.Called(ctx, contractType)
+	return args.Error(0)
+}
+
+func (m *MockContractTypeRepo) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type contractMocks struct {
+	contractRepo *MockContractRepo
+	employeeRepo *MockEmployeeRepo
+	typeRepo     *MockContractTypeRepo
+	positionRepo *MockPositionRepo
+	userRoleRepo *MockUserRoleRepo
+}
+
+func newContractService() (*ContractService, *contractMocks) {
+	m := &contractMocks{
+		contractRepo: new(MockContractRepo),
+		employeeRepo: new(MockEmployeeRepo),
+		typeRepo:     new(MockContractTypeRepo),
+		positionRepo: new(MockPositionRepo),
+		userRoleRepo: new(MockUserRoleRepo),
+	}
+	permissionSvc := NewPermissionService(m.userRoleRepo, mocks.NewMockRoleRepo())
+	svc := NewContractService(&MockTxManager{}, m.contractRepo, m.employeeRepo, m.typeRepo, m.positionRepo,
+		newOpenPeriodRepo(), permissionSvc, nil)
+	return svc, m
+}
+
+func TestContractService_Create_ClosesPreviousContract(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	previous := domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 2000000, IsActive: true,
+		StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(&previous, nil)
+	m.contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{previous}, nil)
+	m.contractRepo.On("Update", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+	m.contractRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+
+	contract := &domain.EmployeeContract{BaseSalary: 2500000, StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	err := svc.Create(ctx, 1, contract)
+
+	assert.NoError(t, err)
+	assert.True(t, contract.IsActive)
+	assert.Equal(t, uint(1), contract.EmployeeID)
+	m.contractRepo.AssertCalled(t, "Update", ctx, mock.MatchedBy(func(c *domain.EmployeeContract) bool {
+		return c.ID == 1 && !c.IsActive && c.EndDate != nil &&
+			c.EndDate.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	}))
+}
+
+func TestContractService_Create_RejectsOverlap(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	closedEnd := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(nil, domain.ErrEmployeeContractNotFound)
+	m.contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{ID: 1, EmployeeID: 1, StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &closedEnd},
+	}, nil)
+
+	err := svc.Create(ctx, 1, &domain.EmployeeContract{StartDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)})
+
+	assert.ErrorIs(t, err, domain.ErrContractOverlap)
+	m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestContractService_Renew_RequiresFixedTerm(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	m.contractRepo.On("GetByID", ctx, uint(3)).Return(&domain.EmployeeContract{ID: 3, EmployeeID: 1, IsActive: true,
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
+
+	_, err := svc.Renew(ctx, 1, 3, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), ContractTerms{})
+
+	assert.ErrorIs(t, err, domain.ErrContractNotRenewable)
+}
+
+func TestContractService_Renew_StartsAfterEndDate(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	current := &domain.EmployeeContract{ID: 3, EmployeeID: 1, BaseSalary: 2000000, IsActive: true,
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &end}
+	m.contractRepo.On("GetByID", ctx, uint(3)).Return(current, nil)
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(current, nil)
+	m.contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{*current}, nil)
+	m.contractRepo.On("Update", ctx, current).Return(nil)
+	m.contractRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+
+	renewed, err := svc.Renew(ctx, 1, 3, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), ContractTerms{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), renewed.StartDate)
+	assert.Equal(t, float64(2000000), renewed.BaseSalary)
+	assert.False(t, current.IsActive)
+	assert.Equal(t, end, *current.EndDate)
+}
+
+func TestContractService_Amend_RejectsClosedPeriod(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+	periodRepo := new(MockAccountingPeriodRepo)
+	periodRepo.On("FindOverlapping", ctx, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{
+		{ID: 1, Status: domain.PeriodStatusClosed,
+			PeriodStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	svc.periodRepo = periodRepo
+
+	current := &domain.EmployeeContract{ID: 3, EmployeeID: 1, BaseSalary: 2000000, IsActive: true,
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.contractRepo.On("GetByID", ctx, uint(3)).Return(current, nil)
+
+	salary := 2500000.0
+	_, err := svc.Amend(ctx, 1, 3, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), ContractTerms{BaseSalary: &salary})
+
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+	m.contractRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestContractService_Amend_DefaultsToTodayAsNewVersion(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	current := &domain.EmployeeContract{ID: 3, EmployeeID: 1, BaseSalary: 2000000, IsActive: true,
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.contractRepo.On("GetByID", ctx, uint(3)).Return(current, nil)
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(current, nil)
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	m.contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{*current}, nil)
+	m.contractRepo.On("Update", ctx, current).Return(nil)
+	m.contractRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+
+	salary := 2500000.0
+	amended, err := svc.Amend(ctx, 1, 3, time.Time{}, ContractTerms{BaseSalary: &salary})
+
+	assert.NoError(t, err)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	assert.NotSame(t, current, amended)
+	assert.Equal(t, today, amended.StartDate)
+	assert.Equal(t, float64(2500000), amended.BaseSalary)
+	assert.False(t, current.IsActive)
+	assert.Equal(t, float64(2000000), current.BaseSalary)
+	assert.Equal(t, today.AddDate(0, 0, -1), *current.EndDate)
+}
+
+func TestContractService_Amend_RejectsSupersededContract(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	current := &domain.EmployeeContract{ID: 3, EmployeeID: 1, BaseSalary: 2000000, IsActive: true,
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.contractRepo.On("GetByID", ctx, uint(3)).Return(current, nil)
+	// Otra petición activó una nueva versión entre la lectura y el bloqueo
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 4, EmployeeID: 1, IsActive: true}, nil)
+
+	salary := 2500000.0
+	_, err := svc.Amend(ctx, 1, 3, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ContractTerms{BaseSalary: &salary})
+
+	assert.ErrorIs(t, err, domain.ErrContractNotActive)
+	m.contractRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestContractService_Create_FixedTermRequiresEndDate(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	m.typeRepo.On("GetByID", ctx, uint(2)).Return(&domain.ContractType{ID: 2, Code: domain.ContractTypeFixedTerm,
+		RequiresEndDate: true, RenewalAlertDays: 30, IsActive: true}, nil)
+
+	err := svc.Create(ctx, 1, &domain.EmployeeContract{ContractTypeID: 2, StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)})
+
+	assert.ErrorIs(t, err, domain.ErrContractEndDateRequired)
+	m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestContractService_RenewalAlerts(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newContractService()
+
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	soon := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC)
+	m.typeRepo.On("List", ctx).Return([]domain.ContractType{
+		{ID: 1, Code: domain.ContractTypeIndefinite},
+		{ID: 2, Code: domain.ContractTypeFixedTerm, RequiresEndDate: true, RenewalAlertDays: 30},
+		{ID: 3, Code: "PROJECT", RequiresEndDate: true, RenewalAlertDays: 90},
+	}, nil)
+	m.contractRepo.On("ListActiveEndingBy", ctx, asOf.AddDate(0, 0, 90)).Return([]domain.EmployeeContract{
+		{ID: 10, EmployeeID: 1, ContractTypeID: 2, EndDate: &soon},
+		{ID: 11, EmployeeID: 2, ContractTypeID: 2, EndDate: &later},
+		{ID: 12, EmployeeID: 3, ContractTypeID: 3, EndDate: &later},
+	}, nil)
+
+	alerts, err := svc.RenewalAlerts(ctx, asOf)
+
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, uint(10), alerts[0].Contract.ID)
+	assert.Equal(t, 19, alerts[0].DaysLeft)
+	assert.Equal(t, uint(12), alerts[1].Contract.ID)
+}
+
+func TestContractService_Create_SalaryBand(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	band := &domain.Position{ID: 4, SalaryMin: 3000000, SalaryMid: 3500000, SalaryMax: 4000000, SalaryCurrency: "COP"}
+
+	setup := func(ctx context.Context) (*ContractService, *contractMocks) {
+		svc, m := newContractService()
+		m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, PositionID: 4, IsActive: true}, nil)
+		m.positionRepo.On("GetByID", ctx, uint(4)).Return(band, nil)
+		m.contractRepo.On("LockActiveByEmployee", ctx, uint(1)).Return(nil, domain.ErrEmployeeContractNotFound)
+		m.contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{}, nil)
+		m.contractRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+		return svc, m
+	}
+
+	t.Run("out of band without justification", func(t *testing.T) {
+		ctx := withActor(context.Background(), 7)
+		svc, m := setup(ctx)
+
+		err := svc.Create(ctx, 1, &domain.EmployeeContract{BaseSalary: 5000000, Currency: "COP", StartDate: start})
+
+		assert.ErrorIs(t, err, domain.ErrSalaryOutOfBand)
+		m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("justification without override permission", func(t *testing.T) {
+		ctx := withActor(context.Background(), 7)
+		svc, m := setup(ctx)
+		m.userRoleRepo.On("GetUserRoles", ctx, uint(7)).Return([]domain.Role{roleWithPermission("contracts", "read")}, nil)
+
+		err := svc.Create(ctx, 1, &domain.EmployeeContract{BaseSalary: 5000000, Currency: "COP", StartDate: start,
+			BandOverrideReason: "retención de talento"})
+
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+		m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("override with permission", func(t *testing.T) {
+		ctx := withActor(context.Background(), 7)
+		svc, m := setup(ctx)
+		m.userRoleRepo.On("GetUserRoles", ctx, uint(7)).Return([]domain.Role{
+			roleWithPermission(ContractPermissionResource, ContractPermissionBandOverride),
+		}, nil)
+
+		contract := &domain.EmployeeContract{BaseSalary: 5000000, Currency: "COP", StartDate: start,
+			BandOverrideReason: "retención de talento"}
+		err := svc.Create(ctx, 1, contract)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), contract.BandOverrideBy)
+	})
+
+	t.Run("within band clears override", func(t *testing.T) {
+		ctx := context.Background()
+		svc, _ := setup(ctx)
+
+		contract := &domain.EmployeeContract{BaseSalary: 3200000, Currency: "COP", StartDate: start,
+			BandOverrideReason: "no aplica", BandOverrideBy: 9}
+		err := svc.Create(ctx, 1, contract)
+
+		assert.NoError(t, err)
+		assert.Empty(t, contract.BandOverrideReason)
+		assert.Zero(t, contract.BandOverrideBy)
+	})
+}
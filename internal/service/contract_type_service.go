@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ContractTypeService administra los tipos de contrato del tenant y sus reglas
+type ContractTypeService struct {
+	contractTypeRepo domain.ContractTypeRepo
+}
+
+func NewContractTypeService(repo domain.ContractTypeRepo) *ContractTypeService {
+	return &ContractTypeService{
+		contractTypeRepo: repo,
+	}
+}
+
+func (s *ContractTypeService) Create(ctx context.Context, contractType *domain.ContractType) error {
+	if contractType == nil {
+		return errors.New("contract type cannot be nil")
+	}
+	contractType.Code = strings.ToUpper(strings.TrimSpace(contractType.Code))
+	if err := validateContractType(contractType); err != nil {
+		return err
+	}
+	return s.contractTypeRepo.Create(ctx, contractType)
+}
+
+func (s *ContractTypeService) GetByID(ctx context.Context, id uint) (*domain.ContractType, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	return s.contractTypeRepo.GetByID(ctx, id)
+}
+
+func (s *ContractTypeService) List(ctx context.Context) ([]domain.ContractType, error) {
+	return s.contractTypeRepo.List(ctx)
+}
+
+func (s *ContractTypeService) Update(ctx context.Context, contractType *domain.ContractType) error {
+	if contractType == nil {
+		return errors.New("contract type cannot be nil")
+	}
+	if contractType.ID == 0 {
+		return errors.New("invalid contract type id")
+	}
+	if err := validateContractType(contractType); err != nil {
+		return err
+	}
+	return s.contractTypeRepo.Update(ctx, contractType)
+}
+
+func (s *ContractTypeService) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid id")
+	}
+	return s.contractTypeRepo.Delete(ctx, id)
+}
+
+// SeedDefaultContractTypes crea los tipos de contrato base que aún no existan en el tenant
+func (s *ContractTypeService) SeedDefaultContractTypes(ctx context.Context) error {
+	for _, contractType := range domain.DefaultContractTypes() {
+		existing, err := s.contractTypeRepo.GetByCode(ctx, contractType.Code)
+		if err == nil && existing != nil {
+			continue
+		}
+		if err := s.contractTypeRepo.Create(ctx, &contractType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateContractType(contractType *domain.ContractType) error {
+	if contractType.Code == "" || strings.TrimSpace(contractType.Name) == "" {
+		return errors.New("contract type code and name are required")
+	}
+	if contractType.StipendPercentage < 0 || contractType.StipendPercentage > 100 {
+		return errors.New("stipend percentage must be between 0 and 100")
+	}
+	if contractType.RenewalAlertDays < 0 {
+		return errors.New("renewal alert days cannot be negative")
+	}
+	if contractType.RenewalAlertDays > 0 && !contractType.RequiresEndDate {
+		return errors.New("renewal alerts require contracts with an end date")
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// CostCenterService administra los centros de costo, la asignación del costo de cada empleado
+// y el informe de costo de nómina por centro
+type CostCenterService struct {
+	costCenterRepo domain.CostCenterRepo
+	allocationRepo domain.CostCenterAllocationRepo
+	employeeRepo   domain.EmployeeRepo
+	registerRepo   domain.PayrollRegisterRepo
+}
+
+func NewCostCenterService(
+	costCenterRepo domain.CostCenterRepo,
+	allocationRepo domain.CostCenterAllocationRepo,
+	employeeRepo domain.EmployeeRepo,
+	registerRepo domain.PayrollRegisterRepo,
+) *CostCenterService {
+	return &CostCenterService{
+		costCenterRepo: costCenterRepo,
+		allocationRepo: allocationRepo,
+		employeeRepo:   employeeRepo,
+		registerRepo:   registerRepo,
+	}
+}
+
+func (s *CostCenterService) Create(ctx context.Context, costCenter *domain.CostCenter) error {
+	if costCenter == nil {
+		return errors.New("cost center cannot be nil")
+	}
+	costCenter.Code = strings.ToUpper(strings.TrimSpace(costCenter.Code))
+	costCenter.Name = strings.TrimSpace(costCenter.Name)
+	if costCenter.Code == "" || costCenter.Name == "" {
+		return errors.New("cost center code and name are required")
+	}
+	costCenter.IsActive = true
+	return s.costCenterRepo.Create(ctx, costCenter)
+}
+
+func (s *CostCenterService) GetByID(ctx context.Context, id uint) (*domain.CostCenter, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	return s.costCenterRepo.GetByID(ctx, id)
+}
+
+func (s *CostCenterService) List(ctx context.Context) ([]domain.CostCenter, error) {
+	return s.costCenterRepo.List(ctx)
+}
+
+// Update guarda los cambios del centro de costo. Desactivarlo no cambia las nóminas ya
+// calculadas, pero impide asignarlo en nuevas versiones.
+func (s *CostCenterService) Update(ctx context.Context, costCenter *domain.CostCenter) error {
+	if costCenter == nil || costCenter.ID == 0 {
+		return errors.New("invalid cost center id")
+	}
+	costCenter.Code = strings.ToUpper(strings.TrimSpace(costCenter.Code))
+	costCenter.Name = strings.TrimSpace(costCenter.Name)
+	if costCenter.Code == "" || costCenter.Name == "" {
+		return errors.New("cost center code and name are required")
+	}
+	return s.costCenterRepo.Update(ctx, costCenter)
+}
+
+// CostAllocationVersion son las asignaciones de un empleado que rigen desde una fecha
+type CostAllocationVersion struct {
+	EffectiveFrom time.Time
+	Allocations   []domain.CostCenterAllocation
+}
+
+// ListAllocations retorna las versiones de asignación del empleado, de la más antigua a la
+// más reciente
+func (s *CostCenterService) ListAllocations(ctx context.Context, employeeID uint) ([]CostAllocationVersion, error) {
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	allocations, err := s.allocationRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return allocationVersions(allocations), nil
+}
+
+// SetAllocation reemplaza la versión que rige desde effectiveFrom. Los porcentajes deben
+// sumar 100 entre centros de costo activos y distintos; una lista vacía elimina la versión.
+// Las nóminas ya calculadas conservan su reparto hasta que se recalculen.
+func (s *CostCenterService) SetAllocation(ctx context.Context, employeeID uint, effectiveFrom time.Time, allocations []domain.CostCenterAllocation) ([]CostAllocationVersion, error) {
+	if effectiveFrom.IsZero() {
+		return nil, domain.ErrInvalidCostAllocation
+	}
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	var total float64
+	seen := make(map[uint]bool, len(allocations))
+	for i := range allocations {
+		a := &allocations[i]
+		if a.Percentage <= 0 || a.Percentage > 100 || seen[a.CostCenterID] {
+			return nil, domain.ErrInvalidCostAllocation
+		}
+		seen[a.CostCenterID] = true
+		total += a.Percentage
+		costCenter, err := s.costCenterRepo.GetByID(ctx, a.CostCenterID)
+		if err != nil {
+			return nil, err
+		}
+		if !costCenter.IsActive {
+			return nil, domain.ErrCostCenterInactive
+		}
+		a.CreatedBy = actorFromCtx(ctx)
+	}
+	if len(allocations) > 0 && roundCents(total) != 100 {
+		return nil, domain.ErrInvalidCostAllocation
+	}
+	if err := s.allocationRepo.ReplaceVersion(ctx, employeeID, effectiveFrom, allocations); err != nil {
+		return nil, err
+	}
+	return s.ListAllocations(ctx, employeeID)
+}
+
+func allocationVersions(allocations []domain.CostCenterAllocation) []CostAllocationVersion {
+	sorted := slices.Clone(allocations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].EffectiveFrom.Before(sorted[j].EffectiveFrom) })
+	var versions []CostAllocationVersion
+	for _, a := range sorted {
+		if n := len(versions); n > 0 && versions[n-1].EffectiveFrom.Equal(a.EffectiveFrom) {
+			versions[n-1].Allocations = append(versions[n-1].Allocations, a)
+			continue
+		}
+		versions = append(versions, CostAllocationVersion{EffectiveFrom: a.EffectiveFrom, Allocations: []domain.CostCenterAllocation{a}})
+	}
+	return versions
+}
+
+// costShare es el porcentaje de un periodo que corresponde a un centro de costo
+type costShare struct {
+	CostCenterID uint
+	Code         string
+	Percentage   float64
+}
+
+// periodCostShares pondera cada versión de asignación por los días del periodo en que rige.
+// Los días anteriores a la primera versión quedan sin repartir.
+func periodCostShares(allocations []domain.CostCenterAllocation, start, end time.Time) []costShare {
+	totalDays := daysInclusive(start, end)
+	if totalDays <= 0 {
+		return nil
+	}
+	versions := allocationVersions(allocations)
+	var shares []costShare
+	index := make(map[uint]int)
+	for i, v := range versions {
+		from := laterOf(v.EffectiveFrom, start)
+		to := end
+		if i+1 < len(versions) && versions[i+1].EffectiveFrom.AddDate(0, 0, -1).Before(to) {
+			to = versions[i+1].EffectiveFrom.AddDate(0, 0, -1)
+		}
+		days := daysInclusive(from, to)
+		if days <= 0 {
+			continue
+		}
+		weight := float64(days) / float64(totalDays)
+		for _, a := range v.Allocations {
+			j, ok := index[a.CostCenterID]
+			if !ok {
+				j = len(shares)
+				index[a.CostCenterID] = j
+				shares = append(shares, costShare{CostCenterID: a.CostCenterID, Code: a.CostCenter.Code})
+			}
+			shares[j].Percentage += a.Percentage * weight
+		}
+	}
+	for i := range shares {
+		shares[i].Percentage = math.Round(shares[i].Percentage*10000) / 10000
+	}
+	return shares
+}
+
+func daysInclusive(start, end time.Time) int {
+	return int(math.Round(end.Sub(start).Hours()/24)) + 1
+}
+
+// allocateItems reparte los devengos y aportes del empleador que aún no tienen reparto. Si
+// los porcentajes suman 100 el último tramo absorbe el redondeo para cuadrar con el item.
+func allocateItems(items []domain.PayrollItem, shares []costShare) {
+	if len(shares) == 0 {
+		return
+	}
+	var total float64
+	for _, share := range shares {
+		total += share.Percentage
+	}
+	complete := roundCents(total) == 100
+	for i := range items {
+		item := &items[i]
+		if item.Type == domain.PayrollTypeDeduction || item.Amount == 0 || len(item.Allocations) > 0 {
+			continue
+		}
+		remaining := item.Amount
+		for j, share := range shares {
+			part := roundCents(item.Amount * share.Percentage / 100)
+			if complete && j == len(shares)-1 {
+				part = roundCents(remaining)
+			}
+			remaining -= part
+			item.Allocations = append(item.Allocations, domain.PayrollItemAllocation{
+				CostCenterID:   share.CostCenterID,
+				CostCenterCode: share.Code,
+				Percentage:     share.Percentage,
+				Amount:         part,
+			})
+		}
+	}
+}
+
+// CostCenterCost es el costo de nómina imputado a un centro de costo
+type CostCenterCost struct {
+	CostCenterID          uint
+	Code                  string
+	Name                  string
+	Earnings              float64
+	EmployerContributions float64
+	Total                 float64
+	Share                 float64 // porcentaje del costo total
+	Concepts              map[string]float64
+}
+
+// CostReport es el costo de nómina de un periodo repartido por centro de costo. El costo sin
+// reparto queda en un centro sin código.
+type CostReport struct {
+	Filter                domain.PayrollRegisterFilter
+	Centers               []CostCenterCost
+	Earnings              float64
+	EmployerContributions float64
+	Total                 float64
+}
+
+// Report suma por centro de costo los devengos y aportes del empleador de las nóminas del
+// filtro; sin estados se toman las nóminas contabilizadas como en el asiento
+func (s *CostCenterService) Report(ctx context.Context, filter domain.PayrollRegisterFilter) (*CostReport, error) {
+	if len(filter.PayrollIDs) == 0 &&
+		(filter.PeriodStart.IsZero() || filter.PeriodEnd.IsZero() || filter.PeriodEnd.Before(filter.PeriodStart)) {
+		return nil, domain.ErrInvalidRegisterFilter
+	}
+	if len(filter.PayrollIDs) == 0 {
+		filter.PeriodEnd = filter.PeriodEnd.Add(24*time.Hour - time.Nanosecond)
+	}
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = journalPayrollStatuses
+	}
+	costCenters, err := s.costCenterRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(costCenters))
+	for _, c := range costCenters {
+		names[c.ID] = c.Name
+	}
+
+	report := &CostReport{Filter: filter}
+	index := make(map[uint]int)
+	center := func(id uint, code string) *CostCenterCost {
+		i, ok := index[id]
+		if !ok {
+			i = len(report.Centers)
+			index[id] = i
+			name := names[id]
+			if id == 0 {
+				name = "Sin centro de costo"
+			}
+			report.Centers = append(report.Centers, CostCenterCost{CostCenterID: id, Code: code, Name: name, Concepts: map[string]float64{}})
+		}
+		return &report.Centers[i]
+	}
+	add := func(c *CostCenterCost, item domain.PayrollItem, amount float64) {
+		if item.Type == domain.PayrollTypeEarning {
+			c.Earnings += amount
+		} else {
+			c.EmployerContributions += amount
+		}
+		code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+		c.Concepts[code] = roundCents(c.Concepts[code] + amount)
+	}
+
+	payrolls := 0
+	err = s.registerRepo.Stream(ctx, filter, registerBatchSize, func(batch []domain.Payroll) error {
+		for _, p := range batch {
+			payrolls++
+			for _, item := range p.Items {
+				if item.Type == domain.PayrollTypeDeduction {
+					continue
+				}
+				remaining := item.Amount
+				for _, a := range item.Allocations {
+					add(center(a.CostCenterID, a.CostCenterCode), item, a.Amount)
+					remaining -= a.Amount
+				}
+				if remaining = roundCents(remaining); remaining != 0 {
+					add(center(0, ""), item, remaining)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payrolls == 0 {
+		return nil, domain.ErrNoPayrollsForRegister
+	}
+
+	for i := range report.Centers {
+		c := &report.Centers[i]
+		c.Earnings = roundCents(c.Earnings)
+		c.EmployerContributions = roundCents(c.EmployerContributions)
+		c.Total = roundCents(c.Earnings + c.EmployerContributions)
+		report.Earnings += c.Earnings
+		report.EmployerContributions += c.EmployerContributions
+	}
+	report.Earnings = roundCents(report.Earnings)
+	report.EmployerContributions = roundCents(report.EmployerContributions)
+	report.Total = roundCents(report.Earnings + report.EmployerContributions)
+	for i := range report.Centers {
+		if report.Total != 0 {
+			report.Centers[i].Share = roundCents(report.Centers[i].Total / report.Total * 100)
+		}
+	}
+	// Los centros sin código (costo sin reparto) van al final
+	sort.SliceStable(report.Centers, func(i, j int) bool {
+		a, b := report.Centers[i], report.Centers[j]
+		if (a.Code == "") != (b.Code == "") {
+			return b.Code == ""
+		}
+		return a.Code < b.Code
+	})
+	return report, nil
+}
+
+// CSV escribe el informe con una fila por centro de costo y una columna por concepto
+func (r *CostReport) CSV() ([]byte, error) {
+	var concepts []string
+	totals := make(map[string]float64)
+	for _, c := range r.Centers {
+		for code, v := range c.Concepts {
+			if !slices.Contains(concepts, code) {
+				concepts = append(concepts, code)
+			}
+			totals[code] = roundCents(totals[code] + v)
+		}
+	}
+	sort.Strings(concepts)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := append([]string{"cost_center", "name"}, concepts...)
+	header = append(header, "earnings", "employer_contributions", "total", "share")
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, c := range r.Centers {
+		row := []string{c.Code, c.Name}
+		for _, code := range concepts {
+			row = append(row, amount(c.Concepts[code]))
+		}
+		row = append(row, amount(c.Earnings), amount(c.EmployerContributions), amount(c.Total), amount(c.Share))
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	total := []string{"TOTAL", ""}
+	for _, code := range concepts {
+		total = append(total, amount(totals[code]))
+	}
+	total = append(total, amount(r.Earnings), amount(r.EmployerContributions), amount(r.Total), amount(100))
+	if err := w.Write(total); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
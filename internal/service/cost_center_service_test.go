@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCostCenterRepo struct {
+	mock.Mock
+}
+
+func (m *MockCostCenterRepo) Create(ctx context.Context, costCenter *domain.CostCenter) error {
+	args := m.Called(ctx, costCenter)
+	return args.Error(0)
+}
+
+func (m *MockCostCenterRepo) GetByID(ctx context.Context, id uint) (*domain.CostCenter, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CostCenter), args.Error(1)
+}
+
+func (m *MockCostCenterRepo) List(ctx context.Context) ([]domain.CostCenter, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.CostCenter), args.Error(1)
+}
+
+func (m *MockCostCenterRepo) Update(ctx context.Context, costCenter *domain.CostCenter) error {
+	args := m.Called(ctx, costCenter)
+	return args.Error(0)
+}
+
+type MockCostCenterAllocationRepo struct {
+	mock.Mock
+}
+
+func (m *MockCostCenterAllocationRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.CostCenterAllocation, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.CostCenterAllocation), args.Error(1)
+}
+
+func (m *MockCostCenterAllocationRepo) ReplaceVersion(ctx context.Context, employeeID uint, effectiveFrom time.Time, allocations []domain.CostCenterAllocation) error {
+	args := m.Called(ctx, employeeID, effectiveFrom, allocations)
+	return args.Error(0)
+}
+
+// newCostAllocationRepoMock es un repositorio sin asignaciones: el costo queda sin repartir
+func newCostAllocationRepoMock() *MockCostCenterAllocationRepo {
+	allocationRepo := new(MockCostCenterAllocationRepo)
+	allocationRepo.On("ListByEmployee", mock.Anything, mock.Anything).Return([]domain.CostCenterAllocation{}, nil)
+	return allocationRepo
+}
+
+func costAllocation(costCenterID uint, code string, percentage float64, from time.Time) domain.CostCenterAllocation {
+	return domain.CostCenterAllocation{
+		EmployeeID: 1, CostCenterID: costCenterID, Percentage: percentage, EffectiveFrom: from,
+		CostCenter: domain.CostCenter{ID: costCenterID, Code: code},
+	}
+}
+
+func TestPeriodCostShares(t *testing.T) {
+	allocations := []domain.CostCenterAllocation{
+		costAllocation(2, "PRY", 50, pilaDate(9, 16)),
+		costAllocation(1, "ADM", 100, pilaDate(1, 1)),
+		costAllocation(1, "ADM", 50, pilaDate(9, 16)),
+	}
+
+	// El cambio de asignación a mitad de mes pondera cada versión por sus días
+	shares := periodCostShares(allocations, pilaDate(9, 1), pilaDate(9, 30))
+	require.Len(t, shares, 2)
+	assert.Equal(t, costShare{CostCenterID: 1, Code: "ADM", Percentage: 75}, shares[0])
+	assert.Equal(t, costShare{CostCenterID: 2, Code: "PRY", Percentage: 25}, shares[1])
+
+	// Un periodo anterior a la primera versión no se reparte
+	assert.Empty(t, periodCostShares(allocations[:1], pilaDate(8, 1), pilaDate(8, 31)))
+
+	// Los días antes de la primera versión quedan sin repartir
+	shares = periodCostShares([]domain.CostCenterAllocation{costAllocation(1, "ADM", 100, pilaDate(9, 11))}, pilaDate(9, 1), pilaDate(9, 30))
+	require.Len(t, shares, 1)
+	assert.InDelta(t, 66.6667, shares[0].Percentage, 0.00001)
+}
+
+func TestAllocateItems(t *testing.T) {
+	items := []domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 1000},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 40},
+		{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 120},
+	}
+	shares := []costShare{
+		{CostCenterID: 1, Code: "ADM", Percentage: 33.3333},
+		{CostCenterID: 2, Code: "PRY", Percentage: 33.3333},
+		{CostCenterID: 3, Code: "VTA", Percentage: 33.3334},
+	}
+
+	allocateItems(items, shares)
+
+	// El último centro absorbe el redondeo para cuadrar con el item
+	require.Len(t, items[0].Allocations, 3)
+	assert.Equal(t, 333.33, items[0].Allocations[0].Amount)
+	assert.Equal(t, 333.33, items[0].Allocations[1].Amount)
+	assert.Equal(t, 333.34, items[0].Allocations[2].Amount)
+	assert.Equal(t, "VTA", items[0].Allocations[2].CostCenterCode)
+	assert.Empty(t, items[1].Allocations)
+	assert.Len(t, items[2].Allocations, 3)
+
+	// Un item ya repartido no se vuelve a repartir
+	allocateItems(items, shares)
+	assert.Len(t, items[0].Allocations, 3)
+
+	// Si los porcentajes no suman 100 el resto queda sin repartir
+	partial := []domain.PayrollItem{{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 3000000}}
+	allocateItems(partial, []costShare{{CostCenterID: 1, Code: "ADM", Percentage: 66.6667}})
+	require.Len(t, partial[0].Allocations, 1)
+	assert.Equal(t, 2000001.0, partial[0].Allocations[0].Amount)
+}
+
+func TestPayrollCalculator_Calculate_AllocatesCostCenters(t *testing.T) {
+	ctx := context.Background()
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	allocationRepo := new(MockCostCenterAllocationRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, new(MockPayrollRepo), new(MockPayrollItemRepo), mockEmployeeRepo,
+		mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), allocationRepo, newAbsenceRepoMock(), nil)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 4000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 4},
+	}, nil)
+	allocationRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.CostCenterAllocation{
+		costAllocation(1, "ADM", 60, pilaDate(1, 1)),
+		costAllocation(2, "PRY", 40, pilaDate(1, 1)),
+	}, nil)
+
+	result, err := calculator.Calculate(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: pilaDate(9, 1),
+		PeriodEnd:   pilaDate(9, 30),
+	})
+
+	require.NoError(t, err)
+	for _, item := range result.Items {
+		if item.Type == domain.PayrollTypeDeduction {
+			assert.Empty(t, item.Allocations, item.Code)
+			continue
+		}
+		require.Len(t, item.Allocations, 2, item.Code)
+		assert.InDelta(t, item.Amount, item.Allocations[0].Amount+item.Allocations[1].Amount, 0.001)
+	}
+	allocationRepo.AssertExpectations(t)
+}
+
+func TestCostCenterService_SetAllocation(t *testing.T) {
+	ctx := withActor(context.Background(), 7)
+	costCenterRepo := new(MockCostCenterRepo)
+	allocationRepo := new(MockCostCenterAllocationRepo)
+	employeeRepo := new(MockEmployeeRepo)
+	svc := NewCostCenterService(costCenterRepo, allocationRepo, employeeRepo, new(MockPayrollRegisterRepo))
+
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1}, nil)
+	costCenterRepo.On("GetByID", ctx, uint(1)).Return(&domain.CostCenter{ID: 1, Code: "ADM", IsActive: true}, nil)
+	costCenterRepo.On("GetByID", ctx, uint(2)).Return(&domain.CostCenter{ID: 2, Code: "PRY", IsActive: true}, nil)
+	costCenterRepo.On("GetByID", ctx, uint(3)).Return(&domain.CostCenter{ID: 3, Code: "OLD"}, nil)
+	from := pilaDate(10, 1)
+
+	tests := []struct {
+		name        string
+		allocations []domain.CostCenterAllocation
+		err         error
+	}{
+		{"no suma 100", []domain.CostCenterAllocation{{CostCenterID: 1, Percentage: 60}, {CostCenterID: 2, Percentage: 30}}, domain.ErrInvalidCostAllocation},
+		{"centro repetido", []domain.CostCenterAllocation{{CostCenterID: 1, Percentage: 50}, {CostCenterID: 1, Percentage: 50}}, domain.ErrInvalidCostAllocation},
+		{"porcentaje negativo", []domain.CostCenterAllocation{{CostCenterID: 1, Percentage: 120}, {CostCenterID: 2, Percentage: -20}}, domain.ErrInvalidCostAllocation},
+		{"centro inactivo", []domain.CostCenterAllocation{{CostCenterID: 3, Percentage: 100}}, domain.ErrCostCenterInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.SetAllocation(ctx, 1, from, tt.allocations)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	allocationRepo.AssertNotCalled(t, "ReplaceVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	allocationRepo.On("ReplaceVersion", ctx, uint(1), from, mock.MatchedBy(func(allocations []domain.CostCenterAllocation) bool {
+		return len(allocations) == 2 && allocations[0].CreatedBy == 7 && allocations[1].CreatedBy == 7
+	})).Return(nil)
+	allocationRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.CostCenterAllocation{
+		costAllocation(1, "ADM", 100, pilaDate(1, 1)),
+		costAllocation(1, "ADM", 70, from),
+		costAllocation(2, "PRY", 30, from),
+	}, nil)
+
+	versions, err := svc.SetAllocation(ctx, 1, from, []domain.CostCenterAllocation{
+		{CostCenterID: 1, Percentage: 70}, {CostCenterID: 2, Percentage: 30},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, from, versions[1].EffectiveFrom)
+	assert.Len(t, versions[1].Allocations, 2)
+	allocationRepo.AssertExpectations(t)
+}
+
+func TestCostCenterService_Report(t *testing.T) {
+	ctx := context.Background()
+	costCenterRepo := new(MockCostCenterRepo)
+	registerRepo := new(MockPayrollRegisterRepo)
+	svc := NewCostCenterService(costCenterRepo, new(MockCostCenterAllocationRepo), new(MockEmployeeRepo), registerRepo)
+
+	costCenterRepo.On("List", ctx).Return([]domain.CostCenter{
+		{ID: 1, Code: "ADM", Name: "Administración"},
+		{ID: 2, Code: "PRY", Name: "Proyecto Norte"},
+	}, nil)
+	allocated := func(item domain.PayrollItem, adm float64) domain.PayrollItem {
+		item.Allocations = []domain.PayrollItemAllocation{
+			{CostCenterID: 1, CostCenterCode: "ADM", Amount: adm},
+			{CostCenterID: 2, CostCenterCode: "PRY", Amount: item.Amount - adm},
+		}
+		return item
+	}
+	registerRepo.On("Stream", ctx, mock.MatchedBy(func(f domain.PayrollRegisterFilter) bool {
+		return len(f.Statuses) == len(journalPayrollStatuses)
+	}), registerBatchSize).Return([]domain.Payroll{
+		{ID: 5, EmployeeID: 1, Items: []domain.PayrollItem{
+			allocated(domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000}, 1500000),
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 80000},
+			allocated(domain.PayrollItem{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 240000}, 180000),
+		}},
+		// Nómina sin asignación: su costo queda sin centro
+		{ID: 6, EmployeeID: 2, Items: []domain.PayrollItem{
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 1000000},
+		}},
+	}, nil)
+
+	report, err := svc.Report(ctx, domain.PayrollRegisterFilter{PeriodStart: pilaDate(9, 1), PeriodEnd: pilaDate(9, 30)})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3000000.0, report.Earnings)
+	assert.Equal(t, 240000.0, report.EmployerContributions)
+	assert.Equal(t, 3240000.0, report.Total)
+	require.Len(t, report.Centers, 3)
+
+	adm := report.Centers[0]
+	assert.Equal(t, "Administración", adm.Name)
+	assert.Equal(t, 1500000.0, adm.Earnings)
+	assert.Equal(t, 180000.0, adm.EmployerContributions)
+	assert.Equal(t, 1680000.0, adm.Total)
+	assert.Equal(t, 51.85, adm.Share)
+	assert.Equal(t, 180000.0, adm.Concepts[domain.ConceptPensionEmployer])
+	assert.Equal(t, "PRY", report.Centers[1].Code)
+	assert.Equal(t, 560000.0, report.Centers[1].Total)
+	assert.Equal(t, "Sin centro de costo", report.Centers[2].Name)
+	assert.Equal(t, 1000000.0, report.Centers[2].Total)
+
+	content, err := report.CSV()
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "cost_center,name,BASE_SALARY,PENSION_EMPLOYER,earnings,employer_contributions,total,share", lines[0])
+	assert.Equal(t, "ADM,Administración,1500000.00,180000.00,1500000.00,180000.00,1680000.00,51.85", lines[1])
+	assert.Equal(t, "TOTAL,,3000000.00,240000.00,3000000.00,240000.00,3240000.00,100.00", lines[4])
+
+	// Sin nóminas en el periodo no hay informe
+	emptyRepo := new(MockPayrollRegisterRepo)
+	emptyRepo.On("Stream", ctx, mock.Anything, registerBatchSize).Return([]domain.Payroll{}, nil)
+	svc = NewCostCenterService(costCenterRepo, new(MockCostCenterAllocationRepo), new(MockEmployeeRepo), emptyRepo)
+	_, err = svc.Report(ctx, domain.PayrollRegisterFilter{PeriodStart: pilaDate(9, 1), PeriodEnd: pilaDate(9, 30)})
+	assert.ErrorIs(t, err, domain.ErrNoPayrollsForRegister)
+
+	_, err = svc.Report(ctx, domain.PayrollRegisterFilter{PeriodStart: pilaDate(9, 30), PeriodEnd: pilaDate(9, 1)})
+	assert.ErrorIs(t, err, domain.ErrInvalidRegisterFilter)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// DataScopeAction es la acción cuyo alcance se usa para filtrar los listados
+const DataScopeAction = "read"
+
+// DataScopeService resuelve el alcance de datos del usuario y lo deja en el contexto para
+// que los repositorios filtren los listados automáticamente
+type DataScopeService struct {
+	permissionSvc *PermissionService
+	employeeRepo  domain.EmployeeRepo
+}
+
+func NewDataScopeService(permissionSvc *PermissionService, employeeRepo domain.EmployeeRepo) *DataScopeService {
+	return &DataScopeService{
+		permissionSvc: permissionSvc,
+		employeeRepo:  employeeRepo,
+	}
+}
+
+// Apply agrega al contexto los empleados visibles por el actor sobre resource. Las peticiones
+// sin actor (X-User-ID es opcional) y los actores sin ningún permiso con alcance limitado no se
+// restringen. Un actor con alcance limitado en algún recurso pero sin permiso read sobre este
+// queda con el alcance vacío y no ve ningún registro.
+func (s *DataScopeService) Apply(ctx context.Context, resource string) (context.Context, error) {
+	actor := actorFromCtx(ctx)
+	if actor == 0 {
+		return ctx, nil
+	}
+	scopes, err := s.permissionSvc.DataScopes(ctx, actor, resource, DataScopeAction)
+	if err != nil {
+		return ctx, err
+	}
+	if len(scopes) == 0 {
+		scoped, err := s.permissionSvc.HasScopedPermission(ctx, actor)
+		if err != nil {
+			return ctx, err
+		}
+		if !scoped {
+			return ctx, nil
+		}
+		return withEmployeeScope(ctx, []uint{}), nil
+	}
+	for _, scope := range scopes {
+		if scope == domain.DataScopeAll {
+			return ctx, nil
+		}
+	}
+
+	ids, err := s.employeeIDs(ctx, actor, scopes)
+	if err != nil {
+		return ctx, err
+	}
+	return withEmployeeScope(ctx, ids), nil
+}
+
+func withEmployeeScope(ctx context.Context, ids []uint) context.Context {
+	return context.WithValue(ctx, domain.EmployeeScopeKey, &domain.EmployeeScope{EmployeeIDs: ids})
+}
+
+// employeeIDs une los empleados de cada alcance; el propio empleado del actor siempre es visible.
+// Un actor sin empleado asociado no ve ningún registro.
+func (s *DataScopeService) employeeIDs(ctx context.Context, actor uint, scopes []string) ([]uint, error) {
+	self, err := s.employeeRepo.GetByUserID(ctx, actor)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmployeeNotFound) {
+			return []uint{}, nil
+		}
+		return nil, err
+	}
+
+	visible := map[uint]bool{self.ID: true}
+	for _, scope := range scopes {
+		switch scope {
+		case domain.DataScopeOwnReports:
+			reports, err := s.reportIDs(ctx, self.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range reports {
+				visible[id] = true
+			}
+		case domain.DataScopeOwnDepartment:
+			if self.DepartmentID == 0 {
+				continue
+			}
+			members, err := s.employeeRepo.ListByDepartment(ctx, self.DepartmentID)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				visible[member.ID] = true
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(visible))
+	for id := range visible {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// reportIDs recorre la jerarquía por niveles y retorna los subordinados directos e indirectos
+func (s *DataScopeService) reportIDs(ctx context.Context, managerID uint) ([]uint, error) {
+	seen := map[uint]bool{managerID: true}
+	var ids []uint
+	level := []uint{managerID}
+	for len(level) > 0 {
+		reports, err := s.employeeRepo.ListByManagers(ctx, level)
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0:0]
+		for _, report := range reports {
+			if seen[report.ID] {
+				continue
+			}
+			seen[report.ID] = true
+			ids = append(ids, report.ID)
+			level = append(level, report.ID)
+		}
+	}
+	return ids, nil
+}
+
+// employeeScopeFromCtx retorna el alcance de datos del contexto, nil si no hay restricción
+func employeeScopeFromCtx(ctx context.Context) *domain.EmployeeScope {
+	scope, _ := ctx.Value(domain.EmployeeScopeKey).(*domain.EmployeeScope)
+	return scope
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// roleWithScope arma un rol con el permiso resource.read limitado al alcance indicado
+func roleWithScope(resource, scope string) domain.Role {
+	role := roleWithPermission(resource, DataScopeAction)
+	role.RolePermissions[0].Scope = scope
+	return role
+}
+
+func newDataScopeService(roles ...domain.Role) (*DataScopeService, *MockEmployeeRepo, context.Context) {
+	ctx := withActor(context.Background(), 7)
+	userRoleRepo := new(MockUserRoleRepo)
+	userRoleRepo.On("GetUserRoles", ctx, uint(7)).Return(roles, nil)
+	employeeRepo := new(MockEmployeeRepo)
+	return NewDataScopeService(NewPermissionService(userRoleRepo, mocks.NewMockRoleRepo()), employeeRepo), employeeRepo, ctx
+}
+
+func TestDataScopeService_Apply_OwnReports(t *testing.T) {
+	svc, employeeRepo, ctx := newDataScopeService(roleWithScope(domain.ResourceEmployees, domain.DataScopeOwnReports))
+
+	employeeRepo.On("GetByUserID", ctx, uint(7)).Return(&domain.Employee{ID: 10}, nil)
+	employeeRepo.On("ListByManagers", ctx, []uint{10}).Return([]domain.Employee{{ID: 11}, {ID: 12}}, nil)
+	employeeRepo.On("ListByManagers", ctx, []uint{11, 12}).Return([]domain.Employee{{ID: 13}}, nil)
+	employeeRepo.On("ListByManagers", ctx, []uint{13}).Return([]domain.Employee{}, nil)
+
+	scoped, err := svc.Apply(ctx, domain.ResourceEmployees)
+
+	assert.NoError(t, err)
+	scope := employeeScopeFromCtx(scoped)
+	assert.NotNil(t, scope)
+	assert.Equal(t, []uint{10, 11, 12, 13}, scope.EmployeeIDs)
+}
+
+func TestDataScopeService_Apply_AllScopeWins(t *testing.T) {
+	svc, employeeRepo, ctx := newDataScopeService(
+		roleWithScope(domain.ResourcePayrolls, domain.DataScopeOwnDepartment),
+		roleWithPermission(domain.ResourcePayrolls, DataScopeAction),
+	)
+
+	scoped, err := svc.Apply(ctx, domain.ResourcePayrolls)
+
+	assert.NoError(t, err)
+	assert.Nil(t, employeeScopeFromCtx(scoped))
+	employeeRepo.AssertNotCalled(t, "GetByUserID", mock.Anything, mock.Anything)
+}
+
+func TestDataScopeService_Apply_NoPermissionSeesNothing(t *testing.T) {
+	// El rol otorga lectura de empleados pero no de nóminas
+	svc, employeeRepo, ctx := newDataScopeService(roleWithScope(domain.ResourceEmployees, domain.DataScopeOwnReports))
+
+	scoped, err := svc.Apply(ctx, domain.ResourcePayrolls)
+
+	assert.NoError(t, err)
+	scope := employeeScopeFromCtx(scoped)
+	assert.NotNil(t, scope)
+	assert.Empty(t, scope.EmployeeIDs)
+	assert.False(t, scope.Allows(7))
+	employeeRepo.AssertNotCalled(t, "GetByUserID", mock.Anything, mock.Anything)
+}
+
+func TestDataScopeService_Apply_WithoutActorIsUnrestricted(t *testing.T) {
+	svc, _, _ := newDataScopeService(roleWithPermission(domain.ResourceEmployees, DataScopeAction))
+
+	scoped, err := svc.Apply(context.Background(), domain.ResourceEmployees)
+
+	assert.NoError(t, err)
+	assert.Nil(t, employeeScopeFromCtx(scoped))
+}
+
+func TestDataScopeService_Apply_NoScopedRoleIsUnrestricted(t *testing.T) {
+	// Los roles existentes sin alcance configurado no restringen los listados
+	svc, employeeRepo, ctx := newDataScopeService(roleWithPermission(domain.ResourceEmployees, "write"))
+
+	scoped, err := svc.Apply(ctx, domain.ResourcePayrolls)
+
+	assert.NoError(t, err)
+	assert.Nil(t, employeeScopeFromCtx(scoped))
+	employeeRepo.AssertNotCalled(t, "GetByUserID", mock.Anything, mock.Anything)
+}
+
+func TestPayrollService_ListByEmployee_OutOfScope(t *testing.T) {
+	scopeSvc, employeeRepo, ctx := newDataScopeService(roleWithScope(domain.ResourcePayrolls, domain.DataScopeOwnDepartment))
+	payrollRepo := new(MockPayrollRepo)
+	svc := NewPayrollService(payrollRepo, newOpenPeriodRepo(), scopeSvc)
+
+	employeeRepo.On("GetByUserID", ctx, uint(7)).Return(&domain.Employee{ID: 10, DepartmentID: 3}, nil)
+	employeeRepo.On("ListByDepartment", ctx, uint(3)).Return([]domain.Employee{{ID: 10}, {ID: 14}}, nil)
+
+	_, err := svc.ListByEmployee(ctx, 20)
+
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	payrollRepo.AssertNotCalled(t, "ListByEmployee", mock.Anything, mock.Anything)
+}
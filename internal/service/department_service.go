@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// DepartmentService administra el árbol de departamentos del tenant y sus jefes
+type DepartmentService struct {
+	departmentRepo domain.DepartmentRepo
+	employeeRepo   domain.EmployeeRepo
+}
+
+func NewDepartmentService(departmentRepo domain.DepartmentRepo, employeeRepo domain.EmployeeRepo) *DepartmentService {
+	return &DepartmentService{
+		departmentRepo: departmentRepo,
+		employeeRepo:   employeeRepo,
+	}
+}
+
+// DepartmentNode es un nodo del árbol organizacional. Headcount cuenta los empleados
+// activos del departamento y TotalHeadcount incluye los de sus sub-departamentos.
+type DepartmentNode struct {
+	Department     domain.Department
+	Headcount      int64
+	TotalHeadcount int64
+	Children       []*DepartmentNode
+}
+
+func (s *DepartmentService) Create(ctx context.Context, department *domain.Department) error {
+	if department == nil {
+		return errors.New("department cannot be nil")
+	}
+	department.Name = strings.TrimSpace(department.Name)
+	department.Code = strings.ToUpper(strings.TrimSpace(department.Code))
+	if department.Name == "" {
+		return errors.New("department name is required")
+	}
+	if department.ParentID != nil {
+		if _, err := s.departmentRepo.GetByID(ctx, *department.ParentID); err != nil {
+			return err
+		}
+	}
+	if err := s.checkHead(ctx, department.HeadEmployeeID); err != nil {
+		return err
+	}
+	return s.departmentRepo.Create(ctx, department)
+}
+
+func (s *DepartmentService) GetByID(ctx context.Context, id uint) (*domain.Department, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	return s.departmentRepo.GetByID(ctx, id)
+}
+
+func (s *DepartmentService) List(ctx context.Context) ([]domain.Department, error) {
+	return s.departmentRepo.List(ctx)
+}
+
+// Update guarda los cambios del departamento. Un padre que lo tenga como ancestro se
+// rechaza, y no se puede desactivar mientras tenga empleados activos.
+func (s *DepartmentService) Update(ctx context.Context, department *domain.Department) error {
+	if department == nil || department.ID == 0 {
+		return errors.New("invalid department id")
+	}
+	existing, err := s.departmentRepo.GetByID(ctx, department.ID)
+	if err != nil {
+		return err
+	}
+	department.Name = strings.TrimSpace(department.Name)
+	department.Code = strings.ToUpper(strings.TrimSpace(department.Code))
+	if department.Name == "" {
+		return errors.New("department name is required")
+	}
+
+	if department.ParentID != nil {
+		departments, err := s.departmentRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+		if !containsDepartment(departments, *department.ParentID) {
+			return domain.ErrDepartmentNotFound
+		}
+		if createsDepartmentCycle(departments, department.ID, *department.ParentID) {
+			return domain.ErrDepartmentCycle
+		}
+	}
+	if err := s.checkHead(ctx, department.HeadEmployeeID); err != nil {
+		return err
+	}
+	if existing.IsActive && !department.IsActive {
+		if err := s.ensureNoActiveEmployees(ctx, department.ID); err != nil {
+			return err
+		}
+	}
+	return s.departmentRepo.Update(ctx, department)
+}
+
+// SetHead asigna o quita (employeeID nil) el jefe del departamento
+func (s *DepartmentService) SetHead(ctx context.Context, id uint, employeeID *uint) (*domain.Department, error) {
+	department, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkHead(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	department.HeadEmployeeID = employeeID
+	if err := s.departmentRepo.Update(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// Delete elimina un departamento sin empleados activos ni sub-departamentos
+func (s *DepartmentService) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid id")
+	}
+	if err := s.ensureNoActiveEmployees(ctx, id); err != nil {
+		return err
+	}
+	departments, err := s.departmentRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range departments {
+		if d.ParentID != nil && *d.ParentID == id {
+			return domain.ErrDepartmentHasChildren
+		}
+	}
+	return s.departmentRepo.Delete(ctx, id)
+}
+
+// Tree retorna el árbol organizacional con el headcount de cada nodo. Los departamentos
+// cuyo padre no existe se tratan como raíces.
+func (s *DepartmentService) Tree(ctx context.Context) ([]*DepartmentNode, error) {
+	departments, err := s.departmentRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headcount, err := s.departmentRepo.Headcount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint]*DepartmentNode, len(departments))
+	for _, d := range departments {
+		nodes[d.ID] = &DepartmentNode{Department: d, Headcount: headcount[d.ID]}
+	}
+	roots := []*DepartmentNode{}
+	for _, d := range departments {
+		node := nodes[d.ID]
+		if d.ParentID != nil {
+			if parent, ok := nodes[*d.ParentID]; ok && *d.ParentID != d.ID {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	for _, root := range roots {
+		sumHeadcount(root)
+	}
+	sort.SliceStable(roots, func(i, j int) bool { return roots[i].Department.Name < roots[j].Department.Name })
+	return roots, nil
+}
+
+func sumHeadcount(node *DepartmentNode) int64 {
+	node.TotalHeadcount = node.Headcount
+	for _, child := range node.Children {
+		node.TotalHeadcount += sumHeadcount(child)
+	}
+	return node.TotalHeadcount
+}
+
+// createsDepartmentCycle indica si asignar parentID como padre de id forma un ciclo
+func createsDepartmentCycle(departments []domain.Department, id, parentID uint) bool {
+	parents := make(map[uint]*uint, len(departments))
+	for _, d := range departments {
+		parents[d.ID] = d.ParentID
+	}
+	visited := make(map[uint]bool)
+	for current := &parentID; current != nil; current = parents[*current] {
+		if *current == id || visited[*current] {
+			return true
+		}
+		visited[*current] = true
+	}
+	return false
+}
+
+func containsDepartment(departments []domain.Department, id uint) bool {
+	for _, d := range departments {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DepartmentService) checkHead(ctx context.Context, employeeID *uint) error {
+	if employeeID == nil {
+		return nil
+	}
+	employee, err := s.employeeRepo.GetByID(ctx, *employeeID)
+	if err != nil {
+		return err
+	}
+	if !employee.IsActive {
+		return domain.ErrEmployeeInactive
+	}
+	return nil
+}
+
+func (s *DepartmentService) ensureNoActiveEmployees(ctx context.Context, id uint) error {
+	headcount, err := s.departmentRepo.Headcount(ctx)
+	if err != nil {
+		return err
+	}
+	if headcount[id] > 0 {
+		return domain.ErrDepartmentHasActiveEmployees
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDepartmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockDepartmentRepo) Create(ctx context.Context, department *domain.Department) error {
+	args := m.Called(ctx, department)
+	return args.Error(0)
+}
+
+func (m *MockDepartmentRepo) GetByID(ctx context.Context, id uint) (*domain.Department, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Department), args.Error(1)
+}
+
+func (m *MockDepartmentRepo) List(ctx context.Context) ([]domain.Department, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Department), args.Error(1)
+}
+
+func (m *MockDepartmentRepo) Update(ctx context.Context, department *domain.Department) error {
+	args := m.Called(ctx, department)
+	return args.Error(0)
+}
+
+func (m *MockDepartmentRepo) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDepartmentRepo) Headcount(ctx context.Context) (map[uint]int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[uint]int64), args.Error(1)
+}
+
+type MockPositionRepo struct {
+	mock.Mock
+}
+
+func (m *MockPositionRepo) Create(ctx context.Context, position *domain.Position) error {
+	args := m.Called(ctx, position)
+	return args.Error(0)
+}
+
+func (m *MockPositionRepo) GetByID(ctx context.Context, id uint) (*domain.Position, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Position), args.Error(1)
+}
+
+func (m *MockPositionRepo) List(ctx context.Context, departmentID uint) ([]domain.Position, error) {
+	args := m.Called(ctx, departmentID)
+	return args.Get(0).([]domain.Position), args.Error(1)
+}
+
+func (m *MockPositionRepo) Update(ctx context.Context, position *domain.Position) error {
+	args := m.Called(ctx, position)
+	return args.Error(0)
+}
+
+func (m *MockPositionRepo) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestDepartmentService_Update_BlocksDeactivationWithActiveEmployees(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockDepartmentRepo)
+	svc := NewDepartmentService(repo, new(MockEmployeeRepo))
+
+	repo.On("GetByID", ctx, uint(3)).Return(&domain.Department{ID: 3, Name: "Ventas", IsActive: true}, nil)
+	repo.On("Headcount", ctx).Return(map[uint]int64{3: 4}, nil)
+
+	err := svc.Update(ctx, &domain.Department{ID: 3, Name: "Ventas", IsActive: false})
+
+	assert.ErrorIs(t, err, domain.ErrDepartmentHasActiveEmployees)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDepartmentService_Update_RejectsCycle(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockDepartmentRepo)
+	svc := NewDepartmentService(repo, new(MockEmployeeRepo))
+
+	repo.On("GetByID", ctx, uint(1)).Return(&domain.Department{ID: 1, Name: "Operaciones", IsActive: true}, nil)
+	repo.On("List", ctx).Return([]domain.Department{
+		{ID: 1, Name: "Operaciones"},
+		{ID: 2, Name: "Logística", ParentID: uintPtr(1)},
+		{ID: 3, Name: "Bodega", ParentID: uintPtr(2)},
+	}, nil)
+
+	err := svc.Update(ctx, &domain.Department{ID: 1, Name: "Operaciones", ParentID: uintPtr(3), IsActive: true})
+
+	assert.ErrorIs(t, err, domain.ErrDepartmentCycle)
+}
+
+func TestDepartmentService_Tree_AggregatesHeadcount(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockDepartmentRepo)
+	svc := NewDepartmentService(repo, new(MockEmployeeRepo))
+
+	repo.On("List", ctx).Return([]domain.Department{
+		{ID: 1, Name: "Operaciones"},
+		{ID: 2, Name: "Logística", ParentID: uintPtr(1)},
+		{ID: 3, Name: "Bodega", ParentID: uintPtr(2)},
+		{ID: 4, Name: "Finanzas"},
+	}, nil)
+	repo.On("Headcount", ctx).Return(map[uint]int64{1: 2, 2: 3, 3: 5, 4: 1}, nil)
+
+	roots, err := svc.Tree(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, roots, 2)
+	assert.Equal(t, "Finanzas", roots[0].Department.Name)
+	operations := roots[1]
+	assert.Equal(t, int64(2), operations.Headcount)
+	assert.Equal(t, int64(10), operations.TotalHeadcount)
+	assert.Equal(t, int64(8), operations.Children[0].TotalHeadcount)
+}
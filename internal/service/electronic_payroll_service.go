@@ -0,0 +1,475 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/dian"
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// Prefijos de numeración de los documentos
+const (
+	prefixIndividual = "NE"
+	prefixAdjustment = "NA"
+)
+
+// ElectronicPayrollService genera los documentos de nómina electrónica de cada mes y los
+// transmite a la DIAN. Un mes ya aceptado que cambia (reverso con reemplazo) genera una
+// nota de reemplazo; si el empleado queda sin nóminas pagadas, una nota de eliminación.
+type ElectronicPayrollService struct {
+	txManager    domain.TxManager
+	documentRepo domain.ElectronicPayrollRepo
+	payrollRepo  domain.PayrollRepo
+	contractRepo domain.EmployeeContractRepo
+	paymentRepo  domain.PaymentRepo
+	templateRepo domain.PayslipTemplateRepo
+	client       domain.ElectronicPayrollClient
+	settings     dian.Settings
+}
+
+func NewElectronicPayrollService(
+	txManager domain.TxManager,
+	documentRepo domain.ElectronicPayrollRepo,
+	payrollRepo domain.PayrollRepo,
+	contractRepo domain.EmployeeContractRepo,
+	paymentRepo domain.PaymentRepo,
+	templateRepo domain.PayslipTemplateRepo,
+	client domain.ElectronicPayrollClient,
+	settings dian.Settings,
+) *ElectronicPayrollService {
+	return &ElectronicPayrollService{
+		txManager:    txManager,
+		documentRepo: documentRepo,
+		payrollRepo:  payrollRepo,
+		contractRepo: contractRepo,
+		paymentRepo:  paymentRepo,
+		templateRepo: templateRepo,
+		client:       client,
+		settings:     settings,
+	}
+}
+
+// ElectronicPayrollGeneration resume una generación mensual
+type ElectronicPayrollGeneration struct {
+	Year       int
+	Month      int
+	Documents  []domain.ElectronicPayrollDocument
+	Unchanged  int
+	Superseded int
+}
+
+// Generate crea los documentos del mes para cada empleado con nóminas pagadas. Los
+// documentos que no cambiaron se conservan; los no aceptados que cambiaron se reemplazan
+// por uno nuevo y los aceptados se corrigen con una nota de ajuste.
+func (s *ElectronicPayrollService) Generate(ctx context.Context, year, month int) (*ElectronicPayrollGeneration, error) {
+	if year < 2000 || month < 1 || month > 12 {
+		return nil, domain.ErrInvalidElectronicPayrollPeriod
+	}
+	employer, err := s.employer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	payrolls, err := s.payrollRepo.GetByPeriod(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	byEmployee := map[uint][]domain.Payroll{}
+	for _, p := range payrolls {
+		if p.Status == domain.PayrollStatusPaid && p.Kind != domain.PayrollKindReversal {
+			byEmployee[p.EmployeeID] = append(byEmployee[p.EmployeeID], p)
+		}
+	}
+
+	existing, err := s.documentRepo.ListByPeriod(ctx, year, month)
+	if err != nil {
+		return nil, err
+	}
+	// Documento vigente de cada empleado: el último no reemplazado
+	heads := map[uint]*domain.ElectronicPayrollDocument{}
+	byID := map[uint]*domain.ElectronicPayrollDocument{}
+	for i := range existing {
+		byID[existing[i].ID] = &existing[i]
+		if existing[i].Status != domain.ElectronicPayrollSuperseded {
+			heads[existing[i].EmployeeID] = &existing[i]
+		}
+	}
+
+	employeeIDs := make([]uint, 0, len(byEmployee)+len(heads))
+	for id := range byEmployee {
+		employeeIDs = append(employeeIDs, id)
+	}
+	for id := range heads {
+		if _, ok := byEmployee[id]; !ok {
+			employeeIDs = append(employeeIDs, id)
+		}
+	}
+	sort.Slice(employeeIDs, func(i, j int) bool { return employeeIDs[i] < employeeIDs[j] })
+
+	result := &ElectronicPayrollGeneration{Year: year, Month: month}
+	issuedAt := time.Now()
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, employeeID := range employeeIDs {
+			employeePayrolls := byEmployee[employeeID]
+			head := heads[employeeID]
+			fingerprint := payrollFingerprint(employeePayrolls)
+			if head != nil && head.Fingerprint == fingerprint && head.Status != domain.ElectronicPayrollRejected {
+				result.Unchanged++
+				continue
+			}
+
+			// El predecesor es el último documento aceptado; una eliminación aceptada cierra la cadena
+			predecessor := head
+			if head != nil && head.Status != domain.ElectronicPayrollAccepted {
+				predecessor = nil
+				if head.PredecessorID != nil {
+					predecessor = byID[*head.PredecessorID]
+				}
+				head.Status = domain.ElectronicPayrollSuperseded
+				if err := s.documentRepo.UpdateStatus(ctx, head); err != nil {
+					return err
+				}
+				result.Superseded++
+			}
+			if predecessor != nil && predecessor.DocumentType == dian.TipoXMLAdjustment && predecessor.NoteType == dian.NoteDelete {
+				predecessor = nil
+			}
+			if len(employeePayrolls) == 0 && predecessor == nil {
+				continue
+			}
+
+			doc := &dian.Document{
+				TipoXML:     dian.TipoXMLIndividual,
+				Prefix:      prefixIndividual,
+				IssuedAt:    issuedAt,
+				PeriodStart: start,
+				PeriodEnd:   start.AddDate(0, 1, -1),
+				Employer:    *employer,
+			}
+			if predecessor != nil {
+				doc.TipoXML = dian.TipoXMLAdjustment
+				doc.Prefix = prefixAdjustment
+				doc.NoteType = dian.NoteReplace
+				doc.Predecessor = &dian.Predecessor{Number: predecessor.Number, CUNE: predecessor.CUNE, IssuedAt: predecessor.IssuedAt}
+				doc.Notes = []string{"Reemplaza el documento " + predecessor.Number}
+				if len(employeePayrolls) == 0 {
+					doc.NoteType = dian.NoteDelete
+					doc.Notes = []string{"Elimina el documento " + predecessor.Number}
+				}
+			}
+			if len(employeePayrolls) > 0 {
+				if err := s.fillEmployeeData(ctx, doc, employeePayrolls); err != nil {
+					return fmt.Errorf("employee %d: %w", employeeID, err)
+				}
+			} else {
+				doc.Worker.Code = strconv.FormatUint(uint64(employeeID), 10)
+			}
+
+			document, err := s.issue(ctx, doc, predecessor, employeeID, year, month, employeePayrolls, fingerprint)
+			if err != nil {
+				return fmt.Errorf("employee %d: %w", employeeID, err)
+			}
+			result.Documents = append(result.Documents, *document)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// issue numera el documento, genera y valida su XML y lo guarda
+func (s *ElectronicPayrollService) issue(ctx context.Context, doc *dian.Document, predecessor *domain.ElectronicPayrollDocument, employeeID uint, year, month int, payrolls []domain.Payroll, fingerprint string) (*domain.ElectronicPayrollDocument, error) {
+	consecutive, err := s.documentRepo.NextConsecutive(ctx, doc.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	doc.Consecutive = consecutive
+	built, err := dian.Build(doc, s.settings)
+	if err != nil {
+		var invalid *dian.ValidationError
+		if errors.As(err, &invalid) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidElectronicPayrollXML, strings.Join(invalid.Problems, "; "))
+		}
+		return nil, err
+	}
+
+	ids := make([]string, len(payrolls))
+	for i, p := range payrolls {
+		ids[i] = strconv.FormatUint(uint64(p.ID), 10)
+	}
+	earnings, deductions, net := doc.Totals()
+	document := &domain.ElectronicPayrollDocument{
+		EmployeeID:      employeeID,
+		Year:            year,
+		Month:           month,
+		DocumentType:    doc.TipoXML,
+		NoteType:        doc.NoteType,
+		Prefix:          doc.Prefix,
+		Consecutive:     doc.Consecutive,
+		Number:          doc.Number(),
+		CUNE:            built.CUNE,
+		PayrollIDs:      strings.Join(ids, ","),
+		Fingerprint:     fingerprint,
+		TotalEarnings:   earnings,
+		TotalDeductions: deductions,
+		NetAmount:       net,
+		XML:             built.XML,
+		Status:          domain.ElectronicPayrollGenerated,
+		IssuedAt:        doc.IssuedAt,
+		GeneratedBy:     actorFromCtx(ctx),
+	}
+	if predecessor != nil {
+		document.PredecessorID = &predecessor.ID
+	}
+	if err := s.documentRepo.Create(ctx, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// fillEmployeeData completa trabajador, pago y conceptos a partir de las nóminas del mes
+func (s *ElectronicPayrollService) fillEmployeeData(ctx context.Context, doc *dian.Document, payrolls []domain.Payroll) error {
+	employee := payrolls[0].Employee
+	if strings.TrimSpace(employee.User.Dni) == "" {
+		return errors.New("employee has no identification number")
+	}
+	doc.Worker = dian.Worker{
+		Code:           strconv.FormatUint(uint64(employee.ID), 10),
+		DocumentType:   "13",
+		DocumentNumber: employee.User.Dni,
+		FirstName:      employee.User.FirstName,
+		LastName:       employee.User.LastName,
+		ContractType:   "2",
+		HireDate:       employee.CreatedAt,
+	}
+
+	// Contratos: fecha de ingreso, sueldo vigente y tipo de contrato
+	contracts, err := s.contractRepo.ListByEmployee(ctx, employee.ID)
+	if err != nil {
+		return err
+	}
+	var current *domain.EmployeeContract
+	for i := range contracts {
+		c := &contracts[i]
+		if i == 0 || c.StartDate.Before(doc.Worker.HireDate) {
+			doc.Worker.HireDate = c.StartDate
+		}
+		if current == nil || c.StartDate.After(current.StartDate) {
+			current = c
+		}
+	}
+	if current != nil {
+		doc.Worker.Salary = current.BaseSalary
+		if current.EndDate != nil {
+			doc.Worker.ContractType = "1"
+		}
+	}
+
+	// Conceptos, días y fechas de pago
+	payDates := map[string]time.Time{}
+	others := map[string]float64{}
+	var otherNames []string
+	for _, p := range payrolls {
+		payDates[p.PayDate.Format("2006-01-02")] = p.PayDate
+		if p.Kind == domain.PayrollKindRegular || p.Kind == domain.PayrollKindReplacement {
+			doc.Earnings.DaysWorked += min(30, domain.Days360(p.PeriodStart, p.PeriodEnd))
+		}
+		if p.Kind == domain.PayrollKindSettlement && current != nil && current.EndDate != nil {
+			doc.Worker.RetirementDate = current.EndDate
+		}
+		for _, item := range p.Items {
+			switch item.Type {
+			case domain.PayrollTypeEarning:
+				switch item.Code {
+				case domain.ConceptBaseSalary:
+					doc.Earnings.Salary += item.Amount
+				case domain.ConceptApprenticeStipend:
+					doc.Earnings.Salary += item.Amount
+					doc.Worker.ContractType = "4"
+				case domain.ConceptTransport:
+					doc.Earnings.Transport += item.Amount
+				case domain.ConceptVacationPayment:
+					doc.Earnings.Vacation += item.Amount
+				case domain.ConceptPrimaPayment:
+					doc.Earnings.Prima += item.Amount
+				case domain.ConceptSeverancePayment:
+					doc.Earnings.Severance += item.Amount
+				case domain.ConceptSeveranceInterestPayment:
+					doc.Earnings.SeveranceInterest += item.Amount
+				case domain.ConceptIndemnification:
+					doc.Earnings.Indemnification += item.Amount
+				case domain.ConceptBonus:
+					doc.Earnings.Bonuses = append(doc.Earnings.Bonuses, item.Amount)
+				default:
+					if _, ok := others[item.Name]; !ok {
+						otherNames = append(otherNames, item.Name)
+					}
+					others[item.Name] += item.Amount
+				}
+			case domain.PayrollTypeDeduction:
+				switch item.Code {
+				case domain.ConceptHealth:
+					doc.Deductions.Health += item.Amount
+				case domain.ConceptPension:
+					doc.Deductions.Pension += item.Amount
+				case domain.ConceptTax:
+					doc.Deductions.Withholding += item.Amount
+				default:
+					doc.Deductions.Others = append(doc.Deductions.Others, item.Amount)
+				}
+			}
+		}
+	}
+	doc.Earnings.DaysWorked = min(30, doc.Earnings.DaysWorked)
+	for _, name := range otherNames {
+		doc.Earnings.Others = append(doc.Earnings.Others, dian.Concept{Description: name, Amount: roundCents(others[name])})
+	}
+	for _, d := range payDates {
+		doc.PayDates = append(doc.PayDates, d)
+	}
+	sort.Slice(doc.PayDates, func(i, j int) bool { return doc.PayDates[i].Before(doc.PayDates[j]) })
+
+	// Medio de pago: el del primer pago no fallido de la última nómina
+	doc.Payment = dian.Payment{Method: "1"}
+	payments, err := s.paymentRepo.ListByPayroll(ctx, payrolls[len(payrolls)-1].ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range payments {
+		if p.Status == domain.PaymentStatusFailed {
+			continue
+		}
+		doc.Payment = dian.Payment{Method: paymentMethodCode(p.Method), BankName: p.BankName, AccountType: p.AccountType, AccountNumber: p.AccountNumber}
+		break
+	}
+	return nil
+}
+
+// employer toma razón social, NIT y dirección de la plantilla del desprendible
+func (s *ElectronicPayrollService) employer(ctx context.Context) (*dian.Employer, error) {
+	template, err := employerTemplate(ctx, s.templateRepo)
+	if err != nil {
+		return nil, err
+	}
+	address := strings.TrimSpace(template.CompanyAddress)
+	if address == "" {
+		address = "No informada"
+	}
+	return &dian.Employer{Name: template.CompanyName, NIT: template.CompanyNIT, Address: address}, nil
+}
+
+// employerTemplate retorna la plantilla del desprendible exigiendo razón social y NIT, que
+// identifican al empleador ante la DIAN y los operadores de seguridad social
+func employerTemplate(ctx context.Context, templateRepo domain.PayslipTemplateRepo) (*domain.PayslipTemplate, error) {
+	template, err := templateRepo.Get(ctx)
+	if errors.Is(err, domain.ErrPayslipTemplateNotFound) {
+		return nil, domain.ErrEmployerDataRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(template.CompanyName) == "" || strings.TrimSpace(template.CompanyNIT) == "" {
+		return nil, domain.ErrEmployerDataRequired
+	}
+	return template, nil
+}
+
+// Submit transmite el documento y guarda la respuesta. Un error de comunicación queda
+// registrado como transmisión con estado error y el documento sigue pendiente.
+func (s *ElectronicPayrollService) Submit(ctx context.Context, id uint) (*domain.ElectronicPayrollSubmission, error) {
+	document, err := s.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.Status != domain.ElectronicPayrollGenerated && document.Status != domain.ElectronicPayrollRejected {
+		return nil, domain.ErrElectronicPayrollNotSubmittable
+	}
+
+	result, sendErr := s.client.Send(ctx, document)
+	submission := &domain.ElectronicPayrollSubmission{
+		DocumentID:  document.ID,
+		Client:      s.client.Name(),
+		TrackID:     result.TrackID,
+		SubmittedBy: actorFromCtx(ctx),
+	}
+	switch {
+	case sendErr != nil:
+		submission.Status = domain.SubmissionError
+		submission.Messages = sendErr.Error()
+	case result.Accepted:
+		submission.Status = domain.SubmissionAccepted
+		document.Status = domain.ElectronicPayrollAccepted
+	default:
+		submission.Status = domain.SubmissionRejected
+		document.Status = domain.ElectronicPayrollRejected
+	}
+	if sendErr == nil {
+		submission.Messages = truncate(strings.Join(result.Messages, "; "), 2000)
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.documentRepo.CreateSubmission(ctx, submission); err != nil {
+			return err
+		}
+		if sendErr != nil {
+			return nil
+		}
+		return s.documentRepo.UpdateStatus(ctx, document)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// Get retorna un documento con sus transmisiones
+func (s *ElectronicPayrollService) Get(ctx context.Context, id uint) (*domain.ElectronicPayrollDocument, error) {
+	return s.documentRepo.GetByID(ctx, id)
+}
+
+// ListByPeriod retorna los documentos del mes
+func (s *ElectronicPayrollService) ListByPeriod(ctx context.Context, year, month int) ([]domain.ElectronicPayrollDocument, error) {
+	if year < 2000 || month < 1 || month > 12 {
+		return nil, domain.ErrInvalidElectronicPayrollPeriod
+	}
+	return s.documentRepo.ListByPeriod(ctx, year, month)
+}
+
+// payrollFingerprint identifica el conjunto de nóminas y sus netos para detectar cambios
+func payrollFingerprint(payrolls []domain.Payroll) string {
+	if len(payrolls) == 0 {
+		return ""
+	}
+	parts := make([]string, len(payrolls))
+	for i, p := range payrolls {
+		parts[i] = fmt.Sprintf("%d:%.2f:%.2f", p.ID, p.GrossAmount, p.NetAmount)
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, ";")))
+	return hex.EncodeToString(sum[:])
+}
+
+// paymentMethodCode traduce el método de pago a la tabla de medios de pago de la DIAN
+func paymentMethodCode(method string) string {
+	switch method {
+	case domain.PaymentMethodBankTransfer:
+		return "47"
+	case "cash":
+		return "10"
+	case "check":
+		return "20"
+	}
+	return "1"
+}
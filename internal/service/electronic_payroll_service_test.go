@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/dian"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockElectronicPayrollRepo struct {
+	mock.Mock
+}
+
+func (m *MockElectronicPayrollRepo) Create(ctx context.Context, document *domain.ElectronicPayrollDocument) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *MockElectronicPayrollRepo) GetByID(ctx context.Context, id uint) (*domain.ElectronicPayrollDocument, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ElectronicPayrollDocument), args.Error(1)
+}
+
+func (m *MockElectronicPayrollRepo) ListByPeriod(ctx context.Context, year, month int) ([]domain.ElectronicPayrollDocument, error) {
+	args := m.Called(ctx, year, month)
+	return args.Get(0).([]domain.ElectronicPayrollDocument), args.Error(1)
+}
+
+func (m *MockElectronicPayrollRepo) UpdateStatus(ctx context.Context, document *domain.ElectronicPayrollDocument) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *MockElectronicPayrollRepo) NextConsecutive(ctx context.Context, prefix string) (int, error) {
+	args := m.Called(ctx, prefix)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockElectronicPayrollRepo) CreateSubmission(ctx context.Context, submission *domain.ElectronicPayrollSubmission) error {
+	args := m.Called(ctx, submission)
+	return args.Error(0)
+}
+
+var testDianSettings = dian.Settings{
+	Environment: "2", SoftwareID: "soft-1", SoftwarePIN: "12345", ProviderName: "Proveedor SAS",
+	ProviderNIT: "900000000", DepartmentCode: "11", CityCode: "11001",
+}
+
+// electronicPayrollMocks prepara plantilla, contrato y pagos de un empleado
+type electronicPayrollMocks struct {
+	documentRepo *MockElectronicPayrollRepo
+	payrollRepo  *MockPayrollRepo
+	svc          *ElectronicPayrollService
+	created      []*domain.ElectronicPayrollDocument
+}
+
+func newElectronicPayrollMocks(ctx context.Context) *electronicPayrollMocks {
+	m := &electronicPayrollMocks{documentRepo: new(MockElectronicPayrollRepo), payrollRepo: new(MockPayrollRepo)}
+	contractRepo := new(MockContractRepo)
+	paymentRepo := new(MockPaymentRepo)
+	templateRepo := new(MockPayslipTemplateRepo)
+	templateRepo.On("Get", ctx).Return(&domain.PayslipTemplate{CompanyName: "ACME SAS", CompanyNIT: "900123456-7", CompanyAddress: "Calle 10 # 5-20"}, nil)
+	contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{StartDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), BaseSalary: 1600000},
+	}, nil)
+	paymentRepo.On("ListByPayroll", ctx, mock.Anything).Return([]domain.Payment{
+		{Method: domain.PaymentMethodBankTransfer, BankName: "Bancolombia", AccountType: "savings", AccountNumber: "00123456789", Status: domain.PaymentStatusCompleted},
+	}, nil)
+	m.documentRepo.On("NextConsecutive", ctx, mock.Anything).Return(1, nil)
+	m.documentRepo.On("Create", ctx, mock.AnythingOfType("*domain.ElectronicPayrollDocument")).
+		Run(func(args mock.Arguments) {
+			doc := args.Get(1).(*domain.ElectronicPayrollDocument)
+			doc.ID = uint(10 + len(m.created))
+			m.created = append(m.created, doc)
+		}).Return(nil)
+	m.svc = NewElectronicPayrollService(&MockTxManager{}, m.documentRepo, m.payrollRepo, contractRepo, paymentRepo,
+		templateRepo, dian.NewStubClient(), testDianSettings)
+	return m
+}
+
+func septemberPayroll(id uint, net float64) domain.Payroll {
+	return domain.Payroll{
+		ID: id, EmployeeID: 1, Status: domain.PayrollStatusPaid, Kind: domain.PayrollKindRegular,
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		PayDate:     time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		GrossAmount: net + 128000, NetAmount: net,
+		Employee: domain.Employee{ID: 1, User: domain.User{FirstName: "Ana María", LastName: "Gómez Ruiz", Dni: "12345678"}},
+		Items: []domain.PayrollItem{
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Name: "Salario", Amount: net - 200000 + 128000},
+			{Type: domain.PayrollTypeEarning, Code: domain.ConceptTransport, Name: "Auxilio de transporte", Amount: 200000},
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Name: "Salud", Amount: 64000},
+			{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Name: "Pensión", Amount: 64000},
+			{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Name: "Pensión empleador", Amount: 192000},
+		},
+	}
+}
+
+func TestCUNE(t *testing.T) {
+	cune := dian.CUNE("NE1", "2026-10-01", "10:00:00-05:00", 1600000, 128000, 1472000, "900123456", "12345678", "102", "12345", "2")
+	assert.Equal(t, "d6b5a65973fa9b96e8418e8ee136c3747cc455bb5a596e0833cef47dee5f1d602ec84da50ea1048afba50d9b81110207", cune)
+	assert.Equal(t, "4", dian.CheckDigit("800197268"))
+}
+
+func TestDianValidate_RejectsInvalidDocument(t *testing.T) {
+	err := dian.Validate(dian.TipoXMLIndividual, []byte(`<NominaIndividual xmlns="dian:gov:co:facturaelectronica:NominaIndividual">`+
+		`<Periodo FechaIngreso="15/01/2024" FechaLiquidacionInicio="2026-09-01" FechaLiquidacionFin="2026-09-30" TiempoLaborado="990.00" FechaGen="2026-10-01"/>`+
+		`<Extra/></NominaIndividual>`))
+
+	var invalid *dian.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Contains(t, invalid.Problems, `/NominaIndividual/Periodo/@FechaIngreso: value "15/01/2024" is not a date`)
+	assert.Contains(t, invalid.Problems, "/NominaIndividual: missing element NumeroSecuenciaXML")
+
+	err = dian.Validate(dian.TipoXMLAdjustment, []byte(`<NominaIndividual xmlns="dian:gov:co:facturaelectronica:NominaIndividual"/>`))
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestElectronicPayroll_Generate_IndividualDocument(t *testing.T) {
+	ctx := context.Background()
+	m := newElectronicPayrollMocks(ctx)
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{
+		septemberPayroll(5, 1672000),
+		{ID: 6, EmployeeID: 1, Status: domain.PayrollStatusDraft, Kind: domain.PayrollKindRegular},
+	}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{}, nil)
+
+	result, err := m.svc.Generate(ctx, 2026, 9)
+
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	doc := result.Documents[0]
+	assert.Equal(t, dian.TipoXMLIndividual, doc.DocumentType)
+	assert.Equal(t, "NE1", doc.Number)
+	assert.Equal(t, "5", doc.PayrollIDs)
+	assert.Equal(t, 1800000.0, doc.TotalEarnings)
+	assert.Equal(t, 128000.0, doc.TotalDeductions)
+	assert.Equal(t, 1672000.0, doc.NetAmount)
+	assert.Len(t, doc.CUNE, 96)
+	assert.NoError(t, dian.Validate(dian.TipoXMLIndividual, doc.XML))
+
+	xml := string(doc.XML)
+	issued := doc.IssuedAt.In(time.FixedZone("COT", -5*60*60))
+	expectedCUNE := dian.CUNE("NE1", issued.Format("2006-01-02"), issued.Format("15:04:05-07:00"),
+		1800000, 128000, 1672000, "900123456", "12345678", "102", "12345", "2")
+	assert.Equal(t, expectedCUNE, doc.CUNE)
+	assert.Contains(t, xml, `<Basico DiasTrabajados="30" SueldoTrabajado="1600000.00">`)
+	assert.Contains(t, xml, `<Transporte AuxilioTransporte="200000.00">`)
+	assert.Contains(t, xml, `PrimerApellido="Gómez" SegundoApellido="Ruiz" PrimerNombre="Ana" OtrosNombres="María"`)
+	assert.Contains(t, xml, `<Pago Forma="1" Metodo="47" Banco="Bancolombia"`)
+	assert.Contains(t, xml, `<Empleador RazonSocial="ACME SAS" NIT="900123456" DV="7"`)
+	assert.NotContains(t, xml, "PENSION_EMPLOYER")
+}
+
+func TestElectronicPayroll_Generate_ReplacementAndDeletionNotes(t *testing.T) {
+	ctx := context.Background()
+	accepted := domain.ElectronicPayrollDocument{
+		ID: 3, EmployeeID: 1, Year: 2026, Month: 9, DocumentType: dian.TipoXMLIndividual, Number: "NE1",
+		CUNE:        strings.Repeat("a", 96),
+		Fingerprint: payrollFingerprint([]domain.Payroll{septemberPayroll(5, 1672000)}),
+		Status:      domain.ElectronicPayrollAccepted, IssuedAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	// Sin cambios: no se genera nada
+	m := newElectronicPayrollMocks(ctx)
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{septemberPayroll(5, 1672000)}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{accepted}, nil)
+	result, err := m.svc.Generate(ctx, 2026, 9)
+	require.NoError(t, err)
+	assert.Empty(t, result.Documents)
+	assert.Equal(t, 1, result.Unchanged)
+
+	// La nómina se reversó y se reemplazó: nota de reemplazo
+	m = newElectronicPayrollMocks(ctx)
+	reversed := septemberPayroll(5, 1672000)
+	reversed.Status = domain.PayrollStatusReversed
+	replacement := septemberPayroll(7, 1700000)
+	replacement.Kind = domain.PayrollKindReplacement
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{reversed, replacement}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{accepted}, nil)
+	result, err = m.svc.Generate(ctx, 2026, 9)
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	note := result.Documents[0]
+	assert.Equal(t, dian.TipoXMLAdjustment, note.DocumentType)
+	assert.Equal(t, dian.NoteReplace, note.NoteType)
+	assert.Equal(t, "NA1", note.Number)
+	assert.Equal(t, uint(3), *note.PredecessorID)
+	assert.Equal(t, "7", note.PayrollIDs)
+	assert.Equal(t, 1700000.0, note.NetAmount)
+	assert.Contains(t, string(note.XML), `<ReemplazandoPredecesor NumeroPred="NE1" CUNEPred="`+accepted.CUNE+`" FechaGenPred="2026-10-01">`)
+	assert.NoError(t, dian.Validate(dian.TipoXMLAdjustment, note.XML))
+
+	// Reverso sin reemplazo: nota de eliminación sin valores
+	m = newElectronicPayrollMocks(ctx)
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{reversed}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{accepted}, nil)
+	result, err = m.svc.Generate(ctx, 2026, 9)
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	deletion := result.Documents[0]
+	assert.Equal(t, dian.NoteDelete, deletion.NoteType)
+	assert.Equal(t, 0.0, deletion.NetAmount)
+	assert.Contains(t, string(deletion.XML), `<EliminandoPredecesor NumeroPred="NE1"`)
+	assert.NotContains(t, string(deletion.XML), "<Trabajador")
+	assert.NoError(t, dian.Validate(dian.TipoXMLAdjustment, deletion.XML))
+}
+
+func TestElectronicPayroll_Generate_SupersedesPendingDocument(t *testing.T) {
+	ctx := context.Background()
+	m := newElectronicPayrollMocks(ctx)
+	pending := domain.ElectronicPayrollDocument{
+		ID: 3, EmployeeID: 1, Year: 2026, Month: 9, DocumentType: dian.TipoXMLIndividual, Number: "NE1",
+		Fingerprint: "old", Status: domain.ElectronicPayrollGenerated,
+	}
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{septemberPayroll(5, 1672000)}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{pending}, nil)
+	m.documentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.ElectronicPayrollDocument")).Return(nil)
+
+	result, err := m.svc.Generate(ctx, 2026, 9)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Superseded)
+	require.Len(t, result.Documents, 1)
+	// Nunca fue aceptado: se emite un documento nuevo, no una nota
+	assert.Equal(t, dian.TipoXMLIndividual, result.Documents[0].DocumentType)
+	m.documentRepo.AssertCalled(t, "UpdateStatus", ctx, mock.MatchedBy(func(d *domain.ElectronicPayrollDocument) bool {
+		return d.ID == 3 && d.Status == domain.ElectronicPayrollSuperseded
+	}))
+}
+
+func TestElectronicPayroll_Generate_RequiresEmployerData(t *testing.T) {
+	ctx := context.Background()
+	templateRepo := new(MockPayslipTemplateRepo)
+	templateRepo.On("Get", ctx).Return(nil, domain.ErrPayslipTemplateNotFound)
+	svc := NewElectronicPayrollService(&MockTxManager{}, new(MockElectronicPayrollRepo), new(MockPayrollRepo), new(MockContractRepo),
+		new(MockPaymentRepo), templateRepo, dian.NewStubClient(), testDianSettings)
+
+	_, err := svc.Generate(ctx, 2026, 9)
+
+	assert.ErrorIs(t, err, domain.ErrEmployerDataRequired)
+}
+
+func TestElectronicPayroll_Submit(t *testing.T) {
+	ctx := withActor(context.Background(), 4)
+	m := newElectronicPayrollMocks(ctx)
+	m.payrollRepo.On("GetByPeriod", ctx, mock.Anything, mock.Anything).Return([]domain.Payroll{septemberPayroll(5, 1672000)}, nil)
+	m.documentRepo.On("ListByPeriod", ctx, 2026, 9).Return([]domain.ElectronicPayrollDocument{}, nil)
+	result, err := m.svc.Generate(ctx, 2026, 9)
+	require.NoError(t, err)
+	doc := result.Documents[0]
+
+	m.documentRepo.On("GetByID", ctx, doc.ID).Return(&doc, nil)
+	m.documentRepo.On("CreateSubmission", ctx, mock.AnythingOfType("*domain.ElectronicPayrollSubmission")).Return(nil)
+	m.documentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.ElectronicPayrollDocument")).Return(nil)
+
+	submission, err := m.svc.Submit(ctx, doc.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SubmissionAccepted, submission.Status)
+	assert.Equal(t, "stub", submission.Client)
+	assert.Equal(t, doc.CUNE, submission.TrackID)
+	assert.Equal(t, uint(4), submission.SubmittedBy)
+	assert.Equal(t, domain.ElectronicPayrollAccepted, doc.Status)
+
+	// Un documento aceptado no se vuelve a transmitir
+	_, err = m.svc.Submit(ctx, doc.ID)
+	assert.ErrorIs(t, err, domain.ErrElectronicPayrollNotSubmittable)
+
+	// El stub rechaza un XML que no cumple el esquema
+	broken := doc
+	broken.Status = domain.ElectronicPayrollGenerated
+	broken.XML = []byte(`<NominaIndividual xmlns="dian:gov:co:facturaelectronica:NominaIndividual"/>`)
+	m.documentRepo.On("GetByID", ctx, uint(99)).Return(&broken, nil)
+	submission, err = m.svc.Submit(ctx, 99)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SubmissionRejected, submission.Status)
+	assert.Contains(t, submission.Messages, "missing element Periodo")
+	assert.Equal(t, domain.ElectronicPayrollRejected, broken.Status)
+}
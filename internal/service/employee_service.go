@@ -8,19 +8,32 @@ import (
 )
 
 type EmployeeService struct {
-	empRepo domain.EmployeeRepo
+	empRepo  domain.EmployeeRepo
+	scopeSvc *DataScopeService
 }
 
-func NewEmployeeService(u domain.EmployeeRepo) *EmployeeService {
+func NewEmployeeService(u domain.EmployeeRepo, scopeSvc *DataScopeService) *EmployeeService {
 	return &EmployeeService{
-		empRepo: u,
+		empRepo:  u,
+		scopeSvc: scopeSvc,
 	}
 }
 
+// OrgChartNode es un empleado del organigrama con sus subordinados directos
+type OrgChartNode struct {
+	Employee      *domain.Employee
+	DirectReports int
+	TotalReports  int
+	Reports       []*OrgChartNode
+}
+
 func (s *EmployeeService) Create(ctx context.Context, emp *domain.Employee) error {
 	if emp == nil {
 		return errors.New("employee cannot be nil")
 	}
+	if err := s.checkManager(ctx, emp); err != nil {
+		return err
+	}
 	return s.empRepo.Create(ctx, emp)
 }
 
@@ -38,7 +51,12 @@ func (s *EmployeeService) GetByUserID(ctx context.Context, userID uint) (*domain
 	return s.empRepo.GetByUserID(ctx, userID)
 }
 
+// List lista los empleados visibles para el actor según su alcance de datos
 func (s *EmployeeService) List(ctx context.Context, page, limit int) ([]domain.Employee, int64, error) {
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourceEmployees)
+	if err != nil {
+		return nil, 0, err
+	}
 	return s.empRepo.List(ctx, page, limit)
 }
 
@@ -49,6 +67,9 @@ func (s *EmployeeService) Update(ctx context.Context, emp *domain.Employee) erro
 	if emp.ID == 0 {
 		return errors.New("invalid employee id")
 	}
+	if err := s.checkManager(ctx, emp); err != nil {
+		return err
+	}
 	return s.empRepo.Update(ctx, emp)
 }
 
@@ -58,3 +79,135 @@ func (s *EmployeeService) Delete(ctx context.Context, id uint) error {
 	}
 	return s.empRepo.Delete(ctx, id)
 }
+
+// SetManager asigna el jefe directo del empleado; nil lo deja sin jefe
+func (s *EmployeeService) SetManager(ctx context.Context, employeeID uint, managerID *uint) (*domain.Employee, error) {
+	emp, err := s.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	emp.ManagerID = managerID
+	if err := s.Update(ctx, emp); err != nil {
+		return nil, err
+	}
+	return emp, nil
+}
+
+// OrgChart arma el árbol de reporte de los empleados activos visibles para el actor. Los
+// empleados cuyo jefe no es visible quedan como raíces. rootID distinto de 0 retorna solo
+// el subárbol de ese empleado.
+func (s *EmployeeService) OrgChart(ctx context.Context, rootID uint) ([]*OrgChartNode, error) {
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourceEmployees)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := allActiveEmployees(ctx, s.empRepo)
+	if err != nil {
+		return nil, err
+	}
+	scope := employeeScopeFromCtx(ctx)
+
+	nodes := make(map[uint]*OrgChartNode, len(employees))
+	var order []uint
+	for i := range employees {
+		if scope != nil && !scope.Allows(employees[i].ID) {
+			continue
+		}
+		nodes[employees[i].ID] = &OrgChartNode{Employee: &employees[i], Reports: []*OrgChartNode{}}
+		order = append(order, employees[i].ID)
+	}
+
+	roots := []*OrgChartNode{}
+	for _, id := range order {
+		node := nodes[id]
+		if managerID := node.Employee.ManagerID; managerID != nil {
+			if manager, ok := nodes[*managerID]; ok {
+				manager.Reports = append(manager.Reports, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	for _, root := range roots {
+		countReports(root)
+	}
+
+	if rootID != 0 {
+		node, ok := nodes[rootID]
+		if !ok {
+			return nil, domain.ErrEmployeeNotFound
+		}
+		return []*OrgChartNode{node}, nil
+	}
+	return roots, nil
+}
+
+// countReports calcula los subordinados directos e indirectos de cada nodo
+func countReports(node *OrgChartNode) int {
+	node.DirectReports = len(node.Reports)
+	node.TotalReports = 0
+	for _, report := range node.Reports {
+		node.TotalReports += 1 + countReports(report)
+	}
+	return node.TotalReports
+}
+
+// checkManager valida que el jefe exista, esté activo y que la asignación no forme un ciclo
+func (s *EmployeeService) checkManager(ctx context.Context, emp *domain.Employee) error {
+	if emp.ManagerID != nil && *emp.ManagerID == 0 {
+		emp.ManagerID = nil
+	}
+	if emp.ManagerID == nil {
+		return nil
+	}
+	if emp.ID != 0 && *emp.ManagerID == emp.ID {
+		return domain.ErrManagerCycle
+	}
+	manager, err := s.empRepo.GetByID(ctx, *emp.ManagerID)
+	if err != nil {
+		return err
+	}
+	if !manager.IsActive {
+		return domain.ErrEmployeeInactive
+	}
+	if emp.ID == 0 {
+		return nil
+	}
+
+	// Subir por la cadena de jefes: si aparece el empleado, el jefe es su subordinado
+	visited := map[uint]bool{manager.ID: true}
+	for current := manager; current.ManagerID != nil; {
+		nextID := *current.ManagerID
+		if nextID == emp.ID {
+			return domain.ErrManagerCycle
+		}
+		if visited[nextID] {
+			return nil
+		}
+		visited[nextID] = true
+		current, err = s.empRepo.GetByID(ctx, nextID)
+		if err != nil {
+			if errors.Is(err, domain.ErrEmployeeNotFound) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// allActiveEmployees recorre todas las páginas de empleados activos con su contrato activo
+func allActiveEmployees(ctx context.Context, employeeRepo domain.EmployeeRepo) ([]domain.Employee, error) {
+	const pageSize = 1000
+	var employees []domain.Employee
+	for page := 1; ; page++ {
+		batch, total, err := employeeRepo.ListActive(ctx, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, batch...)
+		if len(batch) < pageSize || int64(len(employees)) >= total {
+			return employees, nil
+		}
+	}
+}
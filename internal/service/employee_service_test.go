@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEmployeeService_SetManager_RejectsCycle(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockEmployeeRepo)
+	svc := NewEmployeeService(repo, nil)
+
+	// 1 es jefe de 2 y 2 es jefe de 3: asignar 3 como jefe de 1 cierra el ciclo
+	repo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	repo.On("GetByID", ctx, uint(2)).Return(&domain.Employee{ID: 2, ManagerID: uintPtr(1), IsActive: true}, nil)
+	repo.On("GetByID", ctx, uint(3)).Return(&domain.Employee{ID: 3, ManagerID: uintPtr(2), IsActive: true}, nil)
+
+	_, err := svc.SetManager(ctx, 1, uintPtr(3))
+
+	assert.ErrorIs(t, err, domain.ErrManagerCycle)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestEmployeeService_SetManager_RejectsSelf(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockEmployeeRepo)
+	svc := NewEmployeeService(repo, nil)
+
+	repo.On("GetByID", ctx, uint(4)).Return(&domain.Employee{ID: 4, IsActive: true}, nil)
+
+	_, err := svc.SetManager(ctx, 4, uintPtr(4))
+
+	assert.ErrorIs(t, err, domain.ErrManagerCycle)
+}
+
+func TestEmployeeService_OrgChart(t *testing.T) {
+	scopeSvc, repo, ctx := newDataScopeService(roleWithPermission(domain.ResourceEmployees, DataScopeAction))
+	svc := NewEmployeeService(repo, scopeSvc)
+
+	repo.On("ListActive", ctx, 1, 1000).Return([]domain.Employee{
+		{ID: 1},
+		{ID: 2, ManagerID: uintPtr(1)},
+		{ID: 3, ManagerID: uintPtr(2)},
+		{ID: 4, ManagerID: uintPtr(1)},
+		{ID: 5, ManagerID: uintPtr(9)}, // jefe inactivo: queda como raíz
+	}, int64(5), nil)
+
+	chart, err := svc.OrgChart(ctx, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, chart, 2)
+	assert.Equal(t, uint(1), chart[0].Employee.ID)
+	assert.Equal(t, 2, chart[0].DirectReports)
+	assert.Equal(t, 3, chart[0].TotalReports)
+	assert.Equal(t, uint(5), chart[1].Employee.ID)
+
+	subtree, err := svc.OrgChart(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, subtree, 1)
+	assert.Equal(t, 1, subtree[0].TotalReports)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/ledger"
+)
+
+// journalPayrollStatuses son los estados de las nóminas que se contabilizan; las reversadas
+// se incluyen porque su nómina de reverso anula el asiento original
+var journalPayrollStatuses = []string{
+	domain.PayrollStatusApproved, domain.PayrollStatusPartiallyPaid,
+	domain.PayrollStatusPaid, domain.PayrollStatusReversed,
+}
+
+// JournalService genera el asiento contable de causación de nómina a partir de los items y
+// de las cuentas configuradas en cada concepto
+type JournalService struct {
+	payrollRepo domain.PayrollRepo
+	conceptRepo domain.PayrollConceptRepo
+}
+
+func NewJournalService(payrollRepo domain.PayrollRepo, conceptRepo domain.PayrollConceptRepo) *JournalService {
+	return &JournalService{
+		payrollRepo: payrollRepo,
+		conceptRepo: conceptRepo,
+	}
+}
+
+// ForPeriod contabiliza las nóminas cuyo periodo está dentro del rango
+func (s *JournalService) ForPeriod(ctx context.Context, start, end time.Time) (*ledger.Entry, error) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return nil, domain.ErrInvalidJournalPeriod
+	}
+	payrolls, err := s.payrollRepo.GetByPeriod(ctx, start, end.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	entry := ledger.NewEntry(
+		fmt.Sprintf("NOM-%s-%s", start.Format("20060102"), end.Format("20060102")),
+		end,
+		fmt.Sprintf("Causación nómina %s a %s", start.Format("2006-01-02"), end.Format("2006-01-02")),
+	)
+	return s.build(ctx, entry, payrolls)
+}
+
+// ForPayrolls contabiliza las nóminas de una corrida; la fecha del asiento es la última
+// fecha de pago
+func (s *JournalService) ForPayrolls(ctx context.Context, ids []uint) (*ledger.Entry, error) {
+	payrolls := make([]domain.Payroll, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		payroll, err := s.payrollRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		payrolls = append(payrolls, *payroll)
+	}
+	var date time.Time
+	for _, p := range payrolls {
+		if p.PayDate.After(date) {
+			date = p.PayDate
+		}
+	}
+	entry := ledger.NewEntry(
+		fmt.Sprintf("NOM-RUN-%s", date.Format("20060102")),
+		date,
+		fmt.Sprintf("Causación corrida de nómina (%d nóminas)", len(payrolls)),
+	)
+	return s.build(ctx, entry, payrolls)
+}
+
+// build registra cada item contra las cuentas de su concepto. Los devengos y aportes llevan al
+// gasto los centros de costo del reparto del item, o el del concepto si no tiene reparto; el empleado es el tercero del pasivo de salarios por pagar y la
+// EPS o el fondo de pensiones el del pasivo de sus aportes.
+func (s *JournalService) build(ctx context.Context, entry *ledger.Entry, payrolls []domain.Payroll) (*ledger.Entry, error) {
+	concepts, err := s.conceptRepo.GetActiveConcepts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]domain.PayrollConcept, len(concepts))
+	for _, c := range concepts {
+		byCode[c.Code] = c
+	}
+	defaults := domain.DefaultConceptAccounts()
+
+	posted := 0
+	var missing []string
+	for _, p := range payrolls {
+		if !slices.Contains(journalPayrollStatuses, p.Status) {
+			continue
+		}
+		posted++
+		employee := strings.TrimSpace(p.Employee.User.Dni)
+		for _, item := range p.Items {
+			// El pago previo de una nómina de reemplazo ya quedó cruzado por el reverso
+			if item.Code == domain.ConceptPriorPayment {
+				continue
+			}
+			code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+			accounts := defaults[code]
+			var costCenter string
+			description := item.Name
+			if concept, ok := byCode[code]; ok {
+				if concept.DebitAccount != "" {
+					accounts.Debit = concept.DebitAccount
+				}
+				if concept.CreditAccount != "" {
+					accounts.Credit = concept.CreditAccount
+				}
+				costCenter = concept.CostCenter
+				description = concept.Name
+			}
+			if accounts.Debit == "" || accounts.Credit == "" {
+				if !slices.Contains(missing, code) {
+					missing = append(missing, code)
+				}
+				continue
+			}
+
+			debit := ledger.Posting{Account: accounts.Debit, Description: description}
+			credit := ledger.Posting{Account: accounts.Credit, Description: description}
+			switch item.Type {
+			case domain.PayrollTypeEarning:
+				credit.ThirdParty = employee
+			case domain.PayrollTypeDeduction:
+				debit.ThirdParty = employee
+				credit.ThirdParty = socialSecurityEntity(code, &p.Employee)
+				entry.Post(debit, credit, item.Amount)
+				continue
+			case domain.PayrollTypeEmployerContribution:
+				credit.ThirdParty = socialSecurityEntity(code, &p.Employee)
+			}
+			// El gasto se reparte según los centros de costo del empleado; lo no asignado queda
+			// en el centro de costo del concepto
+			remaining := item.Amount
+			for _, a := range item.Allocations {
+				debit.CostCenter = a.CostCenterCode
+				entry.Post(debit, credit, a.Amount)
+				remaining = roundCents(remaining - a.Amount)
+			}
+			if remaining != 0 {
+				debit.CostCenter = costCenter
+				entry.Post(debit, credit, remaining)
+			}
+		}
+	}
+	if posted == 0 {
+		return nil, domain.ErrNoPayrollsForJournal
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrConceptAccountsMissing, strings.Join(missing, ", "))
+	}
+	if !entry.Balanced() {
+		debit, credit := entry.Totals()
+		return nil, fmt.Errorf("%w: debits %.2f, credits %.2f", domain.ErrJournalUnbalanced, debit, credit)
+	}
+	return entry, nil
+}
+
+// socialSecurityEntity retorna el código de la EPS o del fondo de pensiones que recibe el aporte
+func socialSecurityEntity(code string, employee *domain.Employee) string {
+	switch code {
+	case domain.ConceptHealth, domain.ConceptHealthEmployer:
+		return employee.HealthFundCode
+	case domain.ConceptPension, domain.ConceptPensionEmployer:
+		return employee.PensionFundCode
+	}
+	return ""
+}
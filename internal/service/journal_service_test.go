@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/ledger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func journalPayroll(id uint, status string, items ...domain.PayrollItem) domain.Payroll {
+	return domain.Payroll{
+		ID: id, EmployeeID: 1, Status: status, Kind: domain.PayrollKindRegular,
+		PeriodStart: pilaDate(9, 1), PeriodEnd: pilaDate(9, 30), PayDate: pilaDate(9, 30),
+		Employee: domain.Employee{
+			ID: 1, HealthFundCode: "EPS037", PensionFundCode: "230301",
+			User: domain.User{Dni: "12345678"},
+		},
+		Items: items,
+	}
+}
+
+func findLine(entry *ledger.Entry, account, thirdParty string, debit bool) *ledger.Line {
+	for i, l := range entry.Lines {
+		if l.Account == account && l.ThirdParty == thirdParty && (l.Debit > 0) == debit {
+			return &entry.Lines[i]
+		}
+	}
+	return nil
+}
+
+func TestJournalService_ForPeriod_BalancedEntry(t *testing.T) {
+	ctx := context.Background()
+	payrollRepo := new(MockPayrollRepo)
+	conceptRepo := new(MockConceptRepo)
+	payrollRepo.On("GetByPeriod", ctx, pilaDate(9, 1), mock.AnythingOfType("time.Time")).Return([]domain.Payroll{
+		journalPayroll(5, domain.PayrollStatusPaid,
+			domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Name: "Salario", Amount: 2000000},
+			domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptTransport, Name: "Transporte", Amount: 200000},
+			domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: "RETRO_BASE_SALARY", Name: "Retro salario", Amount: 50000},
+			domain.PayrollItem{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Name: "Salud", Amount: 80000},
+			domain.PayrollItem{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Name: "Pensión", Amount: 80000},
+			domain.PayrollItem{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPriorPayment, Name: "Pago previo", Amount: 900000},
+			domain.PayrollItem{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptHealthEmployer, Name: "Salud empleador", Amount: 170000},
+			domain.PayrollItem{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Name: "Pensión empleador", Amount: 240000},
+		),
+		// Los borradores no se contabilizan
+		journalPayroll(6, domain.PayrollStatusDraft,
+			domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 999999}),
+	}, nil)
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{Code: domain.ConceptBaseSalary, Name: "Salario Base", DebitAccount: "510503", CostCenter: "ADM"},
+	}, nil)
+	svc := NewJournalService(payrollRepo, conceptRepo)
+
+	entry, err := svc.ForPeriod(ctx, pilaDate(9, 1), pilaDate(9, 30))
+
+	require.NoError(t, err)
+	assert.Equal(t, "NOM-20260901-20260930", entry.Reference)
+	debit, credit := entry.Totals()
+	assert.Equal(t, 2820000.0, debit)
+	assert.Equal(t, 2820000.0, credit)
+	assert.True(t, entry.Balanced())
+
+	// Gasto con la cuenta y el centro de costo del concepto, acumulando el retroactivo
+	salary := findLine(entry, "510503", "", true)
+	require.NotNil(t, salary)
+	assert.Equal(t, 2050000.0, salary.Debit)
+	assert.Equal(t, "ADM", salary.CostCenter)
+	assert.Equal(t, "Salario Base", salary.Description)
+
+	// Pasivo con el empleado: devengos menos deducciones
+	assert.Equal(t, 2250000.0, findLine(entry, "250505", "12345678", false).Credit)
+	assert.Equal(t, 160000.0, findLine(entry, "250505", "12345678", true).Debit)
+
+	// Pasivo con la EPS y el fondo de pensiones
+	assert.Equal(t, 250000.0, findLine(entry, "237005", "EPS037", false).Credit)
+	assert.Equal(t, 320000.0, findLine(entry, "238030", "230301", false).Credit)
+
+	content, err := entry.CSV()
+	require.NoError(t, err)
+	rows := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Equal(t, "reference,date,account,cost_center,third_party,description,debit,credit", rows[0])
+	assert.Equal(t, "NOM-20260901-20260930,2026-09-30,510503,ADM,,Salario Base,2050000.00,0.00", rows[1])
+	assert.Equal(t, "TOTAL,2026-09-30,,,,8,2820000.00,2820000.00", rows[len(rows)-1])
+}
+
+func TestJournalService_ForPayrolls_ReversalAndMissingAccounts(t *testing.T) {
+	ctx := context.Background()
+	payrollRepo := new(MockPayrollRepo)
+	conceptRepo := new(MockConceptRepo)
+	reversal := journalPayroll(8, domain.PayrollStatusPaid,
+		domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: -1000000},
+		domain.PayrollItem{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: -40000})
+	reversal.Kind = domain.PayrollKindReversal
+	payrollRepo.On("GetByID", ctx, uint(8)).Return(&reversal, nil)
+	commission := journalPayroll(9, domain.PayrollStatusApproved,
+		domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: "COMMISSION", Amount: 300000})
+	commission.PayDate = pilaDate(10, 5)
+	payrollRepo.On("GetByID", ctx, uint(9)).Return(&commission, nil)
+
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{}, nil).Once()
+	svc := NewJournalService(payrollRepo, conceptRepo)
+
+	_, err := svc.ForPayrolls(ctx, []uint{8, 9, 8})
+	assert.ErrorIs(t, err, domain.ErrConceptAccountsMissing)
+	assert.Contains(t, err.Error(), "COMMISSION")
+
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{Code: "COMMISSION", Name: "Comisiones", DebitAccount: "510518", CreditAccount: "250505"},
+	}, nil)
+	entry, err := svc.ForPayrolls(ctx, []uint{8, 9, 8})
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), entry.Date)
+	// El reverso invierte la naturaleza: el gasto queda al crédito y el pasivo al débito
+	assert.Equal(t, 1000000.0, findLine(entry, "510506", "", false).Credit)
+	assert.Equal(t, 1000000.0, findLine(entry, "250505", "12345678", true).Debit)
+	assert.Equal(t, 40000.0, findLine(entry, "237005", "EPS037", true).Debit)
+	assert.Equal(t, 300000.0, findLine(entry, "510518", "", true).Debit)
+	assert.True(t, entry.Balanced())
+	payrollRepo.AssertNumberOfCalls(t, "GetByID", 4)
+}
+
+func TestJournalService_CostCenterAllocations(t *testing.T) {
+	ctx := context.Background()
+	payrollRepo := new(MockPayrollRepo)
+	conceptRepo := new(MockConceptRepo)
+	payroll := journalPayroll(5, domain.PayrollStatusPaid,
+		domain.PayrollItem{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000,
+			Allocations: []domain.PayrollItemAllocation{
+				{CostCenterID: 1, CostCenterCode: "ADM", Percentage: 60, Amount: 1200000},
+				{CostCenterID: 2, CostCenterCode: "PRY", Percentage: 30, Amount: 600000},
+			}},
+		domain.PayrollItem{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 80000},
+		domain.PayrollItem{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 240000,
+			Allocations: []domain.PayrollItemAllocation{
+				{CostCenterID: 1, CostCenterCode: "ADM", Percentage: 60, Amount: 144000},
+				{CostCenterID: 2, CostCenterCode: "PRY", Percentage: 40, Amount: 96000},
+			}},
+	)
+	payrollRepo.On("GetByID", ctx, uint(5)).Return(&payroll, nil)
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{Code: domain.ConceptBaseSalary, Name: "Salario Base", CostCenter: "GEN"},
+	}, nil)
+	svc := NewJournalService(payrollRepo, conceptRepo)
+
+	entry, err := svc.ForPayrolls(ctx, []uint{5})
+
+	require.NoError(t, err)
+	assert.True(t, entry.Balanced())
+	debits := make(map[string]float64)
+	for _, l := range entry.Lines {
+		if l.Debit > 0 && l.ThirdParty == "" {
+			debits[l.Account+"/"+l.CostCenter] += l.Debit
+		}
+	}
+	// Cada centro lleva su parte del gasto y lo no repartido queda en el centro del concepto
+	assert.Equal(t, map[string]float64{
+		"510506/ADM": 1200000, "510506/PRY": 600000, "510506/GEN": 200000,
+		"510570/ADM": 144000, "510570/PRY": 96000,
+	}, debits)
+	// El pasivo con el empleado no se parte por centro de costo
+	assert.Equal(t, 2000000.0, findLine(entry, "250505", "12345678", false).Credit)
+}
+
+func TestJournalService_Errors(t *testing.T) {
+	ctx := context.Background()
+	payrollRepo := new(MockPayrollRepo)
+	conceptRepo := new(MockConceptRepo)
+	payrollRepo.On("GetByPeriod", ctx, pilaDate(9, 1), mock.AnythingOfType("time.Time")).Return([]domain.Payroll{
+		journalPayroll(6, domain.PayrollStatusCalculated),
+	}, nil)
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{}, nil)
+	svc := NewJournalService(payrollRepo, conceptRepo)
+
+	_, err := svc.ForPeriod(ctx, pilaDate(9, 30), pilaDate(9, 1))
+	assert.ErrorIs(t, err, domain.ErrInvalidJournalPeriod)
+
+	_, err = svc.ForPeriod(ctx, pilaDate(9, 1), pilaDate(9, 30))
+	assert.ErrorIs(t, err, domain.ErrNoPayrollsForJournal)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// dispatchBatchSize limita las notificaciones que se procesan en cada ronda
+const dispatchBatchSize = 50
+
+// maxRetryBackoff es la espera máxima entre reintentos de una notificación
+const maxRetryBackoff = 6 * time.Hour
+
+// NotificationService despacha el outbox de notificaciones y expone su estado de entrega
+type NotificationService struct {
+	outboxRepo  domain.NotificationOutboxRepo
+	payrollRepo domain.PayrollRepo
+	payslipSvc  *PayslipService
+	mailer      domain.Mailer
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewNotificationService(
+	outboxRepo domain.NotificationOutboxRepo,
+	payrollRepo domain.PayrollRepo,
+	payslipSvc *PayslipService,
+	mailer domain.Mailer,
+	maxAttempts int,
+	backoff time.Duration,
+) *NotificationService {
+	return &NotificationService{
+		outboxRepo:  outboxRepo,
+		payrollRepo: payrollRepo,
+		payslipSvc:  payslipSvc,
+		mailer:      mailer,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// ListByPayroll retorna el estado de entrega de las notificaciones de una nómina
+func (s *NotificationService) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.NotificationOutbox, error) {
+	if _, err := s.payrollRepo.GetByID(ctx, payrollID); err != nil {
+		return nil, err
+	}
+	return s.outboxRepo.ListByPayroll(ctx, payrollID)
+}
+
+// RetryFailed vuelve a encolar las notificaciones fallidas de una nómina
+func (s *NotificationService) RetryFailed(ctx context.Context, payrollID uint) (int64, error) {
+	if _, err := s.payrollRepo.GetByID(ctx, payrollID); err != nil {
+		return 0, err
+	}
+	return s.outboxRepo.RetryFailed(ctx, payrollID)
+}
+
+// Run despacha el outbox cada interval hasta que se cancele el contexto
+func (s *NotificationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ notification dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch envía las notificaciones vencidas de todos los tenants y retorna cuántas se
+// enviaron. Un envío fallido se reintenta con espera exponencial hasta maxAttempts.
+func (s *NotificationService) Dispatch(ctx context.Context) (int, error) {
+	if s.mailer == nil {
+		return 0, domain.ErrMailerNotConfigured
+	}
+	due, err := s.outboxRepo.ListDue(ctx, time.Now(), dispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range due {
+		notification := &due[i]
+		// Cada notificación se procesa con el tenant al que pertenece
+		tenantCtx := context.WithValue(ctx, domain.TenantIDKey, notification.TenantID)
+
+		deliveryErr := s.deliver(tenantCtx, notification)
+		now := time.Now()
+		notification.Attempts++
+		switch {
+		case deliveryErr == nil:
+			notification.Status = domain.NotificationSent
+			notification.SentAt = &now
+			notification.LastError = ""
+			sent++
+		case isPermanentDeliveryError(deliveryErr) || notification.Attempts >= s.maxAttempts:
+			notification.Status = domain.NotificationFailed
+			notification.LastError = truncate(deliveryErr.Error(), 500)
+		default:
+			notification.NextAttemptAt = now.Add(s.retryDelay(notification.Attempts))
+			notification.LastError = truncate(deliveryErr.Error(), 500)
+		}
+		if err := s.outboxRepo.UpdateDelivery(tenantCtx, notification); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// deliver genera el desprendible y lo envía al correo del empleado
+func (s *NotificationService) deliver(ctx context.Context, notification *domain.NotificationOutbox) error {
+	if notification.Kind != domain.NotificationKindPayslipEmail {
+		return fmt.Errorf("unknown notification kind %q", notification.Kind)
+	}
+	payroll, err := s.payrollRepo.GetByID(ctx, notification.PayrollID)
+	if err != nil {
+		return err
+	}
+	payslip, err := s.payslipSvc.Render(ctx, notification.PayrollID)
+	if err != nil {
+		return err
+	}
+
+	period := fmt.Sprintf("%s al %s", payroll.PeriodStart.Format("2006-01-02"), payroll.PeriodEnd.Format("2006-01-02"))
+	return s.mailer.Send(ctx, domain.EmailMessage{
+		To:      notification.Recipient,
+		Subject: "Desprendible de nómina " + period,
+		Body: fmt.Sprintf("Hola %s,\r\n\r\nAdjuntamos tu desprendible de nómina del periodo %s.\r\n\r\nNeto pagado: %s\r\n",
+			payroll.Employee.User.FirstName, period, formatCOP(payroll.NetAmount)),
+		Attachments: []domain.EmailAttachment{{
+			FileName:    payslip.FileName,
+			ContentType: "application/pdf",
+			Content:     payslip.Content,
+		}},
+	})
+}
+
+// retryDelay duplica la espera con cada intento: backoff, 2×backoff, 4×backoff...
+func (s *NotificationService) retryDelay(attempts int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// isPermanentDeliveryError indica errores que no se corrigen reintentando
+func isPermanentDeliveryError(err error) bool {
+	return errors.Is(err, domain.ErrPayrollNotFound) || errors.Is(err, domain.ErrPayslipNotAvailable)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	smtpmail "github.com/arrase21/crm-users/internal/mail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockNotificationOutboxRepo struct {
+	mock.Mock
+}
+
+func (m *MockNotificationOutboxRepo) Create(ctx context.Context, notification *domain.NotificationOutbox) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationOutboxRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.NotificationOutbox, error) {
+	args := m.Called(ctx, payrollID)
+	return args.Get(0).([]domain.NotificationOutbox), args.Error(1)
+}
+
+func (m *MockNotificationOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.NotificationOutbox, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]domain.NotificationOutbox), args.Error(1)
+}
+
+func (m *MockNotificationOutboxRepo) UpdateDelivery(ctx context.Context, notification *domain.NotificationOutbox) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationOutboxRepo) RetryFailed(ctx context.Context, payrollID uint) (int64, error) {
+	args := m.Called(ctx, payrollID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// newOutboxRepoMock acepta cualquier notificación encolada
+func newOutboxRepoMock() *MockNotificationOutboxRepo {
+	outboxRepo := new(MockNotificationOutboxRepo)
+	outboxRepo.On("ListByPayroll", mock.Anything, mock.Anything).Return([]domain.NotificationOutbox{}, nil)
+	outboxRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.NotificationOutbox")).Return(nil)
+	return outboxRepo
+}
+
+// fakeSMTPServer es un servidor SMTP mínimo en localhost que guarda los mensajes recibidos
+type fakeSMTPServer struct {
+	listener   net.Listener
+	rejectRcpt bool
+
+	mu       sync.Mutex
+	messages []fakeSMTPMessage
+}
+
+type fakeSMTPMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) mailer() *smtpmail.SMTPMailer {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return smtpmail.NewSMTPMailer(host, port, "", "", "nomina@acme.test")
+}
+
+func (s *fakeSMTPServer) received() []fakeSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeSMTPMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake ESMTP")
+	var msg fakeSMTPMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = fakeSMTPMessage{from: strings.TrimSpace(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if s.rejectRcpt {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			msg.to = append(msg.to, strings.TrimSpace(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// newDispatchMocks prepara una nómina pagada lista para generar su desprendible
+func newDispatchMocks() (*MockPayrollRepo, *PayslipService) {
+	payrollRepo := new(MockPayrollRepo)
+	contractRepo := new(MockContractRepo)
+	templateRepo := new(MockPayslipTemplateRepo)
+	payroll := &domain.Payroll{
+		ID: 8, EmployeeID: 1, Status: domain.PayrollStatusPaid,
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		PayDate:     time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		GrossAmount: 1600000, TotalDeductions: 100000, NetAmount: 1500000,
+		Employee: domain.Employee{ID: 1, User: domain.User{FirstName: "Ana", LastName: "Gómez", Dni: "12345678", Email: "ana@acme.test"}},
+		Items: []domain.PayrollItem{
+			{Type: "earning", Code: "SALARY", Name: "Salario", Amount: 1600000},
+			{Type: "deduction", Code: "HEALTH_EMPLOYEE", Name: "Salud", Amount: 100000},
+		},
+	}
+	payrollRepo.On("GetByID", mock.Anything, uint(8)).Return(payroll, nil)
+	contractRepo.On("ListByEmployee", mock.Anything, uint(1)).Return([]domain.EmployeeContract{}, nil)
+	templateRepo.On("Get", mock.Anything).Return(nil, domain.ErrPayslipTemplateNotFound)
+	payslipSvc := NewPayslipService(payrollRepo, contractRepo, new(MockDepartmentRepo), new(MockPositionRepo), templateRepo)
+	return payrollRepo, payslipSvc
+}
+
+func TestPayrollStateService_MarkAsPaid_EnqueuesPayslipEmail(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	outboxRepo := new(MockNotificationOutboxRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, outboxRepo, newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{
+		ID: 3, EmployeeID: 1, Status: domain.PayrollStatusApproved, NetAmount: 1000000,
+		Employee: domain.Employee{ID: 1, User: domain.User{Email: "ana@acme.test"}},
+	}
+	mockPayrollRepo.On("LockByID", ctx, uint(3)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(3)).Return([]domain.Payment{}, nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	outboxRepo.On("ListByPayroll", ctx, uint(3)).Return([]domain.NotificationOutbox{}, nil)
+	outboxRepo.On("Create", ctx, mock.AnythingOfType("*domain.NotificationOutbox")).Return(nil)
+
+	_, err := stateSvc.MarkAsPaid(ctx, 3, "cash")
+
+	assert.NoError(t, err)
+	outboxRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(n *domain.NotificationOutbox) bool {
+		return n.PayrollID == 3 && n.EmployeeID == 1 && n.Kind == domain.NotificationKindPayslipEmail &&
+			n.Recipient == "ana@acme.test" && n.Status == domain.NotificationPending
+	}))
+}
+
+func TestPayrollStateService_PartialPayment_DoesNotEnqueue(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	outboxRepo := new(MockNotificationOutboxRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, outboxRepo, newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 3, EmployeeID: 1, Status: domain.PayrollStatusApproved, NetAmount: 1000000}
+	mockPayrollRepo.On("LockByID", ctx, uint(3)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(3)).Return([]domain.Payment{}, nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+	_, err := stateSvc.RegisterPayments(ctx, 3, []PaymentPart{{Method: "cash", Amount: 400000}})
+
+	assert.NoError(t, err)
+	outboxRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_Dispatch_SendsPayslipThroughSMTP(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSMTPServer(t)
+	payrollRepo, payslipSvc := newDispatchMocks()
+	outboxRepo := new(MockNotificationOutboxRepo)
+	svc := NewNotificationService(outboxRepo, payrollRepo, payslipSvc, server.mailer(), 3, time.Minute)
+
+	outboxRepo.On("ListDue", ctx, mock.AnythingOfType("time.Time"), dispatchBatchSize).Return([]domain.NotificationOutbox{
+		{ID: 1, TenantID: 7, PayrollID: 8, EmployeeID: 1, Kind: domain.NotificationKindPayslipEmail, Recipient: "ana@acme.test", Status: domain.NotificationPending},
+	}, nil)
+	outboxRepo.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*domain.NotificationOutbox")).Return(nil)
+
+	sent, err := svc.Dispatch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	outboxRepo.AssertCalled(t, "UpdateDelivery", mock.MatchedBy(func(c context.Context) bool {
+		return c.Value(domain.TenantIDKey) == uint(7)
+	}), mock.MatchedBy(func(n *domain.NotificationOutbox) bool {
+		return n.Status == domain.NotificationSent && n.Attempts == 1 && n.SentAt != nil
+	}))
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "<nomina@acme.test>", messages[0].from)
+	assert.Equal(t, []string{"<ana@acme.test>"}, messages[0].to)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Desprendible de nómina 2026-09-01 al 2026-09-30", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	_, err = reader.NextPart() // texto
+	require.NoError(t, err)
+	attachment, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "desprendible-12345678-2026-09-30.pdf", attachment.FileName())
+	encoded, err := io.ReadAll(attachment)
+	require.NoError(t, err)
+	pdf, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(pdf), "%PDF-1.4"))
+}
+
+func TestNotificationService_Dispatch_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSMTPServer(t)
+	server.rejectRcpt = true
+	payrollRepo, payslipSvc := newDispatchMocks()
+	outboxRepo := new(MockNotificationOutboxRepo)
+	svc := NewNotificationService(outboxRepo, payrollRepo, payslipSvc, server.mailer(), 3, time.Minute)
+
+	due := []domain.NotificationOutbox{
+		{ID: 1, TenantID: 7, PayrollID: 8, Kind: domain.NotificationKindPayslipEmail, Recipient: "ana@acme.test", Status: domain.NotificationPending, Attempts: 1},
+		{ID: 2, TenantID: 7, PayrollID: 8, Kind: domain.NotificationKindPayslipEmail, Recipient: "ana@acme.test", Status: domain.NotificationPending, Attempts: 2},
+	}
+	outboxRepo.On("ListDue", ctx, mock.AnythingOfType("time.Time"), dispatchBatchSize).Return(due, nil)
+	var updates []domain.NotificationOutbox
+	outboxRepo.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*domain.NotificationOutbox")).
+		Run(func(args mock.Arguments) { updates = append(updates, *args.Get(1).(*domain.NotificationOutbox)) }).
+		Return(nil)
+
+	start := time.Now()
+	sent, err := svc.Dispatch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, server.received())
+	require.Len(t, updates, 2)
+
+	// Segundo intento: sigue pendiente y espera el doble del backoff
+	assert.Equal(t, domain.NotificationPending, updates[0].Status)
+	assert.Equal(t, 2, updates[0].Attempts)
+	assert.WithinDuration(t, start.Add(2*time.Minute), updates[0].NextAttemptAt, 5*time.Second)
+	assert.Contains(t, updates[0].LastError, "550")
+
+	// Tercer intento: se agotan los reintentos
+	assert.Equal(t, domain.NotificationFailed, updates[1].Status)
+	assert.Equal(t, 3, updates[1].Attempts)
+}
+
+func TestNotificationService_RetryDelay(t *testing.T) {
+	svc := NewNotificationService(nil, nil, nil, nil, 10, time.Minute)
+	assert.Equal(t, time.Minute, svc.retryDelay(1))
+	assert.Equal(t, 4*time.Minute, svc.retryDelay(3))
+	assert.Equal(t, maxRetryBackoff, svc.retryDelay(20))
+}
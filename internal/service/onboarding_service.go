@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// OnboardingService crea en una sola transacción el usuario, el empleado, su contrato inicial,
+// los conceptos asignados y la cuenta de pago de una contratación
+type OnboardingService struct {
+	txManager           domain.TxManager
+	userRepo            domain.UserRepo
+	employeeRepo        domain.EmployeeRepo
+	departmentRepo      domain.DepartmentRepo
+	positionRepo        domain.PositionRepo
+	conceptRepo         domain.PayrollConceptRepo
+	employeeConceptRepo domain.EmployeeConceptRepo
+	bankAccountRepo     domain.EmployeeBankAccountRepo
+	periodRepo          domain.AccountingPeriodRepo
+	contractSvc         *ContractService
+}
+
+func NewOnboardingService(
+	txManager domain.TxManager,
+	userRepo domain.UserRepo,
+	employeeRepo domain.EmployeeRepo,
+	departmentRepo domain.DepartmentRepo,
+	positionRepo domain.PositionRepo,
+	conceptRepo domain.PayrollConceptRepo,
+	employeeConceptRepo domain.EmployeeConceptRepo,
+	bankAccountRepo domain.EmployeeBankAccountRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	contractSvc *ContractService,
+) *OnboardingService {
+	return &OnboardingService{
+		txManager:           txManager,
+		userRepo:            userRepo,
+		employeeRepo:        employeeRepo,
+		departmentRepo:      departmentRepo,
+		positionRepo:        positionRepo,
+		conceptRepo:         conceptRepo,
+		employeeConceptRepo: employeeConceptRepo,
+		bankAccountRepo:     bankAccountRepo,
+		periodRepo:          periodRepo,
+		contractSvc:         contractSvc,
+	}
+}
+
+// OnboardingRequest reúne los datos de una contratación. Con DryRun solo se valida.
+type OnboardingRequest struct {
+	User        domain.User
+	Employee    domain.Employee
+	Contract    domain.EmployeeContract
+	Concepts    []OnboardingConcept
+	BankAccount *domain.EmployeeBankAccount
+	DryRun      bool
+}
+
+// OnboardingConcept asigna un concepto por código con monto fijo mensual o porcentaje propio
+type OnboardingConcept struct {
+	Code       string
+	Amount     float64
+	Percentage float64
+}
+
+// OnboardingResult son los registros creados (o que se crearían en un dry-run)
+type OnboardingResult struct {
+	DryRun      bool
+	User        *domain.User
+	Employee    *domain.Employee
+	Contract    *domain.EmployeeContract
+	Concepts    []domain.EmployeeConcept
+	BankAccount *domain.EmployeeBankAccount
+}
+
+// FieldError describe un campo inválido con su ruta en el payload (contract.base_salary)
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// OnboardingError agrupa todos los campos inválidos de la solicitud
+type OnboardingError struct {
+	Fields []FieldError
+}
+
+func (e *OnboardingError) Error() string {
+	return fmt.Sprintf("%s (%d)", domain.ErrOnboardingInvalid, len(e.Fields))
+}
+
+func (e *OnboardingError) Unwrap() error {
+	return domain.ErrOnboardingInvalid
+}
+
+// Onboard valida toda la solicitud y, si no hay errores ni es dry-run, crea todos los
+// registros en una transacción: si algo falla no queda una contratación a medias
+func (s *OnboardingService) Onboard(ctx context.Context, req OnboardingRequest) (*OnboardingResult, error) {
+	result, fields, err := s.prepare(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		return nil, &OnboardingError{Fields: fields}
+	}
+	if req.DryRun {
+		result.DryRun = true
+		return result, nil
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, result.User); err != nil {
+			return err
+		}
+		result.Employee.UserID = result.User.ID
+		if err := s.employeeRepo.Create(ctx, result.Employee); err != nil {
+			return err
+		}
+		if err := s.contractSvc.Create(ctx, result.Employee.ID, result.Contract); err != nil {
+			return err
+		}
+		for i := range result.Concepts {
+			result.Concepts[i].EmployeeID = result.Employee.ID
+		}
+		if err := s.employeeConceptRepo.CreateBatch(ctx, result.Concepts); err != nil {
+			return err
+		}
+		if result.BankAccount != nil {
+			result.BankAccount.EmployeeID = result.Employee.ID
+			if err := s.bankAccountRepo.Create(ctx, result.BankAccount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validate retorna todos los campos inválidos de la solicitud sin crear nada
+func (s *OnboardingService) Validate(ctx context.Context, req OnboardingRequest) ([]FieldError, error) {
+	_, fields, err := s.prepare(ctx, &req)
+	return fields, err
+}
+
+// prepare normaliza y valida cada sección acumulando los errores de campo; err solo se
+// retorna ante fallas de infraestructura
+func (s *OnboardingService) prepare(ctx context.Context, req *OnboardingRequest) (*OnboardingResult, []FieldError, error) {
+	v := &fieldValidator{}
+
+	user := req.User
+	if err := s.validateUser(ctx, &user, v); err != nil {
+		return nil, nil, err
+	}
+	employee := req.Employee
+	employee.ID = 0
+	employee.IsActive = true
+	position, err := s.validateEmployee(ctx, &employee, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	contract := req.Contract
+	if err := s.validateContract(ctx, &contract, position, v); err != nil {
+		return nil, nil, err
+	}
+	concepts, err := s.validateConcepts(ctx, req.Concepts, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var account *domain.EmployeeBankAccount
+	if req.BankAccount != nil {
+		copied := *req.BankAccount
+		account = &copied
+		validateBankAccount(account, v)
+	}
+
+	return &OnboardingResult{
+		User:        &user,
+		Employee:    &employee,
+		Contract:    &contract,
+		Concepts:    concepts,
+		BankAccount: account,
+	}, v.fields, nil
+}
+
+func (s *OnboardingService) validateUser(ctx context.Context, user *domain.User, v *fieldValidator) error {
+	user.Normalize()
+	v.required("user.first_name", user.FirstName)
+	v.required("user.last_name", user.LastName)
+	v.required("user.phone", user.Phone)
+	if v.required("user.email", user.Email) && !strings.Contains(user.Email, "@") {
+		v.add("user.email", "email is invalid")
+	}
+	if user.Gender != "M" && user.Gender != "F" {
+		v.add("user.gender", "gender must be M or F")
+	}
+	switch {
+	case user.BirthDay.IsZero():
+		v.add("user.birth_day", "birth_day is required")
+	case user.BirthDay.After(time.Now()):
+		v.add("user.birth_day", "birth_day cannot be in the future")
+	case user.IsMinor():
+		v.add("user.birth_day", "user must be over 18")
+	}
+
+	if !v.required("user.dni", user.Dni) {
+		return nil
+	}
+	existing, err := s.userRepo.GetByDni(ctx, user.Dni)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if existing != nil {
+		v.add("user.dni", domain.ErrDniAlreadyExist.Error())
+	}
+	return nil
+}
+
+// validateEmployee revisa departamento, cargo y jefe; retorna el cargo para validar la banda
+func (s *OnboardingService) validateEmployee(ctx context.Context, emp *domain.Employee, v *fieldValidator) (*domain.Position, error) {
+	if emp.DepartmentID != 0 {
+		department, err := s.departmentRepo.GetByID(ctx, emp.DepartmentID)
+		switch {
+		case errors.Is(err, domain.ErrDepartmentNotFound):
+			v.add("employee.department_id", err.Error())
+		case err != nil:
+			return nil, err
+		case !department.IsActive:
+			v.add("employee.department_id", "department is not active")
+		}
+	}
+
+	var position *domain.Position
+	if emp.PositionID != 0 {
+		found, err := s.positionRepo.GetByID(ctx, emp.PositionID)
+		switch {
+		case errors.Is(err, domain.ErrPositionNotFound):
+			v.add("employee.position_id", err.Error())
+		case err != nil:
+			return nil, err
+		case !found.IsActive:
+			v.add("employee.position_id", "position is not active")
+		case emp.DepartmentID != 0 && found.DepartmentID != emp.DepartmentID:
+			v.add("employee.position_id", "position does not belong to the department")
+		default:
+			position = found
+		}
+	}
+
+	if emp.ManagerID != nil && *emp.ManagerID == 0 {
+		emp.ManagerID = nil
+	}
+	if emp.ManagerID != nil {
+		manager, err := s.employeeRepo.GetByID(ctx, *emp.ManagerID)
+		switch {
+		case errors.Is(err, domain.ErrEmployeeNotFound):
+			v.add("employee.manager_id", "manager not found")
+		case err != nil:
+			return nil, err
+		case !manager.IsActive:
+			v.add("employee.manager_id", "manager is not active")
+		}
+	}
+	return position, nil
+}
+
+// validateContract aplica las reglas de ContractService que no dependen de contratos previos:
+// fechas, periodo abierto, tipo de contrato y banda salarial del cargo
+func (s *OnboardingService) validateContract(
+	ctx context.Context,
+	contract *domain.EmployeeContract,
+	position *domain.Position,
+	v *fieldValidator,
+) error {
+	contract.ID = 0
+	contract.Currency = strings.ToUpper(strings.TrimSpace(contract.Currency))
+	if contract.BaseSalary <= 0 {
+		v.add("contract.base_salary", "base_salary must be greater than 0")
+	}
+	if len(contract.Currency) != 3 {
+		v.add("contract.currency", "currency must be a 3-letter code")
+	}
+	if contract.StartDate.IsZero() {
+		v.add("contract.start_date", "start_date is required")
+	} else {
+		if err := validateContractDates(contract); err != nil {
+			v.add("contract.end_date", err.Error())
+		}
+		if err := ensurePeriodOpen(ctx, s.periodRepo, contract.StartDate, contract.StartDate); err != nil {
+			if !errors.Is(err, domain.ErrPeriodClosed) {
+				return err
+			}
+			v.add("contract.start_date", err.Error())
+		}
+	}
+
+	err := s.contractSvc.checkContractType(ctx, contract, 0)
+	switch {
+	case errors.Is(err, domain.ErrContractTypeNotFound), errors.Is(err, domain.ErrContractTypeInactive):
+		v.add("contract.contract_type_id", err.Error())
+	case errors.Is(err, domain.ErrContractEndDateRequired):
+		v.add("contract.end_date", err.Error())
+	case errors.Is(err, domain.ErrSocialSecurityProofRequired):
+		v.add("contract.social_security_proof", err.Error())
+	case err != nil:
+		return err
+	}
+
+	if position == nil || contract.BaseSalary <= 0 {
+		return nil
+	}
+	err = s.contractSvc.checkSalaryBand(ctx, contract, position.ID)
+	switch {
+	case errors.Is(err, domain.ErrSalaryOutOfBand):
+		v.add("contract.base_salary", err.Error())
+	case errors.Is(err, domain.ErrPermissionDenied), errors.Is(err, domain.ErrActorRequired):
+		v.add("contract.band_override_reason", err.Error())
+	case err != nil:
+		return err
+	}
+	return nil
+}
+
+func (s *OnboardingService) validateConcepts(ctx context.Context, requested []OnboardingConcept, v *fieldValidator) ([]domain.EmployeeConcept, error) {
+	concepts := make([]domain.EmployeeConcept, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for i, rc := range requested {
+		field := fmt.Sprintf("concepts[%d]", i)
+		code := strings.ToUpper(strings.TrimSpace(rc.Code))
+		if rc.Amount < 0 || rc.Percentage < 0 || rc.Percentage > 100 {
+			v.add(field, "amount must be positive and percentage between 0 and 100")
+		} else if (rc.Amount > 0) == (rc.Percentage > 0) {
+			v.add(field, "set either amount or percentage")
+		}
+		if !v.required(field+".code", code) {
+			continue
+		}
+		if seen[code] {
+			v.add(field+".code", "concept is assigned more than once")
+			continue
+		}
+		seen[code] = true
+
+		concept, err := s.conceptRepo.GetByCode(ctx, code)
+		switch {
+		case errors.Is(err, domain.ErrConceptNotFound):
+			v.add(field+".code", err.Error())
+			continue
+		case err != nil:
+			return nil, err
+		case !concept.IsActive:
+			v.add(field+".code", "payroll concept is not active")
+			continue
+		}
+		concepts = append(concepts, domain.EmployeeConcept{
+			ConceptID:  concept.ID,
+			Amount:     rc.Amount,
+			Percentage: rc.Percentage,
+			IsActive:   true,
+			Concept:    *concept,
+		})
+	}
+	return concepts, nil
+}
+
+// validateBankAccount valida la cuenta de pago; la primera cuenta del empleado es la principal
+func validateBankAccount(account *domain.EmployeeBankAccount, v *fieldValidator) {
+	account.Normalize()
+	account.IsPrimary = true
+	account.IsActive = true
+	account.SplitPercentage = 0
+
+	v.required("bank_account.bank_name", account.BankName)
+	if account.AccountType != domain.BankAccountSavings && account.AccountType != domain.BankAccountChecking {
+		v.add("bank_account.account_type", domain.ErrInvalidAccountType.Error())
+	}
+	if !domain.ValidAccountNumber(account.BankName, account.AccountNumber) {
+		v.add("bank_account.account_number", domain.ErrInvalidAccountNumber.Error())
+	}
+}
+
+// fieldValidator acumula errores de campo
+type fieldValidator struct {
+	fields []FieldError
+}
+
+func (v *fieldValidator) add(field, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// required registra el error si el valor está vacío; retorna si el valor está presente
+func (v *fieldValidator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		name := field[strings.LastIndex(field, ".")+1:]
+		v.add(field, name+" is required")
+		return false
+	}
+	return true
+}
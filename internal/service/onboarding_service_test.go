@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmployeeConceptRepo struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeConceptRepo) CreateBatch(ctx context.Context, concepts []domain.EmployeeConcept) error {
+	args := m.Called(ctx, concepts)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeConceptRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeConcept, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.EmployeeConcept), args.Error(1)
+}
+
+type MockEmployeeBankAccountRepo struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeBankAccountRepo) Create(ctx context.Context, account *domain.EmployeeBankAccount) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeBankAccountRepo) GetByID(ctx context.Context, id uint) (*domain.EmployeeBankAccount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeeBankAccount), args.Error(1)
+}
+
+func (m *MockEmployeeBankAccountRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeBankAccount, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.EmployeeBankAccount), args.Error(1)
+}
+
+func (m *MockEmployeeBankAccountRepo) Update(ctx context.Context, account *domain.EmployeeBankAccount) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeBankAccountRepo) ClearPrimary(ctx context.Context, employeeID, exceptID uint) error {
+	args := m.Called(ctx, employeeID, exceptID)
+	return args.Error(0)
+}
+
+type onboardingMocks struct {
+	userRepo            *mocks.MockUserRepo
+	employeeRepo        *MockEmployeeRepo
+	departmentRepo      *MockDepartmentRepo
+	positionRepo        *MockPositionRepo
+	conceptRepo         *MockConceptRepo
+	employeeConceptRepo *MockEmployeeConceptRepo
+	bankAccountRepo     *MockEmployeeBankAccountRepo
+	contractRepo        *MockContractRepo
+}
+
+func newOnboardingService() (*OnboardingService, *onboardingMocks) {
+	m := &onboardingMocks{
+		userRepo:            mocks.NewMockUserRepo(),
+		employeeRepo:        new(MockEmployeeRepo),
+		departmentRepo:      new(MockDepartmentRepo),
+		positionRepo:        new(MockPositionRepo),
+		conceptRepo:         new(MockConceptRepo),
+		employeeConceptRepo: new(MockEmployeeConceptRepo),
+		bankAccountRepo:     new(MockEmployeeBankAccountRepo),
+		contractRepo:        new(MockContractRepo),
+	}
+	permissionSvc := NewPermissionService(new(MockUserRoleRepo), mocks.NewMockRoleRepo())
+	contractSvc := NewContractService(&MockTxManager{}, m.contractRepo, m.employeeRepo, new(MockContractTypeRepo),
+		m.positionRepo, newOpenPeriodRepo(), permissionSvc, nil)
+	svc := NewOnboardingService(&MockTxManager{}, m.userRepo, m.employeeRepo, m.departmentRepo, m.positionRepo,
+		m.conceptRepo, m.employeeConceptRepo, m.bankAccountRepo, newOpenPeriodRepo(), contractSvc)
+	return svc, m
+}
+
+func validOnboardingRequest() OnboardingRequest {
+	return OnboardingRequest{
+		User: domain.User{
+			FirstName: "Ana",
+			LastName:  "Gomez",
+			Dni:       "12345678",
+			Gender:    "F",
+			Phone:     "+573001234567",
+			Email:     "ana@example.com",
+			BirthDay:  time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC),
+		},
+		Employee: domain.Employee{DepartmentID: 2},
+		Contract: domain.EmployeeContract{
+			BaseSalary: 3000000,
+			Currency:   "cop",
+			StartDate:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Concepts:    []OnboardingConcept{{Code: "bonus", Amount: 200000}},
+		BankAccount: &domain.EmployeeBankAccount{BankName: "Bancolombia", AccountType: "Savings", AccountNumber: "001-2345678-9"},
+	}
+}
+
+func (m *onboardingMocks) expectValidLookups(ctx context.Context) {
+	m.userRepo.On("GetByDni", ctx, "12345678").Return(nil, domain.ErrUserNotFound)
+	m.departmentRepo.On("GetByID", ctx, uint(2)).Return(&domain.Department{ID: 2, IsActive: true}, nil)
+	m.conceptRepo.On("GetByCode", ctx, "BONUS").Return(&domain.PayrollConcept{ID: 7, Code: "BONUS", IsActive: true}, nil)
+}
+
+func TestOnboardingService_Onboard_ReportsAllFieldErrors(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newOnboardingService()
+
+	m.userRepo.On("GetByDni", ctx, "12345678").Return(&domain.User{ID: 3, Dni: "12345678"}, nil)
+	m.departmentRepo.On("GetByID", ctx, uint(2)).Return(nil, domain.ErrDepartmentNotFound)
+	m.conceptRepo.On("GetByCode", ctx, "BONUS").Return(&domain.PayrollConcept{ID: 7, Code: "BONUS", IsActive: true}, nil)
+
+	req := validOnboardingRequest()
+	req.User.Email = ""
+	req.Contract.BaseSalary = 0
+	req.Concepts = append(req.Concepts, OnboardingConcept{Code: "BONUS", Percentage: 5})
+	req.BankAccount.AccountNumber = "12-34"
+
+	result, err := svc.Onboard(ctx, req)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domain.ErrOnboardingInvalid)
+	var validationErr *OnboardingError
+	assert.ErrorAs(t, err, &validationErr)
+	fields := make([]string, len(validationErr.Fields))
+	for i, f := range validationErr.Fields {
+		fields[i] = f.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"user.email", "user.dni", "employee.department_id", "contract.base_salary",
+		"concepts[1].code", "bank_account.account_number",
+	}, fields)
+	m.userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestOnboardingService_Onboard_DryRunDoesNotWrite(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newOnboardingService()
+	m.expectValidLookups(ctx)
+
+	req := validOnboardingRequest()
+	req.DryRun = true
+	result, err := svc.Onboard(ctx, req)
+
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, "COP", result.Contract.Currency)
+	assert.Equal(t, uint(7), result.Concepts[0].ConceptID)
+	assert.Equal(t, domain.BankAccountSavings, result.BankAccount.AccountType)
+	m.userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.employeeRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.contractRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestOnboardingService_Onboard_CreatesEverything(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newOnboardingService()
+	m.expectValidLookups(ctx)
+
+	m.userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.User).ID = 10 }).Return(nil)
+	m.employeeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Employee")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Employee).ID = 20 }).Return(nil)
+	m.employeeRepo.On("GetByID", ctx, uint(20)).Return(&domain.Employee{ID: 20, IsActive: true}, nil)
+	m.contractRepo.On("LockActiveByEmployee", ctx, uint(20)).Return(nil, domain.ErrEmployeeContractNotFound)
+	m.contractRepo.On("ListByEmployee", ctx, uint(20)).Return([]domain.EmployeeContract{}, nil)
+	m.contractRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeContract")).Return(nil)
+	m.employeeConceptRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+	m.bankAccountRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeBankAccount")).Return(nil)
+
+	result, err := svc.Onboard(ctx, validOnboardingRequest())
+
+	assert.NoError(t, err)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, uint(10), result.Employee.UserID)
+	assert.Equal(t, uint(20), result.Contract.EmployeeID)
+	m.employeeConceptRepo.AssertCalled(t, "CreateBatch", ctx, mock.MatchedBy(func(concepts []domain.EmployeeConcept) bool {
+		return len(concepts) == 1 && concepts[0].EmployeeID == 20 && concepts[0].Amount == 200000
+	}))
+	m.bankAccountRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(a *domain.EmployeeBankAccount) bool {
+		return a.EmployeeID == 20 && a.IsPrimary && a.IsActive
+	}))
+}
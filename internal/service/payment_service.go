@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/payout"
+)
+
+// paymentTransitions define los cambios de estado permitidos de un pago
+var paymentTransitions = map[string][]string{
+	domain.PaymentStatusPending: {domain.PaymentStatusSent, domain.PaymentStatusCompleted, domain.PaymentStatusFailed},
+	domain.PaymentStatusSent:    {domain.PaymentStatusCompleted, domain.PaymentStatusFailed},
+}
+
+// PaymentService lleva el ciclo de vida de los pagos: los envía al proveedor, consulta su
+// estado y, cuando el banco los rechaza, devuelve la nómina al estado no pagado.
+//
+// La nómina queda paid (y sus acumulados incrementados) al registrar los pagos, aunque las
+// transferencias sigan pending o sent: paid significa pago ordenado, no liquidado. Si el banco
+// rechaza una transferencia, la nómina vuelve a partially_paid o al estado previo al pago y
+// los acumulados se descuentan en la misma transacción.
+type PaymentService struct {
+	txManager      domain.TxManager
+	paymentRepo    domain.PaymentRepo
+	stateSvc       *PayrollStateService
+	provider       domain.PayoutProvider
+	callbackSecret string
+}
+
+func NewPaymentService(
+	txManager domain.TxManager,
+	paymentRepo domain.PaymentRepo,
+	stateSvc *PayrollStateService,
+	provider domain.PayoutProvider,
+	callbackSecret string,
+) *PaymentService {
+	return &PaymentService{
+		txManager:      txManager,
+		paymentRepo:    paymentRepo,
+		stateSvc:       stateSvc,
+		provider:       provider,
+		callbackSecret: callbackSecret,
+	}
+}
+
+// PaymentSyncResult resume una sincronización con el proveedor
+type PaymentSyncResult struct {
+	Provider  string   `json:"provider"`
+	Submitted int      `json:"submitted"`
+	Completed int      `json:"completed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// GetByID retorna un pago
+func (s *PaymentService) GetByID(ctx context.Context, paymentID uint) (*domain.Payment, error) {
+	return s.paymentRepo.GetByID(ctx, paymentID)
+}
+
+// Sync envía al proveedor las transferencias pendientes y consulta el estado de las enviadas.
+// Un error del proveedor en un pago no detiene los demás; se reporta en el resultado.
+func (s *PaymentService) Sync(ctx context.Context) (*PaymentSyncResult, error) {
+	result := &PaymentSyncResult{Provider: s.provider.Name()}
+
+	pending, err := s.paymentRepo.ListByStatus(ctx, domain.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		payment := &pending[i]
+		if payment.Method != domain.PaymentMethodBankTransfer {
+			continue
+		}
+		ref, err := s.provider.Submit(ctx, payment)
+		status := domain.PaymentStatusSent
+		reason := ""
+		if err != nil {
+			status = domain.PaymentStatusFailed
+			reason = err.Error()
+		}
+		payment.ProviderRef = ref
+		if err := s.transition(ctx, payment, status, reason); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("payment %d: %v", payment.ID, err))
+			continue
+		}
+		if status == domain.PaymentStatusFailed {
+			result.Failed++
+		} else {
+			result.Submitted++
+		}
+	}
+
+	sent, err := s.paymentRepo.ListByStatus(ctx, domain.PaymentStatusSent)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sent {
+		payment := &sent[i]
+		// Los pagos de archivos bancarios se concilian con el extracto, no con el proveedor
+		if payment.ProviderRef == "" || payment.BankFileID != nil {
+			continue
+		}
+		status, err := s.provider.Status(ctx, payment.ProviderRef)
+		if err == nil {
+			err = s.transition(ctx, payment, status.Status, status.FailureReason)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("payment %d: %v", payment.ID, err))
+			continue
+		}
+		switch payment.Status {
+		case domain.PaymentStatusCompleted:
+			result.Completed++
+		case domain.PaymentStatusFailed:
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// VerifyCallback valida la firma HMAC del cuerpo del callback con el secreto del proveedor
+func (s *PaymentService) VerifyCallback(payload []byte, signature string) error {
+	if !payout.VerifySignature(s.callbackSecret, payload, signature) {
+		return domain.ErrInvalidCallbackSignature
+	}
+	return nil
+}
+
+// ApplyCallback aplica la notificación del proveedor sobre el pago con esa referencia. El
+// llamador debe validar antes la firma con VerifyCallback.
+func (s *PaymentService) ApplyCallback(ctx context.Context, reference, status, reason string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetByProviderRef(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(ctx, payment, status, reason); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// UpdateStatus cambia el estado de un pago a mano, por ejemplo al confirmar la entrega de
+// un cheque o del efectivo
+func (s *PaymentService) UpdateStatus(ctx context.Context, paymentID uint, status, reason string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(ctx, payment, status, reason); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// transition guarda el nuevo estado del pago. Un pago fallido recalcula el estado de la
+// nómina en la misma transacción. Repetir el estado actual no hace nada.
+func (s *PaymentService) transition(ctx context.Context, payment *domain.Payment, status, reason string) error {
+	if payment.Status == status {
+		return nil
+	}
+	if !canPaymentTransition(payment.Status, status) {
+		return fmt.Errorf("%w: %s to %s", domain.ErrInvalidPaymentStatus, payment.Status, status)
+	}
+
+	now := time.Now()
+	payment.Status = status
+	switch status {
+	case domain.PaymentStatusSent:
+		payment.SentAt = &now
+	case domain.PaymentStatusCompleted:
+		payment.SettledAt = &now
+	case domain.PaymentStatusFailed:
+		if reason == "" {
+			reason = "rejected by provider"
+		}
+		payment.FailureReason = reason
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.UpdateStatus(ctx, payment); err != nil {
+			return err
+		}
+		if status == domain.PaymentStatusFailed {
+			return s.stateSvc.revertFailedPayment(ctx, payment.PayrollID, reason)
+		}
+		return nil
+	})
+}
+
+func canPaymentTransition(from, to string) bool {
+	for _, allowed := range paymentTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
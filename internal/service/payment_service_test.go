@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/payout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPaymentService_Sync_SubmitsAndSettles(t *testing.T) {
+	ctx := context.Background()
+
+	mockPaymentRepo := new(MockPaymentRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	provider := payout.NewFakeProvider()
+	svc := NewPaymentService(&MockTxManager{}, mockPaymentRepo, stateSvc, provider, "")
+
+	pending := []domain.Payment{
+		{ID: 1, PayrollID: 1, Method: domain.PaymentMethodBankTransfer, AccountNumber: "00123456789", Amount: 100, Status: domain.PaymentStatusPending},
+		{ID: 2, PayrollID: 1, Method: "cash", Amount: 50, Status: domain.PaymentStatusPending},
+	}
+	mockPaymentRepo.On("ListByStatus", ctx, domain.PaymentStatusPending).Return(pending, nil).Once()
+	mockPaymentRepo.On("ListByStatus", ctx, domain.PaymentStatusSent).Return([]domain.Payment{}, nil).Once()
+	mockPaymentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+	result, err := svc.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Submitted)
+	mockPaymentRepo.AssertCalled(t, "UpdateStatus", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.ID == 1 && p.Status == domain.PaymentStatusSent && p.ProviderRef != "" && p.SentAt != nil
+	}))
+	mockPaymentRepo.AssertNotCalled(t, "UpdateStatus", ctx, mock.MatchedBy(func(p *domain.Payment) bool { return p.ID == 2 }))
+
+	// El fake liquida el pago en la segunda consulta
+	sent := pending[0]
+	mockPaymentRepo.On("ListByStatus", ctx, domain.PaymentStatusPending).Return([]domain.Payment{}, nil)
+	mockPaymentRepo.On("ListByStatus", ctx, domain.PaymentStatusSent).Return([]domain.Payment{sent}, nil)
+	_, err = svc.Sync(ctx)
+	assert.NoError(t, err)
+	result, err = svc.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Completed)
+}
+
+func TestPaymentService_Callback_FailureRevertsPayroll(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	svc := NewPaymentService(&MockTxManager{}, mockPaymentRepo, stateSvc, payout.NewFakeProvider(), "")
+
+	payment := &domain.Payment{ID: 3, PayrollID: 1, Method: domain.PaymentMethodBankTransfer, Amount: 1000,
+		Status: domain.PaymentStatusSent, ProviderRef: "FAKE-000001"}
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusPaid, NetAmount: 1000}
+	mockPaymentRepo.On("GetByProviderRef", ctx, "FAKE-000001").Return(payment, nil)
+	mockPaymentRepo.On("UpdateStatus", ctx, payment).Return(nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{
+		{ID: 3, PayrollID: 1, Amount: 1000, Status: domain.PaymentStatusFailed},
+	}, nil)
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, payroll).Return(nil)
+	mockHistoryRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.PayrollStatusHistory{
+		{FromStatus: "", ToStatus: domain.PayrollStatusCalculated},
+		{FromStatus: domain.PayrollStatusCalculated, ToStatus: domain.PayrollStatusPaid},
+	}, nil)
+
+	updated, err := svc.ApplyCallback(ctx, "FAKE-000001", domain.PaymentStatusFailed, "account closed")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusFailed, updated.Status)
+	assert.Equal(t, "account closed", updated.FailureReason)
+	assert.Equal(t, domain.PayrollStatusCalculated, payroll.Status)
+	mockHistoryRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(h *domain.PayrollStatusHistory) bool {
+		return h.ToStatus == domain.PayrollStatusCalculated && h.Reason == "payment failed: account closed"
+	}))
+
+	// Un pago fallido no se puede completar después
+	_, err = svc.ApplyCallback(ctx, "FAKE-000001", domain.PaymentStatusCompleted, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentStatus)
+}
+
+func TestPaymentService_VerifyCallback(t *testing.T) {
+	payload := []byte(`{"reference":"FAKE-000001","status":"completed"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid signature", secret: "s3cret", signature: payout.Sign("s3cret", payload)},
+		{name: "signed with another secret", secret: "s3cret", signature: payout.Sign("other", payload), wantErr: true},
+		{name: "missing signature", secret: "s3cret", wantErr: true},
+		// Sin secreto configurado el callback queda deshabilitado
+		{name: "no secret configured", signature: payout.Sign("", payload), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewPaymentService(&MockTxManager{}, new(MockPaymentRepo), nil, payout.NewFakeProvider(), tt.secret)
+
+			err := svc.VerifyCallback(payload, tt.signature)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidCallbackSignature)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -11,29 +11,47 @@ import (
 
 // PayrollBatchService procesa nóminas de múltiples empleados
 type PayrollBatchService struct {
+	txManager       domain.TxManager
 	payrollRepo     domain.PayrollRepo
 	payrollItemRepo domain.PayrollItemRepo
 	employeeRepo    domain.EmployeeRepo
 	contractRepo    domain.EmployeeContractRepo
 	conceptRepo     domain.PayrollConceptRepo
+	periodRepo      domain.AccountingPeriodRepo
+	retroRepo       domain.RetroAdjustmentRepo
 	stateService    *PayrollStateService
+	accumulatorRepo domain.PayrollAccumulatorRepo
+	allocationRepo  domain.CostCenterAllocationRepo
+	absenceRepo     domain.EmployeeAbsenceRepo
 }
 
 func NewPayrollBatchService(
+	txManager domain.TxManager,
 	payrollRepo domain.PayrollRepo,
 	payrollItemRepo domain.PayrollItemRepo,
 	employeeRepo domain.EmployeeRepo,
 	contractRepo domain.EmployeeContractRepo,
 	conceptRepo domain.PayrollConceptRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	retroRepo domain.RetroAdjustmentRepo,
 	stateService *PayrollStateService,
+	accumulatorRepo domain.PayrollAccumulatorRepo,
+	allocationRepo domain.CostCenterAllocationRepo,
+	absenceRepo domain.EmployeeAbsenceRepo,
 ) *PayrollBatchService {
 	return &PayrollBatchService{
+		txManager:       txManager,
 		payrollRepo:     payrollRepo,
 		payrollItemRepo: payrollItemRepo,
 		employeeRepo:    employeeRepo,
 		contractRepo:    contractRepo,
 		conceptRepo:     conceptRepo,
+		periodRepo:      periodRepo,
+		retroRepo:       retroRepo,
 		stateService:    stateService,
+		accumulatorRepo: accumulatorRepo,
+		allocationRepo:  allocationRepo,
+		absenceRepo:     absenceRepo,
 	}
 }
 
@@ -85,6 +103,10 @@ func (s *PayrollBatchService) CalculatePeriodSummary(ctx context.Context, req Ba
 		req.PayDate = req.PeriodEnd
 	}
 
+	if err := ensurePeriodOpen(ctx, s.periodRepo, req.PeriodStart, req.PeriodEnd); err != nil {
+		return nil, err
+	}
+
 	// Obtener empleados activos
 	employees, total, err := s.employeeRepo.ListActive(ctx, 1, 10000) // Paginar si hay muchos
 	if err != nil {
@@ -210,11 +232,18 @@ func (s *PayrollBatchService) processEmployee(
 	}
 
 	calculator := NewPayrollCalculatorService(
+		s.txManager,
 		s.payrollRepo,
 		s.payrollItemRepo,
 		s.employeeRepo,
 		s.contractRepo,
 		s.conceptRepo,
+		s.periodRepo,
+		s.retroRepo,
+		s.accumulatorRepo,
+		s.allocationRepo,
+		s.absenceRepo,
+		s.stateService,
 	)
 
 	calculated, err := calculator.CalculateAndSave(ctx, calcReq)
@@ -3,32 +3,54 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/arrase21/crm-users/internal/domain"
 )
 
 type PayrollCalculatorService struct {
+	txManager          domain.TxManager
 	payrollRepo        domain.PayrollRepo
 	payrollItemRepo    domain.PayrollItemRepo
 	employeeRepo       domain.EmployeeRepo
 	contractRepo       domain.EmployeeContractRepo
 	payrollConceptRepo domain.PayrollConceptRepo
+	periodRepo         domain.AccountingPeriodRepo
+	retroRepo          domain.RetroAdjustmentRepo
+	accumulatorRepo    domain.PayrollAccumulatorRepo
+	allocationRepo     domain.CostCenterAllocationRepo
+	absenceRepo        domain.EmployeeAbsenceRepo
+	stateSvc           *PayrollStateService
 }
 
 func NewPayrollCalculatorService(
+	txManager domain.TxManager,
 	payrollRepo domain.PayrollRepo,
 	payrollItemRepo domain.PayrollItemRepo,
 	employeeRepo domain.EmployeeRepo,
 	contractRepo domain.EmployeeContractRepo,
 	conceptRepo domain.PayrollConceptRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	retroRepo domain.RetroAdjustmentRepo,
+	accumulatorRepo domain.PayrollAccumulatorRepo,
+	allocationRepo domain.CostCenterAllocationRepo,
+	absenceRepo domain.EmployeeAbsenceRepo,
+	stateSvc *PayrollStateService,
 ) *PayrollCalculatorService {
 	return &PayrollCalculatorService{
+		txManager:          txManager,
 		payrollRepo:        payrollRepo,
 		payrollItemRepo:    payrollItemRepo,
 		employeeRepo:       employeeRepo,
 		contractRepo:       contractRepo,
 		payrollConceptRepo: conceptRepo,
+		periodRepo:         periodRepo,
+		retroRepo:          retroRepo,
+		accumulatorRepo:    accumulatorRepo,
+		allocationRepo:     allocationRepo,
+		absenceRepo:        absenceRepo,
+		stateSvc:           stateSvc,
 	}
 }
 
@@ -45,6 +67,8 @@ type CalculatedPayroll struct {
 	GrossAmount     float64
 	TotalDeductions float64
 	NetAmount       float64
+	// Withholding es la depuración de la retención cuando se liquidó por tabla
+	Withholding *Withholding
 }
 
 func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculatePayrollRequest) (*CalculatedPayroll, error) {
@@ -74,6 +98,10 @@ func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculateP
 		return nil, err
 	}
 
+	if contract.ContractType.RequiresSocialSecurityProof && contract.SocialSecurityProof == "" {
+		return nil, domain.ErrSocialSecurityProofRequired
+	}
+
 	concepts, err := s.payrollConceptRepo.GetActiveConcepts(ctx)
 	if err != nil {
 		return nil, err
@@ -82,8 +110,88 @@ func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculateP
 		return nil, errors.New("no active payroll concepts configured")
 	}
 
+	absences, err := s.absenceRepo.ListByEmployee(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	calculated := s.buildPayroll(employee, contract, concepts, absences, req)
+	if err := s.applyWithholding(ctx, calculated); err != nil {
+		return nil, err
+	}
+	if err := s.allocateCosts(ctx, req.EmployeeID, req.PeriodStart, req.PeriodEnd, calculated.Items); err != nil {
+		return nil, err
+	}
+	return calculated, nil
+}
+
+// allocateCosts reparte los devengos y aportes del empleador entre los centros de costo del
+// empleado según las asignaciones vigentes en el periodo
+func (s *PayrollCalculatorService) allocateCosts(ctx context.Context, employeeID uint, start, end time.Time, items []domain.PayrollItem) error {
+	allocations, err := s.allocationRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+	allocateItems(items, periodCostShares(allocations, start, end))
+	return nil
+}
+
+// applyWithholding liquida la retención en la fuente (TAX) por el procedimiento 1 cuando el
+// concepto no tiene un porcentaje fijo. La renta exenta ya aplicada en el año sale de los
+// acumulados del empleado para respetar el tope anual.
+func (s *PayrollCalculatorService) applyWithholding(ctx context.Context, calculated *CalculatedPayroll) error {
+	idx := -1
+	for i, item := range calculated.Items {
+		if item.Code == domain.ConceptTax && (item.Amount == 0 || calculated.Withholding != nil) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil
+	}
+
+	payroll := calculated.Payroll
+	year := accumulatorYear(payroll)
+	accumulators, err := s.accumulatorRepo.ListByEmployee(ctx, payroll.EmployeeID, year)
+	if err != nil {
+		return err
+	}
+	var ytdExempt float64
+	for _, acc := range accumulators {
+		if acc.Code == domain.AccumulatorExemptIncome {
+			ytdExempt = acc.Amount
+		}
+	}
+
+	withholding := computeWithholding(calculated.Items, year, registerDays(payroll.PeriodStart, payroll.PeriodEnd), ytdExempt)
+	calculated.TotalDeductions += withholding.Amount - calculated.Items[idx].Amount
+	calculated.Items[idx].Amount = withholding.Amount
+	calculated.Withholding = &withholding
+	calculated.NetAmount = calculated.GrossAmount - calculated.TotalDeductions
+	payroll.TotalDeductions = calculated.TotalDeductions
+	payroll.NetAmount = calculated.NetAmount
+	return nil
+}
+
+// buildPayroll calcula los items y totales de un periodo con el contrato y conceptos dados,
+// aplicando las reglas del tipo de contrato. Los días de licencia no remunerada del periodo
+// no se pagan.
+func (s *PayrollCalculatorService) buildPayroll(
+	employee *domain.Employee,
+	contract *domain.EmployeeContract,
+	concepts []domain.PayrollConcept,
+	absences []domain.EmployeeAbsence,
+	req CalculatePayrollRequest,
+) *CalculatedPayroll {
+	rules := &contract.ContractType
 	baseSalary := contract.BaseSalary
+	// Aprendizaje: el apoyo de sostenimiento es un porcentaje del salario mínimo
+	if rules.StipendPercentage > 0 {
+		baseSalary = minimumWage(req.PeriodStart.Year()) * rules.StipendPercentage / 100
+	}
 	periodDays := int(req.PeriodEnd.Sub(req.PeriodStart).Hours()/24) + 1
+	periodDays -= unpaidLeaveDays(absences, req.PeriodStart, req.PeriodEnd)
 	monthDays := 30.0
 	if periodDays != 30 {
 		baseSalary = baseSalary * float64(periodDays) / monthDays
@@ -93,8 +201,30 @@ func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculateP
 	var grossAmount float64
 	var totalDeductions float64
 
+	assigned := make(map[uint]domain.EmployeeConcept, len(employee.Concepts))
+	for _, ec := range employee.Concepts {
+		if ec.IsActive {
+			assigned[ec.ConceptID] = ec
+		}
+	}
+
 	for _, concept := range concepts {
+		if skipConcept(rules, concept) {
+			continue
+		}
+		ec, hasOwnValue := assigned[concept.ID]
+		if hasOwnValue && ec.Percentage > 0 {
+			concept.Percentage = ec.Percentage
+		}
 		item := s.calculateConceptItem(concept, baseSalary, contract)
+		// Monto fijo mensual del empleado, prorrateado como el salario
+		if hasOwnValue && ec.Amount > 0 {
+			item.Amount = roundCents(ec.Amount * float64(periodDays) / monthDays)
+		}
+		if concept.Code == domain.ConceptBaseSalary && rules.StipendPercentage > 0 {
+			item.Code = domain.ConceptApprenticeStipend
+			item.Name = "Apoyo de Sostenimiento"
+		}
 		items = append(items, item)
 
 		switch concept.Type {
@@ -118,6 +248,7 @@ func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculateP
 		TotalDeductions: totalDeductions,
 		NetAmount:       netAmount,
 		Status:          domain.PayrollStatusDraft,
+		Kind:            domain.PayrollKindRegular,
 	}
 
 	return &CalculatedPayroll{
@@ -126,7 +257,50 @@ func (s *PayrollCalculatorService) Calculate(ctx context.Context, req CalculateP
 		GrossAmount:     grossAmount,
 		TotalDeductions: totalDeductions,
 		NetAmount:       netAmount,
-	}, nil
+	}
+}
+
+// unpaidLeaveDays cuenta los días de licencia no remunerada dentro del periodo
+func unpaidLeaveDays(absences []domain.EmployeeAbsence, start, end time.Time) int {
+	days := 0
+	for _, a := range absences {
+		if a.Kind != domain.AbsenceUnpaidLeave || !a.Overlaps(start, end) {
+			continue
+		}
+		from, to := a.StartDate, a.EndDate
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		days += int(to.Sub(from).Hours()/24) + 1
+	}
+	return days
+}
+
+// skipConcept indica si las reglas del tipo de contrato excluyen el concepto: prestación de
+// servicios no tiene deducciones y aprendizaje no aporta a pensión
+func skipConcept(rules *domain.ContractType, concept domain.PayrollConcept) bool {
+	if rules.NoDeductions && concept.Type == domain.PayrollTypeDeduction {
+		return true
+	}
+	if rules.SkipPension && (concept.Code == domain.ConceptPension || concept.Code == domain.ConceptPensionEmployer) {
+		return true
+	}
+	if rules.NoSocialBenefits && isSocialBenefit(domain.BenefitProvisionConcepts()[concept.Code]) {
+		return true
+	}
+	return false
+}
+
+// isSocialBenefit indica si la prestación no aplica a los tipos de contrato sin prestaciones
+func isSocialBenefit(benefit string) bool {
+	switch benefit {
+	case domain.BenefitPrima, domain.BenefitSeverance, domain.BenefitSeveranceInterest:
+		return true
+	}
+	return false
 }
 
 func (s *PayrollCalculatorService) calculateConceptItem(
@@ -144,7 +318,7 @@ func (s *PayrollCalculatorService) calculateConceptItem(
 		amount = baseSalary * concept.Percentage / 100
 
 	case domain.PayrollTypeEmployerContribution:
-		amount = baseSalary * concept.Percentage / 100
+		amount = contributionBase(concept.Code, baseSalary, contract) * concept.Percentage / 100
 	}
 
 	return domain.PayrollItem{
@@ -157,6 +331,16 @@ func (s *PayrollCalculatorService) calculateConceptItem(
 	}
 }
 
+// contributionBase retorna la base de un aporte del empleador. Prima, cesantías e intereses
+// se provisionan sobre el salario más el auxilio de transporte; el resto solo sobre el salario.
+func contributionBase(code string, baseSalary float64, contract *domain.EmployeeContract) float64 {
+	switch code {
+	case domain.ConceptPrimaProvision, domain.ConceptSeveranceProvision, domain.ConceptSeveranceInterestProvision:
+		return baseSalary + contract.TransportAllowance
+	}
+	return baseSalary
+}
+
 func (s *PayrollCalculatorService) calculateEarning(
 	concept domain.PayrollConcept,
 	baseSalary float64,
@@ -180,23 +364,92 @@ func (s *PayrollCalculatorService) calculateEarning(
 }
 
 func (s *PayrollCalculatorService) CalculateAndSave(ctx context.Context, req CalculatePayrollRequest) (*CalculatedPayroll, error) {
+	if err := ensurePeriodOpen(ctx, s.periodRepo, req.PeriodStart, req.PeriodEnd); err != nil {
+		return nil, err
+	}
+
 	calculated, err := s.Calculate(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	calculated.Payroll.CalculatedBy = actorFromCtx(ctx)
+
+	// La nómina, sus items y la asignación de retroactivos se guardan en una sola transacción
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.save(ctx, req, calculated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return calculated, nil
+}
 
+// save crea la nómina del periodo o reemplaza la existente con el cálculo, aplicando los
+// retroactivos pendientes; debe ejecutarse dentro de una transacción
+func (s *PayrollCalculatorService) save(ctx context.Context, req CalculatePayrollRequest, calculated *CalculatedPayroll) error {
+	// Una nómina anulada queda como registro y la reemplaza una nueva nómina regular; los
+	// retroactivos que tenía asignados pasan a la nueva
+	var superseded []domain.RetroAdjustment
 	existing, err := s.payrollRepo.GetByEmployeeAndPeriod(ctx, req.EmployeeID, req.PeriodStart, req.PeriodEnd)
+	if err != nil && !errors.Is(err, domain.ErrPayrollNotFound) {
+		return err
+	}
+	if err == nil && existing.Status == domain.PayrollStatusCancelled &&
+		(existing.Kind == "" || existing.Kind == domain.PayrollKindRegular) {
+		superseded, err = s.retroRepo.ListByTarget(ctx, existing.ID)
+		if err != nil {
+			return err
+		}
+		existing, err = nil, domain.ErrPayrollNotFound
+	}
 	if err == nil {
+		// Nóminas pagadas, reversadas o de reemplazo no se sobrescriben
+		if !isRecalculable(existing) {
+			return domain.ErrPayrollNotRecalculable
+		}
+		// Una nómina calculada o aprobada vuelve a draft con registro en el historial
+		if existing.Status != domain.PayrollStatusDraft {
+			if err := s.stateSvc.resetForRecalculation(ctx, existing); err != nil {
+				return err
+			}
+		}
 		calculated.Payroll.ID = existing.ID
+		// Los ajustes retroactivos ya asignados a esta nómina se conservan al recalcular
+		assigned, err := s.retroRepo.ListByTarget(ctx, existing.ID)
+		if err != nil {
+			return err
+		}
+		applyRetroAdjustments(calculated, assigned)
+	}
+	pending, err := s.retroRepo.ListPending(ctx, req.EmployeeID, req.PeriodStart)
+	if err != nil {
+		return err
+	}
+	pending = append(superseded, pending...)
+	applyRetroAdjustments(calculated, pending)
+	// Los retroactivos cambian la base gravable: la retención se vuelve a liquidar y sus items
+	// se reparten entre los centros de costo del periodo
+	if len(pending) > 0 || calculated.Payroll.ID != 0 {
+		if err := s.applyWithholding(ctx, calculated); err != nil {
+			return err
+		}
+		if err := s.allocateCosts(ctx, req.EmployeeID, req.PeriodStart, req.PeriodEnd, calculated.Items); err != nil {
+			return err
+		}
+	}
+
+	if calculated.Payroll.ID != 0 {
 		err = s.payrollRepo.Update(ctx, calculated.Payroll)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := s.payrollItemRepo.DeleteByPayrollID(ctx, existing.ID); err != nil {
+			return err
 		}
-		s.payrollItemRepo.DeleteByPayrollID(ctx, existing.ID)
 	} else {
 		err = s.payrollRepo.Create(ctx, calculated.Payroll)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 	var itemsToSave []domain.PayrollItem
@@ -206,9 +459,56 @@ func (s *PayrollCalculatorService) CalculateAndSave(ctx context.Context, req Cal
 	}
 	err = s.payrollItemRepo.CreateBatch(ctx, itemsToSave)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return calculated, nil
+	if len(pending) > 0 {
+		ids := make([]uint, 0, len(pending))
+		for _, adj := range pending {
+			ids = append(ids, adj.ID)
+		}
+		if err := s.retroRepo.AssignTarget(ctx, ids, calculated.Payroll.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRetroAdjustments agrega un item RETRO_* por cada ajuste (uno por periodo y concepto)
+// y actualiza los totales de la nómina
+func applyRetroAdjustments(calculated *CalculatedPayroll, adjustments []domain.RetroAdjustment) {
+	for _, adj := range adjustments {
+		calculated.Items = append(calculated.Items, domain.PayrollItem{
+			ConceptID:    adj.ConceptID,
+			Type:         adj.Type,
+			Code:         domain.RetroConceptPrefix + adj.Code,
+			Name:         fmt.Sprintf("Retroactivo %s %s", adj.Name, adj.PeriodStart.Format("2006-01")),
+			Amount:       adj.Amount,
+			CalculatedAt: time.Now(),
+		})
+		switch adj.Type {
+		case domain.PayrollTypeEarning:
+			calculated.GrossAmount += adj.Amount
+		case domain.PayrollTypeDeduction:
+			calculated.TotalDeductions += adj.Amount
+		}
+	}
+	calculated.NetAmount = calculated.GrossAmount - calculated.TotalDeductions
+	calculated.Payroll.GrossAmount = calculated.GrossAmount
+	calculated.Payroll.TotalDeductions = calculated.TotalDeductions
+	calculated.Payroll.NetAmount = calculated.NetAmount
+}
+
+// isRecalculable indica si una nómina existente puede sobrescribirse con un nuevo cálculo
+func isRecalculable(payroll *domain.Payroll) bool {
+	if payroll.Kind != "" && payroll.Kind != domain.PayrollKindRegular {
+		return false
+	}
+	switch payroll.Status {
+	case domain.PayrollStatusPaid, domain.PayrollStatusPartiallyPaid, domain.PayrollStatusReversed,
+		domain.PayrollStatusCancelled:
+		return false
+	}
+	return true
 }
 
 func (s *PayrollCalculatorService) CalculatePeriodSummary(ctx context.Context, periodStart, periodEnd time.Time) ([]CalculatedPayroll, error) {
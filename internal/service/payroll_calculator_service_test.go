@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -34,6 +35,14 @@ func (m *MockPayrollRepo) GetByID(ctx context.Context, id uint) (*domain.Payroll
 	return args.Get(0).(*domain.Payroll), args.Error(1)
 }
 
+func (m *MockPayrollRepo) LockByID(ctx context.Context, id uint) (*domain.Payroll, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payroll), args.Error(1)
+}
+
 func (m *MockPayrollRepo) GetByEmployeeAndPeriod(ctx context.Context, employeeID uint, periodStart, periodEnd time.Time) (*domain.Payroll, error) {
 	args := m.Called(ctx, employeeID, periodStart, periodEnd)
 	if args.Get(0) == nil {
@@ -121,6 +130,16 @@ func (m *MockEmployeeRepo) ListActive(ctx context.Context, page, limit int) ([]d
 	return args.Get(0).([]domain.Employee), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockEmployeeRepo) ListByManagers(ctx context.Context, managerIDs []uint) ([]domain.Employee, error) {
+	args := m.Called(ctx, managerIDs)
+	return args.Get(0).([]domain.Employee), args.Error(1)
+}
+
+func (m *MockEmployeeRepo) ListByDepartment(ctx context.Context, departmentID uint) ([]domain.Employee, error) {
+	args := m.Called(ctx, departmentID)
+	return args.Get(0).([]domain.Employee), args.Error(1)
+}
+
 func (m *MockEmployeeRepo) Update(ctx context.Context, emp *domain.Employee) error {
 	args := m.Called(ctx, emp)
 	return args.Error(0)
@@ -156,11 +175,24 @@ func (m *MockContractRepo) GetActiveByEmployee(ctx context.Context, employeeID u
 	return args.Get(0).(*domain.EmployeeContract), args.Error(1)
 }
 
+func (m *MockContractRepo) LockActiveByEmployee(ctx context.Context, employeeID uint) (*domain.EmployeeContract, error) {
+	args := m.Called(ctx, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeeContract), args.Error(1)
+}
+
 func (m *MockContractRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeContract, error) {
 	args := m.Called(ctx, employeeID)
 	return args.Get(0).([]domain.EmployeeContract), args.Error(1)
 }
 
+func (m *MockContractRepo) ListActiveEndingBy(ctx context.Context, until time.Time) ([]domain.EmployeeContract, error) {
+	args := m.Called(ctx, until)
+	return args.Get(0).([]domain.EmployeeContract), args.Error(1)
+}
+
 func (m *MockContractRepo) Update(ctx context.Context, contract *domain.EmployeeContract) error {
 	args := m.Called(ctx, contract)
 	return args.Error(0)
@@ -231,11 +263,18 @@ func TestPayrollCalculator_Calculate_Success(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	// Datos de prueba
@@ -305,6 +344,78 @@ func TestPayrollCalculator_Calculate_Success(t *testing.T) {
 	mockConceptRepo.AssertExpectations(t)
 }
 
+func TestPayrollCalculator_BuildPayroll_ApprenticeshipRules(t *testing.T) {
+	calculator := &PayrollCalculatorService{}
+	employee := &domain.Employee{ID: 1, TenantID: 1}
+	contract := &domain.EmployeeContract{
+		ID:           1,
+		EmployeeID:   1,
+		BaseSalary:   5000000,
+		ContractType: domain.ContractType{Code: domain.ContractTypeApprenticeship, SkipPension: true, StipendPercentage: 75},
+	}
+	concepts := []domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 4},
+		{ID: 3, Code: domain.ConceptPension, Name: "Pensión", Type: domain.PayrollTypeDeduction, Percentage: 4},
+		{ID: 4, Code: domain.ConceptPensionEmployer, Name: "Pensión Empleador", Type: domain.PayrollTypeEmployerContribution, Percentage: 12},
+	}
+
+	result := calculator.buildPayroll(employee, contract, concepts, nil, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	})
+
+	stipend := minimumWage(2024) * 0.75
+	codes := make(map[string]float64)
+	for _, item := range result.Items {
+		codes[item.Code] = item.Amount
+	}
+	assert.Len(t, result.Items, 2)
+	assert.InDelta(t, stipend, codes[domain.ConceptApprenticeStipend], 0.01)
+	assert.InDelta(t, stipend*0.04, codes[domain.ConceptHealth], 0.01)
+	assert.NotContains(t, codes, domain.ConceptPension)
+	assert.NotContains(t, codes, domain.ConceptPensionEmployer)
+}
+
+func TestPayrollCalculator_Calculate_ServiceContractRules(t *testing.T) {
+	ctx := context.Background()
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, new(MockPayrollRepo), new(MockPayrollItemRepo), mockEmployeeRepo,
+		mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	contract := &domain.EmployeeContract{
+		ID:           1,
+		EmployeeID:   1,
+		BaseSalary:   4000000,
+		ContractType: domain.ContractType{Code: domain.ContractTypeService, NoDeductions: true, RequiresSocialSecurityProof: true},
+	}
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(contract, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 4},
+	}, nil)
+	req := CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Sin planilla de seguridad social no se liquida
+	_, err := calculator.Calculate(ctx, req)
+	assert.ErrorIs(t, err, domain.ErrSocialSecurityProofRequired)
+
+	contract.SocialSecurityProof = "PILA-2024-01-889"
+	result, err := calculator.Calculate(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), result.TotalDeductions)
+	assert.Equal(t, float64(4000000), result.NetAmount)
+}
+
 func TestPayrollCalculator_Calculate_EmployeeNotFound(t *testing.T) {
 	ctx := context.Background()
 
@@ -315,11 +426,18 @@ func TestPayrollCalculator_Calculate_EmployeeNotFound(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	mockEmployeeRepo.On("GetByID", ctx, uint(999)).Return(nil, domain.ErrEmployeeNotFound)
@@ -347,11 +465,18 @@ func TestPayrollCalculator_Calculate_NoActiveContract(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	employee := &domain.Employee{ID: 1, TenantID: 1}
@@ -382,11 +507,18 @@ func TestPayrollCalculator_Calculate_NoConcepts(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	employee := &domain.Employee{ID: 1, TenantID: 1}
@@ -419,11 +551,18 @@ func TestPayrollCalculator_Calculate_InvalidPeriod(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	// PeriodEnd before PeriodStart
@@ -450,11 +589,18 @@ func TestPayrollCalculator_Calculate_PartialPeriod(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	employee := &domain.Employee{ID: 1, TenantID: 1}
@@ -490,7 +636,7 @@ func TestPayrollCalculator_Calculate_PartialPeriod(t *testing.T) {
 }
 
 func TestPayrollCalculator_CalculateAndSave_NewPayroll(t *testing.T) {
-	ctx := context.Background()
+	ctx := withActor(context.Background(), 3)
 
 	mockPayrollRepo := new(MockPayrollRepo)
 	mockPayrollItemRepo := new(MockPayrollItemRepo)
@@ -499,11 +645,18 @@ func TestPayrollCalculator_CalculateAndSave_NewPayroll(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	employee := &domain.Employee{ID: 1, TenantID: 1}
@@ -531,6 +684,8 @@ func TestPayrollCalculator_CalculateAndSave_NewPayroll(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.NotZero(t, result.Payroll.ID)
+	// Quien liquida queda registrado para la doble revisión al aprobar
+	assert.Equal(t, uint(3), result.Payroll.CalculatedBy)
 
 	mockPayrollRepo.AssertCalled(t, "Create", ctx, mock.AnythingOfType("*domain.Payroll"))
 	mockPayrollItemRepo.AssertCalled(t, "CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem"))
@@ -546,11 +701,18 @@ func TestPayrollCalculator_CalculateAndSave_UpdateExisting(t *testing.T) {
 	mockConceptRepo := new(MockConceptRepo)
 
 	calculator := NewPayrollCalculatorService(
+		&MockTxManager{},
 		mockPayrollRepo,
 		mockPayrollItemRepo,
 		mockEmployeeRepo,
 		mockContractRepo,
 		mockConceptRepo,
+		newOpenPeriodRepo(),
+		newEmptyRetroRepo(),
+		newAccumulatorRepoMock(),
+		newCostAllocationRepoMock(),
+		newAbsenceRepoMock(),
+		nil,
 	)
 
 	employee := &domain.Employee{ID: 1, TenantID: 1}
@@ -586,6 +748,132 @@ func TestPayrollCalculator_CalculateAndSave_UpdateExisting(t *testing.T) {
 	mockPayrollItemRepo.AssertCalled(t, "DeleteByPayrollID", ctx, uint(5))
 }
 
+func TestPayrollCalculator_CalculateAndSave_RecalculateApprovedIsRecorded(t *testing.T) {
+	ctx := withActor(context.Background(), 3)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	historyRepo, transitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), mockEmployeeRepo, new(MockEmployeeBankAccountRepo), historyRepo, transitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo,
+		newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), stateSvc)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 2000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+	}, nil)
+	approved := &domain.Payroll{ID: 5, EmployeeID: 1, Status: domain.PayrollStatusApproved, CalculatedBy: 2, ApprovedBy: 4}
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).Return(approved, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("DeleteByPayrollID", ctx, uint(5)).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	req := CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	result, err := calculator.CalculateAndSave(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PayrollStatusDraft, result.Payroll.Status)
+	assert.Equal(t, uint(3), result.Payroll.CalculatedBy)
+	historyRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(h *domain.PayrollStatusHistory) bool {
+		return h.PayrollID == 5 && h.FromStatus == domain.PayrollStatusApproved &&
+			h.ToStatus == domain.PayrollStatusDraft && h.ActorID == 3
+	}))
+}
+
+func TestPayrollCalculator_CalculateAndSave_NotRecalculable(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo,
+		newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 2000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+	}, nil)
+	req := CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Una nómina reversada se corrige con su reemplazo, no recalculando el periodo
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).
+		Return(&domain.Payroll{ID: 5, EmployeeID: 1, Status: domain.PayrollStatusReversed}, nil).Once()
+
+	_, err := calculator.CalculateAndSave(ctx, req)
+
+	assert.ErrorIs(t, err, domain.ErrPayrollNotRecalculable)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	// El error al borrar los items anteriores no se ignora
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).
+		Return(&domain.Payroll{ID: 6, EmployeeID: 1, Status: domain.PayrollStatusDraft}, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("DeleteByPayrollID", ctx, uint(6)).Return(errors.New("db down"))
+
+	_, err = calculator.CalculateAndSave(ctx, req)
+
+	assert.EqualError(t, err, "db down")
+	mockPayrollItemRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestPayrollCalculator_CalculateAndSave_SupersedesCancelled(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	retroRepo := new(MockRetroAdjustmentRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo,
+		newOpenPeriodRepo(), retroRepo, newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, EmployeeID: 1, BaseSalary: 2000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+	}, nil)
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).
+		Return(&domain.Payroll{ID: 5, EmployeeID: 1, Status: domain.PayrollStatusCancelled, Kind: domain.PayrollKindRegular}, nil)
+	mockPayrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	// El retroactivo que recibía la nómina anulada pasa a la nueva
+	retroRepo.On("ListByTarget", ctx, uint(5)).Return([]domain.RetroAdjustment{
+		{ID: 9, SourcePayrollID: 2, ConceptID: 1, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary,
+			Name: "Salario Base", Amount: 100000, PeriodStart: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	retroRepo.On("ListPending", ctx, uint(1), mock.Anything).Return([]domain.RetroAdjustment{}, nil)
+	retroRepo.On("AssignTarget", ctx, []uint{9}, uint(1)).Return(nil)
+
+	result, err := calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), result.Payroll.ID)
+	assert.Equal(t, domain.PayrollStatusDraft, result.Payroll.Status)
+	assert.Equal(t, float64(2100000), result.GrossAmount)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	retroRepo.AssertCalled(t, "AssignTarget", ctx, []uint{9}, uint(1))
+}
+
 // ========================================
 // Test para PayrollStateService
 // ========================================
@@ -610,14 +898,42 @@ func (m *MockPaymentRepo) GetByID(ctx context.Context, id uint) (*domain.Payment
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepo) GetByPayrollID(ctx context.Context, payrollID uint) (*domain.Payment, error) {
+func (m *MockPaymentRepo) LockByID(ctx context.Context, id uint) (*domain.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.Payment, error) {
 	args := m.Called(ctx, payrollID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.Payment, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepo) ListByStatus(ctx context.Context, status string) ([]domain.Payment, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepo) GetByProviderRef(ctx context.Context, reference string) (*domain.Payment, error) {
+	args := m.Called(ctx, reference)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepo) UpdateStatus(ctx context.Context, payment *domain.Payment) error {
+	args := m.Called(ctx, payment)
+	return args.Error(0)
+}
+
 func (m *MockPaymentRepo) Delete(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -629,26 +945,38 @@ func TestPayrollStateService_MarkAsPaid_Success(t *testing.T) {
 	mockPayrollRepo := new(MockPayrollRepo)
 	mockPaymentRepo := new(MockPaymentRepo)
 	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockAccountRepo := new(MockEmployeeBankAccountRepo)
 
-	stateSvc := NewPayrollStateService(mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo, mockAccountRepo, mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
 
 	payroll := &domain.Payroll{
 		ID:         1,
 		EmployeeID: 1,
-		Status:     domain.PayrollStatusCalculated,
+		Status:     domain.PayrollStatusApproved,
 		NetAmount:  1800000,
 	}
 
-	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{}, nil)
+	mockAccountRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeBankAccount{
+		{ID: 5, EmployeeID: 1, BankName: "Bancolombia", AccountType: domain.BankAccountSavings, AccountNumber: "00123456789", IsPrimary: true, IsActive: true},
+		{ID: 6, EmployeeID: 1, BankName: "Davivienda", AccountType: domain.BankAccountSavings, AccountNumber: "001234567890", IsActive: true},
+	}, nil)
 	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
 	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
 
-	payment, err := stateSvc.MarkAsPaid(ctx, 1, "bank_transfer")
+	payments, err := stateSvc.MarkAsPaid(ctx, 1, "bank_transfer")
 
 	assert.NoError(t, err)
-	assert.NotNil(t, payment)
+	assert.Len(t, payments, 1)
+	payment := payments[0]
 	assert.Equal(t, float64(1800000), payment.Amount)
-	assert.Equal(t, "completed", payment.Status)
+	assert.Equal(t, domain.PaymentStatusPending, payment.Status)
+	// Se paga a la cuenta principal con la copia de sus datos
+	assert.Equal(t, uint(5), *payment.BankAccountID)
+	assert.Equal(t, "Bancolombia", payment.BankName)
+	assert.Equal(t, "00123456789", payment.AccountNumber)
 
 	// Verificar que el estado cambió
 	mockPayrollRepo.AssertCalled(t, "Update", ctx, mock.MatchedBy(func(p *domain.Payroll) bool {
@@ -663,14 +991,15 @@ func TestPayrollStateService_MarkAsPaid_AlreadyPaid(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepo)
 	mockEmployeeRepo := new(MockEmployeeRepo)
 
-	stateSvc := NewPayrollStateService(mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo, new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
 
 	payroll := &domain.Payroll{
 		ID:     1,
 		Status: domain.PayrollStatusPaid,
 	}
 
-	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
 
 	_, err := stateSvc.MarkAsPaid(ctx, 1, "bank_transfer")
 
@@ -685,7 +1014,8 @@ func TestPayrollStateService_RevertToDraft_Success(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepo)
 	mockEmployeeRepo := new(MockEmployeeRepo)
 
-	stateSvc := NewPayrollStateService(mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo, new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
 
 	payroll := &domain.Payroll{
 		ID:     1,
@@ -710,7 +1040,8 @@ func TestPayrollStateService_RevertToDraft_CannotRevertPaid(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepo)
 	mockEmployeeRepo := new(MockEmployeeRepo)
 
-	stateSvc := NewPayrollStateService(mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, mockEmployeeRepo, new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
 
 	payroll := &domain.Payroll{
 		ID:     1,
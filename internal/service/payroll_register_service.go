@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/xlsx"
+)
+
+// registerBatchSize es el número de nóminas que se leen por consulta al escribir el libro
+const registerBatchSize = 200
+
+// registerTypeOrder agrupa las columnas de conceptos: devengos, deducciones y aportes
+var registerTypeOrder = map[string]int{
+	domain.PayrollTypeEarning:              0,
+	domain.PayrollTypeDeduction:            1,
+	domain.PayrollTypeEmployerContribution: 2,
+}
+
+// PayrollRegister es el libro de nómina listo para escribirse: el filtro validado y una
+// columna por cada código de concepto presente en las nóminas
+type PayrollRegister struct {
+	Filter  domain.PayrollRegisterFilter
+	Columns []domain.PayrollItem
+}
+
+// Header retorna los encabezados: datos del empleado, un concepto por columna y los totales
+func (r *PayrollRegister) Header() []string {
+	header := []string{"payroll_id", "employee_id", "dni", "employee_name", "department", "position",
+		"period_start", "period_end", "days_worked", "status"}
+	for _, c := range r.Columns {
+		header = append(header, c.Code)
+	}
+	return append(header, "gross_amount", "total_deductions", "net_amount", "employer_contributions")
+}
+
+// PayrollRegisterService genera el libro de nómina con una fila por nómina y una columna por
+// concepto. Las nóminas se leen y escriben por lotes para no cargar el periodo en memoria y
+// se limitan a los empleados del alcance de datos del actor.
+type PayrollRegisterService struct {
+	registerRepo domain.PayrollRegisterRepo
+	scopeSvc     *DataScopeService
+}
+
+func NewPayrollRegisterService(registerRepo domain.PayrollRegisterRepo, scopeSvc *DataScopeService) *PayrollRegisterService {
+	return &PayrollRegisterService{
+		registerRepo: registerRepo,
+		scopeSvc:     scopeSvc,
+	}
+}
+
+// Prepare valida el filtro y resuelve las columnas de conceptos antes de empezar a escribir
+func (s *PayrollRegisterService) Prepare(ctx context.Context, filter domain.PayrollRegisterFilter) (*PayrollRegister, error) {
+	if len(filter.PayrollIDs) == 0 &&
+		(filter.PeriodStart.IsZero() || filter.PeriodEnd.IsZero() || filter.PeriodEnd.Before(filter.PeriodStart)) {
+		return nil, domain.ErrInvalidRegisterFilter
+	}
+	if len(filter.PayrollIDs) == 0 {
+		filter.PeriodEnd = filter.PeriodEnd.Add(24*time.Hour - time.Nanosecond)
+	}
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourcePayrolls)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := s.registerRepo.ListConcepts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, domain.ErrNoPayrollsForRegister
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		if registerTypeOrder[columns[i].Type] != registerTypeOrder[columns[j].Type] {
+			return registerTypeOrder[columns[i].Type] < registerTypeOrder[columns[j].Type]
+		}
+		return columns[i].Code < columns[j].Code
+	})
+	return &PayrollRegister{Filter: filter, Columns: columns}, nil
+}
+
+// registerWriter escribe las filas del libro en un formato de archivo
+type registerWriter interface {
+	WriteRow(values ...any) error
+	Flush() error
+	Close() error
+}
+
+// Write escribe el libro en el formato pedido (csv o xlsx) terminando con una fila TOTAL
+func (s *PayrollRegisterService) Write(ctx context.Context, register *PayrollRegister, format string, w io.Writer) error {
+	var out registerWriter
+	switch format {
+	case "csv":
+		out = &csvRegisterWriter{w: csv.NewWriter(w)}
+	case "xlsx":
+		sheet, err := xlsx.NewWriter(w, "Libro de nómina")
+		if err != nil {
+			return err
+		}
+		out = sheet
+	default:
+		return domain.ErrInvalidRegisterFormat
+	}
+
+	header := register.Header()
+	headerRow := make([]any, len(header))
+	for i, h := range header {
+		headerRow[i] = h
+	}
+	if err := out.WriteRow(headerRow...); err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(register.Columns))
+	for i, c := range register.Columns {
+		index[c.Code] = i
+	}
+	totals := make([]float64, len(register.Columns)+4)
+	count := 0
+
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourcePayrolls)
+	if err != nil {
+		return err
+	}
+	err = s.registerRepo.Stream(ctx, register.Filter, registerBatchSize, func(payrolls []domain.Payroll) error {
+		for i := range payrolls {
+			p := &payrolls[i]
+			amounts := make([]float64, len(register.Columns))
+			var employer float64
+			for _, item := range p.Items {
+				if col, ok := index[item.Code]; ok {
+					amounts[col] += item.Amount
+				}
+				if item.Type == domain.PayrollTypeEmployerContribution {
+					employer += item.Amount
+				}
+			}
+			row := []any{p.ID, p.EmployeeID, strings.TrimSpace(p.Employee.User.Dni),
+				strings.TrimSpace(p.Employee.User.FirstName + " " + p.Employee.User.LastName),
+				p.Employee.Department.Name, p.Employee.Position.NamePosition,
+				p.PeriodStart, p.PeriodEnd, registerDays(p.PeriodStart, p.PeriodEnd), p.Status}
+			for col, amount := range amounts {
+				row = append(row, roundCents(amount))
+				totals[col] += amount
+			}
+			summary := []float64{p.GrossAmount, p.TotalDeductions, p.NetAmount, employer}
+			for k, amount := range summary {
+				row = append(row, roundCents(amount))
+				totals[len(amounts)+k] += amount
+			}
+			if err := out.WriteRow(row...); err != nil {
+				return err
+			}
+			count++
+		}
+		return out.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	total := make([]any, len(header))
+	total[0] = "TOTAL"
+	total[1] = count
+	for k, amount := range totals {
+		total[len(header)-len(totals)+k] = roundCents(amount)
+	}
+	if err := out.WriteRow(total...); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// registerDays cuenta los días liquidados sobre base 30; un periodo que termina el último día
+// del mes se completa a 30 como en la nómina
+func registerDays(start, end time.Time) int {
+	days := domain.Days360(start, end)
+	if end.AddDate(0, 0, 1).Day() == 1 && end.Day() < 30 {
+		days += 30 - end.Day()
+	}
+	return days
+}
+
+// csvRegisterWriter escribe el libro como CSV con los valores en formato plano
+type csvRegisterWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRegisterWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch value := v.(type) {
+		case nil:
+		case string:
+			record[i] = value
+		case float64:
+			record[i] = strconv.FormatFloat(value, 'f', 2, 64)
+		case int:
+			record[i] = strconv.Itoa(value)
+		case uint:
+			record[i] = strconv.FormatUint(uint64(value), 10)
+		case time.Time:
+			record[i] = value.Format("2006-01-02")
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRegisterWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRegisterWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPayrollRegisterRepo struct {
+	mock.Mock
+}
+
+func (m *MockPayrollRegisterRepo) ListConcepts(ctx context.Context, filter domain.PayrollRegisterFilter) ([]domain.PayrollItem, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.PayrollItem), args.Error(1)
+}
+
+// Stream entrega cada nómina configurada en un lote distinto para probar la escritura por lotes
+func (m *MockPayrollRegisterRepo) Stream(ctx context.Context, filter domain.PayrollRegisterFilter, batchSize int, fn func([]domain.Payroll) error) error {
+	args := m.Called(ctx, filter, batchSize)
+	for _, p := range args.Get(0).([]domain.Payroll) {
+		if err := fn([]domain.Payroll{p}); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func registerPayrolls() []domain.Payroll {
+	employee := func(id uint, first, last, dni, department string) domain.Employee {
+		return domain.Employee{
+			ID:         id,
+			User:       domain.User{FirstName: first, LastName: last, Dni: dni},
+			Department: domain.Department{Name: department},
+			Position:   domain.Position{NamePosition: "Analista"},
+		}
+	}
+	return []domain.Payroll{
+		{
+			ID: 5, EmployeeID: 1, Status: domain.PayrollStatusPaid, PeriodStart: pilaDate(2, 1), PeriodEnd: pilaDate(2, 28),
+			GrossAmount: 2200000, TotalDeductions: 160000, NetAmount: 2040000,
+			Employee: employee(1, "Ana", "Gómez", "12345678", "Finanzas"),
+			Items: []domain.PayrollItem{
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptTransport, Amount: 200000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 80000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 80000},
+				{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 240000},
+			},
+		},
+		{
+			ID: 6, EmployeeID: 2, Status: domain.PayrollStatusApproved, PeriodStart: pilaDate(2, 16), PeriodEnd: pilaDate(2, 28),
+			GrossAmount: 750000, TotalDeductions: 30000, NetAmount: 720000,
+			Employee: employee(2, "Luis", "Peña", "98765432", "Ventas, Norte"),
+			Items: []domain.PayrollItem{
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 750000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 30000},
+			},
+		},
+	}
+}
+
+func newRegisterService(ctx context.Context, scopeSvc *DataScopeService) (*PayrollRegisterService, *MockPayrollRegisterRepo) {
+	repo := new(MockPayrollRegisterRepo)
+	repo.On("ListConcepts", ctx, mock.Anything).Return([]domain.PayrollItem{
+		{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptTransport},
+		{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth},
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary},
+	}, nil)
+	repo.On("Stream", ctx, mock.Anything, registerBatchSize).Return(registerPayrolls(), nil)
+	return NewPayrollRegisterService(repo, scopeSvc), repo
+}
+
+func TestPayrollRegisterService_WriteCSV(t *testing.T) {
+	scopeSvc, _, ctx := newDataScopeService(roleWithScope(domain.ResourcePayrolls, domain.DataScopeAll))
+	svc, repo := newRegisterService(ctx, scopeSvc)
+	filter := domain.PayrollRegisterFilter{
+		PeriodStart: pilaDate(2, 1), PeriodEnd: pilaDate(2, 28), DepartmentIDs: []uint{3}, Statuses: []string{"paid", "approved"},
+	}
+
+	register, err := svc.Prepare(ctx, filter)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, svc.Write(ctx, register, "csv", &buf))
+
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, rows, 4)
+	// Columnas de conceptos agrupadas: devengos, deducciones y aportes
+	assert.Equal(t, "payroll_id,employee_id,dni,employee_name,department,position,period_start,period_end,days_worked,status,"+
+		"BASE_SALARY,TRANSPORT,HEALTH,PENSION,PENSION_EMPLOYER,gross_amount,total_deductions,net_amount,employer_contributions", rows[0])
+	assert.Equal(t, "5,1,12345678,Ana Gómez,Finanzas,Analista,2026-02-01,2026-02-28,30,paid,"+
+		"2000000.00,200000.00,80000.00,80000.00,240000.00,2200000.00,160000.00,2040000.00,240000.00", rows[1])
+	assert.Equal(t, `6,2,98765432,Luis Peña,"Ventas, Norte",Analista,2026-02-16,2026-02-28,15,approved,`+
+		"750000.00,0.00,0.00,30000.00,0.00,750000.00,30000.00,720000.00,0.00", rows[2])
+	assert.Equal(t, "TOTAL,2,,,,,,,,,2750000.00,200000.00,80000.00,110000.00,240000.00,2950000.00,190000.00,2760000.00,240000.00", rows[3])
+
+	// El fin del periodo incluye todo el último día y los filtros llegan al repositorio
+	repo.AssertCalled(t, "Stream", ctx, mock.MatchedBy(func(f domain.PayrollRegisterFilter) bool {
+		return f.PeriodEnd.After(pilaDate(2, 28)) && f.PeriodEnd.Before(pilaDate(3, 1)) &&
+			f.DepartmentIDs[0] == 3 && len(f.Statuses) == 2
+	}), registerBatchSize)
+}
+
+func TestPayrollRegisterService_WriteXLSX(t *testing.T) {
+	scopeSvc, _, ctx := newDataScopeService(roleWithScope(domain.ResourcePayrolls, domain.DataScopeAll))
+	svc, _ := newRegisterService(ctx, scopeSvc)
+
+	register, err := svc.Prepare(ctx, domain.PayrollRegisterFilter{PayrollIDs: []uint{5, 6}})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, svc.Write(ctx, register, "xlsx", &buf))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range archive.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			sheet = string(content)
+		}
+	}
+	assert.Contains(t, sheet, `<c r="K1" t="inlineStr"><is><t xml:space="preserve">BASE_SALARY</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="K2"><v>2000000</v></c>`)
+	assert.Contains(t, sheet, `<c r="E3" t="inlineStr"><is><t xml:space="preserve">Ventas, Norte</t></is></c>`)
+	assert.Contains(t, sheet, `<row r="4"><c r="A4" t="inlineStr"><is><t xml:space="preserve">TOTAL</t></is></c><c r="B4"><v>2</v></c>`)
+	assert.Contains(t, sheet, `<c r="S4"><v>240000</v></c></row></sheetData>`)
+}
+
+func TestPayrollRegisterService_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockPayrollRegisterRepo)
+	repo.On("ListConcepts", mock.Anything, mock.Anything).Return([]domain.PayrollItem{}, nil)
+	svc := NewPayrollRegisterService(repo, nil)
+
+	_, err := svc.Prepare(ctx, domain.PayrollRegisterFilter{PeriodStart: pilaDate(2, 1)})
+	assert.ErrorIs(t, err, domain.ErrInvalidRegisterFilter)
+
+	_, err = svc.Prepare(ctx, domain.PayrollRegisterFilter{PeriodStart: pilaDate(2, 1), PeriodEnd: pilaDate(2, 28)})
+	assert.ErrorIs(t, err, domain.ErrNoPayrollsForRegister)
+
+	err = svc.Write(ctx, &PayrollRegister{}, "pdf", io.Discard)
+	assert.ErrorIs(t, err, domain.ErrInvalidRegisterFormat)
+}
+
+func TestPayrollRegisterService_AppliesDataScope(t *testing.T) {
+	scopeSvc, employeeRepo, ctx := newDataScopeService(roleWithScope(domain.ResourcePayrolls, domain.DataScopeOwnReports))
+	employeeRepo.On("GetByUserID", ctx, uint(7)).Return(&domain.Employee{ID: 10}, nil)
+	employeeRepo.On("ListByManagers", ctx, []uint{10}).Return([]domain.Employee{}, nil)
+	scoped := mock.MatchedBy(func(c context.Context) bool {
+		scope := employeeScopeFromCtx(c)
+		return scope != nil && assert.ObjectsAreEqual([]uint{10}, scope.EmployeeIDs)
+	})
+	repo := new(MockPayrollRegisterRepo)
+	repo.On("ListConcepts", scoped, mock.Anything).Return([]domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary},
+	}, nil)
+	repo.On("Stream", scoped, mock.Anything, registerBatchSize).Return([]domain.Payroll{}, nil)
+	svc := NewPayrollRegisterService(repo, scopeSvc)
+
+	register, err := svc.Prepare(ctx, domain.PayrollRegisterFilter{PayrollIDs: []uint{5}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Write(ctx, register, "csv", io.Discard))
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// PayrollRetroService detecta cambios con vigencia pasada que afectan periodos ya pagados,
+// recalcula lo que debió pagarse y liquida la diferencia como items RETRO_* en la
+// siguiente nómina abierta del empleado.
+//
+// Disparan retroactivos las enmiendas de contrato (salario, auxilios) y las novedades de
+// ausencia que cambian los días pagados (licencias no remuneradas). Solo se comparan los
+// items que dependen del contrato; los demás conceptos no se reliquidan con la
+// configuración actual. Las horas extra no tienen novedad con fecha en este sistema: se
+// pagan con los conceptos asignados al empleado y deben registrarse con el periodo abierto.
+type PayrollRetroService struct {
+	txManager    domain.TxManager
+	payrollRepo  domain.PayrollRepo
+	employeeRepo domain.EmployeeRepo
+	contractRepo domain.EmployeeContractRepo
+	conceptRepo  domain.PayrollConceptRepo
+	retroRepo    domain.RetroAdjustmentRepo
+	calculator   *PayrollCalculatorService
+}
+
+func NewPayrollRetroService(
+	txManager domain.TxManager,
+	payrollRepo domain.PayrollRepo,
+	employeeRepo domain.EmployeeRepo,
+	contractRepo domain.EmployeeContractRepo,
+	conceptRepo domain.PayrollConceptRepo,
+	retroRepo domain.RetroAdjustmentRepo,
+	calculator *PayrollCalculatorService,
+) *PayrollRetroService {
+	return &PayrollRetroService{
+		txManager:    txManager,
+		payrollRepo:  payrollRepo,
+		employeeRepo: employeeRepo,
+		contractRepo: contractRepo,
+		conceptRepo:  conceptRepo,
+		retroRepo:    retroRepo,
+		calculator:   calculator,
+	}
+}
+
+// RetroRunResult resume los ajustes generados en una ejecución del motor retroactivo
+type RetroRunResult struct {
+	EmployeeID  uint                     `json:"employee_id"`
+	Adjustments []domain.RetroAdjustment `json:"adjustments"`
+	// NetDifference es el efecto sobre el neto: devengos suman, deducciones restan
+	NetDifference float64 `json:"net_difference"`
+	// TargetPayrollID es la nómina abierta que recibió los ajustes; si es nil quedan
+	// pendientes y se aplican en el próximo cálculo del empleado
+	TargetPayrollID *uint `json:"target_payroll_id,omitempty"`
+}
+
+// RetroPeriodBreakdown agrupa los ajustes originados en un periodo pagado
+type RetroPeriodBreakdown struct {
+	SourcePayrollID uint                     `json:"source_payroll_id"`
+	PeriodStart     time.Time                `json:"period_start"`
+	PeriodEnd       time.Time                `json:"period_end"`
+	Adjustments     []domain.RetroAdjustment `json:"adjustments"`
+	NetDifference   float64                  `json:"net_difference"`
+}
+
+// Run recalcula los periodos pagados del empleado que terminan en o después de since (la
+// fecha de vigencia del cambio; cero recalcula todos). Los ajustes ya generados para un
+// periodo se descuentan, por lo que es idempotente.
+func (s *PayrollRetroService) Run(ctx context.Context, employeeID uint, since time.Time, reason string) (*RetroRunResult, error) {
+	if employeeID == 0 {
+		return nil, errors.New("employee id is required")
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	payrolls, err := s.payrollRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := s.contractRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	concepts, err := s.conceptRepo.GetActiveConcepts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := s.calculator.absenceRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		reason = "change recorded after payment"
+	}
+
+	result := &RetroRunResult{EmployeeID: employeeID, Adjustments: []domain.RetroAdjustment{}}
+	var lastSourceEnd time.Time
+
+	for i := range payrolls {
+		paid := &payrolls[i]
+		if !isRetroSource(paid) || paid.PeriodEnd.Before(since) {
+			continue
+		}
+		contract := effectiveContract(contracts, paid.PeriodStart, paid.PeriodEnd)
+		if contract == nil {
+			continue
+		}
+
+		expected := s.calculator.buildPayroll(employee, contract, concepts, absences, CalculatePayrollRequest{
+			EmployeeID:  employeeID,
+			PeriodStart: paid.PeriodStart,
+			PeriodEnd:   paid.PeriodEnd,
+			PayDate:     paid.PayDate,
+		})
+		previous, err := s.retroRepo.ListBySource(ctx, paid.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		adjustments := diffPayrollItems(paid, expected.Items, previous, reason)
+		if len(adjustments) == 0 {
+			continue
+		}
+		result.Adjustments = append(result.Adjustments, adjustments...)
+		if paid.PeriodEnd.After(lastSourceEnd) {
+			lastSourceEnd = paid.PeriodEnd
+		}
+	}
+
+	if len(result.Adjustments) == 0 {
+		return result, nil
+	}
+	result.NetDifference = retroNetEffect(result.Adjustments)
+
+	target := nextOpenPayroll(payrolls, lastSourceEnd)
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.retroRepo.CreateBatch(ctx, result.Adjustments); err != nil {
+			return err
+		}
+		if target == nil {
+			return nil
+		}
+		// El recálculo de la nómina abierta toma los ajustes pendientes y los asigna
+		_, err := s.calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+			EmployeeID:  employeeID,
+			PeriodStart: target.PeriodStart,
+			PeriodEnd:   target.PeriodEnd,
+			PayDate:     target.PayDate,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if target != nil {
+		result.TargetPayrollID = &target.ID
+		for i := range result.Adjustments {
+			result.Adjustments[i].TargetPayrollID = &target.ID
+		}
+	}
+	return result, nil
+}
+
+// ListByEmployee retorna el desglose por periodo de los ajustes retroactivos del empleado
+func (s *PayrollRetroService) ListByEmployee(ctx context.Context, employeeID uint) ([]RetroPeriodBreakdown, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	adjustments, err := s.retroRepo.ListByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return groupRetroByPeriod(adjustments), nil
+}
+
+// ListByPayroll retorna el desglose de los ajustes liquidados en una nómina
+func (s *PayrollRetroService) ListByPayroll(ctx context.Context, payrollID uint) ([]RetroPeriodBreakdown, error) {
+	if payrollID == 0 {
+		return nil, errors.New("invalid payroll id")
+	}
+	adjustments, err := s.retroRepo.ListByTarget(ctx, payrollID)
+	if err != nil {
+		return nil, err
+	}
+	return groupRetroByPeriod(adjustments), nil
+}
+
+// isRetroSource indica si una nómina representa un pago vigente que puede generar retroactivos
+func isRetroSource(payroll *domain.Payroll) bool {
+	if payroll.Status != domain.PayrollStatusPaid {
+		return false
+	}
+	switch payroll.Kind {
+	case "", domain.PayrollKindRegular, domain.PayrollKindReplacement:
+		return true
+	}
+	return false
+}
+
+// effectiveContract retorna el contrato vigente al cierre del periodo. Los contratos vienen
+// ordenados por fecha de inicio descendente, así que gana el más reciente.
+func effectiveContract(contracts []domain.EmployeeContract, start, end time.Time) *domain.EmployeeContract {
+	for i := range contracts {
+		c := &contracts[i]
+		if c.StartDate.After(end) {
+			continue
+		}
+		if c.EndDate != nil && c.EndDate.Before(start) {
+			continue
+		}
+		return c
+	}
+	return nil
+}
+
+// nextOpenPayroll busca la primera nómina regular sin pagar posterior a los periodos ajustados
+func nextOpenPayroll(payrolls []domain.Payroll, after time.Time) *domain.Payroll {
+	var next *domain.Payroll
+	for i := range payrolls {
+		p := &payrolls[i]
+		if p.Kind != "" && p.Kind != domain.PayrollKindRegular {
+			continue
+		}
+		if p.Status != domain.PayrollStatusDraft && p.Status != domain.PayrollStatusCalculated {
+			continue
+		}
+		if !p.PeriodStart.After(after) {
+			continue
+		}
+		if next == nil || p.PeriodStart.Before(next.PeriodStart) {
+			next = p
+		}
+	}
+	return next
+}
+
+// retroContractCodes son los items que salen directamente del contrato y de los días pagados
+var retroContractCodes = map[string]bool{
+	domain.ConceptBaseSalary:        true,
+	domain.ConceptApprenticeStipend: true,
+	domain.ConceptTransport:         true,
+	domain.ConceptHousing:           true,
+}
+
+// retroSalaryBasedCodes son los aportes y deducciones que se liquidan como porcentaje del
+// salario; los de prestaciones incluyen además el auxilio de transporte en la base
+var retroSalaryBasedCodes = map[string]bool{
+	domain.ConceptHealth:                     true,
+	domain.ConceptPension:                    true,
+	domain.ConceptHealthEmployer:             true,
+	domain.ConceptPensionEmployer:            true,
+	domain.ConceptParafiscales:               true,
+	domain.ConceptPrimaProvision:             true,
+	domain.ConceptSeveranceProvision:         true,
+	domain.ConceptSeveranceInterestProvision: true,
+}
+
+// diffPayrollItems compara por concepto lo pagado (más los ajustes previos) contra lo esperado.
+// Solo se comparan los items del contrato; los porcentajes sobre el salario se escalan con la
+// tasa que se aplicó en el periodo, no con la configuración actual de los conceptos. Los demás
+// conceptos (bonos, horas extra, otras deducciones) no se reliquidan. La retención (TAX) no se
+// compara: sale de la tabla sobre los pagos del mes, así que los retroactivos se gravan al
+// liquidarse en la nómina que los recibe.
+func diffPayrollItems(
+	paid *domain.Payroll,
+	expected []domain.PayrollItem,
+	previous []domain.RetroAdjustment,
+	reason string,
+) []domain.RetroAdjustment {
+	paidByCode := make(map[string]float64)
+	expectedByCode := make(map[string]float64)
+	meta := make(map[string]domain.PayrollItem)
+	var codes []string
+
+	addCode := func(item domain.PayrollItem) {
+		if _, ok := meta[item.Code]; !ok {
+			meta[item.Code] = item
+			codes = append(codes, item.Code)
+		}
+	}
+	for _, item := range expected {
+		expectedByCode[item.Code] += item.Amount
+		if retroContractCodes[item.Code] {
+			addCode(item)
+		}
+	}
+	// Los aportes sobre el salario solo se ajustan si se liquidaron en el periodo pagado
+	for _, item := range paid.Items {
+		if !retroContractCodes[item.Code] && !retroSalaryBasedCodes[item.Code] {
+			continue
+		}
+		paidByCode[item.Code] += item.Amount
+		addCode(item)
+	}
+
+	salary := func(byCode map[string]float64) float64 {
+		return byCode[domain.ConceptBaseSalary] + byCode[domain.ConceptApprenticeStipend]
+	}
+	provisions := domain.BenefitProvisionConcepts()
+	for _, code := range codes {
+		if !retroSalaryBasedCodes[code] {
+			continue
+		}
+		paidBase, expectedBase := salary(paidByCode), salary(expectedByCode)
+		if _, ok := provisions[code]; ok {
+			paidBase += paidByCode[domain.ConceptTransport]
+			expectedBase += expectedByCode[domain.ConceptTransport]
+		}
+		if paidBase > 0 {
+			expectedByCode[code] = paidByCode[code] * expectedBase / paidBase
+		}
+	}
+
+	adjustedByCode := make(map[string]float64)
+	for _, adj := range previous {
+		adjustedByCode[adj.Code] += adj.Amount
+	}
+
+	var adjustments []domain.RetroAdjustment
+	for _, code := range codes {
+		delta := roundCents(expectedByCode[code] - paidByCode[code] - adjustedByCode[code])
+		if delta == 0 {
+			continue
+		}
+		item := meta[code]
+		adjustments = append(adjustments, domain.RetroAdjustment{
+			EmployeeID:      paid.EmployeeID,
+			SourcePayrollID: paid.ID,
+			PeriodStart:     paid.PeriodStart,
+			PeriodEnd:       paid.PeriodEnd,
+			ConceptID:       item.ConceptID,
+			Type:            item.Type,
+			Code:            code,
+			Name:            item.Name,
+			PaidAmount:      paidByCode[code] + adjustedByCode[code],
+			ExpectedAmount:  roundCents(expectedByCode[code]),
+			Amount:          delta,
+			Reason:          reason,
+		})
+	}
+	return adjustments
+}
+
+func groupRetroByPeriod(adjustments []domain.RetroAdjustment) []RetroPeriodBreakdown {
+	breakdown := []RetroPeriodBreakdown{}
+	index := make(map[uint]int)
+	for _, adj := range adjustments {
+		i, ok := index[adj.SourcePayrollID]
+		if !ok {
+			i = len(breakdown)
+			index[adj.SourcePayrollID] = i
+			breakdown = append(breakdown, RetroPeriodBreakdown{
+				SourcePayrollID: adj.SourcePayrollID,
+				PeriodStart:     adj.PeriodStart,
+				PeriodEnd:       adj.PeriodEnd,
+			})
+		}
+		breakdown[i].Adjustments = append(breakdown[i].Adjustments, adj)
+	}
+	for i := range breakdown {
+		breakdown[i].NetDifference = retroNetEffect(breakdown[i].Adjustments)
+	}
+	return breakdown
+}
+
+func retroNetEffect(adjustments []domain.RetroAdjustment) float64 {
+	var net float64
+	for _, adj := range adjustments {
+		switch adj.Type {
+		case domain.PayrollTypeEarning:
+			net += adj.Amount
+		case domain.PayrollTypeDeduction:
+			net -= adj.Amount
+		}
+	}
+	return roundCents(net)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
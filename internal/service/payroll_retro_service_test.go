@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRetroAdjustmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockRetroAdjustmentRepo) CreateBatch(ctx context.Context, adjustments []domain.RetroAdjustment) error {
+	args := m.Called(ctx, adjustments)
+	return args.Error(0)
+}
+
+func (m *MockRetroAdjustmentRepo) ListBySource(ctx context.Context, sourcePayrollID uint) ([]domain.RetroAdjustment, error) {
+	args := m.Called(ctx, sourcePayrollID)
+	return args.Get(0).([]domain.RetroAdjustment), args.Error(1)
+}
+
+func (m *MockRetroAdjustmentRepo) ListByTarget(ctx context.Context, targetPayrollID uint) ([]domain.RetroAdjustment, error) {
+	args := m.Called(ctx, targetPayrollID)
+	return args.Get(0).([]domain.RetroAdjustment), args.Error(1)
+}
+
+func (m *MockRetroAdjustmentRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.RetroAdjustment, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.RetroAdjustment), args.Error(1)
+}
+
+func (m *MockRetroAdjustmentRepo) ListPending(ctx context.Context, employeeID uint, before time.Time) ([]domain.RetroAdjustment, error) {
+	args := m.Called(ctx, employeeID, before)
+	return args.Get(0).([]domain.RetroAdjustment), args.Error(1)
+}
+
+func (m *MockRetroAdjustmentRepo) AssignTarget(ctx context.Context, ids []uint, targetPayrollID uint) error {
+	args := m.Called(ctx, ids, targetPayrollID)
+	return args.Error(0)
+}
+
+// newEmptyRetroRepo crea un repo de retroactivos sin ajustes pendientes ni asignados
+func newEmptyRetroRepo() *MockRetroAdjustmentRepo {
+	repo := new(MockRetroAdjustmentRepo)
+	repo.On("ListPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.RetroAdjustment{}, nil)
+	repo.On("ListByTarget", mock.Anything, mock.Anything).Return([]domain.RetroAdjustment{}, nil)
+	repo.On("ListBySource", mock.Anything, mock.Anything).Return([]domain.RetroAdjustment{}, nil)
+	return repo
+}
+
+// retroFixture arma un empleado con enero pagado a 2.000.000 y un aumento a 2.500.000
+// registrado después del pago con inicio en enero
+func retroFixture(ctx context.Context) (*MockPayrollRepo, *MockEmployeeRepo, *MockContractRepo, *MockConceptRepo, domain.Payroll) {
+	paidAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	january := domain.Payroll{
+		ID:          10,
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+		Status:      domain.PayrollStatusPaid,
+		Kind:        domain.PayrollKindRegular,
+		UpdatedAt:   paidAt,
+		Items: []domain.PayrollItem{
+			{ConceptID: 1, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Name: "Salario Base", Amount: 2000000},
+			{ConceptID: 2, Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Name: "Salud", Amount: 80000},
+		},
+	}
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{ID: 2, BaseSalary: 2500000, StartDate: january.PeriodStart, UpdatedAt: paidAt.AddDate(0, 0, 10)},
+		{ID: 1, BaseSalary: 2000000, StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: paidAt.AddDate(-1, 0, 0)},
+	}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 4},
+	}, nil)
+
+	return mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, january
+}
+
+func TestPayrollRetro_Run_LateRaiseLeavesPendingAdjustments(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, january := retroFixture(ctx)
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+
+	retroRepo := new(MockRetroAdjustmentRepo)
+	retroRepo.On("ListBySource", ctx, uint(10)).Return([]domain.RetroAdjustment{}, nil)
+	retroRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.RetroAdjustment")).Return(nil)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: newAbsenceRepoMock()})
+
+	result, err := retroSvc.Run(ctx, 1, time.Time{}, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Adjustments, 2)
+	assert.Equal(t, domain.ConceptBaseSalary, result.Adjustments[0].Code)
+	assert.Equal(t, float64(500000), result.Adjustments[0].Amount)
+	assert.Equal(t, float64(20000), result.Adjustments[1].Amount)
+	assert.Equal(t, float64(480000), result.NetDifference)
+	// Sin nómina abierta los ajustes quedan pendientes para el próximo cálculo
+	assert.Nil(t, result.TargetPayrollID)
+	retroRepo.AssertCalled(t, "CreateBatch", ctx, mock.Anything)
+}
+
+func TestPayrollRetro_Run_IsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, january := retroFixture(ctx)
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+
+	retroRepo := new(MockRetroAdjustmentRepo)
+	retroRepo.On("ListBySource", ctx, uint(10)).Return([]domain.RetroAdjustment{
+		{SourcePayrollID: 10, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 500000},
+		{SourcePayrollID: 10, Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 20000},
+	}, nil)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: newAbsenceRepoMock()})
+
+	result, err := retroSvc.Run(ctx, 1, time.Time{}, "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Adjustments)
+	retroRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestPayrollCalculator_CalculateAndSave_AppliesPendingRetro(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	retroRepo := new(MockRetroAdjustmentRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), retroRepo, newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	february := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 2, BaseSalary: 2500000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+	}, nil)
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).Return(nil, domain.ErrPayrollNotFound)
+	mockPayrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	retroRepo.On("ListPending", ctx, uint(1), february).Return([]domain.RetroAdjustment{
+		{ID: 7, SourcePayrollID: 10, ConceptID: 1, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary,
+			Name: "Salario Base", Amount: 500000, PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	retroRepo.On("AssignTarget", ctx, []uint{7}, uint(1)).Return(nil)
+
+	result, err := calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: february,
+		PeriodEnd:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3000000), result.GrossAmount)
+	assert.Equal(t, float64(3000000), result.Payroll.NetAmount)
+	assert.Equal(t, "RETRO_BASE_SALARY", result.Items[1].Code)
+	assert.Equal(t, "Retroactivo Salario Base 2024-01", result.Items[1].Name)
+	retroRepo.AssertCalled(t, "AssignTarget", ctx, []uint{7}, uint(1))
+}
+
+func TestPayrollRetro_Run_WithholdingIsNotRefunded(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, january := retroFixture(ctx)
+	// La retención del periodo se liquidó por tabla: no tiene porcentaje en el concepto
+	january.Items = append(january.Items,
+		domain.PayrollItem{ConceptID: 3, Type: domain.PayrollTypeDeduction, Code: domain.ConceptTax, Name: "Retención", Amount: 150000})
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+
+	retroRepo := new(MockRetroAdjustmentRepo)
+	retroRepo.On("ListBySource", ctx, uint(10)).Return([]domain.RetroAdjustment{}, nil)
+	retroRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.RetroAdjustment")).Return(nil)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: newAbsenceRepoMock()})
+
+	result, err := retroSvc.Run(ctx, 1, time.Time{}, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Adjustments, 2)
+	for _, adj := range result.Adjustments {
+		assert.NotEqual(t, domain.ConceptTax, adj.Code)
+	}
+	assert.Equal(t, float64(480000), result.NetDifference)
+}
+
+func TestPayrollCalculator_CalculateAndSave_RetroIsWithheld(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	retroRepo := new(MockRetroAdjustmentRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), retroRepo, newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 2, BaseSalary: 12000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 3, Code: domain.ConceptTax, Name: "Retención", Type: domain.PayrollTypeDeduction},
+	}, nil)
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).Return(nil, domain.ErrPayrollNotFound)
+	mockPayrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	retroRepo.On("ListPending", ctx, uint(1), march).Return([]domain.RetroAdjustment{
+		{ID: 7, SourcePayrollID: 10, ConceptID: 1, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary,
+			Name: "Salario Base", Amount: 3000000, PeriodStart: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	retroRepo.On("AssignTarget", ctx, []uint{7}, uint(1)).Return(nil)
+
+	result, err := calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: march,
+		PeriodEnd:   time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	// La retención de la nómina que recibe el retroactivo se liquida sobre salario más retroactivo
+	var tax float64
+	for _, item := range result.Items {
+		if item.Code == domain.ConceptTax {
+			tax = item.Amount
+		}
+	}
+	salaryOnly := computeWithholding([]domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 12000000},
+	}, 2026, 30, 0)
+	withRetro := computeWithholding([]domain.PayrollItem{
+		{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 12000000},
+		{Type: domain.PayrollTypeEarning, Code: "RETRO_BASE_SALARY", Amount: 3000000},
+	}, 2026, 30, 0)
+	assert.Greater(t, withRetro.Amount, salaryOnly.Amount)
+	assert.Equal(t, withRetro.Amount, tax)
+	assert.Equal(t, 15000000-tax, result.Payroll.NetAmount)
+}
+
+func TestPayrollRetro_Run_SkipsPeriodsBeforeEffectiveDate(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, january := retroFixture(ctx)
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+	retroRepo := new(MockRetroAdjustmentRepo)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: newAbsenceRepoMock()})
+
+	result, err := retroSvc.Run(ctx, 1, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Adjustments)
+	retroRepo.AssertNotCalled(t, "ListBySource", mock.Anything, mock.Anything)
+	retroRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestPayrollRetro_Run_OnlyDiffsContractDrivenItems(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, _, _, january := retroFixture(ctx)
+	january.Items = append(january.Items,
+		domain.PayrollItem{ConceptID: 3, Type: domain.PayrollTypeEarning, Code: domain.ConceptBonus, Name: "Bono", Amount: 100000})
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+	mockContractRepo := new(MockContractRepo)
+	mockContractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{ID: 1, BaseSalary: 2000000, StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	// Después del pago cambiaron los conceptos, no el contrato: salud sube al 5% y hay bono fijo
+	mockConceptRepo := new(MockConceptRepo)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptHealth, Name: "Salud", Type: domain.PayrollTypeDeduction, Percentage: 5},
+		{ID: 3, Code: domain.ConceptBonus, Name: "Bono", Type: domain.PayrollTypeEarning, EmployeePart: 300000},
+		{ID: 4, Code: domain.ConceptPension, Name: "Pensión", Type: domain.PayrollTypeDeduction, Percentage: 4},
+	}, nil)
+	retroRepo := new(MockRetroAdjustmentRepo)
+	retroRepo.On("ListBySource", ctx, uint(10)).Return([]domain.RetroAdjustment{}, nil)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: newAbsenceRepoMock()})
+
+	result, err := retroSvc.Run(ctx, 1, time.Time{}, "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Adjustments)
+	retroRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestPayrollRetro_Run_UnpaidLeaveRecordedAfterPayment(t *testing.T) {
+	ctx := context.Background()
+	mockPayrollRepo, mockEmployeeRepo, _, mockConceptRepo, january := retroFixture(ctx)
+	mockPayrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+	mockContractRepo := new(MockContractRepo)
+	mockContractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{ID: 1, BaseSalary: 2000000, StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	absenceRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeAbsence{
+		{ID: 5, EmployeeID: 1, Kind: domain.AbsenceUnpaidLeave,
+			StartDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	retroRepo := new(MockRetroAdjustmentRepo)
+	retroRepo.On("ListBySource", ctx, uint(10)).Return([]domain.RetroAdjustment{}, nil)
+	retroRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.RetroAdjustment")).Return(nil)
+
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, mockPayrollRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, retroRepo, &PayrollCalculatorService{absenceRepo: absenceRepo})
+
+	result, err := retroSvc.Run(ctx, 1, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), "unpaid leave recorded after payment")
+
+	assert.NoError(t, err)
+	// 3 días sin pago sobre 2.000.000 mensuales: -200.000 de salario y -8.000 de salud
+	assert.Len(t, result.Adjustments, 2)
+	assert.Equal(t, float64(-200000), result.Adjustments[0].Amount)
+	assert.Equal(t, float64(-8000), result.Adjustments[1].Amount)
+	assert.Equal(t, float64(-192000), result.NetDifference)
+}
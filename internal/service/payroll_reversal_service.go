@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// PayrollReversalService reversa nóminas pagadas y genera su nómina de reemplazo
+type PayrollReversalService struct {
+	txManager       domain.TxManager
+	payrollRepo     domain.PayrollRepo
+	payrollItemRepo domain.PayrollItemRepo
+	historyRepo     domain.PayrollStatusHistoryRepo
+	periodRepo      domain.AccountingPeriodRepo
+	calculator      *PayrollCalculatorService
+	accumulatorRepo domain.PayrollAccumulatorRepo
+}
+
+func NewPayrollReversalService(
+	txManager domain.TxManager,
+	payrollRepo domain.PayrollRepo,
+	payrollItemRepo domain.PayrollItemRepo,
+	historyRepo domain.PayrollStatusHistoryRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	calculator *PayrollCalculatorService,
+	accumulatorRepo domain.PayrollAccumulatorRepo,
+) *PayrollReversalService {
+	return &PayrollReversalService{
+		txManager:       txManager,
+		payrollRepo:     payrollRepo,
+		payrollItemRepo: payrollItemRepo,
+		historyRepo:     historyRepo,
+		periodRepo:      periodRepo,
+		calculator:      calculator,
+		accumulatorRepo: accumulatorRepo,
+	}
+}
+
+// ReversePayrollRequest representa la solicitud de reverso de una nómina pagada
+type ReversePayrollRequest struct {
+	PayrollID uint
+	Reason    string
+	// GenerateReplacement recalcula el periodo y crea una nómina de reemplazo
+	// cuyo neto es la diferencia a pagar (positiva) o a recuperar (negativa)
+	GenerateReplacement bool
+	PayDate             time.Time
+}
+
+// ReversalResult agrupa los documentos generados por un reverso
+type ReversalResult struct {
+	Original      *domain.Payroll
+	Reversal      *domain.Payroll
+	Replacement   *domain.Payroll
+	NetDifference float64
+}
+
+// Reverse crea una nómina de reverso con los items negados, marca la original como
+// reversada y opcionalmente genera la nómina de reemplazo, todo en una transacción
+func (s *PayrollReversalService) Reverse(ctx context.Context, req ReversePayrollRequest) (*ReversalResult, error) {
+	if req.PayrollID == 0 {
+		return nil, errors.New("payroll id is required")
+	}
+	if req.Reason == "" {
+		return nil, errors.New("reversal reason is required")
+	}
+
+	original, err := s.payrollRepo.GetByID(ctx, req.PayrollID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == domain.PayrollStatusReversed {
+		return nil, domain.ErrPayrollReversed
+	}
+	if original.Status != domain.PayrollStatusPaid || original.Kind == domain.PayrollKindReversal {
+		return nil, domain.ErrPayrollNotPaid
+	}
+	// Los pagos de prestaciones no se recalculan desde contrato
+	if req.GenerateReplacement && original.Kind == domain.PayrollKindBenefit {
+		return nil, domain.ErrPayrollNotRecalculable
+	}
+	// Los periodos cerrados se corrigen con ajustes en un periodo abierto, no con reversos
+	if err := ensurePeriodOpen(ctx, s.periodRepo, original.PeriodStart, original.PeriodEnd); err != nil {
+		return nil, err
+	}
+
+	payDate := req.PayDate
+	if payDate.IsZero() {
+		payDate = time.Now()
+	}
+
+	// El recálculo solo lee datos, se hace antes de abrir la transacción
+	var corrected *CalculatedPayroll
+	if req.GenerateReplacement {
+		corrected, err = s.calculator.Calculate(ctx, CalculatePayrollRequest{
+			EmployeeID:  original.EmployeeID,
+			PeriodStart: original.PeriodStart,
+			PeriodEnd:   original.PeriodEnd,
+			PayDate:     payDate,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	actor := actorFromCtx(ctx)
+	result := &ReversalResult{Original: original}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		reversal := &domain.Payroll{
+			TenantID:          original.TenantID,
+			EmployeeID:        original.EmployeeID,
+			PeriodStart:       original.PeriodStart,
+			PeriodEnd:         original.PeriodEnd,
+			PayDate:           payDate,
+			GrossAmount:       -original.GrossAmount,
+			TotalDeductions:   -original.TotalDeductions,
+			NetAmount:         -original.NetAmount,
+			Status:            domain.PayrollStatusPaid,
+			Kind:              domain.PayrollKindReversal,
+			OriginalPayrollID: &original.ID,
+			CalculatedBy:      actor,
+		}
+		if err := s.payrollRepo.Create(ctx, reversal); err != nil {
+			return err
+		}
+		reversal.Items = negateItems(original.Items, reversal.ID)
+		if err := s.payrollItemRepo.CreateBatch(ctx, reversal.Items); err != nil {
+			return err
+		}
+		// El reverso nace pagado: sus items negados descuentan la original de los acumulados
+		if err := accumulatePayroll(ctx, s.accumulatorRepo, reversal, 1); err != nil {
+			return err
+		}
+		if err := s.recordHistory(ctx, reversal.ID, "", domain.PayrollStatusPaid, req.Reason); err != nil {
+			return err
+		}
+
+		original.Status = domain.PayrollStatusReversed
+		if err := s.payrollRepo.Update(ctx, original); err != nil {
+			return err
+		}
+		if err := s.recordHistory(ctx, original.ID, domain.PayrollStatusPaid, domain.PayrollStatusReversed, req.Reason); err != nil {
+			return err
+		}
+		result.Reversal = reversal
+
+		if corrected == nil {
+			return nil
+		}
+
+		replacement, items := buildReplacement(original, corrected, actor)
+		if err := s.payrollRepo.Create(ctx, replacement); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].PayrollID = replacement.ID
+		}
+		if err := s.payrollItemRepo.CreateBatch(ctx, items); err != nil {
+			return err
+		}
+		if err := s.recordHistory(ctx, replacement.ID, "", domain.PayrollStatusCalculated, req.Reason); err != nil {
+			return err
+		}
+		replacement.Items = items
+		result.Replacement = replacement
+		result.NetDifference = replacement.NetAmount
+		return nil
+	})
+	if err != nil {
+		original.Status = domain.PayrollStatusPaid
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *PayrollReversalService) recordHistory(ctx context.Context, payrollID uint, from, to, reason string) error {
+	return s.historyRepo.Create(ctx, &domain.PayrollStatusHistory{
+		PayrollID:  payrollID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorFromCtx(ctx),
+		Reason:     reason,
+	})
+}
+
+// negateItems copia los items de una nómina y su reparto por centro de costo con el signo invertido
+func negateItems(items []domain.PayrollItem, payrollID uint) []domain.PayrollItem {
+	now := time.Now()
+	negated := make([]domain.PayrollItem, 0, len(items))
+	for _, item := range items {
+		var allocations []domain.PayrollItemAllocation
+		for _, a := range item.Allocations {
+			allocations = append(allocations, domain.PayrollItemAllocation{
+				CostCenterID:   a.CostCenterID,
+				CostCenterCode: a.CostCenterCode,
+				Percentage:     a.Percentage,
+				Amount:         -a.Amount,
+			})
+		}
+		negated = append(negated, domain.PayrollItem{
+			PayrollID:    payrollID,
+			ConceptID:    item.ConceptID,
+			Type:         item.Type,
+			Code:         item.Code,
+			Name:         item.Name,
+			Amount:       -item.Amount,
+			CalculatedAt: now,
+			Allocations:  allocations,
+		})
+	}
+	return negated
+}
+
+// buildReplacement arma la nómina corregida descontando el neto ya pagado en la original,
+// de modo que su neto es la diferencia a pagar o a recuperar
+func buildReplacement(original *domain.Payroll, corrected *CalculatedPayroll, actor uint) (*domain.Payroll, []domain.PayrollItem) {
+	items := make([]domain.PayrollItem, 0, len(corrected.Items)+1)
+	items = append(items, corrected.Items...)
+	items = append(items, domain.PayrollItem{
+		Type:         domain.PayrollTypeDeduction,
+		Code:         domain.ConceptPriorPayment,
+		Name:         "Pago previo nómina reversada",
+		Amount:       original.NetAmount,
+		CalculatedAt: time.Now(),
+	})
+
+	replacement := &domain.Payroll{
+		TenantID:          original.TenantID,
+		EmployeeID:        original.EmployeeID,
+		PeriodStart:       original.PeriodStart,
+		PeriodEnd:         original.PeriodEnd,
+		PayDate:           corrected.Payroll.PayDate,
+		GrossAmount:       corrected.GrossAmount,
+		TotalDeductions:   corrected.TotalDeductions + original.NetAmount,
+		NetAmount:         corrected.NetAmount - original.NetAmount,
+		Status:            domain.PayrollStatusCalculated,
+		Kind:              domain.PayrollKindReplacement,
+		OriginalPayrollID: &original.ID,
+		CalculatedBy:      actor,
+	}
+	return replacement, items
+}
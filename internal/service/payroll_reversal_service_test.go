@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTxManager ejecuta la función directamente, sin transacción real
+type MockTxManager struct{}
+
+func (m *MockTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestPayrollReversal_Reverse_WithReplacement(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPayrollItemRepo := new(MockPayrollItemRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	mockHistoryRepo, _ := newStateRepoMocks(ctx)
+
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockEmployeeRepo, mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+	reversalSvc := NewPayrollReversalService(&MockTxManager{}, mockPayrollRepo, mockPayrollItemRepo, mockHistoryRepo, newOpenPeriodRepo(), calculator, newAccumulatorRepoMock())
+
+	original := &domain.Payroll{
+		ID:          10,
+		TenantID:    1,
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+		GrossAmount: 2000000,
+		NetAmount:   2000000,
+		Status:      domain.PayrollStatusPaid,
+		Kind:        domain.PayrollKindRegular,
+		Items: []domain.PayrollItem{
+			{ID: 1, PayrollID: 10, Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+		},
+	}
+	concepts := []domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, EmployeePart: 2500000},
+	}
+
+	mockPayrollRepo.On("GetByID", ctx, uint(10)).Return(original, nil)
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, BaseSalary: 2500000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return(concepts, nil)
+	mockPayrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPayrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+
+	result, err := reversalSvc.Reverse(ctx, ReversePayrollRequest{
+		PayrollID:           10,
+		Reason:              "salary raise recorded late",
+		GenerateReplacement: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PayrollStatusReversed, original.Status)
+	assert.Equal(t, domain.PayrollKindReversal, result.Reversal.Kind)
+	assert.Equal(t, float64(-2000000), result.Reversal.NetAmount)
+	assert.Equal(t, uint(10), *result.Reversal.OriginalPayrollID)
+	assert.Equal(t, domain.PayrollKindReplacement, result.Replacement.Kind)
+	assert.Equal(t, float64(500000), result.NetDifference)
+
+	mockPayrollItemRepo.AssertCalled(t, "CreateBatch", ctx, mock.MatchedBy(func(items []domain.PayrollItem) bool {
+		return len(items) == 1 && items[0].Amount == -2000000
+	}))
+}
+
+func TestPayrollReversal_Reverse_NotPaid(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, _ := newStateRepoMocks(ctx)
+	reversalSvc := NewPayrollReversalService(&MockTxManager{}, mockPayrollRepo, new(MockPayrollItemRepo), mockHistoryRepo, newOpenPeriodRepo(), nil, newAccumulatorRepoMock())
+
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(&domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated}, nil)
+
+	_, err := reversalSvc.Reverse(ctx, ReversePayrollRequest{PayrollID: 1, Reason: "error"})
+
+	assert.ErrorIs(t, err, domain.ErrPayrollNotPaid)
+	mockPayrollRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPayrollCalculator_CalculateAndSave_PaidIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockEmployeeRepo := new(MockEmployeeRepo)
+	mockContractRepo := new(MockContractRepo)
+	mockConceptRepo := new(MockConceptRepo)
+	calculator := NewPayrollCalculatorService(&MockTxManager{}, mockPayrollRepo, new(MockPayrollItemRepo), mockEmployeeRepo, mockContractRepo, mockConceptRepo, newOpenPeriodRepo(), newEmptyRetroRepo(), newAccumulatorRepoMock(), newCostAllocationRepoMock(), newAbsenceRepoMock(), nil)
+
+	mockEmployeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	mockContractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 1, BaseSalary: 1000000}, nil)
+	mockConceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Type: domain.PayrollTypeEarning, EmployeePart: 1000000},
+	}, nil)
+	mockPayrollRepo.On("GetByEmployeeAndPeriod", ctx, uint(1), mock.Anything, mock.Anything).
+		Return(&domain.Payroll{ID: 3, Status: domain.PayrollStatusPaid}, nil)
+
+	_, err := calculator.CalculateAndSave(ctx, CalculatePayrollRequest{
+		EmployeeID:  1,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPayrollNotRecalculable)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
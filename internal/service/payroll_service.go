@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/arrase21/crm-users/internal/domain"
@@ -10,11 +11,15 @@ import (
 
 type PayrollService struct {
 	payrollRepo domain.PayrollRepo
+	periodRepo  domain.AccountingPeriodRepo
+	scopeSvc    *DataScopeService
 }
 
-func NewPayrollService(u domain.PayrollRepo) *PayrollService {
+func NewPayrollService(u domain.PayrollRepo, periodRepo domain.AccountingPeriodRepo, scopeSvc *DataScopeService) *PayrollService {
 	return &PayrollService{
 		payrollRepo: u,
+		periodRepo:  periodRepo,
+		scopeSvc:    scopeSvc,
 	}
 }
 
@@ -46,6 +51,13 @@ func (s *PayrollService) ListByEmployee(ctx context.Context, employeeID uint) ([
 	if employeeID == 0 {
 		return nil, errors.New("invalid employee id")
 	}
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourcePayrolls)
+	if err != nil {
+		return nil, err
+	}
+	if scope := employeeScopeFromCtx(ctx); scope != nil && !scope.Allows(employeeID) {
+		return nil, domain.ErrPermissionDenied
+	}
 	return s.payrollRepo.ListByEmployee(ctx, employeeID)
 }
 
@@ -59,17 +71,33 @@ func (s *PayrollService) Update(ctx context.Context, employee *domain.Payroll) e
 	return s.payrollRepo.Update(ctx, employee)
 }
 
-func (s *PayrollService) Delete(ctx context.Context, employeeID uint) error {
-	if employeeID == 0 {
-		return errors.New("invalid employee id")
+// Delete elimina una nómina en borrador o calculada; las aprobadas, pagadas, reversadas o
+// anuladas son registro contable y no se eliminan
+func (s *PayrollService) Delete(ctx context.Context, payrollID uint) error {
+	if payrollID == 0 {
+		return errors.New("invalid payroll id")
+	}
+	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+	if err != nil {
+		return err
 	}
-	return s.payrollRepo.Delete(ctx, employeeID)
+	if payroll.Status != domain.PayrollStatusDraft && payroll.Status != domain.PayrollStatusCalculated {
+		return fmt.Errorf("%w: payroll is %s", domain.ErrPayrollNotDeletable, payroll.Status)
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, payroll.PeriodStart, payroll.PeriodEnd); err != nil {
+		return err
+	}
+	return s.payrollRepo.Delete(ctx, payrollID)
 }
 
-// GetByPeriod obtiene todas las nóminas de un periodo para el tenant actual
+// GetByPeriod obtiene las nóminas de un periodo visibles para el actor en el tenant actual
 func (s *PayrollService) GetByPeriod(ctx context.Context, periodStart, periodEnd time.Time) ([]domain.Payroll, error) {
 	if periodStart.IsZero() || periodEnd.IsZero() {
 		return nil, errors.New("invalid period dates")
 	}
+	ctx, err := s.scopeSvc.Apply(ctx, domain.ResourcePayrolls)
+	if err != nil {
+		return nil, err
+	}
 	return s.payrollRepo.GetByPeriod(ctx, periodStart, periodEnd)
 }
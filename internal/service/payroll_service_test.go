@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPayrollService_Delete_OnlyDraftOrCalculated(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr error
+	}{
+		{status: domain.PayrollStatusDraft},
+		{status: domain.PayrollStatusCalculated},
+		{status: domain.PayrollStatusApproved, wantErr: domain.ErrPayrollNotDeletable},
+		{status: domain.PayrollStatusPartiallyPaid, wantErr: domain.ErrPayrollNotDeletable},
+		{status: domain.PayrollStatusPaid, wantErr: domain.ErrPayrollNotDeletable},
+		{status: domain.PayrollStatusReversed, wantErr: domain.ErrPayrollNotDeletable},
+		{status: domain.PayrollStatusCancelled, wantErr: domain.ErrPayrollNotDeletable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			ctx := context.Background()
+			mockPayrollRepo := new(MockPayrollRepo)
+			mockPayrollRepo.On("GetByID", ctx, uint(7)).Return(&domain.Payroll{ID: 7, Status: tt.status}, nil)
+			mockPayrollRepo.On("Delete", ctx, uint(7)).Return(nil)
+			payrollSvc := NewPayrollService(mockPayrollRepo, newOpenPeriodRepo(), nil)
+
+			err := payrollSvc.Delete(ctx, 7)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockPayrollRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockPayrollRepo.AssertCalled(t, "Delete", ctx, uint(7))
+		})
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/arrase21/crm-users/internal/domain"
@@ -16,70 +18,377 @@ var (
 
 // PayrollStateService maneja las transiciones de estado de la nómina
 type PayrollStateService struct {
-	payrollRepo  domain.PayrollRepo
-	paymentRepo  domain.PaymentRepo
-	employeeRepo domain.EmployeeRepo
+	txManager       domain.TxManager
+	payrollRepo     domain.PayrollRepo
+	paymentRepo     domain.PaymentRepo
+	employeeRepo    domain.EmployeeRepo
+	accountRepo     domain.EmployeeBankAccountRepo
+	historyRepo     domain.PayrollStatusHistoryRepo
+	transitionRepo  domain.PayrollTransitionRepo
+	outboxRepo      domain.NotificationOutboxRepo
+	accumulatorRepo domain.PayrollAccumulatorRepo
+	ledgerRepo      domain.BenefitLedgerRepo
+	periodRepo      domain.AccountingPeriodRepo
 }
 
 func NewPayrollStateService(
+	txManager domain.TxManager,
 	payrollRepo domain.PayrollRepo,
 	paymentRepo domain.PaymentRepo,
 	employeeRepo domain.EmployeeRepo,
+	accountRepo domain.EmployeeBankAccountRepo,
+	historyRepo domain.PayrollStatusHistoryRepo,
+	transitionRepo domain.PayrollTransitionRepo,
+	outboxRepo domain.NotificationOutboxRepo,
+	accumulatorRepo domain.PayrollAccumulatorRepo,
+	ledgerRepo domain.BenefitLedgerRepo,
+	periodRepo domain.AccountingPeriodRepo,
 ) *PayrollStateService {
 	return &PayrollStateService{
-		payrollRepo:  payrollRepo,
-		paymentRepo:  paymentRepo,
-		employeeRepo: employeeRepo,
+		txManager:       txManager,
+		payrollRepo:     payrollRepo,
+		paymentRepo:     paymentRepo,
+		employeeRepo:    employeeRepo,
+		accountRepo:     accountRepo,
+		historyRepo:     historyRepo,
+		transitionRepo:  transitionRepo,
+		outboxRepo:      outboxRepo,
+		accumulatorRepo: accumulatorRepo,
+		ledgerRepo:      ledgerRepo,
+		periodRepo:      periodRepo,
 	}
 }
 
-// MarkAsPaid marca una nómina como pagada y crea el registro de pago
-func (s *PayrollStateService) MarkAsPaid(ctx context.Context, payrollID uint, paymentMethod string) (*domain.Payment, error) {
+// PaymentPart es un pago a registrar sobre una nómina. Amount 0 paga el saldo pendiente
+// después de las demás partes; BankAccountID elige la cuenta de una transferencia, si no
+// se usa la principal o el reparto por porcentaje del empleado.
+type PaymentPart struct {
+	Method        string
+	Amount        float64
+	BankAccountID *uint
+}
+
+// PayrollPayments son los pagos de una nómina con el saldo pendiente
+type PayrollPayments struct {
+	PayrollID  uint
+	Status     string
+	NetAmount  float64
+	PaidAmount float64
+	Balance    float64
+	Payments   []domain.Payment
+}
+
+// MarkAsPaid paga el saldo pendiente de la nómina con un solo método
+func (s *PayrollStateService) MarkAsPaid(ctx context.Context, payrollID uint, paymentMethod string) ([]domain.Payment, error) {
+	return s.RegisterPayments(ctx, payrollID, []PaymentPart{{Method: paymentMethod}})
+}
+
+// RegisterPayments registra uno o varios pagos sobre la nómina: anticipos, el saldo o un
+// reparto entre cuentas y métodos. La suma pagada nunca supera el neto; el estado queda en
+// partially_paid o paid según lo pagado. Los pagos nacen pending hasta que el proveedor o
+// una confirmación manual los complete. Las transferencias guardan una copia de la cuenta.
+func (s *PayrollStateService) RegisterPayments(ctx context.Context, payrollID uint, parts []PaymentPart) ([]domain.Payment, error) {
 	if payrollID == 0 {
 		return nil, errors.New("payroll id is required")
 	}
+	if len(parts) == 0 {
+		return nil, domain.ErrInvalidPaymentAmount
+	}
+
+	// Bloquear la nómina, validar, guardar los pagos, el estado y el aviso al empleado en una
+	// sola transacción: dos pagos concurrentes no pueden sumar sobre el mismo saldo
+	var payments []domain.Payment
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		payroll, err := s.payrollRepo.LockByID(ctx, payrollID)
+		if err != nil {
+			return err
+		}
+
+		// Verificar que no esté ya pagada (primero, mensaje más específico)
+		if payroll.Status == domain.PayrollStatusPaid {
+			return domain.ErrPayrollAlreadyPaid
+		}
+
+		// Validar que el estado admita pagos antes de consultar los existentes
+		payable := payroll.Status == domain.PayrollStatusPartiallyPaid
+		for _, to := range []string{domain.PayrollStatusPaid, domain.PayrollStatusPartiallyPaid} {
+			if payable {
+				break
+			}
+			if payable, err = s.canTransitionTo(ctx, payroll.Status, to); err != nil {
+				return err
+			}
+		}
+		if !payable {
+			return ErrInvalidStatusTransition
+		}
+
+		// Un reemplazo con pagos previos mayores al devengado no deja nada por pagar
+		if roundCents(payroll.NetAmount) <= 0 {
+			return fmt.Errorf("%w: payroll net amount is %.2f", domain.ErrInvalidPaymentAmount, payroll.NetAmount)
+		}
+
+		existing, err := s.paymentRepo.ListByPayroll(ctx, payroll.ID)
+		if err != nil {
+			return err
+		}
+		paid := sumPayments(existing)
+		amounts, err := resolvePaymentAmounts(parts, roundCents(payroll.NetAmount-paid))
+		if err != nil {
+			return err
+		}
+
+		// El estado resulta de lo pagado: partially_paid mientras quede saldo
+		target := domain.PayrollStatusPartiallyPaid
+		if roundCents(paid+sumAmounts(amounts)) >= roundCents(payroll.NetAmount) {
+			target = domain.PayrollStatusPaid
+		}
+		if payroll.Status != target {
+			allowed, err := s.canTransitionTo(ctx, payroll.Status, target)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return ErrInvalidStatusTransition
+			}
+		}
+
+		// Repartir cada parte entre las cuentas del empleado
+		methods := make([]string, 0, len(parts))
+		for i, part := range parts {
+			routed, err := s.routePayment(ctx, payroll.EmployeeID, part, amounts[i])
+			if err != nil {
+				return err
+			}
+			payments = append(payments, routed...)
+			methods = append(methods, part.Method)
+		}
 
-	// Obtener la nómina
+		now := time.Now()
+		for i := range payments {
+			payments[i].PayrollID = payroll.ID
+			payments[i].PaidAt = now
+			payments[i].Status = domain.PaymentStatusPending
+			payments[i].CreatedAt = now
+			if err := s.paymentRepo.Create(ctx, &payments[i]); err != nil {
+				return err
+			}
+		}
+		if payroll.Status == target {
+			return nil
+		}
+		reason := "payment registered: " + strings.Join(methods, ", ")
+		if err := s.applyTransition(ctx, payroll, target, reason); err != nil {
+			return err
+		}
+		if target == domain.PayrollStatusPaid {
+			return s.enqueuePayslipEmail(ctx, payroll)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// ListPayments retorna los pagos de la nómina con lo pagado y el saldo pendiente
+func (s *PayrollStateService) ListPayments(ctx context.Context, payrollID uint) (*PayrollPayments, error) {
+	if payrollID == 0 {
+		return nil, errors.New("payroll id is required")
+	}
 	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
 	if err != nil {
 		return nil, err
 	}
+	payments, err := s.paymentRepo.ListByPayroll(ctx, payrollID)
+	if err != nil {
+		return nil, err
+	}
+	paid := sumPayments(payments)
+	return &PayrollPayments{
+		PayrollID:  payroll.ID,
+		Status:     payroll.Status,
+		NetAmount:  payroll.NetAmount,
+		PaidAmount: paid,
+		Balance:    roundCents(payroll.NetAmount - paid),
+		Payments:   payments,
+	}, nil
+}
+
+// ListEmployeePayments retorna los pagos de todas las nóminas del empleado
+func (s *PayrollStateService) ListEmployeePayments(ctx context.Context, employeeID uint) ([]domain.Payment, error) {
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	return s.paymentRepo.ListByEmployee(ctx, employeeID)
+}
+
+// routePayment arma los pagos de una parte: una transferencia va a la cuenta indicada o se
+// reparte entre las cuentas del empleado; los demás métodos no llevan datos bancarios
+func (s *PayrollStateService) routePayment(ctx context.Context, employeeID uint, part PaymentPart, amount float64) ([]domain.Payment, error) {
+	payments := []domain.Payment{{Amount: amount}}
+	if part.Method == domain.PaymentMethodBankTransfer {
+		if part.BankAccountID != nil {
+			account, err := s.accountRepo.GetByID(ctx, *part.BankAccountID)
+			if err != nil {
+				return nil, err
+			}
+			if account.EmployeeID != employeeID || !account.IsActive {
+				return nil, domain.ErrBankAccountNotFound
+			}
+			payments = []domain.Payment{accountPayment(account, amount)}
+		} else {
+			accounts, err := s.accountRepo.ListByEmployee(ctx, employeeID)
+			if err != nil {
+				return nil, err
+			}
+			payments, err = splitPayment(accounts, amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range payments {
+		payments[i].Method = part.Method
+	}
+	return payments, nil
+}
 
-	// Verificar que no esté ya pagada (primero, mensaje más específico)
-	if payroll.Status == domain.PayrollStatusPaid {
-		return nil, errors.New("payroll is already paid")
+// resolvePaymentAmounts calcula el monto de cada parte: a lo sumo una parte sin monto toma
+// el saldo restante. Solo una nómina con neto 0 admite un pago en 0.
+func resolvePaymentAmounts(parts []PaymentPart, balance float64) ([]float64, error) {
+	amounts := make([]float64, len(parts))
+	remainderIdx := -1
+	var fixed float64
+	for i, part := range parts {
+		if strings.TrimSpace(part.Method) == "" {
+			return nil, errors.New("payment method is required")
+		}
+		switch {
+		case part.Amount < 0:
+			return nil, domain.ErrInvalidPaymentAmount
+		case part.Amount == 0:
+			if remainderIdx >= 0 {
+				return nil, domain.ErrInvalidPaymentAmount
+			}
+			remainderIdx = i
+		default:
+			amounts[i] = roundCents(part.Amount)
+			fixed += amounts[i]
+		}
+	}
+	if roundCents(fixed) > balance {
+		return nil, domain.ErrPaymentExceedsNetPay
 	}
+	if remainderIdx >= 0 {
+		amounts[remainderIdx] = roundCents(balance - fixed)
+		if amounts[remainderIdx] <= 0 && (balance > 0 || len(parts) > 1) {
+			return nil, domain.ErrInvalidPaymentAmount
+		}
+	}
+	return amounts, nil
+}
 
-	// Validar que esté en estado válido para transicionar
-	if !s.canTransitionTo(payroll.Status, domain.PayrollStatusPaid) {
-		return nil, ErrInvalidStatusTransition
+// enqueuePayslipEmail registra en el outbox el correo con el desprendible de la nómina
+// pagada. Si la nómina ya tiene un envío pendiente o hecho (se volvió a pagar tras un
+// pago fallido) no se repite; sin correo del empleado queda como skipped.
+func (s *PayrollStateService) enqueuePayslipEmail(ctx context.Context, payroll *domain.Payroll) error {
+	existing, err := s.outboxRepo.ListByPayroll(ctx, payroll.ID)
+	if err != nil {
+		return err
+	}
+	for _, n := range existing {
+		if n.Kind == domain.NotificationKindPayslipEmail && n.Status != domain.NotificationFailed {
+			return nil
+		}
 	}
 
-	// Crear el registro de pago
-	payment := &domain.Payment{
-		PayrollID: payroll.ID,
-		Method:    paymentMethod,
-		BankName:  "", // Se puede obtener del contrato si existe
-		Amount:    payroll.NetAmount,
-		PaidAt:    time.Now(),
-		Status:    "completed",
-		CreatedAt: time.Now(),
+	notification := &domain.NotificationOutbox{
+		PayrollID:     payroll.ID,
+		EmployeeID:    payroll.EmployeeID,
+		Kind:          domain.NotificationKindPayslipEmail,
+		Recipient:     strings.TrimSpace(payroll.Employee.User.Email),
+		Status:        domain.NotificationPending,
+		NextAttemptAt: time.Now(),
+	}
+	if notification.Recipient == "" {
+		notification.Status = domain.NotificationSkipped
+		notification.LastError = "employee has no email"
 	}
+	return s.outboxRepo.Create(ctx, notification)
+}
 
-	// Guardar el pago
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
-		return nil, err
+// revertFailedPayment recalcula el estado de la nómina sin los pagos fallidos: vuelve a
+// partially_paid si queda algo pagado, o al estado previo al primer pago si no queda nada
+func (s *PayrollStateService) revertFailedPayment(ctx context.Context, payrollID uint, reason string) error {
+	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+	if err != nil {
+		return err
+	}
+	if payroll.Status != domain.PayrollStatusPaid && payroll.Status != domain.PayrollStatusPartiallyPaid {
+		return nil
+	}
+	payments, err := s.paymentRepo.ListByPayroll(ctx, payrollID)
+	if err != nil {
+		return err
 	}
+	paid := sumPayments(payments)
 
-	// Actualizar estado de la nómina
-	payroll.Status = domain.PayrollStatusPaid
-	if err := s.payrollRepo.Update(ctx, payroll); err != nil {
-		// Intentar rollback del pago
-		s.paymentRepo.Delete(ctx, payment.ID)
-		return nil, err
+	target := domain.PayrollStatusPartiallyPaid
+	switch {
+	case paid >= roundCents(payroll.NetAmount):
+		return nil
+	case paid == 0:
+		if target, err = s.statusBeforePayment(ctx, payrollID); err != nil {
+			return err
+		}
+	}
+	if payroll.Status == target {
+		return nil
+	}
+	// La reversión la decide el banco, no el usuario: no pasa por la tabla de transiciones
+	return s.applyTransition(ctx, payroll, target, "payment failed: "+reason)
+}
+
+// statusBeforePayment busca en el historial el estado que tenía la nómina antes de su primer pago
+func (s *PayrollStateService) statusBeforePayment(ctx context.Context, payrollID uint) (string, error) {
+	history, err := s.historyRepo.ListByPayroll(ctx, payrollID)
+	if err != nil {
+		return "", err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if isPaidStatus(h.ToStatus) && !isPaidStatus(h.FromStatus) && h.FromStatus != "" {
+			return h.FromStatus, nil
+		}
 	}
+	return domain.PayrollStatusApproved, nil
+}
 
-	return payment, nil
+func isPaidStatus(status string) bool {
+	return status == domain.PayrollStatusPaid || status == domain.PayrollStatusPartiallyPaid
+}
+
+// sumPayments suma lo pagado; los pagos fallidos no cuentan
+func sumPayments(payments []domain.Payment) float64 {
+	var total float64
+	for _, p := range payments {
+		if p.Status == domain.PaymentStatusFailed {
+			continue
+		}
+		total += p.Amount
+	}
+	return roundCents(total)
+}
+
+func sumAmounts(amounts []float64) float64 {
+	var total float64
+	for _, a := range amounts {
+		total += a
+	}
+	return roundCents(total)
 }
 
 // MarkAsCalculated marca una nómina draft como calculada
@@ -88,41 +397,140 @@ func (s *PayrollStateService) MarkAsCalculated(ctx context.Context, payrollID ui
 		return errors.New("payroll id is required")
 	}
 
-	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
-	if err != nil {
-		return err
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+		if err != nil {
+			return err
+		}
+
+		allowed, err := s.canTransitionTo(ctx, payroll.Status, domain.PayrollStatusCalculated)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrInvalidStatusTransition
+		}
+
+		// Quien liquidó la nómina queda registrado en el cálculo; marcarla no lo reemplaza
+		if payroll.CalculatedBy == 0 {
+			payroll.CalculatedBy = actorFromCtx(ctx)
+		}
+		return s.applyTransition(ctx, payroll, domain.PayrollStatusCalculated, "")
+	})
+}
+
+// Approve aprueba una nómina calculada. El aprobador debe ser distinto de quien la calculó, y
+// ambos deben ser conocidos.
+func (s *PayrollStateService) Approve(ctx context.Context, payrollID uint, reason string) error {
+	if payrollID == 0 {
+		return errors.New("payroll id is required")
+	}
+	actor := actorFromCtx(ctx)
+	if actor == 0 {
+		return domain.ErrActorRequired
 	}
 
-	if !s.canTransitionTo(payroll.Status, domain.PayrollStatusCalculated) {
-		return ErrInvalidStatusTransition
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+		if err != nil {
+			return err
+		}
+
+		allowed, err := s.canTransitionTo(ctx, payroll.Status, domain.PayrollStatusApproved)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrInvalidStatusTransition
+		}
+		// Sin liquidador registrado no se puede garantizar la doble revisión
+		if payroll.CalculatedBy == 0 {
+			return domain.ErrCalculatorUnknown
+		}
+		if payroll.CalculatedBy == actor {
+			return domain.ErrSameUserApproval
+		}
+
+		now := time.Now()
+		payroll.ApprovedBy = actor
+		payroll.ApprovedAt = &now
+		return s.applyTransition(ctx, payroll, domain.PayrollStatusApproved, reason)
+	})
+}
+
+// Cancel anula una nómina que aún no ha sido pagada y cuyo periodo contable sigue abierto
+func (s *PayrollStateService) Cancel(ctx context.Context, payrollID uint, reason string) error {
+	if payrollID == 0 {
+		return errors.New("payroll id is required")
+	}
+	if reason == "" {
+		return errors.New("cancellation reason is required")
 	}
 
-	payroll.Status = domain.PayrollStatusCalculated
-	return s.payrollRepo.Update(ctx, payroll)
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+		if err != nil {
+			return err
+		}
+
+		allowed, err := s.canTransitionTo(ctx, payroll.Status, domain.PayrollStatusCancelled)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrInvalidStatusTransition
+		}
+		if err := ensurePeriodOpen(ctx, s.periodRepo, payroll.PeriodStart, payroll.PeriodEnd); err != nil {
+			return err
+		}
+
+		return s.applyTransition(ctx, payroll, domain.PayrollStatusCancelled, reason)
+	})
 }
 
-// RevertToDraft revierte una nómina calculada (no pagada) a draft
+// RevertToDraft revierte una nómina calculada (no pagada) de un periodo abierto a draft
 func (s *PayrollStateService) RevertToDraft(ctx context.Context, payrollID uint) error {
 	if payrollID == 0 {
 		return errors.New("payroll id is required")
 	}
 
-	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+		if err != nil {
+			return err
+		}
+
+		// Solo se puede revertir si está calculada (no pagada)
+		if payroll.Status == domain.PayrollStatusPaid || payroll.Status == domain.PayrollStatusPartiallyPaid {
+			return errors.New("cannot revert a paid payroll")
+		}
+
+		allowed, err := s.canTransitionTo(ctx, payroll.Status, domain.PayrollStatusDraft)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrInvalidStatusTransition
+		}
+		if err := ensurePeriodOpen(ctx, s.periodRepo, payroll.PeriodStart, payroll.PeriodEnd); err != nil {
+			return err
+		}
+
+		return s.applyTransition(ctx, payroll, domain.PayrollStatusDraft, "")
+	})
+}
+
+// resetForRecalculation regresa a draft una nómina calculada o aprobada antes de recalcularla,
+// si la tabla de transiciones del tenant lo permite
+func (s *PayrollStateService) resetForRecalculation(ctx context.Context, payroll *domain.Payroll) error {
+	allowed, err := s.canTransitionTo(ctx, payroll.Status, domain.PayrollStatusDraft)
 	if err != nil {
 		return err
 	}
-
-	// Solo se puede revertir si está calculada (no pagada)
-	if payroll.Status == domain.PayrollStatusPaid {
-		return errors.New("cannot revert a paid payroll")
-	}
-
-	if !s.canTransitionTo(payroll.Status, domain.PayrollStatusDraft) {
-		return ErrInvalidStatusTransition
+	if !allowed {
+		return domain.ErrPayrollNotRecalculable
 	}
-
-	payroll.Status = domain.PayrollStatusDraft
-	return s.payrollRepo.Update(ctx, payroll)
+	return s.applyTransition(ctx, payroll, domain.PayrollStatusDraft, "recalculated")
 }
 
 // ValidatePayrollCreation valida que no exista una nómina para el mismo periodo
@@ -135,39 +543,189 @@ func (s *PayrollStateService) ValidatePayrollCreation(ctx context.Context, emplo
 		return err
 	}
 
-	// Si existe y está pagada, no se puede recalcular
-	if existing.Status == domain.PayrollStatusPaid {
+	// Si existe y tiene pagos, no se puede recalcular
+	if existing.Status == domain.PayrollStatusPaid || existing.Status == domain.PayrollStatusPartiallyPaid {
 		return errors.New("payroll already paid for this period, cannot recalculate")
 	}
+	if existing.Status == domain.PayrollStatusReversed || existing.Kind == domain.PayrollKindReplacement {
+		return errors.New("payroll for this period was reversed, use its replacement payroll")
+	}
 
 	// Si existe y está calculada, se permite reescribir
 	return nil
 }
 
-// canTransitionTo valida si una transición de estado es válida
-// Estados posibles: draft -> calculated -> paid
-// Excepciones: calculated -> draft (revertir)
-func (s *PayrollStateService) canTransitionTo(from, to string) bool {
-	validTransitions := map[string][]string{
-		domain.PayrollStatusDraft:      {domain.PayrollStatusCalculated, domain.PayrollStatusDraft},
-		domain.PayrollStatusCalculated: {domain.PayrollStatusPaid, domain.PayrollStatusDraft},
-		domain.PayrollStatusPaid:       {}, // Estado terminal, no hay transiciones válidas
+// canTransitionTo valida si una transición de estado es válida según la tabla del tenant
+func (s *PayrollStateService) canTransitionTo(ctx context.Context, from, to string) (bool, error) {
+	// tablas guardadas antes de estas reglas no pueden reabrir estados terminales ni saltar la aprobación
+	if isTerminalPayrollStatus(from) ||
+		(isPaymentStatus(to) && !isPaymentStatus(from) && from != domain.PayrollStatusApproved) {
+		return false, nil
+	}
+	validTransitions, err := s.transitionTable(ctx)
+	if err != nil {
+		return false, err
 	}
 
 	allowed, exists := validTransitions[from]
 	if !exists {
-		return false
+		return false, nil
 	}
 
 	for _, state := range allowed {
 		if state == to {
-			return true
+			return true, nil
 		}
 	}
+	return false, nil
+}
+
+// transitionTable retorna las transiciones configuradas para el tenant,
+// o las transiciones por defecto si el tenant no ha configurado ninguna
+func (s *PayrollStateService) transitionTable(ctx context.Context) (map[string][]string, error) {
+	configured, err := s.transitionRepo.ListByTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(configured) == 0 {
+		return domain.DefaultPayrollTransitions(), nil
+	}
+
+	table := make(map[string][]string)
+	for _, t := range configured {
+		table[t.FromStatus] = append(table[t.FromStatus], t.ToStatus)
+	}
+	return table, nil
+}
+
+// releaseBenefitLedger compensa los movimientos de prestaciones registrados por la nómina
+func (s *PayrollStateService) releaseBenefitLedger(ctx context.Context, payroll *domain.Payroll) error {
+	entries, err := s.ledgerRepo.ListByPayroll(ctx, payroll.ID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	releases := make([]domain.BenefitLedgerEntry, len(entries))
+	for i, e := range entries {
+		releases[i] = domain.BenefitLedgerEntry{
+			EmployeeID:  e.EmployeeID,
+			PayrollID:   e.PayrollID,
+			Benefit:     e.Benefit,
+			EntryType:   e.EntryType,
+			Amount:      -e.Amount,
+			PeriodStart: e.PeriodStart,
+			PeriodEnd:   e.PeriodEnd,
+		}
+	}
+	return s.ledgerRepo.CreateBatch(ctx, releases)
+}
+
+// applyTransition cambia el estado de la nómina y registra la transición en el historial. Los
+// llamadores la ejecutan dentro de una transacción junto con la lectura de la nómina.
+func (s *PayrollStateService) applyTransition(ctx context.Context, payroll *domain.Payroll, to, reason string) error {
+	from := payroll.Status
+	payroll.Status = to
+	if err := s.payrollRepo.Update(ctx, payroll); err != nil {
+		payroll.Status = from
+		return err
+	}
+	// Los acumulados del año reflejan solo lo pagado: suman al pagar y restan si el pago se
+	// revierte. El reverso de una nómina pagada se descuenta con su nómina de reverso.
+	switch {
+	case to == domain.PayrollStatusPaid && from != domain.PayrollStatusPaid:
+		if err := accumulatePayroll(ctx, s.accumulatorRepo, payroll, 1); err != nil {
+			return err
+		}
+	case from == domain.PayrollStatusPaid && to != domain.PayrollStatusPaid && to != domain.PayrollStatusReversed:
+		if err := accumulatePayroll(ctx, s.accumulatorRepo, payroll, -1); err != nil {
+			return err
+		}
+	}
+	// Las nóminas de prestaciones y de liquidación descuentan el ledger al crearse; si se
+	// cancelan antes del pago el saldo se devuelve con movimientos opuestos
+	if to == domain.PayrollStatusCancelled {
+		if err := s.releaseBenefitLedger(ctx, payroll); err != nil {
+			return err
+		}
+	}
+
+	return s.historyRepo.Create(ctx, &domain.PayrollStatusHistory{
+		PayrollID:  payroll.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorFromCtx(ctx),
+		Reason:     reason,
+	})
+}
+
+// GetHistory obtiene el historial de transiciones de una nómina
+func (s *PayrollStateService) GetHistory(ctx context.Context, payrollID uint) ([]domain.PayrollStatusHistory, error) {
+	if payrollID == 0 {
+		return nil, errors.New("payroll id is required")
+	}
+	if _, err := s.payrollRepo.GetByID(ctx, payrollID); err != nil {
+		return nil, err
+	}
+	return s.historyRepo.ListByPayroll(ctx, payrollID)
+}
+
+// GetTransitions retorna la tabla de transiciones efectiva del tenant
+func (s *PayrollStateService) GetTransitions(ctx context.Context) (map[string][]string, error) {
+	return s.transitionTable(ctx)
+}
+
+// SetTransitions reemplaza la tabla de transiciones del tenant.
+// Una tabla vacía restablece las transiciones por defecto.
+func (s *PayrollStateService) SetTransitions(ctx context.Context, table map[string][]string) error {
+	var transitions []domain.PayrollStatusTransition
+	for from, targets := range table {
+		if isTerminalPayrollStatus(from) && len(targets) > 0 {
+			return fmt.Errorf("%s is a terminal status and cannot have transitions", from)
+		}
+		if !isPayrollStatus(from) {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidPayrollStatus, from)
+		}
+		for _, to := range targets {
+			if !isPayrollStatus(to) {
+				return fmt.Errorf("%w: %s", domain.ErrInvalidPayrollStatus, to)
+			}
+			// el pago exige aprobación previa: ninguna tabla puede saltarse approved
+			if isPaymentStatus(to) && !isPaymentStatus(from) && from != domain.PayrollStatusApproved {
+				return fmt.Errorf("%w: %s -> %s skips approval", ErrInvalidStatusTransition, from, to)
+			}
+			transitions = append(transitions, domain.PayrollStatusTransition{
+				FromStatus: from,
+				ToStatus:   to,
+			})
+		}
+	}
+	return s.transitionRepo.ReplaceAll(ctx, transitions)
+}
+
+// isTerminalPayrollStatus indica si el estado no admite transiciones de salida
+func isTerminalPayrollStatus(status string) bool {
+	switch status {
+	case domain.PayrollStatusPaid, domain.PayrollStatusCancelled, domain.PayrollStatusReversed:
+		return true
+	}
 	return false
 }
 
-// GetPaymentHistory obtiene el historial de pagos de una nómina
-func (s *PayrollStateService) GetPaymentHistory(ctx context.Context, payrollID uint) (*domain.Payment, error) {
-	return s.paymentRepo.GetByPayrollID(ctx, payrollID)
+func isPaymentStatus(status string) bool {
+	return status == domain.PayrollStatusPartiallyPaid || status == domain.PayrollStatusPaid
+}
+
+func isPayrollStatus(status string) bool {
+	switch status {
+	case domain.PayrollStatusDraft,
+		domain.PayrollStatusCalculated,
+		domain.PayrollStatusApproved,
+		domain.PayrollStatusPartiallyPaid,
+		domain.PayrollStatusPaid,
+		domain.PayrollStatusCancelled:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ========================================
+// Mocks de historial y transiciones
+// ========================================
+
+type MockPayrollStatusHistoryRepo struct {
+	mock.Mock
+}
+
+func (m *MockPayrollStatusHistoryRepo) Create(ctx context.Context, entry *domain.PayrollStatusHistory) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockPayrollStatusHistoryRepo) ListByPayroll(ctx context.Context, payrollID uint) ([]domain.PayrollStatusHistory, error) {
+	args := m.Called(ctx, payrollID)
+	return args.Get(0).([]domain.PayrollStatusHistory), args.Error(1)
+}
+
+type MockPayrollTransitionRepo struct {
+	mock.Mock
+}
+
+func (m *MockPayrollTransitionRepo) ListByTenant(ctx context.Context) ([]domain.PayrollStatusTransition, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.PayrollStatusTransition), args.Error(1)
+}
+
+func (m *MockPayrollTransitionRepo) ReplaceAll(ctx context.Context, transitions []domain.PayrollStatusTransition) error {
+	args := m.Called(ctx, transitions)
+	return args.Error(0)
+}
+
+// newStateRepoMocks crea mocks que usan la tabla de transiciones por defecto
+// y aceptan cualquier registro de historial
+func newStateRepoMocks(ctx context.Context) (*MockPayrollStatusHistoryRepo, *MockPayrollTransitionRepo) {
+	historyRepo := new(MockPayrollStatusHistoryRepo)
+	historyRepo.On("Create", ctx, mock.AnythingOfType("*domain.PayrollStatusHistory")).Return(nil)
+	transitionRepo := new(MockPayrollTransitionRepo)
+	transitionRepo.On("ListByTenant", ctx).Return([]domain.PayrollStatusTransition{}, nil)
+	return historyRepo, transitionRepo
+}
+
+func withActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, domain.UserIDKey, userID)
+}
+
+func TestPayrollStateService_Approve_Success(t *testing.T) {
+	ctx := withActor(context.Background(), 2)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated, CalculatedBy: 1}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+
+	err := stateSvc.Approve(ctx, 1, "ok")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PayrollStatusApproved, payroll.Status)
+	assert.Equal(t, uint(2), payroll.ApprovedBy)
+	assert.NotNil(t, payroll.ApprovedAt)
+	mockHistoryRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(h *domain.PayrollStatusHistory) bool {
+		return h.FromStatus == domain.PayrollStatusCalculated &&
+			h.ToStatus == domain.PayrollStatusApproved &&
+			h.ActorID == 2 &&
+			h.Reason == "ok"
+	}))
+}
+
+// txCtxManager marca el contexto de la transacción para verificar qué escrituras corren dentro
+type txCtxManager struct{}
+
+type txMarkerKey struct{}
+
+func (m *txCtxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, txMarkerKey{}, true))
+}
+
+func inTx() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(txMarkerKey{}) != nil
+	})
+}
+
+func TestPayrollStateService_Approve_RunsInTransaction(t *testing.T) {
+	ctx := withActor(context.Background(), 2)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo := new(MockPayrollStatusHistoryRepo)
+	mockHistoryRepo.On("Create", inTx(), mock.AnythingOfType("*domain.PayrollStatusHistory")).Return(nil)
+	mockTransitionRepo := new(MockPayrollTransitionRepo)
+	mockTransitionRepo.On("ListByTenant", mock.Anything).Return([]domain.PayrollStatusTransition{}, nil)
+	stateSvc := NewPayrollStateService(&txCtxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated, CalculatedBy: 1}
+	mockPayrollRepo.On("GetByID", inTx(), uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", inTx(), payroll).Return(nil)
+
+	err := stateSvc.Approve(ctx, 1, "ok")
+
+	assert.NoError(t, err)
+	mockPayrollRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+}
+
+func TestPayrollStateService_Approve_SameUserAsCalculator(t *testing.T) {
+	ctx := withActor(context.Background(), 1)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated, CalculatedBy: 1}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+
+	err := stateSvc.Approve(ctx, 1, "")
+
+	assert.ErrorIs(t, err, domain.ErrSameUserApproval)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPayrollStateService_Approve_UnknownCalculator(t *testing.T) {
+	ctx := withActor(context.Background(), 2)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+
+	err := stateSvc.Approve(ctx, 1, "")
+
+	assert.ErrorIs(t, err, domain.ErrCalculatorUnknown)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPayrollStateService_MarkAsCalculated_KeepsCalculator(t *testing.T) {
+	ctx := withActor(context.Background(), 2)
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	// Liquidada por el usuario 1: que otro la marque no lo habilita para aprobarla
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusDraft, CalculatedBy: 1}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+
+	err := stateSvc.MarkAsCalculated(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), payroll.CalculatedBy)
+}
+
+func TestPayrollStateService_Approve_RequiresActor(t *testing.T) {
+	ctx := context.Background()
+
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	err := stateSvc.Approve(ctx, 1, "")
+
+	assert.ErrorIs(t, err, domain.ErrActorRequired)
+}
+
+func TestPayrollStateService_Cancel_PaidIsTerminal(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusPaid}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+
+	err := stateSvc.Cancel(ctx, 1, "duplicated")
+
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+}
+
+func TestPayrollStateService_TenantTransitionsRequireApproval(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo := new(MockPayrollStatusHistoryRepo)
+	mockTransitionRepo := new(MockPayrollTransitionRepo)
+	mockTransitionRepo.On("ListByTenant", ctx).Return([]domain.PayrollStatusTransition{
+		{FromStatus: domain.PayrollStatusDraft, ToStatus: domain.PayrollStatusCalculated},
+		{FromStatus: domain.PayrollStatusCalculated, ToStatus: domain.PayrollStatusApproved},
+		{FromStatus: domain.PayrollStatusApproved, ToStatus: domain.PayrollStatusPaid},
+	}, nil)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated}
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+
+	_, err := stateSvc.MarkAsPaid(ctx, 1, "bank_transfer")
+
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+}
+
+func TestPayrollStateService_SetTransitions_RejectsUnknownStatus(t *testing.T) {
+	ctx := context.Background()
+
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	err := stateSvc.SetTransitions(ctx, map[string][]string{"draft": {"archived"}})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPayrollStatus)
+	mockTransitionRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything, mock.Anything)
+}
+
+func TestPayrollStateService_SetTransitions_RejectsTerminalStatus(t *testing.T) {
+	ctx := context.Background()
+
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	for _, from := range []string{domain.PayrollStatusPaid, domain.PayrollStatusCancelled, domain.PayrollStatusReversed} {
+		err := stateSvc.SetTransitions(ctx, map[string][]string{from: {domain.PayrollStatusDraft}})
+		assert.Error(t, err, from)
+	}
+	mockTransitionRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything, mock.Anything)
+}
+
+func TestPayrollStateService_SetTransitions_RejectsPaymentWithoutApproval(t *testing.T) {
+	ctx := context.Background()
+
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	err := stateSvc.SetTransitions(ctx, map[string][]string{
+		domain.PayrollStatusCalculated: {domain.PayrollStatusApproved, domain.PayrollStatusPaid},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	mockTransitionRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything, mock.Anything)
+}
+
+func TestPayrollStateService_DefaultTransitionsRequireApproval(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated, NetAmount: 1000000}
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+
+	_, err := stateSvc.MarkAsPaid(ctx, 1, "bank_transfer")
+
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+}
+
+func TestPayrollStateService_RegisterPayments_PartialThenRemainder(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	payroll := &domain.Payroll{ID: 1, EmployeeID: 1, Status: domain.PayrollStatusApproved, NetAmount: 1500000}
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+	mockPayrollRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{}, nil).Once()
+
+	// Anticipo en efectivo
+	payments, err := stateSvc.RegisterPayments(ctx, 1, []PaymentPart{{Method: "cash", Amount: 500000}})
+	assert.NoError(t, err)
+	assert.Len(t, payments, 1)
+	assert.Equal(t, domain.PayrollStatusPartiallyPaid, payroll.Status)
+
+	// Pagar más del saldo no se permite
+	mockPaymentRepo.On("ListByPayroll", ctx, uint(1)).Return([]domain.Payment{{ID: 1, Amount: 500000}}, nil)
+	_, err = stateSvc.RegisterPayments(ctx, 1, []PaymentPart{{Method: "cash", Amount: 1000000.01}})
+	assert.ErrorIs(t, err, domain.ErrPaymentExceedsNetPay)
+
+	// El saldo se reparte entre dos métodos: cheque fijo y el resto en efectivo
+	payments, err = stateSvc.RegisterPayments(ctx, 1, []PaymentPart{
+		{Method: "check", Amount: 400000},
+		{Method: "cash"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, payments, 2)
+	assert.Equal(t, 600000.0, payments[1].Amount)
+	assert.Equal(t, domain.PayrollStatusPaid, payroll.Status)
+}
+
+func TestPayrollStateService_RegisterPayments_RejectsNonPositiveNet(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockPaymentRepo := new(MockPaymentRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, mockPaymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+
+	// Reemplazo cuyo pago previo supera el devengado
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusApproved, Kind: domain.PayrollKindReplacement, NetAmount: -150000}
+	mockPayrollRepo.On("LockByID", ctx, uint(1)).Return(payroll, nil)
+
+	_, err := stateSvc.MarkAsPaid(ctx, 1, "cash")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentAmount)
+	assert.Equal(t, domain.PayrollStatusApproved, payroll.Status)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestResolvePaymentAmounts(t *testing.T) {
+	amounts, err := resolvePaymentAmounts([]PaymentPart{{Method: "cash"}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0}, amounts)
+
+	_, err = resolvePaymentAmounts([]PaymentPart{{Method: "cash"}, {Method: "check"}}, 100)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentAmount)
+
+	_, err = resolvePaymentAmounts([]PaymentPart{{Method: "cash", Amount: 100}, {Method: "check"}}, 100)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentAmount)
+
+	_, err = resolvePaymentAmounts([]PaymentPart{{Method: "cash", Amount: -5}}, 100)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentAmount)
+}
+
+func TestPayrollStateService_Cancel_ClosedPeriod(t *testing.T) {
+	ctx := context.Background()
+
+	mockPayrollRepo := new(MockPayrollRepo)
+	mockHistoryRepo, mockTransitionRepo := newStateRepoMocks(ctx)
+	periodRepo := new(MockAccountingPeriodRepo)
+	periodRepo.On("FindOverlapping", ctx, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{
+		{ID: 1, Status: domain.PeriodStatusClosed},
+	}, nil)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, mockPayrollRepo, new(MockPaymentRepo), new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), mockHistoryRepo, mockTransitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), periodRepo)
+
+	payroll := &domain.Payroll{ID: 1, Status: domain.PayrollStatusCalculated,
+		PeriodStart: pilaDate(3, 1), PeriodEnd: pilaDate(3, 31)}
+	mockPayrollRepo.On("GetByID", ctx, uint(1)).Return(payroll, nil)
+
+	err := stateSvc.Cancel(ctx, 1, "duplicated")
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+
+	err = stateSvc.RevertToDraft(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+
+	assert.Equal(t, domain.PayrollStatusCalculated, payroll.Status)
+	mockPayrollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
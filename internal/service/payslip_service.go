@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/pdf"
+)
+
+// maxPayslipLogoSize limita el tamaño del logo de la plantilla
+const maxPayslipLogoSize = 512 << 10
+
+// PayslipService genera el desprendible de nómina en PDF
+type PayslipService struct {
+	payrollRepo    domain.PayrollRepo
+	contractRepo   domain.EmployeeContractRepo
+	departmentRepo domain.DepartmentRepo
+	positionRepo   domain.PositionRepo
+	templateRepo   domain.PayslipTemplateRepo
+}
+
+func NewPayslipService(
+	payrollRepo domain.PayrollRepo,
+	contractRepo domain.EmployeeContractRepo,
+	departmentRepo domain.DepartmentRepo,
+	positionRepo domain.PositionRepo,
+	templateRepo domain.PayslipTemplateRepo,
+) *PayslipService {
+	return &PayslipService{
+		payrollRepo:    payrollRepo,
+		contractRepo:   contractRepo,
+		departmentRepo: departmentRepo,
+		positionRepo:   positionRepo,
+		templateRepo:   templateRepo,
+	}
+}
+
+// Payslip es el PDF generado con su nombre de archivo
+type Payslip struct {
+	FileName string
+	Content  []byte
+}
+
+// payslipData reúne lo que se imprime en el desprendible
+type payslipData struct {
+	payroll       *domain.Payroll
+	template      *domain.PayslipTemplate
+	department    string
+	position      string
+	baseSalary    float64
+	earnings      []domain.PayrollItem
+	deductions    []domain.PayrollItem
+	contributions []domain.PayrollItem
+}
+
+// GetTemplate retorna la plantilla del tenant o la plantilla por defecto si no tiene
+func (s *PayslipService) GetTemplate(ctx context.Context) (*domain.PayslipTemplate, error) {
+	template, err := s.templateRepo.Get(ctx)
+	if errors.Is(err, domain.ErrPayslipTemplateNotFound) {
+		return defaultPayslipTemplate(), nil
+	}
+	return template, err
+}
+
+// SaveTemplate guarda los textos y el color de la plantilla; el logo se conserva
+func (s *PayslipService) SaveTemplate(ctx context.Context, template *domain.PayslipTemplate) (*domain.PayslipTemplate, error) {
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	current, err := s.GetTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = current.ID
+	template.Logo = current.Logo
+	template.LogoContentType = current.LogoContentType
+	template.CreatedAt = current.CreatedAt
+	if err := s.templateRepo.Save(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// SetLogo reemplaza el logo de la plantilla; nil lo quita
+func (s *PayslipService) SetLogo(ctx context.Context, logo []byte) (*domain.PayslipTemplate, error) {
+	template, err := s.GetTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	template.Logo = nil
+	template.LogoContentType = ""
+	if len(logo) > 0 {
+		contentType := http.DetectContentType(logo)
+		if len(logo) > maxPayslipLogoSize || (contentType != "image/png" && contentType != "image/jpeg") {
+			return nil, domain.ErrInvalidPayslipLogo
+		}
+		if _, _, err := image.Decode(bytes.NewReader(logo)); err != nil {
+			return nil, domain.ErrInvalidPayslipLogo
+		}
+		template.Logo = logo
+		template.LogoContentType = contentType
+	}
+	if err := s.templateRepo.Save(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Render genera el desprendible de una nómina calculada, aprobada o pagada
+func (s *PayslipService) Render(ctx context.Context, payrollID uint) (*Payslip, error) {
+	payroll, err := s.payrollRepo.GetByID(ctx, payrollID)
+	if err != nil {
+		return nil, err
+	}
+	switch payroll.Status {
+	case domain.PayrollStatusDraft, domain.PayrollStatusCancelled:
+		return nil, domain.ErrPayslipNotAvailable
+	}
+	template, err := s.GetTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &payslipData{payroll: payroll, template: template}
+	s.loadEmployeeData(ctx, data)
+	for _, item := range payroll.Items {
+		switch item.Type {
+		case "earning":
+			data.earnings = append(data.earnings, item)
+		case "deduction":
+			data.deductions = append(data.deductions, item)
+		case "employer_contribution":
+			data.contributions = append(data.contributions, item)
+		}
+	}
+
+	content, err := renderPayslip(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Payslip{
+		FileName: fmt.Sprintf("desprendible-%s-%s.pdf", payroll.Employee.User.Dni, payroll.PeriodEnd.Format("2006-01-02")),
+		Content:  content,
+	}, nil
+}
+
+// loadEmployeeData completa cargo, departamento y salario básico. Son datos informativos:
+// si no se encuentran el desprendible se genera sin ellos.
+func (s *PayslipService) loadEmployeeData(ctx context.Context, data *payslipData) {
+	employee := data.payroll.Employee
+	if employee.DepartmentID != 0 {
+		if d, err := s.departmentRepo.GetByID(ctx, employee.DepartmentID); err == nil {
+			data.department = d.Name
+		}
+	}
+	if employee.PositionID != 0 {
+		if p, err := s.positionRepo.GetByID(ctx, employee.PositionID); err == nil {
+			data.position = p.NamePosition
+		}
+	}
+	if contracts, err := s.contractRepo.ListByEmployee(ctx, data.payroll.EmployeeID); err == nil {
+		if c := contractInPeriod(contracts, data.payroll.PeriodStart, data.payroll.PeriodEnd); c != nil {
+			data.baseSalary = c.BaseSalary
+		}
+	}
+}
+
+// contractInPeriod retorna el contrato vigente más reciente dentro del periodo
+func contractInPeriod(contracts []domain.EmployeeContract, start, end time.Time) *domain.EmployeeContract {
+	var found *domain.EmployeeContract
+	for i := range contracts {
+		c := &contracts[i]
+		if c.StartDate.After(end) || (c.EndDate != nil && c.EndDate.Before(start)) {
+			continue
+		}
+		if found == nil || c.StartDate.After(found.StartDate) {
+			found = c
+		}
+	}
+	return found
+}
+
+func defaultPayslipTemplate() *domain.PayslipTemplate {
+	return &domain.PayslipTemplate{
+		Title:                     "Comprobante de pago de nómina",
+		PrimaryColor:              "#1F4E79",
+		ShowEmployerContributions: true,
+	}
+}
+
+// Diseño del desprendible en puntos
+const (
+	payslipMargin = 40.0
+	payslipRight  = pdf.PageWidth - payslipMargin
+	payslipBottom = pdf.PageHeight - 60
+)
+
+// payslipWriter lleva la posición vertical y salta de página cuando no hay espacio
+type payslipWriter struct {
+	doc   *pdf.Document
+	y     float64
+	color pdf.Color
+}
+
+func (w *payslipWriter) ensure(height float64) {
+	if w.y+height > payslipBottom {
+		w.doc.AddPage()
+		w.y = payslipMargin
+	}
+}
+
+func renderPayslip(data *payslipData) ([]byte, error) {
+	t := data.template
+	p := data.payroll
+	doc := pdf.New()
+	doc.AddPage()
+	w := &payslipWriter{doc: doc, y: payslipMargin, color: parseHexColor(t.PrimaryColor)}
+
+	// Encabezado: logo y datos del empleador a la izquierda, título a la derecha
+	textX := payslipMargin
+	if len(t.Logo) > 0 {
+		if img, _, err := image.Decode(bytes.NewReader(t.Logo)); err == nil {
+			b := img.Bounds()
+			height := 50.0
+			width := height * float64(b.Dx()) / float64(b.Dy())
+			if width > 120 {
+				width, height = 120, 120*float64(b.Dy())/float64(b.Dx())
+			}
+			if err := doc.Image(img, payslipMargin, w.y, width, height); err != nil {
+				return nil, err
+			}
+			textX += width + 12
+		}
+	}
+	doc.SetTextColor(pdf.Black)
+	doc.SetFont(pdf.Bold, 13)
+	doc.Text(textX, w.y+14, t.CompanyName)
+	doc.SetFont(pdf.Regular, 9)
+	if t.CompanyNIT != "" {
+		doc.Text(textX, w.y+28, "NIT "+t.CompanyNIT)
+	}
+	doc.Text(textX, w.y+40, strings.TrimSpace(t.CompanyAddress+"  "+t.CompanyPhone))
+
+	doc.SetTextColor(w.color)
+	doc.SetFont(pdf.Bold, 12)
+	doc.TextRight(payslipRight, w.y+14, t.Title)
+	doc.SetTextColor(pdf.Black)
+	doc.SetFont(pdf.Regular, 9)
+	doc.TextRight(payslipRight, w.y+28, fmt.Sprintf("Nómina No. %d", p.ID))
+	doc.TextRight(payslipRight, w.y+40, "Estado: "+p.Status)
+	w.y += 62
+	doc.FillRect(payslipMargin, w.y, payslipRight-payslipMargin, 2, w.color)
+	w.y += 18
+
+	// Datos del empleado y del periodo
+	user := p.Employee.User
+	left := [][2]string{
+		{"Empleado", strings.TrimSpace(user.FirstName + " " + user.LastName)},
+		{"Documento", user.Dni},
+		{"Cargo", data.position},
+		{"Departamento", data.department},
+	}
+	right := [][2]string{
+		{"Periodo", p.PeriodStart.Format("2006-01-02") + " a " + p.PeriodEnd.Format("2006-01-02")},
+		{"Fecha de pago", p.PayDate.Format("2006-01-02")},
+		{"Salario básico", formatCOP(data.baseSalary)},
+	}
+	for i := 0; i < len(left); i++ {
+		w.field(payslipMargin, left[i][0], left[i][1])
+		if i < len(right) {
+			w.field(320, right[i][0], right[i][1])
+		}
+		w.y += 14
+	}
+	w.y += 10
+
+	w.section("Devengos", data.earnings, "Total devengado", p.GrossAmount)
+	w.section("Deducciones", data.deductions, "Total deducciones", p.TotalDeductions)
+	if t.ShowEmployerContributions && len(data.contributions) > 0 {
+		w.section("Aportes del empleador (informativo)", data.contributions, "Total aportes", sumItems(data.contributions))
+	}
+
+	// Neto a pagar con el valor en letras
+	w.ensure(60)
+	doc.FillRect(payslipMargin, w.y, payslipRight-payslipMargin, 24, w.color)
+	doc.SetTextColor(pdf.Color{R: 255, G: 255, B: 255})
+	doc.SetFont(pdf.Bold, 12)
+	doc.Text(payslipMargin+8, w.y+16, "NETO A PAGAR")
+	doc.TextRight(payslipRight-8, w.y+16, formatCOP(p.NetAmount))
+	doc.SetTextColor(pdf.Black)
+	w.y += 38
+	doc.SetFont(pdf.Regular, 9)
+	for _, line := range wrapText(amountInWords(p.NetAmount), pdf.Regular, 9, payslipRight-payslipMargin) {
+		doc.Text(payslipMargin, w.y, line)
+		w.y += 12
+	}
+
+	if t.FooterText != "" {
+		w.y += 12
+		doc.SetFont(pdf.Regular, 8)
+		for _, line := range wrapText(t.FooterText, pdf.Regular, 8, payslipRight-payslipMargin) {
+			w.ensure(12)
+			doc.Text(payslipMargin, w.y, line)
+			w.y += 10
+		}
+	}
+	return doc.Bytes()
+}
+
+func (w *payslipWriter) field(x float64, label, value string) {
+	w.doc.SetFont(pdf.Bold, 9)
+	w.doc.Text(x, w.y, label+":")
+	w.doc.SetFont(pdf.Regular, 9)
+	w.doc.Text(x+85, w.y, value)
+}
+
+// section imprime un grupo de conceptos con su subtotal
+func (w *payslipWriter) section(title string, items []domain.PayrollItem, totalLabel string, total float64) {
+	doc := w.doc
+	w.ensure(40)
+	doc.SetTextColor(w.color)
+	doc.SetFont(pdf.Bold, 10)
+	doc.Text(payslipMargin, w.y, title)
+	doc.SetTextColor(pdf.Black)
+	w.y += 4
+	doc.Line(payslipMargin, w.y, payslipRight, w.y)
+	w.y += 12
+
+	doc.SetFont(pdf.Regular, 9)
+	if len(items) == 0 {
+		doc.Text(payslipMargin, w.y, "Sin conceptos")
+		w.y += 13
+	}
+	for _, item := range items {
+		w.ensure(13)
+		doc.Text(payslipMargin, w.y, item.Code)
+		doc.Text(payslipMargin+110, w.y, item.Name)
+		doc.TextRight(payslipRight, w.y, formatCOP(item.Amount))
+		w.y += 13
+	}
+	doc.Line(payslipMargin+300, w.y-8, payslipRight, w.y-8)
+	doc.SetFont(pdf.Bold, 9)
+	doc.Text(payslipMargin+300, w.y+2, totalLabel)
+	doc.TextRight(payslipRight, w.y+2, formatCOP(total))
+	w.y += 22
+}
+
+func sumItems(items []domain.PayrollItem) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Amount
+	}
+	return roundCents(total)
+}
+
+// formatCOP formatea un monto con separador de miles y decimales colombianos: $ 1.500.000,50
+func formatCOP(amount float64) string {
+	cents := int64(amount*100 + 0.5)
+	sign := ""
+	if amount < 0 {
+		cents = int64(-amount*100 + 0.5)
+		sign = "-"
+	}
+	digits := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return fmt.Sprintf("%s$ %s,%02d", sign, b.String(), cents%100)
+}
+
+// parseHexColor convierte #RRGGBB; un color inválido queda en negro
+func parseHexColor(hex string) pdf.Color {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return pdf.Black
+	}
+	return pdf.Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}
+}
+
+// wrapText parte el texto en líneas que caben en el ancho dado
+func wrapText(text string, style pdf.Style, size, width float64) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		candidate := strings.TrimSpace(current + " " + word)
+		if current != "" && pdf.StringWidth(candidate, style, size) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
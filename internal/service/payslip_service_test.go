@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPayslipTemplateRepo struct {
+	mock.Mock
+}
+
+func (m *MockPayslipTemplateRepo) Get(ctx context.Context) (*domain.PayslipTemplate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PayslipTemplate), args.Error(1)
+}
+
+func (m *MockPayslipTemplateRepo) Save(ctx context.Context, template *domain.PayslipTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func TestAmountInWords(t *testing.T) {
+	cases := map[float64]string{
+		1:          "UN PESO M/CTE",
+		100:        "CIEN PESOS M/CTE",
+		21000:      "VEINTIÚN MIL PESOS M/CTE",
+		1000000:    "UN MILLÓN DE PESOS M/CTE",
+		1500000.5:  "UN MILLÓN QUINIENTOS MIL PESOS CON 50/100 M/CTE",
+		2345678.09: "DOS MILLONES TRESCIENTOS CUARENTA Y CINCO MIL SEISCIENTOS SETENTA Y OCHO PESOS CON 09/100 M/CTE",
+	}
+	for amount, expected := range cases {
+		assert.Equal(t, expected, amountInWords(amount), "amount %.2f", amount)
+	}
+	assert.Equal(t, "$ 1.500.000,50", formatCOP(1500000.5))
+}
+
+func TestPayslipService_Render(t *testing.T) {
+	ctx := context.Background()
+	payrollRepo := new(MockPayrollRepo)
+	contractRepo := new(MockContractRepo)
+	departmentRepo := new(MockDepartmentRepo)
+	positionRepo := new(MockPositionRepo)
+	templateRepo := new(MockPayslipTemplateRepo)
+	svc := NewPayslipService(payrollRepo, contractRepo, departmentRepo, positionRepo, templateRepo)
+
+	logo := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	logo.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var logoPNG bytes.Buffer
+	assert.NoError(t, png.Encode(&logoPNG, logo))
+
+	payroll := &domain.Payroll{
+		ID: 8, EmployeeID: 1, Status: domain.PayrollStatusApproved,
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		PayDate:     time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		GrossAmount: 1600000, TotalDeductions: 100000, NetAmount: 1500000,
+		Employee: domain.Employee{ID: 1, DepartmentID: 2, User: domain.User{FirstName: "Ana", LastName: "Gómez", Dni: "12345678"}},
+		Items: []domain.PayrollItem{
+			{Type: "earning", Code: "SALARY", Name: "Salario", Amount: 1600000},
+			{Type: "deduction", Code: "HEALTH_EMPLOYEE", Name: "Salud", Amount: 64000},
+			{Type: "deduction", Code: "PENSION_EMPLOYEE", Name: "Pensión", Amount: 36000},
+			{Type: "employer_contribution", Code: "PENSION_EMPLOYER", Name: "Pensión empleador", Amount: 192000},
+		},
+	}
+	payrollRepo.On("GetByID", ctx, uint(8)).Return(payroll, nil)
+	departmentRepo.On("GetByID", ctx, uint(2)).Return(&domain.Department{ID: 2, Name: "Finanzas"}, nil)
+	contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), BaseSalary: 1600000},
+	}, nil)
+	templateRepo.On("Get", ctx).Return(&domain.PayslipTemplate{
+		CompanyName: "ACME SAS", CompanyNIT: "900123456-7", Title: "Desprendible",
+		PrimaryColor: "#336699", ShowEmployerContributions: false, Logo: logoPNG.Bytes(),
+	}, nil)
+
+	payslip, err := svc.Render(ctx, 8)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "desprendible-12345678-2026-09-30.pdf", payslip.FileName)
+	assert.True(t, bytes.HasPrefix(payslip.Content, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(payslip.Content, []byte("%%EOF\n")))
+	assert.Contains(t, string(payslip.Content), "(UN MILL\xd3N QUINIENTOS MIL PESOS M/CTE)")
+	assert.Contains(t, string(payslip.Content), "(Finanzas)")
+	assert.Contains(t, string(payslip.Content), "/Subtype /Image /Width 4 /Height 2")
+	assert.NotContains(t, string(payslip.Content), "PENSION_EMPLOYER")
+
+	payroll.Status = domain.PayrollStatusDraft
+	_, err = svc.Render(ctx, 8)
+	assert.ErrorIs(t, err, domain.ErrPayslipNotAvailable)
+}
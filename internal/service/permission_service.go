@@ -49,6 +49,63 @@ func (s *PermissionService) UserHasPermission(ctx context.Context, userID uint,
 	return false, nil
 }
 
+// DataScopes retorna los alcances con que los roles del usuario le otorgan resource.action.
+// Vacío si ningún rol le otorga el permiso.
+func (s *PermissionService) DataScopes(ctx context.Context, userID uint, resource, action string) ([]string, error) {
+	if userID == 0 {
+		return nil, errors.New("invalid user id")
+	}
+	roles, err := s.userRoleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var scopes []string
+	for _, role := range roles {
+		if !role.IsActive {
+			continue
+		}
+		for _, rp := range role.RolePermissions {
+			if !rp.Action.IsActive || !rp.Action.Resource.IsActive {
+				continue
+			}
+			if rp.Action.Resource.Name == resource && rp.Action.Action == action {
+				scope := rp.Scope
+				if scope == "" {
+					scope = domain.DataScopeAll
+				}
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes, nil
+}
+
+// HasScopedPermission indica si algún rol activo del usuario limita su alcance de datos
+// (own_reports u own_department) sobre cualquier recurso
+func (s *PermissionService) HasScopedPermission(ctx context.Context, userID uint) (bool, error) {
+	if userID == 0 {
+		return false, errors.New("invalid user id")
+	}
+	roles, err := s.userRoleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if !role.IsActive {
+			continue
+		}
+		for _, rp := range role.RolePermissions {
+			if !rp.Action.IsActive || !rp.Action.Resource.IsActive {
+				continue
+			}
+			if rp.Scope != "" && rp.Scope != domain.DataScopeAll {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (s *PermissionService) UserHasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
 	if userID == 0 {
 		return false, errors.New("invalid user id")
@@ -118,9 +175,15 @@ func (s *PermissionService) RevokeRoleFromUser(ctx context.Context, userID, role
 	return s.userRoleRepo.RevokeRole(ctx, userID, roleID)
 }
 
-// AssignPermissionToRole asigna un permiso a un rol
-func (s *PermissionService) AssignPermissionToRole(ctx context.Context, roleID, actionID uint) error {
-	return s.roleRepo.AssignPermission(ctx, roleID, actionID)
+// AssignPermissionToRole asigna un permiso a un rol con su alcance de datos (vacío = all)
+func (s *PermissionService) AssignPermissionToRole(ctx context.Context, roleID, actionID uint, scope string) error {
+	if scope == "" {
+		scope = domain.DataScopeAll
+	}
+	if scope != domain.DataScopeAll && scope != domain.DataScopeOwnReports && scope != domain.DataScopeOwnDepartment {
+		return domain.ErrInvalidDataScope
+	}
+	return s.roleRepo.AssignPermission(ctx, roleID, actionID, scope)
 }
 
 // RevokePermissionFromRole revoca un permiso de un rol
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/dian"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/pila"
+)
+
+// pilaPayrollStatuses son los estados de las nóminas que se reportan en la planilla
+var pilaPayrollStatuses = []string{
+	domain.PayrollStatusCalculated, domain.PayrollStatusApproved,
+	domain.PayrollStatusPaid, domain.PayrollStatusPartiallyPaid,
+}
+
+// salaryCodes son los devengos que hacen parte del IBC; los pagos no salariales solo
+// cuentan en lo que excedan el 40% de la remuneración (art. 30 Ley 1393 de 2010)
+var (
+	salaryCodes = []string{
+		domain.ConceptBaseSalary, domain.ConceptOvertime, domain.ConceptVacationPayment,
+		domain.ConceptApprenticeStipend,
+	}
+	nonSalaryCodes = []string{domain.ConceptBonus, domain.ConceptHousing}
+)
+
+const nonSalaryLimit = 0.4
+
+// PILAService genera la planilla de aportes a seguridad social y parafiscales de un mes a
+// partir de las nóminas, los contratos y las ausencias de los empleados
+type PILAService struct {
+	payrollRepo  domain.PayrollRepo
+	contractRepo domain.EmployeeContractRepo
+	absenceRepo  domain.EmployeeAbsenceRepo
+	templateRepo domain.PayslipTemplateRepo
+	settings     pila.Settings
+}
+
+func NewPILAService(
+	payrollRepo domain.PayrollRepo,
+	contractRepo domain.EmployeeContractRepo,
+	absenceRepo domain.EmployeeAbsenceRepo,
+	templateRepo domain.PayslipTemplateRepo,
+	settings pila.Settings,
+) *PILAService {
+	return &PILAService{
+		payrollRepo:  payrollRepo,
+		contractRepo: contractRepo,
+		absenceRepo:  absenceRepo,
+		templateRepo: templateRepo,
+		settings:     settings,
+	}
+}
+
+// Generate arma la planilla del mes con una línea por empleado. Los errores de validación
+// se retornan por línea en el archivo; solo los errores de datos impiden generarlo.
+func (s *PILAService) Generate(ctx context.Context, year, month int) (*pila.File, error) {
+	if year < 2000 || month < 1 || month > 12 {
+		return nil, domain.ErrInvalidPILAPeriod
+	}
+	template, err := employerTemplate(ctx, s.templateRepo)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+
+	payrolls, err := s.payrollRepo.GetByPeriod(ctx, start, end.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	byEmployee := map[uint][]domain.Payroll{}
+	for _, p := range payrolls {
+		if p.Kind != domain.PayrollKindReversal && slices.Contains(pilaPayrollStatuses, p.Status) {
+			byEmployee[p.EmployeeID] = append(byEmployee[p.EmployeeID], p)
+		}
+	}
+	if len(byEmployee) == 0 {
+		return nil, domain.ErrNoPayrollsForPILA
+	}
+
+	absences, err := s.absenceRepo.ListOverlapping(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	absencesByEmployee := map[uint][]domain.EmployeeAbsence{}
+	for _, a := range absences {
+		absencesByEmployee[a.EmployeeID] = append(absencesByEmployee[a.EmployeeID], a)
+	}
+
+	employeeIDs := make([]uint, 0, len(byEmployee))
+	for id := range byEmployee {
+		employeeIDs = append(employeeIDs, id)
+	}
+	sort.Slice(employeeIDs, func(i, j int) bool { return employeeIDs[i] < employeeIDs[j] })
+
+	minWage := minimumWage(year)
+	lines := make([]pila.Line, 0, len(employeeIDs))
+	for _, employeeID := range employeeIDs {
+		line, err := s.line(ctx, byEmployee[employeeID], absencesByEmployee[employeeID], start, end, minWage)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, *line)
+	}
+
+	nit, dv := dian.SplitNIT(template.CompanyNIT)
+	if dv == "" {
+		dv = dian.CheckDigit(nit)
+	}
+	return pila.Build(pila.Header{
+		Settings:    s.settings,
+		Name:        template.CompanyName,
+		NIT:         nit,
+		DV:          dv,
+		Period:      start,
+		MinimumWage: minWage,
+	}, lines), nil
+}
+
+// line liquida los aportes de un empleado: días por contrato, novedades por ausencias e IBC
+// y tarifas según los items de sus nóminas
+func (s *PILAService) line(ctx context.Context, payrolls []domain.Payroll, absences []domain.EmployeeAbsence, start, end time.Time, minWage float64) (*pila.Line, error) {
+	employee := payrolls[0].Employee
+	firstName, otherNames := splitWords(employee.User.FirstName)
+	firstSurname, secondSurname := splitWords(employee.User.LastName)
+	line := &pila.Line{
+		EmployeeID:       employee.ID,
+		DocumentType:     "CC",
+		DocumentNumber:   strings.TrimSpace(employee.User.Dni),
+		ContributorType:  pila.ContributorEmployee,
+		DepartmentCode:   s.settings.DepartmentCode,
+		CityCode:         s.settings.CityCode,
+		FirstName:        firstName,
+		OtherNames:       otherNames,
+		FirstSurname:     firstSurname,
+		SecondSurname:    secondSurname,
+		HealthFund:       employee.HealthFundCode,
+		PensionFund:      employee.PensionFundCode,
+		CompensationFund: s.settings.CompensationFund,
+		ARLCode:          s.settings.ARLCode,
+		RiskClass:        employee.RiskClass,
+		EconomicActivity: s.settings.EconomicActivity,
+	}
+	if line.RiskClass == 0 {
+		line.RiskClass = 1
+	}
+
+	// Contratos: ingreso, retiro y variación de salario dentro del mes
+	contracts, err := s.contractRepo.ListByEmployee(ctx, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].StartDate.Before(contracts[j].StartDate) })
+	from, to := start, end
+	hoursPerDay := 8.0
+	var current *domain.EmployeeContract
+	for i := range contracts {
+		c := &contracts[i]
+		if c.StartDate.After(end) || (c.EndDate != nil && c.EndDate.Before(start)) {
+			continue
+		}
+		if i == 0 && !c.StartDate.Before(start) {
+			hire := c.StartDate
+			line.HireDate = &hire
+			from = hire
+		}
+		if current != nil && c.BaseSalary != current.BaseSalary && !c.StartDate.Before(start) {
+			change := c.StartDate
+			line.SalaryChangeDate = &change
+		}
+		current = c
+	}
+	if current != nil {
+		line.Salary = current.BaseSalary
+		if current.WorkHoursPerDay > 0 {
+			hoursPerDay = current.WorkHoursPerDay
+		}
+		if current.EndDate != nil && !current.EndDate.After(end) {
+			retirement := *current.EndDate
+			line.RetirementDate = &retirement
+			to = retirement
+		}
+	}
+	days := pilaDays(from, to, end)
+
+	// Novedades: vacaciones, licencias e incapacidades dentro del mes
+	for _, a := range absences {
+		novelty := pila.Novelty{Start: laterOf(a.StartDate, from), End: earlierOf(a.EndDate, to), Reference: a.Reference}
+		if novelty.End.Before(novelty.Start) {
+			continue
+		}
+		novelty.Days = pilaDays(novelty.Start, novelty.End, end)
+		switch a.Kind {
+		case domain.AbsenceVacation:
+			line.Vacation = mergeNovelty(line.Vacation, novelty)
+		case domain.AbsencePaidLeave:
+			line.PaidLeave = mergeNovelty(line.PaidLeave, novelty)
+		case domain.AbsenceUnpaidLeave:
+			line.UnpaidLeave = mergeNovelty(line.UnpaidLeave, novelty)
+		case domain.AbsenceSickLeave:
+			line.SickLeave = mergeNovelty(line.SickLeave, novelty)
+		case domain.AbsenceMaternity:
+			line.Maternity = mergeNovelty(line.Maternity, novelty)
+		case domain.AbsenceWorkAccident:
+			line.WorkAccident = mergeNovelty(line.WorkAccident, novelty)
+		}
+	}
+
+	// IBC y tarifas desde los items de nómina
+	var salary, nonSalary float64
+	hasPension, hasEmployerHealth, apprentice := false, false, false
+	for _, p := range payrolls {
+		for _, item := range p.Items {
+			code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+			switch {
+			case item.Type == domain.PayrollTypeEarning && slices.Contains(salaryCodes, code):
+				salary += item.Amount
+				apprentice = apprentice || code == domain.ConceptApprenticeStipend
+			case item.Type == domain.PayrollTypeEarning && slices.Contains(nonSalaryCodes, code):
+				nonSalary += item.Amount
+			case code == domain.ConceptPension || code == domain.ConceptPensionEmployer:
+				hasPension = hasPension || item.Amount > 0
+			case code == domain.ConceptHealthEmployer:
+				hasEmployerHealth = hasEmployerHealth || item.Amount > 0
+			}
+		}
+	}
+	ibc := salary + math.Max(0, nonSalary-nonSalaryLimit*(salary+nonSalary))
+
+	line.HealthDays = days
+	line.Hours = int(math.Round(float64(days) * hoursPerDay))
+	line.IBCHealth = ibc
+	if apprentice {
+		// Aprendiz en etapa lectiva: salud a cargo del empleador sobre un SMMLV proporcional
+		line.ContributorType = pila.ContributorApprentice
+		line.IBCHealth = minWage * float64(days) / 30
+		line.HealthRate = pila.HealthRate
+		line.RiskClass = 1
+		return line, nil
+	}
+
+	line.Exonerated = !hasEmployerHealth
+	line.HealthRate = pila.HealthRateExonerated
+	if hasEmployerHealth {
+		line.HealthRate = pila.HealthRate
+		line.SENARate = pila.SENARate
+		line.ICBFRate = pila.ICBFRate
+	}
+	if hasPension {
+		line.PensionRate = pila.PensionRate
+		line.PensionDays = days
+		line.IBCPension = ibc
+	}
+	line.CCFRate = pila.CompensationFundRate
+	line.CCFDays = max(0, days-pila.NoveltyDays(line.UnpaidLeave))
+	line.RiskDays = max(0, days-pila.NoveltyDays(line.Vacation, line.PaidLeave, line.UnpaidLeave,
+		line.SickLeave, line.Maternity, line.WorkAccident))
+	if rate, ok := pila.RiskRate(line.RiskClass); ok {
+		line.RiskRate = rate
+	}
+	if days > 0 {
+		line.IBCCCF = ibc * float64(line.CCFDays) / float64(days)
+		line.IBCRisk = ibc * float64(line.RiskDays) / float64(days)
+	}
+	return line, nil
+}
+
+// pilaDays cuenta los días sobre base 30; un rango que llega al fin de mes lo completa a 30
+func pilaDays(from, to, monthEnd time.Time) int {
+	days := domain.Days360(from, to)
+	if !to.Before(monthEnd) {
+		days += 30 - min(to.Day(), 30)
+	}
+	return min(days, 30)
+}
+
+// mergeNovelty acumula varias ausencias del mismo tipo en una sola novedad
+func mergeNovelty(current *pila.Novelty, next pila.Novelty) *pila.Novelty {
+	if current == nil {
+		return &next
+	}
+	current.Start = earlierOf(current.Start, next.Start)
+	current.End = laterOf(current.End, next.End)
+	current.Days += next.Days
+	if current.Reference == "" {
+		current.Reference = next.Reference
+	}
+	return current
+}
+
+// splitWords separa la primera palabra del resto (primer nombre y segundo nombre)
+func splitWords(value string) (string, string) {
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return "", ""
+	}
+	return words[0], strings.Join(words[1:], " ")
+}
+
+func earlierOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
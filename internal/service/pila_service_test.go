@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/pila"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockEmployeeAbsenceRepo struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeAbsenceRepo) Create(ctx context.Context, absence *domain.EmployeeAbsence) error {
+	args := m.Called(ctx, absence)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeAbsenceRepo) GetByID(ctx context.Context, id uint) (*domain.EmployeeAbsence, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeeAbsence), args.Error(1)
+}
+
+func (m *MockEmployeeAbsenceRepo) ListByEmployee(ctx context.Context, employeeID uint) ([]domain.EmployeeAbsence, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]domain.EmployeeAbsence), args.Error(1)
+}
+
+func (m *MockEmployeeAbsenceRepo) ListOverlapping(ctx context.Context, start, end time.Time) ([]domain.EmployeeAbsence, error) {
+	args := m.Called(ctx, start, end)
+	return args.Get(0).([]domain.EmployeeAbsence), args.Error(1)
+}
+
+func (m *MockEmployeeAbsenceRepo) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+var testPILASettings = pila.Settings{
+	OperatorCode: "83", ARLCode: "14-23", CompensationFund: "CCF22",
+	DepartmentCode: "11", CityCode: "001", EconomicActivity: "1000000",
+}
+
+func pilaDate(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func newPILAService(ctx context.Context, payrolls []domain.Payroll, absences []domain.EmployeeAbsence) *PILAService {
+	payrollRepo := new(MockPayrollRepo)
+	contractRepo := new(MockContractRepo)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	templateRepo := new(MockPayslipTemplateRepo)
+	templateRepo.On("Get", ctx).Return(&domain.PayslipTemplate{CompanyName: "ACME SAS", CompanyNIT: "900123456-7"}, nil)
+	payrollRepo.On("GetByPeriod", ctx, pilaDate(9, 1), mock.Anything).Return(payrolls, nil)
+	absenceRepo.On("ListOverlapping", ctx, pilaDate(9, 1), pilaDate(9, 30)).Return(absences, nil)
+	contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{
+		{StartDate: pilaDate(1, 15).AddDate(-2, 0, 0), BaseSalary: 2000000},
+	}, nil)
+	contractRepo.On("ListByEmployee", ctx, uint(2)).Return([]domain.EmployeeContract{
+		{StartDate: pilaDate(9, 16), BaseSalary: 1000000},
+	}, nil)
+	return NewPILAService(payrollRepo, contractRepo, absenceRepo, templateRepo, testPILASettings)
+}
+
+func pilaPayrolls() []domain.Payroll {
+	return []domain.Payroll{
+		{
+			ID: 5, EmployeeID: 1, Status: domain.PayrollStatusPaid, Kind: domain.PayrollKindRegular,
+			PeriodStart: pilaDate(9, 1), PeriodEnd: pilaDate(9, 30),
+			Employee: domain.Employee{
+				ID: 1, HealthFundCode: "EPS037", PensionFundCode: "230301", RiskClass: 1,
+				User: domain.User{FirstName: "Ana María", LastName: "Gómez Ruiz", Dni: "12345678"},
+			},
+			Items: []domain.PayrollItem{
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 2000000},
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptTransport, Amount: 200000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptHealth, Amount: 80000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 80000},
+				{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptPensionEmployer, Amount: 240000},
+			},
+		},
+		{
+			ID: 6, EmployeeID: 2, Status: domain.PayrollStatusApproved, Kind: domain.PayrollKindRegular,
+			PeriodStart: pilaDate(9, 16), PeriodEnd: pilaDate(9, 30),
+			Employee: domain.Employee{
+				ID: 2, PensionFundCode: "230201", RiskClass: 3,
+				User: domain.User{FirstName: "Luis", LastName: "Peña", Dni: "98765432"},
+			},
+			Items: []domain.PayrollItem{
+				{Type: domain.PayrollTypeEarning, Code: domain.ConceptBaseSalary, Amount: 500000},
+				{Type: domain.PayrollTypeDeduction, Code: domain.ConceptPension, Amount: 20000},
+				{Type: domain.PayrollTypeEmployerContribution, Code: domain.ConceptHealthEmployer, Amount: 42500},
+			},
+		},
+		// Los borradores no se reportan
+		{ID: 7, EmployeeID: 3, Status: domain.PayrollStatusDraft, Kind: domain.PayrollKindRegular},
+	}
+}
+
+func TestPILAService_Generate_FixedWidthFile(t *testing.T) {
+	ctx := context.Background()
+	absences := []domain.EmployeeAbsence{
+		{EmployeeID: 1, Kind: domain.AbsenceVacation, StartDate: pilaDate(9, 10), EndDate: pilaDate(9, 16)},
+	}
+	svc := newPILAService(ctx, pilaPayrolls(), absences)
+
+	file, err := svc.Generate(ctx, 2026, 9)
+
+	require.NoError(t, err)
+	records := strings.Split(strings.TrimSuffix(string(file.Content), "\r\n"), "\r\n")
+	require.Len(t, records, 3)
+	assert.Len(t, records[0], 359)
+	assert.True(t, strings.HasPrefix(records[0], "0110001ACME SAS"))
+	assert.Contains(t, records[0], "NI900123456       7E")
+	assert.Contains(t, records[0], "2026-092026-10")
+	for _, r := range records[1:] {
+		assert.Len(t, r, 693)
+	}
+
+	// Empleado con vacaciones: días ARL descontados y aportes sobre el IBC
+	line := file.Lines[0]
+	assert.Equal(t, 30, line.HealthDays)
+	assert.Equal(t, 23, line.RiskDays)
+	assert.Equal(t, 2000000.0, line.IBCHealth)
+	assert.Equal(t, 1533334.0, line.IBCRisk)
+	assert.Equal(t, 320000.0, line.PensionValue)
+	assert.Equal(t, 80000.0, line.HealthValue)
+	assert.Equal(t, 8100.0, line.RiskValue)
+	assert.Equal(t, 80000.0, line.CCFValue)
+	assert.Equal(t, 0.0, line.SENAValue)
+	assert.True(t, line.Exonerated)
+	assert.True(t, strings.HasPrefix(records[1], "0200001CC12345678        0100  11001GOMEZ"))
+	assert.Equal(t, byte('X'), records[1][148], "VAC-LR")
+	assert.Equal(t, byte(' '), records[1][136], "ING")
+	assert.Contains(t, records[1], "2026-09-102026-09-16")
+	assert.Contains(t, records[1], "0.16000")
+
+	// Ingreso a mitad de mes, sin EPS y con IBC inferior al mínimo proporcional
+	hired := file.Lines[1]
+	assert.Equal(t, 15, hired.HealthDays)
+	assert.Equal(t, byte('X'), records[2][136], "ING")
+	assert.Equal(t, 0.12500, hired.HealthRate)
+	assert.Equal(t, 10000.0, hired.SENAValue)
+	assert.Equal(t, 0.02436, hired.RiskRate)
+	assert.Contains(t, records[2], "LUIS")
+	assert.Equal(t, []pila.LineError{
+		{Line: 2, EmployeeID: 2, Message: "health fund (EPS) code is required"},
+		{Line: 2, EmployeeID: 2, Message: "health IBC 500000 is below the minimum wage for 15 days (875453)"},
+		{Line: 2, EmployeeID: 2, Message: "pension IBC 500000 is below the minimum wage for 15 days"},
+	}, file.Errors)
+	assert.Equal(t, 2500000.0, file.Header.PayrollTotal)
+}
+
+func TestPILAService_Generate_Errors(t *testing.T) {
+	ctx := context.Background()
+	svc := newPILAService(ctx, []domain.Payroll{{ID: 7, EmployeeID: 3, Status: domain.PayrollStatusDraft}}, nil)
+
+	_, err := svc.Generate(ctx, 2026, 13)
+	assert.ErrorIs(t, err, domain.ErrInvalidPILAPeriod)
+
+	_, err = svc.Generate(ctx, 2026, 9)
+	assert.ErrorIs(t, err, domain.ErrNoPayrollsForPILA)
+}
+
+func TestAbsenceService_Create_RejectsOverlap(t *testing.T) {
+	ctx := withActor(context.Background(), 4)
+	employeeRepo := new(MockEmployeeRepo)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1}, nil)
+	absenceRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeAbsence{
+		{ID: 9, EmployeeID: 1, Kind: domain.AbsenceVacation, StartDate: pilaDate(9, 10), EndDate: pilaDate(9, 16)},
+	}, nil)
+	absenceRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeAbsence")).Return(nil)
+	svc := NewAbsenceService(&MockTxManager{}, absenceRepo, employeeRepo, newOpenPeriodRepo(), nil, nil)
+
+	err := svc.Create(ctx, 1, &domain.EmployeeAbsence{Kind: domain.AbsenceSickLeave, StartDate: pilaDate(9, 16), EndDate: pilaDate(9, 18)})
+	assert.ErrorIs(t, err, domain.ErrAbsenceOverlap)
+
+	err = svc.Create(ctx, 1, &domain.EmployeeAbsence{Kind: "holiday", StartDate: pilaDate(9, 20), EndDate: pilaDate(9, 21)})
+	assert.ErrorIs(t, err, domain.ErrInvalidAbsenceKind)
+
+	absence := &domain.EmployeeAbsence{Kind: " Sick_Leave ", StartDate: pilaDate(9, 17), EndDate: pilaDate(9, 18), Reference: "INC-1"}
+	require.NoError(t, svc.Create(ctx, 1, absence))
+	assert.Equal(t, domain.AbsenceSickLeave, absence.Kind)
+	assert.Equal(t, uint(1), absence.EmployeeID)
+	assert.Equal(t, uint(4), absence.CreatedBy)
+}
+
+func TestAbsenceService_RejectsClosedPeriod(t *testing.T) {
+	ctx := withActor(context.Background(), 4)
+	employeeRepo := new(MockEmployeeRepo)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	periodRepo := new(MockAccountingPeriodRepo)
+	periodRepo.On("FindOverlapping", ctx, mock.Anything, mock.Anything).Return([]domain.AccountingPeriod{
+		{ID: 1, Status: domain.PeriodStatusClosed, PeriodStart: pilaDate(9, 1), PeriodEnd: pilaDate(9, 30)},
+	}, nil)
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1}, nil)
+	absenceRepo.On("GetByID", ctx, uint(9)).Return(&domain.EmployeeAbsence{
+		ID: 9, EmployeeID: 1, Kind: domain.AbsenceVacation, StartDate: pilaDate(9, 10), EndDate: pilaDate(9, 16),
+	}, nil)
+	svc := NewAbsenceService(&MockTxManager{}, absenceRepo, employeeRepo, periodRepo, nil, nil)
+
+	err := svc.Create(ctx, 1, &domain.EmployeeAbsence{Kind: domain.AbsenceSickLeave, StartDate: pilaDate(9, 20), EndDate: pilaDate(9, 22)})
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+
+	err = svc.Delete(ctx, 1, 9)
+	assert.ErrorIs(t, err, domain.ErrPeriodClosed)
+
+	absenceRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	absenceRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAbsenceService_List_AppliesDataScope(t *testing.T) {
+	scopeSvc, employeeRepo, ctx := newDataScopeService(roleWithScope(domain.ResourceEmployees, domain.DataScopeOwnReports))
+	employeeRepo.On("GetByUserID", ctx, uint(7)).Return(&domain.Employee{ID: 10}, nil)
+	employeeRepo.On("ListByManagers", ctx, []uint{10}).Return([]domain.Employee{}, nil)
+	employeeRepo.On("GetByID", mock.Anything, uint(11)).Return(&domain.Employee{ID: 11}, nil)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	absenceRepo.On("ListByEmployee", mock.MatchedBy(func(c context.Context) bool {
+		scope := employeeScopeFromCtx(c)
+		return scope != nil && assert.ObjectsAreEqual([]uint{10}, scope.EmployeeIDs)
+	}), uint(11)).Return([]domain.EmployeeAbsence{}, nil)
+	svc := NewAbsenceService(&MockTxManager{}, absenceRepo, employeeRepo, newOpenPeriodRepo(), scopeSvc, nil)
+
+	absences, err := svc.List(ctx, 11)
+
+	require.NoError(t, err)
+	assert.Empty(t, absences)
+	absenceRepo.AssertExpectations(t)
+}
+
+// newAbsenceRepoMock crea un repo de ausencias sin novedades para ningún empleado
+func newAbsenceRepoMock() *MockEmployeeAbsenceRepo {
+	repo := new(MockEmployeeAbsenceRepo)
+	repo.On("ListByEmployee", mock.Anything, mock.Anything).Return([]domain.EmployeeAbsence{}, nil)
+	return repo
+}
+
+func TestAbsenceService_Create_UnpaidLeaveRunsRetro(t *testing.T) {
+	ctx := withActor(context.Background(), 4)
+	employeeRepo := new(MockEmployeeRepo)
+	employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, TenantID: 1}, nil)
+	absenceRepo := new(MockEmployeeAbsenceRepo)
+	absenceRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeAbsence{}, nil)
+	absenceRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeAbsence")).Return(nil)
+	payrollRepo := new(MockPayrollRepo)
+	payrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{}, nil)
+	contractRepo := new(MockContractRepo)
+	contractRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.EmployeeContract{}, nil)
+	conceptRepo := new(MockConceptRepo)
+	conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{}, nil)
+	retroSvc := NewPayrollRetroService(&MockTxManager{}, payrollRepo, employeeRepo, contractRepo, conceptRepo,
+		new(MockRetroAdjustmentRepo), &PayrollCalculatorService{absenceRepo: absenceRepo})
+	svc := NewAbsenceService(&MockTxManager{}, absenceRepo, employeeRepo, newOpenPeriodRepo(), nil, retroSvc)
+
+	require.NoError(t, svc.Create(ctx, 1, &domain.EmployeeAbsence{Kind: domain.AbsenceSickLeave, StartDate: pilaDate(1, 5), EndDate: pilaDate(1, 6)}))
+	payrollRepo.AssertNotCalled(t, "ListByEmployee", mock.Anything, mock.Anything)
+
+	require.NoError(t, svc.Create(ctx, 1, &domain.EmployeeAbsence{Kind: domain.AbsenceUnpaidLeave, StartDate: pilaDate(1, 10), EndDate: pilaDate(1, 12)}))
+	payrollRepo.AssertCalled(t, "ListByEmployee", ctx, uint(1))
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// PositionService administra los cargos de cada departamento
+type PositionService struct {
+	positionRepo   domain.PositionRepo
+	departmentRepo domain.DepartmentRepo
+}
+
+func NewPositionService(positionRepo domain.PositionRepo, departmentRepo domain.DepartmentRepo) *PositionService {
+	return &PositionService{
+		positionRepo:   positionRepo,
+		departmentRepo: departmentRepo,
+	}
+}
+
+func (s *PositionService) Create(ctx context.Context, position *domain.Position) error {
+	if position == nil {
+		return errors.New("position cannot be nil")
+	}
+	if err := s.validate(ctx, position); err != nil {
+		return err
+	}
+	return s.positionRepo.Create(ctx, position)
+}
+
+func (s *PositionService) GetByID(ctx context.Context, id uint) (*domain.Position, error) {
+	if id == 0 {
+		return nil, errors.New("invalid id")
+	}
+	return s.positionRepo.GetByID(ctx, id)
+}
+
+func (s *PositionService) List(ctx context.Context, departmentID uint) ([]domain.Position, error) {
+	return s.positionRepo.List(ctx, departmentID)
+}
+
+func (s *PositionService) Update(ctx context.Context, position *domain.Position) error {
+	if position == nil || position.ID == 0 {
+		return errors.New("invalid position id")
+	}
+	if err := s.validate(ctx, position); err != nil {
+		return err
+	}
+	return s.positionRepo.Update(ctx, position)
+}
+
+func (s *PositionService) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("invalid id")
+	}
+	return s.positionRepo.Delete(ctx, id)
+}
+
+func (s *PositionService) validate(ctx context.Context, position *domain.Position) error {
+	position.NamePosition = strings.TrimSpace(position.NamePosition)
+	if position.NamePosition == "" {
+		return errors.New("position name is required")
+	}
+	if position.DepartmentID == 0 {
+		return errors.New("position department is required")
+	}
+	if _, err := s.departmentRepo.GetByID(ctx, position.DepartmentID); err != nil {
+		return err
+	}
+	return validateSalaryBand(position)
+}
+
+// validateSalaryBand exige una banda completa y ordenada; un cargo sin valores no tiene banda.
+// Si no se indica el punto medio se toma el promedio de mínimo y máximo.
+func validateSalaryBand(position *domain.Position) error {
+	position.SalaryCurrency = strings.ToUpper(strings.TrimSpace(position.SalaryCurrency))
+	if position.SalaryMin == 0 && position.SalaryMid == 0 && position.SalaryMax == 0 {
+		return nil
+	}
+	if position.SalaryMid == 0 {
+		position.SalaryMid = roundCents((position.SalaryMin + position.SalaryMax) / 2)
+	}
+	if position.SalaryMin <= 0 || position.SalaryMin > position.SalaryMid ||
+		position.SalaryMid > position.SalaryMax || len(position.SalaryCurrency) != 3 {
+		return domain.ErrInvalidSalaryBand
+	}
+	return nil
+}
+
+// hasSalaryBand indica si el cargo tiene banda salarial definida
+func hasSalaryBand(position *domain.Position) bool {
+	return position.SalaryMax > 0
+}
+
+// salaryInBand indica si el salario y su moneda caben en la banda del cargo
+func salaryInBand(position *domain.Position, salary float64, currency string) bool {
+	if position.SalaryCurrency != "" && !strings.EqualFold(currency, position.SalaryCurrency) {
+		return false
+	}
+	return salary >= position.SalaryMin && salary <= position.SalaryMax
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/bankfile"
+	"github.com/arrase21/crm-users/internal/bankstatement"
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ReconciliationService importa extractos bancarios y los concilia contra los pagos
+type ReconciliationService struct {
+	txManager     domain.TxManager
+	statementRepo domain.BankStatementRepo
+	paymentRepo   domain.PaymentRepo
+	paymentSvc    *PaymentService
+}
+
+func NewReconciliationService(
+	txManager domain.TxManager,
+	statementRepo domain.BankStatementRepo,
+	paymentRepo domain.PaymentRepo,
+	paymentSvc *PaymentService,
+) *ReconciliationService {
+	return &ReconciliationService{
+		txManager:     txManager,
+		statementRepo: statementRepo,
+		paymentRepo:   paymentRepo,
+		paymentSvc:    paymentSvc,
+	}
+}
+
+// ReconciliationReport resume la conciliación de un extracto
+type ReconciliationReport struct {
+	Statement        *domain.BankStatement
+	MatchedAmount    float64
+	UnmatchedAmount  float64
+	DifferenceAmount float64
+	Exceptions       []domain.BankStatementLine
+}
+
+// ResolveLineRequest resuelve a mano una excepción: la asocia a un pago o la ignora
+type ResolveLineRequest struct {
+	PaymentID *uint
+	Ignore    bool
+	Note      string
+}
+
+// Import lee el extracto y concilia cada débito contra los pagos pendientes o enviados:
+// primero por referencia (EndToEndId del archivo bancario) y si no, por cuenta y monto.
+// Los pagos conciliados quedan completed; el resto de líneas queda como excepción.
+func (s *ReconciliationService) Import(ctx context.Context, format, fileName string, content []byte) (*domain.BankStatement, error) {
+	hash := bankfile.FileHash(content)
+	if _, err := s.statementRepo.GetByHash(ctx, hash); err == nil {
+		return nil, domain.ErrStatementAlreadyImported
+	} else if !errors.Is(err, domain.ErrStatementNotFound) {
+		return nil, err
+	}
+	parsed, err := bankstatement.Parse(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &domain.BankStatement{
+		Format:        strings.ToLower(format),
+		FileName:      fileName,
+		Hash:          hash,
+		Reference:     parsed.Reference,
+		AccountNumber: parsed.AccountNumber,
+		LineCount:     len(parsed.Lines),
+		ImportedBy:    actorFromCtx(ctx),
+		CreatedAt:     time.Now(),
+		Lines:         make([]domain.BankStatementLine, len(parsed.Lines)),
+	}
+
+	// El cruce se hace dentro de la transacción y cada pago conciliado se bloquea, para que
+	// dos extractos importados a la vez no confirmen el mismo pago
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		m, err := s.newMatcher(ctx)
+		if err != nil {
+			return err
+		}
+		for i, l := range parsed.Lines {
+			line := domain.BankStatementLine{
+				LineNumber:    l.Number,
+				Amount:        l.Amount,
+				Debit:         l.Debit,
+				AccountNumber: l.Account,
+				Reference:     l.Reference,
+				Description:   l.Description,
+			}
+			if !l.Date.IsZero() {
+				date := l.Date
+				line.BookingDate = &date
+			}
+			if err := m.match(ctx, &line); err != nil {
+				return err
+			}
+			if line.Status == domain.StatementLineMatched {
+				if err := s.claimPayment(ctx, &line); err != nil {
+					return err
+				}
+			}
+			statement.Lines[i] = line
+		}
+		countLines(statement)
+
+		if err := s.statementRepo.Create(ctx, statement); err != nil {
+			return err
+		}
+		for _, line := range statement.Lines {
+			if line.Status != domain.StatementLineMatched {
+				continue
+			}
+			if _, err := s.paymentSvc.UpdateStatus(ctx, *line.PaymentID, domain.PaymentStatusCompleted, ""); err != nil {
+				return fmt.Errorf("line %d: %w", line.LineNumber, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+// claimPayment bloquea el pago de una línea conciliada y la deja como duplicada si otro
+// extracto ya lo confirmó o concilió
+func (s *ReconciliationService) claimPayment(ctx context.Context, line *domain.BankStatementLine) error {
+	payment, err := s.paymentRepo.LockByID(ctx, *line.PaymentID)
+	if err != nil {
+		return err
+	}
+	if payment.Status != domain.PaymentStatusPending && payment.Status != domain.PaymentStatusSent {
+		line.Status = domain.StatementLineDuplicate
+		line.Note = "payment already confirmed"
+		return nil
+	}
+	matched, err := s.statementRepo.IsPaymentMatched(ctx, payment.ID)
+	if err != nil {
+		return err
+	}
+	if matched {
+		line.Status = domain.StatementLineDuplicate
+		line.Note = "payment already reconciled in another statement"
+	}
+	return nil
+}
+
+// statementMatcher guarda los pagos por conciliar y los ya usados durante una importación
+type statementMatcher struct {
+	paymentRepo domain.PaymentRepo
+	candidates  []domain.Payment
+	byRef       map[string]*domain.Payment
+	claimed     map[uint]bool
+	seenRefs    map[string]bool
+}
+
+func (s *ReconciliationService) newMatcher(ctx context.Context) (*statementMatcher, error) {
+	m := &statementMatcher{
+		paymentRepo: s.paymentRepo,
+		byRef:       make(map[string]*domain.Payment),
+		claimed:     make(map[uint]bool),
+		seenRefs:    make(map[string]bool),
+	}
+	for _, status := range []string{domain.PaymentStatusPending, domain.PaymentStatusSent} {
+		payments, err := s.paymentRepo.ListByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range payments {
+			if p.Method == domain.PaymentMethodBankTransfer {
+				m.candidates = append(m.candidates, p)
+			}
+		}
+	}
+	for i := range m.candidates {
+		if ref := m.candidates[i].ProviderRef; ref != "" {
+			m.byRef[ref] = &m.candidates[i]
+		}
+	}
+	return m, nil
+}
+
+// match clasifica la línea: matched, difference, duplicate, unmatched o ignored (créditos)
+func (m *statementMatcher) match(ctx context.Context, line *domain.BankStatementLine) error {
+	if !line.Debit {
+		line.Status = domain.StatementLineIgnored
+		line.Note = "credit entry"
+		return nil
+	}
+
+	if ref := line.Reference; ref != "" {
+		if m.seenRefs[ref] {
+			line.Status = domain.StatementLineDuplicate
+			line.Note = "reference repeated in the statement"
+			return nil
+		}
+		m.seenRefs[ref] = true
+
+		if p, ok := m.byRef[ref]; ok {
+			m.assign(line, p)
+			return nil
+		}
+		// Una referencia de un pago ya confirmado es un movimiento repetido
+		p, err := m.paymentRepo.GetByProviderRef(ctx, ref)
+		if err != nil && !errors.Is(err, domain.ErrPaymentNotFound) {
+			return err
+		}
+		if p != nil && p.Status == domain.PaymentStatusCompleted {
+			paymentID := p.ID
+			line.Status = domain.StatementLineDuplicate
+			line.PaymentID = &paymentID
+			line.Note = "payment already confirmed"
+			return nil
+		}
+	}
+
+	if line.AccountNumber == "" {
+		line.Status = domain.StatementLineUnmatched
+		return nil
+	}
+	var exact, sameAccount []*domain.Payment
+	for i := range m.candidates {
+		p := &m.candidates[i]
+		if m.claimed[p.ID] || p.AccountNumber != line.AccountNumber {
+			continue
+		}
+		sameAccount = append(sameAccount, p)
+		if roundCents(p.Amount) == line.Amount {
+			exact = append(exact, p)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		m.assign(line, exact[0])
+	case len(exact) > 1:
+		line.Status = domain.StatementLineUnmatched
+		line.Note = fmt.Sprintf("%d payments match account and amount", len(exact))
+	case len(sameAccount) == 1:
+		m.assign(line, sameAccount[0])
+	default:
+		line.Status = domain.StatementLineUnmatched
+	}
+	return nil
+}
+
+// assign asocia la línea al pago; si los montos o la cuenta no coinciden queda como diferencia
+func (m *statementMatcher) assign(line *domain.BankStatementLine, p *domain.Payment) {
+	if m.claimed[p.ID] {
+		line.Status = domain.StatementLineDuplicate
+		line.Note = "payment already matched in the statement"
+		return
+	}
+	paymentID := p.ID
+	line.PaymentID = &paymentID
+	line.Difference = roundCents(line.Amount - p.Amount)
+	switch {
+	case line.Difference != 0:
+		line.Status = domain.StatementLineDifference
+	case line.AccountNumber != "" && line.AccountNumber != p.AccountNumber:
+		line.Status = domain.StatementLineDifference
+		line.Note = "account differs from the payment"
+	default:
+		line.Status = domain.StatementLineMatched
+		m.claimed[p.ID] = true
+	}
+}
+
+// countLines recalcula los totales de conciliación del extracto
+func countLines(statement *domain.BankStatement) {
+	statement.MatchedCount = 0
+	statement.ExceptionCount = 0
+	for _, l := range statement.Lines {
+		switch l.Status {
+		case domain.StatementLineMatched, domain.StatementLineResolved:
+			statement.MatchedCount++
+		case domain.StatementLineUnmatched, domain.StatementLineDifference, domain.StatementLineDuplicate:
+			statement.ExceptionCount++
+		}
+	}
+}
+
+// List retorna los extractos importados
+func (s *ReconciliationService) List(ctx context.Context) ([]domain.BankStatement, error) {
+	return s.statementRepo.List(ctx)
+}
+
+// Report retorna el extracto con los montos conciliados y las excepciones pendientes
+func (s *ReconciliationService) Report(ctx context.Context, statementID uint) (*ReconciliationReport, error) {
+	statement, err := s.statementRepo.GetByID(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+	report := &ReconciliationReport{Statement: statement, Exceptions: []domain.BankStatementLine{}}
+	for _, l := range statement.Lines {
+		switch l.Status {
+		case domain.StatementLineMatched, domain.StatementLineResolved:
+			report.MatchedAmount += l.Amount
+		case domain.StatementLineDifference:
+			report.DifferenceAmount += l.Difference
+			report.Exceptions = append(report.Exceptions, l)
+		case domain.StatementLineUnmatched, domain.StatementLineDuplicate:
+			report.UnmatchedAmount += l.Amount
+			report.Exceptions = append(report.Exceptions, l)
+		}
+	}
+	report.MatchedAmount = roundCents(report.MatchedAmount)
+	report.UnmatchedAmount = roundCents(report.UnmatchedAmount)
+	report.DifferenceAmount = roundCents(report.DifferenceAmount)
+	return report, nil
+}
+
+// ResolveLine resuelve a mano una excepción. Asociarla a un pago lo confirma aunque haya
+// diferencia de monto; la nota queda como justificación.
+func (s *ReconciliationService) ResolveLine(ctx context.Context, statementID, lineID uint, req ResolveLineRequest) (*domain.BankStatementLine, error) {
+	if strings.TrimSpace(req.Note) == "" {
+		return nil, errors.New("resolution note is required")
+	}
+	if !req.Ignore && req.PaymentID == nil {
+		return nil, errors.New("payment id is required to resolve a line")
+	}
+	statement, err := s.statementRepo.GetByID(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+	var line *domain.BankStatementLine
+	for i := range statement.Lines {
+		if statement.Lines[i].ID == lineID {
+			line = &statement.Lines[i]
+		}
+	}
+	if line == nil {
+		return nil, domain.ErrStatementLineNotFound
+	}
+	switch line.Status {
+	case domain.StatementLineMatched, domain.StatementLineResolved, domain.StatementLineIgnored:
+		return nil, domain.ErrStatementLineResolved
+	}
+
+	now := time.Now()
+	line.Note = strings.TrimSpace(req.Note)
+	line.ResolvedBy = actorFromCtx(ctx)
+	line.ResolvedAt = &now
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if req.Ignore {
+			line.Status = domain.StatementLineIgnored
+		} else {
+			payment, err := s.paymentRepo.GetByID(ctx, *req.PaymentID)
+			if err != nil {
+				return err
+			}
+			matched, err := s.statementRepo.IsPaymentMatched(ctx, payment.ID)
+			if err != nil {
+				return err
+			}
+			if matched {
+				return domain.ErrPaymentAlreadyReconciled
+			}
+			paymentID := payment.ID
+			line.PaymentID = &paymentID
+			line.Difference = roundCents(line.Amount - payment.Amount)
+			line.Status = domain.StatementLineResolved
+			if _, err := s.paymentSvc.UpdateStatus(ctx, payment.ID, domain.PaymentStatusCompleted, ""); err != nil {
+				return err
+			}
+		}
+		if err := s.statementRepo.UpdateLine(ctx, line); err != nil {
+			return err
+		}
+		countLines(statement)
+		return s.statementRepo.UpdateCounts(ctx, statement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
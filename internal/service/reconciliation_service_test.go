@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/bankstatement"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBankStatementRepo struct {
+	mock.Mock
+}
+
+func (m *MockBankStatementRepo) Create(ctx context.Context, statement *domain.BankStatement) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepo) GetByID(ctx context.Context, id uint) (*domain.BankStatement, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BankStatement), args.Error(1)
+}
+
+func (m *MockBankStatementRepo) GetByHash(ctx context.Context, hash string) (*domain.BankStatement, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BankStatement), args.Error(1)
+}
+
+func (m *MockBankStatementRepo) List(ctx context.Context) ([]domain.BankStatement, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.BankStatement), args.Error(1)
+}
+
+func (m *MockBankStatementRepo) UpdateCounts(ctx context.Context, statement *domain.BankStatement) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepo) UpdateLine(ctx context.Context, line *domain.BankStatementLine) error {
+	args := m.Called(ctx, line)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepo) IsPaymentMatched(ctx context.Context, paymentID uint) (bool, error) {
+	args := m.Called(ctx, paymentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func newReconciliationService(ctx context.Context) (*ReconciliationService, *MockBankStatementRepo, *MockPaymentRepo) {
+	statementRepo := new(MockBankStatementRepo)
+	paymentRepo := new(MockPaymentRepo)
+	historyRepo, transitionRepo := newStateRepoMocks(ctx)
+	stateSvc := NewPayrollStateService(&MockTxManager{}, new(MockPayrollRepo), paymentRepo, new(MockEmployeeRepo), new(MockEmployeeBankAccountRepo), historyRepo, transitionRepo, newOutboxRepoMock(), newAccumulatorRepoMock(), newBenefitLedgerRepoMock(), newOpenPeriodRepo())
+	paymentSvc := NewPaymentService(&MockTxManager{}, paymentRepo, stateSvc, nil, "")
+	return NewReconciliationService(&MockTxManager{}, statementRepo, paymentRepo, paymentSvc), statementRepo, paymentRepo
+}
+
+func TestReconciliationService_Import_ClassifiesLines(t *testing.T) {
+	ctx := context.Background()
+	svc, statementRepo, paymentRepo := newReconciliationService(ctx)
+
+	sent := []domain.Payment{
+		{ID: 1, Method: domain.PaymentMethodBankTransfer, AccountNumber: "00123456789", Amount: 1000, Status: domain.PaymentStatusSent, ProviderRef: "NOM1-1"},
+		{ID: 2, Method: domain.PaymentMethodBankTransfer, AccountNumber: "3001234567", Amount: 500, Status: domain.PaymentStatusSent},
+		{ID: 3, Method: domain.PaymentMethodBankTransfer, AccountNumber: "001234567890", Amount: 700, Status: domain.PaymentStatusSent, ProviderRef: "NOM1-3"},
+	}
+	statementRepo.On("GetByHash", ctx, mock.Anything).Return(nil, domain.ErrStatementNotFound)
+	statementRepo.On("Create", ctx, mock.AnythingOfType("*domain.BankStatement")).Return(nil)
+	paymentRepo.On("ListByStatus", ctx, domain.PaymentStatusPending).Return([]domain.Payment{}, nil)
+	paymentRepo.On("ListByStatus", ctx, domain.PaymentStatusSent).Return(sent, nil)
+	paymentRepo.On("GetByProviderRef", ctx, "OLD-9").Return(&domain.Payment{ID: 9, Status: domain.PaymentStatusCompleted}, nil)
+	paymentRepo.On("GetByProviderRef", ctx, "XYZ").Return(nil, domain.ErrPaymentNotFound)
+	paymentRepo.On("GetByID", ctx, uint(1)).Return(&sent[0], nil)
+	paymentRepo.On("GetByID", ctx, uint(2)).Return(&sent[1], nil)
+	paymentRepo.On("LockByID", ctx, uint(1)).Return(&sent[0], nil)
+	paymentRepo.On("LockByID", ctx, uint(2)).Return(&sent[1], nil)
+	statementRepo.On("IsPaymentMatched", ctx, mock.Anything).Return(false, nil)
+	paymentRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+	csv := "date,amount,account,reference,description\n" +
+		"2026-09-30,-1000.00,00123456789,NOM1-1,nomina\n" +
+		"2026-09-30,-500,300-123-4567,,nomina\n" +
+		"2026-09-30,-650,001234567890,NOM1-3,nomina\n" +
+		"2026-09-30,-1000.00,00123456789,NOM1-1,repetido\n" +
+		"2026-09-30,-300,,OLD-9,pagado antes\n" +
+		"2026-09-30,-80,,XYZ,sin pago\n" +
+		"2026-09-30,2000,,,abono\n"
+	statement, err := svc.Import(ctx, bankstatement.FormatCSV, "extracto.csv", []byte(csv))
+
+	assert.NoError(t, err)
+	statuses := make([]string, len(statement.Lines))
+	for i, l := range statement.Lines {
+		statuses[i] = l.Status
+	}
+	assert.Equal(t, []string{
+		domain.StatementLineMatched, domain.StatementLineMatched, domain.StatementLineDifference,
+		domain.StatementLineDuplicate, domain.StatementLineDuplicate, domain.StatementLineUnmatched,
+		domain.StatementLineIgnored,
+	}, statuses)
+	assert.Equal(t, -50.0, statement.Lines[2].Difference)
+	assert.Equal(t, 2, statement.MatchedCount)
+	assert.Equal(t, 4, statement.ExceptionCount)
+	paymentRepo.AssertNumberOfCalls(t, "UpdateStatus", 2)
+	assert.Equal(t, domain.PaymentStatusCompleted, sent[0].Status)
+}
+
+func TestReconciliationService_Import_PaymentReconciledElsewhere(t *testing.T) {
+	ctx := context.Background()
+	svc, statementRepo, paymentRepo := newReconciliationService(ctx)
+
+	sent := domain.Payment{ID: 1, Method: domain.PaymentMethodBankTransfer, AccountNumber: "00123456789", Amount: 1000,
+		Status: domain.PaymentStatusSent, ProviderRef: "NOM1-1"}
+	statementRepo.On("GetByHash", ctx, mock.Anything).Return(nil, domain.ErrStatementNotFound)
+	statementRepo.On("Create", ctx, mock.AnythingOfType("*domain.BankStatement")).Return(nil)
+	paymentRepo.On("ListByStatus", ctx, domain.PaymentStatusPending).Return([]domain.Payment{}, nil)
+	paymentRepo.On("ListByStatus", ctx, domain.PaymentStatusSent).Return([]domain.Payment{sent}, nil)
+	paymentRepo.On("LockByID", ctx, uint(1)).Return(&sent, nil)
+	// Otro extracto importado a la vez ya concilió el pago
+	statementRepo.On("IsPaymentMatched", ctx, uint(1)).Return(true, nil)
+
+	csv := "date,amount,account,reference,description\n" +
+		"2026-09-30,-1000.00,00123456789,NOM1-1,nomina\n"
+	statement, err := svc.Import(ctx, bankstatement.FormatCSV, "extracto.csv", []byte(csv))
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatementLineDuplicate, statement.Lines[0].Status)
+	assert.Equal(t, 0, statement.MatchedCount)
+	assert.Equal(t, 1, statement.ExceptionCount)
+	paymentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestReconciliationService_ResolveLine_ConfirmsPayment(t *testing.T) {
+	ctx := withActor(context.Background(), 7)
+	svc, statementRepo, paymentRepo := newReconciliationService(ctx)
+
+	paymentID := uint(3)
+	statement := &domain.BankStatement{ID: 1, Lines: []domain.BankStatementLine{
+		{ID: 10, Amount: 650, Debit: true, Status: domain.StatementLineDifference, PaymentID: &paymentID, Difference: -50},
+		{ID: 11, Amount: 1000, Debit: true, Status: domain.StatementLineMatched},
+	}}
+	payment := &domain.Payment{ID: 3, Amount: 700, Status: domain.PaymentStatusSent}
+	statementRepo.On("GetByID", ctx, uint(1)).Return(statement, nil)
+	statementRepo.On("IsPaymentMatched", ctx, uint(3)).Return(false, nil)
+	statementRepo.On("UpdateLine", ctx, mock.AnythingOfType("*domain.BankStatementLine")).Return(nil)
+	statementRepo.On("UpdateCounts", ctx, statement).Return(nil)
+	paymentRepo.On("GetByID", ctx, uint(3)).Return(payment, nil)
+	paymentRepo.On("UpdateStatus", ctx, payment).Return(nil)
+
+	_, err := svc.ResolveLine(ctx, 1, 10, ResolveLineRequest{PaymentID: &paymentID})
+	assert.Error(t, err)
+
+	line, err := svc.ResolveLine(ctx, 1, 10, ResolveLineRequest{PaymentID: &paymentID, Note: "bank fee withheld"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatementLineResolved, line.Status)
+	assert.Equal(t, uint(7), line.ResolvedBy)
+	assert.Equal(t, domain.PaymentStatusCompleted, payment.Status)
+	assert.Equal(t, 2, statement.MatchedCount)
+	assert.Equal(t, 0, statement.ExceptionCount)
+
+	_, err = svc.ResolveLine(ctx, 1, 11, ResolveLineRequest{Ignore: true, Note: "x"})
+	assert.ErrorIs(t, err, domain.ErrStatementLineResolved)
+}
+
+func TestParseCamt053(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>STMT-0930</Id>
+      <Acct><Id><Othr><Id>00999999999</Id></Othr></Id></Acct>
+      <Ntry>
+        <Amt Ccy="COP">1000.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2026-09-30</Dt></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOM1-1</EndToEndId></Refs>
+          <RltdPties><CdtrAcct><Id><Othr><Id>00123456789</Id></Othr></Id></CdtrAcct></RltdPties>
+        </TxDtls></NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+	st, err := bankstatement.Parse(bankstatement.FormatCamt053, []byte(xml))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "STMT-0930", st.Reference)
+	assert.Equal(t, "00999999999", st.AccountNumber)
+	assert.Len(t, st.Lines, 1)
+	assert.True(t, st.Lines[0].Debit)
+	assert.Equal(t, 1000.0, st.Lines[0].Amount)
+	assert.Equal(t, "NOM1-1", st.Lines[0].Reference)
+	assert.Equal(t, "00123456789", st.Lines[0].Account)
+}
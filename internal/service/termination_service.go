@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// TerminationService termina el contrato de un empleado y genera su liquidación final
+type TerminationService struct {
+	txManager       domain.TxManager
+	employeeRepo    domain.EmployeeRepo
+	contractRepo    domain.EmployeeContractRepo
+	payrollRepo     domain.PayrollRepo
+	payrollItemRepo domain.PayrollItemRepo
+	conceptRepo     domain.PayrollConceptRepo
+	ledgerRepo      domain.BenefitLedgerRepo
+	terminationRepo domain.EmployeeTerminationRepo
+	periodRepo      domain.AccountingPeriodRepo
+	calculator      *PayrollCalculatorService
+	benefitSvc      *BenefitService
+}
+
+func NewTerminationService(
+	txManager domain.TxManager,
+	employeeRepo domain.EmployeeRepo,
+	contractRepo domain.EmployeeContractRepo,
+	payrollRepo domain.PayrollRepo,
+	payrollItemRepo domain.PayrollItemRepo,
+	conceptRepo domain.PayrollConceptRepo,
+	ledgerRepo domain.BenefitLedgerRepo,
+	terminationRepo domain.EmployeeTerminationRepo,
+	periodRepo domain.AccountingPeriodRepo,
+	calculator *PayrollCalculatorService,
+	benefitSvc *BenefitService,
+) *TerminationService {
+	return &TerminationService{
+		txManager:       txManager,
+		employeeRepo:    employeeRepo,
+		contractRepo:    contractRepo,
+		payrollRepo:     payrollRepo,
+		payrollItemRepo: payrollItemRepo,
+		conceptRepo:     conceptRepo,
+		ledgerRepo:      ledgerRepo,
+		terminationRepo: terminationRepo,
+		periodRepo:      periodRepo,
+		calculator:      calculator,
+		benefitSvc:      benefitSvc,
+	}
+}
+
+// TerminateRequest representa la solicitud de terminación de contrato
+type TerminateRequest struct {
+	EmployeeID uint
+	// ContractID es opcional; si se indica debe ser el contrato activo del empleado
+	ContractID      uint
+	TerminationDate time.Time
+	Reason          string
+	// JustCause indica terminación con justa causa; en ese caso no hay indemnización
+	JustCause bool
+	PayDate   time.Time
+}
+
+// SettlementDocument es la liquidación final que el empleado firma
+type SettlementDocument struct {
+	Termination *domain.EmployeeTermination
+	Settlement  *domain.Payroll
+}
+
+// settlementPayouts relaciona cada prestación del ledger con el item que la liquida
+var settlementPayouts = []benefitPayout{
+	{benefit: domain.BenefitPrima, code: domain.ConceptPrimaPayment, name: "Prima de Servicios Proporcional"},
+	{benefit: domain.BenefitSeverance, code: domain.ConceptSeverancePayment, name: "Cesantías"},
+	{benefit: domain.BenefitSeveranceInterest, code: domain.ConceptSeveranceInterestPayment, name: "Intereses sobre Cesantías"},
+	{benefit: domain.BenefitVacation, code: domain.ConceptVacationPayment, name: "Vacaciones no Disfrutadas"},
+}
+
+// Terminate cierra el contrato activo, desactiva al empleado y genera la nómina de liquidación:
+// salario pendiente desde el último periodo pagado, prestaciones causadas (ledger más la
+// provisión de los días pendientes) e indemnización cuando no hay justa causa. Los tipos de
+// contrato sin prestaciones solo liquidan el salario pendiente y las vacaciones.
+func (s *TerminationService) Terminate(ctx context.Context, req TerminateRequest) (*SettlementDocument, error) {
+	if req.EmployeeID == 0 {
+		return nil, errors.New("employee id is required")
+	}
+	if req.TerminationDate.IsZero() {
+		return nil, errors.New("termination date is required")
+	}
+	if req.Reason == "" {
+		return nil, errors.New("termination reason is required")
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !employee.IsActive {
+		return nil, domain.ErrEmployeeTerminated
+	}
+	contract, err := s.contractRepo.GetActiveByEmployee(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ContractID != 0 && req.ContractID != contract.ID {
+		return nil, domain.ErrContractNotActive
+	}
+	if req.TerminationDate.Before(contract.StartDate) {
+		return nil, domain.ErrInvalidTerminationDate
+	}
+	if err := ensurePeriodOpen(ctx, s.periodRepo, req.TerminationDate, req.TerminationDate); err != nil {
+		return nil, err
+	}
+
+	payrolls, err := s.payrollRepo.ListByEmployee(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	pendingStart, err := pendingSalaryStart(payrolls, contract.StartDate, req.TerminationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Las nóminas pagadas aún no causadas deben estar en el ledger antes de liquidar
+	if _, err := s.benefitSvc.AccrueEmployee(ctx, req.EmployeeID); err != nil {
+		return nil, err
+	}
+	entries, err := s.ledgerRepo.ListByEmployee(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]float64)
+	for _, e := range entries {
+		balances[e.Benefit] += e.Amount
+	}
+
+	payDate := req.PayDate
+	if payDate.IsZero() {
+		payDate = req.TerminationDate
+	}
+
+	settlement := &domain.Payroll{
+		EmployeeID:   req.EmployeeID,
+		PeriodStart:  pendingStart,
+		PeriodEnd:    req.TerminationDate,
+		PayDate:      payDate,
+		Status:       domain.PayrollStatusCalculated,
+		Kind:         domain.PayrollKindSettlement,
+		CalculatedBy: actorFromCtx(ctx),
+	}
+	var items []domain.PayrollItem
+
+	// Salario pendiente: días desde el último periodo pagado hasta la terminación
+	if !pendingStart.After(req.TerminationDate) {
+		concepts, err := s.conceptRepo.GetActiveConcepts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		absences, err := s.calculator.absenceRepo.ListByEmployee(ctx, req.EmployeeID)
+		if err != nil {
+			return nil, err
+		}
+		pending := s.calculator.buildPayroll(employee, contract, concepts, absences, CalculatePayrollRequest{
+			EmployeeID:  req.EmployeeID,
+			PeriodStart: pendingStart,
+			PeriodEnd:   req.TerminationDate,
+			PayDate:     payDate,
+		})
+		items = append(items, pending.Items...)
+	} else {
+		settlement.PeriodStart = req.TerminationDate
+	}
+
+	// Provisiones de los días pendientes: se causan y se pagan en la misma liquidación
+	provisions := domain.BenefitProvisionConcepts()
+	pendingProvision := make(map[string]float64)
+	for _, item := range items {
+		if benefit, ok := provisions[item.Code]; ok {
+			pendingProvision[benefit] += item.Amount
+		}
+	}
+
+	var ledgerEntries []domain.BenefitLedgerEntry
+	for _, payout := range settlementPayouts {
+		// Aprendizaje y prestación de servicios no liquidan prima ni cesantías
+		if contract.ContractType.NoSocialBenefits && isSocialBenefit(payout.benefit) {
+			continue
+		}
+		if amount := roundCents(pendingProvision[payout.benefit]); amount != 0 {
+			ledgerEntries = append(ledgerEntries, domain.BenefitLedgerEntry{
+				EmployeeID:  req.EmployeeID,
+				Benefit:     payout.benefit,
+				EntryType:   domain.BenefitEntryAccrual,
+				Amount:      amount,
+				PeriodStart: settlement.PeriodStart,
+				PeriodEnd:   settlement.PeriodEnd,
+			})
+		}
+		amount := roundCents(balances[payout.benefit] + pendingProvision[payout.benefit])
+		if amount <= 0 {
+			continue
+		}
+		items = append(items, domain.PayrollItem{
+			Type:         domain.PayrollTypeEarning,
+			Code:         payout.code,
+			Name:         payout.name,
+			Amount:       amount,
+			CalculatedAt: time.Now(),
+		})
+		ledgerEntries = append(ledgerEntries, domain.BenefitLedgerEntry{
+			EmployeeID:  req.EmployeeID,
+			Benefit:     payout.benefit,
+			EntryType:   domain.BenefitEntryPayment,
+			Amount:      -amount,
+			PeriodStart: settlement.PeriodStart,
+			PeriodEnd:   settlement.PeriodEnd,
+		})
+	}
+
+	indemnityDays := 0.0
+	if !req.JustCause && !contract.ContractType.NoSocialBenefits {
+		indemnityDays, err = indemnificationDays(contract, req.TerminationDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if indemnityDays > 0 {
+		items = append(items, domain.PayrollItem{
+			Type:         domain.PayrollTypeEarning,
+			Code:         domain.ConceptIndemnification,
+			Name:         "Indemnización por Terminación sin Justa Causa",
+			Amount:       roundCents(contract.BaseSalary / 30 * indemnityDays),
+			CalculatedAt: time.Now(),
+		})
+	}
+
+	// La liquidación es costo del periodo pendiente: se reparte entre los centros de costo
+	if err := s.calculator.allocateCosts(ctx, req.EmployeeID, settlement.PeriodStart, settlement.PeriodEnd, items); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		switch item.Type {
+		case domain.PayrollTypeEarning:
+			settlement.GrossAmount += item.Amount
+		case domain.PayrollTypeDeduction:
+			settlement.TotalDeductions += item.Amount
+		}
+	}
+	settlement.GrossAmount = roundCents(settlement.GrossAmount)
+	settlement.TotalDeductions = roundCents(settlement.TotalDeductions)
+	settlement.NetAmount = roundCents(settlement.GrossAmount - settlement.TotalDeductions)
+
+	termination := &domain.EmployeeTermination{
+		EmployeeID:          req.EmployeeID,
+		ContractID:          contract.ID,
+		TerminationDate:     req.TerminationDate,
+		Reason:              req.Reason,
+		JustCause:           req.JustCause,
+		DaysWorked:          daysBetween(contract.StartDate, req.TerminationDate),
+		IndemnificationDays: indemnityDays,
+		CreatedBy:           actorFromCtx(ctx),
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		endDate := req.TerminationDate
+		contract.EndDate = &endDate
+		contract.IsActive = false
+		if err := s.contractRepo.Update(ctx, contract); err != nil {
+			return err
+		}
+		employee.IsActive = false
+		if err := s.employeeRepo.Update(ctx, employee); err != nil {
+			return err
+		}
+
+		if err := s.payrollRepo.Create(ctx, settlement); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].PayrollID = settlement.ID
+		}
+		if err := s.payrollItemRepo.CreateBatch(ctx, items); err != nil {
+			return err
+		}
+		for i := range ledgerEntries {
+			ledgerEntries[i].PayrollID = settlement.ID
+		}
+		if err := s.ledgerRepo.CreateBatch(ctx, ledgerEntries); err != nil {
+			return err
+		}
+
+		termination.SettlementPayrollID = settlement.ID
+		return s.terminationRepo.Create(ctx, termination)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settlement.Items = items
+	return &SettlementDocument{Termination: termination, Settlement: settlement}, nil
+}
+
+// GetSettlement retorna el documento de liquidación del empleado
+func (s *TerminationService) GetSettlement(ctx context.Context, employeeID uint) (*SettlementDocument, error) {
+	if employeeID == 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	termination, err := s.terminationRepo.GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	settlement, err := s.payrollRepo.GetByID(ctx, termination.SettlementPayrollID)
+	if err != nil {
+		return nil, err
+	}
+	return &SettlementDocument{Termination: termination, Settlement: settlement}, nil
+}
+
+// Sign registra la firma de la liquidación. Solo el propio empleado puede firmarla.
+func (s *TerminationService) Sign(ctx context.Context, employeeID uint) (*domain.EmployeeTermination, error) {
+	actor := actorFromCtx(ctx)
+	if actor == 0 {
+		return nil, domain.ErrActorRequired
+	}
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if employee.UserID != actor {
+		return nil, domain.ErrPermissionDenied
+	}
+	termination, err := s.terminationRepo.GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if termination.SignedAt != nil {
+		return nil, domain.ErrSettlementAlreadySigned
+	}
+	now := time.Now()
+	termination.SignedAt = &now
+	if err := s.terminationRepo.Update(ctx, termination); err != nil {
+		return nil, err
+	}
+	return termination, nil
+}
+
+// pendingSalaryStart retorna el primer día no cubierto por una nómina pagada. Sin nóminas
+// pagadas el salario pendiente empieza en el inicio del contrato, limitado al mes de la
+// terminación. Falla si hay nóminas abiertas (incluidas las pagadas parcialmente y los
+// reemplazos) que cubren días hasta la terminación, para no pagarlos dos veces.
+func pendingSalaryStart(payrolls []domain.Payroll, contractStart, terminationDate time.Time) (time.Time, error) {
+	start := contractStart
+	if monthStart := time.Date(terminationDate.Year(), terminationDate.Month(), 1, 0, 0, 0, 0, terminationDate.Location()); monthStart.After(start) {
+		start = monthStart
+	}
+	paid := false
+	for _, p := range payrolls {
+		if !isSalaryPayroll(p) {
+			continue
+		}
+		if p.Status == domain.PayrollStatusPaid {
+			if next := p.PeriodEnd.AddDate(0, 0, 1); !paid || next.After(start) {
+				start = next
+				paid = true
+			}
+		}
+	}
+	for _, p := range payrolls {
+		if !isSalaryPayroll(p) {
+			continue
+		}
+		switch p.Status {
+		case domain.PayrollStatusDraft, domain.PayrollStatusCalculated, domain.PayrollStatusApproved,
+			domain.PayrollStatusPartiallyPaid:
+			if !p.PeriodEnd.Before(start) && !p.PeriodStart.After(terminationDate) {
+				return time.Time{}, domain.ErrOpenPayrollPending
+			}
+		}
+	}
+	return start, nil
+}
+
+// isSalaryPayroll indica si la nómina liquida salario del periodo (regular o reemplazo)
+func isSalaryPayroll(p domain.Payroll) bool {
+	return p.Kind == "" || p.Kind == domain.PayrollKindRegular || p.Kind == domain.PayrollKindReplacement
+}
+
+// minimumWageByYear es el salario mínimo mensual legal vigente por año
+var minimumWageByYear = map[int]float64{
+	2023: 1160000,
+	2024: 1300000,
+	2025: 1423500,
+	2026: 1750905,
+}
+
+// fixedTermMinimumIndemnificationDays es el mínimo de días de indemnización a término fijo
+const fixedTermMinimumIndemnificationDays = 15.0
+
+func minimumWage(year int) float64 {
+	if wage, ok := minimumWageByYear[year]; ok {
+		return wage
+	}
+	latestYear := 0
+	for y := range minimumWageByYear {
+		if y > latestYear {
+			latestYear = y
+		}
+	}
+	return minimumWageByYear[latestYear]
+}
+
+// indemnificationDays calcula los días de salario de indemnización sin justa causa (art. 64 CST).
+// El tipo de contrato define si es a término fijo: los días que faltan para el vencimiento, con
+// un mínimo de 15; si termina en el vencimiento o después no hay indemnización. Término
+// indefinido: 30 días el primer año y 20 por cada año adicional (20 y 15 si el salario es de
+// 10 SMMLV o más), proporcional.
+func indemnificationDays(contract *domain.EmployeeContract, terminationDate time.Time) (float64, error) {
+	if contract.ContractType.RequiresEndDate {
+		if contract.EndDate == nil {
+			return 0, domain.ErrContractEndDateRequired
+		}
+		if !contract.EndDate.After(terminationDate) {
+			return 0, nil
+		}
+		return max(float64(daysBetween(terminationDate, *contract.EndDate)-1), fixedTermMinimumIndemnificationDays), nil
+	}
+
+	firstYear, additional := 30.0, 20.0
+	if contract.BaseSalary >= 10*minimumWage(terminationDate.Year()) {
+		firstYear, additional = 20.0, 15.0
+	}
+	years := float64(daysBetween(contract.StartDate, terminationDate)) / 360
+	if years <= 1 {
+		return firstYear, nil
+	}
+	return roundCents(firstYear + (years-1)*additional), nil
+}
+
+// daysBetween cuenta los días calendario entre dos fechas, ambas incluidas
+func daysBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours()/24) + 1
+}
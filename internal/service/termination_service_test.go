@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmployeeTerminationRepo struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeTerminationRepo) Create(ctx context.Context, termination *domain.EmployeeTermination) error {
+	args := m.Called(ctx, termination)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeTerminationRepo) GetByEmployee(ctx context.Context, employeeID uint) (*domain.EmployeeTermination, error) {
+	args := m.Called(ctx, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmployeeTermination), args.Error(1)
+}
+
+func (m *MockEmployeeTerminationRepo) Update(ctx context.Context, termination *domain.EmployeeTermination) error {
+	args := m.Called(ctx, termination)
+	return args.Error(0)
+}
+
+type terminationMocks struct {
+	employeeRepo    *MockEmployeeRepo
+	contractRepo    *MockContractRepo
+	payrollRepo     *MockPayrollRepo
+	payrollItemRepo *MockPayrollItemRepo
+	conceptRepo     *MockConceptRepo
+	ledgerRepo      *MockBenefitLedgerRepo
+	terminationRepo *MockEmployeeTerminationRepo
+}
+
+func newTerminationService() (*TerminationService, *terminationMocks) {
+	m := &terminationMocks{
+		employeeRepo:    new(MockEmployeeRepo),
+		contractRepo:    new(MockContractRepo),
+		payrollRepo:     new(MockPayrollRepo),
+		payrollItemRepo: new(MockPayrollItemRepo),
+		conceptRepo:     new(MockConceptRepo),
+		ledgerRepo:      new(MockBenefitLedgerRepo),
+		terminationRepo: new(MockEmployeeTerminationRepo),
+	}
+	periodRepo := newOpenPeriodRepo()
+	benefitSvc := NewBenefitService(&MockTxManager{}, m.payrollRepo, m.payrollItemRepo, m.ledgerRepo, periodRepo)
+	svc := NewTerminationService(&MockTxManager{}, m.employeeRepo, m.contractRepo, m.payrollRepo, m.payrollItemRepo,
+		m.conceptRepo, m.ledgerRepo, m.terminationRepo, periodRepo, &PayrollCalculatorService{allocationRepo: newCostAllocationRepoMock(), absenceRepo: newAbsenceRepoMock()}, benefitSvc)
+	return svc, m
+}
+
+func TestTerminationService_Terminate_WithoutJustCause(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newTerminationService()
+
+	employee := &domain.Employee{ID: 1, TenantID: 1, IsActive: true}
+	contract := &domain.EmployeeContract{ID: 5, EmployeeID: 1, BaseSalary: 3000000, IsActive: true,
+		StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	january := domain.Payroll{ID: 10, EmployeeID: 1, Status: domain.PayrollStatusPaid, Kind: domain.PayrollKindRegular,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)}
+
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(employee, nil)
+	m.employeeRepo.On("Update", ctx, employee).Return(nil)
+	m.contractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(contract, nil)
+	m.contractRepo.On("Update", ctx, contract).Return(nil)
+	m.payrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+	m.payrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	m.payrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	m.conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptPrimaProvision, Name: "Provisión Prima", Type: domain.PayrollTypeEmployerContribution, Percentage: 8.33},
+	}, nil)
+	m.ledgerRepo.On("ExistsForPayroll", ctx, uint(10)).Return(true, nil)
+	m.ledgerRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.BenefitLedgerEntry{
+		{Benefit: domain.BenefitPrima, EntryType: domain.BenefitEntryAccrual, Amount: 500000},
+	}, nil)
+	m.ledgerRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.BenefitLedgerEntry")).Return(nil)
+	m.terminationRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeTermination")).Return(nil)
+
+	doc, err := svc.Terminate(ctx, TerminateRequest{
+		EmployeeID:      1,
+		TerminationDate: time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC),
+		Reason:          "restructuring",
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, employee.IsActive)
+	assert.False(t, contract.IsActive)
+	assert.Equal(t, time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC), *contract.EndDate)
+	assert.Equal(t, domain.PayrollKindSettlement, doc.Settlement.Kind)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), doc.Settlement.PeriodStart)
+
+	amounts := make(map[string]float64)
+	for _, item := range doc.Settlement.Items {
+		amounts[item.Code] = item.Amount
+	}
+	assert.Equal(t, float64(1500000), amounts[domain.ConceptBaseSalary])
+	assert.Equal(t, float64(624950), amounts[domain.ConceptPrimaPayment])
+	assert.Greater(t, doc.Termination.IndemnificationDays, 30.0)
+	assert.Equal(t, roundCents(100000*doc.Termination.IndemnificationDays), amounts[domain.ConceptIndemnification])
+}
+
+func TestTerminationService_Terminate_ServiceContractHasNoBenefits(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newTerminationService()
+
+	employee := &domain.Employee{ID: 1, TenantID: 1, IsActive: true}
+	contract := &domain.EmployeeContract{ID: 5, EmployeeID: 1, BaseSalary: 3000000, IsActive: true,
+		StartDate:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		ContractType: domain.ContractType{Code: domain.ContractTypeService, NoDeductions: true, NoSocialBenefits: true}}
+	january := domain.Payroll{ID: 10, EmployeeID: 1, Status: domain.PayrollStatusPaid, Kind: domain.PayrollKindRegular,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)}
+
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(employee, nil)
+	m.employeeRepo.On("Update", ctx, employee).Return(nil)
+	m.contractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(contract, nil)
+	m.contractRepo.On("Update", ctx, contract).Return(nil)
+	m.payrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{january}, nil)
+	m.payrollRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payroll")).Return(nil)
+	m.payrollItemRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.PayrollItem")).Return(nil)
+	m.conceptRepo.On("GetActiveConcepts", ctx).Return([]domain.PayrollConcept{
+		{ID: 1, Code: domain.ConceptBaseSalary, Name: "Salario Base", Type: domain.PayrollTypeEarning, Percentage: 100},
+		{ID: 2, Code: domain.ConceptPrimaProvision, Name: "Provisión Prima", Type: domain.PayrollTypeEmployerContribution, Percentage: 8.33},
+	}, nil)
+	m.ledgerRepo.On("ExistsForPayroll", ctx, uint(10)).Return(true, nil)
+	m.ledgerRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.BenefitLedgerEntry{
+		{Benefit: domain.BenefitPrima, EntryType: domain.BenefitEntryAccrual, Amount: 500000},
+	}, nil)
+	m.ledgerRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]domain.BenefitLedgerEntry")).Return(nil)
+	m.terminationRepo.On("Create", ctx, mock.AnythingOfType("*domain.EmployeeTermination")).Return(nil)
+
+	doc, err := svc.Terminate(ctx, TerminateRequest{
+		EmployeeID:      1,
+		TerminationDate: time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC),
+		Reason:          "restructuring",
+	})
+
+	assert.NoError(t, err)
+	codes := make(map[string]bool)
+	for _, item := range doc.Settlement.Items {
+		codes[item.Code] = true
+	}
+	assert.True(t, codes[domain.ConceptBaseSalary])
+	assert.False(t, codes[domain.ConceptPrimaProvision])
+	assert.False(t, codes[domain.ConceptPrimaPayment])
+	assert.False(t, codes[domain.ConceptIndemnification])
+	assert.Zero(t, doc.Termination.IndemnificationDays)
+}
+
+func TestTerminationService_Terminate_OpenPayrollPending(t *testing.T) {
+	ctx := context.Background()
+	svc, m := newTerminationService()
+
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, IsActive: true}, nil)
+	m.contractRepo.On("GetActiveByEmployee", ctx, uint(1)).Return(&domain.EmployeeContract{ID: 5, BaseSalary: 3000000,
+		StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
+	m.payrollRepo.On("ListByEmployee", ctx, uint(1)).Return([]domain.Payroll{
+		{ID: 11, Status: domain.PayrollStatusCalculated, Kind: domain.PayrollKindRegular,
+			PeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	_, err := svc.Terminate(ctx, TerminateRequest{
+		EmployeeID:      1,
+		TerminationDate: time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC),
+		Reason:          "resignation",
+		JustCause:       true,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrOpenPayrollPending)
+	m.contractRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPendingSalaryStart_PartiallyPaidIsOpen(t *testing.T) {
+	payrolls := []domain.Payroll{
+		{ID: 10, Status: domain.PayrollStatusPaid, Kind: domain.PayrollKindRegular,
+			PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{ID: 11, Status: domain.PayrollStatusPartiallyPaid, Kind: domain.PayrollKindRegular,
+			PeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	_, err := pendingSalaryStart(payrolls, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC))
+
+	assert.ErrorIs(t, err, domain.ErrOpenPayrollPending)
+}
+
+func TestIndemnificationDays_FixedTerm(t *testing.T) {
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	fixedTerm := domain.ContractType{Code: domain.ContractTypeFixedTerm, RequiresEndDate: true}
+	tests := []struct {
+		name        string
+		endDate     *time.Time
+		termination time.Time
+		want        float64
+		wantErr     error
+	}{
+		{name: "remaining days", endDate: &end, termination: time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), want: 30},
+		// Nunca menos de 15 días aunque falten menos para el vencimiento
+		{name: "day before expiry", endDate: &end, termination: time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC), want: 15},
+		{name: "expiry day", endDate: &end, termination: end, want: 0},
+		{name: "day after expiry", endDate: &end, termination: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), want: 0},
+		{name: "missing end date", termination: time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), wantErr: domain.ErrContractEndDateRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &domain.EmployeeContract{BaseSalary: 3000000, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate: tt.endDate, ContractType: fixedTerm}
+
+			days, err := indemnificationDays(contract, tt.termination)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, days)
+		})
+	}
+}
+
+func TestIndemnificationDays_IndefiniteTypeIgnoresEndDate(t *testing.T) {
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	contract := &domain.EmployeeContract{BaseSalary: 3000000, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &end,
+		ContractType: domain.ContractType{Code: domain.ContractTypeIndefinite}}
+
+	days, err := indemnificationDays(contract, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(30), days)
+}
+
+func TestPendingSalaryStart_WithoutPaidPayrollStartsInTerminationMonth(t *testing.T) {
+	start, err := pendingSalaryStart(nil, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), start)
+}
+
+func TestPendingSalaryStart_OpenReplacementIsPending(t *testing.T) {
+	original := uint(10)
+	payrolls := []domain.Payroll{
+		{ID: 10, Status: domain.PayrollStatusReversed, Kind: domain.PayrollKindRegular,
+			PeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{ID: 12, Status: domain.PayrollStatusApproved, Kind: domain.PayrollKindReplacement, OriginalPayrollID: &original,
+			PeriodStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	_, err := pendingSalaryStart(payrolls, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC))
+
+	assert.ErrorIs(t, err, domain.ErrOpenPayrollPending)
+}
+
+func TestTerminationService_Sign_OnlyEmployee(t *testing.T) {
+	ctx := withActor(context.Background(), 99)
+	svc, m := newTerminationService()
+
+	m.employeeRepo.On("GetByID", ctx, uint(1)).Return(&domain.Employee{ID: 1, UserID: 7}, nil)
+
+	_, err := svc.Sign(ctx, 1)
+
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	m.terminationRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// uvtByYear es el valor de la Unidad de Valor Tributario por año
+var uvtByYear = map[int]float64{
+	2023: 42412,
+	2024: 47065,
+	2025: 49799,
+	2026: 52374,
+}
+
+func uvt(year int) float64 {
+	if value, ok := uvtByYear[year]; ok {
+		return value
+	}
+	latestYear := 0
+	for y := range uvtByYear {
+		if y > latestYear {
+			latestYear = y
+		}
+	}
+	return uvtByYear[latestYear]
+}
+
+// Renta exenta del 25% (art. 206 num. 10 ET) con tope anual de 790 UVT
+const (
+	exemptIncomeRate      = 0.25
+	exemptIncomeAnnualUVT = 790.0
+)
+
+// withholdingTable son los rangos en UVT de la tabla del art. 383 ET: desde, tarifa marginal
+// y UVT fijas del rango
+var withholdingTable = []struct{ from, rate, fixed float64 }{
+	{2300, 0.39, 770},
+	{945, 0.37, 268},
+	{640, 0.35, 162},
+	{360, 0.33, 69},
+	{150, 0.28, 10},
+	{95, 0.19, 0},
+}
+
+// untaxedEarnings son los pagos que no entran al procedimiento 1: cesantías, sus intereses e
+// indemnizaciones tienen tratamiento propio
+var untaxedEarnings = []string{
+	domain.ConceptSeverancePayment, domain.ConceptSeveranceInterestPayment, domain.ConceptIndemnification,
+}
+
+// Withholding es la depuración de la retención en la fuente por salarios de un periodo
+type Withholding struct {
+	Income     float64 // pagos laborales gravables
+	NonTaxable float64 // aportes obligatorios a salud y pensión
+	Exempt     float64 // renta exenta del 25% aplicada
+	Base       float64 // base gravable
+	Amount     float64 // retención, redondeada al múltiplo de mil
+}
+
+// taxableSubtotal resta a los pagos laborales los ingresos no constitutivos de renta
+func taxableSubtotal(items []domain.PayrollItem) (income, nonTaxable float64) {
+	for _, item := range items {
+		code := strings.TrimPrefix(item.Code, domain.RetroConceptPrefix)
+		switch {
+		case item.Type == domain.PayrollTypeEarning && !slices.Contains(untaxedEarnings, code):
+			income += item.Amount
+		case item.Type == domain.PayrollTypeDeduction && (code == domain.ConceptHealth || code == domain.ConceptPension):
+			nonTaxable += item.Amount
+		}
+	}
+	return income, nonTaxable
+}
+
+// periodExemptIncome es la renta exenta del periodo: 25% del subtotal con tope mensual de
+// 790/12 UVT proporcional a los días, sin considerar aún el tope anual
+func periodExemptIncome(items []domain.PayrollItem, year, days int) float64 {
+	income, nonTaxable := taxableSubtotal(items)
+	subtotal := income - nonTaxable
+	limit := exemptIncomeAnnualUVT / 12 * uvt(year) * float64(days) / 30
+	exempt := math.Min(math.Abs(subtotal)*exemptIncomeRate, limit)
+	if subtotal < 0 {
+		return -roundCents(exempt)
+	}
+	return roundCents(exempt)
+}
+
+// computeWithholding liquida la retención del periodo por el procedimiento 1 (art. 383 ET).
+// ytdExempt es la renta exenta ya aplicada en el año según los acumulados; los rangos de la
+// tabla se ajustan a los días del periodo.
+func computeWithholding(items []domain.PayrollItem, year, days int, ytdExempt float64) Withholding {
+	income, nonTaxable := taxableSubtotal(items)
+	w := Withholding{Income: roundCents(income), NonTaxable: roundCents(nonTaxable)}
+	subtotal := income - nonTaxable
+	if subtotal <= 0 || days <= 0 {
+		return w
+	}
+	value := uvt(year)
+	remaining := math.Max(0, exemptIncomeAnnualUVT*value-ytdExempt)
+	w.Exempt = math.Min(periodExemptIncome(items, year, days), remaining)
+	w.Base = roundCents(subtotal - w.Exempt)
+
+	factor := float64(days) / 30
+	baseUVT := w.Base / value / factor
+	for _, r := range withholdingTable {
+		if baseUVT > r.from {
+			tax := ((baseUVT-r.from)*r.rate + r.fixed) * factor * value
+			w.Amount = math.Round(tax/1000) * 1000
+			break
+		}
+	}
+	return w
+}
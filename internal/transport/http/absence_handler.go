@@ -0,0 +1,101 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// AbsenceHandler maneja vacaciones, licencias e incapacidades de un empleado
+type AbsenceHandler struct {
+	svc *service.AbsenceService
+}
+
+func NewAbsenceHandler(svc *service.AbsenceService) *AbsenceHandler {
+	return &AbsenceHandler{svc: svc}
+}
+
+// List lista las ausencias del empleado
+// GET /api/v1/employees/:id/absences
+func (h *AbsenceHandler) List(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	absences, err := h.svc.List(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(absenceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.AbsenceResponse, len(absences))
+	for i := range absences {
+		resp[i] = dto.ToAbsenceResponse(&absences[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"absences": resp})
+}
+
+// Create registra una ausencia del empleado
+// POST /api/v1/employees/:id/absences
+func (h *AbsenceHandler) Create(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	var req dto.CreateAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start, err := parseDate(req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format, use YYYY-MM-DD"})
+		return
+	}
+	end, err := parseDate(req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format, use YYYY-MM-DD"})
+		return
+	}
+	absence := req.ToDomain(start, end)
+	if err := h.svc.Create(c.Request.Context(), employeeID, absence); err != nil {
+		c.JSON(absenceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToAbsenceResponse(absence))
+}
+
+// Delete elimina una ausencia
+// DELETE /api/v1/employees/:id/absences/:absenceId
+func (h *AbsenceHandler) Delete(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	absenceID, err := strconv.ParseUint(c.Param("absenceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid absence id"})
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), employeeID, uint(absenceID)); err != nil {
+		c.JSON(absenceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func absenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrAbsenceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidAbsenceKind), errors.Is(err, domain.ErrInvalidAbsenceDate):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrAbsenceOverlap):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
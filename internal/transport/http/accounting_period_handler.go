@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountingPeriodHandler struct {
+	svc *service.AccountingPeriodService
+}
+
+func NewAccountingPeriodHandler(svc *service.AccountingPeriodService) *AccountingPeriodHandler {
+	return &AccountingPeriodHandler{svc}
+}
+
+type CreateAccountingPeriodRequest struct {
+	Name        string `json:"name" binding:"max=50"`
+	PeriodStart string `json:"period_start" binding:"required"`
+	PeriodEnd   string `json:"period_end" binding:"required"`
+}
+
+// Create crea un periodo contable abierto
+// POST /api/v1/accounting-periods
+func (h *AccountingPeriodHandler) Create(c *gin.Context) {
+	var req CreateAccountingPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	periodStart, err := parseDate(req.PeriodStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_start format, use YYYY-MM-DD"})
+		return
+	}
+	periodEnd, err := parseDate(req.PeriodEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_end format, use YYYY-MM-DD"})
+		return
+	}
+
+	period := &domain.AccountingPeriod{
+		Name:        req.Name,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	}
+	if err := h.svc.Create(c.Request.Context(), period); err != nil {
+		c.JSON(periodErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "accounting period created",
+		"period":  period,
+	})
+}
+
+// List lista los periodos contables del tenant
+// GET /api/v1/accounting-periods
+func (h *AccountingPeriodHandler) List(c *gin.Context) {
+	periods, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if periods == nil {
+		periods = []domain.AccountingPeriod{}
+	}
+	c.JSON(http.StatusOK, gin.H{"periods": periods})
+}
+
+// GetByID obtiene un periodo contable
+// GET /api/v1/accounting-periods/:id
+func (h *AccountingPeriodHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	period, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(periodErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, period)
+}
+
+// Close cierra un periodo contable
+// POST /api/v1/accounting-periods/:id/close
+func (h *AccountingPeriodHandler) Close(c *gin.Context) {
+	h.changeStatus(c, h.svc.Close, "accounting period closed")
+}
+
+// Lock bloquea definitivamente un periodo contable
+// POST /api/v1/accounting-periods/:id/lock
+func (h *AccountingPeriodHandler) Lock(c *gin.Context) {
+	h.changeStatus(c, h.svc.Lock, "accounting period locked")
+}
+
+// Reopen reabre un periodo cerrado (requiere permiso accounting_periods.reopen)
+// POST /api/v1/accounting-periods/:id/reopen
+func (h *AccountingPeriodHandler) Reopen(c *gin.Context) {
+	h.changeStatus(c, h.svc.Reopen, "accounting period reopened")
+}
+
+func (h *AccountingPeriodHandler) changeStatus(
+	c *gin.Context,
+	op func(ctx context.Context, id uint) (*domain.AccountingPeriod, error),
+	message string,
+) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	period, err := op(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(periodErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"period":  period,
+	})
+}
+
+func periodErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPeriodNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPeriodOverlap),
+		errors.Is(err, domain.ErrPeriodLocked),
+		errors.Is(err, service.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrActorRequired):
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// AccumulatorHandler expone los acumulados anuales y el certificado de ingresos y retenciones
+type AccumulatorHandler struct {
+	svc *service.AccumulatorService
+}
+
+func NewAccumulatorHandler(svc *service.AccumulatorService) *AccumulatorHandler {
+	return &AccumulatorHandler{svc: svc}
+}
+
+// List retorna los acumulados del empleado en el año (por defecto el actual)
+// GET /api/v1/employees/:id/accumulators?year=2026
+func (h *AccumulatorHandler) List(c *gin.Context) {
+	employeeID, year, ok := accumulatorParams(c, time.Now().Year())
+	if !ok {
+		return
+	}
+	accumulators, err := h.svc.ListByEmployee(c.Request.Context(), employeeID, year)
+	if err != nil {
+		c.JSON(accumulatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.AccumulatorResponse, len(accumulators))
+	for i := range accumulators {
+		resp[i] = dto.ToAccumulatorResponse(&accumulators[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"year": year, "accumulators": resp})
+}
+
+// Certificate retorna el certificado de ingresos y retenciones del año (por defecto el anterior)
+// GET /api/v1/employees/:id/income-certificate?year=2025
+func (h *AccumulatorHandler) Certificate(c *gin.Context) {
+	employeeID, year, ok := accumulatorParams(c, time.Now().Year()-1)
+	if !ok {
+		return
+	}
+	cert, err := h.svc.Certificate(c.Request.Context(), employeeID, year)
+	if err != nil {
+		c.JSON(accumulatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToIncomeCertificateResponse(cert))
+}
+
+// CertificatePDF descarga el certificado de ingresos y retenciones del año en PDF
+// GET /api/v1/employees/:id/income-certificate/pdf?year=2025
+func (h *AccumulatorHandler) CertificatePDF(c *gin.Context) {
+	employeeID, year, ok := accumulatorParams(c, time.Now().Year()-1)
+	if !ok {
+		return
+	}
+	file, err := h.svc.CertificatePDF(c.Request.Context(), employeeID, year)
+	if err != nil {
+		c.JSON(accumulatorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="`+file.FileName+`"`)
+	c.Data(http.StatusOK, "application/pdf", file.Content)
+}
+
+// accumulatorParams lee el empleado de la ruta y el año de la consulta
+func accumulatorParams(c *gin.Context, defaultYear int) (uint, int, bool) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	year := defaultYear
+	if raw := c.Query("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidCertificateYear.Error()})
+			return 0, 0, false
+		}
+		year = parsed
+	}
+	return employeeID, year, true
+}
+
+func accumulatorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCertificateYear):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrNoIncomeForCertificate):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrEmployerDataRequired):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
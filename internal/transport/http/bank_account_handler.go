@@ -0,0 +1,141 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// BankAccountHandler maneja las cuentas de pago de un empleado
+type BankAccountHandler struct {
+	svc *service.BankAccountService
+}
+
+func NewBankAccountHandler(svc *service.BankAccountService) *BankAccountHandler {
+	return &BankAccountHandler{svc: svc}
+}
+
+// List lista las cuentas del empleado con la principal primero
+// GET /api/v1/employees/:id/bank-accounts
+func (h *BankAccountHandler) List(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	accounts, err := h.svc.List(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.EmployeeBankAccountResponse, len(accounts))
+	for i := range accounts {
+		resp[i] = dto.ToEmployeeBankAccountResponse(&accounts[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"bank_accounts": resp})
+}
+
+// Create agrega una cuenta de pago al empleado
+// POST /api/v1/employees/:id/bank-accounts
+func (h *BankAccountHandler) Create(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	var req dto.CreateBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	account := req.ToDomain()
+	if err := h.svc.Create(c.Request.Context(), employeeID, account); err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToEmployeeBankAccountResponse(account))
+}
+
+// Update actualiza los datos de una cuenta
+// PUT /api/v1/employees/:id/bank-accounts/:accountId
+func (h *BankAccountHandler) Update(c *gin.Context) {
+	employeeID, accountID, ok := bankAccountIDs(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	account, err := h.svc.GetByID(ctx, employeeID, accountID)
+	if err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	req.Apply(account)
+	if err := h.svc.Update(ctx, employeeID, account); err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToEmployeeBankAccountResponse(account))
+}
+
+// SetPrimary marca la cuenta como principal
+// PUT /api/v1/employees/:id/bank-accounts/:accountId/primary
+func (h *BankAccountHandler) SetPrimary(c *gin.Context) {
+	employeeID, accountID, ok := bankAccountIDs(c)
+	if !ok {
+		return
+	}
+	account, err := h.svc.SetPrimary(c.Request.Context(), employeeID, accountID)
+	if err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToEmployeeBankAccountResponse(account))
+}
+
+// Delete desactiva la cuenta; los pagos ya registrados conservan sus datos
+// DELETE /api/v1/employees/:id/bank-accounts/:accountId
+func (h *BankAccountHandler) Delete(c *gin.Context) {
+	employeeID, accountID, ok := bankAccountIDs(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Deactivate(c.Request.Context(), employeeID, accountID); err != nil {
+		c.JSON(bankAccountErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "bank account deactivated successfully"})
+}
+
+func bankAccountIDs(c *gin.Context) (uint, uint, bool) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	accountID, err := strconv.ParseUint(c.Param("accountId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank account id"})
+		return 0, 0, false
+	}
+	return employeeID, uint(accountID), true
+}
+
+func bankAccountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrBankAccountNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrBankNameRequired), errors.Is(err, domain.ErrInvalidAccountType),
+		errors.Is(err, domain.ErrInvalidAccountNumber), errors.Is(err, domain.ErrInvalidPaymentSplit):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrPrimaryAccountInactive):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
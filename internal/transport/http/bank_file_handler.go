@@ -0,0 +1,224 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/bankfile"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// BankFileHandler maneja los archivos de pago para el banco y sus plantillas
+type BankFileHandler struct {
+	svc *service.BankFileService
+}
+
+func NewBankFileHandler(svc *service.BankFileService) *BankFileHandler {
+	return &BankFileHandler{svc: svc}
+}
+
+// Export genera el archivo de pagos de las nóminas aprobadas o pagadas parcialmente del periodo
+// POST /api/v1/bank-files
+func (h *BankFileHandler) Export(c *gin.Context) {
+	var req dto.ExportBankFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	periodStart, err := parseDate(req.PeriodStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_start format, use YYYY-MM-DD"})
+		return
+	}
+	periodEnd, err := parseDate(req.PeriodEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_end format, use YYYY-MM-DD"})
+		return
+	}
+	var executionDate time.Time
+	if req.ExecutionDate != "" {
+		if executionDate, err = parseDate(req.ExecutionDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution_date format, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	file, err := h.svc.Export(c.Request.Context(), service.BankFileRequest{
+		Format:        req.Format,
+		TemplateID:    req.TemplateID,
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		ExecutionDate: executionDate,
+		Debtor: bankfile.Debtor{
+			Name:          req.Debtor.Name,
+			ID:            req.Debtor.ID,
+			BankName:      req.Debtor.BankName,
+			AccountType:   req.Debtor.AccountType,
+			AccountNumber: req.Debtor.AccountNumber,
+		},
+	})
+	if err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToBankFileResponse(file))
+}
+
+// List lista los archivos generados
+// GET /api/v1/bank-files
+func (h *BankFileHandler) List(c *gin.Context) {
+	files, err := h.svc.ListFiles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.BankFileResponse, len(files))
+	for i := range files {
+		resp[i] = dto.ToBankFileResponse(&files[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"bank_files": resp})
+}
+
+// GetByID retorna los totales de control de un archivo
+// GET /api/v1/bank-files/:id
+func (h *BankFileHandler) GetByID(c *gin.Context) {
+	id, ok := bankFileID(c)
+	if !ok {
+		return
+	}
+	file, err := h.svc.GetFile(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToBankFileResponse(file))
+}
+
+// Download descarga el contenido del archivo
+// GET /api/v1/bank-files/:id/download
+func (h *BankFileHandler) Download(c *gin.Context) {
+	id, ok := bankFileID(c)
+	if !ok {
+		return
+	}
+	file, err := h.svc.GetFile(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+file.FileName+`"`)
+	c.Header("X-Content-SHA256", file.Hash)
+	c.Data(http.StatusOK, h.svc.ContentType(file), file.Content)
+}
+
+// ListTemplates lista las plantillas de ancho fijo
+// GET /api/v1/bank-file-templates
+func (h *BankFileHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.svc.ListTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.BankFileTemplateResponse, len(templates))
+	for i := range templates {
+		resp[i] = dto.ToBankFileTemplateResponse(&templates[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": resp})
+}
+
+// CreateTemplate registra la plantilla de un banco
+// POST /api/v1/bank-file-templates
+func (h *BankFileHandler) CreateTemplate(c *gin.Context) {
+	var req dto.BankFileTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template := req.ToDomain()
+	if err := h.svc.CreateTemplate(c.Request.Context(), template); err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToBankFileTemplateResponse(template))
+}
+
+// GetTemplate retorna una plantilla
+// GET /api/v1/bank-file-templates/:id
+func (h *BankFileHandler) GetTemplate(c *gin.Context) {
+	id, ok := bankFileID(c)
+	if !ok {
+		return
+	}
+	template, err := h.svc.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToBankFileTemplateResponse(template))
+}
+
+// UpdateTemplate reemplaza una plantilla con sus columnas
+// PUT /api/v1/bank-file-templates/:id
+func (h *BankFileHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := bankFileID(c)
+	if !ok {
+		return
+	}
+	var req dto.BankFileTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template := req.ToDomain()
+	template.ID = id
+	if err := h.svc.UpdateTemplate(c.Request.Context(), template); err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToBankFileTemplateResponse(template))
+}
+
+// DeleteTemplate elimina una plantilla
+// DELETE /api/v1/bank-file-templates/:id
+func (h *BankFileHandler) DeleteTemplate(c *gin.Context) {
+	id, ok := bankFileID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteTemplate(c.Request.Context(), id); err != nil {
+		c.JSON(bankFileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func bankFileID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func bankFileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBankFileNotFound), errors.Is(err, domain.ErrBankFileTemplateNotFound),
+		errors.Is(err, domain.ErrNoPayrollsToExport):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidBankFileFormat), errors.Is(err, domain.ErrInvalidBankFileTemplate),
+		errors.Is(err, domain.ErrDebtorAccountRequired), errors.Is(err, domain.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNoBankAccount), errors.Is(err, domain.ErrNoPrimaryBankAccount),
+		errors.Is(err, domain.ErrInvalidPaymentSplit):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
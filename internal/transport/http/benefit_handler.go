@@ -0,0 +1,161 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// BenefitHandler maneja la causación y el pago de prestaciones sociales
+type BenefitHandler struct {
+	benefitSvc *service.BenefitService
+}
+
+func NewBenefitHandler(benefitSvc *service.BenefitService) *BenefitHandler {
+	return &BenefitHandler{benefitSvc: benefitSvc}
+}
+
+type AccrueBenefitsRequest struct {
+	PeriodStart string `json:"period_start" binding:"required"`
+	PeriodEnd   string `json:"period_end" binding:"required"`
+}
+
+type PayPrimaRequest struct {
+	Year     int    `json:"year" binding:"required"`
+	Semester int    `json:"semester" binding:"required,oneof=1 2"`
+	PayDate  string `json:"pay_date"`
+}
+
+type ConsignSeveranceRequest struct {
+	Year    int    `json:"year" binding:"required"`
+	PayDate string `json:"pay_date"`
+}
+
+// Accrue causa en el ledger las provisiones de las nóminas pagadas del periodo
+// POST /api/v1/benefits/accrue
+func (h *BenefitHandler) Accrue(c *gin.Context) {
+	var req AccrueBenefitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	periodStart, err := parseDate(req.PeriodStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_start format, use YYYY-MM-DD"})
+		return
+	}
+	periodEnd, err := parseDate(req.PeriodEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_end format, use YYYY-MM-DD"})
+		return
+	}
+
+	result, err := h.benefitSvc.Accrue(c.Request.Context(), periodStart, periodEnd)
+	if err != nil {
+		c.JSON(benefitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetLedger retorna los movimientos y saldos de prestaciones del empleado
+// GET /api/v1/benefits/employee/:employeeId
+func (h *BenefitHandler) GetLedger(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("employeeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	ledger, err := h.benefitSvc.GetLedger(c.Request.Context(), uint(employeeID))
+	if err != nil {
+		c.JSON(benefitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ledger)
+}
+
+// PayPrima genera las nóminas de prima semestral
+// POST /api/v1/benefits/prima
+func (h *BenefitHandler) PayPrima(c *gin.Context) {
+	var req PayPrimaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payDate, ok := optionalPayDate(c, req.PayDate)
+	if !ok {
+		return
+	}
+
+	payrolls, err := h.benefitSvc.PayPrima(c.Request.Context(), req.Year, req.Semester, payDate)
+	if err != nil {
+		c.JSON(benefitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, benefitPayrollsResponse(payrolls))
+}
+
+// ConsignSeverance genera las nóminas de consignación de cesantías e intereses del año
+// POST /api/v1/benefits/severance
+func (h *BenefitHandler) ConsignSeverance(c *gin.Context) {
+	var req ConsignSeveranceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payDate, ok := optionalPayDate(c, req.PayDate)
+	if !ok {
+		return
+	}
+
+	payrolls, err := h.benefitSvc.ConsignSeverance(c.Request.Context(), req.Year, payDate)
+	if err != nil {
+		c.JSON(benefitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, benefitPayrollsResponse(payrolls))
+}
+
+// optionalPayDate parsea pay_date si viene; responde 400 y retorna false si es inválida
+func optionalPayDate(c *gin.Context, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	payDate, err := parseDate(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pay_date format, use YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return payDate, true
+}
+
+func benefitPayrollsResponse(payrolls []domain.Payroll) gin.H {
+	var total float64
+	responses := make([]*dto.PayrollResponse, 0, len(payrolls))
+	for i := range payrolls {
+		total += payrolls[i].NetAmount
+		responses = append(responses, dto.ToPayrollResponse(&payrolls[i], ""))
+	}
+	return gin.H{
+		"payrolls":     responses,
+		"count":        len(responses),
+		"total_amount": total,
+	}
+}
+
+func benefitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPeriodClosed):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
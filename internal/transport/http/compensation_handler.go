@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CompensationHandler expone los reportes de compensación
+type CompensationHandler struct {
+	svc *service.CompensationService
+}
+
+func NewCompensationHandler(svc *service.CompensationService) *CompensationHandler {
+	return &CompensationHandler{svc: svc}
+}
+
+// CompaRatio lista el compa-ratio de los empleados activos por departamento
+// GET /api/v1/departments/compa-ratio?department_id=
+func (h *CompensationHandler) CompaRatio(c *gin.Context) {
+	departmentID, err := strconv.ParseUint(c.DefaultQuery("department_id", "0"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department_id"})
+		return
+	}
+
+	report, err := h.svc.CompaRatioReport(c.Request.Context(), uint(departmentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"departments": report})
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// ContractHandler maneja el ciclo de vida de los contratos de un empleado
+type ContractHandler struct {
+	contractSvc    *service.ContractService
+	terminationSvc *service.TerminationService
+}
+
+func NewContractHandler(contractSvc *service.ContractService, terminationSvc *service.TerminationService) *ContractHandler {
+	return &ContractHandler{contractSvc: contractSvc, terminationSvc: terminationSvc}
+}
+
+// Timeline retorna todos los contratos del empleado en orden cronológico
+// GET /api/v1/employees/:id/contracts
+func (h *ContractHandler) Timeline(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	h.respondTimeline(c, http.StatusOK, employeeID)
+}
+
+// Create crea un contrato y lo activa, cerrando el contrato activo anterior
+// POST /api/v1/employees/:id/contracts
+func (h *ContractHandler) Create(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateEmployeeContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	contract, err := req.ToDomain()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.contractSvc.Create(c.Request.Context(), employeeID, contract); err != nil {
+		c.JSON(contractErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.respondTimeline(c, http.StatusCreated, employeeID)
+}
+
+// Amend modifica los términos del contrato activo desde effective_date
+// POST /api/v1/employees/:id/contracts/:contractId/amend
+func (h *ContractHandler) Amend(c *gin.Context) {
+	employeeID, contractID, ok := contractIDs(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AmendContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var effectiveDate time.Time
+	if req.EffectiveDate != "" {
+		parsed, err := parseDate(req.EffectiveDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effective_date format, use YYYY-MM-DD"})
+			return
+		}
+		effectiveDate = parsed
+	}
+	terms := service.ContractTerms{
+		ContractTypeID:      req.ContractTypeID,
+		BaseSalary:          req.BaseSalary,
+		WorkHoursPerDay:     req.WorkHoursPerDay,
+		WorkDaysPerWeek:     req.WorkDaysPerWeek,
+		HealthContribution:  req.HealthContribution,
+		PensionContribution: req.PensionContribution,
+		TransportAllowance:  req.TransportAllowance,
+		HousingAllowance:    req.HousingAllowance,
+		SocialSecurityProof: req.SocialSecurityProof,
+		BandOverrideReason:  req.BandOverrideReason,
+	}
+	if req.Currency != nil {
+		currency := strings.ToUpper(*req.Currency)
+		terms.Currency = &currency
+	}
+	if req.EndDate != nil {
+		endDate, err := parseDate(*req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format, use YYYY-MM-DD"})
+			return
+		}
+		terms.EndDate = &endDate
+	}
+
+	if _, err := h.contractSvc.Amend(c.Request.Context(), employeeID, contractID, effectiveDate, terms); err != nil {
+		c.JSON(contractErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.respondTimeline(c, http.StatusOK, employeeID)
+}
+
+// Renew prorroga un contrato a término fijo hasta end_date
+// POST /api/v1/employees/:id/contracts/:contractId/renew
+func (h *ContractHandler) Renew(c *gin.Context) {
+	employeeID, contractID, ok := contractIDs(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RenewContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format, use YYYY-MM-DD"})
+		return
+	}
+
+	terms := service.ContractTerms{BaseSalary: req.BaseSalary, BandOverrideReason: req.BandOverrideReason}
+	if _, err := h.contractSvc.Renew(c.Request.Context(), employeeID, contractID, endDate, terms); err != nil {
+		c.JSON(contractErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.respondTimeline(c, http.StatusCreated, employeeID)
+}
+
+// Terminate termina el contrato y genera la liquidación final del empleado
+// POST /api/v1/employees/:id/contracts/:contractId/terminate
+func (h *ContractHandler) Terminate(c *gin.Context) {
+	employeeID, contractID, ok := contractIDs(c)
+	if !ok {
+		return
+	}
+
+	var req TerminateEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	terminationDate, err := parseDate(req.TerminationDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid termination_date format, use YYYY-MM-DD"})
+		return
+	}
+	payDate, ok := optionalPayDate(c, req.PayDate)
+	if !ok {
+		return
+	}
+
+	doc, err := h.terminationSvc.Terminate(c.Request.Context(), service.TerminateRequest{
+		EmployeeID:      employeeID,
+		ContractID:      contractID,
+		TerminationDate: terminationDate,
+		Reason:          req.Reason,
+		JustCause:       req.JustCause,
+		PayDate:         payDate,
+	})
+	if err != nil {
+		c.JSON(terminationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, settlementResponse(doc))
+}
+
+// RenewalAlerts lista los contratos que vencen dentro de los días de alerta de su tipo
+// GET /api/v1/contracts/renewal-alerts?as_of=YYYY-MM-DD
+func (h *ContractHandler) RenewalAlerts(c *gin.Context) {
+	var asOf time.Time
+	if value := c.Query("as_of"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of format, use YYYY-MM-DD"})
+			return
+		}
+		asOf = parsed
+	}
+
+	alerts, err := h.contractSvc.RenewalAlerts(c.Request.Context(), asOf)
+	if err != nil {
+		c.JSON(contractErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]gin.H, len(alerts))
+	for i := range alerts {
+		resp[i] = gin.H{
+			"employee_id": alerts[i].Contract.EmployeeID,
+			"contract":    dto.ToContractResponse(&alerts[i].Contract),
+			"days_left":   alerts[i].DaysLeft,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": resp})
+}
+
+func (h *ContractHandler) respondTimeline(c *gin.Context, status int, employeeID uint) {
+	contracts, err := h.contractSvc.Timeline(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(contractErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, dto.ToContractTimelineResponse(employeeID, contracts))
+}
+
+func contractEmployeeID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func contractIDs(c *gin.Context) (uint, uint, bool) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	contractID, err := strconv.ParseUint(c.Param("contractId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contract id"})
+		return 0, 0, false
+	}
+	return employeeID, uint(contractID), true
+}
+
+func contractErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrEmployeeContractNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrActorRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidContractDates):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrContractOverlap), errors.Is(err, domain.ErrContractNotActive),
+		errors.Is(err, domain.ErrContractNotRenewable), errors.Is(err, domain.ErrEmployeeInactive),
+		errors.Is(err, domain.ErrPeriodClosed), errors.Is(err, domain.ErrPayrollNotRecalculable):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrContractTypeNotFound), errors.Is(err, domain.ErrContractTypeInactive),
+		errors.Is(err, domain.ErrContractEndDateRequired), errors.Is(err, domain.ErrSocialSecurityProofRequired),
+		errors.Is(err, domain.ErrSalaryOutOfBand):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// ContractTypeHandler maneja los tipos de contrato del tenant
+type ContractTypeHandler struct {
+	svc *service.ContractTypeService
+}
+
+func NewContractTypeHandler(svc *service.ContractTypeService) *ContractTypeHandler {
+	return &ContractTypeHandler{svc: svc}
+}
+
+// Create crea un tipo de contrato
+// POST /api/v1/contract-types
+func (h *ContractTypeHandler) Create(c *gin.Context) {
+	var req dto.CreateContractTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contractType := req.ToDomain()
+	if err := h.svc.Create(c.Request.Context(), contractType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToContractTypeResponse(contractType))
+}
+
+// List lista los tipos de contrato del tenant
+// GET /api/v1/contract-types
+func (h *ContractTypeHandler) List(c *gin.Context) {
+	contractTypes, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]dto.ContractTypeResponse, len(contractTypes))
+	for i := range contractTypes {
+		resp[i] = dto.ToContractTypeResponse(&contractTypes[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"contract_types": resp})
+}
+
+// GetByID obtiene un tipo de contrato
+// GET /api/v1/contract-types/:id
+func (h *ContractTypeHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	contractType, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(contractTypeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToContractTypeResponse(contractType))
+}
+
+// Update actualiza las reglas de un tipo de contrato; el código no cambia
+// PUT /api/v1/contract-types/:id
+func (h *ContractTypeHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	existing, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(contractTypeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateContractTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Apply(existing)
+
+	if err := h.svc.Update(c.Request.Context(), existing); err != nil {
+		c.JSON(contractTypeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToContractTypeResponse(existing))
+}
+
+// Delete elimina un tipo de contrato
+// DELETE /api/v1/contract-types/:id
+func (h *ContractTypeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(contractTypeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// SeedDefaultContractTypes carga los tipos de contrato por defecto
+// POST /api/v1/contract-types/seed
+func (h *ContractTypeHandler) SeedDefaultContractTypes(c *gin.Context) {
+	if err := h.svc.SeedDefaultContractTypes(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "default contract types seeded"})
+}
+
+func contractTypeErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrContractTypeNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
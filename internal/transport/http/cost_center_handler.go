@@ -0,0 +1,194 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// CostCenterHandler maneja los centros de costo, la asignación del costo de los empleados y
+// el informe de costo por centro
+type CostCenterHandler struct {
+	svc *service.CostCenterService
+}
+
+func NewCostCenterHandler(svc *service.CostCenterService) *CostCenterHandler {
+	return &CostCenterHandler{svc: svc}
+}
+
+// Create crea un centro de costo
+// POST /api/v1/cost-centers
+func (h *CostCenterHandler) Create(c *gin.Context) {
+	var req dto.CreateCostCenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	costCenter := req.ToDomain()
+	if err := h.svc.Create(c.Request.Context(), costCenter); err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToCostCenterResponse(costCenter))
+}
+
+// List lista los centros de costo del tenant
+// GET /api/v1/cost-centers
+func (h *CostCenterHandler) List(c *gin.Context) {
+	costCenters, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.CostCenterResponse, len(costCenters))
+	for i := range costCenters {
+		resp[i] = dto.ToCostCenterResponse(&costCenters[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"cost_centers": resp})
+}
+
+// GetByID obtiene un centro de costo
+// GET /api/v1/cost-centers/:id
+func (h *CostCenterHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost center id"})
+		return
+	}
+
+	costCenter, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToCostCenterResponse(costCenter))
+}
+
+// Update actualiza un centro de costo
+// PUT /api/v1/cost-centers/:id
+func (h *CostCenterHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost center id"})
+		return
+	}
+
+	costCenter, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateCostCenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Apply(costCenter)
+
+	if err := h.svc.Update(c.Request.Context(), costCenter); err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToCostCenterResponse(costCenter))
+}
+
+// ListAllocations retorna las versiones de asignación del costo del empleado
+// GET /api/v1/employees/:id/cost-allocations
+func (h *CostCenterHandler) ListAllocations(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	versions, err := h.svc.ListAllocations(c.Request.Context(), employeeID)
+	if err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": dto.ToCostAllocationVersionsResponse(versions)})
+}
+
+// SetAllocation reemplaza la asignación del costo del empleado que rige desde una fecha
+// PUT /api/v1/employees/:id/cost-allocations
+func (h *CostCenterHandler) SetAllocation(c *gin.Context) {
+	employeeID, ok := contractEmployeeID(c)
+	if !ok {
+		return
+	}
+	var req dto.SetCostAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	effectiveFrom, err := parseDate(req.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effective_from (YYYY-MM-DD)"})
+		return
+	}
+
+	versions, err := h.svc.SetAllocation(c.Request.Context(), employeeID, effectiveFrom, req.ToDomain())
+	if err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": dto.ToCostAllocationVersionsResponse(versions)})
+}
+
+// Report retorna el costo de nómina por centro de costo, en JSON o como archivo CSV
+// GET /api/v1/cost-centers/report?period_start=2026-09-01&period_end=2026-09-30
+// GET /api/v1/cost-centers/report?payroll_ids=10,11&department_ids=2&format=csv
+func (h *CostCenterHandler) Report(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidCostReportFormat.Error()})
+		return
+	}
+	filter, ok := registerFilter(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.svc.Report(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(costCenterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if format == "json" {
+		c.JSON(http.StatusOK, dto.ToCostReportResponse(report))
+		return
+	}
+
+	content, err := report.CSV()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	fileName := "costo_nomina_centros.csv"
+	if len(filter.PayrollIDs) == 0 {
+		fileName = fmt.Sprintf("costo_nomina_centros_%s_%s.csv",
+			filter.PeriodStart.Format("20060102"), filter.PeriodEnd.Format("20060102"))
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "text/csv", content)
+}
+
+func costCenterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCostCenterNotFound), errors.Is(err, domain.ErrEmployeeNotFound),
+		errors.Is(err, domain.ErrCostAllocationNotFound), errors.Is(err, domain.ErrNoPayrollsForRegister):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCostCenterInactive):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCostAllocation), errors.Is(err, domain.ErrInvalidRegisterFilter):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
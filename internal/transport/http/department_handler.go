@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// DepartmentHandler maneja los departamentos y el árbol organizacional
+type DepartmentHandler struct {
+	svc *service.DepartmentService
+}
+
+func NewDepartmentHandler(svc *service.DepartmentService) *DepartmentHandler {
+	return &DepartmentHandler{svc: svc}
+}
+
+// Create crea un departamento
+// POST /api/v1/departments
+func (h *DepartmentHandler) Create(c *gin.Context) {
+	var req dto.CreateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	department := req.ToDomain()
+	if err := h.svc.Create(c.Request.Context(), department); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToDepartmentResponse(department))
+}
+
+// List lista los departamentos del tenant
+// GET /api/v1/departments
+func (h *DepartmentHandler) List(c *gin.Context) {
+	departments, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.DepartmentResponse, len(departments))
+	for i := range departments {
+		resp[i] = dto.ToDepartmentResponse(&departments[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"departments": resp})
+}
+
+// Tree retorna el árbol organizacional con headcount por nodo
+// GET /api/v1/departments/tree
+func (h *DepartmentHandler) Tree(c *gin.Context) {
+	roots, err := h.svc.Tree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tree": departmentTree(roots)})
+}
+
+// GetByID obtiene un departamento
+// GET /api/v1/departments/:id
+func (h *DepartmentHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department id"})
+		return
+	}
+
+	department, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(department))
+}
+
+// Update actualiza un departamento; no se puede desactivar con empleados activos
+// PUT /api/v1/departments/:id
+func (h *DepartmentHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department id"})
+		return
+	}
+
+	department, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Apply(department)
+
+	if err := h.svc.Update(c.Request.Context(), department); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(department))
+}
+
+// SetHead asigna el jefe del departamento
+// PUT /api/v1/departments/:id/head
+func (h *DepartmentHandler) SetHead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department id"})
+		return
+	}
+
+	var req dto.SetDepartmentHeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	department, err := h.svc.SetHead(c.Request.Context(), uint(id), req.EmployeeID)
+	if err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(department))
+}
+
+// Delete elimina un departamento sin empleados activos ni sub-departamentos
+// DELETE /api/v1/departments/:id
+func (h *DepartmentHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department id"})
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func departmentTree(nodes []*service.DepartmentNode) []dto.DepartmentTreeNode {
+	tree := make([]dto.DepartmentTreeNode, len(nodes))
+	for i, node := range nodes {
+		tree[i] = dto.DepartmentTreeNode{
+			DepartmentResponse: *dto.ToDepartmentResponse(&node.Department),
+			Headcount:          node.Headcount,
+			TotalHeadcount:     node.TotalHeadcount,
+			Children:           departmentTree(node.Children),
+		}
+	}
+	return tree
+}
+
+func departmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDepartmentNotFound), errors.Is(err, domain.ErrPositionNotFound),
+		errors.Is(err, domain.ErrEmployeeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrDepartmentHasActiveEmployees), errors.Is(err, domain.ErrDepartmentHasChildren),
+		errors.Is(err, domain.ErrDepartmentCycle), errors.Is(err, domain.ErrEmployeeInactive):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+)
+
+// ========================================
+// Accumulator DTOs
+// ========================================
+
+// AccumulatorResponse representa un acumulado anual del empleado
+type AccumulatorResponse struct {
+	Code      string  `json:"code"`
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// ToAccumulatorResponse convierte domain.PayrollAccumulator a AccumulatorResponse
+func ToAccumulatorResponse(a *domain.PayrollAccumulator) *AccumulatorResponse {
+	return &AccumulatorResponse{
+		Code:      a.Code,
+		Type:      a.Type,
+		Amount:    a.Amount,
+		UpdatedAt: a.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// IncomeCertificateResponse representa el certificado de ingresos y retenciones del año
+type IncomeCertificateResponse struct {
+	Year             int     `json:"year"`
+	IssuedAt         string  `json:"issued_at"`
+	EmployerName     string  `json:"employer_name"`
+	EmployerNIT      string  `json:"employer_nit"`
+	EmployeeID       uint    `json:"employee_id"`
+	EmployeeDNI      string  `json:"employee_dni"`
+	EmployeeName     string  `json:"employee_name"`
+	Salaries         float64 `json:"salaries"`
+	Benefits         float64 `json:"benefits"`
+	Severance        float64 `json:"severance"`
+	OtherPayments    float64 `json:"other_payments"`
+	Gross            float64 `json:"gross"`
+	Health           float64 `json:"health"`
+	Pension          float64 `json:"pension"`
+	OtherDeductions  float64 `json:"other_deductions"`
+	ExemptIncome     float64 `json:"exempt_income"`
+	ContributionBase float64 `json:"contribution_base"`
+	Withholding      float64 `json:"withholding"`
+}
+
+// ToIncomeCertificateResponse convierte el certificado generado
+func ToIncomeCertificateResponse(c *service.IncomeCertificate) *IncomeCertificateResponse {
+	return &IncomeCertificateResponse{
+		Year:             c.Year,
+		IssuedAt:         c.IssuedAt.Format("2006-01-02"),
+		EmployerName:     c.EmployerName,
+		EmployerNIT:      c.EmployerNIT,
+		EmployeeID:       c.EmployeeID,
+		EmployeeDNI:      c.EmployeeDNI,
+		EmployeeName:     c.EmployeeName,
+		Salaries:         c.Salaries,
+		Benefits:         c.Benefits,
+		Severance:        c.Severance,
+		OtherPayments:    c.OtherPayments,
+		Gross:            c.Gross,
+		Health:           c.Health,
+		Pension:          c.Pension,
+		OtherDeductions:  c.OtherDeductions,
+		ExemptIncome:     c.ExemptIncome,
+		ContributionBase: c.ContributionBase,
+		Withholding:      c.Withholding,
+	}
+}
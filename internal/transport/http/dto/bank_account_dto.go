@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// Bank Account DTOs
+// ========================================
+
+// CreateBankAccountRequest representa el DTO para agregar una cuenta de pago al empleado
+type CreateBankAccountRequest struct {
+	BankName        string  `json:"bank_name" binding:"required,max=100"`
+	AccountType     string  `json:"account_type" binding:"required,oneof=savings checking"`
+	AccountNumber   string  `json:"account_number" binding:"required,max=50"`
+	IsPrimary       bool    `json:"is_primary"`
+	SplitPercentage float64 `json:"split_percentage" binding:"min=0,max=100"`
+}
+
+// UpdateBankAccountRequest representa el DTO para actualizar una cuenta. Para cambiar la
+// principal se usa PUT /primary sobre la nueva cuenta
+type UpdateBankAccountRequest struct {
+	BankName        *string  `json:"bank_name,omitempty" binding:"omitempty,max=100"`
+	AccountType     *string  `json:"account_type,omitempty" binding:"omitempty,oneof=savings checking"`
+	AccountNumber   *string  `json:"account_number,omitempty" binding:"omitempty,max=50"`
+	SplitPercentage *float64 `json:"split_percentage,omitempty" binding:"omitempty,min=0,max=100"`
+	IsActive        *bool    `json:"is_active,omitempty"`
+}
+
+// EmployeeBankAccountResponse representa una cuenta de pago del empleado
+type EmployeeBankAccountResponse struct {
+	ID              uint    `json:"id"`
+	EmployeeID      uint    `json:"employee_id"`
+	BankName        string  `json:"bank_name"`
+	AccountType     string  `json:"account_type"`
+	AccountNumber   string  `json:"account_number"`
+	IsPrimary       bool    `json:"is_primary"`
+	SplitPercentage float64 `json:"split_percentage"`
+	IsActive        bool    `json:"is_active"`
+}
+
+// ToDomain convierte CreateBankAccountRequest a domain.EmployeeBankAccount
+func (r *CreateBankAccountRequest) ToDomain() *domain.EmployeeBankAccount {
+	return &domain.EmployeeBankAccount{
+		BankName:        r.BankName,
+		AccountType:     r.AccountType,
+		AccountNumber:   r.AccountNumber,
+		IsPrimary:       r.IsPrimary,
+		SplitPercentage: r.SplitPercentage,
+		IsActive:        true,
+	}
+}
+
+// Apply aplica los campos enviados sobre la cuenta existente
+func (r *UpdateBankAccountRequest) Apply(account *domain.EmployeeBankAccount) {
+	if r.BankName != nil {
+		account.BankName = *r.BankName
+	}
+	if r.AccountType != nil {
+		account.AccountType = *r.AccountType
+	}
+	if r.AccountNumber != nil {
+		account.AccountNumber = *r.AccountNumber
+	}
+	if r.SplitPercentage != nil {
+		account.SplitPercentage = *r.SplitPercentage
+	}
+	if r.IsActive != nil {
+		account.IsActive = *r.IsActive
+	}
+}
+
+// ToEmployeeBankAccountResponse convierte domain.EmployeeBankAccount a EmployeeBankAccountResponse
+func ToEmployeeBankAccountResponse(a *domain.EmployeeBankAccount) *EmployeeBankAccountResponse {
+	return &EmployeeBankAccountResponse{
+		ID:              a.ID,
+		EmployeeID:      a.EmployeeID,
+		BankName:        a.BankName,
+		AccountType:     a.AccountType,
+		AccountNumber:   a.AccountNumber,
+		IsPrimary:       a.IsPrimary,
+		SplitPercentage: a.SplitPercentage,
+		IsActive:        a.IsActive,
+	}
+}
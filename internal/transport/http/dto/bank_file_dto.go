@@ -0,0 +1,133 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// Bank File DTOs
+// ========================================
+
+// ExportBankFileRequest representa el DTO para generar el archivo de pagos de un periodo
+type ExportBankFileRequest struct {
+	Format        string                `json:"format" binding:"required,oneof=csv pain001 fixed_width"`
+	TemplateID    uint                  `json:"template_id" binding:"required_if=Format fixed_width"`
+	PeriodStart   string                `json:"period_start" binding:"required"`
+	PeriodEnd     string                `json:"period_end" binding:"required"`
+	ExecutionDate string                `json:"execution_date"`
+	Debtor        BankFileDebtorRequest `json:"debtor" binding:"required"`
+}
+
+// BankFileDebtorRequest es la cuenta de la empresa que origina las transferencias
+type BankFileDebtorRequest struct {
+	Name          string `json:"name" binding:"required,max=140"`
+	ID            string `json:"id" binding:"max=35"`
+	BankName      string `json:"bank_name" binding:"max=100"`
+	AccountType   string `json:"account_type" binding:"omitempty,oneof=savings checking"`
+	AccountNumber string `json:"account_number" binding:"required,max=34"`
+}
+
+// BankFileResponse representa un archivo generado con sus totales de control
+type BankFileResponse struct {
+	ID            uint    `json:"id"`
+	Reference     string  `json:"reference"`
+	Format        string  `json:"format"`
+	TemplateID    *uint   `json:"template_id,omitempty"`
+	PeriodStart   string  `json:"period_start"`
+	PeriodEnd     string  `json:"period_end"`
+	ExecutionDate string  `json:"execution_date"`
+	FileName      string  `json:"file_name"`
+	RecordCount   int     `json:"record_count"`
+	TotalAmount   float64 `json:"total_amount"`
+	Hash          string  `json:"hash"`
+	CreatedBy     uint    `json:"created_by"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// BankFileTemplateRequest representa el DTO para crear o reemplazar una plantilla de ancho fijo
+type BankFileTemplateRequest struct {
+	Name     string                         `json:"name" binding:"required,max=100"`
+	BankName string                         `json:"bank_name" binding:"max=100"`
+	Fields   []BankFileTemplateFieldRequest `json:"fields" binding:"required,min=1,dive"`
+}
+
+// BankFileTemplateFieldRequest es una columna de la plantilla
+type BankFileTemplateFieldRequest struct {
+	Section  string `json:"section" binding:"required,oneof=header detail trailer"`
+	Position int    `json:"position"`
+	Source   string `json:"source" binding:"required"`
+	Value    string `json:"value,omitempty" binding:"max=100"`
+	Width    int    `json:"width" binding:"required,min=1"`
+	Align    string `json:"align,omitempty" binding:"omitempty,oneof=left right"`
+	PadChar  string `json:"pad_char,omitempty" binding:"max=1"`
+}
+
+// BankFileTemplateResponse representa una plantilla con sus columnas en orden
+type BankFileTemplateResponse struct {
+	ID       uint                           `json:"id"`
+	Name     string                         `json:"name"`
+	BankName string                         `json:"bank_name"`
+	Fields   []BankFileTemplateFieldRequest `json:"fields"`
+}
+
+// ToDomain convierte BankFileTemplateRequest a domain.BankFileTemplate
+func (r *BankFileTemplateRequest) ToDomain() *domain.BankFileTemplate {
+	template := &domain.BankFileTemplate{
+		Name:     r.Name,
+		BankName: r.BankName,
+		Fields:   make([]domain.BankFileTemplateField, len(r.Fields)),
+	}
+	for i, f := range r.Fields {
+		template.Fields[i] = domain.BankFileTemplateField{
+			Section:  f.Section,
+			Position: f.Position,
+			Source:   f.Source,
+			Value:    f.Value,
+			Width:    f.Width,
+			Align:    f.Align,
+			PadChar:  f.PadChar,
+		}
+	}
+	return template
+}
+
+// ToBankFileResponse convierte domain.BankPaymentFile a BankFileResponse
+func ToBankFileResponse(f *domain.BankPaymentFile) *BankFileResponse {
+	return &BankFileResponse{
+		ID:            f.ID,
+		Reference:     f.Reference,
+		Format:        f.Format,
+		TemplateID:    f.TemplateID,
+		PeriodStart:   f.PeriodStart.Format("2006-01-02"),
+		PeriodEnd:     f.PeriodEnd.Format("2006-01-02"),
+		ExecutionDate: f.ExecutionDate.Format("2006-01-02"),
+		FileName:      f.FileName,
+		RecordCount:   f.RecordCount,
+		TotalAmount:   f.TotalAmount,
+		Hash:          f.Hash,
+		CreatedBy:     f.CreatedBy,
+		CreatedAt:     f.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ToBankFileTemplateResponse convierte domain.BankFileTemplate a BankFileTemplateResponse
+func ToBankFileTemplateResponse(t *domain.BankFileTemplate) *BankFileTemplateResponse {
+	resp := &BankFileTemplateResponse{
+		ID:       t.ID,
+		Name:     t.Name,
+		BankName: t.BankName,
+		Fields:   make([]BankFileTemplateFieldRequest, len(t.Fields)),
+	}
+	for i, f := range t.Fields {
+		resp.Fields[i] = BankFileTemplateFieldRequest{
+			Section:  f.Section,
+			Position: f.Position,
+			Source:   f.Source,
+			Value:    f.Value,
+			Width:    f.Width,
+			Align:    f.Align,
+			PadChar:  f.PadChar,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,140 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// ContractType DTOs
+// ========================================
+
+// CreateContractTypeRequest representa el DTO para crear tipos de contrato
+type CreateContractTypeRequest struct {
+	Code                        string  `json:"code" binding:"required,max=30"`
+	Name                        string  `json:"name" binding:"required,max=50"`
+	Description                 string  `json:"description,omitempty" binding:"max=255"`
+	RequiresEndDate             bool    `json:"requires_end_date"`
+	RenewalAlertDays            int     `json:"renewal_alert_days" binding:"min=0"`
+	SkipPension                 bool    `json:"skip_pension"`
+	StipendPercentage           float64 `json:"stipend_percentage" binding:"min=0,max=100"`
+	NoDeductions                bool    `json:"no_deductions"`
+	RequiresSocialSecurityProof bool    `json:"requires_social_security_proof"`
+	NoSocialBenefits            bool    `json:"no_social_benefits"`
+	IsActive                    *bool   `json:"is_active,omitempty"`
+}
+
+// UpdateContractTypeRequest representa el DTO para actualizar tipos de contrato
+type UpdateContractTypeRequest struct {
+	Name                        *string  `json:"name,omitempty" binding:"omitempty,max=50"`
+	Description                 *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	RequiresEndDate             *bool    `json:"requires_end_date,omitempty"`
+	RenewalAlertDays            *int     `json:"renewal_alert_days,omitempty" binding:"omitempty,min=0"`
+	SkipPension                 *bool    `json:"skip_pension,omitempty"`
+	StipendPercentage           *float64 `json:"stipend_percentage,omitempty" binding:"omitempty,min=0,max=100"`
+	NoDeductions                *bool    `json:"no_deductions,omitempty"`
+	RequiresSocialSecurityProof *bool    `json:"requires_social_security_proof,omitempty"`
+	NoSocialBenefits            *bool    `json:"no_social_benefits,omitempty"`
+	IsActive                    *bool    `json:"is_active,omitempty"`
+}
+
+// ContractTypeResponse representa la respuesta de un tipo de contrato
+type ContractTypeResponse struct {
+	ID                          uint      `json:"id"`
+	TenantID                    uint      `json:"tenant_id"`
+	Code                        string    `json:"code"`
+	Name                        string    `json:"name"`
+	Description                 string    `json:"description,omitempty"`
+	RequiresEndDate             bool      `json:"requires_end_date"`
+	RenewalAlertDays            int       `json:"renewal_alert_days"`
+	SkipPension                 bool      `json:"skip_pension"`
+	StipendPercentage           float64   `json:"stipend_percentage"`
+	NoDeductions                bool      `json:"no_deductions"`
+	RequiresSocialSecurityProof bool      `json:"requires_social_security_proof"`
+	NoSocialBenefits            bool      `json:"no_social_benefits"`
+	IsActive                    bool      `json:"is_active"`
+	CreatedAt                   time.Time `json:"created_at"`
+	UpdatedAt                   time.Time `json:"updated_at"`
+}
+
+// ========================================
+// DTO Conversion Methods
+// ========================================
+
+// ToDomain convierte CreateContractTypeRequest a domain.ContractType
+func (r *CreateContractTypeRequest) ToDomain() *domain.ContractType {
+	contractType := &domain.ContractType{
+		Code:                        strings.ToUpper(strings.TrimSpace(r.Code)),
+		Name:                        strings.TrimSpace(r.Name),
+		Description:                 strings.TrimSpace(r.Description),
+		RequiresEndDate:             r.RequiresEndDate,
+		RenewalAlertDays:            r.RenewalAlertDays,
+		SkipPension:                 r.SkipPension,
+		StipendPercentage:           r.StipendPercentage,
+		NoDeductions:                r.NoDeductions,
+		RequiresSocialSecurityProof: r.RequiresSocialSecurityProof,
+		NoSocialBenefits:            r.NoSocialBenefits,
+		IsActive:                    true,
+	}
+	if r.IsActive != nil {
+		contractType.IsActive = *r.IsActive
+	}
+	return contractType
+}
+
+// Apply aplica al tipo de contrato solo los campos enviados
+func (r *UpdateContractTypeRequest) Apply(contractType *domain.ContractType) {
+	if r.Name != nil {
+		contractType.Name = strings.TrimSpace(*r.Name)
+	}
+	if r.Description != nil {
+		contractType.Description = strings.TrimSpace(*r.Description)
+	}
+	if r.RequiresEndDate != nil {
+		contractType.RequiresEndDate = *r.RequiresEndDate
+	}
+	if r.RenewalAlertDays != nil {
+		contractType.RenewalAlertDays = *r.RenewalAlertDays
+	}
+	if r.SkipPension != nil {
+		contractType.SkipPension = *r.SkipPension
+	}
+	if r.StipendPercentage != nil {
+		contractType.StipendPercentage = *r.StipendPercentage
+	}
+	if r.NoDeductions != nil {
+		contractType.NoDeductions = *r.NoDeductions
+	}
+	if r.RequiresSocialSecurityProof != nil {
+		contractType.RequiresSocialSecurityProof = *r.RequiresSocialSecurityProof
+	}
+	if r.NoSocialBenefits != nil {
+		contractType.NoSocialBenefits = *r.NoSocialBenefits
+	}
+	if r.IsActive != nil {
+		contractType.IsActive = *r.IsActive
+	}
+}
+
+// ToContractTypeResponse convierte domain.ContractType a ContractTypeResponse
+func ToContractTypeResponse(contractType *domain.ContractType) ContractTypeResponse {
+	return ContractTypeResponse{
+		ID:                          contractType.ID,
+		TenantID:                    contractType.TenantID,
+		Code:                        contractType.Code,
+		Name:                        contractType.Name,
+		Description:                 contractType.Description,
+		RequiresEndDate:             contractType.RequiresEndDate,
+		RenewalAlertDays:            contractType.RenewalAlertDays,
+		SkipPension:                 contractType.SkipPension,
+		StipendPercentage:           contractType.StipendPercentage,
+		NoDeductions:                contractType.NoDeductions,
+		RequiresSocialSecurityProof: contractType.RequiresSocialSecurityProof,
+		NoSocialBenefits:            contractType.NoSocialBenefits,
+		IsActive:                    contractType.IsActive,
+		CreatedAt:                   contractType.CreatedAt,
+		UpdatedAt:                   contractType.UpdatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+)
+
+// ========================================
+// Cost Center DTOs
+// ========================================
+
+// CreateCostCenterRequest representa el DTO para crear centros de costo
+type CreateCostCenterRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// UpdateCostCenterRequest representa el DTO para actualizar centros de costo
+type UpdateCostCenterRequest struct {
+	Code     *string `json:"code,omitempty" binding:"omitempty,max=20"`
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// SetCostAllocationRequest reemplaza la versión de asignación que rige desde effective_from;
+// una lista vacía la elimina
+type SetCostAllocationRequest struct {
+	EffectiveFrom string                  `json:"effective_from" binding:"required"`
+	Allocations   []CostAllocationRequest `json:"allocations" binding:"dive"`
+}
+
+// CostAllocationRequest es el porcentaje del costo del empleado que va a un centro de costo
+type CostAllocationRequest struct {
+	CostCenterID uint    `json:"cost_center_id" binding:"required,min=1"`
+	Percentage   float64 `json:"percentage" binding:"required,gt=0,lte=100"`
+}
+
+// CostCenterResponse representa un centro de costo
+type CostCenterResponse struct {
+	ID       uint   `json:"id"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	IsActive bool   `json:"is_active"`
+}
+
+// CostAllocationVersionResponse representa las asignaciones que rigen desde una fecha
+type CostAllocationVersionResponse struct {
+	EffectiveFrom string                   `json:"effective_from"`
+	Allocations   []CostAllocationResponse `json:"allocations"`
+}
+
+// CostAllocationResponse representa la asignación a un centro de costo
+type CostAllocationResponse struct {
+	CostCenterID   uint    `json:"cost_center_id"`
+	CostCenterCode string  `json:"cost_center_code"`
+	CostCenterName string  `json:"cost_center_name"`
+	Percentage     float64 `json:"percentage"`
+}
+
+// CostReportResponse representa el costo de nómina repartido por centro de costo
+type CostReportResponse struct {
+	Centers               []CostCenterCostResponse `json:"cost_centers"`
+	Earnings              float64                  `json:"earnings"`
+	EmployerContributions float64                  `json:"employer_contributions"`
+	Total                 float64                  `json:"total"`
+}
+
+// CostCenterCostResponse representa el costo imputado a un centro de costo
+type CostCenterCostResponse struct {
+	CostCenterID          uint               `json:"cost_center_id,omitempty"`
+	Code                  string             `json:"code"`
+	Name                  string             `json:"name"`
+	Earnings              float64            `json:"earnings"`
+	EmployerContributions float64            `json:"employer_contributions"`
+	Total                 float64            `json:"total"`
+	Share                 float64            `json:"share"`
+	Concepts              map[string]float64 `json:"concepts"`
+}
+
+// ToDomain convierte CreateCostCenterRequest a domain.CostCenter
+func (r *CreateCostCenterRequest) ToDomain() *domain.CostCenter {
+	return &domain.CostCenter{
+		Code:     strings.ToUpper(strings.TrimSpace(r.Code)),
+		Name:     strings.TrimSpace(r.Name),
+		IsActive: true,
+	}
+}
+
+// Apply aplica al centro de costo solo los campos enviados
+func (r *UpdateCostCenterRequest) Apply(costCenter *domain.CostCenter) {
+	if r.Code != nil {
+		costCenter.Code = *r.Code
+	}
+	if r.Name != nil {
+		costCenter.Name = *r.Name
+	}
+	if r.IsActive != nil {
+		costCenter.IsActive = *r.IsActive
+	}
+}
+
+// ToDomain convierte las asignaciones de la solicitud a domain.CostCenterAllocation
+func (r *SetCostAllocationRequest) ToDomain() []domain.CostCenterAllocation {
+	allocations := make([]domain.CostCenterAllocation, len(r.Allocations))
+	for i, a := range r.Allocations {
+		allocations[i] = domain.CostCenterAllocation{
+			CostCenterID: a.CostCenterID,
+			Percentage:   a.Percentage,
+		}
+	}
+	return allocations
+}
+
+// ToCostCenterResponse convierte domain.CostCenter a CostCenterResponse
+func ToCostCenterResponse(c *domain.CostCenter) *CostCenterResponse {
+	return &CostCenterResponse{
+		ID:       c.ID,
+		Code:     c.Code,
+		Name:     c.Name,
+		IsActive: c.IsActive,
+	}
+}
+
+// ToCostAllocationVersionsResponse convierte las versiones de asignación del empleado
+func ToCostAllocationVersionsResponse(versions []service.CostAllocationVersion) []CostAllocationVersionResponse {
+	resp := make([]CostAllocationVersionResponse, len(versions))
+	for i, v := range versions {
+		allocations := make([]CostAllocationResponse, len(v.Allocations))
+		for j, a := range v.Allocations {
+			allocations[j] = CostAllocationResponse{
+				CostCenterID:   a.CostCenterID,
+				CostCenterCode: a.CostCenter.Code,
+				CostCenterName: a.CostCenter.Name,
+				Percentage:     a.Percentage,
+			}
+		}
+		resp[i] = CostAllocationVersionResponse{
+			EffectiveFrom: v.EffectiveFrom.Format("2006-01-02"),
+			Allocations:   allocations,
+		}
+	}
+	return resp
+}
+
+// ToCostReportResponse convierte el informe de costo por centro de costo
+func ToCostReportResponse(r *service.CostReport) *CostReportResponse {
+	centers := make([]CostCenterCostResponse, len(r.Centers))
+	for i, c := range r.Centers {
+		centers[i] = CostCenterCostResponse{
+			CostCenterID:          c.CostCenterID,
+			Code:                  c.Code,
+			Name:                  c.Name,
+			Earnings:              c.Earnings,
+			EmployerContributions: c.EmployerContributions,
+			Total:                 c.Total,
+			Share:                 c.Share,
+			Concepts:              c.Concepts,
+		}
+	}
+	return &CostReportResponse{
+		Centers:               centers,
+		Earnings:              r.Earnings,
+		EmployerContributions: r.EmployerContributions,
+		Total:                 r.Total,
+	}
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+)
+
+// ========================================
+// Electronic payroll DTOs
+// ========================================
+
+// GenerateElectronicPayrollRequest es el mes a generar
+type GenerateElectronicPayrollRequest struct {
+	Year  int `json:"year" binding:"required,min=2000"`
+	Month int `json:"month" binding:"required,min=1,max=12"`
+}
+
+// ElectronicPayrollDocumentResponse representa un documento sin su XML
+type ElectronicPayrollDocumentResponse struct {
+	ID              uint                                  `json:"id"`
+	EmployeeID      uint                                  `json:"employee_id"`
+	Year            int                                   `json:"year"`
+	Month           int                                   `json:"month"`
+	DocumentType    string                                `json:"document_type"`
+	NoteType        string                                `json:"note_type,omitempty"`
+	Number          string                                `json:"number"`
+	CUNE            string                                `json:"cune"`
+	PredecessorID   *uint                                 `json:"predecessor_id,omitempty"`
+	PredecessorCUNE string                                `json:"predecessor_cune,omitempty"`
+	PayrollIDs      string                                `json:"payroll_ids"`
+	TotalEarnings   float64                               `json:"total_earnings"`
+	TotalDeductions float64                               `json:"total_deductions"`
+	NetAmount       float64                               `json:"net_amount"`
+	Status          string                                `json:"status"`
+	IssuedAt        string                                `json:"issued_at"`
+	Submissions     []ElectronicPayrollSubmissionResponse `json:"submissions,omitempty"`
+}
+
+// ElectronicPayrollSubmissionResponse representa una transmisión a la DIAN
+type ElectronicPayrollSubmissionResponse struct {
+	ID          uint   `json:"id"`
+	DocumentID  uint   `json:"document_id"`
+	Client      string `json:"client"`
+	TrackID     string `json:"track_id,omitempty"`
+	Status      string `json:"status"`
+	Messages    string `json:"messages,omitempty"`
+	SubmittedBy uint   `json:"submitted_by"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ElectronicPayrollGenerationResponse resume la generación de un mes
+type ElectronicPayrollGenerationResponse struct {
+	Year       int                                 `json:"year"`
+	Month      int                                 `json:"month"`
+	Documents  []ElectronicPayrollDocumentResponse `json:"documents"`
+	Unchanged  int                                 `json:"unchanged"`
+	Superseded int                                 `json:"superseded"`
+}
+
+// ToElectronicPayrollDocumentResponse convierte domain.ElectronicPayrollDocument
+func ToElectronicPayrollDocumentResponse(d *domain.ElectronicPayrollDocument) ElectronicPayrollDocumentResponse {
+	resp := ElectronicPayrollDocumentResponse{
+		ID:              d.ID,
+		EmployeeID:      d.EmployeeID,
+		Year:            d.Year,
+		Month:           d.Month,
+		DocumentType:    d.DocumentType,
+		NoteType:        d.NoteType,
+		Number:          d.Number,
+		CUNE:            d.CUNE,
+		PredecessorID:   d.PredecessorID,
+		PayrollIDs:      d.PayrollIDs,
+		TotalEarnings:   d.TotalEarnings,
+		TotalDeductions: d.TotalDeductions,
+		NetAmount:       d.NetAmount,
+		Status:          d.Status,
+		IssuedAt:        d.IssuedAt.Format("2006-01-02 15:04:05"),
+	}
+	if d.Predecessor != nil {
+		resp.PredecessorCUNE = d.Predecessor.CUNE
+	}
+	for i := range d.Submissions {
+		resp.Submissions = append(resp.Submissions, ToElectronicPayrollSubmissionResponse(&d.Submissions[i]))
+	}
+	return resp
+}
+
+// ToElectronicPayrollDocumentResponses convierte una lista de documentos
+func ToElectronicPayrollDocumentResponses(documents []domain.ElectronicPayrollDocument) []ElectronicPayrollDocumentResponse {
+	resp := make([]ElectronicPayrollDocumentResponse, len(documents))
+	for i := range documents {
+		resp[i] = ToElectronicPayrollDocumentResponse(&documents[i])
+	}
+	return resp
+}
+
+// ToElectronicPayrollSubmissionResponse convierte domain.ElectronicPayrollSubmission
+func ToElectronicPayrollSubmissionResponse(s *domain.ElectronicPayrollSubmission) ElectronicPayrollSubmissionResponse {
+	return ElectronicPayrollSubmissionResponse{
+		ID:          s.ID,
+		DocumentID:  s.DocumentID,
+		Client:      s.Client,
+		TrackID:     s.TrackID,
+		Status:      s.Status,
+		Messages:    s.Messages,
+		SubmittedBy: s.SubmittedBy,
+		CreatedAt:   s.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ToElectronicPayrollGenerationResponse convierte service.ElectronicPayrollGeneration
+func ToElectronicPayrollGenerationResponse(g *service.ElectronicPayrollGeneration) *ElectronicPayrollGenerationResponse {
+	return &ElectronicPayrollGenerationResponse{
+		Year:       g.Year,
+		Month:      g.Month,
+		Documents:  ToElectronicPayrollDocumentResponses(g.Documents),
+		Unchanged:  g.Unchanged,
+		Superseded: g.Superseded,
+	}
+}
@@ -13,33 +13,51 @@ import (
 
 // CreateEmployeeRequest representa el DTO para crear empleados
 type CreateEmployeeRequest struct {
-	UserID       uint  `json:"user_id" binding:"required,min=1"`
-	DepartmentID *uint `json:"department_id,omitempty"`
-	PositionID   *uint `json:"position_id,omitempty"`
-	IsActive     *bool `json:"is_active,omitempty"`
+	UserID          uint   `json:"user_id" binding:"required,min=1"`
+	DepartmentID    *uint  `json:"department_id,omitempty"`
+	PositionID      *uint  `json:"position_id,omitempty"`
+	ManagerID       *uint  `json:"manager_id,omitempty"`
+	IsActive        *bool  `json:"is_active,omitempty"`
+	HealthFundCode  string `json:"health_fund_code,omitempty" binding:"max=6"`
+	PensionFundCode string `json:"pension_fund_code,omitempty" binding:"max=6"`
+	RiskClass       int    `json:"risk_class,omitempty" binding:"omitempty,min=1,max=5"`
 }
 
-// UpdateEmployeeRequest representa el DTO para actualizar empleados
+// UpdateEmployeeRequest representa el DTO para actualizar empleados. manager_id 0 quita el jefe
 type UpdateEmployeeRequest struct {
-	DepartmentID *uint `json:"department_id,omitempty"`
-	PositionID   *uint `json:"position_id,omitempty"`
-	IsActive     *bool `json:"is_active,omitempty"`
+	DepartmentID    *uint   `json:"department_id,omitempty"`
+	PositionID      *uint   `json:"position_id,omitempty"`
+	ManagerID       *uint   `json:"manager_id,omitempty"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+	HealthFundCode  *string `json:"health_fund_code,omitempty" binding:"omitempty,max=6"`
+	PensionFundCode *string `json:"pension_fund_code,omitempty" binding:"omitempty,max=6"`
+	RiskClass       *int    `json:"risk_class,omitempty" binding:"omitempty,min=1,max=5"`
+}
+
+// SetManagerRequest asigna el jefe directo del empleado; null lo quita
+type SetManagerRequest struct {
+	ManagerID *uint `json:"manager_id" binding:"omitempty,min=1"`
 }
 
 // EmployeeResponse representa la respuesta de un empleado
 type EmployeeResponse struct {
-	ID           uint                `json:"id"`
-	TenantID     uint                `json:"tenant_id"`
-	UserID       uint                `json:"user_id"`
-	DepartmentID *uint               `json:"department_id,omitempty"`
-	PositionID   *uint               `json:"position_id,omitempty"`
-	IsActive     bool                `json:"is_active"`
-	CreatedAt    time.Time           `json:"created_at"`
-	UpdatedAt    time.Time           `json:"updated_at"`
-	User         *UserResponse       `json:"user,omitempty"`
-	Department   *DepartmentResponse `json:"department,omitempty"`
-	Position     *PositionResponse   `json:"position,omitempty"`
-	Contracts    []ContractResponse  `json:"contracts,omitempty"`
+	ID           uint  `json:"id"`
+	TenantID     uint  `json:"tenant_id"`
+	UserID       uint  `json:"user_id"`
+	DepartmentID *uint `json:"department_id,omitempty"`
+	PositionID   *uint `json:"position_id,omitempty"`
+	ManagerID    *uint `json:"manager_id,omitempty"`
+	IsActive     bool  `json:"is_active"`
+	// Afiliaciones a seguridad social
+	HealthFundCode  string              `json:"health_fund_code,omitempty"`
+	PensionFundCode string              `json:"pension_fund_code,omitempty"`
+	RiskClass       int                 `json:"risk_class,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	User            *UserResponse       `json:"user,omitempty"`
+	Department      *DepartmentResponse `json:"department,omitempty"`
+	Position        *PositionResponse   `json:"position,omitempty"`
+	Contracts       []ContractResponse  `json:"contracts,omitempty"`
 }
 
 // UserResponse representa la respuesta de usuario embebido
@@ -56,16 +74,25 @@ type UserResponse struct {
 
 // DepartmentResponse representa la respuesta de departamento
 type DepartmentResponse struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
-	Code string `json:"code"`
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	Code           string `json:"code"`
+	ParentID       *uint  `json:"parent_id,omitempty"`
+	HeadEmployeeID *uint  `json:"head_employee_id,omitempty"`
+	IsActive       bool   `json:"is_active"`
 }
 
 // PositionResponse representa la respuesta de posición
 type PositionResponse struct {
-	ID           uint   `json:"id"`
-	NamePosition string `json:"name_position"`
-	Description  string `json:"description"`
+	ID             uint    `json:"id"`
+	DepartmentID   uint    `json:"department_id,omitempty"`
+	NamePosition   string  `json:"name_position"`
+	Description    string  `json:"description"`
+	SalaryMin      float64 `json:"salary_min,omitempty"`
+	SalaryMid      float64 `json:"salary_mid,omitempty"`
+	SalaryMax      float64 `json:"salary_max,omitempty"`
+	SalaryCurrency string  `json:"salary_currency,omitempty"`
+	IsActive       bool    `json:"is_active"`
 }
 
 // ContractResponse representa la respuesta de contrato
@@ -83,15 +110,19 @@ type ContractResponse struct {
 	PensionContribution float64 `json:"pension_contribution"`
 	TransportAllowance  float64 `json:"transport_allowance"`
 	HousingAllowance    float64 `json:"housing_allowance"`
+	SocialSecurityProof string  `json:"social_security_proof,omitempty"`
+	BandOverrideReason  string  `json:"band_override_reason,omitempty"`
+	BandOverrideBy      uint    `json:"band_override_by,omitempty"`
 }
 
 // ========================================
 // Employee Contract DTOs
 // ========================================
 
-// CreateEmployeeContractRequest representa el DTO para crear contratos
+// CreateEmployeeContractRequest representa el DTO para crear contratos. En las rutas
+// /employees/:id/contracts el empleado se toma del path
 type CreateEmployeeContractRequest struct {
-	EmployeeID          uint    `json:"employee_id" binding:"required,min=1"`
+	EmployeeID          uint    `json:"employee_id" binding:"omitempty,min=1"`
 	ContractTypeID      *uint   `json:"contract_type_id,omitempty"`
 	BaseSalary          float64 `json:"base_salary" binding:"required,min=0"`
 	Currency            string  `json:"currency" binding:"required,len=3"`
@@ -103,6 +134,39 @@ type CreateEmployeeContractRequest struct {
 	PensionContribution float64 `json:"pension_contribution" binding:"min=0,max=100"`
 	TransportAllowance  float64 `json:"transport_allowance" binding:"min=0"`
 	HousingAllowance    float64 `json:"housing_allowance" binding:"min=0"`
+	SocialSecurityProof string  `json:"social_security_proof,omitempty" binding:"max=100"`
+	BandOverrideReason  string  `json:"band_override_reason,omitempty" binding:"max=255"`
+}
+
+// AmendContractRequest representa el DTO para modificar el contrato activo. Solo se
+// cambian los campos enviados; effective_date vacío aplica el cambio desde hoy
+type AmendContractRequest struct {
+	EffectiveDate       string   `json:"effective_date"`
+	ContractTypeID      *uint    `json:"contract_type_id,omitempty"`
+	BaseSalary          *float64 `json:"base_salary,omitempty" binding:"omitempty,min=0"`
+	Currency            *string  `json:"currency,omitempty" binding:"omitempty,len=3"`
+	EndDate             *string  `json:"end_date,omitempty"`
+	WorkHoursPerDay     *float64 `json:"work_hours_per_day,omitempty" binding:"omitempty,min=0,max=24"`
+	WorkDaysPerWeek     *float64 `json:"work_days_per_week,omitempty" binding:"omitempty,min=0,max=7"`
+	HealthContribution  *float64 `json:"health_contribution,omitempty" binding:"omitempty,min=0,max=100"`
+	PensionContribution *float64 `json:"pension_contribution,omitempty" binding:"omitempty,min=0,max=100"`
+	TransportAllowance  *float64 `json:"transport_allowance,omitempty" binding:"omitempty,min=0"`
+	HousingAllowance    *float64 `json:"housing_allowance,omitempty" binding:"omitempty,min=0"`
+	SocialSecurityProof *string  `json:"social_security_proof,omitempty" binding:"omitempty,max=100"`
+	BandOverrideReason  *string  `json:"band_override_reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// RenewContractRequest representa el DTO para prorrogar un contrato a término fijo
+type RenewContractRequest struct {
+	EndDate            string   `json:"end_date" binding:"required"`
+	BaseSalary         *float64 `json:"base_salary,omitempty" binding:"omitempty,min=0"`
+	BandOverrideReason *string  `json:"band_override_reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// ContractTimelineResponse representa la historia de contratos de un empleado
+type ContractTimelineResponse struct {
+	EmployeeID uint               `json:"employee_id"`
+	Contracts  []ContractResponse `json:"contracts"`
 }
 
 // ========================================
@@ -121,6 +185,10 @@ func (r *CreateEmployeeRequest) ToDomain() *domain.Employee {
 	if r.PositionID != nil {
 		emp.PositionID = *r.PositionID
 	}
+	emp.ManagerID = r.ManagerID
+	emp.HealthFundCode = strings.TrimSpace(r.HealthFundCode)
+	emp.PensionFundCode = strings.TrimSpace(r.PensionFundCode)
+	emp.RiskClass = max(r.RiskClass, 1)
 	if r.IsActive != nil {
 		emp.IsActive = *r.IsActive
 	} else {
@@ -133,14 +201,18 @@ func (r *CreateEmployeeRequest) ToDomain() *domain.Employee {
 // ToResponse convierte domain.Employee a EmployeeResponse
 func ToEmployeeResponse(emp *domain.Employee) *EmployeeResponse {
 	resp := &EmployeeResponse{
-		ID:           emp.ID,
-		TenantID:     emp.TenantID,
-		UserID:       emp.UserID,
-		DepartmentID: nil,
-		PositionID:   nil,
-		IsActive:     emp.IsActive,
-		CreatedAt:    emp.CreatedAt,
-		UpdatedAt:    emp.UpdatedAt,
+		ID:              emp.ID,
+		TenantID:        emp.TenantID,
+		UserID:          emp.UserID,
+		DepartmentID:    nil,
+		PositionID:      nil,
+		ManagerID:       emp.ManagerID,
+		IsActive:        emp.IsActive,
+		HealthFundCode:  emp.HealthFundCode,
+		PensionFundCode: emp.PensionFundCode,
+		RiskClass:       emp.RiskClass,
+		CreatedAt:       emp.CreatedAt,
+		UpdatedAt:       emp.UpdatedAt,
 	}
 
 	if emp.DepartmentID > 0 {
@@ -166,53 +238,60 @@ func ToEmployeeResponse(emp *domain.Employee) *EmployeeResponse {
 
 	// Include department if preloaded
 	if emp.Department.ID > 0 {
-		resp.Department = &DepartmentResponse{
-			ID:   emp.Department.ID,
-			Name: emp.Department.Name,
-			Code: emp.Department.Code,
-		}
+		resp.Department = ToDepartmentResponse(&emp.Department)
 	}
 
 	// Include position if preloaded
 	if emp.Position.ID > 0 {
-		resp.Position = &PositionResponse{
-			ID:           emp.Position.ID,
-			NamePosition: emp.Position.NamePosition,
-			Description:  emp.Position.Description,
-		}
+		resp.Position = ToPositionResponse(&emp.Position)
 	}
 
 	// Include contracts if preloaded
 	if len(emp.Contracts) > 0 {
 		resp.Contracts = make([]ContractResponse, len(emp.Contracts))
-		for i, c := range emp.Contracts {
-			contractResp := ContractResponse{
-				ID:                  c.ID,
-				BaseSalary:          c.BaseSalary,
-				Currency:            c.Currency,
-				StartDate:           c.StartDate.Format("2006-01-02"),
-				IsActive:            c.IsActive,
-				WorkHoursPerDay:     c.WorkHoursPerDay,
-				WorkDaysPerWeek:     c.WorkDaysPerWeek,
-				HealthContribution:  c.HealthContribution,
-				PensionContribution: c.PensionContribution,
-				TransportAllowance:  c.TransportAllowance,
-				HousingAllowance:    c.HousingAllowance,
-			}
-			if c.ContractTypeID > 0 {
-				contractResp.ContractTypeID = c.ContractTypeID
-			}
-			if c.EndDate != nil {
-				endDate := c.EndDate.Format("2006-01-02")
-				contractResp.EndDate = &endDate
-			}
-			resp.Contracts[i] = contractResp
+		for i := range emp.Contracts {
+			resp.Contracts[i] = ToContractResponse(&emp.Contracts[i])
 		}
 	}
 
 	return resp
 }
 
+// ToContractResponse convierte domain.EmployeeContract a ContractResponse
+func ToContractResponse(c *domain.EmployeeContract) ContractResponse {
+	resp := ContractResponse{
+		ID:                  c.ID,
+		ContractTypeID:      c.ContractTypeID,
+		BaseSalary:          c.BaseSalary,
+		Currency:            c.Currency,
+		StartDate:           c.StartDate.Format("2006-01-02"),
+		IsActive:            c.IsActive,
+		WorkHoursPerDay:     c.WorkHoursPerDay,
+		WorkDaysPerWeek:     c.WorkDaysPerWeek,
+		HealthContribution:  c.HealthContribution,
+		PensionContribution: c.PensionContribution,
+		TransportAllowance:  c.TransportAllowance,
+		HousingAllowance:    c.HousingAllowance,
+		SocialSecurityProof: c.SocialSecurityProof,
+		BandOverrideReason:  c.BandOverrideReason,
+		BandOverrideBy:      c.BandOverrideBy,
+	}
+	if c.EndDate != nil {
+		endDate := c.EndDate.Format("2006-01-02")
+		resp.EndDate = &endDate
+	}
+	return resp
+}
+
+// ToContractTimelineResponse convierte la historia de contratos a su respuesta
+func ToContractTimelineResponse(employeeID uint, contracts []domain.EmployeeContract) *ContractTimelineResponse {
+	resp := &ContractTimelineResponse{EmployeeID: employeeID, Contracts: make([]ContractResponse, len(contracts))}
+	for i := range contracts {
+		resp.Contracts[i] = ToContractResponse(&contracts[i])
+	}
+	return resp
+}
+
 // Sanitize limpia los datos del DTO
 func (r *CreateEmployeeRequest) Sanitize() {
 	// Currently no fields to sanitize
@@ -236,6 +315,8 @@ func (r *CreateEmployeeContractRequest) ToDomain() (*domain.EmployeeContract, er
 		PensionContribution: r.PensionContribution,
 		TransportAllowance:  r.TransportAllowance,
 		HousingAllowance:    r.HousingAllowance,
+		SocialSecurityProof: strings.TrimSpace(r.SocialSecurityProof),
+		BandOverrideReason:  strings.TrimSpace(r.BandOverrideReason),
 		IsActive:            true,
 	}
 
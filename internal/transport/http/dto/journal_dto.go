@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/ledger"
+)
+
+// ========================================
+// Journal DTOs
+// ========================================
+
+// JournalResponse representa el asiento contable de nómina para importar en contabilidad
+type JournalResponse struct {
+	Reference   string        `json:"reference"`
+	Date        string        `json:"date"`
+	Description string        `json:"description"`
+	TotalDebit  float64       `json:"total_debit"`
+	TotalCredit float64       `json:"total_credit"`
+	Balanced    bool          `json:"balanced"`
+	Lines       []ledger.Line `json:"lines"`
+}
+
+// ToJournalResponse convierte el asiento generado
+func ToJournalResponse(entry *ledger.Entry) *JournalResponse {
+	debit, credit := entry.Totals()
+	return &JournalResponse{
+		Reference:   entry.Reference,
+		Date:        entry.Date.Format("2006-01-02"),
+		Description: entry.Description,
+		TotalDebit:  debit,
+		TotalCredit: credit,
+		Balanced:    entry.Balanced(),
+		Lines:       entry.Lines,
+	}
+}
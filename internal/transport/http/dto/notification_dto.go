@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/arrase21/crm-users/internal/domain"
+
+// NotificationResponse es el estado de entrega de una notificación
+type NotificationResponse struct {
+	ID            uint    `json:"id"`
+	PayrollID     uint    `json:"payroll_id"`
+	EmployeeID    uint    `json:"employee_id"`
+	Kind          string  `json:"kind"`
+	Recipient     string  `json:"recipient"`
+	Status        string  `json:"status"`
+	Attempts      int     `json:"attempts"`
+	NextAttemptAt string  `json:"next_attempt_at,omitempty"`
+	LastError     string  `json:"last_error,omitempty"`
+	SentAt        *string `json:"sent_at,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// ToNotificationResponses convierte una lista de notificaciones
+func ToNotificationResponses(notifications []domain.NotificationOutbox) []NotificationResponse {
+	resp := make([]NotificationResponse, len(notifications))
+	for i, n := range notifications {
+		resp[i] = NotificationResponse{
+			ID:         n.ID,
+			PayrollID:  n.PayrollID,
+			EmployeeID: n.EmployeeID,
+			Kind:       n.Kind,
+			Recipient:  n.Recipient,
+			Status:     n.Status,
+			Attempts:   n.Attempts,
+			LastError:  n.LastError,
+			SentAt:     formatTimestamp(n.SentAt),
+			CreatedAt:  n.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if n.Status == domain.NotificationPending {
+			resp[i].NextAttemptAt = n.NextAttemptAt.Format("2006-01-02 15:04:05")
+		}
+	}
+	return resp
+}
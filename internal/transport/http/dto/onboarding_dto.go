@@ -0,0 +1,180 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// Onboarding DTOs
+// ========================================
+
+// OnboardingRequest representa el alta completa de un empleado. Los campos no llevan
+// binding: el servicio valida todo el payload y reporta todos los errores juntos
+type OnboardingRequest struct {
+	DryRun      bool                          `json:"dry_run"`
+	User        OnboardingUserRequest         `json:"user"`
+	Employee    OnboardingEmployeeRequest     `json:"employee"`
+	Contract    OnboardingContractRequest     `json:"contract"`
+	Concepts    []OnboardingConceptRequest    `json:"concepts,omitempty"`
+	BankAccount *OnboardingBankAccountRequest `json:"bank_account,omitempty"`
+}
+
+// OnboardingUserRequest son los datos personales del nuevo usuario
+type OnboardingUserRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Dni       string `json:"dni"`
+	Gender    string `json:"gender"`
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	BirthDay  string `json:"birth_day"`
+}
+
+// OnboardingEmployeeRequest ubica al empleado en la estructura organizacional
+type OnboardingEmployeeRequest struct {
+	DepartmentID uint  `json:"department_id"`
+	PositionID   uint  `json:"position_id"`
+	ManagerID    *uint `json:"manager_id,omitempty"`
+}
+
+// OnboardingContractRequest es el contrato inicial del empleado
+type OnboardingContractRequest struct {
+	ContractTypeID      uint    `json:"contract_type_id"`
+	BaseSalary          float64 `json:"base_salary"`
+	Currency            string  `json:"currency"`
+	StartDate           string  `json:"start_date"`
+	EndDate             *string `json:"end_date,omitempty"`
+	WorkHoursPerDay     float64 `json:"work_hours_per_day"`
+	WorkDaysPerWeek     float64 `json:"work_days_per_week"`
+	HealthContribution  float64 `json:"health_contribution"`
+	PensionContribution float64 `json:"pension_contribution"`
+	TransportAllowance  float64 `json:"transport_allowance"`
+	HousingAllowance    float64 `json:"housing_allowance"`
+	SocialSecurityProof string  `json:"social_security_proof,omitempty"`
+	BandOverrideReason  string  `json:"band_override_reason,omitempty"`
+}
+
+// OnboardingConceptRequest asigna un concepto con monto fijo mensual o porcentaje
+type OnboardingConceptRequest struct {
+	Code       string  `json:"code"`
+	Amount     float64 `json:"amount,omitempty"`
+	Percentage float64 `json:"percentage,omitempty"`
+}
+
+// OnboardingBankAccountRequest es la cuenta donde se paga la nómina
+type OnboardingBankAccountRequest struct {
+	BankName      string `json:"bank_name"`
+	AccountType   string `json:"account_type"`
+	AccountNumber string `json:"account_number"`
+}
+
+// OnboardingResponse representa los registros creados o, en dry-run, los que se crearían
+type OnboardingResponse struct {
+	DryRun      bool                         `json:"dry_run"`
+	Employee    *EmployeeResponse            `json:"employee"`
+	Contract    ContractResponse             `json:"contract"`
+	Concepts    []EmployeeConceptResponse    `json:"concepts"`
+	BankAccount *EmployeeBankAccountResponse `json:"bank_account,omitempty"`
+}
+
+// EmployeeConceptResponse representa un concepto asignado al empleado
+type EmployeeConceptResponse struct {
+	ID         uint    `json:"id"`
+	ConceptID  uint    `json:"concept_id"`
+	Code       string  `json:"code"`
+	Amount     float64 `json:"amount,omitempty"`
+	Percentage float64 `json:"percentage,omitempty"`
+}
+
+// ToDomain convierte los datos del usuario; la fecha inválida se reporta como error de campo
+func (r *OnboardingUserRequest) ToDomain() (domain.User, []ValidationError) {
+	user := domain.User{
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Dni:       r.Dni,
+		Gender:    strings.ToUpper(strings.TrimSpace(r.Gender)),
+		Phone:     r.Phone,
+		Email:     r.Email,
+	}
+	var errs []ValidationError
+	if r.BirthDay != "" {
+		birthDay, err := time.Parse("2006-01-02", r.BirthDay)
+		if err != nil {
+			errs = append(errs, dateValidationError("user.birth_day"))
+		}
+		user.BirthDay = birthDay
+	}
+	return user, errs
+}
+
+// ToDomain convierte los datos del empleado
+func (r *OnboardingEmployeeRequest) ToDomain() domain.Employee {
+	return domain.Employee{
+		DepartmentID: r.DepartmentID,
+		PositionID:   r.PositionID,
+		ManagerID:    r.ManagerID,
+		IsActive:     true,
+	}
+}
+
+// ToDomain convierte el contrato inicial; las fechas inválidas se reportan como errores de campo
+func (r *OnboardingContractRequest) ToDomain() (domain.EmployeeContract, []ValidationError) {
+	contract := domain.EmployeeContract{
+		ContractTypeID:      r.ContractTypeID,
+		BaseSalary:          r.BaseSalary,
+		Currency:            r.Currency,
+		WorkHoursPerDay:     r.WorkHoursPerDay,
+		WorkDaysPerWeek:     r.WorkDaysPerWeek,
+		HealthContribution:  r.HealthContribution,
+		PensionContribution: r.PensionContribution,
+		TransportAllowance:  r.TransportAllowance,
+		HousingAllowance:    r.HousingAllowance,
+		SocialSecurityProof: strings.TrimSpace(r.SocialSecurityProof),
+		BandOverrideReason:  strings.TrimSpace(r.BandOverrideReason),
+		IsActive:            true,
+	}
+	var errs []ValidationError
+	if r.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", r.StartDate)
+		if err != nil {
+			errs = append(errs, dateValidationError("contract.start_date"))
+		}
+		contract.StartDate = startDate
+	}
+	if r.EndDate != nil && *r.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", *r.EndDate)
+		if err != nil {
+			errs = append(errs, dateValidationError("contract.end_date"))
+		} else {
+			contract.EndDate = &endDate
+		}
+	}
+	return contract, errs
+}
+
+// ToDomain convierte la cuenta de pago
+func (r *OnboardingBankAccountRequest) ToDomain() *domain.EmployeeBankAccount {
+	return &domain.EmployeeBankAccount{
+		BankName:      r.BankName,
+		AccountType:   r.AccountType,
+		AccountNumber: r.AccountNumber,
+	}
+}
+
+func dateValidationError(field string) ValidationError {
+	return ValidationError{Field: field, Message: "date must use the format YYYY-MM-DD", Tag: "datetime"}
+}
+
+// ToEmployeeConceptResponse convierte domain.EmployeeConcept a EmployeeConceptResponse
+func ToEmployeeConceptResponse(ec *domain.EmployeeConcept) EmployeeConceptResponse {
+	return EmployeeConceptResponse{
+		ID:         ec.ID,
+		ConceptID:  ec.ConceptID,
+		Code:       ec.Concept.Code,
+		Amount:     ec.Amount,
+		Percentage: ec.Percentage,
+	}
+}
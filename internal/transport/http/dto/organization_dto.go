@@ -0,0 +1,182 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// Department & Position DTOs
+// ========================================
+
+// CreateDepartmentRequest representa el DTO para crear departamentos
+type CreateDepartmentRequest struct {
+	Name           string `json:"name" binding:"required,max=100"`
+	Code           string `json:"code" binding:"required,max=20"`
+	ParentID       *uint  `json:"parent_id,omitempty" binding:"omitempty,min=1"`
+	HeadEmployeeID *uint  `json:"head_employee_id,omitempty" binding:"omitempty,min=1"`
+}
+
+// UpdateDepartmentRequest representa el DTO para actualizar departamentos. parent_id 0
+// convierte el departamento en raíz
+type UpdateDepartmentRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Code     *string `json:"code,omitempty" binding:"omitempty,max=20"`
+	ParentID *uint   `json:"parent_id,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// SetDepartmentHeadRequest asigna el jefe del departamento; null lo quita
+type SetDepartmentHeadRequest struct {
+	EmployeeID *uint `json:"employee_id" binding:"omitempty,min=1"`
+}
+
+// CreatePositionRequest representa el DTO para crear cargos
+type CreatePositionRequest struct {
+	DepartmentID uint        `json:"department_id" binding:"required,min=1"`
+	NamePosition string      `json:"name_position" binding:"required,max=100"`
+	Description  string      `json:"description" binding:"max=255"`
+	SalaryBand   *SalaryBand `json:"salary_band,omitempty"`
+}
+
+// UpdatePositionRequest representa el DTO para actualizar cargos
+type UpdatePositionRequest struct {
+	DepartmentID *uint   `json:"department_id,omitempty" binding:"omitempty,min=1"`
+	NamePosition *string `json:"name_position,omitempty" binding:"omitempty,max=100"`
+	Description  *string `json:"description,omitempty" binding:"omitempty,max=255"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+	// Banda salarial; si se envía reemplaza la banda completa y con todos los valores en 0 la elimina
+	SalaryBand *SalaryBand `json:"salary_band,omitempty"`
+}
+
+// SalaryBand representa la banda salarial de un cargo; salary_mid vacío toma el promedio
+type SalaryBand struct {
+	SalaryMin      float64 `json:"salary_min" binding:"min=0"`
+	SalaryMid      float64 `json:"salary_mid" binding:"min=0"`
+	SalaryMax      float64 `json:"salary_max" binding:"min=0"`
+	SalaryCurrency string  `json:"salary_currency" binding:"omitempty,len=3"`
+}
+
+// DepartmentTreeNode representa un nodo del árbol organizacional
+type DepartmentTreeNode struct {
+	DepartmentResponse
+	Headcount      int64                `json:"headcount"`
+	TotalHeadcount int64                `json:"total_headcount"`
+	Children       []DepartmentTreeNode `json:"children"`
+}
+
+// OrgChartNode representa un empleado del organigrama con sus subordinados
+type OrgChartNode struct {
+	EmployeeID    uint           `json:"employee_id"`
+	Name          string         `json:"name"`
+	DepartmentID  uint           `json:"department_id,omitempty"`
+	PositionID    uint           `json:"position_id,omitempty"`
+	ManagerID     *uint          `json:"manager_id,omitempty"`
+	DirectReports int            `json:"direct_reports"`
+	TotalReports  int            `json:"total_reports"`
+	Reports       []OrgChartNode `json:"reports"`
+}
+
+// ========================================
+// DTO Conversion Methods
+// ========================================
+
+// ToDomain convierte CreateDepartmentRequest a domain.Department
+func (r *CreateDepartmentRequest) ToDomain() *domain.Department {
+	return &domain.Department{
+		Name:           strings.TrimSpace(r.Name),
+		Code:           strings.ToUpper(strings.TrimSpace(r.Code)),
+		ParentID:       r.ParentID,
+		HeadEmployeeID: r.HeadEmployeeID,
+		IsActive:       true,
+	}
+}
+
+// Apply aplica al departamento solo los campos enviados
+func (r *UpdateDepartmentRequest) Apply(department *domain.Department) {
+	if r.Name != nil {
+		department.Name = *r.Name
+	}
+	if r.Code != nil {
+		department.Code = *r.Code
+	}
+	if r.ParentID != nil {
+		if *r.ParentID == 0 {
+			department.ParentID = nil
+		} else {
+			parentID := *r.ParentID
+			department.ParentID = &parentID
+		}
+	}
+	if r.IsActive != nil {
+		department.IsActive = *r.IsActive
+	}
+}
+
+// ToDomain convierte CreatePositionRequest a domain.Position
+func (r *CreatePositionRequest) ToDomain() *domain.Position {
+	position := &domain.Position{
+		DepartmentID: r.DepartmentID,
+		NamePosition: strings.TrimSpace(r.NamePosition),
+		Description:  strings.TrimSpace(r.Description),
+		IsActive:     true,
+	}
+	if r.SalaryBand != nil {
+		r.SalaryBand.apply(position)
+	}
+	return position
+}
+
+// Apply aplica al cargo solo los campos enviados
+func (r *UpdatePositionRequest) Apply(position *domain.Position) {
+	if r.DepartmentID != nil {
+		position.DepartmentID = *r.DepartmentID
+	}
+	if r.NamePosition != nil {
+		position.NamePosition = *r.NamePosition
+	}
+	if r.Description != nil {
+		position.Description = strings.TrimSpace(*r.Description)
+	}
+	if r.IsActive != nil {
+		position.IsActive = *r.IsActive
+	}
+	if r.SalaryBand != nil {
+		r.SalaryBand.apply(position)
+	}
+}
+
+func (b SalaryBand) apply(position *domain.Position) {
+	position.SalaryMin = b.SalaryMin
+	position.SalaryMid = b.SalaryMid
+	position.SalaryMax = b.SalaryMax
+	position.SalaryCurrency = strings.ToUpper(strings.TrimSpace(b.SalaryCurrency))
+}
+
+// ToDepartmentResponse convierte domain.Department a DepartmentResponse
+func ToDepartmentResponse(d *domain.Department) *DepartmentResponse {
+	return &DepartmentResponse{
+		ID:             d.ID,
+		Name:           d.Name,
+		Code:           d.Code,
+		ParentID:       d.ParentID,
+		HeadEmployeeID: d.HeadEmployeeID,
+		IsActive:       d.IsActive,
+	}
+}
+
+// ToPositionResponse convierte domain.Position a PositionResponse
+func ToPositionResponse(p *domain.Position) *PositionResponse {
+	return &PositionResponse{
+		ID:             p.ID,
+		DepartmentID:   p.DepartmentID,
+		NamePosition:   p.NamePosition,
+		Description:    p.Description,
+		SalaryMin:      p.SalaryMin,
+		SalaryMid:      p.SalaryMid,
+		SalaryMax:      p.SalaryMax,
+		SalaryCurrency: p.SalaryCurrency,
+		IsActive:       p.IsActive,
+	}
+}
@@ -22,6 +22,10 @@ type CreatePayrollConceptRequest struct {
 	EmployerPart float64 `json:"employer_part,omitempty" binding:"min=0,max=100"`
 	IsMandatory  *bool   `json:"is_mandatory,omitempty"`
 	IsActive     *bool   `json:"is_active,omitempty"`
+	// Cuentas contables para el asiento de nómina
+	DebitAccount  string `json:"debit_account,omitempty" binding:"max=20"`
+	CreditAccount string `json:"credit_account,omitempty" binding:"max=20"`
+	CostCenter    string `json:"cost_center,omitempty" binding:"max=20"`
 }
 
 // UpdatePayrollConceptRequest representa el DTO para actualizar conceptos
@@ -34,23 +38,30 @@ type UpdatePayrollConceptRequest struct {
 	EmployerPart *float64 `json:"employer_part,omitempty"`
 	IsMandatory  *bool    `json:"is_mandatory,omitempty"`
 	IsActive     *bool    `json:"is_active,omitempty"`
+	// Cuentas contables; un valor vacío vuelve a la cuenta por defecto
+	DebitAccount  *string `json:"debit_account,omitempty" binding:"omitempty,max=20"`
+	CreditAccount *string `json:"credit_account,omitempty" binding:"omitempty,max=20"`
+	CostCenter    *string `json:"cost_center,omitempty" binding:"omitempty,max=20"`
 }
 
 // PayrollConceptResponse representa la respuesta de un concepto de nómina
 type PayrollConceptResponse struct {
-	ID           uint      `json:"id"`
-	TenantID     uint      `json:"tenant_id"`
-	Code         string    `json:"code"`
-	Name         string    `json:"name"`
-	Type         string    `json:"type"`
-	Description  string    `json:"description,omitempty"`
-	Percentage   float64   `json:"percentage"`
-	EmployeePart float64   `json:"employee_part"`
-	EmployerPart float64   `json:"employer_part"`
-	IsMandatory  bool      `json:"is_mandatory"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uint      `json:"id"`
+	TenantID      uint      `json:"tenant_id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description,omitempty"`
+	Percentage    float64   `json:"percentage"`
+	EmployeePart  float64   `json:"employee_part"`
+	EmployerPart  float64   `json:"employer_part"`
+	IsMandatory   bool      `json:"is_mandatory"`
+	IsActive      bool      `json:"is_active"`
+	DebitAccount  string    `json:"debit_account,omitempty"`
+	CreditAccount string    `json:"credit_account,omitempty"`
+	CostCenter    string    `json:"cost_center,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // ========================================
@@ -60,13 +71,16 @@ type PayrollConceptResponse struct {
 // ToDomain convierte CreatePayrollConceptRequest a domain.PayrollConcept
 func (r *CreatePayrollConceptRequest) ToDomain() *domain.PayrollConcept {
 	concept := &domain.PayrollConcept{
-		Code:         strings.ToUpper(strings.TrimSpace(r.Code)),
-		Name:         strings.TrimSpace(r.Name),
-		Type:         r.Type,
-		Description:  r.Description,
-		Percentage:   r.Percentage,
-		EmployeePart: r.EmployeePart,
-		EmployerPart: r.EmployerPart,
+		Code:          strings.ToUpper(strings.TrimSpace(r.Code)),
+		Name:          strings.TrimSpace(r.Name),
+		Type:          r.Type,
+		Description:   r.Description,
+		Percentage:    r.Percentage,
+		EmployeePart:  r.EmployeePart,
+		EmployerPart:  r.EmployerPart,
+		DebitAccount:  strings.TrimSpace(r.DebitAccount),
+		CreditAccount: strings.TrimSpace(r.CreditAccount),
+		CostCenter:    strings.TrimSpace(r.CostCenter),
 	}
 
 	if r.IsMandatory != nil {
@@ -87,19 +101,22 @@ func (r *CreatePayrollConceptRequest) ToDomain() *domain.PayrollConcept {
 // ToResponse convierte domain.PayrollConcept a PayrollConceptResponse
 func ToPayrollConceptResponse(concept *domain.PayrollConcept) *PayrollConceptResponse {
 	resp := &PayrollConceptResponse{
-		ID:           concept.ID,
-		TenantID:     concept.TenantID,
-		Code:         concept.Code,
-		Name:         concept.Name,
-		Type:         concept.Type,
-		Description:  concept.Description,
-		Percentage:   concept.Percentage,
-		EmployeePart: concept.EmployeePart,
-		EmployerPart: concept.EmployerPart,
-		IsMandatory:  concept.IsMandatory,
-		IsActive:     concept.IsActive,
-		CreatedAt:    concept.CreatedAt,
-		UpdatedAt:    concept.UpdatedAt,
+		ID:            concept.ID,
+		TenantID:      concept.TenantID,
+		Code:          concept.Code,
+		Name:          concept.Name,
+		Type:          concept.Type,
+		Description:   concept.Description,
+		Percentage:    concept.Percentage,
+		EmployeePart:  concept.EmployeePart,
+		EmployerPart:  concept.EmployerPart,
+		IsMandatory:   concept.IsMandatory,
+		IsActive:      concept.IsActive,
+		DebitAccount:  concept.DebitAccount,
+		CreditAccount: concept.CreditAccount,
+		CostCenter:    concept.CostCenter,
+		CreatedAt:     concept.CreatedAt,
+		UpdatedAt:     concept.UpdatedAt,
 	}
 	return resp
 }
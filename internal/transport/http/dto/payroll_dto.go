@@ -39,6 +39,8 @@ type PayrollResponse struct {
 	TotalDeductions float64               `json:"total_deductions"`
 	NetAmount       float64               `json:"net_amount"`
 	Status          string                `json:"status"`
+	Kind            string                `json:"kind"`
+	OriginalID      *uint                 `json:"original_payroll_id,omitempty"`
 	Items           []PayrollItemResponse `json:"items"`
 	CreatedAt       string                `json:"created_at"`
 	UpdatedAt       string                `json:"updated_at"`
@@ -80,6 +82,8 @@ func ToCalculatedPayrollResponse(calc *service.CalculatedPayroll) *CalculatedPay
 		TotalDeductions: pr.TotalDeductions,
 		NetAmount:       pr.NetAmount,
 		Status:          pr.Status,
+		Kind:            pr.Kind,
+		OriginalID:      pr.OriginalPayrollID,
 		Items:           items,
 		CreatedAt:       pr.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       pr.UpdatedAt.Format(time.RFC3339),
@@ -120,6 +124,8 @@ func ToPayrollResponse(payroll *domain.Payroll, employeeName string) *PayrollRes
 		TotalDeductions: payroll.TotalDeductions,
 		NetAmount:       payroll.NetAmount,
 		Status:          payroll.Status,
+		Kind:            payroll.Kind,
+		OriginalID:      payroll.OriginalPayrollID,
 		Items:           items,
 		CreatedAt:       payroll.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       payroll.UpdatedAt.Format(time.RFC3339),
@@ -131,3 +137,115 @@ type SavePayrollRequest struct {
 	CalculatePayrollRequest
 	Confirm bool `json:"confirm"`
 }
+
+// PaymentResponse representa un pago de nómina con la copia de la cuenta de destino
+type PaymentResponse struct {
+	ID            uint    `json:"id"`
+	PayrollID     uint    `json:"payroll_id"`
+	Method        string  `json:"method"`
+	BankAccountID *uint   `json:"bank_account_id,omitempty"`
+	BankName      string  `json:"bank_name,omitempty"`
+	AccountType   string  `json:"account_type,omitempty"`
+	AccountNumber string  `json:"account_number,omitempty"`
+	Amount        float64 `json:"amount"`
+	Status        string  `json:"status"`
+	ProviderRef   string  `json:"provider_ref,omitempty"`
+	FailureReason string  `json:"failure_reason,omitempty"`
+	PaidAt        string  `json:"paid_at"`
+	SentAt        *string `json:"sent_at,omitempty"`
+	SettledAt     *string `json:"settled_at,omitempty"`
+}
+
+// ToPaymentResponse convierte domain.Payment a PaymentResponse
+func ToPaymentResponse(p *domain.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:            p.ID,
+		PayrollID:     p.PayrollID,
+		Method:        p.Method,
+		BankAccountID: p.BankAccountID,
+		BankName:      p.BankName,
+		AccountType:   p.AccountType,
+		AccountNumber: p.AccountNumber,
+		Amount:        p.Amount,
+		Status:        p.Status,
+		ProviderRef:   p.ProviderRef,
+		FailureReason: p.FailureReason,
+		PaidAt:        p.PaidAt.Format("2006-01-02 15:04:05"),
+		SentAt:        formatTimestamp(p.SentAt),
+		SettledAt:     formatTimestamp(p.SettledAt),
+	}
+}
+
+// PaymentCallbackRequest es la notificación del proveedor sobre un pago enviado
+type PaymentCallbackRequest struct {
+	Reference     string `json:"reference" binding:"required"`
+	Status        string `json:"status" binding:"required,oneof=sent completed failed"`
+	FailureReason string `json:"failure_reason"`
+}
+
+// UpdatePaymentStatusRequest confirma o rechaza un pago a mano
+type UpdatePaymentStatusRequest struct {
+	Status        string `json:"status" binding:"required,oneof=sent completed failed"`
+	FailureReason string `json:"failure_reason"`
+}
+
+func formatTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02 15:04:05")
+	return &s
+}
+
+// RegisterPaymentsRequest representa uno o varios pagos sobre una nómina. Un pago sin
+// amount toma el saldo pendiente
+type RegisterPaymentsRequest struct {
+	Payments []PaymentPartRequest `json:"payments" binding:"required,min=1,dive"`
+}
+
+// PaymentPartRequest representa un pago con su método y, opcionalmente, la cuenta de destino
+type PaymentPartRequest struct {
+	Method        string  `json:"method" binding:"required,max=30"`
+	Amount        float64 `json:"amount" binding:"min=0"`
+	BankAccountID *uint   `json:"bank_account_id,omitempty" binding:"omitempty,min=1"`
+}
+
+// PayrollPaymentsResponse representa los pagos de una nómina con el saldo pendiente
+type PayrollPaymentsResponse struct {
+	PayrollID  uint              `json:"payroll_id"`
+	Status     string            `json:"status"`
+	NetAmount  float64           `json:"net_amount"`
+	PaidAmount float64           `json:"paid_amount"`
+	Balance    float64           `json:"balance"`
+	Payments   []PaymentResponse `json:"payments"`
+}
+
+// ToPaymentParts convierte el request a las partes del servicio
+func (r *RegisterPaymentsRequest) ToPaymentParts() []service.PaymentPart {
+	parts := make([]service.PaymentPart, len(r.Payments))
+	for i, p := range r.Payments {
+		parts[i] = service.PaymentPart{Method: p.Method, Amount: p.Amount, BankAccountID: p.BankAccountID}
+	}
+	return parts
+}
+
+// ToPaymentResponses convierte una lista de pagos
+func ToPaymentResponses(payments []domain.Payment) []PaymentResponse {
+	resp := make([]PaymentResponse, len(payments))
+	for i := range payments {
+		resp[i] = ToPaymentResponse(&payments[i])
+	}
+	return resp
+}
+
+// ToPayrollPaymentsResponse convierte service.PayrollPayments a PayrollPaymentsResponse
+func ToPayrollPaymentsResponse(p *service.PayrollPayments) *PayrollPaymentsResponse {
+	return &PayrollPaymentsResponse{
+		PayrollID:  p.PayrollID,
+		Status:     p.Status,
+		NetAmount:  p.NetAmount,
+		PaidAmount: p.PaidAmount,
+		Balance:    p.Balance,
+		Payments:   ToPaymentResponses(p.Payments),
+	}
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/domain"
+)
+
+// ========================================
+// Payslip DTOs
+// ========================================
+
+// PayslipTemplateRequest representa los textos y el color del desprendible del tenant
+type PayslipTemplateRequest struct {
+	CompanyName               string `json:"company_name" binding:"max=150"`
+	CompanyNIT                string `json:"company_nit" binding:"max=30"`
+	CompanyAddress            string `json:"company_address" binding:"max=255"`
+	CompanyPhone              string `json:"company_phone" binding:"max=30"`
+	Title                     string `json:"title" binding:"max=100"`
+	FooterText                string `json:"footer_text" binding:"max=500"`
+	PrimaryColor              string `json:"primary_color" binding:"omitempty,hexcolor"`
+	ShowEmployerContributions *bool  `json:"show_employer_contributions,omitempty"`
+}
+
+// PayslipTemplateResponse representa la plantilla; el logo se descarga aparte
+type PayslipTemplateResponse struct {
+	CompanyName               string `json:"company_name"`
+	CompanyNIT                string `json:"company_nit"`
+	CompanyAddress            string `json:"company_address"`
+	CompanyPhone              string `json:"company_phone"`
+	Title                     string `json:"title"`
+	FooterText                string `json:"footer_text"`
+	PrimaryColor              string `json:"primary_color"`
+	ShowEmployerContributions bool   `json:"show_employer_contributions"`
+	HasLogo                   bool   `json:"has_logo"`
+	LogoContentType           string `json:"logo_content_type,omitempty"`
+}
+
+// ToDomain convierte PayslipTemplateRequest a domain.PayslipTemplate
+func (r *PayslipTemplateRequest) ToDomain() *domain.PayslipTemplate {
+	template := &domain.PayslipTemplate{
+		CompanyName:               r.CompanyName,
+		CompanyNIT:                r.CompanyNIT,
+		CompanyAddress:            r.CompanyAddress,
+		CompanyPhone:              r.CompanyPhone,
+		Title:                     r.Title,
+		FooterText:                r.FooterText,
+		PrimaryColor:              r.PrimaryColor,
+		ShowEmployerContributions: true,
+	}
+	if r.ShowEmployerContributions != nil {
+		template.ShowEmployerContributions = *r.ShowEmployerContributions
+	}
+	if template.Title == "" {
+		template.Title = "Comprobante de pago de nómina"
+	}
+	return template
+}
+
+// ToPayslipTemplateResponse convierte domain.PayslipTemplate a PayslipTemplateResponse
+func ToPayslipTemplateResponse(t *domain.PayslipTemplate) *PayslipTemplateResponse {
+	return &PayslipTemplateResponse{
+		CompanyName:               t.CompanyName,
+		CompanyNIT:                t.CompanyNIT,
+		CompanyAddress:            t.CompanyAddress,
+		CompanyPhone:              t.CompanyPhone,
+		Title:                     t.Title,
+		FooterText:                t.FooterText,
+		PrimaryColor:              t.PrimaryColor,
+		ShowEmployerContributions: t.ShowEmployerContributions,
+		HasLogo:                   len(t.Logo) > 0,
+		LogoContentType:           t.LogoContentType,
+	}
+}
@@ -0,0 +1,155 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/pila"
+)
+
+// ========================================
+// Absence DTOs
+// ========================================
+
+// CreateAbsenceRequest registra una ausencia del empleado
+type CreateAbsenceRequest struct {
+	Kind      string `json:"kind" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Reference string `json:"reference,omitempty" binding:"max=30"`
+	Notes     string `json:"notes,omitempty" binding:"max=255"`
+}
+
+// AbsenceResponse representa una ausencia
+type AbsenceResponse struct {
+	ID         uint   `json:"id"`
+	EmployeeID uint   `json:"employee_id"`
+	Kind       string `json:"kind"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Reference  string `json:"reference,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	CreatedBy  uint   `json:"created_by,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// ToDomain convierte la solicitud con las fechas ya validadas
+func (r *CreateAbsenceRequest) ToDomain(start, end time.Time) *domain.EmployeeAbsence {
+	return &domain.EmployeeAbsence{
+		Kind:      r.Kind,
+		StartDate: start,
+		EndDate:   end,
+		Reference: r.Reference,
+		Notes:     r.Notes,
+	}
+}
+
+// ToAbsenceResponse convierte domain.EmployeeAbsence a AbsenceResponse
+func ToAbsenceResponse(a *domain.EmployeeAbsence) *AbsenceResponse {
+	return &AbsenceResponse{
+		ID:         a.ID,
+		EmployeeID: a.EmployeeID,
+		Kind:       a.Kind,
+		StartDate:  a.StartDate.Format("2006-01-02"),
+		EndDate:    a.EndDate.Format("2006-01-02"),
+		Reference:  a.Reference,
+		Notes:      a.Notes,
+		CreatedBy:  a.CreatedBy,
+		CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ========================================
+// PILA DTOs
+// ========================================
+
+// PILALineResponse resume la liquidación de un cotizante
+type PILALineResponse struct {
+	Sequence        int      `json:"sequence"`
+	EmployeeID      uint     `json:"employee_id"`
+	DocumentNumber  string   `json:"document_number"`
+	Name            string   `json:"name"`
+	ContributorType string   `json:"contributor_type"`
+	Novelties       []string `json:"novelties,omitempty"`
+	HealthDays      int      `json:"health_days"`
+	RiskDays        int      `json:"risk_days"`
+	IBC             float64  `json:"ibc"`
+	PensionValue    float64  `json:"pension_value"`
+	SolidarityValue float64  `json:"solidarity_value"`
+	HealthValue     float64  `json:"health_value"`
+	RiskValue       float64  `json:"risk_value"`
+	CCFValue        float64  `json:"ccf_value"`
+	SENAValue       float64  `json:"sena_value"`
+	ICBFValue       float64  `json:"icbf_value"`
+}
+
+// PILAResponse resume la planilla de un mes con sus errores por línea
+type PILAResponse struct {
+	Period            string             `json:"period"`
+	Employees         int                `json:"employees"`
+	PayrollTotal      float64            `json:"payroll_total"`
+	ContributionTotal float64            `json:"contribution_total"`
+	Valid             bool               `json:"valid"`
+	Errors            []pila.LineError   `json:"errors"`
+	Lines             []PILALineResponse `json:"lines"`
+}
+
+// ToPILAResponse convierte la planilla generada
+func ToPILAResponse(file *pila.File) *PILAResponse {
+	resp := &PILAResponse{
+		Period:            file.Header.Period.Format("2006-01"),
+		Employees:         file.Header.Employees,
+		PayrollTotal:      file.Header.PayrollTotal,
+		ContributionTotal: file.Total(),
+		Valid:             len(file.Errors) == 0,
+		Errors:            file.Errors,
+		Lines:             make([]PILALineResponse, len(file.Lines)),
+	}
+	if resp.Errors == nil {
+		resp.Errors = []pila.LineError{}
+	}
+	for i, l := range file.Lines {
+		resp.Lines[i] = PILALineResponse{
+			Sequence:        l.Sequence,
+			EmployeeID:      l.EmployeeID,
+			DocumentNumber:  l.DocumentNumber,
+			Name:            joinNonEmpty(l.FirstName, l.OtherNames, l.FirstSurname, l.SecondSurname),
+			ContributorType: l.ContributorType,
+			Novelties:       pilaNovelties(&l),
+			HealthDays:      l.HealthDays,
+			RiskDays:        l.RiskDays,
+			IBC:             l.IBCHealth,
+			PensionValue:    l.PensionValue,
+			SolidarityValue: l.SolidarityValue + l.SubsistenceValue,
+			HealthValue:     l.HealthValue,
+			RiskValue:       l.RiskValue,
+			CCFValue:        l.CCFValue,
+			SENAValue:       l.SENAValue,
+			ICBFValue:       l.ICBFValue,
+		}
+	}
+	return resp
+}
+
+// pilaNovelties lista las siglas de las novedades reportadas en la línea
+func pilaNovelties(l *pila.Line) []string {
+	var novelties []string
+	for _, n := range []struct {
+		code string
+		set  bool
+	}{
+		{"ING", l.HireDate != nil}, {"RET", l.RetirementDate != nil}, {"VSP", l.SalaryChangeDate != nil},
+		{"SLN", l.UnpaidLeave != nil}, {"IGE", l.SickLeave != nil}, {"LMA", l.Maternity != nil},
+		{"VAC", l.Vacation != nil}, {"LR", l.PaidLeave != nil}, {"IRL", l.WorkAccident != nil},
+	} {
+		if n.set {
+			novelties = append(novelties, n.code)
+		}
+	}
+	return novelties
+}
+
+func joinNonEmpty(parts ...string) string {
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+)
+
+// ========================================
+// Reconciliation DTOs
+// ========================================
+
+// ResolveStatementLineRequest asocia una excepción del extracto a un pago
+type ResolveStatementLineRequest struct {
+	PaymentID uint   `json:"payment_id" binding:"required"`
+	Note      string `json:"note" binding:"required,max=255"`
+}
+
+// IgnoreStatementLineRequest descarta una excepción del extracto con su justificación
+type IgnoreStatementLineRequest struct {
+	Note string `json:"note" binding:"required,max=255"`
+}
+
+// BankStatementResponse representa un extracto importado con sus totales
+type BankStatementResponse struct {
+	ID             uint   `json:"id"`
+	Format         string `json:"format"`
+	FileName       string `json:"file_name"`
+	Hash           string `json:"hash"`
+	Reference      string `json:"reference,omitempty"`
+	AccountNumber  string `json:"account_number,omitempty"`
+	LineCount      int    `json:"line_count"`
+	MatchedCount   int    `json:"matched_count"`
+	ExceptionCount int    `json:"exception_count"`
+	ImportedBy     uint   `json:"imported_by"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// BankStatementLineResponse representa una línea del extracto con su conciliación
+type BankStatementLineResponse struct {
+	ID            uint    `json:"id"`
+	LineNumber    int     `json:"line_number"`
+	BookingDate   *string `json:"booking_date,omitempty"`
+	Amount        float64 `json:"amount"`
+	Debit         bool    `json:"debit"`
+	AccountNumber string  `json:"account_number,omitempty"`
+	Reference     string  `json:"reference,omitempty"`
+	Description   string  `json:"description,omitempty"`
+	Status        string  `json:"status"`
+	PaymentID     *uint   `json:"payment_id,omitempty"`
+	Difference    float64 `json:"difference"`
+	Note          string  `json:"note,omitempty"`
+	ResolvedBy    uint    `json:"resolved_by,omitempty"`
+	ResolvedAt    *string `json:"resolved_at,omitempty"`
+}
+
+// ReconciliationReportResponse es el reporte de conciliación de un extracto
+type ReconciliationReportResponse struct {
+	Statement        *BankStatementResponse      `json:"statement"`
+	MatchedAmount    float64                     `json:"matched_amount"`
+	UnmatchedAmount  float64                     `json:"unmatched_amount"`
+	DifferenceAmount float64                     `json:"difference_amount"`
+	Exceptions       []BankStatementLineResponse `json:"exceptions"`
+	Lines            []BankStatementLineResponse `json:"lines"`
+}
+
+// ToBankStatementResponse convierte domain.BankStatement a BankStatementResponse
+func ToBankStatementResponse(s *domain.BankStatement) *BankStatementResponse {
+	return &BankStatementResponse{
+		ID:             s.ID,
+		Format:         s.Format,
+		FileName:       s.FileName,
+		Hash:           s.Hash,
+		Reference:      s.Reference,
+		AccountNumber:  s.AccountNumber,
+		LineCount:      s.LineCount,
+		MatchedCount:   s.MatchedCount,
+		ExceptionCount: s.ExceptionCount,
+		ImportedBy:     s.ImportedBy,
+		CreatedAt:      s.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ToBankStatementLineResponse convierte domain.BankStatementLine a BankStatementLineResponse
+func ToBankStatementLineResponse(l *domain.BankStatementLine) BankStatementLineResponse {
+	resp := BankStatementLineResponse{
+		ID:            l.ID,
+		LineNumber:    l.LineNumber,
+		Amount:        l.Amount,
+		Debit:         l.Debit,
+		AccountNumber: l.AccountNumber,
+		Reference:     l.Reference,
+		Description:   l.Description,
+		Status:        l.Status,
+		PaymentID:     l.PaymentID,
+		Difference:    l.Difference,
+		Note:          l.Note,
+		ResolvedBy:    l.ResolvedBy,
+		ResolvedAt:    formatTimestamp(l.ResolvedAt),
+	}
+	if l.BookingDate != nil {
+		date := l.BookingDate.Format("2006-01-02")
+		resp.BookingDate = &date
+	}
+	return resp
+}
+
+func toBankStatementLineResponses(lines []domain.BankStatementLine) []BankStatementLineResponse {
+	resp := make([]BankStatementLineResponse, len(lines))
+	for i := range lines {
+		resp[i] = ToBankStatementLineResponse(&lines[i])
+	}
+	return resp
+}
+
+// ToReconciliationReportResponse convierte service.ReconciliationReport
+func ToReconciliationReportResponse(r *service.ReconciliationReport) *ReconciliationReportResponse {
+	return &ReconciliationReportResponse{
+		Statement:        ToBankStatementResponse(r.Statement),
+		MatchedAmount:    r.MatchedAmount,
+		UnmatchedAmount:  r.UnmatchedAmount,
+		DifferenceAmount: r.DifferenceAmount,
+		Exceptions:       toBankStatementLineResponses(r.Exceptions),
+		Lines:            toBankStatementLineResponses(r.Statement.Lines),
+	}
+}
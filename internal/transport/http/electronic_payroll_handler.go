@@ -0,0 +1,123 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// ElectronicPayrollHandler maneja los documentos de nómina electrónica para la DIAN
+type ElectronicPayrollHandler struct {
+	svc *service.ElectronicPayrollService
+}
+
+func NewElectronicPayrollHandler(svc *service.ElectronicPayrollService) *ElectronicPayrollHandler {
+	return &ElectronicPayrollHandler{svc: svc}
+}
+
+// Generate genera los documentos y notas de ajuste del mes
+// POST /api/v1/electronic-payroll/generate
+func (h *ElectronicPayrollHandler) Generate(c *gin.Context) {
+	var req dto.GenerateElectronicPayrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.svc.Generate(c.Request.Context(), req.Year, req.Month)
+	if err != nil {
+		c.JSON(electronicPayrollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToElectronicPayrollGenerationResponse(result))
+}
+
+// List lista los documentos de un mes
+// GET /api/v1/electronic-payroll?year=2026&month=9
+func (h *ElectronicPayrollHandler) List(c *gin.Context) {
+	year, errYear := strconv.Atoi(c.Query("year"))
+	month, errMonth := strconv.Atoi(c.Query("month"))
+	if errYear != nil || errMonth != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year and month query params are required"})
+		return
+	}
+	documents, err := h.svc.ListByPeriod(c.Request.Context(), year, month)
+	if err != nil {
+		c.JSON(electronicPayrollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"documents": dto.ToElectronicPayrollDocumentResponses(documents)})
+}
+
+// GetByID retorna un documento con sus transmisiones
+// GET /api/v1/electronic-payroll/:id
+func (h *ElectronicPayrollHandler) GetByID(c *gin.Context) {
+	id, ok := electronicPayrollID(c)
+	if !ok {
+		return
+	}
+	document, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(electronicPayrollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToElectronicPayrollDocumentResponse(document))
+}
+
+// DownloadXML descarga el XML del documento
+// GET /api/v1/electronic-payroll/:id/xml
+func (h *ElectronicPayrollHandler) DownloadXML(c *gin.Context) {
+	id, ok := electronicPayrollID(c)
+	if !ok {
+		return
+	}
+	document, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(electronicPayrollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+document.Number+`.xml"`)
+	c.Data(http.StatusOK, "application/xml", document.XML)
+}
+
+// Submit transmite el documento a la DIAN
+// POST /api/v1/electronic-payroll/:id/submit
+func (h *ElectronicPayrollHandler) Submit(c *gin.Context) {
+	id, ok := electronicPayrollID(c)
+	if !ok {
+		return
+	}
+	submission, err := h.svc.Submit(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(electronicPayrollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToElectronicPayrollSubmissionResponse(submission))
+}
+
+func electronicPayrollID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func electronicPayrollErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrElectronicPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidElectronicPayrollPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrElectronicPayrollNotSubmittable):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrEmployerDataRequired), errors.Is(err, domain.ErrInvalidElectronicPayrollXML):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
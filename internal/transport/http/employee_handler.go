@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/arrase21/crm-users/internal/domain"
 	"github.com/arrase21/crm-users/internal/service"
@@ -148,11 +149,24 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 	if req.PositionID != nil {
 		existingEmployee.PositionID = *req.PositionID
 	}
+	if req.ManagerID != nil {
+		managerID := *req.ManagerID
+		existingEmployee.ManagerID = &managerID
+	}
 	if req.IsActive != nil {
 		existingEmployee.IsActive = *req.IsActive
 	}
+	if req.HealthFundCode != nil {
+		existingEmployee.HealthFundCode = strings.TrimSpace(*req.HealthFundCode)
+	}
+	if req.PensionFundCode != nil {
+		existingEmployee.PensionFundCode = strings.TrimSpace(*req.PensionFundCode)
+	}
+	if req.RiskClass != nil {
+		existingEmployee.RiskClass = *req.RiskClass
+	}
 	if err := h.svc.Update(c.Request.Context(), existingEmployee); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(employeeErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "employee updated"})
@@ -175,3 +189,73 @@ func (h *EmployeeHandler) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// SetManager asigna o quita el jefe directo del empleado
+// PUT /api/v1/employees/:id/manager
+func (h *EmployeeHandler) SetManager(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	var req dto.SetManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employee, err := h.svc.SetManager(c.Request.Context(), uint(id), req.ManagerID)
+	if err != nil {
+		c.JSON(employeeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToEmployeeResponse(employee))
+}
+
+// OrgChart retorna el árbol de reporte de los empleados activos visibles para el usuario
+// GET /api/v1/employees/org-chart?root_id=
+func (h *EmployeeHandler) OrgChart(c *gin.Context) {
+	rootID, err := strconv.ParseUint(c.DefaultQuery("root_id", "0"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid root_id"})
+		return
+	}
+
+	nodes, err := h.svc.OrgChart(c.Request.Context(), uint(rootID))
+	if err != nil {
+		c.JSON(employeeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"org_chart": orgChart(nodes)})
+}
+
+func orgChart(nodes []*service.OrgChartNode) []dto.OrgChartNode {
+	chart := make([]dto.OrgChartNode, len(nodes))
+	for i, node := range nodes {
+		emp := node.Employee
+		chart[i] = dto.OrgChartNode{
+			EmployeeID:    emp.ID,
+			Name:          strings.TrimSpace(emp.User.FirstName + " " + emp.User.LastName),
+			DepartmentID:  emp.DepartmentID,
+			PositionID:    emp.PositionID,
+			ManagerID:     emp.ManagerID,
+			DirectReports: node.DirectReports,
+			TotalReports:  node.TotalReports,
+			Reports:       orgChart(node.Reports),
+		}
+	}
+	return chart
+}
+
+func employeeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrManagerCycle), errors.Is(err, domain.ErrEmployeeInactive):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/mocks"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEmployeeRepo filtra por el scope del contexto igual que el repositorio gorm
+type stubEmployeeRepo struct {
+	domain.EmployeeRepo
+	employees []domain.Employee
+}
+
+func (r *stubEmployeeRepo) List(ctx context.Context, page, limit int) ([]domain.Employee, int64, error) {
+	scope, _ := ctx.Value(domain.EmployeeScopeKey).(*domain.EmployeeScope)
+	var out []domain.Employee
+	for _, emp := range r.employees {
+		if scope == nil || scope.Allows(emp.ID) {
+			out = append(out, emp)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+type stubUserRoleRepo struct {
+	domain.UserRoleRepo
+	roles []domain.Role
+}
+
+func (r *stubUserRoleRepo) GetUserRoles(ctx context.Context, userID uint) ([]domain.Role, error) {
+	return r.roles, nil
+}
+
+func newEmployeeListRouter(roles []domain.Role) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	repo := &stubEmployeeRepo{employees: []domain.Employee{
+		{ID: 1},
+		{ID: 2},
+	}}
+	permSvc := service.NewPermissionService(&stubUserRoleRepo{roles: roles}, mocks.NewMockRoleRepo())
+	handler := NewEmployeeHandler(service.NewEmployeeService(repo, service.NewDataScopeService(permSvc, repo)))
+
+	r := gin.New()
+	r.Use(middleware.TenantMiddleware(), middleware.ActorMiddleware())
+	r.GET("/employees", handler.List)
+	return r
+}
+
+func TestEmployeeHandler_List_Unscoped(t *testing.T) {
+	unscopedRole := domain.Role{
+		IsActive: true,
+		RolePermissions: []domain.RolePermission{{
+			Scope: domain.DataScopeAll,
+			Action: domain.PermissionAction{
+				Action:   "read",
+				IsActive: true,
+				Resource: domain.Permission{Name: domain.ResourceEmployees, IsActive: true},
+			},
+		}},
+	}
+	tests := []struct {
+		name   string
+		userID string
+		roles  []domain.Role
+	}{
+		{name: "without X-User-ID"},
+		{name: "actor without scoped roles", userID: "7", roles: []domain.Role{unscopedRole}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newEmployeeListRouter(tt.roles)
+			req := httptest.NewRequest(http.MethodGet, "/employees", nil)
+			req.Header.Set("X-Tenant-ID", "1")
+			if tt.userID != "" {
+				req.Header.Set("X-User-ID", tt.userID)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body struct {
+				Employees []json.RawMessage `json:"employees"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Len(t, body.Employees, 2)
+		})
+	}
+}
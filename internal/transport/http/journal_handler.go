@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/ledger"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// JournalHandler exporta el asiento contable de nómina
+type JournalHandler struct {
+	svc *service.JournalService
+}
+
+func NewJournalHandler(svc *service.JournalService) *JournalHandler {
+	return &JournalHandler{svc: svc}
+}
+
+// Preview retorna el asiento de un periodo o de una corrida de nóminas
+// GET /api/v1/journal?start=2026-09-01&end=2026-09-30
+// GET /api/v1/journal?payroll_ids=10,11,12
+func (h *JournalHandler) Preview(c *gin.Context) {
+	entry, ok := h.generate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToJournalResponse(entry))
+}
+
+// Download descarga el asiento como archivo CSV o JSON para importar en contabilidad
+// GET /api/v1/journal/file?start=2026-09-01&end=2026-09-30&format=csv
+func (h *JournalHandler) Download(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidJournalFormat.Error()})
+		return
+	}
+	entry, ok := h.generate(c)
+	if !ok {
+		return
+	}
+
+	var (
+		content     []byte
+		err         error
+		contentType = "text/csv"
+	)
+	if format == "csv" {
+		content, err = entry.CSV()
+	} else {
+		content, err = json.MarshalIndent(dto.ToJournalResponse(entry), "", "  ")
+		contentType = "application/json"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+entry.Reference+"."+format+`"`)
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// generate arma el asiento por corrida si llegan payroll_ids, o por periodo
+func (h *JournalHandler) generate(c *gin.Context) (*ledger.Entry, bool) {
+	ctx := c.Request.Context()
+	var (
+		entry *ledger.Entry
+		err   error
+	)
+	if raw := c.Query("payroll_ids"); raw != "" {
+		ids, parseErr := parseIDList(raw)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll_ids: " + parseErr.Error()})
+			return nil, false
+		}
+		entry, err = h.svc.ForPayrolls(ctx, ids)
+	} else {
+		start, errStart := parseDate(c.Query("start"))
+		end, errEnd := parseDate(c.Query("end"))
+		if errStart != nil || errEnd != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start and end query params (YYYY-MM-DD) or payroll_ids are required"})
+			return nil, false
+		}
+		entry, err = h.svc.ForPeriod(ctx, start, end)
+	}
+	if err != nil {
+		c.JSON(journalErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return entry, true
+}
+
+func journalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidJournalPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNoPayrollsForJournal), errors.Is(err, domain.ErrPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConceptAccountsMissing), errors.Is(err, domain.ErrJournalUnbalanced):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler expone el estado de entrega de las notificaciones de nómina
+type NotificationHandler struct {
+	svc *service.NotificationService
+}
+
+func NewNotificationHandler(svc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// ListByPayroll retorna el estado del envío del desprendible al empleado
+// GET /api/v1/payroll/:id/notifications
+func (h *NotificationHandler) ListByPayroll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+	notifications, err := h.svc.ListByPayroll(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notifications": dto.ToNotificationResponses(notifications)})
+}
+
+// RetryFailed vuelve a encolar los envíos fallidos de la nómina
+// POST /api/v1/payroll/:id/notifications/retry
+func (h *NotificationHandler) RetryFailed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+	count, err := h.svc.RetryFailed(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"requeued": count})
+}
+
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPayrollNotFound), errors.Is(err, domain.ErrNotificationNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
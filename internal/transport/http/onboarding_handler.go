@@ -0,0 +1,158 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// OnboardingHandler maneja el alta completa de empleados
+type OnboardingHandler struct {
+	svc *service.OnboardingService
+}
+
+func NewOnboardingHandler(svc *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{svc: svc}
+}
+
+// Onboard crea usuario, empleado, contrato, conceptos y cuenta de pago en una transacción.
+// Con dry_run (en el body o ?dry_run=true) solo valida y retorna lo que se crearía.
+// POST /api/v1/onboarding
+func (h *OnboardingHandler) Onboard(c *gin.Context) {
+	var req dto.OnboardingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	onboarding, parseErrs := onboardingRequest(&req)
+	if c.Query("dry_run") == "true" {
+		onboarding.DryRun = true
+	}
+	ctx := c.Request.Context()
+
+	// Con fechas inválidas no se crea nada, pero se valida el resto para reportar todo junto
+	if len(parseErrs) > 0 {
+		fields, err := h.svc.Validate(ctx, onboarding)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		respondOnboardingErrors(c, mergeOnboardingErrors(parseErrs, fields))
+		return
+	}
+
+	result, err := h.svc.Onboard(ctx, onboarding)
+	if err != nil {
+		var validationErr *service.OnboardingError
+		if errors.As(err, &validationErr) {
+			respondOnboardingErrors(c, mergeOnboardingErrors(nil, validationErr.Fields))
+			return
+		}
+		c.JSON(onboardingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if result.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, onboardingResponse(result))
+}
+
+// onboardingRequest convierte el DTO al request del servicio junto con los errores de fecha
+func onboardingRequest(req *dto.OnboardingRequest) (service.OnboardingRequest, []dto.ValidationError) {
+	user, errs := req.User.ToDomain()
+	contract, contractErrs := req.Contract.ToDomain()
+	errs = append(errs, contractErrs...)
+
+	onboarding := service.OnboardingRequest{
+		User:     user,
+		Employee: req.Employee.ToDomain(),
+		Contract: contract,
+		Concepts: make([]service.OnboardingConcept, len(req.Concepts)),
+		DryRun:   req.DryRun,
+	}
+	for i, concept := range req.Concepts {
+		onboarding.Concepts[i] = service.OnboardingConcept{
+			Code:       concept.Code,
+			Amount:     concept.Amount,
+			Percentage: concept.Percentage,
+		}
+	}
+	if req.BankAccount != nil {
+		onboarding.BankAccount = req.BankAccount.ToDomain()
+	}
+	return onboarding, errs
+}
+
+// mergeOnboardingErrors une los errores de formato con los del servicio; un campo con fecha
+// inválida no repite el error de "requerido" que el servicio reporta al verla vacía
+func mergeOnboardingErrors(parseErrs []dto.ValidationError, fields []service.FieldError) []dto.ValidationError {
+	seen := make(map[string]bool, len(parseErrs))
+	validations := append([]dto.ValidationError{}, parseErrs...)
+	for _, e := range parseErrs {
+		seen[e.Field] = true
+	}
+	for _, f := range fields {
+		if seen[f.Field] {
+			continue
+		}
+		validations = append(validations, dto.ValidationError{Field: f.Field, Message: f.Message, Tag: "onboarding"})
+	}
+	return validations
+}
+
+func respondOnboardingErrors(c *gin.Context, validations []dto.ValidationError) {
+	c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+		Error:       "validation_failed",
+		Message:     "Onboarding request has invalid fields",
+		Code:        "VALIDATION_ERROR",
+		Validations: validations,
+		Timestamp:   time.Now(),
+	})
+}
+
+func onboardingResponse(result *service.OnboardingResult) *dto.OnboardingResponse {
+	employee := *result.Employee
+	employee.User = *result.User
+	resp := &dto.OnboardingResponse{
+		DryRun:   result.DryRun,
+		Employee: dto.ToEmployeeResponse(&employee),
+		Contract: dto.ToContractResponse(result.Contract),
+		Concepts: make([]dto.EmployeeConceptResponse, len(result.Concepts)),
+	}
+	// En dry-run el usuario no tiene ID; igual se muestran sus datos
+	if resp.Employee.User == nil {
+		resp.Employee.User = &dto.UserResponse{
+			FirstName: result.User.FirstName,
+			LastName:  result.User.LastName,
+			Dni:       result.User.Dni,
+			Gender:    result.User.Gender,
+			Phone:     result.User.Phone,
+			Email:     result.User.Email,
+			BirthDay:  result.User.BirthDay.Format("2006-01-02"),
+		}
+	}
+	for i := range result.Concepts {
+		resp.Concepts[i] = dto.ToEmployeeConceptResponse(&result.Concepts[i])
+	}
+	if result.BankAccount != nil {
+		resp.BankAccount = dto.ToEmployeeBankAccountResponse(result.BankAccount)
+	}
+	return resp
+}
+
+func onboardingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDniAlreadyExist), errors.Is(err, domain.ErrEmailAlreadyExist),
+		errors.Is(err, domain.ErrPhoneAlreadyExist):
+		return http.StatusConflict
+	}
+	return contractErrorStatus(err)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/payout"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// PaymentHandler maneja el ciclo de vida de los pagos frente al proveedor
+type PaymentHandler struct {
+	svc *service.PaymentService
+}
+
+func NewPaymentHandler(svc *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{svc: svc}
+}
+
+// GetByID retorna un pago con su estado frente al proveedor
+// GET /api/v1/payments/:id
+func (h *PaymentHandler) GetByID(c *gin.Context) {
+	id, ok := paymentID(c)
+	if !ok {
+		return
+	}
+	payment, err := h.svc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(paymentLifecycleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPaymentResponse(payment))
+}
+
+// Sync envía las transferencias pendientes y consulta el estado de las enviadas
+// POST /api/v1/payments/sync
+func (h *PaymentHandler) Sync(c *gin.Context) {
+	result, err := h.svc.Sync(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Callback recibe la notificación del proveedor sobre un pago. El cuerpo debe venir firmado
+// con HMAC-SHA256 en el encabezado X-Payout-Signature
+// POST /api/v1/payments/callback
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.VerifyCallback(body, c.GetHeader(payout.SignatureHeader)); err != nil {
+		c.JSON(paymentLifecycleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	var req dto.PaymentCallbackRequest
+	if err := binding.JSON.BindBody(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payment, err := h.svc.ApplyCallback(c.Request.Context(), req.Reference, req.Status, req.FailureReason)
+	if err != nil {
+		c.JSON(paymentLifecycleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPaymentResponse(payment))
+}
+
+// UpdateStatus confirma o rechaza un pago a mano
+// PUT /api/v1/payments/:id/status
+func (h *PaymentHandler) UpdateStatus(c *gin.Context) {
+	id, ok := paymentID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdatePaymentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payment, err := h.svc.UpdateStatus(c.Request.Context(), id, req.Status, req.FailureReason)
+	if err != nil {
+		c.JSON(paymentLifecycleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPaymentResponse(payment))
+}
+
+func paymentID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func paymentLifecycleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound), errors.Is(err, domain.ErrPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPaymentStatus), errors.Is(err, service.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCallbackSignature):
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
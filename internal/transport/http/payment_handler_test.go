@@ -0,0 +1,42 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arrase21/crm-users/internal/payout"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentHandler_Callback_RejectsUnsignedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewPaymentHandler(service.NewPaymentService(nil, nil, nil, payout.NewFakeProvider(), "s3cret"))
+	r := gin.New()
+	r.POST("/payments/callback", handler.Callback)
+
+	body := []byte(`{"reference":"FAKE-000001","status":"completed"}`)
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{name: "without signature"},
+		{name: "tampered body", signature: payout.Sign("s3cret", []byte(`{"reference":"FAKE-000002","status":"completed"}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/payments/callback", bytes.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set(payout.SignatureHeader, tt.signature)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/arrase21/crm-users/internal/domain"
 	"github.com/arrase21/crm-users/internal/service"
@@ -193,6 +194,15 @@ func (h *PayrollConceptHandler) Update(c *gin.Context) {
 	if req.IsActive != nil {
 		existing.IsActive = *req.IsActive
 	}
+	if req.DebitAccount != nil {
+		existing.DebitAccount = strings.TrimSpace(*req.DebitAccount)
+	}
+	if req.CreditAccount != nil {
+		existing.CreditAccount = strings.TrimSpace(*req.CreditAccount)
+	}
+	if req.CostCenter != nil {
+		existing.CostCenter = strings.TrimSpace(*req.CostCenter)
+	}
 
 	if err := h.svc.Update(c.Request.Context(), existing); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -76,7 +77,11 @@ func (h *PayrollHandler) CalculateAndSave(c *gin.Context) {
 
 	result, err := h.calculatorSvc.CalculateAndSave(c.Request.Context(), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrPeriodClosed) || errors.Is(err, domain.ErrPayrollNotRecalculable) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -127,6 +132,10 @@ func (h *PayrollHandler) ListByEmployee(c *gin.Context) {
 
 	payrolls, err := h.payrollSvc.ListByEmployee(c.Request.Context(), uint(employeeID))
 	if err != nil {
+		if errors.Is(err, domain.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -159,7 +168,14 @@ func (h *PayrollHandler) Delete(c *gin.Context) {
 
 	err = h.payrollSvc.Delete(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrPayrollNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrPeriodClosed), errors.Is(err, domain.ErrPayrollNotDeletable):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/xlsx"
+	"github.com/gin-gonic/gin"
+)
+
+// PayrollRegisterHandler descarga el libro de nómina
+type PayrollRegisterHandler struct {
+	svc *service.PayrollRegisterService
+}
+
+func NewPayrollRegisterHandler(svc *service.PayrollRegisterService) *PayrollRegisterHandler {
+	return &PayrollRegisterHandler{svc: svc}
+}
+
+// Download escribe el libro de nómina por lotes directamente en la respuesta
+// GET /api/v1/payroll/register?period_start=2026-09-01&period_end=2026-09-30&format=xlsx
+// GET /api/v1/payroll/register?payroll_ids=10,11&department_ids=2&position_ids=5&status=paid,approved
+func (h *PayrollRegisterHandler) Download(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidRegisterFormat.Error()})
+		return
+	}
+
+	filter, ok := registerFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	register, err := h.svc.Prepare(ctx, filter)
+	if err != nil {
+		c.JSON(payrollRegisterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	fileName := "libro_nomina." + format
+	if len(filter.PayrollIDs) == 0 {
+		fileName = fmt.Sprintf("libro_nomina_%s_%s.%s",
+			filter.PeriodStart.Format("20060102"), filter.PeriodEnd.Format("20060102"), format)
+	}
+	contentType := "text/csv"
+	if format == "xlsx" {
+		contentType = xlsx.ContentType
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+	// La respuesta ya empezó: un error a mitad de la escritura solo se registra y corta el archivo
+	if err := h.svc.Write(ctx, register, format, c.Writer); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// registerFilter lee de la consulta las nóminas, el periodo, los departamentos, cargos y estados
+func registerFilter(c *gin.Context) (domain.PayrollRegisterFilter, bool) {
+	var filter domain.PayrollRegisterFilter
+	var err error
+	if filter.PayrollIDs, err = parseIDList(c.Query("payroll_ids")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll_ids: " + err.Error()})
+		return filter, false
+	}
+	if filter.DepartmentIDs, err = parseIDList(c.Query("department_ids")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department_ids: " + err.Error()})
+		return filter, false
+	}
+	if filter.PositionIDs, err = parseIDList(c.Query("position_ids")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid position_ids: " + err.Error()})
+		return filter, false
+	}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if len(filter.PayrollIDs) == 0 {
+		if filter.PeriodStart, err = parseDate(c.Query("period_start")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidRegisterFilter.Error()})
+			return filter, false
+		}
+		if filter.PeriodEnd, err = parseDate(c.Query("period_end")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidRegisterFilter.Error()})
+			return filter, false
+		}
+	}
+	return filter, true
+}
+
+// parseIDList convierte una lista separada por comas ("1,2,3") en ids
+func parseIDList(raw string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return nil, errors.New("invalid id " + part)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+func payrollRegisterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRegisterFilter), errors.Is(err, domain.ErrInvalidRegisterFormat):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNoPayrollsForRegister):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
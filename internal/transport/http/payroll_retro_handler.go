@@ -0,0 +1,107 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PayrollRetroHandler expone el motor de retroactivos y el desglose por periodo
+type PayrollRetroHandler struct {
+	retroSvc *service.PayrollRetroService
+}
+
+func NewPayrollRetroHandler(retroSvc *service.PayrollRetroService) *PayrollRetroHandler {
+	return &PayrollRetroHandler{retroSvc: retroSvc}
+}
+
+// RunRetroRequest representa el request de ejecución del motor retroactivo
+type RunRetroRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+	// Since es la fecha de vigencia del cambio (YYYY-MM-DD); vacío recalcula todos los periodos pagados
+	Since string `json:"since"`
+}
+
+// Run recalcula los periodos pagados del empleado desde since y genera los ajustes RETRO_*
+// POST /api/v1/payroll/employee/:employeeId/retro
+func (h *PayrollRetroHandler) Run(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("employeeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	var req RunRetroRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var since time.Time
+	if req.Since != "" {
+		parsed, err := parseDate(req.Since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since format, use YYYY-MM-DD"})
+			return
+		}
+		since = parsed
+	}
+
+	result, err := h.retroSvc.Run(c.Request.Context(), uint(employeeID), since, req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrEmployeeNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrPeriodClosed), errors.Is(err, domain.ErrPayrollNotRecalculable):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListByEmployee retorna el desglose por periodo de los retroactivos del empleado
+// GET /api/v1/payroll/employee/:employeeId/retro
+func (h *PayrollRetroHandler) ListByEmployee(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("employeeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	breakdown, err := h.retroSvc.ListByEmployee(c.Request.Context(), uint(employeeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"periods": breakdown})
+}
+
+// ListByPayroll retorna los retroactivos liquidados en una nómina, agrupados por periodo de origen
+// GET /api/v1/payroll/:id/retro
+func (h *PayrollRetroHandler) ListByPayroll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	breakdown, err := h.retroSvc.ListByPayroll(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"periods": breakdown})
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// PayrollReversalHandler maneja el reverso de nóminas pagadas
+type PayrollReversalHandler struct {
+	reversalSvc *service.PayrollReversalService
+}
+
+func NewPayrollReversalHandler(reversalSvc *service.PayrollReversalService) *PayrollReversalHandler {
+	return &PayrollReversalHandler{reversalSvc: reversalSvc}
+}
+
+// ReversePayrollRequest representa el request de reverso
+type ReversePayrollRequest struct {
+	Reason              string `json:"reason" binding:"required,max=255"`
+	GenerateReplacement bool   `json:"generate_replacement"`
+	PayDate             string `json:"pay_date"`
+}
+
+// Reverse reversa una nómina pagada
+// POST /api/v1/payroll/:id/reverse
+func (h *PayrollReversalHandler) Reverse(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	var req ReversePayrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payDate time.Time
+	if req.PayDate != "" {
+		payDate, err = parseDate(req.PayDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pay_date format, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	result, err := h.reversalSvc.Reverse(c.Request.Context(), service.ReversePayrollRequest{
+		PayrollID:           uint(id),
+		Reason:              req.Reason,
+		GenerateReplacement: req.GenerateReplacement,
+		PayDate:             payDate,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrPayrollNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrPayrollNotPaid), errors.Is(err, domain.ErrPayrollReversed),
+			errors.Is(err, domain.ErrPeriodClosed):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"message":  "payroll reversed",
+		"original": dto.ToPayrollResponse(result.Original, ""),
+		"reversal": dto.ToPayrollResponse(result.Reversal, ""),
+	}
+	if result.Replacement != nil {
+		response["replacement"] = dto.ToPayrollResponse(result.Replacement, "")
+		response["net_difference"] = result.NetDifference
+	}
+	c.JSON(http.StatusCreated, response)
+}
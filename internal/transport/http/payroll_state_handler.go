@@ -1,11 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/arrase21/crm-users/internal/domain"
 	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
 	"github.com/gin-gonic/gin"
 )
 
@@ -49,23 +52,23 @@ func (h *PayrollStateHandler) MarkAsPaid(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.stateSvc.MarkAsPaid(c.Request.Context(), uint(id), req.PaymentMethod)
+	payments, err := h.stateSvc.MarkAsPaid(c.Request.Context(), uint(id), req.PaymentMethod)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrInvalidStatusTransition ||
-			err == service.ErrPayrollNotInDraft {
-			status = http.StatusConflict
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	var amount float64
+	for _, p := range payments {
+		amount += p.Amount
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"message":        "payroll marked as paid successfully",
-		"payment_id":     payment.ID,
-		"payment_method": payment.Method,
-		"amount":         payment.Amount,
-		"paid_at":        payment.PaidAt.Format("2006-01-02 15:04:05"),
+		"payment_id":     payments[0].ID,
+		"payment_method": payments[0].Method,
+		"amount":         amount,
+		"paid_at":        payments[0].PaidAt.Format("2006-01-02 15:04:05"),
+		"payments":       dto.ToPaymentResponses(payments),
 	})
 }
 
@@ -81,20 +84,71 @@ func (h *PayrollStateHandler) RevertToDraft(c *gin.Context) {
 
 	err = h.stateSvc.RevertToDraft(c.Request.Context(), uint(id))
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == service.ErrInvalidStatusTransition {
-			status = http.StatusConflict
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		c.JSON(transitionErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "payroll reverted to draft"})
 }
 
-// GetPaymentInfo obtiene la información de pago de una nómina
-// GET /api/v1/payroll/:id/payment
-func (h *PayrollStateHandler) GetPaymentInfo(c *gin.Context) {
+// TransitionReasonRequest representa el motivo de una transición de estado
+type TransitionReasonRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// Approve aprueba una nómina calculada
+// POST /api/v1/payroll/:id/approve
+func (h *PayrollStateHandler) Approve(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	var req TransitionReasonRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.stateSvc.Approve(c.Request.Context(), uint(id), req.Reason); err != nil {
+		c.JSON(transitionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payroll approved"})
+}
+
+// Cancel anula una nómina no pagada
+// POST /api/v1/payroll/:id/cancel
+func (h *PayrollStateHandler) Cancel(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	var req TransitionReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	if err := h.stateSvc.Cancel(c.Request.Context(), uint(id), req.Reason); err != nil {
+		c.JSON(transitionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payroll cancelled"})
+}
+
+// GetHistory obtiene el historial de transiciones de una nómina
+// GET /api/v1/payroll/:id/history
+func (h *PayrollStateHandler) GetHistory(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -102,12 +156,135 @@ func (h *PayrollStateHandler) GetPaymentInfo(c *gin.Context) {
 		return
 	}
 
-	payment, err := h.stateSvc.GetPaymentHistory(c.Request.Context(), uint(id))
+	history, err := h.stateSvc.GetHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(transitionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if history == nil {
+		history = []domain.PayrollStatusHistory{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payroll_id": id, "history": history})
+}
+
+// GetTransitions obtiene la tabla de transiciones de estado del tenant
+// GET /api/v1/payroll/transitions
+func (h *PayrollStateHandler) GetTransitions(c *gin.Context) {
+	table, err := h.stateSvc.GetTransitions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"transitions": table})
+}
+
+// UpdateTransitionsRequest representa la tabla de transiciones (estado -> estados destino)
+type UpdateTransitionsRequest struct {
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// UpdateTransitions reemplaza la tabla de transiciones de estado del tenant
+// PUT /api/v1/payroll/transitions
+func (h *PayrollStateHandler) UpdateTransitions(c *gin.Context) {
+	var req UpdateTransitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.stateSvc.SetTransitions(c.Request.Context(), req.Transitions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payroll transitions updated"})
+}
+
+// transitionErrorStatus traduce errores de transición a códigos HTTP
+func transitionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrSameUserApproval):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrCalculatorUnknown):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrActorRequired):
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
+
+// RegisterPayments registra uno o varios pagos (anticipo, saldo o reparto entre cuentas y
+// métodos). La nómina queda partially_paid o paid según lo pagado
+// POST /api/v1/payroll/:id/payments
+func (h *PayrollStateHandler) RegisterPayments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	var req dto.RegisterPaymentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.stateSvc.RegisterPayments(ctx, uint(id), req.ToPaymentParts()); err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	summary, err := h.stateSvc.ListPayments(ctx, uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToPayrollPaymentsResponse(summary))
+}
+
+// ListPayments lista los pagos de una nómina con lo pagado y el saldo pendiente
+// GET /api/v1/payroll/:id/payments
+func (h *PayrollStateHandler) ListPayments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	summary, err := h.stateSvc.ListPayments(c.Request.Context(), uint(id))
 	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPayrollPaymentsResponse(summary))
+}
+
+// GetPaymentInfo obtiene el último pago de una nómina; se mantiene por compatibilidad con
+// los clientes anteriores a los pagos parciales, el detalle completo está en /payments
+// GET /api/v1/payroll/:id/payment
+func (h *PayrollStateHandler) GetPaymentInfo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+
+	summary, err := h.stateSvc.ListPayments(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if len(summary.Payments) == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "no payment record found"})
 		return
 	}
 
+	payment := summary.Payments[len(summary.Payments)-1]
 	c.JSON(http.StatusOK, gin.H{
 		"payment": gin.H{
 			"id":             payment.ID,
@@ -121,6 +298,39 @@ func (h *PayrollStateHandler) GetPaymentInfo(c *gin.Context) {
 	})
 }
 
+// ListEmployeePayments lista los pagos de todas las nóminas del empleado
+// GET /api/v1/payroll/employee/:employeeId/payments
+func (h *PayrollStateHandler) ListEmployeePayments(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("employeeId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	payments, err := h.stateSvc.ListEmployeePayments(c.Request.Context(), uint(employeeID))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"employee_id": employeeID, "payments": dto.ToPaymentResponses(payments)})
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPayrollNotFound), errors.Is(err, domain.ErrEmployeeNotFound),
+		errors.Is(err, domain.ErrBankAccountNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrPayrollNotInDraft),
+		errors.Is(err, domain.ErrPayrollAlreadyPaid):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNoBankAccount), errors.Is(err, domain.ErrNoPrimaryBankAccount),
+		errors.Is(err, domain.ErrInvalidPaymentSplit), errors.Is(err, domain.ErrInvalidPaymentAmount),
+		errors.Is(err, domain.ErrPaymentExceedsNetPay):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
 // BatchPayrollRequest representa el request para batch processing
 type BatchPayrollRequest struct {
 	PeriodStart string `json:"period_start" binding:"required"`
@@ -170,7 +380,11 @@ func (h *PayrollStateHandler) ProcessBatch(c *gin.Context) {
 
 	result, err := h.batchSvc.CalculatePeriodSummary(c.Request.Context(), batchReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrPeriodClosed) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -208,29 +422,48 @@ func (h *PayrollStateHandler) GetPayrollSummary(c *gin.Context) {
 
 	// Calcular resumen
 	var summary struct {
-		TotalCount      int     `json:"total_count"`
-		DraftCount      int     `json:"draft_count"`
-		CalculatedCount int     `json:"calculated_count"`
-		PaidCount       int     `json:"paid_count"`
-		TotalGross      float64 `json:"total_gross_amount"`
-		TotalDeductions float64 `json:"total_deductions"`
-		TotalNet        float64 `json:"total_net_amount"`
+		TotalCount         int     `json:"total_count"`
+		DraftCount         int     `json:"draft_count"`
+		CalculatedCount    int     `json:"calculated_count"`
+		ApprovedCount      int     `json:"approved_count"`
+		PartiallyPaidCount int     `json:"partially_paid_count"`
+		PaidCount          int     `json:"paid_count"`
+		CancelledCount     int     `json:"cancelled_count"`
+		TotalGross         float64 `json:"total_gross_amount"`
+		TotalDeductions    float64 `json:"total_deductions"`
+		TotalNet           float64 `json:"total_net_amount"`
+		// Aportes y provisiones del empleador; el costo total es devengado + aportes
+		TotalEmployerContributions float64 `json:"total_employer_contributions"`
+		TotalEmployerCost          float64 `json:"total_employer_cost"`
 	}
 
 	for _, p := range payrolls {
 		summary.TotalCount++
 		switch p.Status {
-		case "draft":
+		case domain.PayrollStatusDraft:
 			summary.DraftCount++
-		case "calculated":
+		case domain.PayrollStatusCalculated:
 			summary.CalculatedCount++
-		case "paid":
+		case domain.PayrollStatusApproved:
+			summary.ApprovedCount++
+		case domain.PayrollStatusPartiallyPaid:
+			summary.PartiallyPaidCount++
+		case domain.PayrollStatusPaid:
 			summary.PaidCount++
+		case domain.PayrollStatusCancelled:
+			summary.CancelledCount++
+			continue
 		}
 		summary.TotalGross += p.GrossAmount
 		summary.TotalDeductions += p.TotalDeductions
 		summary.TotalNet += p.NetAmount
+		for _, item := range p.Items {
+			if item.Type == domain.PayrollTypeEmployerContribution {
+				summary.TotalEmployerContributions += item.Amount
+			}
+		}
 	}
+	summary.TotalEmployerCost = summary.TotalGross + summary.TotalEmployerContributions
 
 	c.JSON(http.StatusOK, gin.H{
 		"period_start": periodStartStr,
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// PayslipHandler maneja el desprendible de nómina y su plantilla
+type PayslipHandler struct {
+	svc *service.PayslipService
+}
+
+func NewPayslipHandler(svc *service.PayslipService) *PayslipHandler {
+	return &PayslipHandler{svc: svc}
+}
+
+// Download genera el desprendible de la nómina en PDF
+// GET /api/v1/payroll/:id/payslip.pdf
+func (h *PayslipHandler) Download(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payroll id"})
+		return
+	}
+	payslip, err := h.svc.Render(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(payslipErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="`+payslip.FileName+`"`)
+	c.Data(http.StatusOK, "application/pdf", payslip.Content)
+}
+
+// GetTemplate retorna la plantilla del desprendible del tenant
+// GET /api/v1/payslip-template
+func (h *PayslipHandler) GetTemplate(c *gin.Context) {
+	template, err := h.svc.GetTemplate(c.Request.Context())
+	if err != nil {
+		c.JSON(payslipErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPayslipTemplateResponse(template))
+}
+
+// UpdateTemplate guarda los textos y el color de la plantilla
+// PUT /api/v1/payslip-template
+func (h *PayslipHandler) UpdateTemplate(c *gin.Context) {
+	var req dto.PayslipTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template, err := h.svc.SaveTemplate(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.JSON(payslipErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPayslipTemplateResponse(template))
+}
+
+// UploadLogo reemplaza el logo de la plantilla (multipart, campo logo)
+// PUT /api/v1/payslip-template/logo
+func (h *PayslipHandler) UploadLogo(c *gin.Context) {
+	header, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "logo is required"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	logo, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template, err := h.svc.SetLogo(c.Request.Context(), logo)
+	if err != nil {
+		c.JSON(payslipErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPayslipTemplateResponse(template))
+}
+
+// DeleteLogo quita el logo de la plantilla
+// DELETE /api/v1/payslip-template/logo
+func (h *PayslipHandler) DeleteLogo(c *gin.Context) {
+	template, err := h.svc.SetLogo(c.Request.Context(), nil)
+	if err != nil {
+		c.JSON(payslipErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPayslipTemplateResponse(template))
+}
+
+func payslipErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPayslipNotAvailable):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidPayslipTemplate), errors.Is(err, domain.ErrInvalidPayslipLogo):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
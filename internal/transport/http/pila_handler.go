@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// PILAHandler genera la planilla de aportes a seguridad social
+type PILAHandler struct {
+	svc *service.PILAService
+}
+
+func NewPILAHandler(svc *service.PILAService) *PILAHandler {
+	return &PILAHandler{svc: svc}
+}
+
+// Preview retorna la liquidación del mes con los errores de validación por línea
+// GET /api/v1/pila?year=2026&month=9
+func (h *PILAHandler) Preview(c *gin.Context) {
+	year, month, ok := pilaPeriod(c)
+	if !ok {
+		return
+	}
+	file, err := h.svc.Generate(c.Request.Context(), year, month)
+	if err != nil {
+		c.JSON(pilaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPILAResponse(file))
+}
+
+// Download descarga el archivo plano; si hay errores de validación responde 422 con ellos
+// GET /api/v1/pila/file?year=2026&month=9
+func (h *PILAHandler) Download(c *gin.Context) {
+	year, month, ok := pilaPeriod(c)
+	if !ok {
+		return
+	}
+	file, err := h.svc.Generate(c.Request.Context(), year, month)
+	if err != nil {
+		c.JSON(pilaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if len(file.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": domain.ErrPILAHasErrors.Error(), "errors": file.Errors})
+		return
+	}
+	fileName := fmt.Sprintf("PILA_%04d-%02d.txt", year, month)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "text/plain", file.Content)
+}
+
+func pilaPeriod(c *gin.Context) (int, int, bool) {
+	year, errYear := strconv.Atoi(c.Query("year"))
+	month, errMonth := strconv.Atoi(c.Query("month"))
+	if errYear != nil || errMonth != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year and month query params are required"})
+		return 0, 0, false
+	}
+	return year, month, true
+}
+
+func pilaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPILAPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNoPayrollsForPILA):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrEmployerDataRequired):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
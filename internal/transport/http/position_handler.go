@@ -0,0 +1,119 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// PositionHandler maneja los cargos de cada departamento
+type PositionHandler struct {
+	svc *service.PositionService
+}
+
+func NewPositionHandler(svc *service.PositionService) *PositionHandler {
+	return &PositionHandler{svc: svc}
+}
+
+// Create crea un cargo
+// POST /api/v1/positions
+func (h *PositionHandler) Create(c *gin.Context) {
+	var req dto.CreatePositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position := req.ToDomain()
+	if err := h.svc.Create(c.Request.Context(), position); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToPositionResponse(position))
+}
+
+// List lista los cargos, opcionalmente de un departamento
+// GET /api/v1/positions?department_id=
+func (h *PositionHandler) List(c *gin.Context) {
+	departmentID, err := strconv.ParseUint(c.DefaultQuery("department_id", "0"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department_id"})
+		return
+	}
+
+	positions, err := h.svc.List(c.Request.Context(), uint(departmentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.PositionResponse, len(positions))
+	for i := range positions {
+		resp[i] = dto.ToPositionResponse(&positions[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"positions": resp})
+}
+
+// GetByID obtiene un cargo
+// GET /api/v1/positions/:id
+func (h *PositionHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid position id"})
+		return
+	}
+
+	position, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPositionResponse(position))
+}
+
+// Update actualiza un cargo
+// PUT /api/v1/positions/:id
+func (h *PositionHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid position id"})
+		return
+	}
+
+	position, err := h.svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdatePositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Apply(position)
+
+	if err := h.svc.Update(c.Request.Context(), position); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToPositionResponse(position))
+}
+
+// Delete elimina un cargo
+// DELETE /api/v1/positions/:id
+func (h *PositionHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid position id"})
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(departmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
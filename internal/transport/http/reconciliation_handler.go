@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/bankstatement"
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatementSize limita el tamaño del extracto que se acepta en la carga
+const maxStatementSize = 10 << 20
+
+// ReconciliationHandler maneja la carga de extractos y la conciliación de pagos
+type ReconciliationHandler struct {
+	svc *service.ReconciliationService
+}
+
+func NewReconciliationHandler(svc *service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{svc: svc}
+}
+
+// Import carga un extracto (multipart: file y format csv|camt053) y lo concilia
+// POST /api/v1/bank-statements
+func (h *ReconciliationHandler) Import(c *gin.Context) {
+	format := c.PostForm("format")
+	if format != bankstatement.FormatCSV && format != bankstatement.FormatCamt053 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or camt053"})
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if header.Size > maxStatementSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "statement file is too large"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	statement, err := h.svc.Import(ctx, format, header.Filename, content)
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.svc.Report(ctx, statement.ID)
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.ToReconciliationReportResponse(report))
+}
+
+// List lista los extractos importados
+// GET /api/v1/bank-statements
+func (h *ReconciliationHandler) List(c *gin.Context) {
+	statements, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]*dto.BankStatementResponse, len(statements))
+	for i := range statements {
+		resp[i] = dto.ToBankStatementResponse(&statements[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"bank_statements": resp})
+}
+
+// Report retorna el reporte de conciliación del extracto
+// GET /api/v1/bank-statements/:id
+func (h *ReconciliationHandler) Report(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement id"})
+		return
+	}
+	report, err := h.svc.Report(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToReconciliationReportResponse(report))
+}
+
+// Resolve asocia a mano una excepción a un pago y lo confirma
+// POST /api/v1/bank-statements/:id/lines/:lineId/resolve
+func (h *ReconciliationHandler) Resolve(c *gin.Context) {
+	statementID, lineID, ok := statementLineIDs(c)
+	if !ok {
+		return
+	}
+	var req dto.ResolveStatementLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	line, err := h.svc.ResolveLine(c.Request.Context(), statementID, lineID, service.ResolveLineRequest{
+		PaymentID: &req.PaymentID,
+		Note:      req.Note,
+	})
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToBankStatementLineResponse(line))
+}
+
+// Ignore descarta una excepción del extracto
+// POST /api/v1/bank-statements/:id/lines/:lineId/ignore
+func (h *ReconciliationHandler) Ignore(c *gin.Context) {
+	statementID, lineID, ok := statementLineIDs(c)
+	if !ok {
+		return
+	}
+	var req dto.IgnoreStatementLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	line, err := h.svc.ResolveLine(c.Request.Context(), statementID, lineID, service.ResolveLineRequest{
+		Ignore: true,
+		Note:   req.Note,
+	})
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.ToBankStatementLineResponse(line))
+}
+
+func statementLineIDs(c *gin.Context) (uint, uint, bool) {
+	statementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement id"})
+		return 0, 0, false
+	}
+	lineID, err := strconv.ParseUint(c.Param("lineId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid line id"})
+		return 0, 0, false
+	}
+	return uint(statementID), uint(lineID), true
+}
+
+func reconciliationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrStatementNotFound), errors.Is(err, domain.ErrStatementLineNotFound),
+		errors.Is(err, domain.ErrPaymentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrStatementAlreadyImported), errors.Is(err, domain.ErrStatementLineResolved),
+		errors.Is(err, domain.ErrPaymentAlreadyReconciled), errors.Is(err, domain.ErrInvalidPaymentStatus):
+		return http.StatusConflict
+	case errors.Is(err, bankstatement.ErrInvalidStatement):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
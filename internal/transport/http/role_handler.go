@@ -172,6 +172,8 @@ func (h *RoleHandler) Delete(c *gin.Context) {
 
 type AssignPermissionRequest struct {
 	ActionID uint `json:"action_id" binding:"required"`
+	// Scope limita el permiso a los subordinados o al departamento del usuario
+	Scope string `json:"scope" binding:"omitempty,oneof=all own_reports own_department"`
 }
 
 func (h *RoleHandler) AssignPermission(c *gin.Context) {
@@ -188,7 +190,11 @@ func (h *RoleHandler) AssignPermission(c *gin.Context) {
 		return
 	}
 
-	if err := h.permissionSvc.AssignPermissionToRole(c.Request.Context(), uint(roleID), req.ActionID); err != nil {
+	if err := h.permissionSvc.AssignPermissionToRole(c.Request.Context(), uint(roleID), req.ActionID, req.Scope); err != nil {
+		if errors.Is(err, domain.ErrInvalidDataScope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -16,6 +16,30 @@ func NewRouter(
 	payrollSvc *service.PayrollService,
 	payrollStateSvc *service.PayrollStateService,
 	batchPayrollSvc *service.PayrollBatchService,
+	payrollReversalSvc *service.PayrollReversalService,
+	accountingPeriodSvc *service.AccountingPeriodService,
+	payrollRetroSvc *service.PayrollRetroService,
+	benefitSvc *service.BenefitService,
+	terminationSvc *service.TerminationService,
+	contractSvc *service.ContractService,
+	contractTypeSvc *service.ContractTypeService,
+	departmentSvc *service.DepartmentService,
+	positionSvc *service.PositionService,
+	compensationSvc *service.CompensationService,
+	onboardingSvc *service.OnboardingService,
+	bankAccountSvc *service.BankAccountService,
+	paymentSvc *service.PaymentService,
+	bankFileSvc *service.BankFileService,
+	reconciliationSvc *service.ReconciliationService,
+	payslipSvc *service.PayslipService,
+	notificationSvc *service.NotificationService,
+	electronicPayrollSvc *service.ElectronicPayrollService,
+	absenceSvc *service.AbsenceService,
+	pilaSvc *service.PILAService,
+	journalSvc *service.JournalService,
+	payrollRegisterSvc *service.PayrollRegisterService,
+	accumulatorSvc *service.AccumulatorService,
+	costCenterSvc *service.CostCenterService,
 ) *gin.Engine {
 	r := gin.Default()
 
@@ -23,7 +47,7 @@ func NewRouter(
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Tenant-ID")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Tenant-ID, X-User-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -42,6 +66,7 @@ func NewRouter(
 	// API v1
 	v1 := r.Group("/api/v1")
 	v1.Use(middleware.TenantMiddleware())
+	v1.Use(middleware.ActorMiddleware())
 	// routes
 	users := v1.Group("/users")
 	{
@@ -80,9 +105,106 @@ func NewRouter(
 		employees.POST("", employeeHandler.Create)
 		employees.GET("", employeeHandler.List)
 		employees.GET("/search", employeeHandler.GetByUserID)
+		employees.GET("/org-chart", employeeHandler.OrgChart)
 		employees.GET("/:id", employeeHandler.GetByID)
 		employees.PUT("/:id", employeeHandler.Update)
 		employees.DELETE("/:id", employeeHandler.Delete)
+		employees.PUT("/:id/manager", employeeHandler.SetManager)
+
+		// Terminación de contrato y liquidación final
+		terminationHandler := NewTerminationHandler(terminationSvc)
+		employees.POST("/:id/terminate", terminationHandler.Terminate)
+		employees.GET("/:id/termination", terminationHandler.GetSettlement)
+		employees.POST("/:id/termination/sign", terminationHandler.Sign)
+
+		// Contratos: historia, creación, modificación, prórroga y terminación
+		contractHandler := NewContractHandler(contractSvc, terminationSvc)
+		employees.GET("/:id/contracts", contractHandler.Timeline)
+		employees.POST("/:id/contracts", contractHandler.Create)
+		employees.POST("/:id/contracts/:contractId/amend", contractHandler.Amend)
+		employees.POST("/:id/contracts/:contractId/renew", contractHandler.Renew)
+		employees.POST("/:id/contracts/:contractId/terminate", contractHandler.Terminate)
+
+		// Cuentas de pago (varias por empleado, una principal)
+		bankAccountHandler := NewBankAccountHandler(bankAccountSvc)
+		employees.GET("/:id/bank-accounts", bankAccountHandler.List)
+		employees.POST("/:id/bank-accounts", bankAccountHandler.Create)
+		employees.PUT("/:id/bank-accounts/:accountId", bankAccountHandler.Update)
+		employees.PUT("/:id/bank-accounts/:accountId/primary", bankAccountHandler.SetPrimary)
+		employees.DELETE("/:id/bank-accounts/:accountId", bankAccountHandler.Delete)
+
+		// Ausencias (vacaciones, licencias e incapacidades)
+		absenceHandler := NewAbsenceHandler(absenceSvc)
+		employees.GET("/:id/absences", absenceHandler.List)
+		employees.POST("/:id/absences", absenceHandler.Create)
+		employees.DELETE("/:id/absences/:absenceId", absenceHandler.Delete)
+
+		// Acumulados anuales y certificado de ingresos y retenciones
+		accumulatorHandler := NewAccumulatorHandler(accumulatorSvc)
+		employees.GET("/:id/accumulators", accumulatorHandler.List)
+		employees.GET("/:id/income-certificate", accumulatorHandler.Certificate)
+		employees.GET("/:id/income-certificate/pdf", accumulatorHandler.CertificatePDF)
+		costCenterHandler := NewCostCenterHandler(costCenterSvc)
+		employees.GET("/:id/cost-allocations", costCenterHandler.ListAllocations)
+		employees.PUT("/:id/cost-allocations", costCenterHandler.SetAllocation)
+	}
+
+	// Onboarding (alta completa de empleados en una transacción)
+	v1.POST("/onboarding", NewOnboardingHandler(onboardingSvc).Onboard)
+
+	// Departments (árbol organizacional)
+	departments := v1.Group("/departments")
+	{
+		departmentHandler := NewDepartmentHandler(departmentSvc)
+		departments.POST("", departmentHandler.Create)
+		departments.GET("", departmentHandler.List)
+		departments.GET("/tree", departmentHandler.Tree)
+		departments.GET("/compa-ratio", NewCompensationHandler(compensationSvc).CompaRatio)
+		departments.GET("/:id", departmentHandler.GetByID)
+		departments.PUT("/:id", departmentHandler.Update)
+		departments.PUT("/:id/head", departmentHandler.SetHead)
+		departments.DELETE("/:id", departmentHandler.Delete)
+	}
+
+	// Cost centers (reparto del costo de nómina por centro de costo)
+	costCenters := v1.Group("/cost-centers")
+	{
+		costCenterHandler := NewCostCenterHandler(costCenterSvc)
+		costCenters.POST("", costCenterHandler.Create)
+		costCenters.GET("", costCenterHandler.List)
+		costCenters.GET("/report", costCenterHandler.Report)
+		costCenters.GET("/:id", costCenterHandler.GetByID)
+		costCenters.PUT("/:id", costCenterHandler.Update)
+	}
+
+	// Positions (cargos por departamento)
+	positions := v1.Group("/positions")
+	{
+		positionHandler := NewPositionHandler(positionSvc)
+		positions.POST("", positionHandler.Create)
+		positions.GET("", positionHandler.List)
+		positions.GET("/:id", positionHandler.GetByID)
+		positions.PUT("/:id", positionHandler.Update)
+		positions.DELETE("/:id", positionHandler.Delete)
+	}
+
+	// Contract Types (reglas por tipo de contrato)
+	contractTypes := v1.Group("/contract-types")
+	{
+		contractTypeHandler := NewContractTypeHandler(contractTypeSvc)
+		contractTypes.POST("", contractTypeHandler.Create)
+		contractTypes.GET("", contractTypeHandler.List)
+		contractTypes.POST("/seed", contractTypeHandler.SeedDefaultContractTypes)
+		contractTypes.GET("/:id", contractTypeHandler.GetByID)
+		contractTypes.PUT("/:id", contractTypeHandler.Update)
+		contractTypes.DELETE("/:id", contractTypeHandler.Delete)
+	}
+
+	// Contracts (alertas de renovación de todos los empleados)
+	contracts := v1.Group("/contracts")
+	{
+		contractHandler := NewContractHandler(contractSvc, terminationSvc)
+		contracts.GET("/renewal-alerts", contractHandler.RenewalAlerts)
 	}
 
 	// Payroll Concepts
@@ -113,9 +235,135 @@ func NewRouter(
 		stateHandler := NewPayrollStateHandler(payrollStateSvc, batchPayrollSvc, payrollSvc)
 		payroll.POST("/:id/mark-paid", stateHandler.MarkAsPaid)
 		payroll.POST("/:id/revert-to-draft", stateHandler.RevertToDraft)
+		payroll.POST("/:id/approve", stateHandler.Approve)
+		payroll.POST("/:id/cancel", stateHandler.Cancel)
+		payroll.GET("/:id/history", stateHandler.GetHistory)
 		payroll.GET("/:id/payment", stateHandler.GetPaymentInfo)
+		payroll.GET("/:id/payments", stateHandler.ListPayments)
+		payroll.POST("/:id/payments", stateHandler.RegisterPayments)
+		payroll.GET("/employee/:employeeId/payments", stateHandler.ListEmployeePayments)
 		payroll.POST("/batch", stateHandler.ProcessBatch)
 		payroll.GET("/summary", stateHandler.GetPayrollSummary)
+		payroll.GET("/register", NewPayrollRegisterHandler(payrollRegisterSvc).Download)
+		payroll.GET("/transitions", stateHandler.GetTransitions)
+		payroll.PUT("/transitions", stateHandler.UpdateTransitions)
+
+		// Reversos de nóminas pagadas
+		reversalHandler := NewPayrollReversalHandler(payrollReversalSvc)
+		payroll.POST("/:id/reverse", reversalHandler.Reverse)
+
+		// Retroactivos sobre periodos ya pagados
+		retroHandler := NewPayrollRetroHandler(payrollRetroSvc)
+		payroll.POST("/employee/:employeeId/retro", retroHandler.Run)
+		payroll.GET("/employee/:employeeId/retro", retroHandler.ListByEmployee)
+		payroll.GET("/:id/retro", retroHandler.ListByPayroll)
+
+		// Desprendible de nómina
+		payslipHandler := NewPayslipHandler(payslipSvc)
+		payroll.GET("/:id/payslip.pdf", payslipHandler.Download)
+
+		// Envío del desprendible por correo
+		notificationHandler := NewNotificationHandler(notificationSvc)
+		payroll.GET("/:id/notifications", notificationHandler.ListByPayroll)
+		payroll.POST("/:id/notifications/retry", notificationHandler.RetryFailed)
+	}
+
+	// Payslip template (plantilla y logo del desprendible)
+	payslipTemplate := v1.Group("/payslip-template")
+	{
+		payslipHandler := NewPayslipHandler(payslipSvc)
+		payslipTemplate.GET("", payslipHandler.GetTemplate)
+		payslipTemplate.PUT("", payslipHandler.UpdateTemplate)
+		payslipTemplate.PUT("/logo", payslipHandler.UploadLogo)
+		payslipTemplate.DELETE("/logo", payslipHandler.DeleteLogo)
+	}
+
+	// Payments (envío al proveedor, callbacks y confirmación manual)
+	payments := v1.Group("/payments")
+	{
+		paymentHandler := NewPaymentHandler(paymentSvc)
+		payments.POST("/sync", paymentHandler.Sync)
+		payments.POST("/callback", paymentHandler.Callback)
+		payments.GET("/:id", paymentHandler.GetByID)
+		payments.PUT("/:id/status", paymentHandler.UpdateStatus)
+	}
+
+	// Bank files (archivos de pago para cargar en el banco)
+	bankFileHandler := NewBankFileHandler(bankFileSvc)
+	bankFiles := v1.Group("/bank-files")
+	{
+		bankFiles.POST("", bankFileHandler.Export)
+		bankFiles.GET("", bankFileHandler.List)
+		bankFiles.GET("/:id", bankFileHandler.GetByID)
+		bankFiles.GET("/:id/download", bankFileHandler.Download)
+	}
+	bankFileTemplates := v1.Group("/bank-file-templates")
+	{
+		bankFileTemplates.GET("", bankFileHandler.ListTemplates)
+		bankFileTemplates.POST("", bankFileHandler.CreateTemplate)
+		bankFileTemplates.GET("/:id", bankFileHandler.GetTemplate)
+		bankFileTemplates.PUT("/:id", bankFileHandler.UpdateTemplate)
+		bankFileTemplates.DELETE("/:id", bankFileHandler.DeleteTemplate)
+	}
+
+	// Bank statements (conciliación de pagos contra el extracto)
+	statements := v1.Group("/bank-statements")
+	{
+		reconciliationHandler := NewReconciliationHandler(reconciliationSvc)
+		statements.POST("", reconciliationHandler.Import)
+		statements.GET("", reconciliationHandler.List)
+		statements.GET("/:id", reconciliationHandler.Report)
+		statements.POST("/:id/lines/:lineId/resolve", reconciliationHandler.Resolve)
+		statements.POST("/:id/lines/:lineId/ignore", reconciliationHandler.Ignore)
+	}
+
+	// Electronic payroll (nómina electrónica para la DIAN)
+	electronicPayroll := v1.Group("/electronic-payroll")
+	{
+		electronicPayrollHandler := NewElectronicPayrollHandler(electronicPayrollSvc)
+		electronicPayroll.POST("/generate", electronicPayrollHandler.Generate)
+		electronicPayroll.GET("", electronicPayrollHandler.List)
+		electronicPayroll.GET("/:id", electronicPayrollHandler.GetByID)
+		electronicPayroll.GET("/:id/xml", electronicPayrollHandler.DownloadXML)
+		electronicPayroll.POST("/:id/submit", electronicPayrollHandler.Submit)
+	}
+
+	// PILA (planilla de aportes a seguridad social y parafiscales)
+	pilaGroup := v1.Group("/pila")
+	{
+		pilaHandler := NewPILAHandler(pilaSvc)
+		pilaGroup.GET("", pilaHandler.Preview)
+		pilaGroup.GET("/file", pilaHandler.Download)
+	}
+
+	// Asiento contable de nómina
+	journal := v1.Group("/journal")
+	{
+		journalHandler := NewJournalHandler(journalSvc)
+		journal.GET("", journalHandler.Preview)
+		journal.GET("/file", journalHandler.Download)
+	}
+
+	// Accounting Periods (cierre contable)
+	periods := v1.Group("/accounting-periods")
+	{
+		periodHandler := NewAccountingPeriodHandler(accountingPeriodSvc)
+		periods.POST("", periodHandler.Create)
+		periods.GET("", periodHandler.List)
+		periods.GET("/:id", periodHandler.GetByID)
+		periods.POST("/:id/close", periodHandler.Close)
+		periods.POST("/:id/lock", periodHandler.Lock)
+		periods.POST("/:id/reopen", periodHandler.Reopen)
+	}
+
+	// Social benefits (prestaciones sociales)
+	benefits := v1.Group("/benefits")
+	{
+		benefitHandler := NewBenefitHandler(benefitSvc)
+		benefits.POST("/accrue", benefitHandler.Accrue)
+		benefits.GET("/employee/:employeeId", benefitHandler.GetLedger)
+		benefits.POST("/prima", benefitHandler.PayPrima)
+		benefits.POST("/severance", benefitHandler.ConsignSeverance)
 	}
 
 	return r
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/arrase21/crm-users/internal/service"
+	"github.com/arrase21/crm-users/internal/transport/http/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// TerminationHandler maneja la terminación de contratos y el documento de liquidación
+type TerminationHandler struct {
+	terminationSvc *service.TerminationService
+}
+
+func NewTerminationHandler(terminationSvc *service.TerminationService) *TerminationHandler {
+	return &TerminationHandler{terminationSvc: terminationSvc}
+}
+
+// TerminateEmployeeRequest representa el request de terminación
+type TerminateEmployeeRequest struct {
+	TerminationDate string `json:"termination_date" binding:"required"`
+	Reason          string `json:"reason" binding:"required,max=255"`
+	JustCause       bool   `json:"just_cause"`
+	PayDate         string `json:"pay_date"`
+}
+
+// Terminate termina el contrato activo y genera la liquidación final
+// POST /api/v1/employees/:id/terminate
+func (h *TerminationHandler) Terminate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	var req TerminateEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	terminationDate, err := parseDate(req.TerminationDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid termination_date format, use YYYY-MM-DD"})
+		return
+	}
+	payDate, ok := optionalPayDate(c, req.PayDate)
+	if !ok {
+		return
+	}
+
+	doc, err := h.terminationSvc.Terminate(c.Request.Context(), service.TerminateRequest{
+		EmployeeID:      uint(id),
+		TerminationDate: terminationDate,
+		Reason:          req.Reason,
+		JustCause:       req.JustCause,
+		PayDate:         payDate,
+	})
+	if err != nil {
+		c.JSON(terminationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, settlementResponse(doc))
+}
+
+// GetSettlement retorna el documento de liquidación del empleado
+// GET /api/v1/employees/:id/termination
+func (h *TerminationHandler) GetSettlement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	doc, err := h.terminationSvc.GetSettlement(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(terminationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settlementResponse(doc))
+}
+
+// Sign registra la firma del empleado sobre su liquidación
+// POST /api/v1/employees/:id/termination/sign
+func (h *TerminationHandler) Sign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	termination, err := h.terminationSvc.Sign(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(terminationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "settlement signed",
+		"termination": termination,
+	})
+}
+
+func settlementResponse(doc *service.SettlementDocument) gin.H {
+	return gin.H{
+		"termination": doc.Termination,
+		"settlement":  dto.ToPayrollResponse(doc.Settlement, ""),
+		"signed":      doc.Termination.SignedAt != nil,
+	}
+}
+
+func terminationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrEmployeeContractNotFound),
+		errors.Is(err, domain.ErrTerminationNotFound), errors.Is(err, domain.ErrPayrollNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTerminationDate):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrEmployeeTerminated), errors.Is(err, domain.ErrOpenPayrollPending),
+		errors.Is(err, domain.ErrContractNotActive),
+		errors.Is(err, domain.ErrPeriodClosed), errors.Is(err, domain.ErrSettlementAlreadySigned):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrContractEndDateRequired):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrActorRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
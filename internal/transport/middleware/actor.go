@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/arrase21/crm-users/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// ActorMiddleware lee el usuario que ejecuta la petición desde X-User-ID (opcional)
+func ActorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userHeader := c.GetHeader("X-User-ID")
+		if userHeader == "" {
+			c.Next()
+			return
+		}
+		userID, err := strconv.ParseUint(userHeader, 10, 32)
+		if err != nil || userID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid X-User-ID"})
+			c.Abort()
+			return
+		}
+		ctx := context.WithValue(c.Request.Context(), domain.UserIDKey, uint(userID))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}